@@ -146,6 +146,15 @@ public class UserProfileController {
         return userProfileService.getUsersInternal(userIds);
     }
 
+    @ResponseBody
+    @GetMapping(value = Constants.Urls.INTERNAL_API+Constants.Urls.USER+Constants.Urls.EMAIL)
+    public List<UserEmailInternalDTO> getUserEmailsInternal(
+        @RequestParam(value = "userId") List<Long> userIds
+    ) {
+        LOGGER.info("Getting user emails internal");
+        return userProfileService.getUserEmailsInternal(userIds);
+    }
+
     @ResponseBody
     @PatchMapping(Constants.Urls.EXTERNAL_API +Constants.Urls.PROFILE)
     @PreAuthorize("isAuthenticated()")
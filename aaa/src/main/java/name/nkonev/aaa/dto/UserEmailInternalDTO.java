@@ -0,0 +1,9 @@
+package name.nkonev.aaa.dto;
+
+// for the notification digest of the users which were created before it, the email shouldn't be shown publicly
+public record UserEmailInternalDTO(
+        Long id,
+        String login,
+        String email
+) {
+}
@@ -334,6 +334,18 @@ public class UserProfileService {
         return result;
     }
 
+    public List<UserEmailInternalDTO> getUserEmailsInternal(
+        List<Long> userIds
+    ) {
+        if (userIds == null) {
+            throw new BadRequestException("Cannot be null");
+        }
+        if (userIds.size() > MAX_USERS_RESPONSE_LENGTH) {
+            throw new BadRequestException("Cannot be greater than " + MAX_USERS_RESPONSE_LENGTH);
+        }
+        return userAccountRepository.findByIdInOrderById(userIds).stream().map(v -> new UserEmailInternalDTO(v.id(), v.login(), v.email())).toList();
+    }
+
     public UserSelfProfileDTO editNonEmpty(
             UserAccountDetailsDTO userAccount,
             EditUserDTO editUserAccountDTO,
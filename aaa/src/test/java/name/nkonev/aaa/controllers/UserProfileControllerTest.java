@@ -316,6 +316,24 @@ public class UserProfileControllerTest extends AbstractMockMvcTestRunner {
 
     }
 
+    @Test
+    public void testGetUserEmailsInternal() throws Exception {
+        UserAccount bob = getUserFromBd(USER_BOB);
+        UserAccount alice = getUserFromBd(TestConstants.USER_ALICE);
+
+        MvcResult mvcResult = mockMvc.perform(
+                get(Constants.Urls.INTERNAL_API + Constants.Urls.USER+Constants.Urls.EMAIL+"?userId="+bob.id()+"&userId="+alice.id())
+            )
+            .andExpect(status().isOk())
+            .andExpect(jsonPath("$[0].id").value(alice.id()))
+            .andExpect(jsonPath("$[0].login").value(TestConstants.USER_ALICE))
+            .andExpect(jsonPath("$[0].email").value(alice.email()))
+            .andExpect(jsonPath("$[1].login").value(USER_BOB))
+            .andExpect(jsonPath("$[1].email").value(bob.email()))
+            .andReturn();
+
+    }
+
     @Test
     public void userCanSeeOnlyOwnProfileEmail() throws Exception {
         String session = getMockMvcSession(TestConstants.USER_ALICE, TestConstants.USER_ALICE_PASSWORD);
@@ -272,6 +272,22 @@ func (rc *TestRestClient) PutUserChatNotificationSettings(ctx context.Context, b
 	return queryNoResponse[dto.PutChatNotificationSettingsDto](ctx, &rc.restClient, behalfUserId, http.MethodPut, "/api/chat/"+utils.ToString(chatId)+"/notification", "chat.PutUserChatNotificationSettings", &req, nil)
 }
 
+func (rc *TestRestClient) PutNotificationKeywords(ctx context.Context, behalfUserId int64, keywords []dto.NotificationKeywordDto, mutedWords []string) error {
+	req := dto.NotificationKeywordsDto{
+		Keywords:   keywords,
+		MutedWords: mutedWords,
+	}
+	return queryNoResponse[dto.NotificationKeywordsDto](ctx, &rc.restClient, behalfUserId, http.MethodPut, "/api/chat/notification/keywords", "chat.PutNotificationKeywords", &req, nil)
+}
+
+func (rc *TestRestClient) GetNotificationKeywords(ctx context.Context, behalfUserId int64) (*dto.NotificationKeywordsDto, error) {
+	resp, err := query[any, dto.NotificationKeywordsDto](ctx, &rc.restClient, behalfUserId, http.MethodGet, "/api/chat/notification/keywords", "chat.GetNotificationKeywords", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 func (rc *TestRestClient) SearchBlogs(ctx context.Context) (dto.BlogPostsDTO, error) {
 	return query[any, dto.BlogPostsDTO](ctx, &rc.restClient, dto.NonExistentUser, http.MethodGet, "/api/blog", "blog.Search", nil, nil)
 }
@@ -118,6 +118,7 @@ To run without schedulers:
 			cqrs.NewEventHandler,
 			listener.CreateRabbitInternalEventsListener,
 			listener.CreateRabbitAaaUserProfileUpdateListener,
+			listener.CreatePresenceListener,
 			type_registry.NewTypeRegistryInstance,
 			tasks.RedisV9,
 			tasks.Scheduler,
@@ -142,6 +143,7 @@ To run without schedulers:
 			producer.EnableNotificationEvents,
 			listener.CreateAndListenInternalEventsChannel,
 			listener.CreateAndListenAaaChannel,
+			listener.CreateAndListenPresenceChannel,
 			tasks.RunScheduler,
 			handlers.RunHttpServer,
 		),
@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"nkonev.name/chat/client"
 	"nkonev.name/chat/config"
@@ -19,6 +21,7 @@ import (
 	"nkonev.name/chat/tasks"
 	"nkonev.name/chat/utils"
 
+	"github.com/streadway/amqp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -1855,6 +1858,141 @@ func TestReplyNotification(t *testing.T) {
 		})
 }
 
+func TestKeywordNotification(t *testing.T) {
+	const user1 int64 = 1
+	const user2 int64 = 2
+	const user1Login = "admin1"
+	const user2Login = "admin2"
+
+	mockUser1 := dto.User{
+		Id:               user1,
+		Login:            user1Login,
+		Avatar:           nil,
+		ShortInfo:        nil,
+		LoginColor:       nil,
+		LastSeenDateTime: nil,
+		AdditionalData:   nil,
+	}
+
+	mockUser2 := dto.User{
+		Id:               user2,
+		Login:            user2Login,
+		Avatar:           nil,
+		ShortInfo:        nil,
+		LoginColor:       nil,
+		LastSeenDateTime: nil,
+		AdditionalData:   nil,
+	}
+
+	startAppFull(t,
+		func(
+			aaaRestClient client.AaaRestClient,
+		) {
+			mockAaaClient := aaaRestClient.(*client.MockAaaRestClient)
+			mockAaaClient.EXPECT().GetUsers(mock.Anything, mock.Anything).Return([]*dto.User{&mockUser1, &mockUser2}, nil)
+		},
+		func(
+			lgr *logger.LoggerWrapper,
+			cfg *config.AppConfig,
+			testRestClient *client.TestRestClient,
+			admCl *kadm.Client,
+			m *cqrs.CommonProjection,
+			testOutputEventsAccumulator *listener.TestOutputEventAccumulator,
+			testNotificationEventsAccumulator *listener.TestNotificationEventAccumulator,
+			lc fx.Lifecycle,
+		) {
+			const chat1Name = "new chat 1"
+			const chat2Name = "new chat 2"
+
+			ctx := context.Background()
+
+			chat1Id, err := testRestClient.CreateChat(ctx, user1, chat1Name, client.NewChatOptionParticipants(user2))
+			require.NoError(t, err, "error in creating chat")
+			chat2Id, err := testRestClient.CreateChat(ctx, user1, chat2Name, client.NewChatOptionParticipants(user2))
+			require.NoError(t, err, "error in creating chat")
+			require.NoError(t, kafka.WaitForAllEventsProcessedChat(lgr, cfg, admCl, lc), "error in waiting for processing events")
+			require.NoError(t, kafka.WaitForAllEventsProcessedUser(lgr, cfg, admCl, lc), "error in waiting for processing events")
+
+			err = testRestClient.PutNotificationKeywords(ctx, user2, []dto.NotificationKeywordDto{
+				{Keyword: " Outage "},
+				{Keyword: "Payment   Service", ChatId: &chat2Id},
+			}, []string{"Standup"})
+			require.NoError(t, err, "error in putting keywords")
+			require.NoError(t, kafka.WaitForAllEventsProcessedChat(lgr, cfg, admCl, lc), "error in waiting for processing events")
+			require.NoError(t, kafka.WaitForAllEventsProcessedUser(lgr, cfg, admCl, lc), "error in waiting for processing events")
+
+			keywords, err := testRestClient.GetNotificationKeywords(ctx, user2)
+			require.NoError(t, err, "error in getting keywords")
+			assert.Equal(t, []dto.NotificationKeywordDto{
+				{Keyword: "outage"},
+				{Keyword: "payment service", ChatId: &chat2Id},
+			}, keywords.Keywords)
+			assert.Equal(t, []string{"standup"}, keywords.MutedWords)
+
+			t.Run("keyword_added", func(t *testing.T) {
+				testNotificationEventsAccumulator.Clean()
+
+				const message1Text = "<p>We have an OUTAGE, folks!</p>"
+				message1Id, err := testRestClient.CreateMessage(ctx, user1, chat1Id, message1Text)
+				require.NoError(t, err, "error in creating message")
+				require.NoError(t, kafka.WaitForAllEventsProcessedChat(lgr, cfg, admCl, lc), "error in waiting for processing events")
+				require.NoError(t, kafka.WaitForAllEventsProcessedUser(lgr, cfg, admCl, lc), "error in waiting for processing events")
+
+				require.NoError(t, testNotificationEventsAccumulator.AwaitForBufferContainsSpecifiedEvents(cfg.RabbitMQ.MaxWaitForEvents, true, []func(e any) bool{
+					func(ee any) bool {
+						e, ok := ee.(*dto.NotificationEvent)
+						return ok && e.EventType == dto.EventTypeKeywordAdded &&
+							e.UserId == user2 &&
+							e.ChatId == chat1Id &&
+							e.ByUserId == user1 &&
+							e.KeywordNotification.Id == message1Id &&
+							e.KeywordNotification.Keyword == "outage" &&
+							strings.Contains(e.KeywordNotification.Text, "OUTAGE")
+					},
+				}))
+			})
+
+			t.Run("keyword_scoped_and_muted", func(t *testing.T) {
+				testNotificationEventsAccumulator.Clean()
+
+				// the keyword is scoped to chat 2
+				message2Id, err := testRestClient.CreateMessage(ctx, user1, chat1Id, "payment service is slow")
+				require.NoError(t, err, "error in creating message")
+				require.NoError(t, kafka.WaitForAllEventsProcessedChat(lgr, cfg, admCl, lc), "error in waiting for processing events")
+				require.NoError(t, kafka.WaitForAllEventsProcessedUser(lgr, cfg, admCl, lc), "error in waiting for processing events")
+
+				// the muted word suppresses the keyword
+				message3Id, err := testRestClient.CreateMessage(ctx, user1, chat2Id, "outage during the standup")
+				require.NoError(t, err, "error in creating message")
+				message4Id, err := testRestClient.CreateMessage(ctx, user1, chat2Id, "payment service is slow")
+				require.NoError(t, err, "error in creating message")
+				require.NoError(t, kafka.WaitForAllEventsProcessedChat(lgr, cfg, admCl, lc), "error in waiting for processing events")
+				require.NoError(t, kafka.WaitForAllEventsProcessedUser(lgr, cfg, admCl, lc), "error in waiting for processing events")
+
+				require.NoError(t, testNotificationEventsAccumulator.AwaitForBufferContainsSpecifiedEvents(cfg.RabbitMQ.MaxWaitForEvents, true, []func(e any) bool{
+					func(ee any) bool {
+						e, ok := ee.(*dto.NotificationEvent)
+						return ok && e.EventType == dto.EventTypeKeywordAdded &&
+							e.UserId == user2 &&
+							e.ChatId == chat2Id &&
+							e.KeywordNotification.Id == message4Id &&
+							e.KeywordNotification.Keyword == "payment service"
+					},
+				}))
+
+				// the message 4 was processed the last, so the events of the others would be already received
+				for _, messageId := range []int64{message2Id, message3Id} {
+					assert.False(t, testNotificationEventsAccumulator.AssertHasEventsUnordered([]func(e any) bool{
+						func(ee any) bool {
+							e, ok := ee.(*dto.NotificationEvent)
+							return ok && e.EventType == dto.EventTypeKeywordAdded && e.KeywordNotification.Id == messageId
+						},
+					}))
+				}
+			})
+		})
+}
+
 func TestReactionNotification(t *testing.T) {
 	const user1 int64 = 1
 	const user2 int64 = 2
@@ -2806,6 +2944,99 @@ func TestAddParticipant(t *testing.T) {
 		})
 }
 
+func TestParticipantPresence(t *testing.T) {
+	const user1 int64 = 1
+	const user2 int64 = 2
+	const user1Login = "admin1"
+	const user2Login = "admin2"
+
+	mockUser1 := dto.User{
+		Id:    user1,
+		Login: user1Login,
+	}
+
+	mockUser2 := dto.User{
+		Id:    user2,
+		Login: user2Login,
+	}
+
+	away := "away"
+	busy := "busy"
+	emoji := "📅"
+	text := "in a meeting until 15:00"
+	expired := time.Now().Add(-time.Minute)
+
+	startAppFull(t,
+		func(
+			aaaRestClient client.AaaRestClient,
+		) {
+			mockAaaClient := aaaRestClient.(*client.MockAaaRestClient)
+			mockAaaClient.EXPECT().GetUsers(mock.Anything, mock.Anything).Return([]*dto.User{&mockUser1, &mockUser2}, nil)
+		},
+		func(
+			lgr *logger.LoggerWrapper,
+			cfg *config.AppConfig,
+			testRestClient *client.TestRestClient,
+			admCl *kadm.Client,
+			dba *db.DB,
+			m *cqrs.CommonProjection,
+			presenceListener listener.PresenceListener,
+			lc fx.Lifecycle,
+		) {
+			ctx := context.Background()
+
+			receivePresences := func(presences ...dto.UserPresence) {
+				body, err := json.Marshal(dto.UserPresencesChanged{UserPresences: presences})
+				require.NoError(t, err)
+				require.NoError(t, presenceListener(&amqp.Delivery{Type: "dto.UserPresencesChanged", Body: body}))
+			}
+
+			chat1Id, err := testRestClient.CreateChat(ctx, user1, "new chat 1")
+			require.NoError(t, err, "error in creating chat")
+			require.NoError(t, kafka.WaitForAllEventsProcessedChat(lgr, cfg, admCl, lc), "error in waiting for processing events")
+			require.NoError(t, kafka.WaitForAllEventsProcessedUser(lgr, cfg, admCl, lc), "error in waiting for processing events")
+
+			waitForChatExists(lgr, m, dba, chat1Id, user1, cfg.Cqrs.SleepBeforePolling, cfg.Cqrs.PollingMaxTimes)
+
+			err = testRestClient.AddChatParticipants(ctx, user1, chat1Id, []int64{user2})
+			require.NoError(t, err, "error in adding participants")
+			require.NoError(t, kafka.WaitForAllEventsProcessedChat(lgr, cfg, admCl, lc), "error in waiting for processing events")
+			require.NoError(t, kafka.WaitForAllEventsProcessedUser(lgr, cfg, admCl, lc), "error in waiting for processing events")
+
+			// the expired status of the user1 isn't shown even before the event service sends its clearing
+			receivePresences(
+				dto.UserPresence{UserId: user1, Status: &away, ExpiresAt: &expired, Idle: true},
+				dto.UserPresence{UserId: user2, Status: &busy, Emoji: &emoji, Text: &text},
+			)
+
+			chat1Participants, _, err := testRestClient.GetChatParticipants(ctx, user1, chat1Id)
+			require.NoError(t, err, "error in chat participants")
+			require.Equal(t, 2, len(chat1Participants))
+
+			assert.Equal(t, user2, chat1Participants[0].Id)
+			require.NotNil(t, chat1Participants[0].Presence)
+			assert.Equal(t, busy, *chat1Participants[0].Presence.Status)
+			assert.Equal(t, emoji, *chat1Participants[0].Presence.Emoji)
+			assert.Equal(t, text, *chat1Participants[0].Presence.Text)
+			assert.False(t, chat1Participants[0].Presence.Idle)
+
+			assert.Equal(t, user1, chat1Participants[1].Id)
+			require.NotNil(t, chat1Participants[1].Presence)
+			assert.Nil(t, chat1Participants[1].Presence.Status)
+			assert.Nil(t, chat1Participants[1].Presence.ExpiresAt)
+			assert.True(t, chat1Participants[1].Presence.Idle)
+
+			// the cleared status
+			receivePresences(dto.UserPresence{UserId: user2})
+
+			chat1Participants, _, err = testRestClient.GetChatParticipants(ctx, user1, chat1Id)
+			require.NoError(t, err, "error in chat participants")
+			require.Equal(t, 2, len(chat1Participants))
+			assert.Equal(t, user2, chat1Participants[0].Id)
+			assert.Nil(t, chat1Participants[0].Presence)
+		})
+}
+
 func TestAddParticipantChatStillNotExists(t *testing.T) {
 	startAppFull(t,
 		func(aaaRestClient client.AaaRestClient) {},
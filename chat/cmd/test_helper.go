@@ -135,6 +135,7 @@ func runTestFunc(lgr *logger.LoggerWrapper, cfg *config.AppConfig, t *testing.T,
 			listener.NewRabbitTestNotificationEventAccumulator,
 			listener.CreateRabbitInternalEventsListener,
 			listener.CreateRabbitAaaUserProfileUpdateListener,
+			listener.CreatePresenceListener,
 			type_registry.NewTypeRegistryInstance,
 			tasks.NewCleanAbandonedChatsService,
 			tasks.NewCleanDeletedUserDataService,
@@ -151,6 +152,7 @@ func runTestFunc(lgr *logger.LoggerWrapper, cfg *config.AppConfig, t *testing.T,
 			listener.CreateAndListenTestNotificationEventChannel,
 			listener.CreateAndListenInternalEventsChannel,
 			listener.CreateAndListenAaaChannel,
+			listener.CreateAndListenPresenceChannel,
 			cqrs.UnsetIsNeedToSkipImport,
 			handlers.RunHttpServer,
 			waitForHealthCheck,
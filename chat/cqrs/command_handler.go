@@ -283,6 +283,12 @@ type ChatNotificationSettingsSet struct {
 	Set            bool
 }
 
+type NotificationKeywordsSet struct {
+	AdditionalData *AdditionalData
+	Keywords       []dto.NotificationKeywordDto
+	MutedWords     []string
+}
+
 type MessageRead struct {
 	AdditionalData     *AdditionalData
 	ChatId             int64
@@ -728,6 +734,20 @@ func (s *ChatNotificationSettingsSet) Handle(ctx context.Context, eventBus *Kafk
 	return eventBus.Publish(ctx, cp)
 }
 
+func (s *NotificationKeywordsSet) Handle(ctx context.Context, eventBus *KafkaProducer) error {
+	keywords, mutedWords, err := normalizeNotificationKeywords(s.Keywords, s.MutedWords)
+	if err != nil {
+		return NewValidationError(fmt.Sprintf("Error during validation: %v", err))
+	}
+
+	cp := &NotificationKeywordsSetted{
+		AdditionalData: s.AdditionalData,
+		Keywords:       keywords,
+		MutedWords:     mutedWords,
+	}
+	return eventBus.Publish(ctx, cp)
+}
+
 func (sp *MessageCreate) Handle(ctx context.Context, eventBus *KafkaProducer, dba *db.DB, commonProjection *CommonProjection, cfg *config.AppConfig, lgr *logger.LoggerWrapper, policy *sanitizer.SanitizerPolicy, userPermissions []string) (int64, error) {
 	var copyCommand *MessageCreate
 	err := reprint.FromTo(&sp, &copyCommand)
@@ -918,6 +938,18 @@ func (s *MessageRead) Handle(ctx context.Context, lgr *logger.LoggerWrapper, eve
 			lgr.ErrorContext(ctx, "Error during sending to rabbitmq", logger.AttributeError, err)
 		}
 
+		err = rabbitmqNotificationEventsPublisher.Publish(ctx, s.AdditionalData.GetCorrelationId(), dto.NotificationEvent{
+			EventType: dto.EventTypeKeywordDeleted,
+			UserId:    s.AdditionalData.BehalfUserId,
+			ChatId:    s.ChatId,
+			KeywordNotification: &dto.KeywordNotification{
+				Id: s.MessageId,
+			},
+		})
+		if err != nil {
+			lgr.ErrorContext(ctx, "Error during sending to rabbitmq", logger.AttributeError, err)
+		}
+
 		var messageOwnerId = messageBasic.GetOwnerId()
 		if messageOwnerId == s.AdditionalData.BehalfUserId { // only for myself
 			var reactions []string
@@ -191,6 +191,10 @@ func ListenChatTopic(
 		EventChatNotificationSettingsSetted: func(metadata *Metadata, record *kgo.Record) (CqrsEvent, context.Context, trace.Span, error) {
 			return prepareEvent[*ChatNotificationSettingsSetted](p.lgr, p.cfg, metadata, record, p.tracer)
 		},
+		// this event need to be in event-chat topic, because only this topic is backupable
+		EventNotificationKeywordsSetted: func(metadata *Metadata, record *kgo.Record) (CqrsEvent, context.Context, trace.Span, error) {
+			return prepareEvent[*NotificationKeywordsSetted](p.lgr, p.cfg, metadata, record, p.tracer)
+		},
 		EventParticipantsAdded: func(metadata *Metadata, record *kgo.Record) (CqrsEvent, context.Context, trace.Span, error) {
 			return prepareEvent[*ParticipantsAdded](p.lgr, p.cfg, metadata, record, p.tracer)
 		},
@@ -254,6 +258,10 @@ func ListenChatTopic(
 		EventChatNotificationSettingsSetted: func(b BatchEvent) (context.Context, error) {
 			return processEvent(p.lgr, p.cfg, b, unwrapSingleBatch(p.cqrsEventHandler.OnChatNotificationSettingsSetted))
 		},
+		// this event need to be in event-chat topic, because only this topic is backupable
+		EventNotificationKeywordsSetted: func(b BatchEvent) (context.Context, error) {
+			return processEvent(p.lgr, p.cfg, b, unwrapSingleBatch(p.cqrsEventHandler.OnNotificationKeywordsSetted))
+		},
 		EventParticipantsAdded: func(b BatchEvent) (context.Context, error) {
 			return processEvent(p.lgr, p.cfg, b, unwrapSingleBatch(p.cqrsEventHandler.OnParticipantAdded))
 		},
@@ -328,6 +336,9 @@ func ListenUserTopic(
 		EventUserChatNotificationSettingsSetted: func(metadata *Metadata, record *kgo.Record) (CqrsEvent, context.Context, trace.Span, error) {
 			return prepareEvent[*UserChatNotificationSettingsSetted](p.lgr, p.cfg, metadata, record, p.tracer)
 		},
+		EventUserNotificationKeywordsSetted: func(metadata *Metadata, record *kgo.Record) (CqrsEvent, context.Context, trace.Span, error) {
+			return prepareEvent[*UserNotificationKeywordsSetted](p.lgr, p.cfg, metadata, record, p.tracer)
+		},
 		EventUserMessageReaded: func(metadata *Metadata, record *kgo.Record) (CqrsEvent, context.Context, trace.Span, error) {
 			return prepareEvent[*UserMessageReaded](p.lgr, p.cfg, metadata, record, p.tracer)
 		},
@@ -359,6 +370,9 @@ func ListenUserTopic(
 		EventUserChatNotificationSettingsSetted: func(b BatchEvent) (context.Context, error) {
 			return processEvent(p.lgr, p.cfg, b, unwrapSingleBatch(p.cqrsEventHandler.OnUserChatNotificationSettingsSetted))
 		},
+		EventUserNotificationKeywordsSetted: func(b BatchEvent) (context.Context, error) {
+			return processEvent(p.lgr, p.cfg, b, unwrapSingleBatch(p.cqrsEventHandler.OnUserNotificationKeywordsSetted))
+		},
 		EventUserMessageReaded: func(b BatchEvent) (context.Context, error) {
 			return processEvent(p.lgr, p.cfg, b, unwrapSingleBatch(p.cqrsEventHandler.OnUserUnreadMessageReaded))
 		},
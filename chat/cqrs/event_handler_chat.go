@@ -3,6 +3,7 @@ package cqrs
 import (
 	"context"
 	"fmt"
+	"html"
 	"maps"
 	"slices"
 	"time"
@@ -634,6 +635,12 @@ func (m *EventHandler) onMessagesCreated(ctx context.Context, events []MessageCr
 		}
 	}
 
+	// for keywords and muted words
+	messageWords := map[int64][]string{}
+	for _, event := range authorizedMessageEvents {
+		messageWords[event.MessageCommoned.Id] = m.getMessageWords(event.MessageCommoned.Content)
+	}
+
 	if len(authorizedMessageEvents) > 0 {
 		errOuter0 := m.commonProjection.IterateOverChatParticipantIdsExcepting(ctx, m.db, chatId, nil, func(participantIdsPortion []int64) error {
 			userOnlines := m.getMemoizedUserOnlines(ctx, participantIdsPortion, m.aaaRestClient)
@@ -644,6 +651,20 @@ func (m *EventHandler) onMessagesCreated(ctx context.Context, events []MessageCr
 			}
 			allPortionUsersMap := utils.ToMap(allPortionUsers)
 
+			portionKeywords, errInn := m.commonProjection.getNotificationKeywordsForChat(ctx, m.db, chatId, participantIdsPortion)
+			if errInn != nil {
+				return errInn
+			}
+			// the message which contains a muted word doesn't produce any notifications for the participant
+			isMuted := func(participantId, messageId int64) bool {
+				kws, ok := portionKeywords[participantId]
+				if !ok {
+					return false
+				}
+				_, muted := findKeyword(messageWords[messageId], kws.mutedWords)
+				return muted
+			}
+
 			inPortionMessageIds := []int64{}
 			for _, messageView := range messageViews {
 				inPortionMessageIds = append(inPortionMessageIds, messageView.Id)
@@ -678,7 +699,7 @@ func (m *EventHandler) onMessagesCreated(ctx context.Context, events []MessageCr
 					}
 
 					// notification about the new message (red dot)
-					if messageView.BehalfUserId != event.AdditionalData.BehalfUserId { // skip myself
+					if messageView.BehalfUserId != event.AdditionalData.BehalfUserId && !isMuted(messageView.BehalfUserId, messageView.Id) { // skip myself
 						if owner, ok := allPortionUsersMap[messageView.OwnerId]; !ok {
 							m.lgr.InfoContext(ctx, "Message owner isn't found", logger.AttributeUserId, messageView.OwnerId)
 						} else {
@@ -724,6 +745,9 @@ func (m *EventHandler) onMessagesCreated(ctx context.Context, events []MessageCr
 							if participantId == event.AdditionalData.BehalfUserId {
 								continue // skip myself
 							}
+							if isMuted(participantId, event.MessageCommoned.Id) {
+								continue
+							}
 
 							errInn = m.rabbitmqNotificationEventsPublisher.Publish(ctx, event.AdditionalData.GetCorrelationId(), dto.NotificationEvent{
 								EventType: dto.EventTypeMentionAdded,
@@ -748,7 +772,7 @@ func (m *EventHandler) onMessagesCreated(ctx context.Context, events []MessageCr
 						if behalfUserDto == nil {
 							m.lgr.InfoContext(ctx, "Unable to get behalf user for reply notification", logger.AttributeUserId, event.AdditionalData.BehalfUserId)
 						} else {
-							if *newRepliedUserId != event.AdditionalData.BehalfUserId && slices.Contains(participantIdsPortion, *newRepliedUserId) { // skip myself and don't duplicate
+							if *newRepliedUserId != event.AdditionalData.BehalfUserId && slices.Contains(participantIdsPortion, *newRepliedUserId) && !isMuted(*newRepliedUserId, event.MessageCommoned.Id) { // skip myself and don't duplicate
 								err = m.rabbitmqNotificationEventsPublisher.Publish(ctx, event.AdditionalData.GetCorrelationId(), dto.NotificationEvent{
 									EventType: dto.EventTypeReplyAdded,
 									UserId:    *newRepliedUserId,
@@ -769,6 +793,44 @@ func (m *EventHandler) onMessagesCreated(ctx context.Context, events []MessageCr
 							}
 						}
 					}
+
+					if len(portionKeywords) > 0 { // per this MessageCreated in the current chat participants portion
+						if behalfUserDto == nil {
+							m.lgr.InfoContext(ctx, "Unable to get behalf user for keyword notification", logger.AttributeUserId, event.AdditionalData.BehalfUserId)
+						} else {
+							for _, participantId := range participantIdsPortion {
+								if participantId == event.AdditionalData.BehalfUserId || slices.Contains(newToSendMentions, participantId) || (newRepliedUserId != nil && *newRepliedUserId == participantId) {
+									continue // skip myself and don't duplicate the mention or the reply
+								}
+								kws, ok := portionKeywords[participantId]
+								if !ok || isMuted(participantId, event.MessageCommoned.Id) {
+									continue
+								}
+								keyword, found := findKeyword(messageWords[event.MessageCommoned.Id], kws.keywords)
+								if !found {
+									continue
+								}
+
+								errInn = m.rabbitmqNotificationEventsPublisher.Publish(ctx, event.AdditionalData.GetCorrelationId(), dto.NotificationEvent{
+									EventType: dto.EventTypeKeywordAdded,
+									UserId:    participantId,
+									ChatId:    event.MessageCommoned.ChatId,
+									KeywordNotification: &dto.KeywordNotification{
+										Id:      event.MessageCommoned.Id,
+										Text:    newWithoutAnyHtml,
+										Keyword: keyword,
+									},
+									ByUserId:  behalfUserDto.Id,
+									ByLogin:   behalfUserDto.Login,
+									ByAvatar:  behalfUserDto.Avatar,
+									ChatTitle: chatNotificationTitle,
+								})
+								if errInn != nil {
+									m.lgr.ErrorContext(ctx, "Error during sending to rabbitmq", logger.AttributeError, errInn)
+								}
+							}
+						}
+					}
 				}
 			}
 
@@ -1230,6 +1292,12 @@ func (m *EventHandler) getNotificationData(ctx context.Context, messageHtml stri
 	return newMentionedUserIds, newHasHere, newHasAll, newWithoutAnyHtml, repliedUserId
 }
 
+// the words of the message, the quotes and the code are skipped in order not to trigger keywords on them
+func (m *EventHandler) getMessageWords(messageHtml string) []string {
+	withoutSourceTags := m.stripSourceContent.Sanitize(messageHtml)
+	return splitToWords(html.UnescapeString(m.stripAllTags.Sanitize(withoutSourceTags)))
+}
+
 func (m *EventHandler) OnMessageRemoved(ctx context.Context, event *MessageDeleted) error {
 	eventType := dto.EventTypeMessageDeleted
 
@@ -1348,6 +1416,18 @@ func (m *EventHandler) OnMessageRemoved(ctx context.Context, event *MessageDelet
 				m.lgr.ErrorContext(ctx, "Error during sending to rabbitmq", logger.AttributeError, err)
 			}
 
+			err = m.rabbitmqNotificationEventsPublisher.Publish(ctx, event.AdditionalData.GetCorrelationId(), dto.NotificationEvent{
+				EventType: dto.EventTypeKeywordDeleted,
+				UserId:    participantId,
+				ChatId:    event.ChatId,
+				KeywordNotification: &dto.KeywordNotification{
+					Id: event.MessageId,
+				},
+			})
+			if err != nil {
+				m.lgr.ErrorContext(ctx, "Error during sending to rabbitmq", logger.AttributeError, err)
+			}
+
 			for _, reaction := range reactions {
 				var messageOwnerId = messageBasic.GetOwnerId()
 				if messageOwnerId == dto.NoOwner || messageOwnerId == dto.NoId {
@@ -1995,6 +2075,14 @@ func (m *EventHandler) OnChatNotificationSettingsSetted(ctx context.Context, eve
 	})
 }
 
+func (m *EventHandler) OnNotificationKeywordsSetted(ctx context.Context, event *NotificationKeywordsSetted) error {
+	return m.eventBus.Publish(ctx, &UserNotificationKeywordsSetted{
+		AdditionalData: event.AdditionalData,
+		Keywords:       event.Keywords,
+		MutedWords:     event.MutedWords,
+	})
+}
+
 func (m *EventHandler) OnUnreadMessageReaded(ctx context.Context, event *MessageReaded) error {
 	err := m.commonProjection.OnChatUnreadMessageReaded(ctx, event)
 	if err != nil {
@@ -346,6 +346,10 @@ func (m *EventHandler) OnUserChatNotificationSettingsSetted(ctx context.Context,
 	return nil
 }
 
+func (m *EventHandler) OnUserNotificationKeywordsSetted(ctx context.Context, event *UserNotificationKeywordsSetted) error {
+	return m.commonProjection.OnNotificationKeywordsSetted(ctx, event)
+}
+
 func (m *EventHandler) OnUserMessagesCreated(ctx context.Context, event *UserMessagesCreated) error {
 	eventTypeChatUnreadMessagesChanged := dto.EventTypeChatUnreadMessagesChanged
 
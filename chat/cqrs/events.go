@@ -23,6 +23,8 @@ const (
 	EventChatPinned                         = "chatPinned"
 	EventUserChatNotificationSettingsSetted = "userChatNotificationSettingsSetted"
 	EventChatNotificationSettingsSetted     = "chatNotificationSettingsSetted"
+	EventUserNotificationKeywordsSetted     = "userNotificationKeywordsSetted"
+	EventNotificationKeywordsSetted         = "notificationKeywordsSetted"
 	EventMessageCreated                     = "messageCreated"
 	EventMessageEdited                      = "messageEdited"
 	EventUserMessageReaded                  = "userMessageReaded"
@@ -187,6 +189,18 @@ type ChatNotificationSettingsSetted struct {
 	Setted         bool            `json:"setted"`
 }
 
+type NotificationKeywordsSetted struct {
+	AdditionalData *AdditionalData              `json:"additionalData"`
+	Keywords       []dto.NotificationKeywordDto `json:"keywords"`
+	MutedWords     []string                     `json:"mutedWords"`
+}
+
+type UserNotificationKeywordsSetted struct {
+	AdditionalData *AdditionalData              `json:"additionalData"`
+	Keywords       []dto.NotificationKeywordDto `json:"keywords"`
+	MutedWords     []string                     `json:"mutedWords"`
+}
+
 type MessageCommoned struct {
 	Id           int64   `json:"id"` // message id
 	ChatId       int64   `json:"chatId"`
@@ -480,6 +494,14 @@ func (s *ChatNotificationSettingsSetted) GetPartitionKey() string {
 	return utils.ToString(s.ChatId)
 }
 
+func (s *NotificationKeywordsSetted) GetPartitionKey() string {
+	return utils.ToString(s.AdditionalData.BehalfUserId)
+}
+
+func (s *UserNotificationKeywordsSetted) GetPartitionKey() string {
+	return utils.ToString(s.AdditionalData.BehalfUserId)
+}
+
 func (s *MessageCreated) GetPartitionKey() string {
 	return utils.ToString(s.MessageCommoned.ChatId)
 }
@@ -588,6 +610,14 @@ func (s *ChatNotificationSettingsSetted) GetEventType() string {
 	return EventChatNotificationSettingsSetted
 }
 
+func (s *NotificationKeywordsSetted) GetEventType() string {
+	return EventNotificationKeywordsSetted
+}
+
+func (s *UserNotificationKeywordsSetted) GetEventType() string {
+	return EventUserNotificationKeywordsSetted
+}
+
 func (s *MessageCreated) GetEventType() string {
 	return EventMessageCreated
 }
@@ -696,6 +726,14 @@ func (s *ChatNotificationSettingsSetted) GetEventTopic() EventTopic {
 	return EventTopicChat
 }
 
+func (s *NotificationKeywordsSetted) GetEventTopic() EventTopic {
+	return EventTopicChat
+}
+
+func (s *UserNotificationKeywordsSetted) GetEventTopic() EventTopic {
+	return EventTopicUser
+}
+
 func (s *MessageCreated) GetEventTopic() EventTopic {
 	return EventTopicChat
 }
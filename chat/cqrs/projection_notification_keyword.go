@@ -0,0 +1,220 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"nkonev.name/chat/db"
+	"nkonev.name/chat/dto"
+	"nkonev.name/chat/logger"
+
+	"github.com/georgysavva/scany/v2/sqlscan"
+)
+
+const maxNotificationKeywords = 100
+const maxNotificationMutedWords = 100
+const maxNotificationKeywordLen = 256
+
+type notificationKeyword struct {
+	keyword string
+	words   []string
+}
+
+type notificationKeywordsForUser struct {
+	keywords   []notificationKeyword
+	mutedWords []notificationKeyword
+}
+
+// returns the keywords and the muted words of the participants which are applicable to the chat
+func (m *CommonProjection) getNotificationKeywordsForChat(ctx context.Context, co db.CommonOperations, chatId int64, participantIds []int64) (map[int64]*notificationKeywordsForUser, error) {
+	type keywordDto struct {
+		UserId  int64  `db:"user_id"`
+		Keyword string `db:"keyword"`
+		Muted   bool   `db:"muted"`
+	}
+
+	list := []keywordDto{}
+	err := sqlscan.Select(ctx, co, &list, `
+		select user_id, keyword, false as muted from notification_keyword where user_id = any($1) and (chat_id is null or chat_id = $2)
+		union all
+		select user_id, word, true as muted from notification_muted_word where user_id = any($1)
+	`, participantIds, chatId)
+	if err != nil {
+		return nil, fmt.Errorf("error during interacting with db: %w", err)
+	}
+
+	res := map[int64]*notificationKeywordsForUser{}
+	for _, kw := range list {
+		forUser, ok := res[kw.UserId]
+		if !ok {
+			forUser = &notificationKeywordsForUser{}
+			res[kw.UserId] = forUser
+		}
+		if kw.Muted {
+			forUser.mutedWords = append(forUser.mutedWords, notificationKeyword{kw.Keyword, splitToWords(kw.Keyword)})
+		} else {
+			forUser.keywords = append(forUser.keywords, notificationKeyword{kw.Keyword, splitToWords(kw.Keyword)})
+		}
+	}
+	return res, nil
+}
+
+func (m *CommonProjection) OnNotificationKeywordsSetted(ctx context.Context, event *UserNotificationKeywordsSetted) error {
+	errOuter := db.Transact(ctx, m.db, func(tx *db.Tx) error {
+		userId := event.AdditionalData.BehalfUserId
+
+		_, err := tx.ExecContext(ctx, "delete from notification_keyword where user_id = $1", userId)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "delete from notification_muted_word where user_id = $1", userId)
+		if err != nil {
+			return err
+		}
+
+		for _, kw := range event.Keywords {
+			_, err = tx.ExecContext(ctx, "insert into notification_keyword(user_id, keyword, chat_id) values ($1, $2, $3)", userId, kw.Keyword, kw.ChatId)
+			if err != nil {
+				return err
+			}
+		}
+		for _, word := range event.MutedWords {
+			_, err = tx.ExecContext(ctx, "insert into notification_muted_word(user_id, word) values ($1, $2) on conflict do nothing", userId, word)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errOuter != nil {
+		return errOuter
+	}
+
+	m.lgr.InfoContext(ctx,
+		"Notification keywords setted",
+		logger.AttributeUserId, event.AdditionalData.BehalfUserId,
+		"keywords", len(event.Keywords),
+		"muted_words", len(event.MutedWords),
+	)
+
+	return nil
+}
+
+func (m *CommonProjection) GetNotificationKeywords(ctx context.Context, userId int64) (*dto.NotificationKeywordsDto, error) {
+	res := dto.NotificationKeywordsDto{
+		Keywords:   []dto.NotificationKeywordDto{},
+		MutedWords: []string{},
+	}
+
+	type keywordDto struct {
+		Keyword string `db:"keyword"`
+		ChatId  *int64 `db:"chat_id"`
+	}
+	keywords := []keywordDto{}
+	err := sqlscan.Select(ctx, m.db, &keywords, "select keyword, chat_id from notification_keyword where user_id = $1 order by keyword, chat_id nulls first", userId)
+	if err != nil {
+		return nil, fmt.Errorf("error during interacting with db: %w", err)
+	}
+	for _, kw := range keywords {
+		res.Keywords = append(res.Keywords, dto.NotificationKeywordDto{
+			Keyword: kw.Keyword,
+			ChatId:  kw.ChatId,
+		})
+	}
+
+	err = sqlscan.Select(ctx, m.db, &res.MutedWords, "select word from notification_muted_word where user_id = $1 order by word", userId)
+	if err != nil {
+		return nil, fmt.Errorf("error during interacting with db: %w", err)
+	}
+
+	return &res, nil
+}
+
+// trims, lowercases and deduplicates the keywords
+func normalizeNotificationKeywords(keywords []dto.NotificationKeywordDto, mutedWords []string) ([]dto.NotificationKeywordDto, []string, error) {
+	if len(keywords) > maxNotificationKeywords {
+		return nil, nil, fmt.Errorf("max allowed keywords %d, got %d", maxNotificationKeywords, len(keywords))
+	}
+	if len(mutedWords) > maxNotificationMutedWords {
+		return nil, nil, fmt.Errorf("max allowed muted words %d, got %d", maxNotificationMutedWords, len(mutedWords))
+	}
+
+	resKeywords := []dto.NotificationKeywordDto{}
+	for _, kw := range keywords {
+		normalized, err := normalizeNotificationKeyword(kw.Keyword)
+		if err != nil {
+			return nil, nil, err
+		}
+		if normalized == "" {
+			continue
+		}
+		item := dto.NotificationKeywordDto{
+			Keyword: normalized,
+			ChatId:  kw.ChatId,
+		}
+		if !slices.ContainsFunc(resKeywords, func(e dto.NotificationKeywordDto) bool {
+			return e.Keyword == item.Keyword && ((e.ChatId == nil && item.ChatId == nil) || (e.ChatId != nil && item.ChatId != nil && *e.ChatId == *item.ChatId))
+		}) {
+			resKeywords = append(resKeywords, item)
+		}
+	}
+
+	resMutedWords := []string{}
+	for _, word := range mutedWords {
+		normalized, err := normalizeNotificationKeyword(word)
+		if err != nil {
+			return nil, nil, err
+		}
+		if normalized != "" && !slices.Contains(resMutedWords, normalized) {
+			resMutedWords = append(resMutedWords, normalized)
+		}
+	}
+
+	return resKeywords, resMutedWords, nil
+}
+
+func normalizeNotificationKeyword(keyword string) (string, error) {
+	normalized := strings.Join(strings.Fields(strings.ToLower(keyword)), " ")
+	if normalized == "" {
+		return "", nil
+	}
+	if len([]rune(normalized)) > maxNotificationKeywordLen {
+		return "", fmt.Errorf("keyword is longer than %d", maxNotificationKeywordLen)
+	}
+	if len(splitToWords(normalized)) == 0 {
+		return "", errors.New("keyword should contain letters or digits")
+	}
+	return normalized, nil
+}
+
+func splitToWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// returns the first keyword which is presented in the text as the whole words
+func findKeyword(textWords []string, keywords []notificationKeyword) (string, bool) {
+	for _, kw := range keywords {
+		if containsWords(textWords, kw.words) {
+			return kw.keyword, true
+		}
+	}
+	return "", false
+}
+
+func containsWords(textWords []string, keywordWords []string) bool {
+	if len(keywordWords) == 0 {
+		return false
+	}
+	for i := 0; i+len(keywordWords) <= len(textWords); i++ {
+		if slices.Equal(textWords[i:i+len(keywordWords)], keywordWords) {
+			return true
+		}
+	}
+	return false
+}
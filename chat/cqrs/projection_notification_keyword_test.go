@@ -0,0 +1,65 @@
+package cqrs
+
+import (
+	"testing"
+
+	"nkonev.name/chat/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeNotificationKeywords(t *testing.T) {
+	var chatId int64 = 1
+
+	keywords, mutedWords, err := normalizeNotificationKeywords([]dto.NotificationKeywordDto{
+		{Keyword: " Outage "},
+		{Keyword: "outage"},
+		{Keyword: "outage", ChatId: &chatId},
+		{Keyword: "Payment   Service"},
+		{Keyword: "  "},
+	}, []string{"Standup", "standup ", ""})
+	require.NoError(t, err)
+
+	assert.Equal(t, []dto.NotificationKeywordDto{
+		{Keyword: "outage"},
+		{Keyword: "outage", ChatId: &chatId},
+		{Keyword: "payment service"},
+	}, keywords)
+	assert.Equal(t, []string{"standup"}, mutedWords)
+
+	_, _, err = normalizeNotificationKeywords([]dto.NotificationKeywordDto{{Keyword: "!!!"}}, nil)
+	assert.Error(t, err)
+}
+
+func TestFindKeyword(t *testing.T) {
+	keywords := []notificationKeyword{
+		{"outage", splitToWords("outage")},
+		{"payment service", splitToWords("payment service")},
+		{"api-gateway", splitToWords("api-gateway")},
+	}
+
+	testCases := []struct {
+		text    string
+		keyword string
+		found   bool
+	}{
+		{"We have an OUTAGE, folks!", "outage", true},
+		{"There are outages", "", false},
+		{"the payment service is slow", "payment service", true},
+		{"the payment is slow, service is fine", "", false},
+		{"restart the api gateway", "api-gateway", true},
+		{"сбой платежей", "", false},
+		{"", "", false},
+	}
+
+	for _, tc := range testCases {
+		keyword, found := findKeyword(splitToWords(tc.text), keywords)
+		assert.Equal(t, tc.found, found, tc.text)
+		assert.Equal(t, tc.keyword, keyword, tc.text)
+	}
+
+	keyword, found := findKeyword(splitToWords("Сбой в Платежах"), []notificationKeyword{{"сбой", splitToWords("сбой")}})
+	assert.True(t, found)
+	assert.Equal(t, "сбой", keyword)
+}
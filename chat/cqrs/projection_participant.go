@@ -348,9 +348,12 @@ func (m *EnrichingProjection) GetParticipantsEnriched(ctx context.Context, behal
 
 		enrichedUsers := makeEnrichedUsers(pwc.usersWithAdmin, behalfUserId, pwc.areAdminsOfUserIds[behalfUserId], pwc.chat.TetATet)
 
-		return map[int64][]*dto.UserViewEnrichedDto{
+		res := map[int64][]*dto.UserViewEnrichedDto{
 			behalfUserId: enrichedUsers,
-		}, pwc.count, nil
+		}
+		m.enrichWithPresences(ctx, res)
+
+		return res, pwc.count, nil
 	} else {
 		pwc, errOuter := db.TransactWithResult(ctx, m.cp.db, func(tx *db.Tx) (*participantsWithCount, error) {
 			var participants []*ParticipantWithAdmin
@@ -422,6 +425,7 @@ func (m *EnrichingProjection) GetParticipantsEnriched(ctx context.Context, behal
 			enrichedUsersBehalfUser := makeEnrichedUsers(orderedEnrichedParticipants, behalfUserId, pwc.areAdminsOfUserIds[behalfUserId], pwc.chat.TetATet)
 			res[behalfUserId] = enrichedUsersBehalfUser
 		}
+		m.enrichWithPresences(ctx, res)
 
 		return res, pwc.count, nil
 	}
@@ -442,6 +446,31 @@ func makeEnrichedUsers(users []*dto.UserWithAdmin, behalfUserId int64, behalfIsC
 	return res
 }
 
+// the participants are shown without the presence in case it can't be read
+func (m *EnrichingProjection) enrichWithPresences(ctx context.Context, usersByBehalfs map[int64][]*dto.UserViewEnrichedDto) {
+	var usersSet = map[int64]bool{}
+	for _, users := range usersByBehalfs {
+		for _, u := range users {
+			usersSet[u.Id] = true
+		}
+	}
+	if len(usersSet) == 0 {
+		return
+	}
+
+	presences, err := m.cp.getUserPresences(ctx, m.cp.db, utils.SetMapIdBoolToSlice(usersSet), time.Now().UTC())
+	if err != nil {
+		m.lgr.WarnContext(ctx, "unable to get presences", logger.AttributeError, err)
+		return
+	}
+
+	for _, users := range usersByBehalfs {
+		for _, u := range users {
+			u.Presence = presences[u.Id]
+		}
+	}
+}
+
 func (m *EnrichingProjection) SearchUsersContaining(ctx context.Context, co db.CommonOperations, searchString string, chatId int64, pageSize int32, requestOffset int64, reverse bool, needCount bool) ([]*dto.UserWithAdmin, int64, error) {
 	searchString = m.SanitizeSearchString(searchString)
 
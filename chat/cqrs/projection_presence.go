@@ -0,0 +1,61 @@
+package cqrs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"nkonev.name/chat/db"
+	"nkonev.name/chat/dto"
+
+	"github.com/georgysavva/scany/v2/sqlscan"
+)
+
+// the presences are projected from the event service, so reading them doesn't depend on its availability
+func (m *CommonProjection) OnUserPresencesChanged(ctx context.Context, presences []dto.UserPresence) error {
+	return db.Transact(ctx, m.db, func(tx *db.Tx) error {
+		for _, p := range presences {
+			if p.IsEmpty() {
+				_, err := tx.ExecContext(ctx, "delete from user_presence where user_id = $1", p.UserId)
+				if err != nil {
+					return fmt.Errorf("error during interacting with db: %w", err)
+				}
+				continue
+			}
+			var expiresAt *time.Time
+			if p.ExpiresAt != nil {
+				utc := p.ExpiresAt.UTC()
+				expiresAt = &utc
+			}
+			_, err := tx.ExecContext(ctx, `
+				insert into user_presence(user_id, status, emoji, text, expires_at, idle) values ($1, $2, $3, $4, $5, $6)
+				on conflict(user_id) do update set status = excluded.status, emoji = excluded.emoji, text = excluded.text, expires_at = excluded.expires_at, idle = excluded.idle
+			`, p.UserId, p.Status, p.Emoji, p.Text, expiresAt, p.Idle)
+			if err != nil {
+				return fmt.Errorf("error during interacting with db: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// the expired status isn't returned even if the event service hasn't sent the change yet
+func (m *CommonProjection) getUserPresences(ctx context.Context, co db.CommonOperations, userIds []int64, now time.Time) (map[int64]*dto.UserPresence, error) {
+	list := []*dto.UserPresence{}
+	err := sqlscan.Select(ctx, co, &list, `select user_id, status, emoji, text, expires_at, idle from user_presence where user_id = any($1)`, userIds)
+	if err != nil {
+		return nil, fmt.Errorf("error during interacting with db: %w", err)
+	}
+
+	res := make(map[int64]*dto.UserPresence, len(list))
+	for _, p := range list {
+		if p.ExpiresAt != nil && !p.ExpiresAt.After(now) {
+			p.Status = nil
+			p.Emoji = nil
+			p.Text = nil
+			p.ExpiresAt = nil
+		}
+		res[p.UserId] = p
+	}
+	return res, nil
+}
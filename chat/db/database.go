@@ -293,6 +293,9 @@ func (db *DB) Reset(mc config.MigrationConfig, hard bool) error {
 	drop table if exists message;
 	drop table if exists chat_user_view;
 	drop table if exists has_unread_messages;
+	drop table if exists notification_keyword;
+	drop table if exists notification_muted_word;
+	drop table if exists user_presence;
 	
 	%s
 
@@ -0,0 +1,14 @@
+create unlogged table notification_keyword(
+    user_id bigint not null,
+    keyword varchar(256) not null,
+    chat_id bigint -- null means all the chats
+);
+create index notification_keyword_user_id_idx on notification_keyword(user_id);
+SELECT create_distributed_table('notification_keyword', 'user_id');
+
+create unlogged table notification_muted_word(
+    user_id bigint not null,
+    word varchar(256) not null,
+    primary key (user_id, word)
+);
+SELECT create_distributed_table('notification_muted_word', 'user_id');
@@ -0,0 +1,10 @@
+-- the projection of the presences from the event service
+create unlogged table user_presence(
+    user_id bigint primary key,
+    status varchar(16),
+    emoji varchar(64),
+    text varchar(512),
+    expires_at timestamp,
+    idle boolean not null default false
+);
+SELECT create_distributed_table('user_presence', 'user_id');
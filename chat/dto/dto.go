@@ -16,6 +16,16 @@ type PutChatNotificationSettingsDto struct {
 	ConsiderMessagesOfThisChatAsUnread bool `json:"considerMessagesOfThisChatAsUnread"`
 }
 
+type NotificationKeywordDto struct {
+	Keyword string `json:"keyword"`
+	ChatId  *int64 `json:"chatId"` // nil means all the chats
+}
+
+type NotificationKeywordsDto struct {
+	Keywords   []NotificationKeywordDto `json:"keywords"`
+	MutedWords []string                 `json:"mutedWords"`
+}
+
 type SearchUsersRequestDto struct {
 	Page         int64   `json:"page"`
 	Size         int32   `json:"size"`
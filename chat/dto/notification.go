@@ -5,6 +5,12 @@ type MentionNotification struct {
 	Text string `json:"text"`
 }
 
+type KeywordNotification struct {
+	Id      int64  `json:"id"` // message id
+	Text    string `json:"text"`
+	Keyword string `json:"keyword"` // the matched one
+}
+
 type ReactionEvent struct {
 	UserId    int64  `json:"userId"` // who gave this reaction
 	Reaction  string `json:"reaction"`
@@ -28,4 +34,5 @@ type NotificationEvent struct {
 	MentionNotification *MentionNotification `json:"mentionNotification"`
 	ReplyNotification   *ReplyDto            `json:"replyNotification"`
 	ReactionEvent       *ReactionEvent       `json:"reactionEvent"`
+	KeywordNotification *KeywordNotification `json:"keywordNotification"`
 }
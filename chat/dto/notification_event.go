@@ -10,3 +10,6 @@ const EventTypeReactionDeleted = "reaction_notification_removed"
 
 const EventTypeMessageBrowserNotificationAdd = "browser_notification_add_message"
 const EventTypeMessageBrowserNotificationDelete = "browser_notification_remove_message"
+
+const EventTypeKeywordAdded = "keyword_added"
+const EventTypeKeywordDeleted = "keyword_deleted"
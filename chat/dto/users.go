@@ -53,9 +53,10 @@ type UserWithAdmin struct {
 
 type UserViewEnrichedDto struct {
 	UserWithAdmin
-	BehalfUserId int64 `json:"-"` // behalf userId
-	CanChange    bool  `json:"canChange"`
-	CanDelete    bool  `json:"canDelete"`
+	BehalfUserId int64         `json:"-"` // behalf userId
+	CanChange    bool          `json:"canChange"`
+	CanDelete    bool          `json:"canDelete"`
+	Presence     *UserPresence `json:"presence"`
 }
 
 type AdditionalData struct {
@@ -75,6 +76,30 @@ func (u UserOnline) GetId() int64 {
 	return u.Id
 }
 
+// the status set by the user and the idle derived from the activity, they are stored in the event service and projected here
+type UserPresence struct {
+	UserId    int64      `json:"userId" db:"user_id"`
+	Status    *string    `json:"status" db:"status"` // away, busy, dnd; nil is available
+	Emoji     *string    `json:"emoji" db:"emoji"`
+	Text      *string    `json:"text" db:"text"`
+	ExpiresAt *time.Time `json:"expiresAt" db:"expires_at"`
+	Idle      bool       `json:"idle" db:"idle"`
+}
+
+func (u *UserPresence) GetId() int64 {
+	return u.UserId
+}
+
+// the user is available and active, so there is nothing to store
+func (u *UserPresence) IsEmpty() bool {
+	return u.Status == nil && u.Emoji == nil && u.Text == nil && !u.Idle
+}
+
+// is sent by the event service on every change
+type UserPresencesChanged struct {
+	UserPresences []UserPresence `json:"userPresences"`
+}
+
 type ParticipantAddDto struct {
 	ParticipantIds []int64 `json:"addParticipantIds"`
 }
@@ -342,6 +342,60 @@ func (ch *ChatHandler) GetUserChatNotificationSettings(g *gin.Context) {
 	g.JSON(http.StatusOK, cns)
 }
 
+func (ch *ChatHandler) PutNotificationKeywords(g *gin.Context) {
+	req := dto.NotificationKeywordsDto{}
+	err := g.Bind(&req)
+	if err != nil {
+		ch.lgr.ErrorContext(g.Request.Context(), "Error binding NotificationKeywordsDto", logger.AttributeError, err)
+		g.Status(http.StatusInternalServerError)
+		return
+	}
+
+	userId, err := getUserId(g)
+	if err != nil {
+		ch.lgr.ErrorContext(g.Request.Context(), "Error parsing UserId", logger.AttributeError, err)
+		g.Status(http.StatusInternalServerError)
+		return
+	}
+
+	cc := cqrs.NotificationKeywordsSet{
+		AdditionalData: cqrs.GenerateMessageAdditionalData(getCorrelationId(g), userId),
+		Keywords:       req.Keywords,
+		MutedWords:     req.MutedWords,
+	}
+
+	err = cc.Handle(g.Request.Context(), ch.eventBus)
+	if err != nil {
+		if translateChatError(g, err) {
+			return
+		}
+
+		ch.lgr.ErrorContext(g.Request.Context(), "Error sending NotificationKeywordsSet command", logger.AttributeError, err)
+		g.Status(http.StatusInternalServerError)
+		return
+	}
+
+	g.Status(http.StatusOK)
+}
+
+func (ch *ChatHandler) GetNotificationKeywords(g *gin.Context) {
+	userId, err := getUserId(g)
+	if err != nil {
+		ch.lgr.ErrorContext(g.Request.Context(), "Error parsing UserId", logger.AttributeError, err)
+		g.Status(http.StatusInternalServerError)
+		return
+	}
+
+	kws, err := ch.commonProjection.GetNotificationKeywords(g.Request.Context(), userId)
+	if err != nil {
+		ch.lgr.ErrorContext(g.Request.Context(), "Error getting notification keywords", logger.AttributeError, err)
+		g.Status(http.StatusInternalServerError)
+		return
+	}
+
+	g.JSON(http.StatusOK, kws)
+}
+
 func (ch *ChatHandler) HasNewMessages(g *gin.Context) {
 	userId, err := getUserId(g)
 	if err != nil {
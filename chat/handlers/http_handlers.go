@@ -60,6 +60,8 @@ func CreateHttpRouter(
 
 	ginRouter.PUT("/api/chat/:id/notification", chatHandler.PutUserChatNotificationSettings)
 	ginRouter.GET("/api/chat/:id/notification", chatHandler.GetUserChatNotificationSettings)
+	ginRouter.PUT("/api/chat/notification/keywords", chatHandler.PutNotificationKeywords)
+	ginRouter.GET("/api/chat/notification/keywords", chatHandler.GetNotificationKeywords)
 	ginRouter.GET("/api/chat/has-new-messages", chatHandler.HasNewMessages)
 
 	ginRouter.PUT("/api/chat/:id/participant", participantHandler.AddParticipant)
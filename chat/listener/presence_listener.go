@@ -0,0 +1,57 @@
+package listener
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+	"nkonev.name/chat/config"
+	"nkonev.name/chat/cqrs"
+	"nkonev.name/chat/dto"
+	"nkonev.name/chat/logger"
+	"nkonev.name/chat/rabbitmq"
+	"nkonev.name/chat/type_registry"
+)
+
+type PresenceListener func(*amqp.Delivery) error
+
+func CreatePresenceListener(lgr *logger.LoggerWrapper, cfg *config.AppConfig, commonProjection *cqrs.CommonProjection, typeRegistry *type_registry.TypeRegistryInstance) PresenceListener {
+	tr := otel.Tracer("amqp/listener")
+
+	return func(msg *amqp.Delivery) error {
+		ctx := rabbitmq.ExtractAMQPHeaders(context.Background(), msg.Headers)
+		ctx, span := tr.Start(ctx, "presence.listener")
+		defer span.End()
+
+		bytesData := msg.Body
+		aType := msg.Type
+
+		listenerName := "presence"
+
+		if cfg.RabbitMQ.Dump {
+			strData := string(bytesData)
+
+			if cfg.RabbitMQ.PrettyLog && !cfg.Logger.Json {
+				fmt.Printf("[rabbitmq listener] Received message: listener=%s, trace_id=%s, headers=%v, type=%v, body: %v\n", listenerName, logger.GetTraceId(ctx), msg.Headers, aType, strData)
+			} else {
+				lgr.InfoContext(ctx, fmt.Sprintf("[rabbitmq listener] Received message: listener=%s, trace_id=%s, headers=%v, type=%v, body: %v\n", listenerName, logger.GetTraceId(ctx), msg.Headers, aType, strData))
+			}
+		}
+
+		if aType != typeRegistry.GetType(dto.UserPresencesChanged{}) {
+			lgr.InfoContext(ctx, "Unexpected type in rabbit presence_listener", "type", aType)
+			return nil
+		}
+
+		var bindTo dto.UserPresencesChanged
+		err := json.Unmarshal(bytesData, &bindTo)
+		if err != nil {
+			lgr.ErrorContext(ctx, "Error during deserialize presences", logger.AttributeError, err)
+			return err
+		}
+
+		return commonProjection.OnUserPresencesChanged(ctx, bindTo.UserPresences)
+	}
+}
@@ -15,6 +15,7 @@ import (
 const testOutputQueueName = "chat-output-event-test"
 const testNotificationQueueName = "chat-notification-event-test"
 const aaaEventsQueue = "chat-aaa-profile-events"
+const presenceEventsQueue = "chat-presence-events"
 
 type ChatChannel struct{ *rabbitmq.Channel }
 type InternalEventChannel struct{ *rabbitmq.Channel }
@@ -22,6 +23,8 @@ type InternalEventChannel struct{ *rabbitmq.Channel }
 type AaaEventsChannel struct{ *rabbitmq.Channel }
 type AaaEventsQueue struct{ *amqp.Queue }
 
+type PresenceEventsChannel struct{ *rabbitmq.Channel }
+
 func create(lgr *logger.LoggerWrapper, name string, consumeCh *rabbitmq.Channel) (*amqp.Queue, error) {
 	var err error
 	var q amqp.Queue
@@ -242,6 +245,45 @@ func CreateAndListenAaaChannel(
 	return &AaaEventsChannel{ch}, nil
 }
 
+func CreateAndListenPresenceChannel(
+	lgr *logger.LoggerWrapper,
+	connection *rabbitmq.Connection,
+	onMessage PresenceListener,
+	lc fx.Lifecycle,
+	sh fx.Shutdowner,
+) (*PresenceEventsChannel, error) {
+	ch, err := myRabbit.CreateRabbitMqChannelWithCallback(
+		lgr,
+		connection,
+		func(channel *rabbitmq.Channel) error {
+			lc.Append(fx.Hook{
+				OnStop: func(ctx context.Context) error {
+					lgr.Info("Stopping queue listening", "queue", presenceEventsQueue)
+					return channel.Close()
+				},
+			})
+
+			err := channel.ExchangeDeclare(producer.PresenceEventsExchange, "fanout", true, false, false, false, nil)
+			if err != nil {
+				return err
+			}
+
+			aQueue, err := createAndBind(lgr, presenceEventsQueue, "", producer.PresenceEventsExchange, channel)
+			if err != nil {
+				return err
+			}
+
+			listen(lgr, channel, aQueue, onMessage, sh)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresenceEventsChannel{ch}, nil
+}
+
 func listen(
 	lgr *logger.LoggerWrapper,
 	channel *rabbitmq.Channel,
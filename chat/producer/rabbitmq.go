@@ -20,6 +20,7 @@ const EventsFanoutExchange = "async-events-exchange"
 const NotificationsFanoutExchange = "notifications-exchange"
 const ChatInternalExchange = "chat-internal-exchange"
 const AaaEventsExchange = "aaa-profile-events-exchange"
+const PresenceEventsExchange = "presence-events-exchange"
 const correlationIdName = "correlationId"
 
 func (rp *RabbitOutputEventsPublisher) Publish(ctx context.Context, correlationId *string, aDto interface{}) error {
@@ -20,6 +20,7 @@ func NewTypeRegistryInstance() *TypeRegistryInstance {
 	// input events
 	res.AddToRegistryIfNeed(dto.UserAccountEventCreated{})
 	res.AddToRegistryIfNeed(dto.UserAccountEventChanged{})
+	res.AddToRegistryIfNeed(dto.UserPresencesChanged{})
 
 	// output events
 	res.AddToRegistryIfNeed(dto.GlobalUserEvent{})
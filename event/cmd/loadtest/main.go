@@ -0,0 +1,233 @@
+package main
+
+// simulates the thousands of chatEvents subscribers, the part of them are slow,
+// in order to check that the slow ones don't affect the others and to see the subscription metrics.
+// go run ./cmd/loadtest -subscribers 5000 -slow 0.05 -policy disconnect
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/montag451/go-eventbus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"nkonev.name/event/auth"
+	"nkonev.name/event/client"
+	"nkonev.name/event/config"
+	"nkonev.name/event/dto"
+	"nkonev.name/event/graph"
+	"nkonev.name/event/graph/model"
+	"nkonev.name/event/logger"
+	"nkonev.name/event/subscription"
+	"nkonev.name/event/utils"
+)
+
+var eventTypes = []string{"message_created", "message_edited", "user_typing", "message_reaction_changed"}
+
+type stats struct {
+	published    atomic.Int64
+	received     atomic.Int64
+	resyncs      atomic.Int64
+	closed       atomic.Int64
+	latencySum   atomic.Int64 // of the fast subscribers
+	latencyCount atomic.Int64
+	latencyMax   atomic.Int64
+}
+
+func main() {
+	subscribers := flag.Int("subscribers", 5000, "The number of the subscribers")
+	chats := flag.Int("chats", 100, "The number of the chats the subscribers are distributed among")
+	slowFraction := flag.Float64("slow", 0.02, "The fraction of the slow subscribers")
+	slowDelay := flag.Duration("slowDelay", 500*time.Millisecond, "The delay of the slow subscriber before reading the next event")
+	rate := flag.Int("rate", 200, "The number of the published chat events per second, each one is delivered to every subscriber of the chat")
+	duration := flag.Duration("duration", 30*time.Second, "The duration of the publishing")
+	policy := flag.String("policy", "", "Overrides subscription.chatEvents.overflowPolicy")
+	queueSize := flag.Int("queueSize", 0, "Overrides subscription.chatEvents.queueSize")
+
+	config.InitViper()
+	viper.Set("logger.writeToFile", false)
+	viper.Set("logger.level", "error")
+	viper.Set("http.maxIdleConns", 100)
+	if *policy != "" {
+		viper.Set("subscription."+subscription.ChatEvents+".overflowPolicy", *policy)
+	}
+	if *queueSize > 0 {
+		viper.Set("subscription."+subscription.ChatEvents+".queueSize", *queueSize)
+	}
+
+	lgr := logger.NewLogger()
+	defer lgr.CloseLogger()
+
+	// every user has the access to every chat
+	chatServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer chatServer.Close()
+	viper.Set("chat.url.base", chatServer.URL)
+
+	bus := eventbus.New()
+	defer bus.Close()
+
+	router := subscription.NewRouter(lgr)
+	if err := router.Start(bus); err != nil {
+		panic(fmt.Errorf("Unable to start the router: %w", err))
+	}
+	defer router.Stop(bus)
+
+	resolver := &graph.Resolver{
+		Bus:        bus,
+		HttpClient: client.NewRestClient(lgr),
+		Tr:         otel.Tracer("loadtest"),
+		Lgr:        lgr,
+		Router:     router,
+	}
+
+	cfg := subscription.GetConfig(subscription.ChatEvents)
+	fmt.Printf("Subscribing %v users to %v chats, the slow fraction is %v, queueSize=%v, overflowPolicy=%v\n", *subscribers, *chats, *slowFraction, cfg.QueueSize, cfg.OverflowPolicy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	st := &stats{}
+	var wg sync.WaitGroup
+	slowEvery := 0
+	if *slowFraction > 0 {
+		slowEvery = int(1 / *slowFraction)
+	}
+	for i := 0; i < *subscribers; i++ {
+		userId := int64(i + 1)
+		chatId := int64(i % *chats)
+		slow := slowEvery > 0 && i%slowEvery == 0
+
+		subscriberCtx := context.WithValue(ctx, utils.USER_PRINCIPAL_DTO, &auth.AuthResult{UserId: userId})
+		events, err := resolver.Subscription().ChatEvents(subscriberCtx, chatId, nil)
+		if err != nil {
+			panic(fmt.Errorf("Unable to subscribe user %v: %w", userId, err))
+		}
+		wg.Add(1)
+		go consume(&wg, st, events, slow, *slowDelay)
+	}
+
+	publishCtx, publishCancel := context.WithTimeout(ctx, *duration)
+	defer publishCancel()
+	go publish(publishCtx, bus, st, *subscribers, *chats, *rate)
+	report(publishCtx, st)
+
+	cancel()
+	wg.Wait()
+	printStats(st)
+	printMetrics()
+}
+
+func consume(wg *sync.WaitGroup, st *stats, events <-chan *model.ChatEvent, slow bool, slowDelay time.Duration) {
+	defer wg.Done()
+	for event := range events {
+		st.received.Add(1)
+		if event.EventType == subscription.ResyncRequiredEventType {
+			st.resyncs.Add(1)
+			continue
+		}
+		if slow {
+			time.Sleep(slowDelay)
+		} else if event.CorrelationID != nil {
+			publishedAt, err := strconv.ParseInt(*event.CorrelationID, 10, 64)
+			if err == nil {
+				latency := time.Now().UnixNano() - publishedAt
+				st.latencySum.Add(latency)
+				st.latencyCount.Add(1)
+				for {
+					m := st.latencyMax.Load()
+					if latency <= m || st.latencyMax.CompareAndSwap(m, latency) {
+						break
+					}
+				}
+			}
+		}
+	}
+	st.closed.Add(1)
+}
+
+// the chat service sends the event to every participant, so the subscribers of the chat get the same event
+func publish(ctx context.Context, bus *eventbus.Bus, st *stats, subscribers, chats, rate int) {
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+	for n := 0; ; n++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			chatId := n % chats
+			eventType := eventTypes[n%len(eventTypes)]
+			for i := chatId; i < subscribers; i += chats {
+				correlationId := strconv.FormatInt(time.Now().UnixNano(), 10)
+				err := bus.PublishAsync(dto.ChatEvent{
+					EventType:     eventType,
+					ChatId:        int64(chatId),
+					UserId:        int64(i + 1),
+					CorrelationId: &correlationId,
+				})
+				if err != nil {
+					return
+				}
+				st.published.Add(1)
+			}
+		}
+	}
+}
+
+// prints every second till the end of the publishing
+func report(ctx context.Context, st *stats) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			printStats(st)
+		}
+	}
+}
+
+func printStats(st *stats) {
+	var avg time.Duration
+	if c := st.latencyCount.Load(); c > 0 {
+		avg = time.Duration(st.latencySum.Load() / c)
+	}
+	fmt.Printf("published=%v received=%v resyncs=%v closed=%v goroutines=%v fast latency avg=%v max=%v\n",
+		st.published.Load(), st.received.Load(), st.resyncs.Load(), st.closed.Load(), runtime.NumGoroutine(), avg, time.Duration(st.latencyMax.Load()))
+}
+
+func printMetrics() {
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		fmt.Printf("Unable to gather the metrics: %v\n", err)
+		return
+	}
+	for _, family := range families {
+		if !strings.HasPrefix(family.GetName(), "event_subscription_") {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			var value float64
+			if metric.GetCounter() != nil {
+				value = metric.GetCounter().GetValue()
+			} else if metric.GetGauge() != nil {
+				value = metric.GetGauge().GetValue()
+			}
+			labels := []string{}
+			for _, label := range metric.GetLabel() {
+				labels = append(labels, label.GetName()+"="+label.GetValue())
+			}
+			fmt.Printf("%v{%v} %v\n", family.GetName(), strings.Join(labels, ","), value)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/montag451/go-eventbus"
+)
+
+const USER_PRESENCE = "user.presence"
+
+// the statuses which are set by the user, nil status means available
+const (
+	PresenceAway         = "away"
+	PresenceBusy         = "busy"
+	PresenceDoNotDisturb = "dnd"
+)
+
+const MaxPresenceTextLength = 128
+const MaxPresenceEmojiLength = 16
+
+type UserPresence struct {
+	UserId    int64      `json:"userId"`
+	Status    *string    `json:"status"`
+	Emoji     *string    `json:"emoji"`
+	Text      *string    `json:"text"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	Idle      bool       `json:"idle"` // derived from the activity
+}
+
+func (p *UserPresence) IsDoNotDisturb() bool {
+	return p.Status != nil && *p.Status == PresenceDoNotDisturb
+}
+
+type ArrayUserPresence struct {
+	UserPresences []UserPresence
+	TraceString   string `json:"-"`
+}
+
+func (ArrayUserPresence) Name() eventbus.EventName {
+	return USER_PRESENCE
+}
+
+// is sent to the other services on every change
+type UserPresencesChanged struct {
+	UserPresences []UserPresence `json:"userPresences"`
+}
+
+type UserPresenceSetDto struct {
+	Status    *string    `json:"status"`
+	Emoji     *string    `json:"emoji"`
+	Text      *string    `json:"text"`
+	ExpiresAt *time.Time `json:"expiresAt"` // nil means till it's cleared
+}
+
+func (s *UserPresenceSetDto) Validate(now time.Time) error {
+	if s.Status != nil {
+		switch *s.Status {
+		case PresenceAway, PresenceBusy, PresenceDoNotDisturb:
+		default:
+			return fmt.Errorf("wrong status %q, expected one of %v, %v, %v", *s.Status, PresenceAway, PresenceBusy, PresenceDoNotDisturb)
+		}
+	}
+	if s.Emoji != nil && utf8.RuneCountInString(*s.Emoji) > MaxPresenceEmojiLength {
+		return fmt.Errorf("emoji is longer than %d", MaxPresenceEmojiLength)
+	}
+	if s.Text != nil && utf8.RuneCountInString(*s.Text) > MaxPresenceTextLength {
+		return fmt.Errorf("text is longer than %d", MaxPresenceTextLength)
+	}
+	if s.ExpiresAt != nil && !s.ExpiresAt.After(now) {
+		return errors.New("expiresAt should be in the future")
+	}
+	return nil
+}
+
+// there is nothing to store
+func (s *UserPresenceSetDto) IsEmpty() bool {
+	return s.Status == nil && (s.Emoji == nil || *s.Emoji == "") && (s.Text == nil || *s.Text == "")
+}
@@ -40,6 +40,7 @@ type ReactionChangedEvent struct {
 type ChatEvent struct {
 	TraceString                  string                        `json:"-"`
 	CorrelationId                *string                       `json:"-"`
+	Seq                          *int64                        `json:"-"` // the number in the replay stream, nil for the ephemeral events
 	EventType                    string                        `json:"eventType"`
 	ChatId                       int64                         `json:"chatId"`
 	UserId                       int64                         `json:"userId"`
@@ -52,6 +53,9 @@ type ChatEvent struct {
 	FileEvent                    *WrappedFileInfoDto           `json:"fileEvent"`
 	PublishedMessageNotification *PublishedMessageEvent        `json:"publishedMessageEvent"`
 	ReactionChangedEvent         *ReactionChangedEvent         `json:"reactionChangedEvent"`
+	RaisedHandsEvent             *VideoRaisedHandsChangedDto   `json:"raisedHandsEvent"`
+	BreakoutRoomsEvent           *VideoBreakoutRoomsChangedDto `json:"breakoutRoomsEvent"`
+	RoomChangedEvent             *VideoRoomChangedDto          `json:"roomChangedEvent"`
 }
 
 func (ChatEvent) Name() eventbus.EventName {
@@ -93,6 +97,7 @@ type BrowserNotification struct {
 type GlobalUserEvent struct {
 	TraceString                      string                           `json:"-"`
 	CorrelationId                    *string                          `json:"-"`
+	Seq                              *int64                           `json:"-"` // the number in the replay stream, nil for the ephemeral events
 	EventType                        string                           `json:"eventType"`
 	UserId                           int64                            `json:"userId"`
 	ChatNotification                 *ChatDto                         `json:"chatNotification"`
@@ -160,6 +165,9 @@ type FileInfoDto struct {
 	FileItemUuid   string    `json:"fileItemUuid"`
 	Previewable    bool      `json:"previewable"`
 	Type           *string   `json:"aType"`
+	ScanStatus     string    `json:"scanStatus"`
+	VersionCount   int       `json:"versionCount"`
+	HlsUrl         *string   `json:"hlsUrl"`
 }
 
 type GeneralEvent struct {
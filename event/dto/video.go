@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type VideoCallUserCountChangedDto struct {
 	UsersCount int64 `json:"usersCount"`
 	ChatId     int64 `json:"chatId"`
@@ -48,3 +50,34 @@ type VideoCallUserCallStatusChangedDto struct {
 	UserId      int64 `json:"userId"`
 	IsInVideo   bool  `json:"isInVideo"`
 }
+
+type VideoRaisedHandDto struct {
+	UserId   int64     `json:"userId"`
+	HasFloor bool      `json:"hasFloor"`
+	RaisedAt time.Time `json:"raisedAt"`
+}
+
+type VideoRaisedHandsChangedDto struct {
+	ChatId    int64                `json:"chatId"`
+	Moderated bool                 `json:"moderated"`
+	Hands     []VideoRaisedHandDto `json:"hands"`
+}
+
+type BreakoutRoomDto struct {
+	Id         int64      `json:"id"`
+	Name       string     `json:"name"`
+	EndsAt     *time.Time `json:"endsAt"`
+	UserIds    []int64    `json:"userIds"`
+	UsersCount int64      `json:"usersCount"`
+}
+
+type VideoBreakoutRoomsChangedDto struct {
+	ChatId        int64             `json:"chatId"`
+	BreakoutRooms []BreakoutRoomDto `json:"breakoutRooms"`
+}
+
+type VideoRoomChangedDto struct {
+	ChatId           int64   `json:"chatId"`
+	BreakoutRoomId   *int64  `json:"breakoutRoomId"`
+	BreakoutRoomName *string `json:"breakoutRoomName"`
+}
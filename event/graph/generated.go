@@ -61,6 +61,14 @@ type ComplexityRoot struct {
 		AllUnreadMessages func(childComplexity int) int
 	}
 
+	BreakoutRoomDto struct {
+		EndsAt     func(childComplexity int) int
+		ID         func(childComplexity int) int
+		Name       func(childComplexity int) int
+		UserIds    func(childComplexity int) int
+		UsersCount func(childComplexity int) int
+	}
+
 	BrowserNotification struct {
 		ChatAvatar  func(childComplexity int) int
 		ChatID      func(childComplexity int) int
@@ -117,6 +125,7 @@ type ComplexityRoot struct {
 	}
 
 	ChatEvent struct {
+		BreakoutRoomsEvent    func(childComplexity int) int
 		CorrelationID         func(childComplexity int) int
 		EventType             func(childComplexity int) int
 		FileEvent             func(childComplexity int) int
@@ -127,7 +136,10 @@ type ComplexityRoot struct {
 		PreviewCreatedEvent   func(childComplexity int) int
 		PromoteMessageEvent   func(childComplexity int) int
 		PublishedMessageEvent func(childComplexity int) int
+		RaisedHandsEvent      func(childComplexity int) int
 		ReactionChangedEvent  func(childComplexity int) int
+		RoomChangedEvent      func(childComplexity int) int
+		Seq                   func(childComplexity int) int
 	}
 
 	ChatNotificationSettingsChanged struct {
@@ -189,6 +201,7 @@ type ComplexityRoot struct {
 		CreateDateTime func(childComplexity int) int
 		FileItemUUID   func(childComplexity int) int
 		Filename       func(childComplexity int) int
+		HlsURL         func(childComplexity int) int
 		ID             func(childComplexity int) int
 		LastModified   func(childComplexity int) int
 		Owner          func(childComplexity int) int
@@ -196,8 +209,10 @@ type ComplexityRoot struct {
 		PreviewURL     func(childComplexity int) int
 		Previewable    func(childComplexity int) int
 		PublishedURL   func(childComplexity int) int
+		ScanStatus     func(childComplexity int) int
 		Size           func(childComplexity int) int
 		URL            func(childComplexity int) int
+		VersionCount   func(childComplexity int) int
 	}
 
 	ForceLogoutEvent struct {
@@ -217,6 +232,7 @@ type ComplexityRoot struct {
 		ForceLogout                     func(childComplexity int) int
 		HasUnreadMessagesChanged        func(childComplexity int) int
 		NotificationEvent               func(childComplexity int) int
+		Seq                             func(childComplexity int) int
 		UnreadMessagesNotification      func(childComplexity int) int
 		UserTypingEvent                 func(childComplexity int) int
 		VideoCallInvitation             func(childComplexity int) int
@@ -325,8 +341,8 @@ type ComplexityRoot struct {
 	}
 
 	Subscription struct {
-		ChatEvents        func(childComplexity int, chatID int64) int
-		GlobalEvents      func(childComplexity int) int
+		ChatEvents        func(childComplexity int, chatID int64, since *int64) int
+		GlobalEvents      func(childComplexity int, since *int64) int
 		UserAccountEvents func(childComplexity int, userIdsFilter []int64) int
 		UserStatusEvents  func(childComplexity int, userIds []int64) int
 	}
@@ -368,11 +384,16 @@ type ComplexityRoot struct {
 	}
 
 	UserStatusEvent struct {
-		EventType        func(childComplexity int) int
-		IsInVideo        func(childComplexity int) int
-		LastSeenDateTime func(childComplexity int) int
-		Online           func(childComplexity int) int
-		UserID           func(childComplexity int) int
+		EventType         func(childComplexity int) int
+		Idle              func(childComplexity int) int
+		IsInVideo         func(childComplexity int) int
+		LastSeenDateTime  func(childComplexity int) int
+		Online            func(childComplexity int) int
+		PresenceEmoji     func(childComplexity int) int
+		PresenceExpiresAt func(childComplexity int) int
+		PresenceStatus    func(childComplexity int) int
+		PresenceText      func(childComplexity int) int
+		UserID            func(childComplexity int) int
 	}
 
 	UserTypingDto struct {
@@ -393,6 +414,11 @@ type ComplexityRoot struct {
 		ShortInfo      func(childComplexity int) int
 	}
 
+	VideoBreakoutRoomsChangedDto struct {
+		BreakoutRooms func(childComplexity int) int
+		ChatID        func(childComplexity int) int
+	}
+
 	VideoCallInvitationDto struct {
 		Avatar   func(childComplexity int) int
 		ChatID   func(childComplexity int) int
@@ -415,11 +441,29 @@ type ComplexityRoot struct {
 		Dials  func(childComplexity int) int
 	}
 
+	VideoRaisedHandDto struct {
+		HasFloor func(childComplexity int) int
+		RaisedAt func(childComplexity int) int
+		UserID   func(childComplexity int) int
+	}
+
+	VideoRaisedHandsChangedDto struct {
+		ChatID    func(childComplexity int) int
+		Hands     func(childComplexity int) int
+		Moderated func(childComplexity int) int
+	}
+
 	VideoRecordingChangedDto struct {
 		ChatID           func(childComplexity int) int
 		RecordInProgress func(childComplexity int) int
 	}
 
+	VideoRoomChangedDto struct {
+		BreakoutRoomID   func(childComplexity int) int
+		BreakoutRoomName func(childComplexity int) int
+		ChatID           func(childComplexity int) int
+	}
+
 	VideoUserCountChangedDto struct {
 		ChatID     func(childComplexity int) int
 		UsersCount func(childComplexity int) int
@@ -439,8 +483,8 @@ type QueryResolver interface {
 	Ping(ctx context.Context) (*bool, error)
 }
 type SubscriptionResolver interface {
-	ChatEvents(ctx context.Context, chatID int64) (<-chan *model.ChatEvent, error)
-	GlobalEvents(ctx context.Context) (<-chan *model.GlobalEvent, error)
+	ChatEvents(ctx context.Context, chatID int64, since *int64) (<-chan *model.ChatEvent, error)
+	GlobalEvents(ctx context.Context, since *int64) (<-chan *model.GlobalEvent, error)
 	UserStatusEvents(ctx context.Context, userIds []int64) (<-chan []*model.UserStatusEvent, error)
 	UserAccountEvents(ctx context.Context, userIdsFilter []int64) (<-chan *model.UserAccountEvent, error)
 }
@@ -506,6 +550,41 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.AllUnreadMessages.AllUnreadMessages(childComplexity), true
 
+	case "BreakoutRoomDto.endsAt":
+		if e.complexity.BreakoutRoomDto.EndsAt == nil {
+			break
+		}
+
+		return e.complexity.BreakoutRoomDto.EndsAt(childComplexity), true
+
+	case "BreakoutRoomDto.id":
+		if e.complexity.BreakoutRoomDto.ID == nil {
+			break
+		}
+
+		return e.complexity.BreakoutRoomDto.ID(childComplexity), true
+
+	case "BreakoutRoomDto.name":
+		if e.complexity.BreakoutRoomDto.Name == nil {
+			break
+		}
+
+		return e.complexity.BreakoutRoomDto.Name(childComplexity), true
+
+	case "BreakoutRoomDto.userIds":
+		if e.complexity.BreakoutRoomDto.UserIds == nil {
+			break
+		}
+
+		return e.complexity.BreakoutRoomDto.UserIds(childComplexity), true
+
+	case "BreakoutRoomDto.usersCount":
+		if e.complexity.BreakoutRoomDto.UsersCount == nil {
+			break
+		}
+
+		return e.complexity.BreakoutRoomDto.UsersCount(childComplexity), true
+
 	case "BrowserNotification.chatAvatar":
 		if e.complexity.BrowserNotification.ChatAvatar == nil {
 			break
@@ -828,6 +907,13 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.ChatDto.UnreadMessages(childComplexity), true
 
+	case "ChatEvent.breakoutRoomsEvent":
+		if e.complexity.ChatEvent.BreakoutRoomsEvent == nil {
+			break
+		}
+
+		return e.complexity.ChatEvent.BreakoutRoomsEvent(childComplexity), true
+
 	case "ChatEvent.correlationId":
 		if e.complexity.ChatEvent.CorrelationID == nil {
 			break
@@ -898,6 +984,13 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.ChatEvent.PublishedMessageEvent(childComplexity), true
 
+	case "ChatEvent.raisedHandsEvent":
+		if e.complexity.ChatEvent.RaisedHandsEvent == nil {
+			break
+		}
+
+		return e.complexity.ChatEvent.RaisedHandsEvent(childComplexity), true
+
 	case "ChatEvent.reactionChangedEvent":
 		if e.complexity.ChatEvent.ReactionChangedEvent == nil {
 			break
@@ -905,6 +998,20 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.ChatEvent.ReactionChangedEvent(childComplexity), true
 
+	case "ChatEvent.roomChangedEvent":
+		if e.complexity.ChatEvent.RoomChangedEvent == nil {
+			break
+		}
+
+		return e.complexity.ChatEvent.RoomChangedEvent(childComplexity), true
+
+	case "ChatEvent.seq":
+		if e.complexity.ChatEvent.Seq == nil {
+			break
+		}
+
+		return e.complexity.ChatEvent.Seq(childComplexity), true
+
 	case "ChatNotificationSettingsChanged.chatId":
 		if e.complexity.ChatNotificationSettingsChanged.ChatID == nil {
 			break
@@ -1206,6 +1313,13 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.FileInfoDto.Filename(childComplexity), true
 
+	case "FileInfoDto.hlsUrl":
+		if e.complexity.FileInfoDto.HlsURL == nil {
+			break
+		}
+
+		return e.complexity.FileInfoDto.HlsURL(childComplexity), true
+
 	case "FileInfoDto.id":
 		if e.complexity.FileInfoDto.ID == nil {
 			break
@@ -1255,6 +1369,13 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.FileInfoDto.PublishedURL(childComplexity), true
 
+	case "FileInfoDto.scanStatus":
+		if e.complexity.FileInfoDto.ScanStatus == nil {
+			break
+		}
+
+		return e.complexity.FileInfoDto.ScanStatus(childComplexity), true
+
 	case "FileInfoDto.size":
 		if e.complexity.FileInfoDto.Size == nil {
 			break
@@ -1269,6 +1390,13 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.FileInfoDto.URL(childComplexity), true
 
+	case "FileInfoDto.versionCount":
+		if e.complexity.FileInfoDto.VersionCount == nil {
+			break
+		}
+
+		return e.complexity.FileInfoDto.VersionCount(childComplexity), true
+
 	case "ForceLogoutEvent.reasonType":
 		if e.complexity.ForceLogoutEvent.ReasonType == nil {
 			break
@@ -1360,6 +1488,13 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.GlobalEvent.NotificationEvent(childComplexity), true
 
+	case "GlobalEvent.seq":
+		if e.complexity.GlobalEvent.Seq == nil {
+			break
+		}
+
+		return e.complexity.GlobalEvent.Seq(childComplexity), true
+
 	case "GlobalEvent.unreadMessagesNotification":
 		if e.complexity.GlobalEvent.UnreadMessagesNotification == nil {
 			break
@@ -1811,14 +1946,19 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 			return 0, false
 		}
 
-		return e.complexity.Subscription.ChatEvents(childComplexity, args["chatId"].(int64)), true
+		return e.complexity.Subscription.ChatEvents(childComplexity, args["chatId"].(int64), args["since"].(*int64)), true
 
 	case "Subscription.globalEvents":
 		if e.complexity.Subscription.GlobalEvents == nil {
 			break
 		}
 
-		return e.complexity.Subscription.GlobalEvents(childComplexity), true
+		args, err := ec.field_Subscription_globalEvents_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Subscription.GlobalEvents(childComplexity, args["since"].(*int64)), true
 
 	case "Subscription.userAccountEvents":
 		if e.complexity.Subscription.UserAccountEvents == nil {
@@ -2040,6 +2180,13 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.UserStatusEvent.EventType(childComplexity), true
 
+	case "UserStatusEvent.idle":
+		if e.complexity.UserStatusEvent.Idle == nil {
+			break
+		}
+
+		return e.complexity.UserStatusEvent.Idle(childComplexity), true
+
 	case "UserStatusEvent.isInVideo":
 		if e.complexity.UserStatusEvent.IsInVideo == nil {
 			break
@@ -2061,6 +2208,34 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.UserStatusEvent.Online(childComplexity), true
 
+	case "UserStatusEvent.presenceEmoji":
+		if e.complexity.UserStatusEvent.PresenceEmoji == nil {
+			break
+		}
+
+		return e.complexity.UserStatusEvent.PresenceEmoji(childComplexity), true
+
+	case "UserStatusEvent.presenceExpiresAt":
+		if e.complexity.UserStatusEvent.PresenceExpiresAt == nil {
+			break
+		}
+
+		return e.complexity.UserStatusEvent.PresenceExpiresAt(childComplexity), true
+
+	case "UserStatusEvent.presenceStatus":
+		if e.complexity.UserStatusEvent.PresenceStatus == nil {
+			break
+		}
+
+		return e.complexity.UserStatusEvent.PresenceStatus(childComplexity), true
+
+	case "UserStatusEvent.presenceText":
+		if e.complexity.UserStatusEvent.PresenceText == nil {
+			break
+		}
+
+		return e.complexity.UserStatusEvent.PresenceText(childComplexity), true
+
 	case "UserStatusEvent.userId":
 		if e.complexity.UserStatusEvent.UserID == nil {
 			break
@@ -2152,6 +2327,20 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.UserViewEnrichedDto.ShortInfo(childComplexity), true
 
+	case "VideoBreakoutRoomsChangedDto.breakoutRooms":
+		if e.complexity.VideoBreakoutRoomsChangedDto.BreakoutRooms == nil {
+			break
+		}
+
+		return e.complexity.VideoBreakoutRoomsChangedDto.BreakoutRooms(childComplexity), true
+
+	case "VideoBreakoutRoomsChangedDto.chatId":
+		if e.complexity.VideoBreakoutRoomsChangedDto.ChatID == nil {
+			break
+		}
+
+		return e.complexity.VideoBreakoutRoomsChangedDto.ChatID(childComplexity), true
+
 	case "VideoCallInvitationDto.avatar":
 		if e.complexity.VideoCallInvitationDto.Avatar == nil {
 			break
@@ -2222,6 +2411,48 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.VideoDialChanges.Dials(childComplexity), true
 
+	case "VideoRaisedHandDto.hasFloor":
+		if e.complexity.VideoRaisedHandDto.HasFloor == nil {
+			break
+		}
+
+		return e.complexity.VideoRaisedHandDto.HasFloor(childComplexity), true
+
+	case "VideoRaisedHandDto.raisedAt":
+		if e.complexity.VideoRaisedHandDto.RaisedAt == nil {
+			break
+		}
+
+		return e.complexity.VideoRaisedHandDto.RaisedAt(childComplexity), true
+
+	case "VideoRaisedHandDto.userId":
+		if e.complexity.VideoRaisedHandDto.UserID == nil {
+			break
+		}
+
+		return e.complexity.VideoRaisedHandDto.UserID(childComplexity), true
+
+	case "VideoRaisedHandsChangedDto.chatId":
+		if e.complexity.VideoRaisedHandsChangedDto.ChatID == nil {
+			break
+		}
+
+		return e.complexity.VideoRaisedHandsChangedDto.ChatID(childComplexity), true
+
+	case "VideoRaisedHandsChangedDto.hands":
+		if e.complexity.VideoRaisedHandsChangedDto.Hands == nil {
+			break
+		}
+
+		return e.complexity.VideoRaisedHandsChangedDto.Hands(childComplexity), true
+
+	case "VideoRaisedHandsChangedDto.moderated":
+		if e.complexity.VideoRaisedHandsChangedDto.Moderated == nil {
+			break
+		}
+
+		return e.complexity.VideoRaisedHandsChangedDto.Moderated(childComplexity), true
+
 	case "VideoRecordingChangedDto.chatId":
 		if e.complexity.VideoRecordingChangedDto.ChatID == nil {
 			break
@@ -2236,6 +2467,27 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.VideoRecordingChangedDto.RecordInProgress(childComplexity), true
 
+	case "VideoRoomChangedDto.breakoutRoomId":
+		if e.complexity.VideoRoomChangedDto.BreakoutRoomID == nil {
+			break
+		}
+
+		return e.complexity.VideoRoomChangedDto.BreakoutRoomID(childComplexity), true
+
+	case "VideoRoomChangedDto.breakoutRoomName":
+		if e.complexity.VideoRoomChangedDto.BreakoutRoomName == nil {
+			break
+		}
+
+		return e.complexity.VideoRoomChangedDto.BreakoutRoomName(childComplexity), true
+
+	case "VideoRoomChangedDto.chatId":
+		if e.complexity.VideoRoomChangedDto.ChatID == nil {
+			break
+		}
+
+		return e.complexity.VideoRoomChangedDto.ChatID(childComplexity), true
+
 	case "VideoUserCountChangedDto.chatId":
 		if e.complexity.VideoUserCountChangedDto.ChatID == nil {
 			break
@@ -2427,6 +2679,11 @@ func (ec *executionContext) field_Subscription_chatEvents_args(ctx context.Conte
 		return nil, err
 	}
 	args["chatId"] = arg0
+	arg1, err := ec.field_Subscription_chatEvents_argsSince(ctx, rawArgs)
+	if err != nil {
+		return nil, err
+	}
+	args["since"] = arg1
 	return args, nil
 }
 func (ec *executionContext) field_Subscription_chatEvents_argsChatID(
@@ -2442,6 +2699,42 @@ func (ec *executionContext) field_Subscription_chatEvents_argsChatID(
 	return zeroVal, nil
 }
 
+func (ec *executionContext) field_Subscription_chatEvents_argsSince(
+	ctx context.Context,
+	rawArgs map[string]any,
+) (*int64, error) {
+	ctx = graphql.WithPathContext(ctx, graphql.NewPathWithField("since"))
+	if tmp, ok := rawArgs["since"]; ok {
+		return ec.unmarshalOInt642ᚖint64(ctx, tmp)
+	}
+
+	var zeroVal *int64
+	return zeroVal, nil
+}
+
+func (ec *executionContext) field_Subscription_globalEvents_args(ctx context.Context, rawArgs map[string]any) (map[string]any, error) {
+	var err error
+	args := map[string]any{}
+	arg0, err := ec.field_Subscription_globalEvents_argsSince(ctx, rawArgs)
+	if err != nil {
+		return nil, err
+	}
+	args["since"] = arg0
+	return args, nil
+}
+func (ec *executionContext) field_Subscription_globalEvents_argsSince(
+	ctx context.Context,
+	rawArgs map[string]any,
+) (*int64, error) {
+	ctx = graphql.WithPathContext(ctx, graphql.NewPathWithField("since"))
+	if tmp, ok := rawArgs["since"]; ok {
+		return ec.unmarshalOInt642ᚖint64(ctx, tmp)
+	}
+
+	var zeroVal *int64
+	return zeroVal, nil
+}
+
 func (ec *executionContext) field_Subscription_userAccountEvents_args(ctx context.Context, rawArgs map[string]any) (map[string]any, error) {
 	var err error
 	args := map[string]any{}
@@ -2852,8 +3145,8 @@ func (ec *executionContext) fieldContext_AllUnreadMessages_allUnreadMessages(_ c
 	return fc, nil
 }
 
-func (ec *executionContext) _BrowserNotification_chatId(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_BrowserNotification_chatId(ctx, field)
+func (ec *executionContext) _BreakoutRoomDto_id(ctx context.Context, field graphql.CollectedField, obj *model.BreakoutRoomDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_BreakoutRoomDto_id(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -2866,7 +3159,7 @@ func (ec *executionContext) _BrowserNotification_chatId(ctx context.Context, fie
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ChatID, nil
+		return obj.ID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2883,9 +3176,9 @@ func (ec *executionContext) _BrowserNotification_chatId(ctx context.Context, fie
 	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_BrowserNotification_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_BreakoutRoomDto_id(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "BrowserNotification",
+		Object:     "BreakoutRoomDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -2896,8 +3189,8 @@ func (ec *executionContext) fieldContext_BrowserNotification_chatId(_ context.Co
 	return fc, nil
 }
 
-func (ec *executionContext) _BrowserNotification_chatName(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_BrowserNotification_chatName(ctx, field)
+func (ec *executionContext) _BreakoutRoomDto_name(ctx context.Context, field graphql.CollectedField, obj *model.BreakoutRoomDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_BreakoutRoomDto_name(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -2910,7 +3203,7 @@ func (ec *executionContext) _BrowserNotification_chatName(ctx context.Context, f
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ChatName, nil
+		return obj.Name, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2927,9 +3220,9 @@ func (ec *executionContext) _BrowserNotification_chatName(ctx context.Context, f
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_BrowserNotification_chatName(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_BreakoutRoomDto_name(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "BrowserNotification",
+		Object:     "BreakoutRoomDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -2940,8 +3233,8 @@ func (ec *executionContext) fieldContext_BrowserNotification_chatName(_ context.
 	return fc, nil
 }
 
-func (ec *executionContext) _BrowserNotification_chatAvatar(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_BrowserNotification_chatAvatar(ctx, field)
+func (ec *executionContext) _BreakoutRoomDto_endsAt(ctx context.Context, field graphql.CollectedField, obj *model.BreakoutRoomDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_BreakoutRoomDto_endsAt(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -2954,7 +3247,7 @@ func (ec *executionContext) _BrowserNotification_chatAvatar(ctx context.Context,
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ChatAvatar, nil
+		return obj.EndsAt, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2963,26 +3256,26 @@ func (ec *executionContext) _BrowserNotification_chatAvatar(ctx context.Context,
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(*time.Time)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalOTime2ᚖtimeᚐTime(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_BrowserNotification_chatAvatar(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_BreakoutRoomDto_endsAt(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "BrowserNotification",
+		Object:     "BreakoutRoomDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Time does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _BrowserNotification_messageId(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_BrowserNotification_messageId(ctx, field)
+func (ec *executionContext) _BreakoutRoomDto_userIds(ctx context.Context, field graphql.CollectedField, obj *model.BreakoutRoomDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_BreakoutRoomDto_userIds(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -2995,7 +3288,7 @@ func (ec *executionContext) _BrowserNotification_messageId(ctx context.Context,
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.MessageID, nil
+		return obj.UserIds, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -3007,14 +3300,14 @@ func (ec *executionContext) _BrowserNotification_messageId(ctx context.Context,
 		}
 		return graphql.Null
 	}
-	res := resTmp.(int64)
+	res := resTmp.([]int64)
 	fc.Result = res
-	return ec.marshalNInt642int64(ctx, field.Selections, res)
+	return ec.marshalNInt642ᚕint64ᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_BrowserNotification_messageId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_BreakoutRoomDto_userIds(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "BrowserNotification",
+		Object:     "BreakoutRoomDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -3025,8 +3318,8 @@ func (ec *executionContext) fieldContext_BrowserNotification_messageId(_ context
 	return fc, nil
 }
 
-func (ec *executionContext) _BrowserNotification_messageText(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_BrowserNotification_messageText(ctx, field)
+func (ec *executionContext) _BreakoutRoomDto_usersCount(ctx context.Context, field graphql.CollectedField, obj *model.BreakoutRoomDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_BreakoutRoomDto_usersCount(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -3039,7 +3332,7 @@ func (ec *executionContext) _BrowserNotification_messageText(ctx context.Context
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.MessageText, nil
+		return obj.UsersCount, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -3051,26 +3344,26 @@ func (ec *executionContext) _BrowserNotification_messageText(ctx context.Context
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(int64)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_BrowserNotification_messageText(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_BreakoutRoomDto_usersCount(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "BrowserNotification",
+		Object:     "BreakoutRoomDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Int64 does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _BrowserNotification_ownerId(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_BrowserNotification_ownerId(ctx, field)
+func (ec *executionContext) _BrowserNotification_chatId(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_BrowserNotification_chatId(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -3083,7 +3376,7 @@ func (ec *executionContext) _BrowserNotification_ownerId(ctx context.Context, fi
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.OwnerID, nil
+		return obj.ChatID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -3100,7 +3393,7 @@ func (ec *executionContext) _BrowserNotification_ownerId(ctx context.Context, fi
 	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_BrowserNotification_ownerId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_BrowserNotification_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "BrowserNotification",
 		Field:      field,
@@ -3113,8 +3406,8 @@ func (ec *executionContext) fieldContext_BrowserNotification_ownerId(_ context.C
 	return fc, nil
 }
 
-func (ec *executionContext) _BrowserNotification_ownerLogin(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_BrowserNotification_ownerLogin(ctx, field)
+func (ec *executionContext) _BrowserNotification_chatName(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_BrowserNotification_chatName(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -3127,7 +3420,7 @@ func (ec *executionContext) _BrowserNotification_ownerLogin(ctx context.Context,
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.OwnerLogin, nil
+		return obj.ChatName, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -3144,7 +3437,7 @@ func (ec *executionContext) _BrowserNotification_ownerLogin(ctx context.Context,
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_BrowserNotification_ownerLogin(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_BrowserNotification_chatName(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "BrowserNotification",
 		Field:      field,
@@ -3157,8 +3450,8 @@ func (ec *executionContext) fieldContext_BrowserNotification_ownerLogin(_ contex
 	return fc, nil
 }
 
-func (ec *executionContext) _ChatDeletedDto_id(ctx context.Context, field graphql.CollectedField, obj *model.ChatDeletedDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_ChatDeletedDto_id(ctx, field)
+func (ec *executionContext) _BrowserNotification_chatAvatar(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_BrowserNotification_chatAvatar(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -3171,38 +3464,35 @@ func (ec *executionContext) _ChatDeletedDto_id(ctx context.Context, field graphq
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ID, nil
+		return obj.ChatAvatar, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(int64)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalNInt642int64(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_ChatDeletedDto_id(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_BrowserNotification_chatAvatar(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "ChatDeletedDto",
+		Object:     "BrowserNotification",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Int64 does not have child fields")
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _ChatDto_id(ctx context.Context, field graphql.CollectedField, obj *model.ChatDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_ChatDto_id(ctx, field)
+func (ec *executionContext) _BrowserNotification_messageId(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_BrowserNotification_messageId(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -3215,7 +3505,7 @@ func (ec *executionContext) _ChatDto_id(ctx context.Context, field graphql.Colle
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ID, nil
+		return obj.MessageID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -3232,9 +3522,9 @@ func (ec *executionContext) _ChatDto_id(ctx context.Context, field graphql.Colle
 	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_ChatDto_id(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_BrowserNotification_messageId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "ChatDto",
+		Object:     "BrowserNotification",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -3245,8 +3535,8 @@ func (ec *executionContext) fieldContext_ChatDto_id(_ context.Context, field gra
 	return fc, nil
 }
 
-func (ec *executionContext) _ChatDto_name(ctx context.Context, field graphql.CollectedField, obj *model.ChatDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_ChatDto_name(ctx, field)
+func (ec *executionContext) _BrowserNotification_messageText(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_BrowserNotification_messageText(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -3259,7 +3549,7 @@ func (ec *executionContext) _ChatDto_name(ctx context.Context, field graphql.Col
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Name, nil
+		return obj.MessageText, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -3276,9 +3566,229 @@ func (ec *executionContext) _ChatDto_name(ctx context.Context, field graphql.Col
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_ChatDto_name(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_BrowserNotification_messageText(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "ChatDto",
+		Object:     "BrowserNotification",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _BrowserNotification_ownerId(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_BrowserNotification_ownerId(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.OwnerID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int64)
+	fc.Result = res
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_BrowserNotification_ownerId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "BrowserNotification",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _BrowserNotification_ownerLogin(ctx context.Context, field graphql.CollectedField, obj *model.BrowserNotification) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_BrowserNotification_ownerLogin(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.OwnerLogin, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_BrowserNotification_ownerLogin(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "BrowserNotification",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ChatDeletedDto_id(ctx context.Context, field graphql.CollectedField, obj *model.ChatDeletedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ChatDeletedDto_id(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int64)
+	fc.Result = res
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ChatDeletedDto_id(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ChatDeletedDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ChatDto_id(ctx context.Context, field graphql.CollectedField, obj *model.ChatDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ChatDto_id(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int64)
+	fc.Result = res
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ChatDto_id(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ChatDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ChatDto_name(ctx context.Context, field graphql.CollectedField, obj *model.ChatDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ChatDto_name(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Name, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ChatDto_name(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ChatDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -4951,8 +5461,8 @@ func (ec *executionContext) fieldContext_ChatEvent_correlationId(_ context.Conte
 	return fc, nil
 }
 
-func (ec *executionContext) _ChatEvent_messageEvent(ctx context.Context, field graphql.CollectedField, obj *model.ChatEvent) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_ChatEvent_messageEvent(ctx, field)
+func (ec *executionContext) _ChatEvent_seq(ctx context.Context, field graphql.CollectedField, obj *model.ChatEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ChatEvent_seq(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -4965,7 +5475,7 @@ func (ec *executionContext) _ChatEvent_messageEvent(ctx context.Context, field g
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.MessageEvent, nil
+		return obj.Seq, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -4974,45 +5484,86 @@ func (ec *executionContext) _ChatEvent_messageEvent(ctx context.Context, field g
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*model.DisplayMessageDto)
+	res := resTmp.(*int64)
 	fc.Result = res
-	return ec.marshalODisplayMessageDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐDisplayMessageDto(ctx, field.Selections, res)
+	return ec.marshalOInt642ᚖint64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_ChatEvent_messageEvent(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_ChatEvent_seq(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "ChatEvent",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "id":
-				return ec.fieldContext_DisplayMessageDto_id(ctx, field)
-			case "text":
-				return ec.fieldContext_DisplayMessageDto_text(ctx, field)
-			case "chatId":
-				return ec.fieldContext_DisplayMessageDto_chatId(ctx, field)
-			case "ownerId":
-				return ec.fieldContext_DisplayMessageDto_ownerId(ctx, field)
-			case "createDateTime":
-				return ec.fieldContext_DisplayMessageDto_createDateTime(ctx, field)
-			case "editDateTime":
-				return ec.fieldContext_DisplayMessageDto_editDateTime(ctx, field)
-			case "owner":
-				return ec.fieldContext_DisplayMessageDto_owner(ctx, field)
-			case "canEdit":
-				return ec.fieldContext_DisplayMessageDto_canEdit(ctx, field)
-			case "canSyncEmbed":
-				return ec.fieldContext_DisplayMessageDto_canSyncEmbed(ctx, field)
-			case "canDelete":
-				return ec.fieldContext_DisplayMessageDto_canDelete(ctx, field)
-			case "fileItemUuid":
-				return ec.fieldContext_DisplayMessageDto_fileItemUuid(ctx, field)
-			case "embedMessage":
-				return ec.fieldContext_DisplayMessageDto_embedMessage(ctx, field)
-			case "pinned":
-				return ec.fieldContext_DisplayMessageDto_pinned(ctx, field)
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ChatEvent_messageEvent(ctx context.Context, field graphql.CollectedField, obj *model.ChatEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ChatEvent_messageEvent(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.MessageEvent, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*model.DisplayMessageDto)
+	fc.Result = res
+	return ec.marshalODisplayMessageDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐDisplayMessageDto(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ChatEvent_messageEvent(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ChatEvent",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "id":
+				return ec.fieldContext_DisplayMessageDto_id(ctx, field)
+			case "text":
+				return ec.fieldContext_DisplayMessageDto_text(ctx, field)
+			case "chatId":
+				return ec.fieldContext_DisplayMessageDto_chatId(ctx, field)
+			case "ownerId":
+				return ec.fieldContext_DisplayMessageDto_ownerId(ctx, field)
+			case "createDateTime":
+				return ec.fieldContext_DisplayMessageDto_createDateTime(ctx, field)
+			case "editDateTime":
+				return ec.fieldContext_DisplayMessageDto_editDateTime(ctx, field)
+			case "owner":
+				return ec.fieldContext_DisplayMessageDto_owner(ctx, field)
+			case "canEdit":
+				return ec.fieldContext_DisplayMessageDto_canEdit(ctx, field)
+			case "canSyncEmbed":
+				return ec.fieldContext_DisplayMessageDto_canSyncEmbed(ctx, field)
+			case "canDelete":
+				return ec.fieldContext_DisplayMessageDto_canDelete(ctx, field)
+			case "fileItemUuid":
+				return ec.fieldContext_DisplayMessageDto_fileItemUuid(ctx, field)
+			case "embedMessage":
+				return ec.fieldContext_DisplayMessageDto_embedMessage(ctx, field)
+			case "pinned":
+				return ec.fieldContext_DisplayMessageDto_pinned(ctx, field)
 			case "blogPost":
 				return ec.fieldContext_DisplayMessageDto_blogPost(ctx, field)
 			case "pinnedPromoted":
@@ -5430,6 +5981,151 @@ func (ec *executionContext) fieldContext_ChatEvent_reactionChangedEvent(_ contex
 	return fc, nil
 }
 
+func (ec *executionContext) _ChatEvent_raisedHandsEvent(ctx context.Context, field graphql.CollectedField, obj *model.ChatEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ChatEvent_raisedHandsEvent(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.RaisedHandsEvent, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*model.VideoRaisedHandsChangedDto)
+	fc.Result = res
+	return ec.marshalOVideoRaisedHandsChangedDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoRaisedHandsChangedDto(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ChatEvent_raisedHandsEvent(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ChatEvent",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "chatId":
+				return ec.fieldContext_VideoRaisedHandsChangedDto_chatId(ctx, field)
+			case "moderated":
+				return ec.fieldContext_VideoRaisedHandsChangedDto_moderated(ctx, field)
+			case "hands":
+				return ec.fieldContext_VideoRaisedHandsChangedDto_hands(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type VideoRaisedHandsChangedDto", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ChatEvent_breakoutRoomsEvent(ctx context.Context, field graphql.CollectedField, obj *model.ChatEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ChatEvent_breakoutRoomsEvent(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.BreakoutRoomsEvent, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*model.VideoBreakoutRoomsChangedDto)
+	fc.Result = res
+	return ec.marshalOVideoBreakoutRoomsChangedDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoBreakoutRoomsChangedDto(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ChatEvent_breakoutRoomsEvent(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ChatEvent",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "chatId":
+				return ec.fieldContext_VideoBreakoutRoomsChangedDto_chatId(ctx, field)
+			case "breakoutRooms":
+				return ec.fieldContext_VideoBreakoutRoomsChangedDto_breakoutRooms(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type VideoBreakoutRoomsChangedDto", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ChatEvent_roomChangedEvent(ctx context.Context, field graphql.CollectedField, obj *model.ChatEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ChatEvent_roomChangedEvent(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.RoomChangedEvent, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*model.VideoRoomChangedDto)
+	fc.Result = res
+	return ec.marshalOVideoRoomChangedDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoRoomChangedDto(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ChatEvent_roomChangedEvent(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ChatEvent",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "chatId":
+				return ec.fieldContext_VideoRoomChangedDto_chatId(ctx, field)
+			case "breakoutRoomId":
+				return ec.fieldContext_VideoRoomChangedDto_breakoutRoomId(ctx, field)
+			case "breakoutRoomName":
+				return ec.fieldContext_VideoRoomChangedDto_breakoutRoomName(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type VideoRoomChangedDto", field.Name)
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _ChatNotificationSettingsChanged_chatId(ctx context.Context, field graphql.CollectedField, obj *model.ChatNotificationSettingsChanged) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_ChatNotificationSettingsChanged_chatId(ctx, field)
 	if err != nil {
@@ -7748,8 +8444,8 @@ func (ec *executionContext) fieldContext_FileInfoDto_aType(_ context.Context, fi
 	return fc, nil
 }
 
-func (ec *executionContext) _ForceLogoutEvent_reasonType(ctx context.Context, field graphql.CollectedField, obj *model.ForceLogoutEvent) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_ForceLogoutEvent_reasonType(ctx, field)
+func (ec *executionContext) _FileInfoDto_scanStatus(ctx context.Context, field graphql.CollectedField, obj *model.FileInfoDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FileInfoDto_scanStatus(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -7762,7 +8458,7 @@ func (ec *executionContext) _ForceLogoutEvent_reasonType(ctx context.Context, fi
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ReasonType, nil
+		return obj.ScanStatus, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -7779,9 +8475,9 @@ func (ec *executionContext) _ForceLogoutEvent_reasonType(ctx context.Context, fi
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_ForceLogoutEvent_reasonType(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FileInfoDto_scanStatus(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "ForceLogoutEvent",
+		Object:     "FileInfoDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -7792,8 +8488,8 @@ func (ec *executionContext) fieldContext_ForceLogoutEvent_reasonType(_ context.C
 	return fc, nil
 }
 
-func (ec *executionContext) _GlobalEvent_eventType(ctx context.Context, field graphql.CollectedField, obj *model.GlobalEvent) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GlobalEvent_eventType(ctx, field)
+func (ec *executionContext) _FileInfoDto_versionCount(ctx context.Context, field graphql.CollectedField, obj *model.FileInfoDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FileInfoDto_versionCount(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -7806,7 +8502,7 @@ func (ec *executionContext) _GlobalEvent_eventType(ctx context.Context, field gr
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.EventType, nil
+		return obj.VersionCount, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -7818,26 +8514,26 @@ func (ec *executionContext) _GlobalEvent_eventType(ctx context.Context, field gr
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(int)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNInt2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GlobalEvent_eventType(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FileInfoDto_versionCount(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GlobalEvent",
+		Object:     "FileInfoDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Int does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GlobalEvent_correlationId(ctx context.Context, field graphql.CollectedField, obj *model.GlobalEvent) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GlobalEvent_correlationId(ctx, field)
+func (ec *executionContext) _FileInfoDto_hlsUrl(ctx context.Context, field graphql.CollectedField, obj *model.FileInfoDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FileInfoDto_hlsUrl(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -7850,7 +8546,7 @@ func (ec *executionContext) _GlobalEvent_correlationId(ctx context.Context, fiel
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.CorrelationID, nil
+		return obj.HlsURL, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -7864,9 +8560,9 @@ func (ec *executionContext) _GlobalEvent_correlationId(ctx context.Context, fiel
 	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GlobalEvent_correlationId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FileInfoDto_hlsUrl(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GlobalEvent",
+		Object:     "FileInfoDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -7877,8 +8573,8 @@ func (ec *executionContext) fieldContext_GlobalEvent_correlationId(_ context.Con
 	return fc, nil
 }
 
-func (ec *executionContext) _GlobalEvent_chatEvent(ctx context.Context, field graphql.CollectedField, obj *model.GlobalEvent) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GlobalEvent_chatEvent(ctx, field)
+func (ec *executionContext) _ForceLogoutEvent_reasonType(ctx context.Context, field graphql.CollectedField, obj *model.ForceLogoutEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ForceLogoutEvent_reasonType(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -7891,63 +8587,233 @@ func (ec *executionContext) _GlobalEvent_chatEvent(ctx context.Context, field gr
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ChatEvent, nil
+		return obj.ReasonType, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*model.ChatDto)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOChatDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐChatDto(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GlobalEvent_chatEvent(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_ForceLogoutEvent_reasonType(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GlobalEvent",
+		Object:     "ForceLogoutEvent",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "id":
-				return ec.fieldContext_ChatDto_id(ctx, field)
-			case "name":
-				return ec.fieldContext_ChatDto_name(ctx, field)
-			case "avatar":
-				return ec.fieldContext_ChatDto_avatar(ctx, field)
-			case "avatarBig":
-				return ec.fieldContext_ChatDto_avatarBig(ctx, field)
-			case "shortInfo":
-				return ec.fieldContext_ChatDto_shortInfo(ctx, field)
-			case "lastUpdateDateTime":
-				return ec.fieldContext_ChatDto_lastUpdateDateTime(ctx, field)
-			case "participantIds":
-				return ec.fieldContext_ChatDto_participantIds(ctx, field)
-			case "canEdit":
-				return ec.fieldContext_ChatDto_canEdit(ctx, field)
-			case "canDelete":
-				return ec.fieldContext_ChatDto_canDelete(ctx, field)
-			case "canLeave":
-				return ec.fieldContext_ChatDto_canLeave(ctx, field)
-			case "unreadMessages":
-				return ec.fieldContext_ChatDto_unreadMessages(ctx, field)
-			case "canBroadcast":
-				return ec.fieldContext_ChatDto_canBroadcast(ctx, field)
-			case "canVideoKick":
-				return ec.fieldContext_ChatDto_canVideoKick(ctx, field)
-			case "canChangeChatAdmins":
-				return ec.fieldContext_ChatDto_canChangeChatAdmins(ctx, field)
-			case "tetATet":
-				return ec.fieldContext_ChatDto_tetATet(ctx, field)
-			case "tetATetSelf":
-				return ec.fieldContext_ChatDto_tetATetSelf(ctx, field)
-			case "canAudioMute":
-				return ec.fieldContext_ChatDto_canAudioMute(ctx, field)
-			case "participants":
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GlobalEvent_eventType(ctx context.Context, field graphql.CollectedField, obj *model.GlobalEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GlobalEvent_eventType(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.EventType, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GlobalEvent_eventType(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GlobalEvent",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GlobalEvent_correlationId(ctx context.Context, field graphql.CollectedField, obj *model.GlobalEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GlobalEvent_correlationId(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CorrelationID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GlobalEvent_correlationId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GlobalEvent",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GlobalEvent_seq(ctx context.Context, field graphql.CollectedField, obj *model.GlobalEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GlobalEvent_seq(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Seq, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*int64)
+	fc.Result = res
+	return ec.marshalOInt642ᚖint64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GlobalEvent_seq(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GlobalEvent",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GlobalEvent_chatEvent(ctx context.Context, field graphql.CollectedField, obj *model.GlobalEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GlobalEvent_chatEvent(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ChatEvent, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*model.ChatDto)
+	fc.Result = res
+	return ec.marshalOChatDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐChatDto(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GlobalEvent_chatEvent(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GlobalEvent",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "id":
+				return ec.fieldContext_ChatDto_id(ctx, field)
+			case "name":
+				return ec.fieldContext_ChatDto_name(ctx, field)
+			case "avatar":
+				return ec.fieldContext_ChatDto_avatar(ctx, field)
+			case "avatarBig":
+				return ec.fieldContext_ChatDto_avatarBig(ctx, field)
+			case "shortInfo":
+				return ec.fieldContext_ChatDto_shortInfo(ctx, field)
+			case "lastUpdateDateTime":
+				return ec.fieldContext_ChatDto_lastUpdateDateTime(ctx, field)
+			case "participantIds":
+				return ec.fieldContext_ChatDto_participantIds(ctx, field)
+			case "canEdit":
+				return ec.fieldContext_ChatDto_canEdit(ctx, field)
+			case "canDelete":
+				return ec.fieldContext_ChatDto_canDelete(ctx, field)
+			case "canLeave":
+				return ec.fieldContext_ChatDto_canLeave(ctx, field)
+			case "unreadMessages":
+				return ec.fieldContext_ChatDto_unreadMessages(ctx, field)
+			case "canBroadcast":
+				return ec.fieldContext_ChatDto_canBroadcast(ctx, field)
+			case "canVideoKick":
+				return ec.fieldContext_ChatDto_canVideoKick(ctx, field)
+			case "canChangeChatAdmins":
+				return ec.fieldContext_ChatDto_canChangeChatAdmins(ctx, field)
+			case "tetATet":
+				return ec.fieldContext_ChatDto_tetATet(ctx, field)
+			case "tetATetSelf":
+				return ec.fieldContext_ChatDto_tetATetSelf(ctx, field)
+			case "canAudioMute":
+				return ec.fieldContext_ChatDto_canAudioMute(ctx, field)
+			case "participants":
 				return ec.fieldContext_ChatDto_participants(ctx, field)
 			case "participantsCount":
 				return ec.fieldContext_ChatDto_participantsCount(ctx, field)
@@ -11424,7 +12290,7 @@ func (ec *executionContext) _Subscription_chatEvents(ctx context.Context, field
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Subscription().ChatEvents(rctx, fc.Args["chatId"].(int64))
+		return ec.resolvers.Subscription().ChatEvents(rctx, fc.Args["chatId"].(int64), fc.Args["since"].(*int64))
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -11467,6 +12333,8 @@ func (ec *executionContext) fieldContext_Subscription_chatEvents(ctx context.Con
 				return ec.fieldContext_ChatEvent_eventType(ctx, field)
 			case "correlationId":
 				return ec.fieldContext_ChatEvent_correlationId(ctx, field)
+			case "seq":
+				return ec.fieldContext_ChatEvent_seq(ctx, field)
 			case "messageEvent":
 				return ec.fieldContext_ChatEvent_messageEvent(ctx, field)
 			case "messageDeletedEvent":
@@ -11485,6 +12353,12 @@ func (ec *executionContext) fieldContext_Subscription_chatEvents(ctx context.Con
 				return ec.fieldContext_ChatEvent_publishedMessageEvent(ctx, field)
 			case "reactionChangedEvent":
 				return ec.fieldContext_ChatEvent_reactionChangedEvent(ctx, field)
+			case "raisedHandsEvent":
+				return ec.fieldContext_ChatEvent_raisedHandsEvent(ctx, field)
+			case "breakoutRoomsEvent":
+				return ec.fieldContext_ChatEvent_breakoutRoomsEvent(ctx, field)
+			case "roomChangedEvent":
+				return ec.fieldContext_ChatEvent_roomChangedEvent(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type ChatEvent", field.Name)
 		},
@@ -11517,7 +12391,7 @@ func (ec *executionContext) _Subscription_globalEvents(ctx context.Context, fiel
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Subscription().GlobalEvents(rctx)
+		return ec.resolvers.Subscription().GlobalEvents(rctx, fc.Args["since"].(*int64))
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -11548,7 +12422,7 @@ func (ec *executionContext) _Subscription_globalEvents(ctx context.Context, fiel
 	}
 }
 
-func (ec *executionContext) fieldContext_Subscription_globalEvents(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Subscription_globalEvents(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "Subscription",
 		Field:      field,
@@ -11560,6 +12434,8 @@ func (ec *executionContext) fieldContext_Subscription_globalEvents(_ context.Con
 				return ec.fieldContext_GlobalEvent_eventType(ctx, field)
 			case "correlationId":
 				return ec.fieldContext_GlobalEvent_correlationId(ctx, field)
+			case "seq":
+				return ec.fieldContext_GlobalEvent_seq(ctx, field)
 			case "chatEvent":
 				return ec.fieldContext_GlobalEvent_chatEvent(ctx, field)
 			case "chatDeletedEvent":
@@ -11598,6 +12474,17 @@ func (ec *executionContext) fieldContext_Subscription_globalEvents(_ context.Con
 			return nil, fmt.Errorf("no field named %q was found under type GlobalEvent", field.Name)
 		},
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ec.Recover(ctx, r)
+			ec.Error(ctx, err)
+		}
+	}()
+	ctx = graphql.WithFieldContext(ctx, fc)
+	if fc.Args, err = ec.field_Subscription_globalEvents_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+		ec.Error(ctx, err)
+		return fc, err
+	}
 	return fc, nil
 }
 
@@ -11664,6 +12551,16 @@ func (ec *executionContext) fieldContext_Subscription_userStatusEvents(ctx conte
 				return ec.fieldContext_UserStatusEvent_lastSeenDateTime(ctx, field)
 			case "eventType":
 				return ec.fieldContext_UserStatusEvent_eventType(ctx, field)
+			case "presenceStatus":
+				return ec.fieldContext_UserStatusEvent_presenceStatus(ctx, field)
+			case "presenceEmoji":
+				return ec.fieldContext_UserStatusEvent_presenceEmoji(ctx, field)
+			case "presenceText":
+				return ec.fieldContext_UserStatusEvent_presenceText(ctx, field)
+			case "presenceExpiresAt":
+				return ec.fieldContext_UserStatusEvent_presenceExpiresAt(ctx, field)
+			case "idle":
+				return ec.fieldContext_UserStatusEvent_idle(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type UserStatusEvent", field.Name)
 		},
@@ -13148,8 +14045,8 @@ func (ec *executionContext) fieldContext_UserStatusEvent_eventType(_ context.Con
 	return fc, nil
 }
 
-func (ec *executionContext) _UserTypingDto_login(ctx context.Context, field graphql.CollectedField, obj *model.UserTypingDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_UserTypingDto_login(ctx, field)
+func (ec *executionContext) _UserStatusEvent_presenceStatus(ctx context.Context, field graphql.CollectedField, obj *model.UserStatusEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserStatusEvent_presenceStatus(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13162,26 +14059,23 @@ func (ec *executionContext) _UserTypingDto_login(ctx context.Context, field grap
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Login, nil
+		return obj.PresenceStatus, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_UserTypingDto_login(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_UserStatusEvent_presenceStatus(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "UserTypingDto",
+		Object:     "UserStatusEvent",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -13192,8 +14086,8 @@ func (ec *executionContext) fieldContext_UserTypingDto_login(_ context.Context,
 	return fc, nil
 }
 
-func (ec *executionContext) _UserTypingDto_participantId(ctx context.Context, field graphql.CollectedField, obj *model.UserTypingDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_UserTypingDto_participantId(ctx, field)
+func (ec *executionContext) _UserStatusEvent_presenceEmoji(ctx context.Context, field graphql.CollectedField, obj *model.UserStatusEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserStatusEvent_presenceEmoji(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13206,38 +14100,35 @@ func (ec *executionContext) _UserTypingDto_participantId(ctx context.Context, fi
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ParticipantID, nil
+		return obj.PresenceEmoji, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(int64)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalNInt642int64(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_UserTypingDto_participantId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_UserStatusEvent_presenceEmoji(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "UserTypingDto",
+		Object:     "UserStatusEvent",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Int64 does not have child fields")
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _UserTypingDto_chatId(ctx context.Context, field graphql.CollectedField, obj *model.UserTypingDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_UserTypingDto_chatId(ctx, field)
+func (ec *executionContext) _UserStatusEvent_presenceText(ctx context.Context, field graphql.CollectedField, obj *model.UserStatusEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserStatusEvent_presenceText(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13250,38 +14141,35 @@ func (ec *executionContext) _UserTypingDto_chatId(ctx context.Context, field gra
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ChatID, nil
+		return obj.PresenceText, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(int64)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalNInt642int64(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_UserTypingDto_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_UserStatusEvent_presenceText(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "UserTypingDto",
+		Object:     "UserStatusEvent",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Int64 does not have child fields")
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _UserViewEnrichedDto_id(ctx context.Context, field graphql.CollectedField, obj *model.UserViewEnrichedDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_UserViewEnrichedDto_id(ctx, field)
+func (ec *executionContext) _UserStatusEvent_presenceExpiresAt(ctx context.Context, field graphql.CollectedField, obj *model.UserStatusEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserStatusEvent_presenceExpiresAt(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13294,14 +14182,228 @@ func (ec *executionContext) _UserViewEnrichedDto_id(ctx context.Context, field g
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ID, nil
+		return obj.PresenceExpiresAt, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
+		return graphql.Null
+	}
+	res := resTmp.(*time.Time)
+	fc.Result = res
+	return ec.marshalOTime2ᚖtimeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_UserStatusEvent_presenceExpiresAt(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "UserStatusEvent",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Time does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _UserStatusEvent_idle(ctx context.Context, field graphql.CollectedField, obj *model.UserStatusEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserStatusEvent_idle(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Idle, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*bool)
+	fc.Result = res
+	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_UserStatusEvent_idle(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "UserStatusEvent",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Boolean does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _UserTypingDto_login(ctx context.Context, field graphql.CollectedField, obj *model.UserTypingDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserTypingDto_login(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Login, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_UserTypingDto_login(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "UserTypingDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _UserTypingDto_participantId(ctx context.Context, field graphql.CollectedField, obj *model.UserTypingDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserTypingDto_participantId(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ParticipantID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int64)
+	fc.Result = res
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_UserTypingDto_participantId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "UserTypingDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _UserTypingDto_chatId(ctx context.Context, field graphql.CollectedField, obj *model.UserTypingDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserTypingDto_chatId(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ChatID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int64)
+	fc.Result = res
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_UserTypingDto_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "UserTypingDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _UserViewEnrichedDto_id(ctx context.Context, field graphql.CollectedField, obj *model.UserViewEnrichedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserViewEnrichedDto_id(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
 			ec.Errorf(ctx, "must not be null")
 		}
 		return graphql.Null
@@ -13423,38 +14525,534 @@ func (ec *executionContext) _UserViewEnrichedDto_admin(ctx context.Context, fiel
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Admin, nil
+		return obj.Admin, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_UserViewEnrichedDto_admin(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "UserViewEnrichedDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Boolean does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _UserViewEnrichedDto_shortInfo(ctx context.Context, field graphql.CollectedField, obj *model.UserViewEnrichedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserViewEnrichedDto_shortInfo(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ShortInfo, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_UserViewEnrichedDto_shortInfo(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "UserViewEnrichedDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _UserViewEnrichedDto_loginColor(ctx context.Context, field graphql.CollectedField, obj *model.UserViewEnrichedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserViewEnrichedDto_loginColor(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.LoginColor, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_UserViewEnrichedDto_loginColor(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "UserViewEnrichedDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _UserViewEnrichedDto_additionalData(ctx context.Context, field graphql.CollectedField, obj *model.UserViewEnrichedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserViewEnrichedDto_additionalData(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.AdditionalData, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*model.AdditionalData)
+	fc.Result = res
+	return ec.marshalOAdditionalData2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐAdditionalData(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_UserViewEnrichedDto_additionalData(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "UserViewEnrichedDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "enabled":
+				return ec.fieldContext_AdditionalData_enabled(ctx, field)
+			case "expired":
+				return ec.fieldContext_AdditionalData_expired(ctx, field)
+			case "locked":
+				return ec.fieldContext_AdditionalData_locked(ctx, field)
+			case "confirmed":
+				return ec.fieldContext_AdditionalData_confirmed(ctx, field)
+			case "roles":
+				return ec.fieldContext_AdditionalData_roles(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type AdditionalData", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _UserViewEnrichedDto_canChange(ctx context.Context, field graphql.CollectedField, obj *model.UserViewEnrichedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserViewEnrichedDto_canChange(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CanChange, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_UserViewEnrichedDto_canChange(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "UserViewEnrichedDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Boolean does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _UserViewEnrichedDto_canDelete(ctx context.Context, field graphql.CollectedField, obj *model.UserViewEnrichedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_UserViewEnrichedDto_canDelete(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CanDelete, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_UserViewEnrichedDto_canDelete(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "UserViewEnrichedDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Boolean does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _VideoBreakoutRoomsChangedDto_chatId(ctx context.Context, field graphql.CollectedField, obj *model.VideoBreakoutRoomsChangedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoBreakoutRoomsChangedDto_chatId(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ChatID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int64)
+	fc.Result = res
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_VideoBreakoutRoomsChangedDto_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "VideoBreakoutRoomsChangedDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _VideoBreakoutRoomsChangedDto_breakoutRooms(ctx context.Context, field graphql.CollectedField, obj *model.VideoBreakoutRoomsChangedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoBreakoutRoomsChangedDto_breakoutRooms(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.BreakoutRooms, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]*model.BreakoutRoomDto)
+	fc.Result = res
+	return ec.marshalNBreakoutRoomDto2ᚕᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐBreakoutRoomDtoᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_VideoBreakoutRoomsChangedDto_breakoutRooms(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "VideoBreakoutRoomsChangedDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "id":
+				return ec.fieldContext_BreakoutRoomDto_id(ctx, field)
+			case "name":
+				return ec.fieldContext_BreakoutRoomDto_name(ctx, field)
+			case "endsAt":
+				return ec.fieldContext_BreakoutRoomDto_endsAt(ctx, field)
+			case "userIds":
+				return ec.fieldContext_BreakoutRoomDto_userIds(ctx, field)
+			case "usersCount":
+				return ec.fieldContext_BreakoutRoomDto_usersCount(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type BreakoutRoomDto", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _VideoCallInvitationDto_chatId(ctx context.Context, field graphql.CollectedField, obj *model.VideoCallInvitationDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoCallInvitationDto_chatId(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ChatID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int64)
+	fc.Result = res
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_VideoCallInvitationDto_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "VideoCallInvitationDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _VideoCallInvitationDto_chatName(ctx context.Context, field graphql.CollectedField, obj *model.VideoCallInvitationDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoCallInvitationDto_chatName(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ChatName, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_VideoCallInvitationDto_chatName(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "VideoCallInvitationDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _VideoCallInvitationDto_status(ctx context.Context, field graphql.CollectedField, obj *model.VideoCallInvitationDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoCallInvitationDto_status(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Status, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_VideoCallInvitationDto_status(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "VideoCallInvitationDto",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _VideoCallInvitationDto_avatar(ctx context.Context, field graphql.CollectedField, obj *model.VideoCallInvitationDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoCallInvitationDto_avatar(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Avatar, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(bool)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_UserViewEnrichedDto_admin(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoCallInvitationDto_avatar(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "UserViewEnrichedDto",
+		Object:     "VideoCallInvitationDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Boolean does not have child fields")
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _UserViewEnrichedDto_shortInfo(ctx context.Context, field graphql.CollectedField, obj *model.UserViewEnrichedDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_UserViewEnrichedDto_shortInfo(ctx, field)
+func (ec *executionContext) _VideoCallScreenShareChangedDto_chatId(ctx context.Context, field graphql.CollectedField, obj *model.VideoCallScreenShareChangedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoCallScreenShareChangedDto_chatId(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13467,35 +15065,38 @@ func (ec *executionContext) _UserViewEnrichedDto_shortInfo(ctx context.Context,
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ShortInfo, nil
+		return obj.ChatID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(int64)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_UserViewEnrichedDto_shortInfo(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoCallScreenShareChangedDto_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "UserViewEnrichedDto",
+		Object:     "VideoCallScreenShareChangedDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Int64 does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _UserViewEnrichedDto_loginColor(ctx context.Context, field graphql.CollectedField, obj *model.UserViewEnrichedDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_UserViewEnrichedDto_loginColor(ctx, field)
+func (ec *executionContext) _VideoCallScreenShareChangedDto_hasScreenShares(ctx context.Context, field graphql.CollectedField, obj *model.VideoCallScreenShareChangedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoCallScreenShareChangedDto_hasScreenShares(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13508,35 +15109,38 @@ func (ec *executionContext) _UserViewEnrichedDto_loginColor(ctx context.Context,
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.LoginColor, nil
+		return obj.HasScreenShares, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(bool)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_UserViewEnrichedDto_loginColor(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoCallScreenShareChangedDto_hasScreenShares(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "UserViewEnrichedDto",
+		Object:     "VideoCallScreenShareChangedDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Boolean does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _UserViewEnrichedDto_additionalData(ctx context.Context, field graphql.CollectedField, obj *model.UserViewEnrichedDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_UserViewEnrichedDto_additionalData(ctx, field)
+func (ec *executionContext) _VideoDialChanged_userId(ctx context.Context, field graphql.CollectedField, obj *model.VideoDialChanged) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoDialChanged_userId(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13549,47 +15153,38 @@ func (ec *executionContext) _UserViewEnrichedDto_additionalData(ctx context.Cont
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.AdditionalData, nil
+		return obj.UserID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*model.AdditionalData)
+	res := resTmp.(int64)
 	fc.Result = res
-	return ec.marshalOAdditionalData2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐAdditionalData(ctx, field.Selections, res)
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_UserViewEnrichedDto_additionalData(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoDialChanged_userId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "UserViewEnrichedDto",
+		Object:     "VideoDialChanged",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "enabled":
-				return ec.fieldContext_AdditionalData_enabled(ctx, field)
-			case "expired":
-				return ec.fieldContext_AdditionalData_expired(ctx, field)
-			case "locked":
-				return ec.fieldContext_AdditionalData_locked(ctx, field)
-			case "confirmed":
-				return ec.fieldContext_AdditionalData_confirmed(ctx, field)
-			case "roles":
-				return ec.fieldContext_AdditionalData_roles(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type AdditionalData", field.Name)
+			return nil, errors.New("field of type Int64 does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _UserViewEnrichedDto_canChange(ctx context.Context, field graphql.CollectedField, obj *model.UserViewEnrichedDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_UserViewEnrichedDto_canChange(ctx, field)
+func (ec *executionContext) _VideoDialChanged_status(ctx context.Context, field graphql.CollectedField, obj *model.VideoDialChanged) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoDialChanged_status(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13602,7 +15197,7 @@ func (ec *executionContext) _UserViewEnrichedDto_canChange(ctx context.Context,
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.CanChange, nil
+		return obj.Status, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -13614,26 +15209,26 @@ func (ec *executionContext) _UserViewEnrichedDto_canChange(ctx context.Context,
 		}
 		return graphql.Null
 	}
-	res := resTmp.(bool)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_UserViewEnrichedDto_canChange(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoDialChanged_status(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "UserViewEnrichedDto",
+		Object:     "VideoDialChanged",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Boolean does not have child fields")
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _UserViewEnrichedDto_canDelete(ctx context.Context, field graphql.CollectedField, obj *model.UserViewEnrichedDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_UserViewEnrichedDto_canDelete(ctx, field)
+func (ec *executionContext) _VideoDialChanges_chatId(ctx context.Context, field graphql.CollectedField, obj *model.VideoDialChanges) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoDialChanges_chatId(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13646,7 +15241,7 @@ func (ec *executionContext) _UserViewEnrichedDto_canDelete(ctx context.Context,
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.CanDelete, nil
+		return obj.ChatID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -13658,26 +15253,26 @@ func (ec *executionContext) _UserViewEnrichedDto_canDelete(ctx context.Context,
 		}
 		return graphql.Null
 	}
-	res := resTmp.(bool)
+	res := resTmp.(int64)
 	fc.Result = res
-	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_UserViewEnrichedDto_canDelete(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoDialChanges_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "UserViewEnrichedDto",
+		Object:     "VideoDialChanges",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Boolean does not have child fields")
+			return nil, errors.New("field of type Int64 does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _VideoCallInvitationDto_chatId(ctx context.Context, field graphql.CollectedField, obj *model.VideoCallInvitationDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_VideoCallInvitationDto_chatId(ctx, field)
+func (ec *executionContext) _VideoDialChanges_dials(ctx context.Context, field graphql.CollectedField, obj *model.VideoDialChanges) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoDialChanges_dials(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13690,7 +15285,7 @@ func (ec *executionContext) _VideoCallInvitationDto_chatId(ctx context.Context,
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ChatID, nil
+		return obj.Dials, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -13702,26 +15297,32 @@ func (ec *executionContext) _VideoCallInvitationDto_chatId(ctx context.Context,
 		}
 		return graphql.Null
 	}
-	res := resTmp.(int64)
+	res := resTmp.([]*model.VideoDialChanged)
 	fc.Result = res
-	return ec.marshalNInt642int64(ctx, field.Selections, res)
+	return ec.marshalNVideoDialChanged2ᚕᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoDialChangedᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_VideoCallInvitationDto_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoDialChanges_dials(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "VideoCallInvitationDto",
+		Object:     "VideoDialChanges",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Int64 does not have child fields")
+			switch field.Name {
+			case "userId":
+				return ec.fieldContext_VideoDialChanged_userId(ctx, field)
+			case "status":
+				return ec.fieldContext_VideoDialChanged_status(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type VideoDialChanged", field.Name)
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _VideoCallInvitationDto_chatName(ctx context.Context, field graphql.CollectedField, obj *model.VideoCallInvitationDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_VideoCallInvitationDto_chatName(ctx, field)
+func (ec *executionContext) _VideoRaisedHandDto_userId(ctx context.Context, field graphql.CollectedField, obj *model.VideoRaisedHandDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoRaisedHandDto_userId(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13734,7 +15335,7 @@ func (ec *executionContext) _VideoCallInvitationDto_chatName(ctx context.Context
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ChatName, nil
+		return obj.UserID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -13746,26 +15347,26 @@ func (ec *executionContext) _VideoCallInvitationDto_chatName(ctx context.Context
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(int64)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_VideoCallInvitationDto_chatName(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoRaisedHandDto_userId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "VideoCallInvitationDto",
+		Object:     "VideoRaisedHandDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Int64 does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _VideoCallInvitationDto_status(ctx context.Context, field graphql.CollectedField, obj *model.VideoCallInvitationDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_VideoCallInvitationDto_status(ctx, field)
+func (ec *executionContext) _VideoRaisedHandDto_hasFloor(ctx context.Context, field graphql.CollectedField, obj *model.VideoRaisedHandDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoRaisedHandDto_hasFloor(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13778,7 +15379,7 @@ func (ec *executionContext) _VideoCallInvitationDto_status(ctx context.Context,
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Status, nil
+		return obj.HasFloor, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -13790,26 +15391,26 @@ func (ec *executionContext) _VideoCallInvitationDto_status(ctx context.Context,
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(bool)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_VideoCallInvitationDto_status(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoRaisedHandDto_hasFloor(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "VideoCallInvitationDto",
+		Object:     "VideoRaisedHandDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Boolean does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _VideoCallInvitationDto_avatar(ctx context.Context, field graphql.CollectedField, obj *model.VideoCallInvitationDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_VideoCallInvitationDto_avatar(ctx, field)
+func (ec *executionContext) _VideoRaisedHandDto_raisedAt(ctx context.Context, field graphql.CollectedField, obj *model.VideoRaisedHandDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoRaisedHandDto_raisedAt(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13822,35 +15423,38 @@ func (ec *executionContext) _VideoCallInvitationDto_avatar(ctx context.Context,
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Avatar, nil
+		return obj.RaisedAt, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(time.Time)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_VideoCallInvitationDto_avatar(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoRaisedHandDto_raisedAt(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "VideoCallInvitationDto",
+		Object:     "VideoRaisedHandDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Time does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _VideoCallScreenShareChangedDto_chatId(ctx context.Context, field graphql.CollectedField, obj *model.VideoCallScreenShareChangedDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_VideoCallScreenShareChangedDto_chatId(ctx, field)
+func (ec *executionContext) _VideoRaisedHandsChangedDto_chatId(ctx context.Context, field graphql.CollectedField, obj *model.VideoRaisedHandsChangedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoRaisedHandsChangedDto_chatId(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13880,9 +15484,9 @@ func (ec *executionContext) _VideoCallScreenShareChangedDto_chatId(ctx context.C
 	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_VideoCallScreenShareChangedDto_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoRaisedHandsChangedDto_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "VideoCallScreenShareChangedDto",
+		Object:     "VideoRaisedHandsChangedDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -13893,8 +15497,8 @@ func (ec *executionContext) fieldContext_VideoCallScreenShareChangedDto_chatId(_
 	return fc, nil
 }
 
-func (ec *executionContext) _VideoCallScreenShareChangedDto_hasScreenShares(ctx context.Context, field graphql.CollectedField, obj *model.VideoCallScreenShareChangedDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_VideoCallScreenShareChangedDto_hasScreenShares(ctx, field)
+func (ec *executionContext) _VideoRaisedHandsChangedDto_moderated(ctx context.Context, field graphql.CollectedField, obj *model.VideoRaisedHandsChangedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoRaisedHandsChangedDto_moderated(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13907,7 +15511,7 @@ func (ec *executionContext) _VideoCallScreenShareChangedDto_hasScreenShares(ctx
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.HasScreenShares, nil
+		return obj.Moderated, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -13924,9 +15528,9 @@ func (ec *executionContext) _VideoCallScreenShareChangedDto_hasScreenShares(ctx
 	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_VideoCallScreenShareChangedDto_hasScreenShares(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoRaisedHandsChangedDto_moderated(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "VideoCallScreenShareChangedDto",
+		Object:     "VideoRaisedHandsChangedDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -13937,8 +15541,8 @@ func (ec *executionContext) fieldContext_VideoCallScreenShareChangedDto_hasScree
 	return fc, nil
 }
 
-func (ec *executionContext) _VideoDialChanged_userId(ctx context.Context, field graphql.CollectedField, obj *model.VideoDialChanged) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_VideoDialChanged_userId(ctx, field)
+func (ec *executionContext) _VideoRaisedHandsChangedDto_hands(ctx context.Context, field graphql.CollectedField, obj *model.VideoRaisedHandsChangedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoRaisedHandsChangedDto_hands(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13951,7 +15555,7 @@ func (ec *executionContext) _VideoDialChanged_userId(ctx context.Context, field
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.UserID, nil
+		return obj.Hands, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -13963,26 +15567,34 @@ func (ec *executionContext) _VideoDialChanged_userId(ctx context.Context, field
 		}
 		return graphql.Null
 	}
-	res := resTmp.(int64)
+	res := resTmp.([]*model.VideoRaisedHandDto)
 	fc.Result = res
-	return ec.marshalNInt642int64(ctx, field.Selections, res)
+	return ec.marshalNVideoRaisedHandDto2ᚕᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoRaisedHandDtoᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_VideoDialChanged_userId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoRaisedHandsChangedDto_hands(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "VideoDialChanged",
+		Object:     "VideoRaisedHandsChangedDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Int64 does not have child fields")
+			switch field.Name {
+			case "userId":
+				return ec.fieldContext_VideoRaisedHandDto_userId(ctx, field)
+			case "hasFloor":
+				return ec.fieldContext_VideoRaisedHandDto_hasFloor(ctx, field)
+			case "raisedAt":
+				return ec.fieldContext_VideoRaisedHandDto_raisedAt(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type VideoRaisedHandDto", field.Name)
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _VideoDialChanged_status(ctx context.Context, field graphql.CollectedField, obj *model.VideoDialChanged) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_VideoDialChanged_status(ctx, field)
+func (ec *executionContext) _VideoRecordingChangedDto_recordInProgress(ctx context.Context, field graphql.CollectedField, obj *model.VideoRecordingChangedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoRecordingChangedDto_recordInProgress(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -13995,7 +15607,7 @@ func (ec *executionContext) _VideoDialChanged_status(ctx context.Context, field
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Status, nil
+		return obj.RecordInProgress, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -14007,26 +15619,26 @@ func (ec *executionContext) _VideoDialChanged_status(ctx context.Context, field
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(bool)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_VideoDialChanged_status(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoRecordingChangedDto_recordInProgress(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "VideoDialChanged",
+		Object:     "VideoRecordingChangedDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Boolean does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _VideoDialChanges_chatId(ctx context.Context, field graphql.CollectedField, obj *model.VideoDialChanges) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_VideoDialChanges_chatId(ctx, field)
+func (ec *executionContext) _VideoRecordingChangedDto_chatId(ctx context.Context, field graphql.CollectedField, obj *model.VideoRecordingChangedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoRecordingChangedDto_chatId(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -14056,9 +15668,9 @@ func (ec *executionContext) _VideoDialChanges_chatId(ctx context.Context, field
 	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_VideoDialChanges_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoRecordingChangedDto_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "VideoDialChanges",
+		Object:     "VideoRecordingChangedDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -14069,8 +15681,8 @@ func (ec *executionContext) fieldContext_VideoDialChanges_chatId(_ context.Conte
 	return fc, nil
 }
 
-func (ec *executionContext) _VideoDialChanges_dials(ctx context.Context, field graphql.CollectedField, obj *model.VideoDialChanges) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_VideoDialChanges_dials(ctx, field)
+func (ec *executionContext) _VideoRoomChangedDto_chatId(ctx context.Context, field graphql.CollectedField, obj *model.VideoRoomChangedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoRoomChangedDto_chatId(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -14083,7 +15695,7 @@ func (ec *executionContext) _VideoDialChanges_dials(ctx context.Context, field g
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Dials, nil
+		return obj.ChatID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -14095,32 +15707,26 @@ func (ec *executionContext) _VideoDialChanges_dials(ctx context.Context, field g
 		}
 		return graphql.Null
 	}
-	res := resTmp.([]*model.VideoDialChanged)
+	res := resTmp.(int64)
 	fc.Result = res
-	return ec.marshalNVideoDialChanged2ᚕᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoDialChangedᚄ(ctx, field.Selections, res)
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_VideoDialChanges_dials(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoRoomChangedDto_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "VideoDialChanges",
+		Object:     "VideoRoomChangedDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "userId":
-				return ec.fieldContext_VideoDialChanged_userId(ctx, field)
-			case "status":
-				return ec.fieldContext_VideoDialChanged_status(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type VideoDialChanged", field.Name)
+			return nil, errors.New("field of type Int64 does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _VideoRecordingChangedDto_recordInProgress(ctx context.Context, field graphql.CollectedField, obj *model.VideoRecordingChangedDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_VideoRecordingChangedDto_recordInProgress(ctx, field)
+func (ec *executionContext) _VideoRoomChangedDto_breakoutRoomId(ctx context.Context, field graphql.CollectedField, obj *model.VideoRoomChangedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoRoomChangedDto_breakoutRoomId(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -14133,38 +15739,35 @@ func (ec *executionContext) _VideoRecordingChangedDto_recordInProgress(ctx conte
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.RecordInProgress, nil
+		return obj.BreakoutRoomID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(bool)
+	res := resTmp.(*int64)
 	fc.Result = res
-	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+	return ec.marshalOInt642ᚖint64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_VideoRecordingChangedDto_recordInProgress(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoRoomChangedDto_breakoutRoomId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "VideoRecordingChangedDto",
+		Object:     "VideoRoomChangedDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Boolean does not have child fields")
+			return nil, errors.New("field of type Int64 does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _VideoRecordingChangedDto_chatId(ctx context.Context, field graphql.CollectedField, obj *model.VideoRecordingChangedDto) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_VideoRecordingChangedDto_chatId(ctx, field)
+func (ec *executionContext) _VideoRoomChangedDto_breakoutRoomName(ctx context.Context, field graphql.CollectedField, obj *model.VideoRoomChangedDto) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_VideoRoomChangedDto_breakoutRoomName(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -14177,31 +15780,28 @@ func (ec *executionContext) _VideoRecordingChangedDto_chatId(ctx context.Context
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (any, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ChatID, nil
+		return obj.BreakoutRoomName, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(int64)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalNInt642int64(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_VideoRecordingChangedDto_chatId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_VideoRoomChangedDto_breakoutRoomName(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "VideoRecordingChangedDto",
+		Object:     "VideoRoomChangedDto",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Int64 does not have child fields")
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
@@ -14369,6 +15969,12 @@ func (ec *executionContext) fieldContext_WrappedFileInfoDto_fileInfoDto(_ contex
 				return ec.fieldContext_FileInfoDto_previewable(ctx, field)
 			case "aType":
 				return ec.fieldContext_FileInfoDto_aType(ctx, field)
+			case "scanStatus":
+				return ec.fieldContext_FileInfoDto_scanStatus(ctx, field)
+			case "versionCount":
+				return ec.fieldContext_FileInfoDto_versionCount(ctx, field)
+			case "hlsUrl":
+				return ec.fieldContext_FileInfoDto_hlsUrl(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type FileInfoDto", field.Name)
 		},
@@ -16566,6 +18172,62 @@ func (ec *executionContext) _AllUnreadMessages(ctx context.Context, sel ast.Sele
 	return out
 }
 
+var breakoutRoomDtoImplementors = []string{"BreakoutRoomDto"}
+
+func (ec *executionContext) _BreakoutRoomDto(ctx context.Context, sel ast.SelectionSet, obj *model.BreakoutRoomDto) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, breakoutRoomDtoImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("BreakoutRoomDto")
+		case "id":
+			out.Values[i] = ec._BreakoutRoomDto_id(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "name":
+			out.Values[i] = ec._BreakoutRoomDto_name(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "endsAt":
+			out.Values[i] = ec._BreakoutRoomDto_endsAt(ctx, field, obj)
+		case "userIds":
+			out.Values[i] = ec._BreakoutRoomDto_userIds(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "usersCount":
+			out.Values[i] = ec._BreakoutRoomDto_usersCount(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
 var browserNotificationImplementors = []string{"BrowserNotification"}
 
 func (ec *executionContext) _BrowserNotification(ctx context.Context, sel ast.SelectionSet, obj *model.BrowserNotification) graphql.Marshaler {
@@ -16880,6 +18542,8 @@ func (ec *executionContext) _ChatEvent(ctx context.Context, sel ast.SelectionSet
 			}
 		case "correlationId":
 			out.Values[i] = ec._ChatEvent_correlationId(ctx, field, obj)
+		case "seq":
+			out.Values[i] = ec._ChatEvent_seq(ctx, field, obj)
 		case "messageEvent":
 			out.Values[i] = ec._ChatEvent_messageEvent(ctx, field, obj)
 		case "messageDeletedEvent":
@@ -16898,6 +18562,12 @@ func (ec *executionContext) _ChatEvent(ctx context.Context, sel ast.SelectionSet
 			out.Values[i] = ec._ChatEvent_publishedMessageEvent(ctx, field, obj)
 		case "reactionChangedEvent":
 			out.Values[i] = ec._ChatEvent_reactionChangedEvent(ctx, field, obj)
+		case "raisedHandsEvent":
+			out.Values[i] = ec._ChatEvent_raisedHandsEvent(ctx, field, obj)
+		case "breakoutRoomsEvent":
+			out.Values[i] = ec._ChatEvent_breakoutRoomsEvent(ctx, field, obj)
+		case "roomChangedEvent":
+			out.Values[i] = ec._ChatEvent_roomChangedEvent(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -17326,6 +18996,18 @@ func (ec *executionContext) _FileInfoDto(ctx context.Context, sel ast.SelectionS
 			}
 		case "aType":
 			out.Values[i] = ec._FileInfoDto_aType(ctx, field, obj)
+		case "scanStatus":
+			out.Values[i] = ec._FileInfoDto_scanStatus(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "versionCount":
+			out.Values[i] = ec._FileInfoDto_versionCount(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "hlsUrl":
+			out.Values[i] = ec._FileInfoDto_hlsUrl(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -17406,6 +19088,8 @@ func (ec *executionContext) _GlobalEvent(ctx context.Context, sel ast.SelectionS
 			}
 		case "correlationId":
 			out.Values[i] = ec._GlobalEvent_correlationId(ctx, field, obj)
+		case "seq":
+			out.Values[i] = ec._GlobalEvent_seq(ctx, field, obj)
 		case "chatEvent":
 			out.Values[i] = ec._GlobalEvent_chatEvent(ctx, field, obj)
 		case "chatDeletedEvent":
@@ -18467,6 +20151,227 @@ func (ec *executionContext) _UserStatusEvent(ctx context.Context, sel ast.Select
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
+		case "presenceStatus":
+			out.Values[i] = ec._UserStatusEvent_presenceStatus(ctx, field, obj)
+		case "presenceEmoji":
+			out.Values[i] = ec._UserStatusEvent_presenceEmoji(ctx, field, obj)
+		case "presenceText":
+			out.Values[i] = ec._UserStatusEvent_presenceText(ctx, field, obj)
+		case "presenceExpiresAt":
+			out.Values[i] = ec._UserStatusEvent_presenceExpiresAt(ctx, field, obj)
+		case "idle":
+			out.Values[i] = ec._UserStatusEvent_idle(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var userTypingDtoImplementors = []string{"UserTypingDto"}
+
+func (ec *executionContext) _UserTypingDto(ctx context.Context, sel ast.SelectionSet, obj *model.UserTypingDto) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, userTypingDtoImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("UserTypingDto")
+		case "login":
+			out.Values[i] = ec._UserTypingDto_login(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "participantId":
+			out.Values[i] = ec._UserTypingDto_participantId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "chatId":
+			out.Values[i] = ec._UserTypingDto_chatId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var userViewEnrichedDtoImplementors = []string{"UserViewEnrichedDto"}
+
+func (ec *executionContext) _UserViewEnrichedDto(ctx context.Context, sel ast.SelectionSet, obj *model.UserViewEnrichedDto) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, userViewEnrichedDtoImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("UserViewEnrichedDto")
+		case "id":
+			out.Values[i] = ec._UserViewEnrichedDto_id(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "login":
+			out.Values[i] = ec._UserViewEnrichedDto_login(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "avatar":
+			out.Values[i] = ec._UserViewEnrichedDto_avatar(ctx, field, obj)
+		case "admin":
+			out.Values[i] = ec._UserViewEnrichedDto_admin(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "shortInfo":
+			out.Values[i] = ec._UserViewEnrichedDto_shortInfo(ctx, field, obj)
+		case "loginColor":
+			out.Values[i] = ec._UserViewEnrichedDto_loginColor(ctx, field, obj)
+		case "additionalData":
+			out.Values[i] = ec._UserViewEnrichedDto_additionalData(ctx, field, obj)
+		case "canChange":
+			out.Values[i] = ec._UserViewEnrichedDto_canChange(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "canDelete":
+			out.Values[i] = ec._UserViewEnrichedDto_canDelete(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var videoBreakoutRoomsChangedDtoImplementors = []string{"VideoBreakoutRoomsChangedDto"}
+
+func (ec *executionContext) _VideoBreakoutRoomsChangedDto(ctx context.Context, sel ast.SelectionSet, obj *model.VideoBreakoutRoomsChangedDto) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, videoBreakoutRoomsChangedDtoImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("VideoBreakoutRoomsChangedDto")
+		case "chatId":
+			out.Values[i] = ec._VideoBreakoutRoomsChangedDto_chatId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "breakoutRooms":
+			out.Values[i] = ec._VideoBreakoutRoomsChangedDto_breakoutRooms(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var videoCallInvitationDtoImplementors = []string{"VideoCallInvitationDto"}
+
+func (ec *executionContext) _VideoCallInvitationDto(ctx context.Context, sel ast.SelectionSet, obj *model.VideoCallInvitationDto) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, videoCallInvitationDtoImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("VideoCallInvitationDto")
+		case "chatId":
+			out.Values[i] = ec._VideoCallInvitationDto_chatId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "chatName":
+			out.Values[i] = ec._VideoCallInvitationDto_chatName(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "status":
+			out.Values[i] = ec._VideoCallInvitationDto_status(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "avatar":
+			out.Values[i] = ec._VideoCallInvitationDto_avatar(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -18490,29 +20395,24 @@ func (ec *executionContext) _UserStatusEvent(ctx context.Context, sel ast.Select
 	return out
 }
 
-var userTypingDtoImplementors = []string{"UserTypingDto"}
+var videoCallScreenShareChangedDtoImplementors = []string{"VideoCallScreenShareChangedDto"}
 
-func (ec *executionContext) _UserTypingDto(ctx context.Context, sel ast.SelectionSet, obj *model.UserTypingDto) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, userTypingDtoImplementors)
+func (ec *executionContext) _VideoCallScreenShareChangedDto(ctx context.Context, sel ast.SelectionSet, obj *model.VideoCallScreenShareChangedDto) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, videoCallScreenShareChangedDtoImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	deferred := make(map[string]*graphql.FieldSet)
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("UserTypingDto")
-		case "login":
-			out.Values[i] = ec._UserTypingDto_login(ctx, field, obj)
-			if out.Values[i] == graphql.Null {
-				out.Invalids++
-			}
-		case "participantId":
-			out.Values[i] = ec._UserTypingDto_participantId(ctx, field, obj)
+			out.Values[i] = graphql.MarshalString("VideoCallScreenShareChangedDto")
+		case "chatId":
+			out.Values[i] = ec._VideoCallScreenShareChangedDto_chatId(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
-		case "chatId":
-			out.Values[i] = ec._UserTypingDto_chatId(ctx, field, obj)
+		case "hasScreenShares":
+			out.Values[i] = ec._VideoCallScreenShareChangedDto_hasScreenShares(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
@@ -18539,47 +20439,24 @@ func (ec *executionContext) _UserTypingDto(ctx context.Context, sel ast.Selectio
 	return out
 }
 
-var userViewEnrichedDtoImplementors = []string{"UserViewEnrichedDto"}
+var videoDialChangedImplementors = []string{"VideoDialChanged"}
 
-func (ec *executionContext) _UserViewEnrichedDto(ctx context.Context, sel ast.SelectionSet, obj *model.UserViewEnrichedDto) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, userViewEnrichedDtoImplementors)
+func (ec *executionContext) _VideoDialChanged(ctx context.Context, sel ast.SelectionSet, obj *model.VideoDialChanged) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, videoDialChangedImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	deferred := make(map[string]*graphql.FieldSet)
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("UserViewEnrichedDto")
-		case "id":
-			out.Values[i] = ec._UserViewEnrichedDto_id(ctx, field, obj)
-			if out.Values[i] == graphql.Null {
-				out.Invalids++
-			}
-		case "login":
-			out.Values[i] = ec._UserViewEnrichedDto_login(ctx, field, obj)
-			if out.Values[i] == graphql.Null {
-				out.Invalids++
-			}
-		case "avatar":
-			out.Values[i] = ec._UserViewEnrichedDto_avatar(ctx, field, obj)
-		case "admin":
-			out.Values[i] = ec._UserViewEnrichedDto_admin(ctx, field, obj)
-			if out.Values[i] == graphql.Null {
-				out.Invalids++
-			}
-		case "shortInfo":
-			out.Values[i] = ec._UserViewEnrichedDto_shortInfo(ctx, field, obj)
-		case "loginColor":
-			out.Values[i] = ec._UserViewEnrichedDto_loginColor(ctx, field, obj)
-		case "additionalData":
-			out.Values[i] = ec._UserViewEnrichedDto_additionalData(ctx, field, obj)
-		case "canChange":
-			out.Values[i] = ec._UserViewEnrichedDto_canChange(ctx, field, obj)
+			out.Values[i] = graphql.MarshalString("VideoDialChanged")
+		case "userId":
+			out.Values[i] = ec._VideoDialChanged_userId(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
-		case "canDelete":
-			out.Values[i] = ec._UserViewEnrichedDto_canDelete(ctx, field, obj)
+		case "status":
+			out.Values[i] = ec._VideoDialChanged_status(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
@@ -18606,34 +20483,27 @@ func (ec *executionContext) _UserViewEnrichedDto(ctx context.Context, sel ast.Se
 	return out
 }
 
-var videoCallInvitationDtoImplementors = []string{"VideoCallInvitationDto"}
+var videoDialChangesImplementors = []string{"VideoDialChanges"}
 
-func (ec *executionContext) _VideoCallInvitationDto(ctx context.Context, sel ast.SelectionSet, obj *model.VideoCallInvitationDto) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, videoCallInvitationDtoImplementors)
+func (ec *executionContext) _VideoDialChanges(ctx context.Context, sel ast.SelectionSet, obj *model.VideoDialChanges) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, videoDialChangesImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	deferred := make(map[string]*graphql.FieldSet)
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("VideoCallInvitationDto")
+			out.Values[i] = graphql.MarshalString("VideoDialChanges")
 		case "chatId":
-			out.Values[i] = ec._VideoCallInvitationDto_chatId(ctx, field, obj)
-			if out.Values[i] == graphql.Null {
-				out.Invalids++
-			}
-		case "chatName":
-			out.Values[i] = ec._VideoCallInvitationDto_chatName(ctx, field, obj)
+			out.Values[i] = ec._VideoDialChanges_chatId(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
-		case "status":
-			out.Values[i] = ec._VideoCallInvitationDto_status(ctx, field, obj)
+		case "dials":
+			out.Values[i] = ec._VideoDialChanges_dials(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
-		case "avatar":
-			out.Values[i] = ec._VideoCallInvitationDto_avatar(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -18657,24 +20527,29 @@ func (ec *executionContext) _VideoCallInvitationDto(ctx context.Context, sel ast
 	return out
 }
 
-var videoCallScreenShareChangedDtoImplementors = []string{"VideoCallScreenShareChangedDto"}
+var videoRaisedHandDtoImplementors = []string{"VideoRaisedHandDto"}
 
-func (ec *executionContext) _VideoCallScreenShareChangedDto(ctx context.Context, sel ast.SelectionSet, obj *model.VideoCallScreenShareChangedDto) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, videoCallScreenShareChangedDtoImplementors)
+func (ec *executionContext) _VideoRaisedHandDto(ctx context.Context, sel ast.SelectionSet, obj *model.VideoRaisedHandDto) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, videoRaisedHandDtoImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	deferred := make(map[string]*graphql.FieldSet)
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("VideoCallScreenShareChangedDto")
-		case "chatId":
-			out.Values[i] = ec._VideoCallScreenShareChangedDto_chatId(ctx, field, obj)
+			out.Values[i] = graphql.MarshalString("VideoRaisedHandDto")
+		case "userId":
+			out.Values[i] = ec._VideoRaisedHandDto_userId(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
-		case "hasScreenShares":
-			out.Values[i] = ec._VideoCallScreenShareChangedDto_hasScreenShares(ctx, field, obj)
+		case "hasFloor":
+			out.Values[i] = ec._VideoRaisedHandDto_hasFloor(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "raisedAt":
+			out.Values[i] = ec._VideoRaisedHandDto_raisedAt(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
@@ -18701,24 +20576,29 @@ func (ec *executionContext) _VideoCallScreenShareChangedDto(ctx context.Context,
 	return out
 }
 
-var videoDialChangedImplementors = []string{"VideoDialChanged"}
+var videoRaisedHandsChangedDtoImplementors = []string{"VideoRaisedHandsChangedDto"}
 
-func (ec *executionContext) _VideoDialChanged(ctx context.Context, sel ast.SelectionSet, obj *model.VideoDialChanged) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, videoDialChangedImplementors)
+func (ec *executionContext) _VideoRaisedHandsChangedDto(ctx context.Context, sel ast.SelectionSet, obj *model.VideoRaisedHandsChangedDto) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, videoRaisedHandsChangedDtoImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	deferred := make(map[string]*graphql.FieldSet)
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("VideoDialChanged")
-		case "userId":
-			out.Values[i] = ec._VideoDialChanged_userId(ctx, field, obj)
+			out.Values[i] = graphql.MarshalString("VideoRaisedHandsChangedDto")
+		case "chatId":
+			out.Values[i] = ec._VideoRaisedHandsChangedDto_chatId(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
-		case "status":
-			out.Values[i] = ec._VideoDialChanged_status(ctx, field, obj)
+		case "moderated":
+			out.Values[i] = ec._VideoRaisedHandsChangedDto_moderated(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "hands":
+			out.Values[i] = ec._VideoRaisedHandsChangedDto_hands(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
@@ -18745,24 +20625,24 @@ func (ec *executionContext) _VideoDialChanged(ctx context.Context, sel ast.Selec
 	return out
 }
 
-var videoDialChangesImplementors = []string{"VideoDialChanges"}
+var videoRecordingChangedDtoImplementors = []string{"VideoRecordingChangedDto"}
 
-func (ec *executionContext) _VideoDialChanges(ctx context.Context, sel ast.SelectionSet, obj *model.VideoDialChanges) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, videoDialChangesImplementors)
+func (ec *executionContext) _VideoRecordingChangedDto(ctx context.Context, sel ast.SelectionSet, obj *model.VideoRecordingChangedDto) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, videoRecordingChangedDtoImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	deferred := make(map[string]*graphql.FieldSet)
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("VideoDialChanges")
-		case "chatId":
-			out.Values[i] = ec._VideoDialChanges_chatId(ctx, field, obj)
+			out.Values[i] = graphql.MarshalString("VideoRecordingChangedDto")
+		case "recordInProgress":
+			out.Values[i] = ec._VideoRecordingChangedDto_recordInProgress(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
-		case "dials":
-			out.Values[i] = ec._VideoDialChanges_dials(ctx, field, obj)
+		case "chatId":
+			out.Values[i] = ec._VideoRecordingChangedDto_chatId(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
@@ -18789,27 +20669,26 @@ func (ec *executionContext) _VideoDialChanges(ctx context.Context, sel ast.Selec
 	return out
 }
 
-var videoRecordingChangedDtoImplementors = []string{"VideoRecordingChangedDto"}
+var videoRoomChangedDtoImplementors = []string{"VideoRoomChangedDto"}
 
-func (ec *executionContext) _VideoRecordingChangedDto(ctx context.Context, sel ast.SelectionSet, obj *model.VideoRecordingChangedDto) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, videoRecordingChangedDtoImplementors)
+func (ec *executionContext) _VideoRoomChangedDto(ctx context.Context, sel ast.SelectionSet, obj *model.VideoRoomChangedDto) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, videoRoomChangedDtoImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	deferred := make(map[string]*graphql.FieldSet)
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("VideoRecordingChangedDto")
-		case "recordInProgress":
-			out.Values[i] = ec._VideoRecordingChangedDto_recordInProgress(ctx, field, obj)
-			if out.Values[i] == graphql.Null {
-				out.Invalids++
-			}
+			out.Values[i] = graphql.MarshalString("VideoRoomChangedDto")
 		case "chatId":
-			out.Values[i] = ec._VideoRecordingChangedDto_chatId(ctx, field, obj)
+			out.Values[i] = ec._VideoRoomChangedDto_chatId(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
+		case "breakoutRoomId":
+			out.Values[i] = ec._VideoRoomChangedDto_breakoutRoomId(ctx, field, obj)
+		case "breakoutRoomName":
+			out.Values[i] = ec._VideoRoomChangedDto_breakoutRoomName(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -19308,6 +21187,60 @@ func (ec *executionContext) marshalNBoolean2bool(ctx context.Context, sel ast.Se
 	return res
 }
 
+func (ec *executionContext) marshalNBreakoutRoomDto2ᚕᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐBreakoutRoomDtoᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.BreakoutRoomDto) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNBreakoutRoomDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐBreakoutRoomDto(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+
+	for _, e := range ret {
+		if e == graphql.Null {
+			return graphql.Null
+		}
+	}
+
+	return ret
+}
+
+func (ec *executionContext) marshalNBreakoutRoomDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐBreakoutRoomDto(ctx context.Context, sel ast.SelectionSet, v *model.BreakoutRoomDto) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+		return graphql.Null
+	}
+	return ec._BreakoutRoomDto(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalNChatEvent2nkonevᚗnameᚋeventᚋgraphᚋmodelᚐChatEvent(ctx context.Context, sel ast.SelectionSet, v model.ChatEvent) graphql.Marshaler {
 	return ec._ChatEvent(ctx, sel, &v)
 }
@@ -19730,6 +21663,60 @@ func (ec *executionContext) marshalNVideoDialChanged2ᚖnkonevᚗnameᚋeventᚋ
 	return ec._VideoDialChanged(ctx, sel, v)
 }
 
+func (ec *executionContext) marshalNVideoRaisedHandDto2ᚕᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoRaisedHandDtoᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.VideoRaisedHandDto) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNVideoRaisedHandDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoRaisedHandDto(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+
+	for _, e := range ret {
+		if e == graphql.Null {
+			return graphql.Null
+		}
+	}
+
+	return ret
+}
+
+func (ec *executionContext) marshalNVideoRaisedHandDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoRaisedHandDto(ctx context.Context, sel ast.SelectionSet, v *model.VideoRaisedHandDto) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+		return graphql.Null
+	}
+	return ec._VideoRaisedHandDto(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalN__Directive2githubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐDirective(ctx context.Context, sel ast.SelectionSet, v introspection.Directive) graphql.Marshaler {
 	return ec.___Directive(ctx, sel, &v)
 }
@@ -20311,6 +22298,13 @@ func (ec *executionContext) marshalOUserViewEnrichedDto2ᚕᚖnkonevᚗnameᚋev
 	return ret
 }
 
+func (ec *executionContext) marshalOVideoBreakoutRoomsChangedDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoBreakoutRoomsChangedDto(ctx context.Context, sel ast.SelectionSet, v *model.VideoBreakoutRoomsChangedDto) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	return ec._VideoBreakoutRoomsChangedDto(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalOVideoCallInvitationDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoCallInvitationDto(ctx context.Context, sel ast.SelectionSet, v *model.VideoCallInvitationDto) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
@@ -20332,6 +22326,13 @@ func (ec *executionContext) marshalOVideoDialChanges2ᚖnkonevᚗnameᚋeventᚋ
 	return ec._VideoDialChanges(ctx, sel, v)
 }
 
+func (ec *executionContext) marshalOVideoRaisedHandsChangedDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoRaisedHandsChangedDto(ctx context.Context, sel ast.SelectionSet, v *model.VideoRaisedHandsChangedDto) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	return ec._VideoRaisedHandsChangedDto(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalOVideoRecordingChangedDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoRecordingChangedDto(ctx context.Context, sel ast.SelectionSet, v *model.VideoRecordingChangedDto) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
@@ -20339,6 +22340,13 @@ func (ec *executionContext) marshalOVideoRecordingChangedDto2ᚖnkonevᚗnameᚋ
 	return ec._VideoRecordingChangedDto(ctx, sel, v)
 }
 
+func (ec *executionContext) marshalOVideoRoomChangedDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoRoomChangedDto(ctx context.Context, sel ast.SelectionSet, v *model.VideoRoomChangedDto) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	return ec._VideoRoomChangedDto(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalOVideoUserCountChangedDto2ᚖnkonevᚗnameᚋeventᚋgraphᚋmodelᚐVideoUserCountChangedDto(ctx context.Context, sel ast.SelectionSet, v *model.VideoUserCountChangedDto) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
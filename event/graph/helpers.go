@@ -2,6 +2,7 @@ package graph
 
 import (
 	"context"
+	"errors"
 
 	"nkonev.name/event/auth"
 	"nkonev.name/event/dto"
@@ -9,6 +10,8 @@ import (
 	"nkonev.name/event/utils"
 )
 
+var ErrUnauthorized = errors.New("Unauthorized")
+
 func filter(userFromBus int64, userIdsFilter []int64) bool {
 	if len(userIdsFilter) == 0 {
 		return true
@@ -101,10 +104,23 @@ func convertToUserOnline(userOnline dto.UserOnline) *model.UserStatusEvent {
 		LastSeenDateTime: userOnline.LastSeenDateTime,
 	}
 }
+func convertToUserPresence(userPresence dto.UserPresence) *model.UserStatusEvent {
+	idle := userPresence.Idle
+	return &model.UserStatusEvent{
+		EventType:         "user_presence_changed",
+		UserID:            userPresence.UserId,
+		PresenceStatus:    userPresence.Status,
+		PresenceEmoji:     userPresence.Emoji,
+		PresenceText:      userPresence.Text,
+		PresenceExpiresAt: userPresence.ExpiresAt,
+		Idle:              &idle,
+	}
+}
 func convertToChatEvent(e *dto.ChatEvent) *model.ChatEvent {
 	var result = &model.ChatEvent{
 		EventType:     e.EventType,
 		CorrelationID: e.CorrelationId,
+		Seq:           e.Seq,
 	}
 	messageDto := e.MessageNotification
 	if messageDto != nil {
@@ -177,6 +193,9 @@ func convertToChatEvent(e *dto.ChatEvent) *model.ChatEvent {
 				FileItemUUID:   fileEvent.FileInfoDto.FileItemUuid,
 				Previewable:    fileEvent.FileInfoDto.Previewable,
 				AType:          fileEvent.FileInfoDto.Type,
+				ScanStatus:     fileEvent.FileInfoDto.ScanStatus,
+				VersionCount:   fileEvent.FileInfoDto.VersionCount,
+				HlsURL:         fileEvent.FileInfoDto.HlsUrl,
 			},
 		}
 	}
@@ -189,8 +208,60 @@ func convertToChatEvent(e *dto.ChatEvent) *model.ChatEvent {
 		}
 	}
 
+	raisedHandsEvent := e.RaisedHandsEvent
+	if raisedHandsEvent != nil {
+		result.RaisedHandsEvent = convertRaisedHandsEvent(raisedHandsEvent)
+	}
+
+	breakoutRoomsEvent := e.BreakoutRoomsEvent
+	if breakoutRoomsEvent != nil {
+		result.BreakoutRoomsEvent = convertBreakoutRoomsEvent(breakoutRoomsEvent)
+	}
+
+	roomChangedEvent := e.RoomChangedEvent
+	if roomChangedEvent != nil {
+		result.RoomChangedEvent = &model.VideoRoomChangedDto{
+			ChatID:           roomChangedEvent.ChatId,
+			BreakoutRoomID:   roomChangedEvent.BreakoutRoomId,
+			BreakoutRoomName: roomChangedEvent.BreakoutRoomName,
+		}
+	}
+
 	return result
 }
+
+func convertRaisedHandsEvent(e *dto.VideoRaisedHandsChangedDto) *model.VideoRaisedHandsChangedDto {
+	hands := make([]*model.VideoRaisedHandDto, 0, len(e.Hands))
+	for _, hand := range e.Hands {
+		hands = append(hands, &model.VideoRaisedHandDto{
+			UserID:   hand.UserId,
+			HasFloor: hand.HasFloor,
+			RaisedAt: hand.RaisedAt,
+		})
+	}
+	return &model.VideoRaisedHandsChangedDto{
+		ChatID:    e.ChatId,
+		Moderated: e.Moderated,
+		Hands:     hands,
+	}
+}
+
+func convertBreakoutRoomsEvent(e *dto.VideoBreakoutRoomsChangedDto) *model.VideoBreakoutRoomsChangedDto {
+	breakoutRooms := make([]*model.BreakoutRoomDto, 0, len(e.BreakoutRooms))
+	for _, br := range e.BreakoutRooms {
+		breakoutRooms = append(breakoutRooms, &model.BreakoutRoomDto{
+			ID:         br.Id,
+			Name:       br.Name,
+			EndsAt:     br.EndsAt,
+			UserIds:    br.UserIds,
+			UsersCount: br.UsersCount,
+		})
+	}
+	return &model.VideoBreakoutRoomsChangedDto{
+		ChatID:        e.ChatId,
+		BreakoutRooms: breakoutRooms,
+	}
+}
 func convertDisplayMessageDto(messageDto *dto.DisplayMessageDto) *model.DisplayMessageDto {
 	var result = &model.DisplayMessageDto{ // dto.DisplayMessageDto
 		ID:              messageDto.Id,
@@ -285,6 +356,7 @@ func convertToGlobalEvent(e *dto.GlobalUserEvent) *model.GlobalEvent {
 	var ret = &model.GlobalEvent{
 		EventType:     e.EventType,
 		CorrelationID: e.CorrelationId,
+		Seq:           e.Seq,
 	}
 	chatEvent := e.ChatNotification
 	if chatEvent != nil {
@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nkonev.name/event/dto"
+)
+
+func TestConvertRoomChangedEvent(t *testing.T) {
+	cases := []struct {
+		name             string
+		breakoutRoomId   *int64
+		breakoutRoomName *string
+	}{
+		{"to the breakout room", ptr(int64(3)), ptr("first")},
+		{"back to the main room", nil, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result := convertToChatEvent(&dto.ChatEvent{
+				EventType: "video_room_changed",
+				ChatId:    1,
+				RoomChangedEvent: &dto.VideoRoomChangedDto{
+					ChatId:           1,
+					BreakoutRoomId:   c.breakoutRoomId,
+					BreakoutRoomName: c.breakoutRoomName,
+				},
+			})
+
+			require.NotNil(t, result.RoomChangedEvent)
+			assert.Equal(t, "video_room_changed", result.EventType)
+			assert.Equal(t, int64(1), result.RoomChangedEvent.ChatID)
+			assert.Equal(t, c.breakoutRoomId, result.RoomChangedEvent.BreakoutRoomID)
+			assert.Equal(t, c.breakoutRoomName, result.RoomChangedEvent.BreakoutRoomName)
+		})
+	}
+}
@@ -22,6 +22,14 @@ type AllUnreadMessages struct {
 	AllUnreadMessages int64 `json:"allUnreadMessages"`
 }
 
+type BreakoutRoomDto struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	EndsAt     *time.Time `json:"endsAt"`
+	UserIds    []int64    `json:"userIds"`
+	UsersCount int64      `json:"usersCount"`
+}
+
 type BrowserNotification struct {
 	ChatID      int64   `json:"chatId"`
 	ChatName    string  `json:"chatName"`
@@ -80,6 +88,7 @@ type ChatDto struct {
 type ChatEvent struct {
 	EventType             string                        `json:"eventType"`
 	CorrelationID         *string                       `json:"correlationId"`
+	Seq                   *int64                        `json:"seq"`
 	MessageEvent          *DisplayMessageDto            `json:"messageEvent"`
 	MessageDeletedEvent   *MessageDeletedDto            `json:"messageDeletedEvent"`
 	MessageBroadcastEvent *MessageBroadcastNotification `json:"messageBroadcastEvent"`
@@ -89,6 +98,9 @@ type ChatEvent struct {
 	FileEvent             *WrappedFileInfoDto           `json:"fileEvent"`
 	PublishedMessageEvent *PublishedMessageEvent        `json:"publishedMessageEvent"`
 	ReactionChangedEvent  *ReactionChangedEvent         `json:"reactionChangedEvent"`
+	RaisedHandsEvent      *VideoRaisedHandsChangedDto   `json:"raisedHandsEvent"`
+	BreakoutRoomsEvent    *VideoBreakoutRoomsChangedDto `json:"breakoutRoomsEvent"`
+	RoomChangedEvent      *VideoRoomChangedDto          `json:"roomChangedEvent"`
 }
 
 type ChatNotificationSettingsChanged struct {
@@ -159,6 +171,9 @@ type FileInfoDto struct {
 	FileItemUUID   string       `json:"fileItemUuid"`
 	Previewable    bool         `json:"previewable"`
 	AType          *string      `json:"aType"`
+	ScanStatus     string       `json:"scanStatus"`
+	VersionCount   int          `json:"versionCount"`
+	HlsURL         *string      `json:"hlsUrl"`
 }
 
 type ForceLogoutEvent struct {
@@ -168,6 +183,7 @@ type ForceLogoutEvent struct {
 type GlobalEvent struct {
 	EventType                       string                           `json:"eventType"`
 	CorrelationID                   *string                          `json:"correlationId"`
+	Seq                             *int64                           `json:"seq"`
 	ChatEvent                       *ChatDto                         `json:"chatEvent"`
 	ChatDeletedEvent                *ChatDeletedDto                  `json:"chatDeletedEvent"`
 	ChatTetATetUpsertedEvent        *ChatTetATetUpsertedDto          `json:"chatTetATetUpsertedEvent"`
@@ -328,11 +344,16 @@ type UserDeletedDto struct {
 func (UserDeletedDto) IsUserAccountEventDto() {}
 
 type UserStatusEvent struct {
-	UserID           int64      `json:"userId"`
-	Online           *bool      `json:"online"`
-	IsInVideo        *bool      `json:"isInVideo"`
-	LastSeenDateTime *time.Time `json:"lastSeenDateTime"`
-	EventType        string     `json:"eventType"`
+	UserID            int64      `json:"userId"`
+	Online            *bool      `json:"online"`
+	IsInVideo         *bool      `json:"isInVideo"`
+	LastSeenDateTime  *time.Time `json:"lastSeenDateTime"`
+	EventType         string     `json:"eventType"`
+	PresenceStatus    *string    `json:"presenceStatus"`
+	PresenceEmoji     *string    `json:"presenceEmoji"`
+	PresenceText      *string    `json:"presenceText"`
+	PresenceExpiresAt *time.Time `json:"presenceExpiresAt"`
+	Idle              *bool      `json:"idle"`
 }
 
 type UserTypingDto struct {
@@ -353,6 +374,11 @@ type UserViewEnrichedDto struct {
 	CanDelete      bool            `json:"canDelete"`
 }
 
+type VideoBreakoutRoomsChangedDto struct {
+	ChatID        int64              `json:"chatId"`
+	BreakoutRooms []*BreakoutRoomDto `json:"breakoutRooms"`
+}
+
 type VideoCallInvitationDto struct {
 	ChatID   int64   `json:"chatId"`
 	ChatName string  `json:"chatName"`
@@ -375,11 +401,29 @@ type VideoDialChanges struct {
 	Dials  []*VideoDialChanged `json:"dials"`
 }
 
+type VideoRaisedHandDto struct {
+	UserID   int64     `json:"userId"`
+	HasFloor bool      `json:"hasFloor"`
+	RaisedAt time.Time `json:"raisedAt"`
+}
+
+type VideoRaisedHandsChangedDto struct {
+	ChatID    int64                 `json:"chatId"`
+	Moderated bool                  `json:"moderated"`
+	Hands     []*VideoRaisedHandDto `json:"hands"`
+}
+
 type VideoRecordingChangedDto struct {
 	RecordInProgress bool  `json:"recordInProgress"`
 	ChatID           int64 `json:"chatId"`
 }
 
+type VideoRoomChangedDto struct {
+	ChatID           int64   `json:"chatId"`
+	BreakoutRoomID   *int64  `json:"breakoutRoomId"`
+	BreakoutRoomName *string `json:"breakoutRoomName"`
+}
+
 type VideoUserCountChangedDto struct {
 	UsersCount int64 `json:"usersCount"`
 	ChatID     int64 `json:"chatId"`
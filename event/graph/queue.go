@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"nkonev.name/event/graph/model"
+	"nkonev.name/event/subscription"
+)
+
+// the keys for coalescing and the hints for the overflowed subscriptions
+
+func chatEventType(e *model.ChatEvent) string {
+	return e.EventType
+}
+
+func globalEventType(e *model.GlobalEvent) string {
+	return e.EventType
+}
+
+// the batch contains the events of the same type
+func userStatusEventType(batch []*model.UserStatusEvent) string {
+	if len(batch) == 0 {
+		return ""
+	}
+	return batch[0].EventType
+}
+
+// the later status of the user replaces the queued one, the statuses of the other users are kept
+func mergeUserStatuses(queued, batch []*model.UserStatusEvent) []*model.UserStatusEvent {
+	updated := map[int64]bool{}
+	for _, e := range batch {
+		updated[e.UserID] = true
+	}
+	merged := make([]*model.UserStatusEvent, 0, len(queued)+len(batch))
+	for _, e := range queued {
+		if !updated[e.UserID] {
+			merged = append(merged, e)
+		}
+	}
+	return append(merged, batch...)
+}
+
+func userAccountEventType(e *model.UserAccountEvent) string {
+	return e.EventType
+}
+
+func chatResyncRequired() *model.ChatEvent {
+	return &model.ChatEvent{EventType: subscription.ResyncRequiredEventType}
+}
+
+func globalResyncRequired() *model.GlobalEvent {
+	return &model.GlobalEvent{EventType: subscription.ResyncRequiredEventType}
+}
+
+func userStatusResyncRequired() []*model.UserStatusEvent {
+	return []*model.UserStatusEvent{{EventType: subscription.ResyncRequiredEventType}}
+}
+
+func userAccountResyncRequired() *model.UserAccountEvent {
+	return &model.UserAccountEvent{EventType: subscription.ResyncRequiredEventType}
+}
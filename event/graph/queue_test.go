@@ -0,0 +1,19 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"nkonev.name/event/graph/model"
+)
+
+func TestMergeUserStatuses(t *testing.T) {
+	a := &model.UserStatusEvent{UserID: 1, EventType: "user_online", Online: ptr(true)}
+	b := &model.UserStatusEvent{UserID: 2, EventType: "user_online", Online: ptr(true)}
+	aOffline := &model.UserStatusEvent{UserID: 1, EventType: "user_online", Online: ptr(false)}
+	c := &model.UserStatusEvent{UserID: 3, EventType: "user_online", Online: ptr(true)}
+
+	merged := mergeUserStatuses([]*model.UserStatusEvent{a, b}, []*model.UserStatusEvent{aOffline, c})
+
+	assert.Equal(t, []*model.UserStatusEvent{b, aOffline, c}, merged)
+}
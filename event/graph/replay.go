@@ -0,0 +1,113 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+
+	"nkonev.name/event/dto"
+	"nkonev.name/event/graph/model"
+	"nkonev.name/event/logger"
+	"nkonev.name/event/replay"
+	"nkonev.name/event/subscription"
+)
+
+// the client should reload the data because the missed events can't be replayed
+const refreshRequiredEventType = "refresh_required"
+
+// the live events are queued till the end of the replay, the gate skips the ones which have been replayed
+type replayGate struct {
+	ready   chan struct{}
+	lastSeq int64 // is written before ready is closed
+}
+
+func newReplayGate() *replayGate {
+	return &replayGate{
+		ready: make(chan struct{}),
+	}
+}
+
+// returns false in case the live event has been already replayed
+func (g *replayGate) passLive(seq *int64) bool {
+	<-g.ready
+	return seq == nil || *seq > g.lastSeq
+}
+
+func replayEvents[T any](
+	ctx context.Context,
+	lgr *logger.Logger,
+	buffer *replay.Buffer,
+	gate *replayGate,
+	queue *subscription.Queue[*T],
+	stream string,
+	since *int64,
+	convert func(entry *replay.Entry) (*T, error),
+	refreshRequired func() *T,
+) {
+	defer close(gate.ready)
+
+	if since == nil {
+		return
+	}
+
+	send := func(event *T) bool {
+		return queue.Send(ctx, event)
+	}
+
+	if !buffer.Enabled() {
+		send(refreshRequired())
+		return
+	}
+
+	entries, gap, err := buffer.Since(ctx, stream, *since)
+	if err != nil {
+		lgr.WithTracing(ctx).Errorf("Error during getting the replay of stream %v: %v", stream, err)
+		send(refreshRequired())
+		return
+	}
+	if gap {
+		lgr.WithTracing(ctx).Infof("The gap after %v in stream %v is too large, requesting the refresh", *since, stream)
+		send(refreshRequired())
+		return
+	}
+
+	for _, entry := range entries {
+		event, err := convert(&entry)
+		if err != nil {
+			lgr.WithTracing(ctx).Errorf("Error during converting the replay entry %v of stream %v: %v", entry.Seq, stream, err)
+			send(refreshRequired())
+			return
+		}
+		if !send(event) {
+			return
+		}
+		gate.lastSeq = entry.Seq
+	}
+}
+
+func convertReplayedChatEvent(entry *replay.Entry) (*model.ChatEvent, error) {
+	var event dto.ChatEvent
+	if err := json.Unmarshal(entry.Envelope.Body, &event); err != nil {
+		return nil, err
+	}
+	event.CorrelationId = entry.Envelope.CorrelationId
+	event.Seq = &entry.Seq
+	return convertToChatEvent(&event), nil
+}
+
+func convertReplayedGlobalEvent(entry *replay.Entry) (*model.GlobalEvent, error) {
+	var event dto.GlobalUserEvent
+	if err := json.Unmarshal(entry.Envelope.Body, &event); err != nil {
+		return nil, err
+	}
+	event.CorrelationId = entry.Envelope.CorrelationId
+	event.Seq = &entry.Seq
+	return convertToGlobalEvent(&event), nil
+}
+
+func chatRefreshRequired() *model.ChatEvent {
+	return &model.ChatEvent{EventType: refreshRequiredEventType}
+}
+
+func globalRefreshRequired() *model.GlobalEvent {
+	return &model.GlobalEvent{EventType: refreshRequiredEventType}
+}
@@ -0,0 +1,159 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nkonev.name/event/dto"
+	"nkonev.name/event/graph/model"
+	"nkonev.name/event/logger"
+	"nkonev.name/event/replay"
+	"nkonev.name/event/subscription"
+)
+
+const testStream = "chat:1:10"
+
+func newTestReplayBuffer(t *testing.T, enabled bool, maxSize int) *replay.Buffer {
+	viper.Set("replay.enabled", enabled)
+	viper.Set("replay.maxSize", maxSize)
+	viper.Set("replay.ttl", time.Hour)
+	viper.Set("replay.deliveryTtl", time.Minute)
+	viper.Set("subscription."+subscription.ChatEvents+".queueSize", 16)
+	viper.Set("subscription."+subscription.ChatEvents+".overflowPolicy", string(subscription.Disconnect))
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+	})
+	return replay.NewBuffer(client, logger.NewLogger())
+}
+
+// appends the message_created events with the seqs 1..n
+func appendChatEvents(t *testing.T, buffer *replay.Buffer, n int) {
+	for i := 1; i <= n; i++ {
+		body, err := json.Marshal(dto.ChatEvent{EventType: "message_created", ChatId: 1, UserId: 10})
+		require.NoError(t, err)
+		_, err = buffer.Append(context.Background(), testStream, fmt.Sprintf("d%v", i), &replay.Envelope{Body: body})
+		require.NoError(t, err)
+	}
+}
+
+func liveChatEvent(eventType string, seq *int64) *model.ChatEvent {
+	return &model.ChatEvent{EventType: eventType, Seq: seq}
+}
+
+// runs the delivery the same way the ChatEvents resolver does and collects what the client gets
+func runChatSubscription(t *testing.T, buffer *replay.Buffer, since *int64, live ...*model.ChatEvent) []*model.ChatEvent {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	queue := subscription.NewQueue(subscription.ChatEvents, chatEventType, chatResyncRequired)
+	gate := newReplayGate()
+	// the live events which have arrived while the replay is being read
+	for _, e := range live {
+		queue.Push(e)
+	}
+
+	go func() {
+		replayEvents(ctx, logger.NewLogger(), buffer, gate, queue, testStream, since, convertReplayedChatEvent, chatRefreshRequired)
+		queue.Run(ctx, func(e *model.ChatEvent) bool { return gate.passLive(e.Seq) })
+		queue.Close()
+	}()
+
+	received := []*model.ChatEvent{}
+	for e := range queue.Out() {
+		received = append(received, e)
+		if e.EventType == "done" {
+			cancel()
+		}
+	}
+	require.NotErrorIs(t, ctx.Err(), context.DeadlineExceeded, "the subscription hasn't been finished in time")
+	return received
+}
+
+func seqs(events []*model.ChatEvent) []string {
+	result := []string{}
+	for _, e := range events {
+		if e.Seq != nil {
+			result = append(result, fmt.Sprintf("%v:%v", e.EventType, *e.Seq))
+		} else {
+			result = append(result, e.EventType)
+		}
+	}
+	return result
+}
+
+func TestReplayThenLive(t *testing.T) {
+	buffer := newTestReplayBuffer(t, true, 10)
+	appendChatEvents(t, buffer, 4)
+
+	received := runChatSubscription(t, buffer, ptr(int64(1)),
+		liveChatEvent("message_created", ptr(int64(3))), // is in the replay as well
+		liveChatEvent("user_typing", nil),
+		liveChatEvent("message_created", ptr(int64(4))), // is in the replay as well
+		liveChatEvent("message_created", ptr(int64(5))),
+		liveChatEvent("done", nil),
+	)
+
+	assert.Equal(t, []string{
+		"message_created:2",
+		"message_created:3",
+		"message_created:4",
+		"user_typing",
+		"message_created:5",
+		"done",
+	}, seqs(received))
+}
+
+func TestNoReplayWithoutSince(t *testing.T) {
+	buffer := newTestReplayBuffer(t, true, 10)
+	appendChatEvents(t, buffer, 4)
+
+	received := runChatSubscription(t, buffer, nil,
+		liveChatEvent("message_created", ptr(int64(5))),
+		liveChatEvent("done", nil),
+	)
+
+	assert.Equal(t, []string{"message_created:5", "done"}, seqs(received))
+}
+
+func TestRefreshRequired(t *testing.T) {
+	cases := []struct {
+		name    string
+		enabled bool
+		since   int64
+	}{
+		{"disabled", false, 1},
+		{"evicted", true, 1},
+		{"ahead of the sequence", true, 100},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buffer := newTestReplayBuffer(t, c.enabled, 2)
+			if c.enabled {
+				appendChatEvents(t, buffer, 4)
+			}
+
+			received := runChatSubscription(t, buffer, ptr(c.since),
+				liveChatEvent("message_created", ptr(int64(5))),
+				liveChatEvent("done", nil),
+			)
+
+			// the live events are delivered after the hint
+			assert.Equal(t, []string{refreshRequiredEventType, "message_created:5", "done"}, seqs(received))
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
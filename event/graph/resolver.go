@@ -7,6 +7,9 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"nkonev.name/event/client"
 	"nkonev.name/event/logger"
+	"nkonev.name/event/presence"
+	"nkonev.name/event/replay"
+	"nkonev.name/event/subscription"
 )
 
 // This file will not be regenerated automatically.
@@ -14,8 +17,11 @@ import (
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	Bus        *eventbus.Bus
-	HttpClient *client.RestClient
-	Tr         trace.Tracer
-	Lgr        *logger.Logger
+	Bus          *eventbus.Bus
+	HttpClient   *client.RestClient
+	Tr           trace.Tracer
+	Lgr          *logger.Logger
+	ReplayBuffer *replay.Buffer
+	Presence     *presence.Store
+	Router       *subscription.Router
 }
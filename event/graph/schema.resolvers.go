@@ -16,6 +16,8 @@ import (
 	"nkonev.name/event/dto"
 	"nkonev.name/event/graph/model"
 	"nkonev.name/event/rabbitmq"
+	"nkonev.name/event/replay"
+	"nkonev.name/event/subscription"
 	"nkonev.name/event/utils"
 )
 
@@ -26,7 +28,7 @@ func (r *queryResolver) Ping(ctx context.Context) (*bool, error) {
 }
 
 // ChatEvents is the resolver for the chatEvents field.
-func (r *subscriptionResolver) ChatEvents(ctx context.Context, chatID int64) (<-chan *model.ChatEvent, error) {
+func (r *subscriptionResolver) ChatEvents(ctx context.Context, chatID int64, since *int64) (<-chan *model.ChatEvent, error) {
 	authResult, ok := ctx.Value(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
 	if !ok {
 		return nil, errors.New("Unable to get auth context")
@@ -39,94 +41,78 @@ func (r *subscriptionResolver) ChatEvents(ctx context.Context, chatID int64) (<-
 	}
 	if !hasAccess {
 		r.Lgr.WithTracing(ctx).Infof("User %v is not participant of chat %v", authResult.UserId, chatID)
-		return nil, errors.New("Unauthorized")
+		return nil, ErrUnauthorized
 	}
 	r.Lgr.WithTracing(ctx).Infof("Subscribing to chatEvents channel as user %v", authResult.UserId)
 
-	var cam = make(chan *model.ChatEvent)
-	subscribeHandler, err := r.Bus.Subscribe(dto.CHAT_EVENTS, func(event eventbus.Event, t time.Time) {
+	queue := subscription.NewQueue(subscription.ChatEvents, chatEventType, chatResyncRequired)
+	gate := newReplayGate()
+	// the router calls it only for the events of this user and chat
+	unsubscribe := r.Router.Chats.Subscribe(subscription.ChatKey{ChatId: chatID, UserId: authResult.UserId}, func(typedEvent dto.ChatEvent) {
 		defer func() {
 			if err := recover(); err != nil {
 				r.Lgr.WithTracing(ctx).Errorf("In processing ChatEvents panic recovered: %v", err)
 			}
 		}()
 
-		switch typedEvent := event.(type) {
-		case dto.ChatEvent:
-			if isReceiverOfEvent(typedEvent.UserId, authResult) && typedEvent.ChatId == chatID {
-				_, span := r.Tr.Start(rabbitmq.DeserializeValues(ctx, r.Lgr, typedEvent.TraceString), fmt.Sprintf("subscription.%s", typedEvent.EventType))
-				defer span.End()
-				span.SetAttributes(
-					attribute.Int64("userId", typedEvent.UserId),
-					attribute.Int64("chatId", typedEvent.ChatId),
-				)
+		_, span := r.Tr.Start(rabbitmq.DeserializeValues(ctx, r.Lgr, typedEvent.TraceString), fmt.Sprintf("subscription.%s", typedEvent.EventType))
+		defer span.End()
+		span.SetAttributes(
+			attribute.Int64("userId", typedEvent.UserId),
+			attribute.Int64("chatId", typedEvent.ChatId),
+		)
 
-				cam <- convertToChatEvent(&typedEvent)
-			}
-			break
-		default:
-			r.Lgr.WithTracing(ctx).Debugf("Skipping %v as is no mapping here for this type, user %v, chat %v", typedEvent, authResult.UserId, chatID)
-		}
+		queue.Push(convertToChatEvent(&typedEvent))
 	})
-	if err != nil {
-		r.Lgr.WithTracing(ctx).Errorf("Error during creating eventbus subscription user %v, chat %v", authResult.UserId, chatID)
-		return nil, err
-	}
 
 	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				r.Lgr.WithTracing(ctx).Infof("Closing chatEvents channel for user %v", authResult.UserId)
-				err := r.Bus.Unsubscribe(subscribeHandler)
-				if err != nil {
-					r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in chatEvents channel for user %v", authResult.UserId)
-				}
-				close(cam)
-				return
-			}
+		replayEvents(ctx, r.Lgr, r.ReplayBuffer, gate, queue, replay.ChatStream(authResult.UserId, chatID), since, convertReplayedChatEvent, chatRefreshRequired)
+
+		// the live events are queued during the replay, the replayed ones are skipped
+		if queue.Run(ctx, func(e *model.ChatEvent) bool { return gate.passLive(e.Seq) }) {
+			r.Lgr.WithTracing(ctx).Warnf("Disconnecting the slow chatEvents client of user %v", authResult.UserId)
 		}
+
+		r.Lgr.WithTracing(ctx).Infof("Closing chatEvents channel for user %v", authResult.UserId)
+		unsubscribe()
+		queue.Close()
 	}()
 
-	return cam, nil
+	return queue.Out(), nil
 }
 
 // GlobalEvents is the resolver for the globalEvents field.
-func (r *subscriptionResolver) GlobalEvents(ctx context.Context) (<-chan *model.GlobalEvent, error) {
+func (r *subscriptionResolver) GlobalEvents(ctx context.Context, since *int64) (<-chan *model.GlobalEvent, error) {
 	authResult, ok := ctx.Value(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
 	if !ok {
 		return nil, errors.New("Unable to get auth context")
 	}
 	r.Lgr.WithTracing(ctx).Infof("Subscribing to globalEvents channel as user %v", authResult.UserId)
 
-	var cam = make(chan *model.GlobalEvent)
-	globalSubscribeHandler, err := r.Bus.Subscribe(dto.GLOBAL_USER_EVENTS, func(event eventbus.Event, t time.Time) {
+	// opening the application is the activity
+	err := r.Presence.Touch(ctx, authResult.UserId)
+	if err != nil {
+		r.Lgr.WithTracing(ctx).Errorf("Error during touching the presence of user %v: %v", authResult.UserId, err)
+	}
+
+	queue := subscription.NewQueue(subscription.GlobalEvents, globalEventType, globalResyncRequired)
+	gate := newReplayGate()
+	// the router calls it only for the events of this user
+	unsubscribe := r.Router.Users.Subscribe(authResult.UserId, func(typedEvent dto.GlobalUserEvent) {
 		defer func() {
 			if err := recover(); err != nil {
 				r.Lgr.WithTracing(ctx).Errorf("In processing GlobalEvents panic recovered: %v", err)
 			}
 		}()
 
-		switch typedEvent := event.(type) {
-		case dto.GlobalUserEvent:
-			if isReceiverOfEvent(typedEvent.UserId, authResult) {
-				_, span := r.Tr.Start(rabbitmq.DeserializeValues(ctx, r.Lgr, typedEvent.TraceString), fmt.Sprintf("subscription.%s", typedEvent.EventType))
-				defer span.End()
-				span.SetAttributes(
-					attribute.Int64("userId", typedEvent.UserId),
-				)
+		_, span := r.Tr.Start(rabbitmq.DeserializeValues(ctx, r.Lgr, typedEvent.TraceString), fmt.Sprintf("subscription.%s", typedEvent.EventType))
+		defer span.End()
+		span.SetAttributes(
+			attribute.Int64("userId", typedEvent.UserId),
+		)
 
-				cam <- convertToGlobalEvent(&typedEvent)
-			}
-			break
-		default:
-			r.Lgr.WithTracing(ctx).Debugf("Skipping %v as is no mapping here for this type, user %v", typedEvent, authResult.UserId)
-		}
+		queue.Push(convertToGlobalEvent(&typedEvent))
 	})
-	if err != nil {
-		r.Lgr.WithTracing(ctx).Errorf("Error during creating eventbus subscription user %v", authResult.UserId)
-		return nil, err
-	}
 	killSessionsSubscribeHandler, err := r.Bus.Subscribe(dto.AAA_KILL_SESSIONS, func(event eventbus.Event, t time.Time) {
 		defer func() {
 			if err := recover(); err != nil {
@@ -143,7 +129,7 @@ func (r *subscriptionResolver) GlobalEvents(ctx context.Context) (<-chan *model.
 					attribute.Int64("userId", typedEvent.UserId),
 				)
 
-				cam <- convertToUserSessionsKilledEvent(&typedEvent)
+				queue.Push(convertToUserSessionsKilledEvent(&typedEvent))
 			}
 			break
 		default:
@@ -156,29 +142,26 @@ func (r *subscriptionResolver) GlobalEvents(ctx context.Context) (<-chan *model.
 	}
 
 	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-
-				r.Lgr.WithTracing(ctx).Infof("Closing globalEvents channel for user %v", authResult.UserId)
-				err := r.Bus.Unsubscribe(globalSubscribeHandler)
-				if err != nil {
-					r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in globalEvents channel for user %v", authResult.UserId)
-				}
+		replayEvents(ctx, r.Lgr, r.ReplayBuffer, gate, queue, replay.GlobalStream(authResult.UserId), since, convertReplayedGlobalEvent, globalRefreshRequired)
 
-				r.Lgr.WithTracing(ctx).Infof("Closing killSessionsSubscribeHandler channel for user %v", authResult.UserId)
-				err = r.Bus.Unsubscribe(killSessionsSubscribeHandler)
-				if err != nil {
-					r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserVideoStatus channel for user %v", authResult.UserId)
-				}
+		// the live events are queued during the replay, the replayed ones are skipped
+		if queue.Run(ctx, func(e *model.GlobalEvent) bool { return gate.passLive(e.Seq) }) {
+			r.Lgr.WithTracing(ctx).Warnf("Disconnecting the slow globalEvents client of user %v", authResult.UserId)
+		}
 
-				close(cam)
-				return
-			}
+		r.Lgr.WithTracing(ctx).Infof("Closing globalEvents channel for user %v", authResult.UserId)
+		unsubscribe()
+
+		r.Lgr.WithTracing(ctx).Infof("Closing killSessionsSubscribeHandler channel for user %v", authResult.UserId)
+		err := r.Bus.Unsubscribe(killSessionsSubscribeHandler)
+		if err != nil {
+			r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserVideoStatus channel for user %v", authResult.UserId)
 		}
+
+		queue.Close()
 	}()
 
-	return cam, nil
+	return queue.Out(), nil
 }
 
 // UserStatusEvents is the resolver for the userStatusEvents field.
@@ -190,7 +173,7 @@ func (r *subscriptionResolver) UserStatusEvents(ctx context.Context, userIds []i
 	}
 	r.Lgr.WithTracing(ctx).Infof("Subscribing to UserOnline channel as user %v", authResult.UserId)
 
-	var cam = make(chan []*model.UserStatusEvent)
+	queue := subscription.NewMergingQueue(subscription.UserStatusEvents, userStatusEventType, mergeUserStatuses, userStatusResyncRequired)
 
 	subscribeHandlerUserOnline, err := r.Bus.Subscribe(dto.USER_ONLINE, func(event eventbus.Event, t time.Time) {
 		defer func() {
@@ -214,7 +197,41 @@ func (r *subscriptionResolver) UserStatusEvents(ctx context.Context, userIds []i
 				}
 			}
 			if len(batch) > 0 {
-				cam <- batch
+				queue.Push(batch)
+			}
+			break
+		default:
+			r.Lgr.WithTracing(ctx).Debugf("Skipping %v as is no mapping here for this type, user %v", typedEvent, authResult.UserId)
+		}
+	})
+	if err != nil {
+		r.Lgr.WithTracing(ctx).Errorf("Error during creating eventbus subscription user %v", authResult.UserId)
+		return nil, err
+	}
+
+	subscribeHandlerUserPresence, err := r.Bus.Subscribe(dto.USER_PRESENCE, func(event eventbus.Event, t time.Time) {
+		defer func() {
+			if err := recover(); err != nil {
+				r.Lgr.WithTracing(ctx).Errorf("In processing UserPresence panic recovered: %v", err)
+			}
+		}()
+
+		switch typedEvent := event.(type) {
+		case dto.ArrayUserPresence:
+			var batch = []*model.UserStatusEvent{}
+			for _, userPresence := range typedEvent.UserPresences {
+				if utils.Contains(userIds, userPresence.UserId) {
+					_, span := r.Tr.Start(rabbitmq.DeserializeValues(ctx, r.Lgr, typedEvent.TraceString), fmt.Sprintf("subscription.%s", "user_presence_changed"))
+					defer span.End()
+					span.SetAttributes(
+						attribute.Int64("userId", userPresence.UserId),
+					)
+
+					batch = append(batch, convertToUserPresence(userPresence))
+				}
+			}
+			if len(batch) > 0 {
+				queue.Push(batch)
 			}
 			break
 		default:
@@ -250,7 +267,7 @@ func (r *subscriptionResolver) UserStatusEvents(ctx context.Context, userIds []i
 					}
 				}
 				if len(batch) > 0 {
-					cam <- batch
+					queue.Push(batch)
 				}
 			}
 			break
@@ -264,28 +281,44 @@ func (r *subscriptionResolver) UserStatusEvents(ctx context.Context, userIds []i
 	}
 
 	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				r.Lgr.WithTracing(ctx).Infof("Closing UserOnline channel for user %v", authResult.UserId)
-				err := r.Bus.Unsubscribe(subscribeHandlerUserOnline)
-				if err != nil {
-					r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserOnline channel for user %v", authResult.UserId)
-				}
+		// the current presences, the changes come after them
+		presences, err := r.Presence.Get(ctx, userIds)
+		if err != nil {
+			r.Lgr.WithTracing(ctx).Errorf("Error during getting presences for user %v: %v", authResult.UserId, err)
+		} else if len(presences) > 0 {
+			var batch = []*model.UserStatusEvent{}
+			for _, userPresence := range presences {
+				batch = append(batch, convertToUserPresence(userPresence))
+			}
+			queue.Send(ctx, batch)
+		}
 
-				r.Lgr.WithTracing(ctx).Infof("Closing UserVideoStatus channel for user %v", authResult.UserId)
-				err = r.Bus.Unsubscribe(subscribeHandlerVideoCallStatus)
-				if err != nil {
-					r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserVideoStatus channel for user %v", authResult.UserId)
-				}
+		if queue.Run(ctx, nil) {
+			r.Lgr.WithTracing(ctx).Warnf("Disconnecting the slow UserStatus client of user %v", authResult.UserId)
+		}
 
-				close(cam)
-				return
-			}
+		r.Lgr.WithTracing(ctx).Infof("Closing UserOnline channel for user %v", authResult.UserId)
+		err = r.Bus.Unsubscribe(subscribeHandlerUserOnline)
+		if err != nil {
+			r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserOnline channel for user %v", authResult.UserId)
 		}
+
+		r.Lgr.WithTracing(ctx).Infof("Closing UserPresence channel for user %v", authResult.UserId)
+		err = r.Bus.Unsubscribe(subscribeHandlerUserPresence)
+		if err != nil {
+			r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserPresence channel for user %v", authResult.UserId)
+		}
+
+		r.Lgr.WithTracing(ctx).Infof("Closing UserVideoStatus channel for user %v", authResult.UserId)
+		err = r.Bus.Unsubscribe(subscribeHandlerVideoCallStatus)
+		if err != nil {
+			r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserVideoStatus channel for user %v", authResult.UserId)
+		}
+
+		queue.Close()
 	}()
 
-	return cam, nil
+	return queue.Out(), nil
 }
 
 // UserAccountEvents is the resolver for the userAccountEvents field.
@@ -296,7 +329,7 @@ func (r *subscriptionResolver) UserAccountEvents(ctx context.Context, userIdsFil
 	}
 	r.Lgr.WithTracing(ctx).Infof("Subscribing to UserAccount channel as user %v", authResult.UserId)
 
-	var cam = make(chan *model.UserAccountEvent)
+	queue := subscription.NewQueue(subscription.UserAccountEvents, userAccountEventType, userAccountResyncRequired)
 
 	subscribeHandlerAaaChange, err := r.Bus.Subscribe(dto.AAA_CHANGE, func(event eventbus.Event, t time.Time) {
 		defer func() {
@@ -316,7 +349,7 @@ func (r *subscriptionResolver) UserAccountEvents(ctx context.Context, userIdsFil
 						attribute.Int64("userId", typedEvent.UserId),
 					)
 
-					cam <- anEvent
+					queue.Push(anEvent)
 				}
 			}
 			break
@@ -347,7 +380,7 @@ func (r *subscriptionResolver) UserAccountEvents(ctx context.Context, userIdsFil
 						attribute.Int64("userId", typedEvent.UserId),
 					)
 
-					cam <- anEvent
+					queue.Push(anEvent)
 				}
 			}
 			break
@@ -378,7 +411,7 @@ func (r *subscriptionResolver) UserAccountEvents(ctx context.Context, userIdsFil
 						attribute.Int64("userId", typedEvent.UserId),
 					)
 
-					cam <- anEvent
+					queue.Push(anEvent)
 				}
 			}
 		default:
@@ -391,34 +424,32 @@ func (r *subscriptionResolver) UserAccountEvents(ctx context.Context, userIdsFil
 	}
 
 	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				r.Lgr.WithTracing(ctx).Infof("Closing UserAccount change channel for user %v", authResult.UserId)
-				err := r.Bus.Unsubscribe(subscribeHandlerAaaChange)
-				if err != nil {
-					r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserAccount change channel for user %v", authResult.UserId)
-				}
+		if queue.Run(ctx, nil) {
+			r.Lgr.WithTracing(ctx).Warnf("Disconnecting the slow UserAccount client of user %v", authResult.UserId)
+		}
 
-				r.Lgr.WithTracing(ctx).Infof("Closing UserAccount create channel for user %v", authResult.UserId)
-				err = r.Bus.Unsubscribe(subscribeHandlerAaaCreate)
-				if err != nil {
-					r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserAccount create channel for user %v", authResult.UserId)
-				}
+		r.Lgr.WithTracing(ctx).Infof("Closing UserAccount change channel for user %v", authResult.UserId)
+		err := r.Bus.Unsubscribe(subscribeHandlerAaaChange)
+		if err != nil {
+			r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserAccount change channel for user %v", authResult.UserId)
+		}
 
-				r.Lgr.WithTracing(ctx).Infof("Closing UserAccount delete channel for user %v", authResult.UserId)
-				err = r.Bus.Unsubscribe(subscribeHandlerAaaDelete)
-				if err != nil {
-					r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserAccount delete channel for user %v", authResult.UserId)
-				}
+		r.Lgr.WithTracing(ctx).Infof("Closing UserAccount create channel for user %v", authResult.UserId)
+		err = r.Bus.Unsubscribe(subscribeHandlerAaaCreate)
+		if err != nil {
+			r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserAccount create channel for user %v", authResult.UserId)
+		}
 
-				close(cam)
-				return
-			}
+		r.Lgr.WithTracing(ctx).Infof("Closing UserAccount delete channel for user %v", authResult.UserId)
+		err = r.Bus.Unsubscribe(subscribeHandlerAaaDelete)
+		if err != nil {
+			r.Lgr.WithTracing(ctx).Errorf("Error during unsubscribing from bus in UserAccount delete channel for user %v", authResult.UserId)
 		}
+
+		queue.Close()
 	}()
 
-	return cam, nil
+	return queue.Out(), nil
 }
 
 // Query returns QueryResolver implementation.
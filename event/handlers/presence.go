@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"nkonev.name/event/auth"
+	"nkonev.name/event/dto"
+	"nkonev.name/event/logger"
+	"nkonev.name/event/presence"
+	"nkonev.name/event/utils"
+)
+
+type PresenceHandler struct {
+	store *presence.Store
+	lgr   *logger.Logger
+}
+
+func NewPresenceHandler(store *presence.Store, lgr *logger.Logger) *PresenceHandler {
+	return &PresenceHandler{
+		store: store,
+		lgr:   lgr,
+	}
+}
+
+func (h *PresenceHandler) GetPresence(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	presences, err := h.store.Get(c.Request().Context(), []int64{userPrincipalDto.UserId})
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting presence %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, presences[0])
+}
+
+func (h *PresenceHandler) PutPresence(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	var bindTo = new(dto.UserPresenceSetDto)
+	if err := c.Bind(bindTo); err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Warnf("Error during binding to dto %v", err)
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": "wrong body"})
+	}
+	if err := bindTo.Validate(time.Now()); err != nil {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": err.Error()})
+	}
+
+	if err := h.store.Set(c.Request().Context(), userPrincipalDto.UserId, bindTo); err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during setting presence %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return h.GetPresence(c)
+}
+
+func (h *PresenceHandler) DeletePresence(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	if err := h.store.Clear(c.Request().Context(), userPrincipalDto.UserId); err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during clearing presence %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// the frontend calls it on the user's input, throttled
+func (h *PresenceHandler) PutActivity(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	if err := h.store.Touch(c.Request().Context(), userPrincipalDto.UserId); err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during touching presence %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// for chat's participant lists and notification's do not disturb
+func (h *PresenceHandler) GetPresencesInternal(c echo.Context) error {
+	userIds, err := GetQueryParamsAsInt64Slice(c, "userId")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": "wrong user id"})
+	}
+
+	presences, err := h.store.Get(c.Request().Context(), userIds)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting presences %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, presences)
+}
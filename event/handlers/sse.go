@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+	"nkonev.name/event/graph"
+	"nkonev.name/event/graph/model"
+	"nkonev.name/event/logger"
+	"nkonev.name/event/utils"
+)
+
+const lastEventIdHeader = "Last-Event-ID"
+const lastEventIdQueryParam = "lastEventId" // for the clients which can't set the header on the first connection
+
+const userStatusEventType = "user_status"
+
+// the graphql subscription sends the bare array, here it is wrapped in order to have the type as the other events do
+type userStatusSseEvent struct {
+	EventType string                   `json:"eventType"`
+	Statuses  []*model.UserStatusEvent `json:"statuses"`
+}
+
+// exposes the same subscriptions as graphql, encoded as plain json in the text/event-stream,
+// so the browser's EventSource can be used without any graphql client.
+// all the events are the unnamed ones, so EventSource.onmessage receives them, the type is in the eventType field of the json
+type SseHandler struct {
+	resolver *graph.Resolver
+	lgr      *logger.Logger
+}
+
+func NewSseHandler(resolver *graph.Resolver, lgr *logger.Logger) *SseHandler {
+	return &SseHandler{
+		resolver: resolver,
+		lgr:      lgr,
+	}
+}
+
+func (h *SseHandler) ChatEvents(c echo.Context) error {
+	chatId, err := GetPathParamAsInt64(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": "wrong chat id"})
+	}
+	since, err := getLastEventId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": "wrong last event id"})
+	}
+
+	events, err := h.resolver.Subscription().ChatEvents(c.Request().Context(), chatId, since)
+	if err != nil {
+		return h.subscriptionError(c, err)
+	}
+	return streamSse(c, h.lgr, events, func(e *model.ChatEvent) (*int64, any) {
+		return e.Seq, e
+	})
+}
+
+func (h *SseHandler) GlobalEvents(c echo.Context) error {
+	since, err := getLastEventId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": "wrong last event id"})
+	}
+
+	events, err := h.resolver.Subscription().GlobalEvents(c.Request().Context(), since)
+	if err != nil {
+		return h.subscriptionError(c, err)
+	}
+	return streamSse(c, h.lgr, events, func(e *model.GlobalEvent) (*int64, any) {
+		return e.Seq, e
+	})
+}
+
+func (h *SseHandler) UserStatusEvents(c echo.Context) error {
+	userIds, err := GetQueryParamsAsInt64Slice(c, "userId")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": "wrong user id"})
+	}
+
+	events, err := h.resolver.Subscription().UserStatusEvents(c.Request().Context(), userIds)
+	if err != nil {
+		return h.subscriptionError(c, err)
+	}
+	return streamSse(c, h.lgr, events, func(e []*model.UserStatusEvent) (*int64, any) {
+		return nil, &userStatusSseEvent{EventType: userStatusEventType, Statuses: e}
+	})
+}
+
+func (h *SseHandler) UserAccountEvents(c echo.Context) error {
+	userIds, err := GetQueryParamsAsInt64Slice(c, "userId")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": "wrong user id"})
+	}
+
+	events, err := h.resolver.Subscription().UserAccountEvents(c.Request().Context(), userIds)
+	if err != nil {
+		return h.subscriptionError(c, err)
+	}
+	return streamSse(c, h.lgr, events, func(e *model.UserAccountEvent) (*int64, any) {
+		return nil, e
+	})
+}
+
+func (h *SseHandler) subscriptionError(c echo.Context, err error) error {
+	if errors.Is(err, graph.ErrUnauthorized) {
+		return c.JSON(http.StatusForbidden, &utils.H{"message": err.Error()})
+	}
+	h.lgr.WithTracing(c.Request().Context()).Errorf("Error during subscribing via sse: %v", err)
+	return c.NoContent(http.StatusInternalServerError)
+}
+
+// the browser sends the header on the reconnect, it corresponds to the seq of the last received event
+func getLastEventId(c echo.Context) (*int64, error) {
+	lastEventId := strings.TrimSpace(c.Request().Header.Get(lastEventIdHeader))
+	if lastEventId == "" {
+		lastEventId = c.QueryParam(lastEventIdQueryParam)
+	}
+	if lastEventId == "" {
+		return nil, nil
+	}
+	since, err := utils.ParseInt64(lastEventId)
+	if err != nil {
+		return nil, err
+	}
+	return &since, nil
+}
+
+// writes the events until the client disconnects, the resolver closes the channel on the request context cancellation.
+// describe returns the seq, which becomes the id for Last-Event-ID, and what is sent as the data
+func streamSse[T any](c echo.Context, lgr *logger.Logger, events <-chan T, describe func(T) (*int64, any)) error {
+	ctx := c.Request().Context()
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disables the buffering in nginx
+	w.WriteHeader(http.StatusOK)
+
+	retry := viper.GetDuration("sse.retry")
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", retry.Milliseconds()); err != nil {
+		return nil
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(viper.GetDuration("sse.heartbeatInterval"))
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return nil
+			}
+			w.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			id, payload := describe(event)
+			data, err := json.Marshal(payload)
+			if err != nil {
+				lgr.WithTracing(ctx).Errorf("Error during marshalling sse event: %v", err)
+				continue
+			}
+			var sb strings.Builder
+			if id != nil {
+				sb.WriteString(fmt.Sprintf("id: %d\n", *id))
+			}
+			sb.WriteString(fmt.Sprintf("data: %s\n\n", data))
+			if _, err := fmt.Fprint(w, sb.String()); err != nil {
+				return nil
+			}
+			w.Flush()
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/montag451/go-eventbus"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"nkonev.name/event/auth"
+	"nkonev.name/event/graph"
+	"nkonev.name/event/logger"
+	"nkonev.name/event/presence"
+	"nkonev.name/event/replay"
+	"nkonev.name/event/subscription"
+	"nkonev.name/event/utils"
+)
+
+const testUserId = int64(10)
+
+// serves the global events of the test user, the events with the seqs 1..len(replayed) are in the replay buffer
+func startSseServer(t *testing.T, replayed ...string) *httptest.Server {
+	viper.Set("sse.retry", 3*time.Second)
+	viper.Set("sse.heartbeatInterval", 50*time.Millisecond)
+	viper.Set("replay.enabled", true)
+	viper.Set("replay.maxSize", 100)
+	viper.Set("replay.ttl", time.Hour)
+	viper.Set("replay.deliveryTtl", time.Minute)
+	viper.Set("subscription."+subscription.GlobalEvents+".queueSize", 16)
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		redisClient.Close()
+	})
+	lgr := logger.NewLogger()
+	bus := eventbus.New()
+	buffer := replay.NewBuffer(redisClient, lgr)
+	for i, body := range replayed {
+		_, err := buffer.Append(context.Background(), replay.GlobalStream(testUserId), fmt.Sprintf("d%v", i), &replay.Envelope{Body: json.RawMessage(body)})
+		require.NoError(t, err)
+	}
+
+	resolver := &graph.Resolver{
+		Bus:          bus,
+		Tr:           noop.NewTracerProvider().Tracer("test"),
+		Lgr:          lgr,
+		ReplayBuffer: buffer,
+		Presence:     presence.NewStore(redisClient, bus, nil, lgr),
+		Router:       subscription.NewRouter(lgr),
+	}
+	h := NewSseHandler(resolver, lgr)
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := context.WithValue(c.Request().Context(), utils.USER_PRINCIPAL_DTO, &auth.AuthResult{UserId: testUserId})
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	})
+	e.GET("/global", h.GlobalEvents)
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// reads the stream till the heartbeat, returns the lines before it
+func readTillPing(t *testing.T, server *httptest.Server, lastEventId string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/global", nil)
+	require.NoError(t, err)
+	if lastEventId != "" {
+		req.Header.Set(lastEventIdHeader, lastEventId)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get(echo.HeaderContentType))
+
+	lines := []string{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == ": ping" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+	require.Fail(t, "the stream has ended without the heartbeat", scanner.Err())
+	return nil
+}
+
+func TestSseHeartbeat(t *testing.T) {
+	server := startSseServer(t)
+
+	lines := readTillPing(t, server, "")
+	assert.Equal(t, []string{"retry: 3000", ""}, lines)
+}
+
+func TestSseResumesFromLastEventId(t *testing.T) {
+	server := startSseServer(t,
+		`{"eventType":"chat_deleted","userId":10,"chatDeletedNotification":{"id":1}}`,
+		`{"eventType":"chat_deleted","userId":10,"chatDeletedNotification":{"id":2}}`,
+		`{"eventType":"chat_deleted","userId":10,"chatDeletedNotification":{"id":3}}`,
+	)
+
+	lines := readTillPing(t, server, "1")
+
+	ids := []string{}
+	for i, line := range lines {
+		// the named events aren't delivered to EventSource.onmessage
+		assert.False(t, strings.HasPrefix(line, "event:"), line)
+		if id, ok := strings.CutPrefix(line, "id: "); ok {
+			ids = append(ids, id)
+			require.Less(t, i+1, len(lines))
+			data, ok := strings.CutPrefix(lines[i+1], "data: ")
+			require.True(t, ok, "the data should follow the id")
+			var event struct {
+				EventType string `json:"eventType"`
+				Seq       int64  `json:"seq"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(data), &event))
+			assert.Equal(t, "chat_deleted", event.EventType)
+			assert.Equal(t, id, fmt.Sprint(event.Seq))
+		}
+	}
+	assert.Equal(t, []string{"2", "3"}, ids)
+}
+
+func TestSseRejectsWrongLastEventId(t *testing.T) {
+	server := startSseServer(t)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/global?"+lastEventIdQueryParam+"=abc", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
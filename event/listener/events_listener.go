@@ -11,6 +11,7 @@ import (
 	"nkonev.name/event/dto"
 	"nkonev.name/event/logger"
 	"nkonev.name/event/rabbitmq"
+	"nkonev.name/event/replay"
 	"nkonev.name/event/type_registry"
 )
 
@@ -18,7 +19,7 @@ const correlationIdName = "correlationId"
 
 type EventsListener func(*amqp.Delivery) error
 
-func CreateEventsListener(lgr *logger.Logger, bus *eventbus.Bus, typeRegistry *type_registry.TypeRegistryInstance) EventsListener {
+func CreateEventsListener(lgr *logger.Logger, bus *eventbus.Bus, typeRegistry *type_registry.TypeRegistryInstance, replayBuffer *replay.Buffer) EventsListener {
 	tr := otel.Tracer("amqp/listener")
 
 	return func(msg *amqp.Delivery) error {
@@ -57,6 +58,9 @@ func CreateEventsListener(lgr *logger.Logger, bus *eventbus.Bus, typeRegistry *t
 			}
 			bindTo.TraceString = traceString
 			bindTo.CorrelationId = correlationId
+			if replay.IsReplayable(bindTo.EventType) {
+				bindTo.Seq = appendToReplay(ctx, lgr, replayBuffer, replay.ChatStream(bindTo.UserId, bindTo.ChatId), msg, correlationId)
+			}
 
 			err = bus.PublishAsync(bindTo)
 			if err != nil {
@@ -72,6 +76,9 @@ func CreateEventsListener(lgr *logger.Logger, bus *eventbus.Bus, typeRegistry *t
 			}
 			bindTo.TraceString = traceString
 			bindTo.CorrelationId = correlationId
+			if replay.IsReplayable(bindTo.EventType) {
+				bindTo.Seq = appendToReplay(ctx, lgr, replayBuffer, replay.GlobalStream(bindTo.UserId), msg, correlationId)
+			}
 
 			err = bus.PublishAsync(bindTo)
 			if err != nil {
@@ -171,3 +178,19 @@ func CreateEventsListener(lgr *logger.Logger, bus *eventbus.Bus, typeRegistry *t
 		return nil
 	}
 }
+
+// the event is delivered without the sequence in case of the error, so the live subscriptions aren't affected by redis
+func appendToReplay(ctx context.Context, lgr *logger.Logger, replayBuffer *replay.Buffer, stream string, msg *amqp.Delivery, correlationId *string) *int64 {
+	if !replayBuffer.Enabled() {
+		return nil
+	}
+	seq, err := replayBuffer.Append(ctx, stream, replay.DeliveryId(msg), &replay.Envelope{
+		CorrelationId: correlationId,
+		Body:          msg.Body,
+	})
+	if err != nil {
+		lgr.WithTracing(ctx).Errorf("Error during appending to replay stream %v: %v", stream, err)
+		return nil
+	}
+	return &seq
+}
@@ -6,13 +6,16 @@ import (
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/montag451/go-eventbus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/viper"
+	"github.com/vektah/gqlparser/v2/ast"
 	gqlgen_opentelemetry "github.com/zhevron/gqlgen-opentelemetry/v2"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 	jaegerPropagator "go.opentelemetry.io/contrib/propagators/jaeger"
@@ -34,7 +37,11 @@ import (
 	"nkonev.name/event/handlers"
 	"nkonev.name/event/listener"
 	"nkonev.name/event/logger"
+	"nkonev.name/event/presence"
+	"nkonev.name/event/producer"
 	"nkonev.name/event/rabbitmq"
+	"nkonev.name/event/replay"
+	"nkonev.name/event/subscription"
 	"nkonev.name/event/type_registry"
 )
 
@@ -43,6 +50,8 @@ const TRACE_RESOURCE = app.APP_NAME
 
 const GRAPHQL_PATH = "/api/event/graphql"
 const GRAPHQL_PLAYGROUND = "/event/playground"
+const SSE_PATH = "/api/event/sse"
+const PRESENCE_PATH = "/api/event/presence"
 
 func main() {
 	config.InitViper()
@@ -56,21 +65,32 @@ func main() {
 		}),
 		fx.Provide(
 			configureTracer,
+			configureResolver,
 			configureGraphQlServer,
 			configureGraphQlPlayground,
 			configureEcho,
 			configureEventBus,
 			handlers.ConfigureStaticMiddleware,
 			handlers.ConfigureAuthMiddleware,
+			handlers.NewSseHandler,
+			handlers.NewPresenceHandler,
 			listener.CreateEventsListener,
 			rabbitmq.CreateRabbitMqConnection,
 			type_registry.NewTypeRegistryInstance,
 			client.NewRestClient,
+			replay.RedisV9,
+			replay.NewBuffer,
+			presence.NewStore,
+			producer.NewRabbitPresencePublisher,
+			subscription.NewRouter,
 		),
 		fx.Invoke(
 			runEcho,
 			listener.CreateEventsChannel,
 			listener.CreateAaaChannel,
+			presence.RunPresenceListener,
+			presence.RunPresenceScheduler,
+			subscription.RunRouter,
 		),
 	)
 	appFx.Run()
@@ -117,6 +137,8 @@ func configureEcho(
 	tp *sdktrace.TracerProvider,
 	graphQlServer *handler.Server,
 	graphQlPlayground *GraphQlPlayground,
+	sseHandler *handlers.SseHandler,
+	presenceHandler *handlers.PresenceHandler,
 	lgr *logger.Logger,
 ) *echo.Echo {
 
@@ -183,6 +205,19 @@ func configureEcho(
 	e.Any(GRAPHQL_PATH, handlers.Convert(graphQlServer))
 	e.GET(GRAPHQL_PLAYGROUND, handlers.Convert(graphQlPlayground))
 
+	e.GET(SSE_PATH+"/chat/:id", sseHandler.ChatEvents)
+	e.GET(SSE_PATH+"/global", sseHandler.GlobalEvents)
+	e.GET(SSE_PATH+"/user-status", sseHandler.UserStatusEvents)
+	e.GET(SSE_PATH+"/user-account", sseHandler.UserAccountEvents)
+
+	e.GET(PRESENCE_PATH, presenceHandler.GetPresence)
+	e.PUT(PRESENCE_PATH, presenceHandler.PutPresence)
+	e.DELETE(PRESENCE_PATH, presenceHandler.DeletePresence)
+	e.PUT(PRESENCE_PATH+"/activity", presenceHandler.PutActivity)
+	e.GET("/internal/presence", presenceHandler.GetPresencesInternal)
+
+	e.GET("/internal/metrics", echo.WrapHandler(promhttp.Handler()))
+
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
 			// do some work on application stop (like closing connections and files)
@@ -194,11 +229,19 @@ func configureEcho(
 	return e
 }
 
-func configureGraphQlServer(lgr *logger.Logger, bus *eventbus.Bus, httpClient *client.RestClient, tp *sdktrace.TracerProvider) *handler.Server {
+// is shared between graphql and sse
+func configureResolver(lgr *logger.Logger, bus *eventbus.Bus, httpClient *client.RestClient, replayBuffer *replay.Buffer, presenceStore *presence.Store, router *subscription.Router) *graph.Resolver {
 	tr := otel.Tracer("graphql")
-	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{bus, httpClient, tr, lgr}}))
-	srv.AddTransport(transport.POST{})
+	return &graph.Resolver{bus, httpClient, tr, lgr, replayBuffer, presenceStore, router}
+}
 
+func configureGraphQlServer(resolver *graph.Resolver, tp *sdktrace.TracerProvider) *handler.Server {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
+
+	// the transports are checked in order, sse should be before POST because it is POST with Accept: text/event-stream
+	srv.AddTransport(transport.SSE{
+		KeepAlivePingInterval: viper.GetDuration("sse.heartbeatInterval"),
+	})
 	d := viper.GetDuration("graphql.websocket.keepAlivePingInterval")
 	srv.AddTransport(transport.Websocket{
 		KeepAlivePingInterval: d,
@@ -208,6 +251,15 @@ func configureGraphQlServer(lgr *logger.Logger, bus *eventbus.Bus, httpClient *c
 			},
 		},
 	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: lru.New[string](100),
+	})
 	srv.Use(extension.Introspection{})
 	srv.Use(gqlgen_opentelemetry.Tracer{
 		TracerProvider: tp,
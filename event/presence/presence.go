@@ -0,0 +1,313 @@
+package presence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/montag451/go-eventbus"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/fx"
+	"nkonev.name/event/dto"
+	"nkonev.name/event/logger"
+	"nkonev.name/event/producer"
+	"nkonev.name/event/rabbitmq"
+)
+
+const changedChannel = "event:presence:changed"
+const activityKey = "event:presence:activity"
+const expiryKey = "event:presence:expiry"
+
+const dueBatchSize = 1000
+
+// the status which is set by the user is stored till it's expired or cleared,
+// the idle is derived from the last activity of the user.
+// it is stored in redis in order to be shared across the replicas, the changes are propagated to them through the pub/sub
+// and to the other services through rabbitmq
+type Store struct {
+	redis     *redis.Client
+	bus       *eventbus.Bus
+	publisher producer.PresencePublisher
+	lgr       *logger.Logger
+}
+
+type changedMessage struct {
+	TraceString   string             `json:"traceString"`
+	UserPresences []dto.UserPresence `json:"userPresences"`
+}
+
+func NewStore(redisClient *redis.Client, bus *eventbus.Bus, publisher producer.PresencePublisher, lgr *logger.Logger) *Store {
+	return &Store{
+		redis:     redisClient,
+		bus:       bus,
+		publisher: publisher,
+		lgr:       lgr,
+	}
+}
+
+func (s *Store) Get(ctx context.Context, userIds []int64) ([]dto.UserPresence, error) {
+	if len(userIds) == 0 {
+		return []dto.UserPresence{}, nil
+	}
+
+	pipe := s.redis.Pipeline()
+	statusCmds := make([]*redis.StringCmd, 0, len(userIds))
+	idleCmds := make([]*redis.IntCmd, 0, len(userIds))
+	for _, userId := range userIds {
+		statusCmds = append(statusCmds, pipe.Get(ctx, statusKey(userId)))
+		idleCmds = append(idleCmds, pipe.Exists(ctx, idleKey(userId)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	res := make([]dto.UserPresence, 0, len(userIds))
+	for i, userId := range userIds {
+		p := dto.UserPresence{
+			UserId: userId,
+			Idle:   idleCmds[i].Val() > 0,
+		}
+		data, err := statusCmds[i].Bytes()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+		if err == nil {
+			set := dto.UserPresenceSetDto{}
+			if err := json.Unmarshal(data, &set); err != nil {
+				return nil, err
+			}
+			// the expiration is checked periodically, so here it can be already expired
+			if set.ExpiresAt == nil || set.ExpiresAt.After(time.Now()) {
+				p.Status = set.Status
+				p.Emoji = set.Emoji
+				p.Text = set.Text
+				p.ExpiresAt = set.ExpiresAt
+			}
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+func (s *Store) Set(ctx context.Context, userId int64, set *dto.UserPresenceSetDto) error {
+	if set.IsEmpty() {
+		return s.Clear(ctx, userId)
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		return err
+	}
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if set.ExpiresAt != nil {
+			pipe.SetArgs(ctx, statusKey(userId), data, redis.SetArgs{ExpireAt: *set.ExpiresAt})
+			pipe.ZAdd(ctx, expiryKey, redis.Z{Score: float64(set.ExpiresAt.Unix()), Member: userId})
+		} else {
+			pipe.Set(ctx, statusKey(userId), data, 0)
+			pipe.ZRem(ctx, expiryKey, userId)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.publishChanged(ctx, []int64{userId})
+}
+
+func (s *Store) Clear(ctx context.Context, userId int64) error {
+	var deleted *redis.IntCmd
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		deleted = pipe.Del(ctx, statusKey(userId))
+		pipe.ZRem(ctx, expiryKey, userId)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if deleted.Val() == 0 {
+		return nil
+	}
+	return s.publishChanged(ctx, []int64{userId})
+}
+
+// registers the activity of the user, the idle user becomes active
+func (s *Store) Touch(ctx context.Context, userId int64) error {
+	var wasIdle *redis.IntCmd
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, activityKey, redis.Z{Score: float64(time.Now().Unix()), Member: userId})
+		wasIdle = pipe.Del(ctx, idleKey(userId))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if wasIdle.Val() == 0 {
+		return nil
+	}
+	return s.publishChanged(ctx, []int64{userId})
+}
+
+// makes idle the users without the recent activity and clears the expired statuses.
+// every replica runs it, removing from the sorted set decides which replica takes the user
+func (s *Store) ProcessDue(ctx context.Context) {
+	now := time.Now()
+	changed := []int64{}
+
+	inactive, err := s.takeDue(ctx, activityKey, now.Add(-viper.GetDuration("presence.idleTimeout")))
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get the inactive users: %v", err)
+	}
+	for _, userId := range inactive {
+		if err := s.redis.Set(ctx, idleKey(userId), 1, viper.GetDuration("presence.idleTtl")).Err(); err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to make user %v idle: %v", userId, err)
+			continue
+		}
+		changed = append(changed, userId)
+	}
+
+	expired, err := s.takeDue(ctx, expiryKey, now)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get the expired statuses: %v", err)
+	}
+	for _, userId := range expired {
+		// the key is expired by redis itself, so it's deleted just in case the redis hasn't done it yet
+		if err := s.redis.Del(ctx, statusKey(userId)).Err(); err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to clear the expired status of user %v: %v", userId, err)
+			continue
+		}
+		changed = append(changed, userId)
+	}
+
+	if len(changed) > 0 {
+		if err := s.publishChanged(ctx, changed); err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to publish the changed presences: %v", err)
+		}
+	}
+}
+
+// returns the users which are removed from the sorted set by this replica
+func (s *Store) takeDue(ctx context.Context, key string, until time.Time) ([]int64, error) {
+	members, err := s.redis.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(until.Unix(), 10),
+		Count: dueBatchSize,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	taken := []int64{}
+	for _, member := range members {
+		removed, err := s.redis.ZRem(ctx, key, member).Result()
+		if err != nil {
+			return taken, err
+		}
+		if removed == 0 {
+			// the other replica has taken it
+			continue
+		}
+		userId, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Wrong member %v in %v", member, key)
+			continue
+		}
+		taken = append(taken, userId)
+	}
+	return taken, nil
+}
+
+func (s *Store) publishChanged(ctx context.Context, userIds []int64) error {
+	presences, err := s.Get(ctx, userIds)
+	if err != nil {
+		return err
+	}
+	// the status is already stored, so the user doesn't get the error because of the others
+	if err := s.publisher.PublishPresences(ctx, presences); err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to publish the presences to rabbitmq: %v", err)
+	}
+	data, err := json.Marshal(changedMessage{
+		TraceString:   rabbitmq.SerializeValues(ctx, s.lgr),
+		UserPresences: presences,
+	})
+	if err != nil {
+		return err
+	}
+	return s.redis.Publish(ctx, changedChannel, data).Err()
+}
+
+func statusKey(userId int64) string {
+	return "event:presence:status:" + strconv.FormatInt(userId, 10)
+}
+
+func idleKey(userId int64) string {
+	return "event:presence:idle:" + strconv.FormatInt(userId, 10)
+}
+
+// delivers the changes from all the replicas to the local subscriptions
+func RunPresenceListener(lgr *logger.Logger, store *Store, lc fx.Lifecycle) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pubsub := store.redis.Subscribe(ctx, changedChannel)
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				for msg := range pubsub.Channel() {
+					changed := changedMessage{}
+					if err := json.Unmarshal([]byte(msg.Payload), &changed); err != nil {
+						lgr.Errorf("Error during deserialize presence %v", err)
+						continue
+					}
+					err := store.bus.PublishAsync(dto.ArrayUserPresence{
+						UserPresences: changed.UserPresences,
+						TraceString:   changed.TraceString,
+					})
+					if err != nil {
+						lgr.Errorf("Error during sending to bus : %v", err)
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			lgr.Infof("Stopping presence listener")
+			cancel()
+			return pubsub.Close()
+		},
+	})
+}
+
+func RunPresenceScheduler(lgr *logger.Logger, store *Store, lc fx.Lifecycle) {
+	tr := otel.Tracer("scheduler")
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := time.NewTicker(viper.GetDuration("presence.checkInterval"))
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						func() {
+							spanCtx, span := tr.Start(ctx, "presence.processDue")
+							defer span.End()
+							store.ProcessDue(spanCtx)
+						}()
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			lgr.Infof("Stopping presence scheduler")
+			ticker.Stop()
+			cancel()
+			return nil
+		},
+	})
+}
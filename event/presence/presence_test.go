@@ -0,0 +1,84 @@
+package presence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nkonev.name/event/dto"
+	"nkonev.name/event/logger"
+)
+
+type testPublisher struct {
+	published [][]dto.UserPresence
+}
+
+func (p *testPublisher) PublishPresences(ctx context.Context, presences []dto.UserPresence) error {
+	p.published = append(p.published, presences)
+	return nil
+}
+
+func newTestStore(t *testing.T) (*Store, *testPublisher) {
+	viper.Set("presence.idleTimeout", time.Minute)
+	viper.Set("presence.idleTtl", time.Hour)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+	})
+	publisher := &testPublisher{}
+	return NewStore(client, nil, publisher, logger.NewLogger()), publisher
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSetPublishesToOtherServices(t *testing.T) {
+	s, publisher := newTestStore(t)
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, s.Set(ctx, 1, &dto.UserPresenceSetDto{Status: ptr(dto.PresenceDoNotDisturb), Text: ptr("focusing"), ExpiresAt: &expiresAt}))
+
+	require.Len(t, publisher.published, 1)
+	require.Len(t, publisher.published[0], 1)
+	p := publisher.published[0][0]
+	assert.Equal(t, int64(1), p.UserId)
+	assert.True(t, p.IsDoNotDisturb())
+	assert.Equal(t, "focusing", *p.Text)
+	assert.True(t, expiresAt.Equal(*p.ExpiresAt))
+
+	require.NoError(t, s.Clear(ctx, 1))
+	require.Len(t, publisher.published, 2)
+	assert.Nil(t, publisher.published[1][0].Status)
+
+	// nothing has changed
+	require.NoError(t, s.Clear(ctx, 1))
+	assert.Len(t, publisher.published, 2)
+}
+
+func TestProcessDuePublishesIdleAndExpired(t *testing.T) {
+	s, publisher := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Touch(ctx, 2))
+	assert.Empty(t, publisher.published, "the active user stays active")
+
+	// the activity is in the past
+	require.NoError(t, s.redis.ZAdd(ctx, activityKey, redis.Z{Score: float64(time.Now().Add(-2 * time.Minute).Unix()), Member: 2}).Err())
+	s.ProcessDue(ctx)
+
+	require.Len(t, publisher.published, 1)
+	assert.Equal(t, []dto.UserPresence{{UserId: 2, Idle: true}}, publisher.published[0])
+
+	require.NoError(t, s.Touch(ctx, 2))
+	require.Len(t, publisher.published, 2)
+	assert.Equal(t, []dto.UserPresence{{UserId: 2, Idle: false}}, publisher.published[1])
+}
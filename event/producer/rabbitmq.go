@@ -0,0 +1,57 @@
+package producer
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/beliyav/go-amqp-reconnect/rabbitmq"
+	"github.com/streadway/amqp"
+	"nkonev.name/event/dto"
+	"nkonev.name/event/logger"
+	myRabbitmq "nkonev.name/event/rabbitmq"
+	"nkonev.name/event/utils"
+)
+
+const PresenceEventsExchange = "presence-events-exchange"
+
+type PresencePublisher interface {
+	PublishPresences(ctx context.Context, presences []dto.UserPresence) error
+}
+
+// chat and notification keep their own projection of the presences, so they don't ask event on their hot paths
+type RabbitPresencePublisher struct {
+	channel *rabbitmq.Channel
+	lgr     *logger.Logger
+}
+
+func NewRabbitPresencePublisher(lgr *logger.Logger, connection *rabbitmq.Connection) PresencePublisher {
+	return &RabbitPresencePublisher{
+		channel: myRabbitmq.CreateRabbitMqChannelWithCallback(lgr, connection, func(channel *rabbitmq.Channel) error {
+			return channel.ExchangeDeclare(PresenceEventsExchange, "fanout", true, false, false, false, nil)
+		}),
+		lgr: lgr,
+	}
+}
+
+func (rp *RabbitPresencePublisher) PublishPresences(ctx context.Context, presences []dto.UserPresence) error {
+	event := dto.UserPresencesChanged{
+		UserPresences: presences,
+	}
+
+	bytea, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := amqp.Publishing{
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now().UTC(),
+		ContentType:  "application/json",
+		Body:         bytea,
+		Type:         utils.GetType(event),
+		Headers:      myRabbitmq.InjectAMQPHeaders(ctx),
+	}
+
+	return rp.channel.Publish(PresenceEventsExchange, "", false, false, msg)
+}
@@ -0,0 +1,191 @@
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/streadway/amqp"
+	"go.uber.org/fx"
+	"nkonev.name/event/logger"
+)
+
+// the events which are irrelevant after a reconnect, they aren't sequenced
+var ephemeralEventTypes = map[string]bool{
+	"user_typing": true,
+}
+
+func IsReplayable(eventType string) bool {
+	return !ephemeralEventTypes[eventType]
+}
+
+func ChatStream(userId, chatId int64) string {
+	return fmt.Sprintf("chat:%v:%v", userId, chatId)
+}
+
+func GlobalStream(userId int64) string {
+	return fmt.Sprintf("global:%v", userId)
+}
+
+type Envelope struct {
+	CorrelationId *string         `json:"correlationId"`
+	Body          json.RawMessage `json:"body"`
+}
+
+type Entry struct {
+	Seq      int64
+	Envelope Envelope
+}
+
+// the replay buffer is stored in redis in order to be shared across the replicas.
+// every replica receives every event from its own fanout queue, so the delivery id makes the appending idempotent
+// and all the replicas get the same sequence for the same event
+type Buffer struct {
+	redis *redis.Client
+	lgr   *logger.Logger
+}
+
+func RedisV9(lgr *logger.Logger, lc fx.Lifecycle) *redis.Client {
+	client := redis.NewClient(&redis.Options{
+		Addr:       viper.GetString("redis.address"),
+		Password:   viper.GetString("redis.password"),
+		DB:         viper.GetInt("redis.db"),
+		MaxRetries: viper.GetInt("redis.maxRetries"),
+	})
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			lgr.Infof("Stopping redis replay connection")
+			return client.Close()
+		},
+	})
+	return client
+}
+
+func NewBuffer(redisClient *redis.Client, lgr *logger.Logger) *Buffer {
+	return &Buffer{
+		redis: redisClient,
+		lgr:   lgr,
+	}
+}
+
+func (b *Buffer) Enabled() bool {
+	return viper.GetBool("replay.enabled")
+}
+
+// KEYS: sequence, buffer, delivery
+// ARGV: envelope, max size, ttl seconds, delivery ttl seconds
+var appendScript = redis.NewScript(`
+local existing = redis.call('GET', KEYS[3])
+if existing then
+	return tonumber(existing)
+end
+local seq = redis.call('INCR', KEYS[1])
+redis.call('ZADD', KEYS[2], seq, seq .. ':' .. ARGV[1])
+redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -tonumber(ARGV[2]) - 1)
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+redis.call('SET', KEYS[3], seq, 'EX', ARGV[4])
+return seq
+`)
+
+// returns the sequence of the event in the stream
+func (b *Buffer) Append(ctx context.Context, stream string, deliveryId string, envelope *Envelope) (int64, error) {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return 0, err
+	}
+	return appendScript.Run(
+		ctx,
+		b.redis,
+		[]string{sequenceKey(stream), bufferKey(stream), deliveryKey(stream, deliveryId)},
+		string(data),
+		viper.GetInt("replay.maxSize"),
+		int(viper.GetDuration("replay.ttl").Seconds()),
+		int(viper.GetDuration("replay.deliveryTtl").Seconds()),
+	).Int64()
+}
+
+// returns the events after since, gap is true in case some of them are already evicted and the client should make a full refresh
+func (b *Buffer) Since(ctx context.Context, stream string, since int64) ([]Entry, bool, error) {
+	current, err := b.redis.Get(ctx, sequenceKey(stream)).Int64()
+	if err == redis.Nil {
+		current = 0
+	} else if err != nil {
+		return nil, false, err
+	}
+	if since > current {
+		// the sequence was expired or the redis was cleaned
+		return nil, true, nil
+	}
+	if since == current {
+		return []Entry{}, false, nil
+	}
+
+	members, err := b.redis.ZRangeByScore(ctx, bufferKey(stream), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(since, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	entries := make([]Entry, 0, len(members))
+	for _, member := range members {
+		seqString, data, found := strings.Cut(member, ":")
+		if !found {
+			return nil, false, fmt.Errorf("wrong replay entry in stream %v", stream)
+		}
+		seq, err := strconv.ParseInt(seqString, 10, 64)
+		if err != nil {
+			return nil, false, err
+		}
+		entry := Entry{Seq: seq}
+		if err := json.Unmarshal([]byte(data), &entry.Envelope); err != nil {
+			return nil, false, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 || entries[0].Seq != since+1 {
+		return nil, true, nil
+	}
+	return entries, false, nil
+}
+
+func sequenceKey(stream string) string {
+	return "event:replay:seq:" + stream
+}
+
+func bufferKey(stream string) string {
+	return "event:replay:buffer:" + stream
+}
+
+func deliveryKey(stream, deliveryId string) string {
+	return "event:replay:delivery:" + stream + ":" + deliveryId
+}
+
+// is the same for the same message in the fanout queues of the different replicas
+func DeliveryId(msg *amqp.Delivery) string {
+	h := sha256.New()
+	h.Write([]byte(msg.Type))
+	h.Write([]byte(msg.Timestamp.UTC().Format(time.RFC3339Nano)))
+	// the tracing headers make the same payloads published twice distinguishable
+	headerNames := make([]string, 0, len(msg.Headers))
+	for name := range msg.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		h.Write([]byte(fmt.Sprintf("%v=%v;", name, msg.Headers[name])))
+	}
+	h.Write(msg.Body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,134 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nkonev.name/event/logger"
+)
+
+const testStream = "chat:1:10"
+
+func newTestBuffer(t *testing.T, maxSize int) (*Buffer, *miniredis.Miniredis) {
+	viper.Set("replay.enabled", true)
+	viper.Set("replay.maxSize", maxSize)
+	viper.Set("replay.ttl", time.Hour)
+	viper.Set("replay.deliveryTtl", time.Minute)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+	})
+	return NewBuffer(client, logger.NewLogger()), mr
+}
+
+func appendEvent(t *testing.T, b *Buffer, deliveryId string, body string) int64 {
+	seq, err := b.Append(context.Background(), testStream, deliveryId, &Envelope{Body: json.RawMessage(body)})
+	require.NoError(t, err)
+	return seq
+}
+
+func TestAppendAssignsSequence(t *testing.T) {
+	b, _ := newTestBuffer(t, 10)
+
+	assert.Equal(t, int64(1), appendEvent(t, b, "d1", `{"n":1}`))
+	assert.Equal(t, int64(2), appendEvent(t, b, "d2", `{"n":2}`))
+	assert.Equal(t, int64(1), appendEvent(t, b, "d1", `{"n":1}`), "the same delivery from the other replica gets the same seq")
+
+	entries, gap, err := b.Since(context.Background(), testStream, 0)
+	require.NoError(t, err)
+	assert.False(t, gap)
+	require.Len(t, entries, 2)
+	assert.Equal(t, int64(1), entries[0].Seq)
+	assert.JSONEq(t, `{"n":1}`, string(entries[0].Envelope.Body))
+	assert.Equal(t, int64(2), entries[1].Seq)
+}
+
+func TestAppendTrimsToMaxSize(t *testing.T) {
+	b, mr := newTestBuffer(t, 3)
+
+	for _, d := range []string{"d1", "d2", "d3", "d4", "d5"} {
+		appendEvent(t, b, d, `{}`)
+	}
+
+	members, err := mr.ZMembers(bufferKey(testStream))
+	require.NoError(t, err)
+	assert.Len(t, members, 3)
+	assert.True(t, mr.TTL(bufferKey(testStream)) > 0)
+	assert.True(t, mr.TTL(sequenceKey(testStream)) > 0)
+
+	entries, gap, err := b.Since(context.Background(), testStream, 2)
+	require.NoError(t, err)
+	assert.False(t, gap)
+	require.Len(t, entries, 3)
+	assert.Equal(t, int64(3), entries[0].Seq)
+	assert.Equal(t, int64(5), entries[2].Seq)
+}
+
+func TestSinceGap(t *testing.T) {
+	b, mr := newTestBuffer(t, 3)
+	for _, d := range []string{"d1", "d2", "d3", "d4", "d5"} {
+		appendEvent(t, b, d, `{}`)
+	}
+
+	cases := []struct {
+		name    string
+		since   int64
+		gap     bool
+		entries int
+	}{
+		{"evicted", 1, true, 0},
+		{"first kept", 2, false, 3},
+		{"up to date", 5, false, 0},
+		{"ahead of the sequence", 7, true, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			entries, gap, err := b.Since(context.Background(), testStream, c.since)
+			require.NoError(t, err)
+			assert.Equal(t, c.gap, gap)
+			assert.Len(t, entries, c.entries)
+		})
+	}
+
+	// redis was cleaned, so the client can't know what it has missed
+	mr.FlushAll()
+	_, gap, err := b.Since(context.Background(), testStream, 3)
+	require.NoError(t, err)
+	assert.True(t, gap)
+}
+
+func TestEnvelopeWithSeparator(t *testing.T) {
+	b, _ := newTestBuffer(t, 10)
+	correlationId := "a:b"
+	_, err := b.Append(context.Background(), testStream, "d1", &Envelope{CorrelationId: &correlationId, Body: json.RawMessage(`{"text":"1:2:3"}`)})
+	require.NoError(t, err)
+
+	entries, gap, err := b.Since(context.Background(), testStream, 0)
+	require.NoError(t, err)
+	assert.False(t, gap)
+	require.Len(t, entries, 1)
+	assert.Equal(t, correlationId, *entries[0].Envelope.CorrelationId)
+	assert.JSONEq(t, `{"text":"1:2:3"}`, string(entries[0].Envelope.Body))
+}
+
+func TestDeliveryId(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	msg := amqp.Delivery{Type: "dto.ChatEvent", Timestamp: ts, Headers: amqp.Table{"uber-trace-id": "1", "b": "2"}, Body: []byte(`{}`)}
+	same := amqp.Delivery{Type: "dto.ChatEvent", Timestamp: ts, Headers: amqp.Table{"b": "2", "uber-trace-id": "1"}, Body: []byte(`{}`)}
+	other := amqp.Delivery{Type: "dto.ChatEvent", Timestamp: ts, Headers: amqp.Table{"uber-trace-id": "2", "b": "2"}, Body: []byte(`{}`)}
+
+	assert.Equal(t, DeliveryId(&msg), DeliveryId(&same))
+	assert.NotEqual(t, DeliveryId(&msg), DeliveryId(&other))
+	assert.True(t, IsReplayable("message_created"))
+	assert.False(t, IsReplayable("user_typing"))
+}
@@ -0,0 +1,69 @@
+package subscription
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const metricsNamespace = "event"
+const metricsSubsystem = "subscription"
+const typeLabel = "type"
+
+var (
+	activeGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "active",
+		Help:      "The number of the active subscriptions",
+	}, []string{typeLabel})
+	queuedGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "queued",
+		Help:      "The number of the events waiting for the slow clients",
+	}, []string{typeLabel})
+	deliveredCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "delivered_total",
+		Help:      "The number of the events delivered to the clients",
+	}, []string{typeLabel})
+	droppedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "dropped_total",
+		Help:      "The number of the events dropped because of the full queue",
+	}, []string{typeLabel})
+	coalescedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "coalesced_total",
+		Help:      "The number of the events replaced by the newer ones of the same type",
+	}, []string{typeLabel})
+	disconnectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "disconnected_total",
+		Help:      "The number of the subscriptions closed because the client hasn't kept up",
+	}, []string{typeLabel})
+)
+
+type queueMetrics struct {
+	active       prometheus.Gauge
+	queued       prometheus.Gauge
+	delivered    prometheus.Counter
+	dropped      prometheus.Counter
+	coalesced    prometheus.Counter
+	disconnected prometheus.Counter
+}
+
+func newQueueMetrics(kind string) *queueMetrics {
+	return &queueMetrics{
+		active:       activeGauge.WithLabelValues(kind),
+		queued:       queuedGauge.WithLabelValues(kind),
+		delivered:    deliveredCounter.WithLabelValues(kind),
+		dropped:      droppedCounter.WithLabelValues(kind),
+		coalesced:    coalescedCounter.WithLabelValues(kind),
+		disconnected: disconnectedCounter.WithLabelValues(kind),
+	}
+}
@@ -0,0 +1,63 @@
+package subscription
+
+import (
+	"sync"
+)
+
+// the subscriptions indexed by the key, so the event is evaluated only by the subscriptions of its key
+type Registry[K comparable, E any] struct {
+	mu          sync.RWMutex
+	subscribers map[K]map[*subscriber[E]]struct{}
+}
+
+type subscriber[E any] struct {
+	fn func(E)
+}
+
+func NewRegistry[K comparable, E any]() *Registry[K, E] {
+	return &Registry[K, E]{
+		subscribers: map[K]map[*subscriber[E]]struct{}{},
+	}
+}
+
+// returns the function which removes the subscription
+func (r *Registry[K, E]) Subscribe(key K, fn func(E)) func() {
+	s := &subscriber[E]{fn: fn}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byKey, ok := r.subscribers[key]
+	if !ok {
+		byKey = map[*subscriber[E]]struct{}{}
+		r.subscribers[key] = byKey
+	}
+	byKey[s] = struct{}{}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			byKey, ok := r.subscribers[key]
+			if !ok {
+				return
+			}
+			delete(byKey, s)
+			if len(byKey) == 0 {
+				delete(r.subscribers, key)
+			}
+		})
+	}
+}
+
+// the subscribers are called under the read lock, so they should not block
+func (r *Registry[K, E]) Dispatch(key K, event E) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for s := range r.subscribers[key] {
+		s.fn(event)
+	}
+}
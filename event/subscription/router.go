@@ -0,0 +1,103 @@
+package subscription
+
+import (
+	"context"
+	"time"
+
+	"github.com/montag451/go-eventbus"
+	"github.com/spf13/viper"
+	"go.uber.org/fx"
+	"nkonev.name/event/dto"
+	"nkonev.name/event/logger"
+)
+
+// the chat events are addressed to the participant, so they are routed by both the chat and the user
+type ChatKey struct {
+	ChatId int64
+	UserId int64
+}
+
+// routes the chat and the global events from the bus to the subscriptions of their chat or user,
+// instead of evaluating every event by every subscription of the node
+type Router struct {
+	Chats *Registry[ChatKey, dto.ChatEvent]
+	Users *Registry[int64, dto.GlobalUserEvent] // by the user id
+
+	lgr      *logger.Logger
+	handlers []*eventbus.Handler
+}
+
+func NewRouter(lgr *logger.Logger) *Router {
+	return &Router{
+		Chats: NewRegistry[ChatKey, dto.ChatEvent](),
+		Users: NewRegistry[int64, dto.GlobalUserEvent](),
+		lgr:   lgr,
+	}
+}
+
+// the only bus subscriptions of the node for these events,
+// their queue is shared by all the subscriptions so it's bigger than the default one
+func (r *Router) Start(bus *eventbus.Bus) error {
+	queueSize := eventbus.WithQueueSize(viper.GetInt("subscription.routerQueueSize"))
+
+	chatHandler, err := bus.Subscribe(dto.CHAT_EVENTS, func(event eventbus.Event, t time.Time) {
+		defer r.recoverPanic(dto.CHAT_EVENTS)
+
+		switch typedEvent := event.(type) {
+		case dto.ChatEvent:
+			r.Chats.Dispatch(ChatKey{ChatId: typedEvent.ChatId, UserId: typedEvent.UserId}, typedEvent)
+		default:
+			r.lgr.Debugf("Skipping %v as is no mapping here for this type", typedEvent)
+		}
+	}, queueSize)
+	if err != nil {
+		return err
+	}
+	r.handlers = append(r.handlers, chatHandler)
+
+	globalHandler, err := bus.Subscribe(dto.GLOBAL_USER_EVENTS, func(event eventbus.Event, t time.Time) {
+		defer r.recoverPanic(dto.GLOBAL_USER_EVENTS)
+
+		switch typedEvent := event.(type) {
+		case dto.GlobalUserEvent:
+			r.Users.Dispatch(typedEvent.UserId, typedEvent)
+		default:
+			r.lgr.Debugf("Skipping %v as is no mapping here for this type", typedEvent)
+		}
+	}, queueSize)
+	if err != nil {
+		return err
+	}
+	r.handlers = append(r.handlers, globalHandler)
+
+	return nil
+}
+
+func (r *Router) Stop(bus *eventbus.Bus) {
+	for _, handler := range r.handlers {
+		if err := bus.Unsubscribe(handler); err != nil {
+			r.lgr.Errorf("Error during unsubscribing the router from bus: %v", err)
+		}
+	}
+	r.handlers = nil
+}
+
+func (r *Router) recoverPanic(eventName string) {
+	if err := recover(); err != nil {
+		r.lgr.Errorf("In routing %v panic recovered: %v", eventName, err)
+	}
+}
+
+func RunRouter(lgr *logger.Logger, bus *eventbus.Bus, router *Router, lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			lgr.Infof("Starting subscription router")
+			return router.Start(bus)
+		},
+		OnStop: func(context.Context) error {
+			lgr.Infof("Stopping subscription router")
+			router.Stop(bus)
+			return nil
+		},
+	})
+}
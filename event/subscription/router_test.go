@@ -0,0 +1,143 @@
+package subscription
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/montag451/go-eventbus"
+	"nkonev.name/event/dto"
+	"nkonev.name/event/logger"
+)
+
+// go test -run '^$' -bench . ./subscription
+const benchmarkSubscriptions = 10000
+const benchmarkChats = 1000
+
+// the subscription i is the user i+1 in the chat i%benchmarkChats, so the chat has 10 subscriptions
+func benchmarkChatKey(i int) ChatKey {
+	return ChatKey{ChatId: int64(i % benchmarkChats), UserId: int64(i + 1)}
+}
+
+// every subscription has its own bus handler which evaluates every event
+func BenchmarkBroadcastChatEvents(b *testing.B) {
+	bus := eventbus.New()
+	defer bus.Close()
+
+	var delivered atomic.Int64
+	for i := 0; i < benchmarkSubscriptions; i++ {
+		key := benchmarkChatKey(i)
+		_, err := bus.Subscribe(dto.CHAT_EVENTS, func(event eventbus.Event, t time.Time) {
+			e := event.(dto.ChatEvent)
+			if e.ChatId == key.ChatId && e.UserId == key.UserId {
+				delivered.Add(1)
+			}
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := benchmarkChatKey(i % benchmarkSubscriptions)
+		if err := bus.PublishSync(dto.ChatEvent{ChatId: key.ChatId, UserId: key.UserId}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	if delivered.Load() != int64(b.N) {
+		b.Fatalf("Expected %v delivered, got %v", b.N, delivered.Load())
+	}
+}
+
+func BenchmarkRoutedChatEvents(b *testing.B) {
+	bus := eventbus.New()
+	defer bus.Close()
+	router := startBenchmarkRouter(b, bus)
+
+	var delivered atomic.Int64
+	for i := 0; i < benchmarkSubscriptions; i++ {
+		router.Chats.Subscribe(benchmarkChatKey(i), func(e dto.ChatEvent) {
+			delivered.Add(1)
+		})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := benchmarkChatKey(i % benchmarkSubscriptions)
+		if err := bus.PublishSync(dto.ChatEvent{ChatId: key.ChatId, UserId: key.UserId}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	if delivered.Load() != int64(b.N) {
+		b.Fatalf("Expected %v delivered, got %v", b.N, delivered.Load())
+	}
+}
+
+func BenchmarkBroadcastGlobalEvents(b *testing.B) {
+	bus := eventbus.New()
+	defer bus.Close()
+
+	var delivered atomic.Int64
+	for i := 0; i < benchmarkSubscriptions; i++ {
+		userId := int64(i + 1)
+		_, err := bus.Subscribe(dto.GLOBAL_USER_EVENTS, func(event eventbus.Event, t time.Time) {
+			if event.(dto.GlobalUserEvent).UserId == userId {
+				delivered.Add(1)
+			}
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := bus.PublishSync(dto.GlobalUserEvent{UserId: int64(i%benchmarkSubscriptions + 1)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	if delivered.Load() != int64(b.N) {
+		b.Fatalf("Expected %v delivered, got %v", b.N, delivered.Load())
+	}
+}
+
+func BenchmarkRoutedGlobalEvents(b *testing.B) {
+	bus := eventbus.New()
+	defer bus.Close()
+	router := startBenchmarkRouter(b, bus)
+
+	var delivered atomic.Int64
+	for i := 0; i < benchmarkSubscriptions; i++ {
+		router.Users.Subscribe(int64(i+1), func(e dto.GlobalUserEvent) {
+			delivered.Add(1)
+		})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := bus.PublishSync(dto.GlobalUserEvent{UserId: int64(i%benchmarkSubscriptions + 1)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	if delivered.Load() != int64(b.N) {
+		b.Fatalf("Expected %v delivered, got %v", b.N, delivered.Load())
+	}
+}
+
+func startBenchmarkRouter(b *testing.B, bus *eventbus.Bus) *Router {
+	router := NewRouter(logger.NewLogger())
+	if err := router.Start(bus); err != nil {
+		b.Fatal(err)
+	}
+	// is unsubscribed by closing the bus
+	return router
+}
@@ -0,0 +1,242 @@
+package subscription
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// what to do when the client doesn't keep up and its queue is full
+type OverflowPolicy string
+
+const (
+	DropOldest OverflowPolicy = "dropOldest"
+	Coalesce   OverflowPolicy = "coalesce" // replaces or merges the queued event of the same type, drops the oldest if there is none
+	Disconnect OverflowPolicy = "disconnect"
+)
+
+// the last event before the subscription is closed because of the overflow,
+// the client should resubscribe with the last received seq in order to get the missed events replayed
+const ResyncRequiredEventType = "resync_required"
+
+// the subscription types, they are the keys in the config and the labels of the metrics
+const (
+	ChatEvents        = "chatEvents"
+	GlobalEvents      = "globalEvents"
+	UserStatusEvents  = "userStatusEvents"
+	UserAccountEvents = "userAccountEvents"
+)
+
+type Config struct {
+	QueueSize      int
+	OverflowPolicy OverflowPolicy
+	ResyncTimeout  time.Duration
+}
+
+func GetConfig(kind string) Config {
+	policy := OverflowPolicy(viper.GetString("subscription." + kind + ".overflowPolicy"))
+	switch policy {
+	case DropOldest, Coalesce, Disconnect:
+	default:
+		policy = DropOldest
+	}
+	queueSize := viper.GetInt("subscription." + kind + ".queueSize")
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	return Config{
+		QueueSize:      queueSize,
+		OverflowPolicy: policy,
+		ResyncTimeout:  viper.GetDuration("subscription.resyncTimeout"),
+	}
+}
+
+// decouples the bus handlers from the client, so the slow client can't block the delivery to the others.
+// the bus handlers push into the bounded queue, the only goroutine of the subscription runs the delivery to the client and closes the channel
+type Queue[T any] struct {
+	config  Config
+	key     func(T) string // the event type, for coalescing
+	merge   func(queued, item T) T
+	resync  func() T
+	metrics *queueMetrics
+
+	out  chan T
+	wake chan struct{}
+
+	mu         sync.Mutex
+	items      []T
+	overflowed bool
+	closed     bool
+}
+
+func NewQueue[T any](kind string, key func(T) string, resync func() T) *Queue[T] {
+	return NewMergingQueue(kind, key, nil, resync)
+}
+
+// the coalesced event is merged into the queued one instead of the replacing, e.g. when the event is the batch of the statuses of the different users
+func NewMergingQueue[T any](kind string, key func(T) string, merge func(queued, item T) T, resync func() T) *Queue[T] {
+	config := GetConfig(kind)
+	metrics := newQueueMetrics(kind)
+	metrics.active.Inc()
+	return &Queue[T]{
+		config:  config,
+		key:     key,
+		merge:   merge,
+		resync:  resync,
+		metrics: metrics,
+		out:     make(chan T),
+		wake:    make(chan struct{}, 1),
+		items:   make([]T, 0, config.QueueSize),
+	}
+}
+
+func (q *Queue[T]) Out() <-chan T {
+	return q.out
+}
+
+// never blocks, is called from the bus handlers
+func (q *Queue[T]) Push(item T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed || q.overflowed {
+		return
+	}
+
+	if len(q.items) >= q.config.QueueSize {
+		switch q.config.OverflowPolicy {
+		case Disconnect:
+			q.metrics.queued.Sub(float64(len(q.items)))
+			q.metrics.disconnected.Inc()
+			q.items = nil
+			q.overflowed = true
+			q.notify()
+			return
+		case Coalesce:
+			if i := q.indexOfLocked(q.key(item)); i >= 0 {
+				if q.merge != nil {
+					item = q.merge(q.items[i], item)
+				}
+				q.items[i] = item
+				q.metrics.coalesced.Inc()
+				return
+			}
+			q.dropOldestLocked()
+		default:
+			q.dropOldestLocked()
+		}
+	}
+
+	q.items = append(q.items, item)
+	q.metrics.queued.Inc()
+	q.notify()
+}
+
+// sends bypassing the queue, for the replay and the initial state which are sent before Run
+func (q *Queue[T]) Send(ctx context.Context, item T) bool {
+	select {
+	case q.out <- item:
+		q.metrics.delivered.Inc()
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// delivers the queued events till the context is done or the client has overflowed the queue,
+// returns true in the latter case. the events for which pass returns false are skipped, pass can be nil
+func (q *Queue[T]) Run(ctx context.Context, pass func(T) bool) bool {
+	for {
+		item, ok, overflowed := q.pop()
+		if overflowed {
+			q.sendResync(ctx)
+			return true
+		}
+		if ok {
+			if pass != nil && !pass(item) {
+				continue
+			}
+			if !q.Send(ctx, item) {
+				return false
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-q.wake:
+		}
+	}
+}
+
+// should be called after the unsubscribing from the bus, by the goroutine which has called Run
+func (q *Queue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.closed = true
+	q.metrics.queued.Sub(float64(len(q.items)))
+	q.items = nil
+	q.metrics.active.Dec()
+	close(q.out)
+}
+
+func (q *Queue[T]) pop() (item T, ok bool, overflowed bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.overflowed {
+		return item, false, true
+	}
+	if len(q.items) == 0 {
+		return item, false, false
+	}
+	item = q.items[0]
+	var zero T
+	q.items[0] = zero
+	q.items = q.items[1:]
+	q.metrics.queued.Dec()
+	return item, true, false
+}
+
+// the client is already slow, so it isn't waited for too long
+func (q *Queue[T]) sendResync(ctx context.Context) {
+	timer := time.NewTimer(q.config.ResyncTimeout)
+	defer timer.Stop()
+
+	select {
+	case q.out <- q.resync():
+		q.metrics.delivered.Inc()
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+func (q *Queue[T]) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue[T]) dropOldestLocked() {
+	var zero T
+	q.items[0] = zero
+	q.items = q.items[1:]
+	q.metrics.queued.Dec()
+	q.metrics.dropped.Inc()
+}
+
+func (q *Queue[T]) indexOfLocked(key string) int {
+	for i := len(q.items) - 1; i >= 0; i-- {
+		if q.key(q.items[i]) == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,195 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvent struct {
+	Type string
+	N    int
+}
+
+func testEventKey(e testEvent) string {
+	return e.Type
+}
+
+func testResync() testEvent {
+	return testEvent{Type: ResyncRequiredEventType}
+}
+
+var testKinds atomic.Int64
+
+// every queue has its own kind, so the metrics of the tests and of the repeated runs don't interfere
+func newTestQueue(t *testing.T, kind string, queueSize int, policy OverflowPolicy, resyncTimeout time.Duration) *Queue[testEvent] {
+	return newTestMergingQueue(t, kind, queueSize, policy, resyncTimeout, nil)
+}
+
+func newTestMergingQueue(t *testing.T, kind string, queueSize int, policy OverflowPolicy, resyncTimeout time.Duration, merge func(queued, item testEvent) testEvent) *Queue[testEvent] {
+	kind = fmt.Sprintf("%v_%v", kind, testKinds.Add(1))
+	viper.Set("subscription."+kind+".queueSize", queueSize)
+	viper.Set("subscription."+kind+".overflowPolicy", string(policy))
+	viper.Set("subscription.resyncTimeout", resyncTimeout)
+	q := NewMergingQueue[testEvent](kind, testEventKey, merge, testResync)
+	t.Cleanup(q.Close)
+	return q
+}
+
+func pushAll(q *Queue[testEvent], events ...testEvent) {
+	for _, e := range events {
+		q.Push(e)
+	}
+}
+
+// runs the delivery till the expected count is received, returns what Run has returned
+func receive(t *testing.T, q *Queue[testEvent], count int) ([]testEvent, bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	result := make(chan bool, 1)
+	go func() {
+		result <- q.Run(ctx, nil)
+	}()
+
+	received := []testEvent{}
+	for len(received) < count {
+		select {
+		case e := <-q.Out():
+			received = append(received, e)
+		case <-time.After(time.Second):
+			t.Fatalf("expected %v events, received %v", count, received)
+		}
+	}
+	cancel()
+	return received, <-result
+}
+
+func TestQueueOverflow(t *testing.T) {
+	a1, b2, c3 := testEvent{"a", 1}, testEvent{"b", 2}, testEvent{"c", 3}
+
+	cases := []struct {
+		name                 string
+		policy               OverflowPolicy
+		overflowing          testEvent
+		expected             []testEvent
+		expectedOverflowed   bool
+		expectedDropped      float64
+		expectedCoalesced    float64
+		expectedDisconnected float64
+	}{
+		{"drop oldest", DropOldest, testEvent{"d", 4}, []testEvent{b2, c3, {"d", 4}}, false, 1, 0, 0},
+		{"coalesce replaces the same type in place", Coalesce, testEvent{"b", 4}, []testEvent{a1, {"b", 4}, c3}, false, 0, 1, 0},
+		{"coalesce drops the oldest without the same type", Coalesce, testEvent{"d", 4}, []testEvent{b2, c3, {"d", 4}}, false, 1, 0, 0},
+		{"disconnect sends only the resync", Disconnect, testEvent{"d", 4}, []testEvent{testResync()}, true, 0, 0, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q := newTestQueue(t, "test_overflow", 3, c.policy, time.Second)
+
+			pushAll(q, a1, b2, c3)
+			assert.Equal(t, float64(3), testutil.ToFloat64(q.metrics.queued), "at capacity")
+			assert.Equal(t, float64(0), testutil.ToFloat64(q.metrics.dropped), "nothing is dropped till the capacity")
+
+			q.Push(c.overflowing)
+
+			received, overflowed := receive(t, q, len(c.expected))
+			assert.Equal(t, c.expected, received)
+			assert.Equal(t, c.expectedOverflowed, overflowed)
+
+			assert.Equal(t, c.expectedDropped, testutil.ToFloat64(q.metrics.dropped))
+			assert.Equal(t, c.expectedCoalesced, testutil.ToFloat64(q.metrics.coalesced))
+			assert.Equal(t, c.expectedDisconnected, testutil.ToFloat64(q.metrics.disconnected))
+			assert.Equal(t, float64(len(c.expected)), testutil.ToFloat64(q.metrics.delivered))
+			assert.Equal(t, float64(0), testutil.ToFloat64(q.metrics.queued))
+		})
+	}
+}
+
+func TestQueueCoalesceMerges(t *testing.T) {
+	sum := func(queued, item testEvent) testEvent {
+		return testEvent{item.Type, queued.N + item.N}
+	}
+	q := newTestMergingQueue(t, "test_merge", 2, Coalesce, time.Second, sum)
+
+	pushAll(q, testEvent{"a", 1}, testEvent{"b", 2}, testEvent{"a", 3}, testEvent{"a", 4})
+
+	received, overflowed := receive(t, q, 2)
+	assert.False(t, overflowed)
+	assert.Equal(t, []testEvent{{"a", 8}, {"b", 2}}, received)
+	assert.Equal(t, float64(2), testutil.ToFloat64(q.metrics.coalesced))
+	assert.Equal(t, float64(0), testutil.ToFloat64(q.metrics.dropped))
+}
+
+func TestQueueIgnoresPushAfterDisconnect(t *testing.T) {
+	q := newTestQueue(t, "test_after_disconnect", 1, Disconnect, time.Second)
+
+	pushAll(q, testEvent{"a", 1}, testEvent{"b", 2}, testEvent{"c", 3})
+	assert.Equal(t, float64(1), testutil.ToFloat64(q.metrics.disconnected), "disconnected once")
+	assert.Equal(t, float64(0), testutil.ToFloat64(q.metrics.queued))
+
+	received, overflowed := receive(t, q, 1)
+	assert.True(t, overflowed)
+	assert.Equal(t, []testEvent{testResync()}, received, "the resync marker is the last event")
+}
+
+func TestQueueResyncIsNotWaitedForever(t *testing.T) {
+	q := newTestQueue(t, "test_resync_timeout", 1, Disconnect, 50*time.Millisecond)
+	pushAll(q, testEvent{"a", 1}, testEvent{"b", 2})
+
+	// the client doesn't read at all
+	result := make(chan bool, 1)
+	go func() {
+		result <- q.Run(context.Background(), nil)
+	}()
+
+	select {
+	case overflowed := <-result:
+		assert.True(t, overflowed)
+	case <-time.After(time.Second):
+		t.Fatal("Run hasn't returned after the resync timeout")
+	}
+	assert.Equal(t, float64(0), testutil.ToFloat64(q.metrics.delivered))
+}
+
+func TestQueueRunSkipsNotPassed(t *testing.T) {
+	q := newTestQueue(t, "test_pass", 3, DropOldest, time.Second)
+	pushAll(q, testEvent{"a", 1}, testEvent{"b", 2}, testEvent{"c", 3})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx, func(e testEvent) bool {
+		return e.Type != "b"
+	})
+
+	require.Equal(t, testEvent{"a", 1}, <-q.Out())
+	require.Equal(t, testEvent{"c", 3}, <-q.Out())
+	// the counter is incremented right after the send
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(q.metrics.delivered) == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestQueueClose(t *testing.T) {
+	q := newTestQueue(t, "test_close", 3, DropOldest, time.Second)
+	assert.Equal(t, float64(1), testutil.ToFloat64(q.metrics.active))
+
+	pushAll(q, testEvent{"a", 1}, testEvent{"b", 2})
+	q.Close()
+	q.Close()
+
+	_, open := <-q.Out()
+	assert.False(t, open)
+	assert.Equal(t, float64(0), testutil.ToFloat64(q.metrics.active))
+	assert.Equal(t, float64(0), testutil.ToFloat64(q.metrics.queued))
+
+	q.Push(testEvent{"c", 3})
+	assert.Equal(t, float64(0), testutil.ToFloat64(q.metrics.queued), "the closed queue doesn't take the events")
+}
@@ -141,6 +141,7 @@ export const PIN_VIDEO = "pinVideo";
 export const UN_PIN_VIDEO = "unPinVideo";
 
 export const START_CLOSING_VIDEO = "startClosingVideo";
+export const VIDEO_ROOM_CHANGED = "videoRoomChanged";
 
 export const CHAT_NOTIFICATION_SETTINGS_CHANGED = "chatNotificationSettingsChanged";
 
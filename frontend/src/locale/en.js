@@ -60,6 +60,10 @@ export default {
     user_open_chat: "Open tet-a-tet chat",
     user_online: "Online",
     user_in_video_call: "In call",
+    presence_away: "Away",
+    presence_busy: "Busy",
+    presence_dnd: "Do not disturb",
+    presence_idle: "Idle",
     user_offline: "Offline",
     participants_modal_title: "Participants",
     add: "Add",
@@ -145,13 +149,19 @@ export default {
     notifications: "Notifications",
     notify_about_mentions: "Mentions",
     notify_about_missed_calls: "Missed calls",
+    notify_about_call_invitations: "Incoming calls",
     notify_about_replies: "Replies",
     notify_about_reactions: "Reactions",
     notification_mention: "Mention by {0}",
     notification_missed_call: "Missed call by {0}",
+    notification_meeting_reminder: "Meeting by {0} is about to start",
     notification_reply: "Reply by {0}",
     notification_reaction: "Reaction by {0}",
+    notification_keyword: "Keyword in the message by {0}",
+    notification_group: "{0} in total, by {1} users",
+    quiet_hours_are_over: "The quiet hours are over",
     no_notifications: "You don't have notifications",
+    load_more: "Load more",
     search_in_chats: "Search by chats",
     search_in_messages: "Search by messages",
     link: "Link",
@@ -233,11 +243,15 @@ export default {
     message_edit_audio: "Add an audio",
     add_media_audio_by_link: "Add an audio by link",
     file_download: "Download the file",
+    download_all_files: "Download all the files as a zip archive",
     chats_not_found: "Chats not found",
     users_not_found: "Users not found",
     messages_not_found: "Messages not found",
     chat_not_found: "The chat doesn't exist or you aren't a participant of the chat",
     user_is_already_in_other_call: "User {0} is already in a call",
+    user_is_busy: "User {0} is busy and can't answer now",
+    file_infected: "The file is infected, it isn't available for downloading",
+    file_infected_uploaded: "The file {0} is infected, it isn't available for downloading",
     search_related_message: "Find related message",
     add_reaction_on_message: "Add a reaction",
     configuring_smileys: "Configuring smileys",
@@ -304,6 +318,8 @@ export default {
     call_notifications_in_browser: "Call notification in-browser",
     remove_sessions: "Remove sessions",
     video_successfully_reconnected: "Successfully reconnected to video server",
+    video_moved_to_breakout_room: "You have been moved to the breakout room '{0}'",
+    video_moved_to_main_room: "You have been moved back to the main room",
     send_message_after_media_insert: "Send the message",
     send_message_after_media_insert_description: "Send the message after media insert",
     open_in_new_tab: "Open in the new tab",
@@ -60,6 +60,10 @@ export default {
     user_open_chat: "Открыть диалог",
     user_online: "Онлайн",
     user_in_video_call: "В звонке",
+    presence_away: "Отошёл",
+    presence_busy: "Занят",
+    presence_dnd: "Не беспокоить",
+    presence_idle: "Неактивен",
     user_offline: "Оффлайн",
     participants_modal_title: "Участники",
     add: "Добавить",
@@ -145,13 +149,19 @@ export default {
     notifications: "Уведомления",
     notify_about_mentions: "Упоминания",
     notify_about_missed_calls: "Пропущенные звонки",
+    notify_about_call_invitations: "Входящие звонки",
     notify_about_replies: "Ответы",
     notify_about_reactions: "Реакции",
     notification_mention: "Упоминание от {0}",
     notification_missed_call: "Пропущенный звонок от {0}",
+    notification_meeting_reminder: "Скоро начнётся встреча от {0}",
     notification_reply: "Ответ от {0}",
     notification_reaction: "Реакция от {0}",
+    notification_keyword: "Ключевое слово в сообщении от {0}",
+    notification_group: "Всего {0}, пользователей: {1}",
+    quiet_hours_are_over: "Тихие часы закончились",
     no_notifications: "У вас нет уведомлений",
+    load_more: "Загрузить ещё",
     search_in_chats: "Поиск по чатам",
     search_in_messages: "Поиск по сообщениям",
     link: "Ссылка",
@@ -233,11 +243,15 @@ export default {
     message_edit_audio: "Добавить аудио",
     add_media_audio_by_link: "Добавить аудио по ссылке",
     file_download: "Скачать файл",
+    download_all_files: "Скачать все файлы zip-архивом",
     chats_not_found: "Чаты не найдены",
     users_not_found: "Пользователи не найдены",
     messages_not_found: "Сообщения не найдены",
     chat_not_found: "Чат не найден или вы в нём не состоите",
     user_is_already_in_other_call: "Пользователь {0} уже находится в звонке",
+    user_is_busy: "Пользователь {0} занят и не может ответить сейчас",
+    file_infected: "Файл заражён, он недоступен для скачивания",
+    file_infected_uploaded: "Файл {0} заражён, он недоступен для скачивания",
     search_related_message: "Найти связанное сообщение",
     add_reaction_on_message: "Добавить реакцию",
     configuring_smileys: "Настройка смайликов",
@@ -304,6 +318,8 @@ export default {
     call_notifications_in_browser: "Нотификация о звонке в браузере",
     remove_sessions: "Удалить сессии",
     video_successfully_reconnected: "Успешно передпоключились к серверу",
+    video_moved_to_breakout_room: "Вы перемещены в комнату '{0}'",
+    video_moved_to_main_room: "Вы перемещены обратно в основную комнату",
     send_message_after_media_insert: "Отправить сообщение",
     send_message_after_media_insert_description: "Отправить сообщение после вставки медиа",
     open_in_new_tab: "Открыть в новой вкладке",
@@ -1,44 +1,86 @@
 import {graphQlClient} from "@/graphql/graphql";
 
+// the sequenced subscriptions have the seq field
+const getSeq = (e) => {
+    const data = e.data;
+    if (!data) {
+        return null
+    }
+    return Object.values(data)[0]?.seq
+}
+
+// the server completes the subscription after it because the client hasn't kept up
+const isResyncRequired = (e) => {
+    const data = e.data;
+    if (!data) {
+        return false
+    }
+    const payload = Object.values(data)[0];
+    const first = Array.isArray(payload) ? payload[0] : payload;
+    return first?.eventType === 'resync_required'
+}
+
 // expects methods setError, onNextSubscriptionElement, getGraphQlSubscriptionQuery
 export default (nameForLog, getGraphQlSubscriptionQuery, setError, onNextSubscriptionElement) => {
     const state = {};
+    // graphql-ws resends the same payload on reconnect, so the mutated variables make the server replay the missed events
+    const variables = {};
+
+    // keeps since, so the missed events are replayed
+    const subscribe = () => {
+        const onNext_ = (e) => {
+            console.debug(`Got ${nameForLog} event`, e);
+            if (e.errors != null && e.errors.length) {
+                console.log("Subscription errors", e.errors);
+                setError(null, `Error in onNext ${nameForLog} subscription`);
+                return
+            }
+            if (isResyncRequired(e)) {
+                state.resyncRequired = true;
+                return
+            }
+            const seq = getSeq(e);
+            if (seq != null && (variables.since == null || seq > variables.since)) {
+                variables.since = seq;
+            }
+            onNextSubscriptionElement(e);
+        }
+        const onError = (e) => {
+            console.error(`Got err in ${nameForLog} subscription`, e);
+            if (Array.isArray(e)) {
+                setError(null, `Error in onError ${nameForLog} subscription`);
+            }
+        }
+        const onComplete = () => {
+            console.log(`Got complete in ${nameForLog} subscription`);
+            if (state.resyncRequired) {
+                state.resyncRequired = false;
+                console.log(`Resubscribing to ${nameForLog} after the resync request`);
+                subscribe();
+            }
+        }
+
+        console.log(`Subscribing to ${nameForLog}`);
+        state.unsubscribe = graphQlClient.subscribe(
+            {
+                query: getGraphQlSubscriptionQuery(),
+                variables: variables,
+            },
+            {
+                next: onNext_,
+                error: onError,
+                complete: onComplete,
+            },
+        );
+    };
 
     return {
         graphQlSubscribe() {
             // unsubscribe from the previous
             this.graphQlUnsubscribe();
+            delete variables.since;
 
-            const onNext_ = (e) => {
-                console.debug(`Got ${nameForLog} event`, e);
-                if (e.errors != null && e.errors.length) {
-                    console.log("Subscription errors", e.errors);
-                    setError(null, `Error in onNext ${nameForLog} subscription`);
-                    return
-                }
-                onNextSubscriptionElement(e);
-            }
-            const onError = (e) => {
-                console.error(`Got err in ${nameForLog} subscription`, e);
-                if (Array.isArray(e)) {
-                    setError(null, `Error in onError ${nameForLog} subscription`);
-                }
-            }
-            const onComplete = () => {
-                console.log(`Got complete in ${nameForLog} subscription`);
-            }
-
-            console.log(`Subscribing to ${nameForLog}`);
-            state.unsubscribe = graphQlClient.subscribe(
-                {
-                    query: getGraphQlSubscriptionQuery(),
-                },
-                {
-                    next: onNext_,
-                    error: onError,
-                    complete: onComplete,
-                },
-            );
+            subscribe();
         },
         graphQlUnsubscribe() {
             console.log(`Unsubscribing from ${nameForLog}`);
@@ -46,6 +88,7 @@ export default (nameForLog, getGraphQlSubscriptionQuery, setError, onNextSubscri
                 state.unsubscribe();
             }
             state.unsubscribe = null;
+            state.resyncRequired = false;
         },
     };
 }
@@ -1,5 +1,5 @@
 import {graphQlClient} from "@/graphql/graphql";
-import {deepCopy, hasLength} from "@/utils";
+import {deepCopy, escapeHtml, hasLength} from "@/utils";
 import axios from "axios";
 
 // requires getUserIdsSubscribeTo(), onUserStatusChanged()
@@ -21,8 +21,45 @@ export default (nameForLog) => {
                     bldr += " (" + this.$vuetify.locale.t('$vuetify.user_in_video_call') + ")";
                   }
                 }
+                const presence = this.getPresenceText(item);
+                if (hasLength(presence)) {
+                  bldr += " · " + presence;
+                }
                 return bldr;
             },
+            // is used in v-html, so the user's input is escaped
+            getPresenceText(item) {
+                const presence = item.presence;
+                if (!presence) {
+                  return null
+                }
+                const parts = [];
+                if (hasLength(presence.emoji)) {
+                  parts.push(escapeHtml(presence.emoji));
+                }
+                if (hasLength(presence.text)) {
+                  parts.push(escapeHtml(presence.text));
+                } else if (hasLength(presence.status)) {
+                  parts.push(this.$vuetify.locale.t('$vuetify.presence_' + presence.status));
+                } else if (presence.idle && item.online) {
+                  parts.push(this.$vuetify.locale.t('$vuetify.presence_idle'));
+                }
+                return parts.join(" ")
+            },
+            // the participants from chat already have the presence, it's updated by the events
+            applyPresence(item, dtoItem) {
+                if (dtoItem.eventType != "user_presence_changed" || item.id != dtoItem.userId) {
+                  return
+                }
+                item.presence = {
+                  userId: dtoItem.userId,
+                  status: dtoItem.presenceStatus,
+                  emoji: dtoItem.presenceEmoji,
+                  text: dtoItem.presenceText,
+                  expiresAt: dtoItem.presenceExpiresAt,
+                  idle: dtoItem.idle,
+                };
+            },
             transformItem(item) {
                 item.online = false;
                 item.isInVideo = false;
@@ -31,10 +68,19 @@ export default (nameForLog) => {
                 const copiedNewItem = deepCopy(newItem);
                 copiedNewItem.online = existing.online;
                 copiedNewItem.isInVideo = existing.isInVideo;
+                if (!copiedNewItem.presence) {
+                  copiedNewItem.presence = existing.presence;
+                }
                 return copiedNewItem
             },
             getUserBadgeColor(item) {
-                return item.isInVideo ? 'red accent-4' : 'success accent-4'
+                if (item.isInVideo || item.presence?.status == 'busy' || item.presence?.status == 'dnd') {
+                  return 'red accent-4'
+                }
+                if (item.presence?.status == 'away' || item.presence?.idle) {
+                  return 'warning accent-4'
+                }
+                return 'success accent-4'
             },
             getUserOnlineSubscriptionQuery() {
                 const userIds = this.getUserIdsSubscribeTo();
@@ -45,6 +91,12 @@ export default (nameForLog) => {
                             online
                             isInVideo
                             lastSeenDateTime
+                            eventType
+                            presenceStatus
+                            presenceEmoji
+                            presenceText
+                            presenceExpiresAt
+                            idle
                         }
                     }`
             },
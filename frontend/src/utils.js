@@ -391,6 +391,11 @@ export const isCalling = (status) => {
   return status == "beingInvited"
 }
 
+// the callee is either in do-not-disturb mode or in another call
+export const isBusy = (status) => {
+  return status == "busy"
+}
+
 export const setLanguageToVuetify = (that, newLanguage) => {
     that.$vuetify.locale.current = newLanguage;
 }
@@ -407,6 +412,8 @@ export const getNotificationSubtitle = (vuetify, item) => {
     switch (item.notificationType) {
         case "missed_call":
             return vuetify.locale.t('$vuetify.notification_missed_call', unescapeHtml(item.byLogin))
+        case "meeting_reminder":
+            return vuetify.locale.t('$vuetify.notification_meeting_reminder', unescapeHtml(item.byLogin))
         case "mention":
             let builder1 = vuetify.locale.t('$vuetify.notification_mention', unescapeHtml(item.byLogin))
             if (hasLength(item.chatTitle)) {
@@ -425,6 +432,12 @@ export const getNotificationSubtitle = (vuetify, item) => {
                 builder3 += (vuetify.locale.t('$vuetify.in') + "'" + unescapeHtml(item.chatTitle) + "'")
             }
             return builder3
+        case "keyword":
+            let builder4 = vuetify.locale.t('$vuetify.notification_keyword', unescapeHtml(item.byLogin))
+            if (hasLength(item.chatTitle)) {
+                builder4 += (vuetify.locale.t('$vuetify.in') + "'" + unescapeHtml(item.chatTitle) + "'")
+            }
+            return builder4
     }
 }
 
@@ -475,6 +488,13 @@ export const unescapeHtml = (text) => {
     return he.decode(text);
 }
 
+export const escapeHtml = (text) => {
+    if (!text) {
+        return text
+    }
+    return he.encode(text, {useNamedReferences: true});
+}
+
 export const getExtendedUserFragment = (reqEmail) => {
     const email = `
 email,
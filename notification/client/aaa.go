@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/logger"
+	"nkonev.name/notification/utils"
+)
+
+type AaaRestClient struct {
+	client            *http.Client
+	aaaBaseUrl        string
+	getUserEmailsPath string
+	tracer            trace.Tracer
+	lgr               *logger.Logger
+}
+
+func NewAaaRestClient(lgr *logger.Logger) *AaaRestClient {
+	client := &http.Client{
+		Transport: &http.Transport{
+			MaxIdleConns:       viper.GetInt("http.maxIdleConns"),
+			IdleConnTimeout:    viper.GetDuration("http.idleConnTimeout"),
+			DisableCompression: viper.GetBool("http.disableCompression"),
+		},
+		Timeout: viper.GetDuration("http.timeout"),
+	}
+
+	return &AaaRestClient{
+		client:            client,
+		aaaBaseUrl:        viper.GetString("aaa.url.base"),
+		getUserEmailsPath: viper.GetString("aaa.url.getUserEmails"),
+		tracer:            otel.Tracer("rest/client"),
+		lgr:               lgr,
+	}
+}
+
+// the absent users aren't returned
+func (h *AaaRestClient) GetUserEmails(c context.Context, userIds []int64) ([]dto.UserEmail, error) {
+	if len(userIds) == 0 {
+		return []dto.UserEmail{}, nil
+	}
+
+	queryParams := url.Values{}
+	for _, userId := range userIds {
+		queryParams.Add("userId", utils.Int64ToString(userId))
+	}
+	fullUrl := h.aaaBaseUrl + h.getUserEmailsPath + "?" + queryParams.Encode()
+
+	ctx, span := h.tracer.Start(c, "user.GetEmails")
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	response, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status on user.GetEmails: %v", response.StatusCode)
+	}
+
+	users := []dto.UserEmail{}
+	if err := json.NewDecoder(response.Body).Decode(&users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
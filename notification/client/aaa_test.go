@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/viper"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/logger"
+)
+
+func TestGetUserEmails(t *testing.T) {
+	var requestedUserIds []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/internal/user/email" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		requestedUserIds = r.URL.Query()["userId"]
+		email := "alice@example.com"
+		w.Header().Set("Content-Type", "application/json")
+		// the absent user 3 isn't returned
+		json.NewEncoder(w).Encode([]dto.UserEmail{
+			{Id: 1, Login: "alice", Email: &email},
+			{Id: 2, Login: "bob", Email: nil},
+		})
+	}))
+	defer server.Close()
+	viper.Set("aaa.url.base", server.URL)
+	viper.Set("aaa.url.getUserEmails", "/internal/user/email")
+
+	c := NewAaaRestClient(logger.NewLogger())
+
+	users, err := c.GetUserEmails(context.Background(), []int64{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requestedUserIds) != 3 || requestedUserIds[0] != "1" || requestedUserIds[2] != "3" {
+		t.Errorf("unexpected requested users %v", requestedUserIds)
+	}
+	if len(users) != 2 || users[0].Login != "alice" || users[0].Email == nil || *users[0].Email != "alice@example.com" || users[1].Email != nil {
+		t.Errorf("unexpected users %+v", users)
+	}
+
+	requestedUserIds = nil
+	users, err = c.GetUserEmails(context.Background(), []int64{})
+	if err != nil || len(users) != 0 || requestedUserIds != nil {
+		t.Errorf("aaa shouldn't be requested for no users")
+	}
+
+	viper.Set("aaa.url.getUserEmails", "/internal/user/absent")
+	c = NewAaaRestClient(logger.NewLogger())
+	if _, err := c.GetUserEmails(context.Background(), []int64{1}); err == nil {
+		t.Error("expected the error")
+	}
+}
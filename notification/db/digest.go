@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/rotisserie/eris"
+	"nkonev.name/notification/dto"
+	"time"
+)
+
+// meeting_reminder isn't included because it's outdated at the moment of sending
+const digestNotificationTypes = `('mention', 'reply', 'reaction', 'missed_call', 'keyword')`
+
+func (db *DB) GetNotificationDigestSettings(ctx context.Context, userId int64) (*dto.NotificationDigestSettings, error) {
+	row := db.QueryRowContext(ctx, `select frequency, email is not null from notification_digest_settings where user_id = $1`, userId)
+	if row.Err() != nil {
+		return nil, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var result = new(dto.NotificationDigestSettings)
+	err := row.Scan(&result.Frequency, &result.HasEmail)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// if there is no rows then return default
+			return &dto.NotificationDigestSettings{ // should match to defaults
+				Frequency: dto.DigestFrequencyNone,
+				HasEmail:  false,
+			}, nil
+		}
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	return result, nil
+}
+
+func (db *DB) PutNotificationDigestFrequency(ctx context.Context, userId int64, frequency string) error {
+	if _, err := db.ExecContext(ctx, `insert into notification_digest_settings(user_id, frequency) values($1, $2) on conflict(user_id) do update set frequency = excluded.frequency`, userId, frequency); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+func (db *DB) PutNotificationDigestEmail(ctx context.Context, userId int64, login string, email *string) error {
+	if _, err := db.ExecContext(ctx, `insert into notification_digest_settings(user_id, login, email, email_checked) values($1, $2, $3, true) on conflict(user_id) do update set login = excluded.login, email = excluded.email, email_checked = true`, userId, login, email); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// the users who haven't got the email in aaa aren't looked up again, they get it with the aaa event
+func (db *DB) SetNotificationDigestEmailChecked(ctx context.Context, userIds []int64) error {
+	if _, err := db.ExecContext(ctx, `update notification_digest_settings set email_checked = true where user_id = any($1)`, userIds); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// returns the subscribed users whose email hasn't come from aaa, e.g. they were created before the digest, ordered by user_id in order to page by it
+func (db *DB) GetDigestSubscribersWithoutEmail(ctx context.Context, afterUserId int64, limit int) ([]int64, error) {
+	rows, err := db.QueryContext(ctx, `select user_id from notification_digest_settings
+		where frequency <> $1 and email is null and not email_checked and user_id > $2
+		order by user_id
+		limit $3`,
+		dto.DigestFrequencyNone, afterUserId, limit)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+
+	list := make([]int64, 0)
+	for rows.Next() {
+		var userId int64
+		if err := rows.Scan(&userId); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		} else {
+			list = append(list, userId)
+		}
+	}
+	return list, nil
+}
+
+func (db *DB) DeleteNotificationDigestSettings(ctx context.Context, userId int64) error {
+	if _, err := db.ExecContext(ctx, `delete from notification_digest_settings where user_id = $1`, userId); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// returns false in case the token is unknown
+func (db *DB) UnsubscribeNotificationDigest(ctx context.Context, unsubscribeToken string) (bool, error) {
+	res, err := db.ExecContext(ctx, `update notification_digest_settings set frequency = $2 where unsubscribe_token::text = $1`, unsubscribeToken, dto.DigestFrequencyNone)
+	if err != nil {
+		return false, eris.Wrap(err, "error during interacting with db")
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, eris.Wrap(err, "error during interacting with db")
+	}
+	return affected > 0, nil
+}
+
+// returns the recipients with the given frequency, whose last digest was sent earlier than period ago, ordered by user_id in order to page by it
+func (db *DB) GetDueDigestRecipients(ctx context.Context, frequency string, period time.Duration, afterUserId int64, limit int) ([]dto.DigestRecipient, error) {
+	rows, err := db.QueryContext(ctx, `select user_id, email, coalesce(login, ''), frequency, unsubscribe_token::text, last_sent_date_time
+		from notification_digest_settings
+		where frequency = $1 and email is not null and user_id > $2 and (last_sent_date_time is null or last_sent_date_time < utc_now() - make_interval(secs => $3))
+		order by user_id
+		limit $4`,
+		frequency, afterUserId, period.Seconds(), limit)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+
+	list := make([]dto.DigestRecipient, 0)
+	for rows.Next() {
+		recipient := dto.DigestRecipient{}
+		if err := rows.Scan(&recipient.UserId, &recipient.Email, &recipient.Login, &recipient.Frequency, &recipient.UnsubscribeToken, &recipient.LastSentDateTime); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		} else {
+			list = append(list, recipient)
+		}
+	}
+	return list, nil
+}
+
+// returns the unread notifications which were created after since, the notifications are deleted on reading
+func (db *DB) GetDigestNotifications(ctx context.Context, userId int64, since *time.Time, size int) ([]dto.NotificationDto, error) {
+	rows, err := db.QueryContext(ctx, `select id, notification_type, description, chat_id, message_id, create_date_time, by_user_id, by_login, chat_title
+		from notification
+		where user_id = $1 and notification_type in `+digestNotificationTypes+` and ($2::timestamp is null or create_date_time > $2::timestamp)
+		order by id desc
+		limit $3`,
+		userId, since, size)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+
+	list := make([]dto.NotificationDto, 0)
+	for rows.Next() {
+		notificationDto := dto.NotificationDto{}
+		if err := rows.Scan(&notificationDto.Id, &notificationDto.NotificationType, &notificationDto.Description, &notificationDto.ChatId, &notificationDto.MessageId, &notificationDto.CreateDateTime, &notificationDto.ByUserId, &notificationDto.ByLogin, &notificationDto.ChatTitle); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		} else {
+			list = append(list, notificationDto)
+		}
+	}
+	return list, nil
+}
+
+func (db *DB) GetDigestNotificationCount(ctx context.Context, userId int64, since *time.Time) (int64, error) {
+	row := db.QueryRowContext(ctx, `select count(*) from notification where user_id = $1 and notification_type in `+digestNotificationTypes+` and ($2::timestamp is null or create_date_time > $2::timestamp)`, userId, since)
+	if row.Err() != nil {
+		return 0, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var count int64
+	err := row.Scan(&count)
+	if err != nil {
+		return 0, eris.Wrap(err, "error during interacting with db")
+	}
+
+	return count, nil
+}
+
+// sets the time of sending only if nobody changed it since the reading, so the several instances don't send the same digest
+// returns false in case the other instance took this recipient
+func (db *DB) SetDigestLastSent(ctx context.Context, userId int64, previous *time.Time, lastSent *time.Time) (bool, error) {
+	res, err := db.ExecContext(ctx, `update notification_digest_settings set last_sent_date_time = $3 where user_id = $1 and last_sent_date_time is not distinct from $2::timestamp`, userId, previous, lastSent)
+	if err != nil {
+		return false, eris.Wrap(err, "error during interacting with db")
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, eris.Wrap(err, "error during interacting with db")
+	}
+	return affected > 0, nil
+}
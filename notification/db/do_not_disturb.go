@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"github.com/rotisserie/eris"
+	"time"
+)
+
+func (db *DB) PutNotificationDoNotDisturb(ctx context.Context, userId int64, expiresAt *time.Time) error {
+	var expiresAtUtc *time.Time
+	if expiresAt != nil {
+		utc := expiresAt.UTC()
+		expiresAtUtc = &utc
+	}
+	if _, err := db.ExecContext(ctx, `insert into notification_do_not_disturb(user_id, expires_at) values($1, $2)
+		on conflict(user_id) do update set expires_at = excluded.expires_at`, userId, expiresAtUtc); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+func (db *DB) DeleteNotificationDoNotDisturb(ctx context.Context, userId int64) error {
+	if _, err := db.ExecContext(ctx, `delete from notification_do_not_disturb where user_id = $1`, userId); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// the expired one isn't taken into account even if the event service hasn't sent the clearing yet
+func (db *DB) GetNotificationDoNotDisturbUserIds(ctx context.Context, userIds []int64, now time.Time) (map[int64]bool, error) {
+	rows, err := db.QueryContext(ctx, `select user_id from notification_do_not_disturb where user_id = any($1) and (expires_at is null or expires_at > $2)`, userIds, now.UTC())
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+
+	res := map[int64]bool{}
+	for rows.Next() {
+		var userId int64
+		if err := rows.Scan(&userId); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		}
+		res[userId] = true
+	}
+	return res, nil
+}
@@ -0,0 +1,10 @@
+create table notification_digest_settings(
+    user_id bigint primary key,
+    frequency varchar(16) not null default 'none',
+    unsubscribe_token uuid not null unique default gen_random_uuid(),
+    email varchar(256),
+    login varchar(256),
+    last_sent_date_time timestamp
+);
+
+create index notification_user_id__create_date_time__idx on notification (user_id, create_date_time);
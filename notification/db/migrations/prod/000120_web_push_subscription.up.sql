@@ -0,0 +1,12 @@
+-- the endpoint identifies the browser on the device
+create table web_push_subscription(
+    id bigserial primary key,
+    user_id bigint not null,
+    endpoint text not null unique,
+    p256dh varchar(128) not null,
+    auth varchar(64) not null,
+    user_agent text,
+    create_date_time timestamp not null default utc_now()
+);
+
+create index web_push_subscription_user_id__idx on web_push_subscription (user_id);
@@ -0,0 +1,12 @@
+create table notification_quiet_hours(
+    user_id bigint primary key,
+    enabled boolean not null default false,
+    time_zone varchar(64) not null default 'UTC',
+    windows jsonb not null default '[]',
+    -- the beginning of holding back the delivery, the summary is sent after the quiet hours in case it isn't null
+    held_since timestamp
+);
+
+create index notification_quiet_hours_held_since_idx on notification_quiet_hours(user_id) where held_since is not null;
+
+alter table notification_settings_chat add column bypass_quiet_hours boolean;
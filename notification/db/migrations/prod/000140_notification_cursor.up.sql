@@ -0,0 +1,3 @@
+-- for the cursor listing and the grouping
+create index notification_user_id_id_idx on notification(user_id, id);
+create index notification_user_id_group_idx on notification(user_id, chat_id, message_id, notification_type);
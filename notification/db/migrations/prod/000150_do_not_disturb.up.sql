@@ -0,0 +1,6 @@
+-- the projection of the do not disturb statuses from the event service
+create table notification_do_not_disturb(
+    user_id bigint primary key,
+    -- null means till it's cleared
+    expires_at timestamp
+);
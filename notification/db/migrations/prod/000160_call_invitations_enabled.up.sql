@@ -0,0 +1,2 @@
+alter table notification_settings add column call_invitations_enabled boolean not null default true;
+alter table notification_settings_chat add column call_invitations_enabled boolean;
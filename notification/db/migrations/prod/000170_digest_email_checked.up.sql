@@ -0,0 +1,2 @@
+-- the users who were created before the digest are looked up in aaa once
+alter table notification_digest_settings add column email_checked boolean not null default false;
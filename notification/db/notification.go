@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"github.com/rotisserie/eris"
 	"nkonev.name/notification/dto"
 	"time"
@@ -80,6 +81,106 @@ func (db *DB) GetNotifications(ctx context.Context, userId int64, size, offset i
 
 }
 
+// appends the conditions of the filter, the arguments are numbered after the existing ones
+func notificationFilterCondition(filter *dto.NotificationFilter, args []any) (string, []any) {
+	condition := ""
+	if len(filter.Types) > 0 {
+		args = append(args, filter.Types)
+		condition += fmt.Sprintf(" and notification_type = any($%v)", len(args))
+	}
+	if filter.ChatId != nil {
+		args = append(args, *filter.ChatId)
+		condition += fmt.Sprintf(" and chat_id = $%v", len(args))
+	}
+	if filter.MessageId != nil {
+		args = append(args, *filter.MessageId)
+		condition += fmt.Sprintf(" and message_id = $%v", len(args))
+	}
+	return condition, args
+}
+
+// returns the notifications with id less than cursor, the nil cursor means the first page
+func (db *DB) GetNotificationsByCursor(ctx context.Context, userId int64, filter *dto.NotificationFilter, cursor *int64, size int) ([]dto.NotificationDto, error) {
+	condition, args := notificationFilterCondition(filter, []any{userId, cursor, size})
+	rows, err := db.QueryContext(ctx, `select id, notification_type, description, chat_id, message_id, create_date_time, by_user_id, by_login, chat_title
+		from notification
+		where user_id = $1 and ($2::bigint is null or id < $2::bigint)`+condition+`
+		order by id desc
+		limit $3`, args...)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+
+	list := make([]dto.NotificationDto, 0)
+	for rows.Next() {
+		notificationDto := dto.NotificationDto{}
+		if err := rows.Scan(&notificationDto.Id, &notificationDto.NotificationType, &notificationDto.Description, &notificationDto.ChatId, &notificationDto.MessageId, &notificationDto.CreateDateTime, &notificationDto.ByUserId, &notificationDto.ByLogin, &notificationDto.ChatTitle); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		} else {
+			list = append(list, notificationDto)
+		}
+	}
+	return list, nil
+}
+
+// the notifications about the same message are grouped, the ones without the message, e.g. missed_call and meeting_reminder, are separate
+const notificationGroupKey = `chat_id, notification_type, message_id, case when message_id is null then id end`
+
+// aggregates the notifications by chat, message and type, each group is represented by its latest notification
+// and is paged by its id
+func (db *DB) GetNotificationGroupsByCursor(ctx context.Context, userId int64, filter *dto.NotificationFilter, cursor *int64, size int) ([]dto.NotificationDto, error) {
+	condition, args := notificationFilterCondition(filter, []any{userId, cursor, size})
+	rows, err := db.QueryContext(ctx, `select n.id, n.notification_type, n.description, n.chat_id, n.message_id, n.create_date_time, n.by_user_id, n.by_login, n.chat_title, g.group_count, g.by_users_count
+		from (
+			select max(id) as id, count(*) as group_count, count(distinct by_user_id) as by_users_count
+			from notification
+			where user_id = $1`+condition+`
+			group by `+notificationGroupKey+`
+			having ($2::bigint is null or max(id) < $2::bigint)
+			order by max(id) desc
+			limit $3
+		) g
+		join notification n on n.id = g.id
+		order by n.id desc`, args...)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+
+	list := make([]dto.NotificationDto, 0)
+	for rows.Next() {
+		notificationDto := dto.NotificationDto{}
+		if err := rows.Scan(&notificationDto.Id, &notificationDto.NotificationType, &notificationDto.Description, &notificationDto.ChatId, &notificationDto.MessageId, &notificationDto.CreateDateTime, &notificationDto.ByUserId, &notificationDto.ByLogin, &notificationDto.ChatTitle, &notificationDto.GroupCount, &notificationDto.GroupByUsersCount); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		} else {
+			list = append(list, notificationDto)
+		}
+	}
+	return list, nil
+}
+
+// returns the deleted notifications, only id and type are filled
+func (db *DB) DeleteNotificationsByFilter(ctx context.Context, userId int64, filter *dto.NotificationFilter) ([]dto.NotificationDto, error) {
+	condition, args := notificationFilterCondition(filter, []any{userId})
+	rows, err := db.QueryContext(ctx, `delete from notification where user_id = $1`+condition+` returning id, notification_type`, args...)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+
+	list := make([]dto.NotificationDto, 0)
+	for rows.Next() {
+		notificationDto := dto.NotificationDto{}
+		if err := rows.Scan(&notificationDto.Id, &notificationDto.NotificationType); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		} else {
+			list = append(list, notificationDto)
+		}
+	}
+	return list, nil
+}
+
 func (db *DB) GetNotificationCount(ctx context.Context, userId int64) (int64, error) {
 	row := db.QueryRowContext(ctx, "select count(*) from notification where user_id = $1", userId)
 	if row.Err() != nil {
@@ -99,8 +200,18 @@ func (db *DB) ClearAllNotifications(ctx context.Context, userId int64) error {
 	return err
 }
 
+// the low-value notifications are evicted first, so the popular message's reactions don't push out the mentions
+const notificationEvictionOrder = `case notification_type
+	when 'reaction' then 0
+	when 'meeting_reminder' then 1
+	when 'keyword' then 2
+	when 'missed_call' then 3
+	when 'reply' then 4
+	else 5
+end`
+
 func (db *DB) GetExcessUserNotificationIds(ctx context.Context, userId int64, numToDelete int64) ([]int64, error) {
-	rows, err := db.QueryContext(ctx, "select id from notification where user_id = $1 order by id asc limit $2", userId, numToDelete)
+	rows, err := db.QueryContext(ctx, "select id from notification where user_id = $1 order by "+notificationEvictionOrder+", id asc limit $2", userId, numToDelete)
 	if err != nil {
 		return nil, eris.Wrap(err, "error during interacting with db")
 	}
@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"nkonev.name/notification/config"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/logger"
+)
+
+var testDb *DB
+
+// runs against the postgres of docker-compose
+func TestMain(m *testing.M) {
+	config.InitViper()
+	lgr := logger.NewLogger()
+
+	d, err := ConfigureDb(lgr, nil)
+	if err != nil {
+		lgr.Panicf("Error during getting db connection for test: %v", err)
+	}
+	if err := d.Ping(); err != nil {
+		fmt.Printf("Skipping the db tests because postgres isn't available: %v\n", err)
+		os.Exit(0)
+	}
+	d.Migrate(&MigrationsConfig{})
+	testDb = d
+
+	retCode := m.Run()
+	d.Close()
+	os.Exit(retCode)
+}
+
+const testUserId = 1_000_001
+const testChatId = 1
+
+func putTestNotification(t *testing.T, messageId *int64, notificationType string, byUserId int64, messageSubId *string) int64 {
+	id, _, err := testDb.PutNotification(context.Background(), messageId, testUserId, testChatId, notificationType, "description", byUserId, fmt.Sprintf("user%v", byUserId), "chat", messageSubId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestGetNotificationGroupsByCursor(t *testing.T) {
+	ctx := context.Background()
+	if err := testDb.ClearAllNotifications(ctx, testUserId); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		testDb.ClearAllNotifications(ctx, testUserId)
+	})
+
+	firstMissedCall := putTestNotification(t, nil, "missed_call", 2, nil)
+	putTestNotification(t, ptr(int64(10)), "reaction", 2, ptr("👍"))
+	reminder := putTestNotification(t, nil, "meeting_reminder", 2, nil)
+	putTestNotification(t, ptr(int64(10)), "reaction", 3, ptr("👍"))
+	mention := putTestNotification(t, ptr(int64(10)), "mention", 3, nil)
+	lastReaction := putTestNotification(t, ptr(int64(10)), "reaction", 3, ptr("🔥"))
+	secondMissedCall := putTestNotification(t, nil, "missed_call", 3, nil)
+	otherMessageReaction := putTestNotification(t, ptr(int64(11)), "reaction", 2, ptr("👍"))
+
+	type group struct {
+		id, count, byUsersCount int64
+	}
+	toGroups := func(notifications []dto.NotificationDto) []group {
+		res := []group{}
+		for _, n := range notifications {
+			res = append(res, group{n.Id, n.GroupCount, n.GroupByUsersCount})
+		}
+		return res
+	}
+
+	// the missed calls and the reminder have no message, but they aren't merged
+	expected := []group{
+		{otherMessageReaction, 1, 1},
+		{secondMissedCall, 1, 1},
+		{lastReaction, 3, 2},
+		{mention, 1, 1},
+		{reminder, 1, 1},
+		{firstMissedCall, 1, 1},
+	}
+
+	all, err := testDb.GetNotificationGroupsByCursor(ctx, testUserId, &dto.NotificationFilter{}, nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := toGroups(all); fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	// the pages don't overlap
+	firstPage, err := testDb.GetNotificationGroupsByCursor(ctx, testUserId, &dto.NotificationFilter{}, nil, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondPage, err := testDb.GetNotificationGroupsByCursor(ctx, testUserId, &dto.NotificationFilter{}, &firstPage[len(firstPage)-1].Id, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := append(toGroups(firstPage), toGroups(secondPage)...); fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	missedCalls, err := testDb.GetNotificationGroupsByCursor(ctx, testUserId, &dto.NotificationFilter{Types: []string{"missed_call"}}, nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := toGroups(missedCalls); fmt.Sprint(actual) != fmt.Sprint([]group{{secondMissedCall, 1, 1}, {firstMissedCall, 1, 1}}) {
+		t.Errorf("unexpected missed calls %v", actual)
+	}
+
+	messageReactions, err := testDb.GetNotificationGroupsByCursor(ctx, testUserId, &dto.NotificationFilter{Types: []string{"reaction"}, MessageId: ptr(int64(10))}, nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := toGroups(messageReactions); fmt.Sprint(actual) != fmt.Sprint([]group{{lastReaction, 3, 2}}) {
+		t.Errorf("unexpected reactions %v", actual)
+	}
+}
+
+func TestGetNotificationsByCursorAndDeleteByFilter(t *testing.T) {
+	ctx := context.Background()
+	if err := testDb.ClearAllNotifications(ctx, testUserId); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		testDb.ClearAllNotifications(ctx, testUserId)
+	})
+
+	ids := []int64{}
+	for i := int64(0); i < 5; i++ {
+		ids = append(ids, putTestNotification(t, ptr(100+i), "reaction", 2, ptr("👍")))
+	}
+	mention := putTestNotification(t, ptr(int64(100)), "mention", 2, nil)
+
+	page, err := testDb.GetNotificationsByCursor(ctx, testUserId, &dto.NotificationFilter{Types: []string{"reaction"}}, &ids[3], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].Id != ids[2] || page[1].Id != ids[1] {
+		t.Errorf("unexpected page %v", page)
+	}
+
+	deleted, err := testDb.DeleteNotificationsByFilter(ctx, testUserId, &dto.NotificationFilter{Types: []string{"reaction"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 5 {
+		t.Errorf("expected 5 deleted, got %v", len(deleted))
+	}
+	rest, err := testDb.GetNotificationsByCursor(ctx, testUserId, &dto.NotificationFilter{}, nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 1 || rest[0].Id != mention {
+		t.Errorf("only the mention should stay, got %v", rest)
+	}
+}
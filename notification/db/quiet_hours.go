@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/rotisserie/eris"
+	"nkonev.name/notification/dto"
+	"time"
+)
+
+func (db *DB) GetNotificationQuietHours(ctx context.Context, userId int64) (*dto.NotificationQuietHoursSettings, error) {
+	row := db.QueryRowContext(ctx, `select enabled, time_zone, windows from notification_quiet_hours where user_id = $1`, userId)
+	if row.Err() != nil {
+		return nil, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var result = new(dto.NotificationQuietHoursSettings)
+	var windows []byte
+	err := row.Scan(&result.Enabled, &result.TimeZone, &windows)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// if there is no rows then return default
+			return &dto.NotificationQuietHoursSettings{ // should match to defaults
+				Enabled:  false,
+				TimeZone: "UTC",
+				Windows:  []dto.QuietHoursWindow{},
+			}, nil
+		}
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	if err := json.Unmarshal(windows, &result.Windows); err != nil {
+		return nil, eris.Wrap(err, "error during unmarshalling quiet hours windows")
+	}
+	return result, nil
+}
+
+func (db *DB) PutNotificationQuietHours(ctx context.Context, userId int64, to *dto.NotificationQuietHoursSettings) error {
+	windows, err := json.Marshal(to.Windows)
+	if err != nil {
+		return eris.Wrap(err, "error during marshalling quiet hours windows")
+	}
+	if _, err := db.ExecContext(ctx, `insert into notification_quiet_hours(user_id, enabled, time_zone, windows) values($1, $2, $3, $4)
+		on conflict(user_id) do update set enabled = excluded.enabled, time_zone = excluded.time_zone, windows = excluded.windows`, userId, to.Enabled, to.TimeZone, windows); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+func (db *DB) DeleteNotificationQuietHours(ctx context.Context, userId int64) error {
+	if _, err := db.ExecContext(ctx, `delete from notification_quiet_hours where user_id = $1`, userId); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// remembers the beginning of the holding, the subsequent calls keep the first time.
+// the row is created for the users who have do not disturb without the quiet hours
+func (db *DB) HoldNotificationQuietHours(ctx context.Context, userId int64) error {
+	if _, err := db.ExecContext(ctx, `insert into notification_quiet_hours(user_id, held_since) values($1, utc_now())
+		on conflict(user_id) do update set held_since = coalesce(notification_quiet_hours.held_since, excluded.held_since)`, userId); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// returns the users with the held notifications, ordered by user_id in order to page by it
+func (db *DB) GetQuietHoursHeldUsers(ctx context.Context, afterUserId int64, limit int) ([]dto.QuietHoursHeldUser, error) {
+	rows, err := db.QueryContext(ctx, `select user_id, held_since, enabled, time_zone, windows
+		from notification_quiet_hours
+		where held_since is not null and user_id > $1
+		order by user_id
+		limit $2`,
+		afterUserId, limit)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+
+	list := make([]dto.QuietHoursHeldUser, 0)
+	for rows.Next() {
+		held := dto.QuietHoursHeldUser{}
+		var windows []byte
+		if err := rows.Scan(&held.UserId, &held.HeldSince, &held.Settings.Enabled, &held.Settings.TimeZone, &windows); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		}
+		if err := json.Unmarshal(windows, &held.Settings.Windows); err != nil {
+			return nil, eris.Wrap(err, "error during unmarshalling quiet hours windows")
+		}
+		list = append(list, held)
+	}
+	return list, nil
+}
+
+// clears the holding only if nobody changed it since the reading, so the several instances don't send the same summary
+// returns false in case the other instance took this user
+func (db *DB) ReleaseNotificationQuietHours(ctx context.Context, userId int64, heldSince time.Time) (bool, error) {
+	res, err := db.ExecContext(ctx, `update notification_quiet_hours set held_since = null where user_id = $1 and held_since = $2`, userId, heldSince)
+	if err != nil {
+		return false, eris.Wrap(err, "error during interacting with db")
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, eris.Wrap(err, "error during interacting with db")
+	}
+	return affected > 0, nil
+}
+
+// returns the count of the notifications which are still unread since the given time
+func (db *DB) GetNotificationCountSince(ctx context.Context, userId int64, since time.Time) (int64, error) {
+	row := db.QueryRowContext(ctx, `select count(*) from notification where user_id = $1 and create_date_time >= $2`, userId, since)
+	if row.Err() != nil {
+		return 0, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var count int64
+	err := row.Scan(&count)
+	if err != nil {
+		return 0, eris.Wrap(err, "error during interacting with db")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"github.com/rotisserie/eris"
+	"nkonev.name/notification/dto"
+)
+
+// the browser can be re-used by the other user after logout, so the owner of the endpoint is overwritten
+func (db *DB) PutWebPushSubscription(ctx context.Context, userId int64, subscription *dto.WebPushSubscription, userAgent string) error {
+	if _, err := db.ExecContext(ctx,
+		`insert into web_push_subscription(user_id, endpoint, p256dh, auth, user_agent) values($1, $2, $3, $4, $5)
+			on conflict(endpoint) do update set user_id = excluded.user_id, p256dh = excluded.p256dh, auth = excluded.auth, user_agent = excluded.user_agent`,
+		userId, subscription.Endpoint, subscription.Keys.P256dh, subscription.Keys.Auth, userAgent); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+func (db *DB) DeleteWebPushSubscription(ctx context.Context, userId int64, endpoint string) error {
+	if _, err := db.ExecContext(ctx, `delete from web_push_subscription where user_id = $1 and endpoint = $2`, userId, endpoint); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// for the subscriptions which were expired on the push service
+func (db *DB) DeleteWebPushSubscriptionByEndpoint(ctx context.Context, endpoint string) error {
+	if _, err := db.ExecContext(ctx, `delete from web_push_subscription where endpoint = $1`, endpoint); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+func (db *DB) GetWebPushSubscriptions(ctx context.Context, userId int64) ([]dto.WebPushSubscription, error) {
+	rows, err := db.QueryContext(ctx, `select endpoint, p256dh, auth from web_push_subscription where user_id = $1 order by id`, userId)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+
+	list := make([]dto.WebPushSubscription, 0)
+	for rows.Next() {
+		subscription := dto.WebPushSubscription{}
+		if err := rows.Scan(&subscription.Endpoint, &subscription.Keys.P256dh, &subscription.Keys.Auth); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		} else {
+			list = append(list, subscription)
+		}
+	}
+	return list, nil
+}
+
+// the oldest subscriptions are removed when the user has too many devices
+func (db *DB) DeleteExcessWebPushSubscriptions(ctx context.Context, userId int64, maxSubscriptions int) error {
+	if _, err := db.ExecContext(ctx,
+		`delete from web_push_subscription where user_id = $1 and id not in (select id from web_push_subscription where user_id = $1 order by id desc limit $2)`,
+		userId, maxSubscriptions); err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package dto
+
+const EventTypeUserAccountCreated = "user_account_created"
+const EventTypeUserAccountChanged = "user_account_changed"
+const EventTypeUserAccountDeleted = "user_account_deleted"
+
+// matches UserAccountEventDTO.java, only the needed fields
+type UserAccountEventUser struct {
+	Id    int64   `json:"id"`
+	Login string  `json:"login"`
+	Email *string `json:"email"`
+}
+
+// the common part of the created, changed and deleted events of aaa, the deleted one has no user
+type UserAccountEvent struct {
+	UserId    int64                 `json:"userId"`
+	EventType string                `json:"eventType"`
+	User      *UserAccountEventUser `json:"user"`
+}
+
+// matches UserEmailInternalDTO.java
+type UserEmail struct {
+	Id    int64   `json:"id"`
+	Login string  `json:"login"`
+	Email *string `json:"email"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+)
+
+const DigestFrequencyNone = "none"
+const DigestFrequencyDaily = "daily"
+const DigestFrequencyWeekly = "weekly"
+
+func IsValidDigestFrequency(frequency string) bool {
+	switch frequency {
+	case DigestFrequencyNone, DigestFrequencyDaily, DigestFrequencyWeekly:
+		return true
+	default:
+		return false
+	}
+}
+
+type NotificationDigestSettings struct {
+	Frequency string `json:"frequency"`
+	HasEmail  bool   `json:"hasEmail"` // the email is taken from aaa, the digest isn't sent without it
+}
+
+// the user whose digest is due
+type DigestRecipient struct {
+	UserId           int64
+	Email            string
+	Login            string
+	Frequency        string
+	UnsubscribeToken string
+	LastSentDateTime *time.Time
+}
@@ -15,6 +15,9 @@ type NotificationDto struct {
 	ByLogin          string    `json:"byLogin"`
 	ByAvatar         *string   `json:"byAvatar"`
 	ChatTitle        string    `json:"chatTitle"`
+	// they are filled only in the grouped listing, the notification is the latest one of the group
+	GroupCount        int64    `json:"groupCount,omitempty"`
+	GroupByUsersCount int64    `json:"groupByUsersCount,omitempty"`
 }
 
 type WrapperNotificationDto struct {
@@ -23,17 +26,20 @@ type WrapperNotificationDto struct {
 }
 
 type NotificationGlobalSettings struct {
-	MentionsEnabled    bool `json:"mentionsEnabled"`
-	MissedCallsEnabled bool `json:"missedCallsEnabled"`
-	AnswersEnabled     bool `json:"answersEnabled"`
-	ReactionsEnabled   bool `json:"reactionsEnabled"`
+	MentionsEnabled        bool `json:"mentionsEnabled"`
+	MissedCallsEnabled     bool `json:"missedCallsEnabled"`
+	AnswersEnabled         bool `json:"answersEnabled"`
+	ReactionsEnabled       bool `json:"reactionsEnabled"`
+	CallInvitationsEnabled bool `json:"callInvitationsEnabled"`
 }
 
 type NotificationPerChatSettings struct {
-	MentionsEnabled    *bool `json:"mentionsEnabled"`
-	MissedCallsEnabled *bool `json:"missedCallsEnabled"`
-	AnswersEnabled     *bool `json:"answersEnabled"`
-	ReactionsEnabled   *bool `json:"reactionsEnabled"`
+	MentionsEnabled        *bool `json:"mentionsEnabled"`
+	MissedCallsEnabled     *bool `json:"missedCallsEnabled"`
+	AnswersEnabled         *bool `json:"answersEnabled"`
+	ReactionsEnabled       *bool `json:"reactionsEnabled"`
+	BypassQuietHours       *bool `json:"bypassQuietHours"`
+	CallInvitationsEnabled *bool `json:"callInvitationsEnabled"`
 }
 
 func NewNotificationDeleteDto(id int64, notificationType string) *NotificationDto {
@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"slices"
+)
+
+var notificationTypes = []string{"mention", "missed_call", "meeting_reminder", "reply", "reaction", "keyword"}
+
+func IsValidNotificationType(notificationType string) bool {
+	return slices.Contains(notificationTypes, notificationType)
+}
+
+// the empty filter matches all the notifications of the user
+type NotificationFilter struct {
+	Types     []string
+	ChatId    *int64
+	MessageId *int64
+}
+
+func (f *NotificationFilter) IsEmpty() bool {
+	return len(f.Types) == 0 && f.ChatId == nil && f.MessageId == nil
+}
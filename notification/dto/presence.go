@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+const PresenceDoNotDisturb = "dnd"
+
+// is stored in the event service
+type UserPresence struct {
+	UserId    int64      `json:"userId"`
+	Status    *string    `json:"status"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	Idle      bool       `json:"idle"`
+}
+
+// is sent by the event service on every change
+type UserPresencesChanged struct {
+	UserPresences []UserPresence `json:"userPresences"`
+}
+
+func (p *UserPresence) IsDoNotDisturb() bool {
+	return p.Status != nil && *p.Status == PresenceDoNotDisturb
+}
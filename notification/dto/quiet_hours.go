@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const MaxQuietHoursWindows = 14
+
+// the window starts on the given weekday in the user's time zone, it lasts till the next day in case end isn't after start
+type QuietHoursWindow struct {
+	Weekday time.Weekday `json:"weekday"` // 0 is sunday
+	Start   string       `json:"start"`   // 22:00
+	End     string       `json:"end"`     // 07:00
+}
+
+type NotificationQuietHoursSettings struct {
+	Enabled  bool               `json:"enabled"`
+	TimeZone string             `json:"timeZone"` // IANA, e.g. Europe/Berlin
+	Windows  []QuietHoursWindow `json:"windows"`
+}
+
+type QuietHoursHeldUser struct {
+	UserId    int64
+	HeldSince time.Time
+	Settings  NotificationQuietHoursSettings
+}
+
+// returns the minutes since the midnight
+func ParseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("wrong time %q, expected HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (s *NotificationQuietHoursSettings) Validate() error {
+	if _, err := time.LoadLocation(s.TimeZone); err != nil {
+		return fmt.Errorf("unknown time zone %q", s.TimeZone)
+	}
+	if len(s.Windows) > MaxQuietHoursWindows {
+		return fmt.Errorf("max allowed windows %d, got %d", MaxQuietHoursWindows, len(s.Windows))
+	}
+	for _, w := range s.Windows {
+		if w.Weekday < time.Sunday || w.Weekday > time.Saturday {
+			return fmt.Errorf("wrong weekday %d, expected 0..6", w.Weekday)
+		}
+		start, err := ParseClock(w.Start)
+		if err != nil {
+			return err
+		}
+		end, err := ParseClock(w.End)
+		if err != nil {
+			return err
+		}
+		if start == end {
+			return errors.New("the window should have the different start and end")
+		}
+	}
+	return nil
+}
@@ -1,14 +1,32 @@
 package dto
 
+import "time"
+
 type MentionNotification struct {
 	Id   int64  `json:"id"`
 	Text string `json:"text"`
 }
 
+type KeywordNotification struct {
+	Id      int64  `json:"id"` // message id
+	Text    string `json:"text"`
+	Keyword string `json:"keyword"`
+}
+
 type MissedCallNotification struct {
 	Description string `json:"description"`
 }
 
+type CallInvitationNotification struct {
+	Description string `json:"description"`
+}
+
+type MeetingReminderNotification struct {
+	MeetingId   int64     `json:"meetingId"`
+	Description string    `json:"description"`
+	StartTime   time.Time `json:"startTime"`
+}
+
 type ReplyDto struct {
 	MessageId        int64  `json:"messageId"`
 	ChatId           int64  `json:"chatId"`
@@ -34,6 +52,9 @@ type NotificationEvent struct {
 	ByAvatar               *string                 `json:"byAvatar"`
 	ChatTitle              string                  `json:"chatTitle"`
 	ReactionEvent          *ReactionEvent		   `json:"reactionEvent"`
+	MeetingReminderNotification *MeetingReminderNotification `json:"meetingReminderNotification"`
+	CallInvitationNotification  *CallInvitationNotification  `json:"callInvitationNotification"`
+	KeywordNotification         *KeywordNotification         `json:"keywordNotification"`
 }
 
 type GlobalUserEvent struct {
@@ -0,0 +1,24 @@
+package dto
+
+// matches PushSubscription.toJSON() of the browser
+type WebPushSubscription struct {
+	Endpoint string                  `json:"endpoint"`
+	Keys     WebPushSubscriptionKeys `json:"keys"`
+}
+
+type WebPushSubscriptionKeys struct {
+	P256dh string `json:"p256dh"` // the public key of the browser, base64url
+	Auth   string `json:"auth"`   // the authentication secret, base64url
+}
+
+// is shown by the service worker
+type WebPushPayload struct {
+	NotificationType string  `json:"notificationType"`
+	Title            string  `json:"title"`
+	Body             string  `json:"body"`
+	ChatId           int64   `json:"chatId"`
+	MessageId        *int64  `json:"messageId"`
+	Url              string  `json:"url"`
+	Tag              string  `json:"tag"` // the notification with the same tag replaces the previous one
+	Icon             *string `json:"icon"`
+}
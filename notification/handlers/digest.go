@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"github.com/labstack/echo/v4"
+	htmlTemplate "html/template"
+	"net/http"
+	"nkonev.name/notification/auth"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/utils"
+)
+
+//go:embed digest_templates
+var digestTemplates embed.FS
+
+var unsubscribeDigestTemplate = htmlTemplate.Must(htmlTemplate.ParseFS(digestTemplates, "digest_templates/unsubscribe.html"))
+
+type DigestFrequencyDto struct {
+	Frequency string `json:"frequency"`
+}
+
+func (mc *NotificationHandler) GetDigestNotificationSettings(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	notSett, err := mc.db.GetNotificationDigestSettings(c.Request().Context(), userPrincipalDto.UserId)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting digest settings %v", err)
+		return err
+	}
+
+	return c.JSON(http.StatusOK, notSett)
+}
+
+func (mc *NotificationHandler) PutDigestNotificationSettings(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	var bindTo = new(DigestFrequencyDto)
+	err := c.Bind(bindTo)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during reading digest settings %v", err)
+		return err
+	}
+	if !dto.IsValidDigestFrequency(bindTo.Frequency) {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": "Wrong frequency"})
+	}
+
+	err = mc.db.PutNotificationDigestFrequency(c.Request().Context(), userPrincipalDto.UserId, bindTo.Frequency)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during writing digest settings %v", err)
+		return err
+	}
+
+	notSett, err := mc.db.GetNotificationDigestSettings(c.Request().Context(), userPrincipalDto.UserId)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting digest settings %v", err)
+		return err
+	}
+
+	// the user was created before the digest, so there was no aaa event with the email
+	if notSett.Frequency != dto.DigestFrequencyNone && !notSett.HasEmail {
+		err = mc.digestService.LookupEmails(c.Request().Context(), []int64{userPrincipalDto.UserId})
+		if err != nil {
+			// the scheduler will try again
+			mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during looking up email %v", err)
+		} else {
+			notSett, err = mc.db.GetNotificationDigestSettings(c.Request().Context(), userPrincipalDto.UserId)
+			if err != nil {
+				mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting digest settings %v", err)
+				return err
+			}
+		}
+	}
+
+	return c.JSON(http.StatusOK, notSett)
+}
+
+// the link from the email, so it's public. GET doesn't change anything because the link can be opened by the mail scanners and the prefetchers,
+// it only asks the user to confirm with the form which is sent by POST
+func (mc *NotificationHandler) ConfirmUnsubscribeDigest(c echo.Context) error {
+	token := c.QueryParam("token")
+	if token == "" {
+		return c.String(http.StatusBadRequest, "The token is required")
+	}
+
+	var page bytes.Buffer
+	err := unsubscribeDigestTemplate.Execute(&page, map[string]string{"Token": token})
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during rendering unsubscribe page %v", err)
+		return err
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.HTMLBlob(http.StatusOK, page.Bytes())
+}
+
+// the confirmation form of the user and the one-click unsubscribe of the mail clients (RFC 8058), the token is in the query of the latter
+func (mc *NotificationHandler) UnsubscribeDigest(c echo.Context) error {
+	token := c.FormValue("token")
+	if token == "" {
+		return c.String(http.StatusBadRequest, "The token is required")
+	}
+
+	found, err := mc.db.UnsubscribeNotificationDigest(c.Request().Context(), token)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during unsubscribing from digest %v", err)
+		return err
+	}
+	if !found {
+		return c.String(http.StatusNotFound, "The unsubscribe link is invalid")
+	}
+
+	return c.String(http.StatusOK, "You have been unsubscribed from the notification digest")
+}
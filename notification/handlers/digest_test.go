@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"nkonev.name/notification/logger"
+)
+
+// there is no db, so the page can't change the settings
+func TestConfirmUnsubscribeDigest(t *testing.T) {
+	h := &NotificationHandler{lgr: logger.NewLogger()}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/notification/public/digest/unsubscribe?token=a%22b", nil)
+	rec := httptest.NewRecorder()
+	if err := h.ConfirmUnsubscribeDigest(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := rec.Result()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %v", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), `<form method="post">`) {
+		t.Errorf("the page should contain the form which is sent by POST, got %s", body)
+	}
+	if !strings.Contains(string(body), `value="a&#34;b"`) {
+		t.Errorf("the token should be escaped, got %s", body)
+	}
+	if cacheControl := resp.Header.Get("Cache-Control"); cacheControl != "no-store" {
+		t.Errorf("the page shouldn't be cached, got %v", cacheControl)
+	}
+}
+
+func TestConfirmUnsubscribeDigestRequiresToken(t *testing.T) {
+	h := &NotificationHandler{lgr: logger.NewLogger()}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/notification/public/digest/unsubscribe", nil)
+	rec := httptest.NewRecorder()
+	if err := h.ConfirmUnsubscribeDigest(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unexpected status %v", rec.Code)
+	}
+}
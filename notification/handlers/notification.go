@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"github.com/labstack/echo/v4"
 	"net/http"
 	"nkonev.name/notification/auth"
@@ -11,25 +12,29 @@ import (
 	"nkonev.name/notification/producer"
 	"nkonev.name/notification/services"
 	"nkonev.name/notification/utils"
+	"strings"
 )
 
 type NotificationHandler struct {
 	db                    *db.DB
 	rabbitEventsPublisher *producer.RabbitEventPublisher
+	digestService         *services.DigestService
 	lgr                   *logger.Logger
 }
 
-func NewMessageHandler(dbR *db.DB, rabbitEventsPublisher *producer.RabbitEventPublisher, lgr *logger.Logger) *NotificationHandler {
+func NewMessageHandler(dbR *db.DB, rabbitEventsPublisher *producer.RabbitEventPublisher, digestService *services.DigestService, lgr *logger.Logger) *NotificationHandler {
 	return &NotificationHandler{
 		db:                    dbR,
 		rabbitEventsPublisher: rabbitEventsPublisher,
+		digestService:         digestService,
 		lgr:                   lgr,
 	}
 }
 
 type NotificationsWrapper struct {
-	Data  []dto.NotificationDto `json:"items"`
-	Count int64                 `json:"count"` // total notification number for this user
+	Data       []dto.NotificationDto `json:"items"`
+	Count      int64                 `json:"count"`                // total notification number for this user
+	NextCursor *int64                `json:"nextCursor,omitempty"` // absent on the last page and in the page mode
 }
 
 type NotificationsCount struct {
@@ -43,8 +48,14 @@ func (mc *NotificationHandler) GetNotifications(c echo.Context) error {
 		return errors.New("Error during getting auth context")
 	}
 
-	page := utils.FixPageString(c.QueryParam("page"))
 	size := utils.FixSizeString(c.QueryParam("size"))
+
+	// the page mode is left for the old clients
+	if c.QueryParam("page") == "" {
+		return mc.getNotificationsByCursor(c, userPrincipalDto.UserId, size)
+	}
+
+	page := utils.FixPageString(c.QueryParam("page"))
 	offset := utils.GetOffset(page, size)
 
 	if notifications, err := mc.db.GetNotifications(c.Request().Context(), userPrincipalDto.UserId, size, offset); err != nil {
@@ -61,6 +72,73 @@ func (mc *NotificationHandler) GetNotifications(c echo.Context) error {
 	}
 }
 
+func (mc *NotificationHandler) getNotificationsByCursor(c echo.Context, userId int64, size int) error {
+	filter, err := getNotificationFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": err.Error()})
+	}
+
+	var cursor *int64
+	if c.QueryParam("cursor") != "" {
+		cursorValue, err := GetQueryParamAsInt64(c, "cursor")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, &utils.H{"message": "Wrong cursor"})
+		}
+		cursor = &cursorValue
+	}
+
+	var notifications []dto.NotificationDto
+	if utils.GetBoolean(c.QueryParam("grouped")) {
+		notifications, err = mc.db.GetNotificationGroupsByCursor(c.Request().Context(), userId, filter, cursor, size)
+	} else {
+		notifications, err = mc.db.GetNotificationsByCursor(c.Request().Context(), userId, filter, cursor, size)
+	}
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error get notification from db %v", err)
+		return err
+	}
+
+	notificationsCount, err := mc.db.GetNotificationCount(c.Request().Context(), userId)
+	if err != nil {
+		return errors.New("Error during getting user notification count")
+	}
+
+	var nextCursor *int64
+	if len(notifications) == size {
+		nextCursor = &notifications[len(notifications)-1].Id
+	}
+
+	return c.JSON(http.StatusOK, NotificationsWrapper{Data: notifications, Count: notificationsCount, NextCursor: nextCursor})
+}
+
+// type can be repeated or comma-separated
+func getNotificationFilter(c echo.Context) (*dto.NotificationFilter, error) {
+	filter := dto.NotificationFilter{}
+	for _, value := range c.Request().URL.Query()["type"] {
+		for _, notificationType := range strings.Split(value, ",") {
+			if !dto.IsValidNotificationType(notificationType) {
+				return nil, fmt.Errorf("Wrong notification type %q", notificationType)
+			}
+			filter.Types = append(filter.Types, notificationType)
+		}
+	}
+	if c.QueryParam("chatId") != "" {
+		chatId, err := GetQueryParamAsInt64(c, "chatId")
+		if err != nil {
+			return nil, errors.New("Wrong chatId")
+		}
+		filter.ChatId = &chatId
+	}
+	if c.QueryParam("messageId") != "" {
+		messageId, err := GetQueryParamAsInt64(c, "messageId")
+		if err != nil {
+			return nil, errors.New("Wrong messageId")
+		}
+		filter.MessageId = &messageId
+	}
+	return &filter, nil
+}
+
 func (mc *NotificationHandler) GetNotificationsCount(c echo.Context) error {
 	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
 	if !ok {
@@ -83,7 +161,19 @@ func (mc *NotificationHandler) DeleteAllNotifications(c echo.Context) error {
 		return errors.New("Error during getting auth context")
 	}
 
-	err := mc.db.ClearAllNotifications(c.Request().Context(), userPrincipalDto.UserId)
+	filter, err := getNotificationFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": err.Error()})
+	}
+	if !filter.IsEmpty() {
+		err = mc.deleteNotificationsByFilter(c, userPrincipalDto.UserId, filter)
+		if err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusOK)
+	}
+
+	err = mc.db.ClearAllNotifications(c.Request().Context(), userPrincipalDto.UserId)
 	if err != nil {
 		return errors.New("Error during getting user chat count")
 	}
@@ -127,6 +217,55 @@ func (mc *NotificationHandler) ReadNotification(c echo.Context) error {
 	return c.NoContent(http.StatusAccepted)
 }
 
+// reads the whole group, e.g. all the reactions on the message, the reading deletes the notifications
+func (mc *NotificationHandler) ReadNotifications(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	filter, err := getNotificationFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": err.Error()})
+	}
+	if filter.IsEmpty() {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": "At least one of type, chatId or messageId is required"})
+	}
+
+	err = mc.deleteNotificationsByFilter(c, userPrincipalDto.UserId, filter)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (mc *NotificationHandler) deleteNotificationsByFilter(c echo.Context, userId int64, filter *dto.NotificationFilter) error {
+	deleted, err := mc.db.DeleteNotificationsByFilter(c.Request().Context(), userId, filter)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Unable to delete notifications %v", err)
+		return err
+	}
+	if len(deleted) == 0 {
+		return nil
+	}
+
+	count, err := mc.db.GetNotificationCount(c.Request().Context(), userId)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Unable to count notification %v", err)
+		return err
+	}
+
+	for _, notification := range deleted {
+		err = mc.rabbitEventsPublisher.Publish(c.Request().Context(), userId, dto.NewWrapperNotificationDeleteDto(notification.Id, count, notification.NotificationType), services.NotificationDelete)
+		if err != nil {
+			mc.lgr.WithTracing(c.Request().Context()).Errorf("Unable to send notification delete %v", err)
+		}
+	}
+	return nil
+}
+
 func (mc *NotificationHandler) GetGlobalNotificationSettings(c echo.Context) error {
 	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
 	if !ok {
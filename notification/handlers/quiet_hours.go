@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"nkonev.name/notification/auth"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/utils"
+)
+
+func (mc *NotificationHandler) GetQuietHoursNotificationSettings(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	notSett, err := mc.db.GetNotificationQuietHours(c.Request().Context(), userPrincipalDto.UserId)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting quiet hours settings %v", err)
+		return err
+	}
+
+	return c.JSON(http.StatusOK, notSett)
+}
+
+func (mc *NotificationHandler) PutQuietHoursNotificationSettings(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	var bindTo = new(dto.NotificationQuietHoursSettings)
+	err := c.Bind(bindTo)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during reading quiet hours settings %v", err)
+		return err
+	}
+	if bindTo.TimeZone == "" {
+		bindTo.TimeZone = "UTC"
+	}
+	if bindTo.Windows == nil {
+		bindTo.Windows = []dto.QuietHoursWindow{}
+	}
+	if err := bindTo.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": err.Error()})
+	}
+
+	err = mc.db.PutNotificationQuietHours(c.Request().Context(), userPrincipalDto.UserId, bindTo)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during writing quiet hours settings %v", err)
+		return err
+	}
+
+	notSett, err := mc.db.GetNotificationQuietHours(c.Request().Context(), userPrincipalDto.UserId)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting quiet hours settings %v", err)
+		return err
+	}
+
+	return c.JSON(http.StatusOK, notSett)
+}
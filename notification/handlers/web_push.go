@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+	"net/http"
+	"nkonev.name/notification/auth"
+	"nkonev.name/notification/db"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/logger"
+	"nkonev.name/notification/services"
+	"nkonev.name/notification/utils"
+)
+
+type WebPushHandler struct {
+	db             *db.DB
+	webPushService *services.WebPushService
+	lgr            *logger.Logger
+}
+
+func NewWebPushHandler(dbR *db.DB, webPushService *services.WebPushService, lgr *logger.Logger) *WebPushHandler {
+	return &WebPushHandler{
+		db:             dbR,
+		webPushService: webPushService,
+		lgr:            lgr,
+	}
+}
+
+type WebPushConfigDto struct {
+	Enabled        bool   `json:"enabled"`
+	VapidPublicKey string `json:"vapidPublicKey"`
+}
+
+type WebPushUnsubscribeDto struct {
+	Endpoint string `json:"endpoint"`
+}
+
+func (mc *WebPushHandler) GetConfig(c echo.Context) error {
+	return c.JSON(http.StatusOK, WebPushConfigDto{
+		Enabled:        mc.webPushService.Enabled(),
+		VapidPublicKey: mc.webPushService.VapidPublicKey(),
+	})
+}
+
+func (mc *WebPushHandler) PutSubscription(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	var bindTo = new(dto.WebPushSubscription)
+	err := c.Bind(bindTo)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during reading web push subscription %v", err)
+		return err
+	}
+	if services.ValidateWebPushEndpoint(bindTo.Endpoint) != nil || bindTo.Keys.P256dh == "" || bindTo.Keys.Auth == "" {
+		mc.lgr.WithTracing(c.Request().Context()).Infof("Rejected web push subscription of user %v", userPrincipalDto.UserId)
+		return c.JSON(http.StatusBadRequest, &utils.H{"message": "Wrong subscription"})
+	}
+
+	err = mc.db.PutWebPushSubscription(c.Request().Context(), userPrincipalDto.UserId, bindTo, c.Request().UserAgent())
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during writing web push subscription %v", err)
+		return err
+	}
+
+	err = mc.db.DeleteExcessWebPushSubscriptions(c.Request().Context(), userPrincipalDto.UserId, viper.GetInt("webPush.maxSubscriptionsPerUser"))
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during removing excess web push subscriptions %v", err)
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// on logout or when the user has disabled the notifications in the browser
+func (mc *WebPushHandler) DeleteSubscription(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	var bindTo = new(WebPushUnsubscribeDto)
+	err := c.Bind(bindTo)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during reading web push subscription %v", err)
+		return err
+	}
+
+	err = mc.db.DeleteWebPushSubscription(c.Request().Context(), userPrincipalDto.UserId, bindTo.Endpoint)
+	if err != nil {
+		mc.lgr.WithTracing(c.Request().Context()).Errorf("Error during removing web push subscription %v", err)
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,56 @@
+package listener
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+	"nkonev.name/notification/db"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/logger"
+	"nkonev.name/notification/rabbitmq"
+)
+
+type AaaEventsListener func(*amqp.Delivery) error
+
+// keeps the emails of the users for the digest, the ones who were created before it are looked up by DigestService
+func CreateAaaEventsListener(dbs *db.DB, lgr *logger.Logger) AaaEventsListener {
+	tr := otel.Tracer("amqp/listener")
+
+	return func(msg *amqp.Delivery) error {
+		ctx := rabbitmq.ExtractAMQPHeaders(context.Background(), msg.Headers)
+		ctx, span := tr.Start(ctx, "aaa.listener")
+		defer span.End()
+
+		lgr.WithTracing(ctx).Debugf("Received aaa event with type %v", msg.Type)
+
+		var bindTo = new(dto.UserAccountEvent)
+		err := json.Unmarshal(msg.Body, bindTo)
+		if err != nil {
+			lgr.WithTracing(ctx).Errorf("Unable to unmarshall aaa event %v", err)
+			return err
+		}
+
+		switch bindTo.EventType {
+		case dto.EventTypeUserAccountCreated, dto.EventTypeUserAccountChanged:
+			if bindTo.User == nil {
+				return nil
+			}
+			email := bindTo.User.Email
+			if email != nil && *email == "" {
+				email = nil
+			}
+			return dbs.PutNotificationDigestEmail(ctx, bindTo.User.Id, bindTo.User.Login, email)
+		case dto.EventTypeUserAccountDeleted:
+			if err := dbs.DeleteNotificationQuietHours(ctx, bindTo.UserId); err != nil {
+				return err
+			}
+			if err := dbs.DeleteNotificationDoNotDisturb(ctx, bindTo.UserId); err != nil {
+				return err
+			}
+			return dbs.DeleteNotificationDigestSettings(ctx, bindTo.UserId)
+		}
+
+		return nil
+	}
+}
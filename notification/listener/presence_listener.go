@@ -0,0 +1,46 @@
+package listener
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+	"nkonev.name/notification/db"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/logger"
+	"nkonev.name/notification/rabbitmq"
+)
+
+type PresenceEventsListener func(*amqp.Delivery) error
+
+// keeps the do not disturb of the users, so the delivery doesn't ask the event service about every notification
+func CreatePresenceEventsListener(dbs *db.DB, lgr *logger.Logger) PresenceEventsListener {
+	tr := otel.Tracer("amqp/listener")
+
+	return func(msg *amqp.Delivery) error {
+		ctx := rabbitmq.ExtractAMQPHeaders(context.Background(), msg.Headers)
+		ctx, span := tr.Start(ctx, "presence.listener")
+		defer span.End()
+
+		lgr.WithTracing(ctx).Debugf("Received presence event with type %v", msg.Type)
+
+		var bindTo = new(dto.UserPresencesChanged)
+		err := json.Unmarshal(msg.Body, bindTo)
+		if err != nil {
+			lgr.WithTracing(ctx).Errorf("Unable to unmarshall presence event %v", err)
+			return err
+		}
+
+		for _, p := range bindTo.UserPresences {
+			if p.IsDoNotDisturb() {
+				err = dbs.PutNotificationDoNotDisturb(ctx, p.UserId, p.ExpiresAt)
+			} else {
+				err = dbs.DeleteNotificationDoNotDisturb(ctx, p.UserId)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
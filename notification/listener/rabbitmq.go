@@ -10,8 +10,12 @@ import (
 )
 
 const NotificationsExchange = "notifications-exchange"
+const AaaEventsExchange = "aaa-profile-events-exchange"
+const PresenceEventsExchange = "presence-events-exchange"
 
 type FanoutNotificationsChannel struct{ *rabbitmq.Channel }
+type AaaEventsChannel struct{ *rabbitmq.Channel }
+type PresenceEventsChannel struct{ *rabbitmq.Channel }
 
 func create(lgr *logger.Logger, name string, consumeCh *rabbitmq.Channel) *amqp.Queue {
 	var err error
@@ -80,6 +84,68 @@ func CreateNotificationsChannel(lgr *logger.Logger, connection *rabbitmq.Connect
 	)}
 }
 
+func CreateAaaEventsChannel(lgr *logger.Logger, connection *rabbitmq.Connection, onMessage AaaEventsListener, lc fx.Lifecycle) AaaEventsChannel {
+	// not auto-deleted one, the changes of the emails shouldn't be lost during the restart
+	var queueName = "notification-aaa-profile-events"
+
+	return AaaEventsChannel{myRabbit.CreateRabbitMqChannelWithCallback(
+		lgr,
+		connection,
+		func(channel *rabbitmq.Channel) error {
+			lc.Append(fx.Hook{
+				OnStop: func(ctx context.Context) error {
+					lgr.Infof("Stopping queue listening '%v'", queueName)
+					return channel.Close()
+				},
+			})
+
+			err := channel.ExchangeDeclare(AaaEventsExchange, "fanout", true, false, false, false, nil)
+			if err != nil {
+				return err
+			}
+
+			aQueue := create(lgr, queueName, channel)
+			err = channel.QueueBind(aQueue.Name, "", AaaEventsExchange, false, nil)
+			if err != nil {
+				return err
+			}
+			listen(lgr, channel, aQueue, onMessage, lc)
+			return nil
+		},
+	)}
+}
+
+func CreatePresenceEventsChannel(lgr *logger.Logger, connection *rabbitmq.Connection, onMessage PresenceEventsListener, lc fx.Lifecycle) PresenceEventsChannel {
+	// not auto-deleted one, the do not disturb shouldn't be lost during the restart
+	var queueName = "notification-presence-events"
+
+	return PresenceEventsChannel{myRabbit.CreateRabbitMqChannelWithCallback(
+		lgr,
+		connection,
+		func(channel *rabbitmq.Channel) error {
+			lc.Append(fx.Hook{
+				OnStop: func(ctx context.Context) error {
+					lgr.Infof("Stopping queue listening '%v'", queueName)
+					return channel.Close()
+				},
+			})
+
+			err := channel.ExchangeDeclare(PresenceEventsExchange, "fanout", true, false, false, false, nil)
+			if err != nil {
+				return err
+			}
+
+			aQueue := create(lgr, queueName, channel)
+			err = channel.QueueBind(aQueue.Name, "", PresenceEventsExchange, false, nil)
+			if err != nil {
+				return err
+			}
+			listen(lgr, channel, aQueue, onMessage, lc)
+			return nil
+		},
+	)}
+}
+
 func listen(
 	lgr *logger.Logger,
 	channel *rabbitmq.Channel,
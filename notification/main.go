@@ -20,6 +20,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"nkonev.name/notification/app"
+	"nkonev.name/notification/client"
 	"nkonev.name/notification/config"
 	"nkonev.name/notification/db"
 	"nkonev.name/notification/handlers"
@@ -49,17 +50,29 @@ func main() {
 			handlers.ConfigureStaticMiddleware,
 			handlers.ConfigureAuthMiddleware,
 			handlers.NewMessageHandler,
+			handlers.NewWebPushHandler,
 			configureMigrations,
 			db.ConfigureDb,
 			listener.CreateNotificationsListener,
+			listener.CreateAaaEventsListener,
 			rabbitmq.CreateRabbitMqConnection,
 			services.CreateNotificationService,
+			services.NewMailSender,
+			services.NewDigestService,
+			services.NewWebPushService,
+			services.NewQuietHoursService,
 			producer.NewRabbiEventPublisher,
+			listener.CreatePresenceEventsListener,
+			client.NewAaaRestClient,
 		),
 		fx.Invoke(
 			runMigrations,
 			runEcho,
 			listener.CreateNotificationsChannel,
+			listener.CreateAaaEventsChannel,
+			listener.CreatePresenceEventsChannel,
+			services.RunDigestScheduler,
+			services.RunQuietHoursScheduler,
 		),
 	)
 	appFx.Run()
@@ -105,6 +118,7 @@ func configureEcho(
 	staticMiddleware handlers.StaticMiddleware,
 	authMiddleware handlers.AuthMiddleware,
 	ch *handlers.NotificationHandler,
+	wph *handlers.WebPushHandler,
 	lc fx.Lifecycle,
 	tp *sdktrace.TracerProvider,
 ) *echo.Echo {
@@ -177,6 +191,16 @@ func configureEcho(
 	e.GET("/api/notification/settings/:id/chat", ch.GetChatNotificationSettings)
 	e.PUT("/api/notification/settings/:id/chat", ch.PutChatNotificationSettings)
 	e.PUT("/api/notification/read/:notificationId", ch.ReadNotification)
+	e.PUT("/api/notification/read", ch.ReadNotifications)
+	e.GET("/api/notification/settings/digest", ch.GetDigestNotificationSettings)
+	e.PUT("/api/notification/settings/digest", ch.PutDigestNotificationSettings)
+	e.GET("/api/notification/public/digest/unsubscribe", ch.ConfirmUnsubscribeDigest)
+	e.POST("/api/notification/public/digest/unsubscribe", ch.UnsubscribeDigest)
+	e.GET("/api/notification/settings/quiet-hours", ch.GetQuietHoursNotificationSettings)
+	e.PUT("/api/notification/settings/quiet-hours", ch.PutQuietHoursNotificationSettings)
+	e.GET("/api/notification/web-push/config", wph.GetConfig)
+	e.PUT("/api/notification/web-push/subscription", wph.PutSubscription)
+	e.DELETE("/api/notification/web-push/subscription", wph.DeleteSubscription)
 
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmlTemplate "html/template"
+	"net/url"
+	textTemplate "text/template"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/fx"
+	"nkonev.name/notification/client"
+	"nkonev.name/notification/db"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/logger"
+)
+
+//go:embed digest_templates
+var digestTemplates embed.FS
+
+const digestDescriptionMaxLength = 200
+
+const recipientsBatchSize = 100
+
+var digestPeriods = map[string]time.Duration{
+	dto.DigestFrequencyDaily:  24 * time.Hour,
+	dto.DigestFrequencyWeekly: 7 * 24 * time.Hour,
+}
+
+// collects the unread notifications and sends them by email, the users choose the frequency
+type DigestService struct {
+	dbs          *db.DB
+	mailSender   *MailSender
+	quietHours   *QuietHoursService
+	aaaClient    *client.AaaRestClient
+	lgr          *logger.Logger
+	htmlTemplate *htmlTemplate.Template
+	textTemplate *textTemplate.Template
+}
+
+func NewDigestService(dbs *db.DB, mailSender *MailSender, quietHours *QuietHoursService, aaaClient *client.AaaRestClient, lgr *logger.Logger) (*DigestService, error) {
+	htmlTmpl, err := htmlTemplate.ParseFS(digestTemplates, "digest_templates/digest.html")
+	if err != nil {
+		return nil, err
+	}
+	textTmpl, err := textTemplate.ParseFS(digestTemplates, "digest_templates/digest.txt")
+	if err != nil {
+		return nil, err
+	}
+	return &DigestService{
+		dbs:          dbs,
+		mailSender:   mailSender,
+		quietHours:   quietHours,
+		aaaClient:    aaaClient,
+		lgr:          lgr,
+		htmlTemplate: htmlTmpl,
+		textTemplate: textTmpl,
+	}, nil
+}
+
+type digestItem struct {
+	Title          string
+	Description    string
+	Url            string
+	CreateDateTime time.Time
+}
+
+type digestTemplateData struct {
+	Subject        string
+	Login          string
+	Frequency      string
+	Items          []digestItem
+	MoreCount      int64
+	FrontendUrl    string
+	UnsubscribeUrl string
+}
+
+// the emails come with the aaa events, so the users who were created before the digest are looked up in aaa
+func (s *DigestService) LookupEmails(ctx context.Context, userIds []int64) error {
+	users, err := s.aaaClient.GetUserEmails(ctx, userIds)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		email := user.Email
+		if email != nil && *email == "" {
+			email = nil
+		}
+		if err := s.dbs.PutNotificationDigestEmail(ctx, user.Id, user.Login, email); err != nil {
+			return err
+		}
+	}
+	return s.dbs.SetNotificationDigestEmailChecked(ctx, userIds)
+}
+
+func (s *DigestService) backfillEmails(ctx context.Context) {
+	var afterUserId int64 = 0
+	for {
+		userIds, err := s.dbs.GetDigestSubscribersWithoutEmail(ctx, afterUserId, recipientsBatchSize)
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to get digest subscribers without email %v", err)
+			return
+		}
+		if len(userIds) == 0 {
+			return
+		}
+		err = s.LookupEmails(ctx, userIds)
+		if err != nil {
+			// they will be looked up on the next check
+			s.lgr.WithTracing(ctx).Errorf("Unable to look up emails of digest subscribers %v", err)
+			return
+		}
+		if len(userIds) < recipientsBatchSize {
+			return
+		}
+		afterUserId = userIds[len(userIds)-1]
+	}
+}
+
+func (s *DigestService) SendDueDigests(ctx context.Context) {
+	s.backfillEmails(ctx)
+
+	// the check is performed every interval, so the digest can be sent a bit earlier in order not to be shifted by interval every time
+	interval := viper.GetDuration("digest.interval")
+
+	for frequency, period := range digestPeriods {
+		var afterUserId int64 = 0
+		for {
+			recipients, err := s.dbs.GetDueDigestRecipients(ctx, frequency, period-interval, afterUserId, recipientsBatchSize)
+			if err != nil {
+				s.lgr.WithTracing(ctx).Errorf("Unable to get digest recipients %v", err)
+				return
+			}
+			for _, recipient := range recipients {
+				afterUserId = recipient.UserId
+				if s.quietHours.IsQuiet(ctx, recipient.UserId, false) {
+					// it will be sent on the first check after the quiet hours
+					continue
+				}
+				err = s.sendDigest(ctx, &recipient)
+				if err != nil {
+					s.lgr.WithTracing(ctx).Errorf("Unable to send digest to user %v: %v", recipient.UserId, err)
+				}
+			}
+			if len(recipients) < recipientsBatchSize {
+				break
+			}
+		}
+	}
+}
+
+func (s *DigestService) sendDigest(ctx context.Context, recipient *dto.DigestRecipient) error {
+	// the postgres's timestamp has the microsecond precision
+	lastSent := time.Now().UTC().Truncate(time.Microsecond)
+	taken, err := s.dbs.SetDigestLastSent(ctx, recipient.UserId, recipient.LastSentDateTime, &lastSent)
+	if err != nil {
+		return err
+	}
+	if !taken {
+		// the other instance is sending this digest
+		return nil
+	}
+
+	sent, err := s.sendDigestMail(ctx, recipient)
+	if err != nil {
+		// it will be retried on the next check
+		_, err0 := s.dbs.SetDigestLastSent(ctx, recipient.UserId, &lastSent, recipient.LastSentDateTime)
+		if err0 != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to restore digest last sent time for user %v: %v", recipient.UserId, err0)
+		}
+		return err
+	}
+	if sent {
+		s.lgr.WithTracing(ctx).Infof("Digest was sent to user %v", recipient.UserId)
+	}
+	return nil
+}
+
+// returns false in case there was nothing to send
+func (s *DigestService) sendDigestMail(ctx context.Context, recipient *dto.DigestRecipient) (bool, error) {
+	maxItems := viper.GetInt("digest.maxItems")
+	notifications, err := s.dbs.GetDigestNotifications(ctx, recipient.UserId, recipient.LastSentDateTime, maxItems)
+	if err != nil {
+		return false, err
+	}
+	if len(notifications) == 0 {
+		return false, nil
+	}
+	count, err := s.dbs.GetDigestNotificationCount(ctx, recipient.UserId, recipient.LastSentDateTime)
+	if err != nil {
+		return false, err
+	}
+
+	mail, err := s.renderDigestMail(recipient, notifications, count)
+	if err != nil {
+		return false, err
+	}
+	err = s.mailSender.Send(ctx, mail)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *DigestService) renderDigestMail(recipient *dto.DigestRecipient, notifications []dto.NotificationDto, count int64) (*Mail, error) {
+	frontendUrl := viper.GetString("digest.frontendUrl")
+	unsubscribeUrl := frontendUrl + "/api/notification/public/digest/unsubscribe?token=" + url.QueryEscape(recipient.UnsubscribeToken)
+
+	items := make([]digestItem, 0, len(notifications))
+	for _, notification := range notifications {
+		items = append(items, makeDigestItem(frontendUrl, &notification))
+	}
+
+	data := digestTemplateData{
+		Subject:        fmt.Sprintf("%v unread notifications", count),
+		Login:          recipient.Login,
+		Frequency:      recipient.Frequency,
+		Items:          items,
+		MoreCount:      count - int64(len(items)),
+		FrontendUrl:    frontendUrl,
+		UnsubscribeUrl: unsubscribeUrl,
+	}
+
+	var html bytes.Buffer
+	if err := s.htmlTemplate.Execute(&html, data); err != nil {
+		return nil, err
+	}
+	var text bytes.Buffer
+	if err := s.textTemplate.Execute(&text, data); err != nil {
+		return nil, err
+	}
+
+	return &Mail{
+		To:      recipient.Email,
+		Subject: data.Subject,
+		Text:    text.String(),
+		Html:    html.String(),
+		Headers: [][2]string{
+			// https://datatracker.ietf.org/doc/html/rfc8058
+			{"List-Unsubscribe", "<" + unsubscribeUrl + ">"},
+			{"List-Unsubscribe-Post", "List-Unsubscribe=One-Click"},
+		},
+	}, nil
+}
+
+func makeDigestItem(frontendUrl string, notification *dto.NotificationDto) digestItem {
+	var title string
+	switch notification.NotificationType {
+	case "mention":
+		title = fmt.Sprintf("%v mentioned you in %v", notification.ByLogin, notification.ChatTitle)
+	case "reply":
+		title = fmt.Sprintf("%v replied to you in %v", notification.ByLogin, notification.ChatTitle)
+	case "reaction":
+		title = fmt.Sprintf("%v reacted on your message in %v", notification.ByLogin, notification.ChatTitle)
+	case "missed_call":
+		title = fmt.Sprintf("Missed call from %v in %v", notification.ByLogin, notification.ChatTitle)
+	case "keyword":
+		title = fmt.Sprintf("%v wrote about your keyword in %v", notification.ByLogin, notification.ChatTitle)
+	default:
+		title = notification.ChatTitle
+	}
+
+	link := fmt.Sprintf("%v/chat/%v", frontendUrl, notification.ChatId)
+	if notification.MessageId != nil {
+		link = frontendUrl + messageUrl(notification.ChatId, *notification.MessageId)
+	}
+
+	description := []rune(notification.Description)
+	if len(description) > digestDescriptionMaxLength {
+		description = append(description[:digestDescriptionMaxLength], '…')
+	}
+
+	return digestItem{
+		Title:          title,
+		Description:    string(description),
+		Url:            link,
+		CreateDateTime: notification.CreateDateTime,
+	}
+}
+
+func RunDigestScheduler(lgr *logger.Logger, service *DigestService, lc fx.Lifecycle) {
+	if !viper.GetBool("digest.enabled") {
+		lgr.Infof("Digest sending is disabled")
+		return
+	}
+
+	tr := otel.Tracer("scheduler")
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := time.NewTicker(viper.GetDuration("digest.interval"))
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						func() {
+							spanCtx, span := tr.Start(ctx, "digest.send")
+							defer span.End()
+							service.SendDueDigests(spanCtx)
+						}()
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			lgr.Infof("Stopping digest scheduler")
+			ticker.Stop()
+			cancel()
+			return nil
+		},
+	})
+}
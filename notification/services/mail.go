@@ -0,0 +1,183 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"nkonev.name/notification/logger"
+)
+
+type Mail struct {
+	To      string
+	Subject string
+	Text    string
+	Html    string
+	Headers [][2]string // additional ones, e.g. List-Unsubscribe
+}
+
+// sends the mails via smtp, the implicit tls (port 465) and STARTTLS are supported
+type MailSender struct {
+	lgr *logger.Logger
+}
+
+func NewMailSender(lgr *logger.Logger) *MailSender {
+	return &MailSender{
+		lgr: lgr,
+	}
+}
+
+func (s *MailSender) Send(ctx context.Context, m *Mail) error {
+	from := viper.GetString("smtp.from")
+	body, err := buildMail(from, m)
+	if err != nil {
+		return err
+	}
+
+	fromAddress, err := mail.ParseAddress(from)
+	if err != nil {
+		return err
+	}
+
+	address := viper.GetString("smtp.address")
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	timeout := viper.GetDuration("smtp.timeout")
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: viper.GetBool("smtp.insecureSkipVerify"),
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	if viper.GetBool("smtp.implicitTls") {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return err
+	}
+	err = conn.SetDeadline(time.Now().Add(timeout))
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if !viper.GetBool("smtp.implicitTls") {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err = client.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+
+	if username := viper.GetString("smtp.username"); username != "" {
+		if err = client.Auth(smtp.PlainAuth("", username, viper.GetString("smtp.password"), host)); err != nil {
+			return err
+		}
+	}
+
+	if err = client.Mail(fromAddress.Address); err != nil {
+		return err
+	}
+	if err = client.Rcpt(m.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(body); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	s.lgr.WithTracing(ctx).Debugf("Mail %q was sent to %v", m.Subject, m.To)
+	return client.Quit()
+}
+
+// builds multipart/alternative with the plain text and the html, the mail clients show the last one they support
+func buildMail(from string, m *Mail) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	headers := [][2]string{
+		{"From", from},
+		{"To", m.To},
+		{"Subject", mime.QEncoding.Encode("utf-8", m.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", newMessageId(from)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", mw.Boundary())},
+	}
+	headers = append(headers, m.Headers...)
+	// the boundary is needed in the headers, so the parts are written into the separate buffer
+	var headersBuf bytes.Buffer
+	for _, h := range headers {
+		fmt.Fprintf(&headersBuf, "%s: %s\r\n", h[0], h[1])
+	}
+	headersBuf.WriteString("\r\n")
+
+	if err := writeMailPart(mw, "text/plain; charset=utf-8", m.Text); err != nil {
+		return nil, err
+	}
+	if err := writeMailPart(mw, "text/html; charset=utf-8", m.Html); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	headersBuf.Write(buf.Bytes())
+	return headersBuf.Bytes(), nil
+}
+
+func writeMailPart(mw *multipart.Writer, contentType, content string) error {
+	pw, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qw := quotedprintable.NewWriter(pw)
+	if _, err := qw.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qw.Close()
+}
+
+func newMessageId(from string) string {
+	domain := "localhost"
+	if address, err := mail.ParseAddress(from); err == nil {
+		if i := strings.LastIndex(address.Address, "@"); i >= 0 {
+			domain = address.Address[i+1:]
+		}
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain)
+}
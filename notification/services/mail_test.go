@@ -0,0 +1,250 @@
+package services
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/logger"
+)
+
+type receivedMail struct {
+	from string
+	to   []string
+	data []byte
+}
+
+// answers like the smtp server does, without tls and auth
+type fakeSmtp struct {
+	listener   net.Listener
+	rejectRcpt bool
+	mu         sync.Mutex
+	received   []receivedMail
+}
+
+func startFakeSmtp(t *testing.T, rejectRcpt bool) *fakeSmtp {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSmtp{listener: listener, rejectRcpt: rejectRcpt}
+	t.Cleanup(func() {
+		listener.Close()
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	viper.Set("smtp.address", listener.Addr().String())
+	viper.Set("smtp.implicitTls", false)
+	viper.Set("smtp.username", "")
+	viper.Set("smtp.from", "Videochat <noreply@example.com>")
+	viper.Set("smtp.timeout", 5*time.Second)
+	return s
+}
+
+func (s *fakeSmtp) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	current := receivedMail{}
+	tp.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			tp.PrintfLine("250-localhost")
+			tp.PrintfLine("250 8BITMIME")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			current.from = strings.Trim(strings.Fields(line[len("MAIL FROM:"):])[0], "<>")
+			tp.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			if s.rejectRcpt {
+				tp.PrintfLine("550 No such user")
+				continue
+			}
+			current.to = append(current.to, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			tp.PrintfLine("250 OK")
+		case command == "DATA":
+			tp.PrintfLine("354 Go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			current.data = data
+			s.mu.Lock()
+			s.received = append(s.received, current)
+			s.mu.Unlock()
+			current = receivedMail{}
+			tp.PrintfLine("250 OK")
+		case command == "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		case command == "RSET", command == "NOOP":
+			tp.PrintfLine("250 OK")
+		default:
+			tp.PrintfLine("502 Not implemented")
+		}
+	}
+}
+
+func (s *fakeSmtp) getReceived() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMail{}, s.received...)
+}
+
+// returns the decoded text/plain and text/html parts
+func readMailParts(t *testing.T, msg *mail.Message) map[string]string {
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type %v", mediaType)
+	}
+	parts := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		partType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// multipart.Reader decodes quoted-printable itself
+		content, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[partType] = string(content)
+	}
+	return parts
+}
+
+func TestSendDigestMail(t *testing.T) {
+	smtpServer := startFakeSmtp(t, false)
+	viper.Set("digest.frontendUrl", "https://chat.example.com")
+
+	lgr := logger.NewLogger()
+	digestService, err := NewDigestService(nil, NewMailSender(lgr), nil, nil, lgr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messageId := int64(5)
+	recipient := &dto.DigestRecipient{
+		UserId:           2,
+		Email:            "bob@example.com",
+		Login:            "bob",
+		Frequency:        dto.DigestFrequencyDaily,
+		UnsubscribeToken: "a1b2c3",
+	}
+	m, err := digestService.renderDigestMail(recipient, []dto.NotificationDto{
+		{NotificationType: "mention", Description: "<b>look</b> at this, " + strings.Repeat("долгий текст ", 20), ChatId: 1, MessageId: &messageId, ByLogin: "alice", ChatTitle: "Team", CreateDateTime: time.Date(2026, time.October, 19, 10, 30, 0, 0, time.UTC)},
+		{NotificationType: "missed_call", ChatId: 3, ByLogin: "carol", ChatTitle: "Calls"},
+	}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = NewMailSender(lgr).Send(t.Context(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received := smtpServer.getReceived()
+	if len(received) != 1 {
+		t.Fatalf("expected one mail, got %v", len(received))
+	}
+	if received[0].from != "noreply@example.com" {
+		t.Errorf("unexpected envelope sender %v", received[0].from)
+	}
+	if len(received[0].to) != 1 || received[0].to[0] != "bob@example.com" {
+		t.Errorf("unexpected envelope recipients %v", received[0].to)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(received[0].data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "5 unread notifications" {
+		t.Errorf("unexpected subject %v", subject)
+	}
+	if msg.Header.Get("To") != "bob@example.com" || msg.Header.Get("From") != "Videochat <noreply@example.com>" {
+		t.Errorf("unexpected headers %v", msg.Header)
+	}
+	if !strings.HasSuffix(msg.Header.Get("Message-Id"), "@example.com>") {
+		t.Errorf("unexpected message id %v", msg.Header.Get("Message-Id"))
+	}
+	unsubscribeUrl := "https://chat.example.com/api/notification/public/digest/unsubscribe?token=a1b2c3"
+	if msg.Header.Get("List-Unsubscribe") != "<"+unsubscribeUrl+">" || msg.Header.Get("List-Unsubscribe-Post") != "List-Unsubscribe=One-Click" {
+		t.Errorf("unexpected unsubscribe headers %v", msg.Header)
+	}
+
+	parts := readMailParts(t, msg)
+	text := parts["text/plain"]
+	for _, expected := range []string{
+		"Hello, bob.",
+		"* alice mentioned you in Team (2026-10-19 10:30 UTC)",
+		"https://chat.example.com/chat/1#message-5",
+		"Missed call from carol in Calls",
+		"https://chat.example.com/chat/3\n",
+		"And 3 more in the chat",
+		"daily digest",
+		"Unsubscribe: " + unsubscribeUrl,
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("the text part doesn't contain %q:\n%v", expected, text)
+		}
+	}
+	if !strings.Contains(text, "…") || strings.Count(text, "долгий текст") >= 20 {
+		t.Errorf("the long description should be shortened:\n%v", text)
+	}
+
+	html := parts["text/html"]
+	if !strings.Contains(html, "&lt;b&gt;look&lt;/b&gt;") || strings.Contains(html, "<b>look</b>") {
+		t.Errorf("the description should be escaped:\n%v", html)
+	}
+	if !strings.Contains(html, `<a href="https://chat.example.com/chat/1#message-5">alice mentioned you in Team</a>`) {
+		t.Errorf("the html part doesn't contain the link:\n%v", html)
+	}
+}
+
+func TestSendMailRejectedRecipient(t *testing.T) {
+	smtpServer := startFakeSmtp(t, true)
+
+	err := NewMailSender(logger.NewLogger()).Send(t.Context(), &Mail{To: "nobody@example.com", Subject: "Test", Text: "text", Html: "<p>html</p>"})
+	if err == nil {
+		t.Fatal("expected the error")
+	}
+	if len(smtpServer.getReceived()) != 0 {
+		t.Error("nothing should be sent")
+	}
+}
@@ -4,23 +4,30 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/spf13/viper"
 	"nkonev.name/notification/db"
 	"nkonev.name/notification/dto"
 	"nkonev.name/notification/logger"
 	"nkonev.name/notification/producer"
+	"sync"
+	"time"
 )
 
 type NotificationService struct {
 	dbs                   *db.DB
 	rabbitEventsPublisher *producer.RabbitEventPublisher
+	webPushService        *WebPushService
+	quietHoursService     *QuietHoursService
 	lgr                   *logger.Logger
 }
 
-func CreateNotificationService(dbs *db.DB, rabbitEventsPublisher *producer.RabbitEventPublisher, lgr *logger.Logger) *NotificationService {
+func CreateNotificationService(dbs *db.DB, rabbitEventsPublisher *producer.RabbitEventPublisher, webPushService *WebPushService, quietHoursService *QuietHoursService, lgr *logger.Logger) *NotificationService {
 	return &NotificationService{
 		dbs:                   dbs,
 		rabbitEventsPublisher: rabbitEventsPublisher,
+		webPushService:        webPushService,
+		quietHoursService:     quietHoursService,
 		lgr:                   lgr,
 	}
 }
@@ -31,12 +38,19 @@ const NotificationClearAll = "notification_clear_all"
 
 func (srv *NotificationService) HandleChatNotification(ctx context.Context, event *dto.NotificationEvent) {
 
-	settings, err := srv.getNotificationSettings(ctx, event)
+	settings, perChatSettings, err := srv.getNotificationSettings(ctx, event)
 	if err != nil {
 		srv.lgr.WithTracing(ctx).Errorf("Unable to get notification settings %v", err)
 		return
 	}
 
+	// the notifications are stored anyway, only the delivery is held back.
+	// it's looked up only for the notifications which are going to be delivered, not for the disabled and the deleted ones
+	quiet := sync.OnceValue(func() bool {
+		bypassQuietHours := perChatSettings.BypassQuietHours != nil && *perChatSettings.BypassQuietHours
+		return srv.quietHoursService.IsQuiet(ctx, event.UserId, bypassQuietHours)
+	})
+
 	var count int64
 
 	if event.MentionNotification != nil && settings.MentionsEnabled {
@@ -62,29 +76,32 @@ func (srv *NotificationService) HandleChatNotification(ctx context.Context, even
 				return
 			}
 
-			err = srv.rabbitEventsPublisher.Publish(
-				ctx,
-				event.UserId,
-				&dto.WrapperNotificationDto{
-					NotificationDto: dto.NotificationDto{
-						Id:               id,
-						ChatId:           event.ChatId,
-						MessageId:        &mentionNotification.Id,
-						NotificationType: notificationType,
-						Description:      mentionNotification.Text,
-						CreateDateTime:   createDateTime,
-						ByUserId:         event.ByUserId,
-						ByLogin:          event.ByLogin,
-						ByAvatar:         event.ByAvatar,
-						ChatTitle:        event.ChatTitle,
-					},
-					TotalCount: count,
+			srv.publishAdded(ctx, quiet(), event.UserId, &dto.WrapperNotificationDto{
+				NotificationDto: dto.NotificationDto{
+					Id:               id,
+					ChatId:           event.ChatId,
+					MessageId:        &mentionNotification.Id,
+					NotificationType: notificationType,
+					Description:      mentionNotification.Text,
+					CreateDateTime:   createDateTime,
+					ByUserId:         event.ByUserId,
+					ByLogin:          event.ByLogin,
+					ByAvatar:         event.ByAvatar,
+					ChatTitle:        event.ChatTitle,
 				},
-				NotificationAdd,
-			)
-			if err != nil {
-				srv.lgr.WithTracing(ctx).Errorf("Unable to send notification delete %v", err)
-			}
+				TotalCount: count,
+			})
+
+			srv.push(ctx, quiet(), event.UserId, &dto.WebPushPayload{
+				NotificationType: notificationType,
+				Title:            fmt.Sprintf("%v mentioned you in %v", event.ByLogin, event.ChatTitle),
+				Body:             mentionNotification.Text,
+				ChatId:           event.ChatId,
+				MessageId:        &mentionNotification.Id,
+				Url:              messageUrl(event.ChatId, mentionNotification.Id),
+				Tag:              fmt.Sprintf("mention-%v", mentionNotification.Id),
+				Icon:             event.ByAvatar,
+			}, viper.GetDuration("webPush.ttl"), WebPushUrgencyNormal)
 
 		case "mention_deleted":
 			id, err := srv.dbs.DeleteNotificationByMessageId(ctx, mentionNotification.Id, notificationType, event.UserId, nil)
@@ -131,29 +148,57 @@ func (srv *NotificationService) HandleChatNotification(ctx context.Context, even
 			return
 		}
 
-		err = srv.rabbitEventsPublisher.Publish(
-			ctx,
-			event.UserId,
-			&dto.WrapperNotificationDto{
-				NotificationDto: dto.NotificationDto{
-					Id:               id,
-					ChatId:           event.ChatId,
-					MessageId:        nil,
-					NotificationType: notificationType,
-					Description:      notification.Description,
-					CreateDateTime:   createDateTime,
-					ByUserId:         event.ByUserId,
-					ByLogin:          event.ByLogin,
-					ByAvatar:         event.ByAvatar,
-					ChatTitle:        event.ChatTitle,
-				},
-				TotalCount: count,
+		srv.publishAdded(ctx, quiet(), event.UserId, &dto.WrapperNotificationDto{
+			NotificationDto: dto.NotificationDto{
+				Id:               id,
+				ChatId:           event.ChatId,
+				MessageId:        nil,
+				NotificationType: notificationType,
+				Description:      notification.Description,
+				CreateDateTime:   createDateTime,
+				ByUserId:         event.ByUserId,
+				ByLogin:          event.ByLogin,
+				ByAvatar:         event.ByAvatar,
+				ChatTitle:        event.ChatTitle,
 			},
-			NotificationAdd,
-		)
+			TotalCount: count,
+		})
+	} else if event.MeetingReminderNotification != nil {
+		err := srv.removeExcessNotificationsIfNeed(ctx, event.UserId)
 		if err != nil {
-			srv.lgr.WithTracing(ctx).Errorf("Unable to send notification delete %v", err)
+			srv.lgr.WithTracing(ctx).Errorf("Unable to delete excess notifications %v", err)
+			return
+		}
+
+		notification := event.MeetingReminderNotification
+		notificationType := "meeting_reminder"
+		id, createDateTime, err := srv.dbs.PutNotification(ctx, nil, event.UserId, event.ChatId, notificationType, notification.Description, event.ByUserId, event.ByLogin, event.ChatTitle, nil)
+		if err != nil {
+			srv.lgr.WithTracing(ctx).Errorf("Unable to put notification %v", err)
+			return
 		}
+
+		count, err = srv.dbs.GetNotificationCount(ctx, event.UserId)
+		if err != nil {
+			srv.lgr.WithTracing(ctx).Errorf("Unable to count notification %v", err)
+			return
+		}
+
+		srv.publishAdded(ctx, quiet(), event.UserId, &dto.WrapperNotificationDto{
+			NotificationDto: dto.NotificationDto{
+				Id:               id,
+				ChatId:           event.ChatId,
+				MessageId:        nil,
+				NotificationType: notificationType,
+				Description:      notification.Description,
+				CreateDateTime:   createDateTime,
+				ByUserId:         event.ByUserId,
+				ByLogin:          event.ByLogin,
+				ByAvatar:         event.ByAvatar,
+				ChatTitle:        event.ChatTitle,
+			},
+			TotalCount: count,
+		})
 	} else if event.ReplyNotification != nil && settings.AnswersEnabled {
 		err := srv.removeExcessNotificationsIfNeed(ctx, event.UserId)
 		if err != nil {
@@ -176,29 +221,32 @@ func (srv *NotificationService) HandleChatNotification(ctx context.Context, even
 				return
 			}
 
-			err = srv.rabbitEventsPublisher.Publish(
-				ctx,
-				event.UserId,
-				&dto.WrapperNotificationDto{
-					NotificationDto: dto.NotificationDto{
-						Id:               id,
-						ChatId:           event.ChatId,
-						MessageId:        &notification.MessageId,
-						NotificationType: notificationType,
-						Description:      notification.ReplyableMessage,
-						CreateDateTime:   createDateTime,
-						ByUserId:         event.ByUserId,
-						ByLogin:          event.ByLogin,
-						ByAvatar:         event.ByAvatar,
-						ChatTitle:        event.ChatTitle,
-					},
-					TotalCount: count,
+			srv.publishAdded(ctx, quiet(), event.UserId, &dto.WrapperNotificationDto{
+				NotificationDto: dto.NotificationDto{
+					Id:               id,
+					ChatId:           event.ChatId,
+					MessageId:        &notification.MessageId,
+					NotificationType: notificationType,
+					Description:      notification.ReplyableMessage,
+					CreateDateTime:   createDateTime,
+					ByUserId:         event.ByUserId,
+					ByLogin:          event.ByLogin,
+					ByAvatar:         event.ByAvatar,
+					ChatTitle:        event.ChatTitle,
 				},
-				NotificationAdd,
-			)
-			if err != nil {
-				srv.lgr.WithTracing(ctx).Errorf("Unable to send notification delete %v", err)
-			}
+				TotalCount: count,
+			})
+
+			srv.push(ctx, quiet(), event.UserId, &dto.WebPushPayload{
+				NotificationType: notificationType,
+				Title:            fmt.Sprintf("%v replied to you in %v", event.ByLogin, event.ChatTitle),
+				Body:             notification.ReplyableMessage,
+				ChatId:           event.ChatId,
+				MessageId:        &notification.MessageId,
+				Url:              messageUrl(event.ChatId, notification.MessageId),
+				Tag:              fmt.Sprintf("reply-%v", notification.MessageId),
+				Icon:             event.ByAvatar,
+			}, viper.GetDuration("webPush.ttl"), WebPushUrgencyNormal)
 
 		case "reply_deleted":
 			id, err := srv.dbs.DeleteNotificationByMessageId(ctx, notification.MessageId, notificationType, event.UserId, nil)
@@ -246,32 +294,96 @@ func (srv *NotificationService) HandleChatNotification(ctx context.Context, even
 				return
 			}
 
-			err = srv.rabbitEventsPublisher.Publish(
-				ctx,
-				event.UserId,
-				&dto.WrapperNotificationDto{
-					NotificationDto: dto.NotificationDto{
-						Id:               id,
-						ChatId:           event.ChatId,
-						MessageId:        &notification.MessageId,
-						NotificationType: notificationType,
-						Description:      notification.Reaction,
-						CreateDateTime:   createDateTime,
-						ByUserId:         event.ByUserId,
-						ByLogin:          event.ByLogin,
-						ByAvatar:         event.ByAvatar,
-						ChatTitle:        event.ChatTitle,
-					},
-					TotalCount: count,
+			srv.publishAdded(ctx, quiet(), event.UserId, &dto.WrapperNotificationDto{
+				NotificationDto: dto.NotificationDto{
+					Id:               id,
+					ChatId:           event.ChatId,
+					MessageId:        &notification.MessageId,
+					NotificationType: notificationType,
+					Description:      notification.Reaction,
+					CreateDateTime:   createDateTime,
+					ByUserId:         event.ByUserId,
+					ByLogin:          event.ByLogin,
+					ByAvatar:         event.ByAvatar,
+					ChatTitle:        event.ChatTitle,
 				},
-				NotificationAdd,
-			)
+				TotalCount: count,
+			})
+
+		case "reaction_notification_removed":
+			id, err := srv.dbs.DeleteNotificationByMessageId(ctx, notification.MessageId, notificationType, event.UserId, &notification.Reaction)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) { // occurs during message read on previously read message
+					srv.lgr.WithTracing(ctx).Debugf("Missed notification %v", err)
+				} else {
+					srv.lgr.WithTracing(ctx).Errorf("Unable to delete notification %v", err)
+				}
+				return
+			}
+			count, err = srv.dbs.GetNotificationCount(ctx, event.UserId)
+			if err != nil {
+				srv.lgr.WithTracing(ctx).Errorf("Unable to count notification %v", err)
+				return
+			}
+
+			err = srv.rabbitEventsPublisher.Publish(ctx, event.UserId, dto.NewWrapperNotificationDeleteDto(id, count, notificationType), NotificationDelete)
 			if err != nil {
 				srv.lgr.WithTracing(ctx).Errorf("Unable to send notification delete %v", err)
 			}
+		}
+	} else if event.KeywordNotification != nil {
+		// the user has subscribed to the keywords explicitly, so there is no setting for it
+		notification := event.KeywordNotification
+		notificationType := "keyword"
+		switch event.EventType {
+		case "keyword_added":
+			err := srv.removeExcessNotificationsIfNeed(ctx, event.UserId)
+			if err != nil {
+				srv.lgr.WithTracing(ctx).Errorf("Unable to delete excess notifications %v", err)
+				return
+			}
 
-		case "reaction_notification_removed":
-			id, err := srv.dbs.DeleteNotificationByMessageId(ctx, notification.MessageId, notificationType, event.UserId, &notification.Reaction)
+			id, createDateTime, err := srv.dbs.PutNotification(ctx, &notification.Id, event.UserId, event.ChatId, notificationType, notification.Text, event.ByUserId, event.ByLogin, event.ChatTitle, nil)
+			if err != nil {
+				srv.lgr.WithTracing(ctx).Errorf("Unable to put notification %v", err)
+				return
+			}
+
+			count, err = srv.dbs.GetNotificationCount(ctx, event.UserId)
+			if err != nil {
+				srv.lgr.WithTracing(ctx).Errorf("Unable to count notification %v", err)
+				return
+			}
+
+			srv.publishAdded(ctx, quiet(), event.UserId, &dto.WrapperNotificationDto{
+				NotificationDto: dto.NotificationDto{
+					Id:               id,
+					ChatId:           event.ChatId,
+					MessageId:        &notification.Id,
+					NotificationType: notificationType,
+					Description:      notification.Text,
+					CreateDateTime:   createDateTime,
+					ByUserId:         event.ByUserId,
+					ByLogin:          event.ByLogin,
+					ByAvatar:         event.ByAvatar,
+					ChatTitle:        event.ChatTitle,
+				},
+				TotalCount: count,
+			})
+
+			srv.push(ctx, quiet(), event.UserId, &dto.WebPushPayload{
+				NotificationType: notificationType,
+				Title:            fmt.Sprintf("%v wrote about %q in %v", event.ByLogin, notification.Keyword, event.ChatTitle),
+				Body:             notification.Text,
+				ChatId:           event.ChatId,
+				MessageId:        &notification.Id,
+				Url:              messageUrl(event.ChatId, notification.Id),
+				Tag:              fmt.Sprintf("keyword-%v", notification.Id),
+				Icon:             event.ByAvatar,
+			}, viper.GetDuration("webPush.ttl"), WebPushUrgencyNormal)
+
+		case "keyword_deleted":
+			id, err := srv.dbs.DeleteNotificationByMessageId(ctx, notification.Id, notificationType, event.UserId, nil)
 			if err != nil {
 				if errors.Is(err, sql.ErrNoRows) { // occurs during message read on previously read message
 					srv.lgr.WithTracing(ctx).Debugf("Missed notification %v", err)
@@ -280,6 +392,7 @@ func (srv *NotificationService) HandleChatNotification(ctx context.Context, even
 				}
 				return
 			}
+
 			count, err = srv.dbs.GetNotificationCount(ctx, event.UserId)
 			if err != nil {
 				srv.lgr.WithTracing(ctx).Errorf("Unable to count notification %v", err)
@@ -290,50 +403,98 @@ func (srv *NotificationService) HandleChatNotification(ctx context.Context, even
 			if err != nil {
 				srv.lgr.WithTracing(ctx).Errorf("Unable to send notification delete %v", err)
 			}
+		default:
+			srv.lgr.WithTracing(ctx).Errorf("Unexpected event type %v", event.EventType)
 		}
+	} else if event.CallInvitationNotification != nil && settings.CallInvitationsEnabled {
+		// isn't stored because the ringing is short, the missed call is stored instead
+		srv.push(ctx, quiet(), event.UserId, &dto.WebPushPayload{
+			NotificationType: "call_invitation",
+			Title:            fmt.Sprintf("%v is calling you", event.ByLogin),
+			Body:             event.CallInvitationNotification.Description,
+			ChatId:           event.ChatId,
+			Url:              fmt.Sprintf("/chat/%v/video", event.ChatId),
+			Tag:              fmt.Sprintf("call-%v", event.ChatId),
+			Icon:             event.ByAvatar,
+		}, viper.GetDuration("webPush.callTtl"), WebPushUrgencyHigh)
 	}
 
 }
 
-func (srv *NotificationService) getNotificationSettings(ctx context.Context, event *dto.NotificationEvent) (*dto.NotificationGlobalSettings, error) {
+func (srv *NotificationService) publishAdded(ctx context.Context, quiet bool, userId int64, notification *dto.WrapperNotificationDto) {
+	if quiet {
+		srv.quietHoursService.Hold(ctx, userId)
+		return
+	}
+	err := srv.rabbitEventsPublisher.Publish(ctx, userId, notification, NotificationAdd)
+	if err != nil {
+		srv.lgr.WithTracing(ctx).Errorf("Unable to send notification add %v", err)
+	}
+}
+
+func (srv *NotificationService) push(ctx context.Context, quiet bool, userId int64, payload *dto.WebPushPayload, ttl time.Duration, urgency string) {
+	if quiet {
+		srv.quietHoursService.Hold(ctx, userId)
+		return
+	}
+	srv.webPushService.Push(ctx, userId, payload, ttl, urgency)
+}
+
+// matches the routes of the frontend
+func messageUrl(chatId, messageId int64) string {
+	return fmt.Sprintf("/chat/%v#message-%v", chatId, messageId)
+}
+
+// returns the global settings overridden by the chat ones and the chat ones themselves
+func (srv *NotificationService) getNotificationSettings(ctx context.Context, event *dto.NotificationEvent) (*dto.NotificationGlobalSettings, *dto.NotificationPerChatSettings, error) {
 
 	userNotificationsGlobalSettings, err := srv.dbs.GetNotificationGlobalSettings(ctx, event.UserId)
 	if err != nil {
 		srv.lgr.WithTracing(ctx).Errorf("Error during getting global notification settings %v", err)
-		return nil, err
+		return nil, nil, err
 	}
 
 	userNotificationsPerChatSettings, err := srv.dbs.GetNotificationPerChatSettings(ctx, event.UserId, event.ChatId)
 	if err != nil {
 		srv.lgr.WithTracing(ctx).Errorf("Error during getting per chat notification settings %v", err)
-		return nil, err
+		return nil, nil, err
 	}
 
+	return mergeNotificationSettings(userNotificationsGlobalSettings, userNotificationsPerChatSettings), userNotificationsPerChatSettings, nil
+}
+
+// the chat settings override the global ones when they are set
+func mergeNotificationSettings(global *dto.NotificationGlobalSettings, perChat *dto.NotificationPerChatSettings) *dto.NotificationGlobalSettings {
 	result := dto.NotificationGlobalSettings{
-		MentionsEnabled:    userNotificationsGlobalSettings.MentionsEnabled,
-		MissedCallsEnabled: userNotificationsGlobalSettings.MissedCallsEnabled,
-		AnswersEnabled:     userNotificationsGlobalSettings.AnswersEnabled,
-		ReactionsEnabled:   userNotificationsGlobalSettings.ReactionsEnabled,
+		MentionsEnabled:        global.MentionsEnabled,
+		MissedCallsEnabled:     global.MissedCallsEnabled,
+		AnswersEnabled:         global.AnswersEnabled,
+		ReactionsEnabled:       global.ReactionsEnabled,
+		CallInvitationsEnabled: global.CallInvitationsEnabled,
 	}
 
 	// override
-	if userNotificationsPerChatSettings.MentionsEnabled != nil {
-		result.MentionsEnabled = *userNotificationsPerChatSettings.MentionsEnabled
+	if perChat.MentionsEnabled != nil {
+		result.MentionsEnabled = *perChat.MentionsEnabled
+	}
+
+	if perChat.MissedCallsEnabled != nil {
+		result.MissedCallsEnabled = *perChat.MissedCallsEnabled
 	}
 
-	if userNotificationsPerChatSettings.MissedCallsEnabled != nil {
-		result.MissedCallsEnabled = *userNotificationsPerChatSettings.MissedCallsEnabled
+	if perChat.AnswersEnabled != nil {
+		result.AnswersEnabled = *perChat.AnswersEnabled
 	}
 
-	if userNotificationsPerChatSettings.AnswersEnabled != nil {
-		result.AnswersEnabled = *userNotificationsPerChatSettings.AnswersEnabled
+	if perChat.ReactionsEnabled != nil {
+		result.ReactionsEnabled = *perChat.ReactionsEnabled
 	}
 
-	if userNotificationsPerChatSettings.ReactionsEnabled != nil {
-		result.ReactionsEnabled = *userNotificationsPerChatSettings.ReactionsEnabled
+	if perChat.CallInvitationsEnabled != nil {
+		result.CallInvitationsEnabled = *perChat.CallInvitationsEnabled
 	}
 
-	return &result, nil
+	return &result
 }
 
 func (srv *NotificationService) removeExcessNotificationsIfNeed(ctx context.Context, userId int64) error {
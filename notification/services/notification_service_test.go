@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+
+	"nkonev.name/notification/dto"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestMergeNotificationSettings(t *testing.T) {
+	allEnabled := &dto.NotificationGlobalSettings{MentionsEnabled: true, MissedCallsEnabled: true, AnswersEnabled: true, ReactionsEnabled: true, CallInvitationsEnabled: true}
+
+	cases := []struct {
+		name                    string
+		global                  *dto.NotificationGlobalSettings
+		perChat                 *dto.NotificationPerChatSettings
+		expectedMissedCalls     bool
+		expectedCallInvitations bool
+	}{
+		{"the global ones when the chat ones aren't set", allEnabled, &dto.NotificationPerChatSettings{}, true, true},
+		{"the missed calls are off, but the invitations still ring", allEnabled, &dto.NotificationPerChatSettings{MissedCallsEnabled: boolPtr(false)}, false, true},
+		{"the invitations are off, but the missed calls are still stored", allEnabled, &dto.NotificationPerChatSettings{CallInvitationsEnabled: boolPtr(false)}, true, false},
+		{"the chat enables what is globally off", &dto.NotificationGlobalSettings{}, &dto.NotificationPerChatSettings{CallInvitationsEnabled: boolPtr(true)}, false, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result := mergeNotificationSettings(c.global, c.perChat)
+			if result.MissedCallsEnabled != c.expectedMissedCalls {
+				t.Errorf("expected missed calls %v, got %v", c.expectedMissedCalls, result.MissedCallsEnabled)
+			}
+			if result.CallInvitationsEnabled != c.expectedCallInvitations {
+				t.Errorf("expected call invitations %v, got %v", c.expectedCallInvitations, result.CallInvitationsEnabled)
+			}
+			if result.MentionsEnabled != c.global.MentionsEnabled {
+				t.Errorf("the mentions should stay global")
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+	_ "time/tzdata" // the time zones of the users shouldn't depend on the image
+
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/fx"
+	"nkonev.name/notification/db"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/logger"
+	"nkonev.name/notification/producer"
+)
+
+const NotificationSummary = "notification_summary"
+
+const quietHoursSummaryType = "quiet_hours_summary"
+
+const heldUsersBatchSize = 100
+
+// during the quiet hours and do not disturb the notifications are stored, but the delivery is held back till the end of them
+type QuietHoursService struct {
+	dbs                   *db.DB
+	rabbitEventsPublisher *producer.RabbitEventPublisher
+	webPushService        *WebPushService
+	lgr                   *logger.Logger
+}
+
+func NewQuietHoursService(dbs *db.DB, rabbitEventsPublisher *producer.RabbitEventPublisher, webPushService *WebPushService, lgr *logger.Logger) *QuietHoursService {
+	return &QuietHoursService{
+		dbs:                   dbs,
+		rabbitEventsPublisher: rabbitEventsPublisher,
+		webPushService:        webPushService,
+		lgr:                   lgr,
+	}
+}
+
+// bypassQuietHours is set by the user for the critical chats, it's false for the deliveries which aren't related to a chat, e.g. the digest
+func (s *QuietHoursService) IsQuiet(ctx context.Context, userId int64, bypassQuietHours bool) bool {
+	doNotDisturb := s.getDoNotDisturbUserIds(ctx, []int64{userId})[userId]
+
+	settings := &dto.NotificationQuietHoursSettings{}
+	if !doNotDisturb && !bypassQuietHours {
+		fetched, err := s.dbs.GetNotificationQuietHours(ctx, userId)
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to get quiet hours of user %v: %v", userId, err)
+		} else {
+			settings = fetched
+		}
+	}
+	return isQuiet(doNotDisturb, bypassQuietHours, settings, time.Now())
+}
+
+// the do not disturb status can't be bypassed by the chat
+func isQuiet(doNotDisturb bool, bypassQuietHours bool, settings *dto.NotificationQuietHoursSettings, now time.Time) bool {
+	if doNotDisturb {
+		return true
+	}
+	if bypassQuietHours {
+		return false
+	}
+	return isQuietHours(settings, now)
+}
+
+func (s *QuietHoursService) Hold(ctx context.Context, userId int64) {
+	err := s.dbs.HoldNotificationQuietHours(ctx, userId)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to hold notifications of user %v: %v", userId, err)
+	}
+}
+
+// sends the summary to the users whose quiet hours or do not disturb are over
+func (s *QuietHoursService) ReleaseDue(ctx context.Context) {
+	now := time.Now()
+	var afterUserId int64 = 0
+	for {
+		heldUsers, err := s.dbs.GetQuietHoursHeldUsers(ctx, afterUserId, heldUsersBatchSize)
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to get users with held notifications %v", err)
+			return
+		}
+		heldUserIds := make([]int64, 0, len(heldUsers))
+		for _, held := range heldUsers {
+			heldUserIds = append(heldUserIds, held.UserId)
+		}
+		dndUserIds := s.getDoNotDisturbUserIds(ctx, heldUserIds)
+
+		for _, held := range heldUsers {
+			if !isQuietHours(&held.Settings, now) && !dndUserIds[held.UserId] {
+				err = s.release(ctx, &held)
+				if err != nil {
+					s.lgr.WithTracing(ctx).Errorf("Unable to release notifications of user %v: %v", held.UserId, err)
+				}
+			}
+			afterUserId = held.UserId
+		}
+		if len(heldUsers) < heldUsersBatchSize {
+			break
+		}
+	}
+}
+
+// the do not disturb is projected from the event service by the presence listener
+func (s *QuietHoursService) getDoNotDisturbUserIds(ctx context.Context, userIds []int64) map[int64]bool {
+	res, err := s.dbs.GetNotificationDoNotDisturbUserIds(ctx, userIds, time.Now())
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get do not disturb of users %v: %v", userIds, err)
+		return map[int64]bool{}
+	}
+	return res
+}
+
+func (s *QuietHoursService) release(ctx context.Context, held *dto.QuietHoursHeldUser) error {
+	taken, err := s.dbs.ReleaseNotificationQuietHours(ctx, held.UserId, held.HeldSince)
+	if err != nil {
+		return err
+	}
+	if !taken {
+		// the other instance is releasing this user
+		return nil
+	}
+
+	// the read ones are already deleted, so only the still relevant are counted
+	count, err := s.dbs.GetNotificationCountSince(ctx, held.UserId, held.HeldSince)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	totalCount, err := s.dbs.GetNotificationCount(ctx, held.UserId)
+	if err != nil {
+		return err
+	}
+
+	description := fmt.Sprintf("%v notifications while they were paused", count)
+	err = s.rabbitEventsPublisher.Publish(
+		ctx,
+		held.UserId,
+		&dto.WrapperNotificationDto{
+			NotificationDto: dto.NotificationDto{
+				NotificationType: quietHoursSummaryType,
+				Description:      description,
+				CreateDateTime:   time.Now().UTC(),
+			},
+			TotalCount: totalCount,
+		},
+		NotificationSummary,
+	)
+	if err != nil {
+		return err
+	}
+
+	s.webPushService.Push(ctx, held.UserId, &dto.WebPushPayload{
+		NotificationType: quietHoursSummaryType,
+		Title:            "The notifications are resumed",
+		Body:             description,
+		Url:              "/",
+		Tag:              quietHoursSummaryType,
+	}, viper.GetDuration("webPush.ttl"), WebPushUrgencyNormal)
+
+	s.lgr.WithTracing(ctx).Infof("Held notifications were released for user %v", held.UserId)
+	return nil
+}
+
+func isQuietHours(settings *dto.NotificationQuietHoursSettings, now time.Time) bool {
+	if !settings.Enabled {
+		return false
+	}
+	loc, err := time.LoadLocation(settings.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+
+	for _, w := range settings.Windows {
+		start, err := dto.ParseClock(w.Start)
+		if err != nil {
+			continue
+		}
+		end, err := dto.ParseClock(w.End)
+		if err != nil {
+			continue
+		}
+		// the window which has started yesterday can last till today
+		for _, dayOffset := range []int{0, -1} {
+			day := time.Date(local.Year(), local.Month(), local.Day()+dayOffset, 0, 0, 0, 0, loc)
+			if day.Weekday() != w.Weekday {
+				continue
+			}
+			windowStart := time.Date(day.Year(), day.Month(), day.Day(), start/60, start%60, 0, 0, loc)
+			windowEnd := time.Date(day.Year(), day.Month(), day.Day(), end/60, end%60, 0, 0, loc)
+			if end <= start {
+				windowEnd = time.Date(day.Year(), day.Month(), day.Day()+1, end/60, end%60, 0, 0, loc)
+			}
+			if !local.Before(windowStart) && local.Before(windowEnd) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func RunQuietHoursScheduler(lgr *logger.Logger, service *QuietHoursService, lc fx.Lifecycle) {
+	tr := otel.Tracer("scheduler")
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := time.NewTicker(viper.GetDuration("quietHours.interval"))
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						func() {
+							spanCtx, span := tr.Start(ctx, "quietHours.release")
+							defer span.End()
+							service.ReleaseDue(spanCtx)
+						}()
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			lgr.Infof("Stopping quiet hours scheduler")
+			ticker.Stop()
+			cancel()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"nkonev.name/notification/dto"
+)
+
+func quietHoursSettings(timeZone string, windows ...dto.QuietHoursWindow) *dto.NotificationQuietHoursSettings {
+	return &dto.NotificationQuietHoursSettings{
+		Enabled:  true,
+		TimeZone: timeZone,
+		Windows:  windows,
+	}
+}
+
+func TestIsQuietHours(t *testing.T) {
+	mondayNight := dto.QuietHoursWindow{Weekday: time.Monday, Start: "22:00", End: "07:00"}
+	wednesdayLunch := dto.QuietHoursWindow{Weekday: time.Wednesday, Start: "12:00", End: "13:00"}
+	saturdayNight := dto.QuietHoursWindow{Weekday: time.Saturday, Start: "22:00", End: "07:00"}
+
+	// 2 March 2026 is monday, Berlin is UTC+1 till 29 March
+	cases := []struct {
+		name     string
+		settings *dto.NotificationQuietHoursSettings
+		now      time.Time
+		expected bool
+	}{
+		{"before the overnight window", quietHoursSettings("Europe/Berlin", mondayNight), time.Date(2026, time.March, 2, 20, 59, 0, 0, time.UTC), false},
+		{"the start of the overnight window", quietHoursSettings("Europe/Berlin", mondayNight), time.Date(2026, time.March, 2, 21, 0, 0, 0, time.UTC), true},
+		{"after the midnight the window of the previous day lasts", quietHoursSettings("Europe/Berlin", mondayNight), time.Date(2026, time.March, 3, 5, 59, 0, 0, time.UTC), true},
+		{"the end of the overnight window", quietHoursSettings("Europe/Berlin", mondayNight), time.Date(2026, time.March, 3, 6, 0, 0, 0, time.UTC), false},
+		{"the other weekday", quietHoursSettings("Europe/Berlin", mondayNight), time.Date(2026, time.March, 3, 21, 30, 0, 0, time.UTC), false},
+		{"sunday night isn't monday night", quietHoursSettings("Europe/Berlin", mondayNight), time.Date(2026, time.March, 1, 22, 30, 0, 0, time.UTC), false},
+		{"the same instant in the other time zone", quietHoursSettings("America/New_York", mondayNight), time.Date(2026, time.March, 2, 21, 30, 0, 0, time.UTC), false},
+		{"the window in the other time zone", quietHoursSettings("America/New_York", mondayNight), time.Date(2026, time.March, 3, 3, 30, 0, 0, time.UTC), true},
+		{"the weekday is in the user's time zone", quietHoursSettings("Asia/Tokyo", mondayNight), time.Date(2026, time.March, 2, 13, 30, 0, 0, time.UTC), true},
+		{"within the daytime window", quietHoursSettings("Europe/Berlin", mondayNight, wednesdayLunch), time.Date(2026, time.March, 4, 11, 30, 0, 0, time.UTC), true},
+		{"after the daytime window", quietHoursSettings("Europe/Berlin", mondayNight, wednesdayLunch), time.Date(2026, time.March, 4, 12, 0, 0, 0, time.UTC), false},
+		{"the night of the daylight saving time change", quietHoursSettings("Europe/Berlin", saturdayNight), time.Date(2026, time.March, 29, 4, 59, 0, 0, time.UTC), true},
+		{"the end is in the summer time", quietHoursSettings("Europe/Berlin", saturdayNight), time.Date(2026, time.March, 29, 5, 0, 0, 0, time.UTC), false},
+		{"the unknown time zone is utc", quietHoursSettings("Mars/Olympus_Mons", mondayNight), time.Date(2026, time.March, 2, 22, 30, 0, 0, time.UTC), true},
+		{"disabled", &dto.NotificationQuietHoursSettings{Enabled: false, TimeZone: "UTC", Windows: []dto.QuietHoursWindow{mondayNight}}, time.Date(2026, time.March, 2, 23, 0, 0, 0, time.UTC), false},
+		{"without windows", quietHoursSettings("UTC"), time.Date(2026, time.March, 2, 23, 0, 0, 0, time.UTC), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := isQuietHours(c.settings, c.now); actual != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIsQuiet(t *testing.T) {
+	settings := quietHoursSettings("UTC", dto.QuietHoursWindow{Weekday: time.Monday, Start: "22:00", End: "07:00"})
+	during := time.Date(2026, time.March, 2, 23, 0, 0, 0, time.UTC)
+	outside := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name             string
+		doNotDisturb     bool
+		bypassQuietHours bool
+		now              time.Time
+		expected         bool
+	}{
+		{"during the quiet hours", false, false, during, true},
+		{"outside the quiet hours", false, false, outside, false},
+		{"the urgent chat bypasses the quiet hours", false, true, during, false},
+		{"do not disturb outside the quiet hours", true, false, outside, true},
+		{"the urgent chat doesn't bypass do not disturb", true, true, during, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := isQuiet(c.doNotDisturb, c.bypassQuietHours, settings, c.now); actual != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/viper"
+	"nkonev.name/notification/db"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/logger"
+)
+
+const WebPushUrgencyNormal = "normal"
+const WebPushUrgencyHigh = "high"
+
+// the payload together with the padding delimiter and the tag of aes-gcm should fit into one record
+const webPushRecordSize = 4096
+const webPushMaxPayloadSize = webPushRecordSize - 16 - 1
+
+const webPushVapidExpiration = 12 * time.Hour
+
+var errWebPushSubscriptionGone = errors.New("web push subscription is gone")
+var ErrWebPushEndpointNotAllowed = errors.New("web push endpoint isn't allowed")
+
+// the carrier-grade nat, it isn't covered by net.IP.IsPrivate
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// sends the encrypted notifications to the push services of the browsers, see RFC 8030, RFC 8291 and RFC 8292
+type WebPushService struct {
+	dbs            *db.DB
+	lgr            *logger.Logger
+	client         *http.Client
+	vapidKey       *ecdsa.PrivateKey
+	vapidPublicKey string
+}
+
+func NewWebPushService(dbs *db.DB, lgr *logger.Logger) (*WebPushService, error) {
+	s := &WebPushService{
+		dbs: dbs,
+		lgr: lgr,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext:        (&net.Dialer{Control: denyInternalAddress}).DialContext,
+				MaxIdleConns:       viper.GetInt("http.maxIdleConns"),
+				IdleConnTimeout:    viper.GetDuration("http.idleConnTimeout"),
+				DisableCompression: viper.GetBool("http.disableCompression"),
+			},
+			Timeout: viper.GetDuration("webPush.timeout"),
+		},
+	}
+	if !s.Enabled() {
+		return s, nil
+	}
+
+	privateKey, err := base64.RawURLEncoding.DecodeString(viper.GetString("webPush.vapidPrivateKey"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode vapid private key: %w", err)
+	}
+	s.vapidKey, err = ecdsa.ParseRawPrivateKey(elliptic.P256(), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse vapid private key: %w", err)
+	}
+	publicKey, err := s.vapidKey.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	s.vapidPublicKey = base64.RawURLEncoding.EncodeToString(publicKey)
+	return s, nil
+}
+
+func (s *WebPushService) Enabled() bool {
+	return viper.GetBool("webPush.enabled")
+}
+
+// the browser needs it as applicationServerKey in order to subscribe
+func (s *WebPushService) VapidPublicKey() string {
+	return s.vapidPublicKey
+}
+
+// sends to all the devices of the user, the expired subscriptions are removed
+func (s *WebPushService) Push(ctx context.Context, userId int64, payload *dto.WebPushPayload, ttl time.Duration, urgency string) {
+	if !s.Enabled() {
+		return
+	}
+
+	subscriptions, err := s.dbs.GetWebPushSubscriptions(ctx, userId)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get web push subscriptions of user %v: %v", userId, err)
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	message, err := json.Marshal(payload)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to marshal web push payload %v", err)
+		return
+	}
+	if len(message) > webPushMaxPayloadSize {
+		s.lgr.WithTracing(ctx).Errorf("Web push payload of user %v is too big: %v bytes", userId, len(message))
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		// the subscription could be saved before the endpoints were checked
+		if ValidateWebPushEndpoint(subscription.Endpoint) != nil {
+			s.lgr.WithTracing(ctx).Warnf("Removing web push subscription of user %v with not allowed endpoint", userId)
+			err = s.dbs.DeleteWebPushSubscriptionByEndpoint(ctx, subscription.Endpoint)
+			if err != nil {
+				s.lgr.WithTracing(ctx).Errorf("Unable to remove web push subscription %v", err)
+			}
+			continue
+		}
+		err = s.send(ctx, &subscription, message, payload.Tag, ttl, urgency)
+		if errors.Is(err, errWebPushSubscriptionGone) {
+			s.lgr.WithTracing(ctx).Infof("Removing expired web push subscription of user %v", userId)
+			err = s.dbs.DeleteWebPushSubscriptionByEndpoint(ctx, subscription.Endpoint)
+			if err != nil {
+				s.lgr.WithTracing(ctx).Errorf("Unable to remove web push subscription %v", err)
+			}
+		} else if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to send web push to user %v: %v", userId, err)
+		}
+	}
+}
+
+func (s *WebPushService) send(ctx context.Context, subscription *dto.WebPushSubscription, message []byte, topic string, ttl time.Duration, urgency string) error {
+	body, err := encryptWebPush(subscription, message)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(subscription.Endpoint)
+	if err != nil {
+		return err
+	}
+	authorization, err := s.vapidAuthorization(endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Urgency", urgency)
+	req.Header.Set("Authorization", authorization)
+	if topic != "" {
+		// the undelivered message with the same topic is replaced
+		req.Header.Set("Topic", topic)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return errWebPushSubscriptionGone
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("push service responded with status %v", resp.StatusCode)
+	default:
+		return nil
+	}
+}
+
+// the pushes are sent to the url which is given by the user, so it should belong to one of the push services of the browsers
+func ValidateWebPushEndpoint(endpoint string) error {
+	endpointUrl, err := url.Parse(endpoint)
+	if err != nil || endpointUrl.Scheme != "https" || endpointUrl.User != nil || (endpointUrl.Port() != "" && endpointUrl.Port() != "443") {
+		return ErrWebPushEndpointNotAllowed
+	}
+	host := strings.ToLower(endpointUrl.Hostname())
+	if _, err := netip.ParseAddr(host); err == nil {
+		return ErrWebPushEndpointNotAllowed
+	}
+	for _, allowed := range viper.GetStringSlice("webPush.allowedHosts") {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return ErrWebPushEndpointNotAllowed
+}
+
+// the allowed host could be resolved to the internal address, so the address is checked right before the connecting
+func denyInternalAddress(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	addr := addrPort.Addr().Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsMulticast() || addr.IsUnspecified() || sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("%w: %v", ErrWebPushEndpointNotAllowed, addr)
+	}
+	return nil
+}
+
+// RFC 8292, the push service checks that the sender is the one the browser subscribed to
+func (s *WebPushService) vapidAuthorization(endpoint *url.URL) (string, error) {
+	header, err := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": time.Now().Add(webPushVapidExpiration).Unix(),
+		"sub": viper.GetString("webPush.subject"),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(unsigned))
+	r, sg, err := ecdsa.Sign(rand.Reader, s.vapidKey, hash[:])
+	if err != nil {
+		return "", err
+	}
+	// JWS requires the fixed size r || s instead of ASN.1
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	sg.FillBytes(signature[32:])
+
+	token := unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+	return "vapid t=" + token + ", k=" + s.vapidPublicKey, nil
+}
+
+// RFC 8291, the payload is encrypted with the key which is known only to the browser
+func encryptWebPush(subscription *dto.WebPushSubscription, message []byte) ([]byte, error) {
+	uaPublicBytes, err := decodeWebPushKey(subscription.Keys.P256dh)
+	if err != nil {
+		return nil, err
+	}
+	authSecret, err := decodeWebPushKey(subscription.Keys.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	// every message has its own key and salt
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encryptWebPushWith(uaPublicBytes, authSecret, asPrivate, salt, message)
+}
+
+// the key and the salt are given, so the result can be checked against the example of RFC 8291
+func encryptWebPushWith(uaPublicBytes, authSecret []byte, asPrivate *ecdh.PrivateKey, salt, message []byte) ([]byte, error) {
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, err
+	}
+	asPublicBytes := asPrivate.PublicKey().Bytes()
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	// the auth secret is mixed in
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaPublicBytes...), asPublicBytes...)
+	prkKey := hmacSha256(authSecret, ecdhSecret)
+	ikm := hmacSha256(prkKey, append(keyInfo, 0x01))
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// the delimiter of the last record
+	plaintext := append(append([]byte{}, message...), 0x02)
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+
+	// the header is salt || rs || idlen || keyid
+	header := make([]byte, 0, 16+4+1+len(asPublicBytes))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublicBytes)))
+	header = append(header, asPublicBytes...)
+	return append(header, ciphertext...), nil
+}
+
+// the browsers use base64url without padding, but the padded one is also seen
+func decodeWebPushKey(key string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+}
+
+func hmacSha256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
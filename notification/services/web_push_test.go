@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"nkonev.name/notification/dto"
+	"nkonev.name/notification/logger"
+)
+
+func decodeTestBase64(t *testing.T, s string) []byte {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// the example of RFC 8291, section 5
+func TestEncryptWebPushRfc8291Example(t *testing.T) {
+	asPrivate, err := ecdh.P256().NewPrivateKey(decodeTestBase64(t, "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asPublic := base64.RawURLEncoding.EncodeToString(asPrivate.PublicKey().Bytes()); asPublic != "BP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A8" {
+		t.Fatalf("unexpected public key of the application server %v", asPublic)
+	}
+
+	result, err := encryptWebPushWith(
+		decodeTestBase64(t, "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"),
+		decodeTestBase64(t, "BTBZMqHH6r4Tts7J_aSIgg"),
+		asPrivate,
+		decodeTestBase64(t, "DGv6ra1nlYgDCS1FRnbzlw"),
+		[]byte("When I grow up, I want to be a watermelon"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+	if actual := base64.RawURLEncoding.EncodeToString(result); actual != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, actual)
+	}
+}
+
+func TestEncryptWebPushUsesNewKeyEveryTime(t *testing.T) {
+	subscription := &dto.WebPushSubscription{}
+	subscription.Keys.P256dh = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	// the padded one is also accepted
+	subscription.Keys.Auth = "BTBZMqHH6r4Tts7J_aSIgg=="
+
+	first, err := encryptWebPush(subscription, []byte("message"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := encryptWebPush(subscription, []byte("message"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// salt(16) || rs(4) || idlen(1) || keyid(65) || ciphertext with the delimiter and the tag
+	if len(first) != 16+4+1+65+len("message")+1+16 {
+		t.Errorf("unexpected length %v", len(first))
+	}
+	if string(first[:16]) == string(second[:16]) || string(first[21:86]) == string(second[21:86]) {
+		t.Error("the salt and the key should be new for every message")
+	}
+
+	subscription.Keys.P256dh = "not a key"
+	if _, err := encryptWebPush(subscription, []byte("message")); err == nil {
+		t.Error("expected an error for the invalid key")
+	}
+}
+
+func newTestWebPushService(t *testing.T) *WebPushService {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawKey, err := key.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("webPush.enabled", true)
+	viper.Set("webPush.vapidPrivateKey", base64.RawURLEncoding.EncodeToString(rawKey))
+	viper.Set("webPush.subject", "mailto:admin@example.com")
+	viper.Set("webPush.timeout", 5*time.Second)
+	t.Cleanup(func() {
+		viper.Set("webPush.enabled", false)
+	})
+
+	s, err := NewWebPushService(nil, logger.NewLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// checks the token like the push service does
+func verifyVapid(t *testing.T, authorization string, expectedAudience string) {
+	parameters := strings.TrimPrefix(authorization, "vapid ")
+	tokenAndKey := strings.SplitN(parameters, ", k=", 2)
+	if len(tokenAndKey) != 2 || !strings.HasPrefix(tokenAndKey[0], "t=") {
+		t.Fatalf("unexpected authorization %v", authorization)
+	}
+	token := strings.TrimPrefix(tokenAndKey[0], "t=")
+
+	publicKey, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), decodeTestBase64(t, tokenAndKey[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("unexpected token %v", token)
+	}
+	signature := decodeTestBase64(t, parts[2])
+	if len(signature) != 64 {
+		t.Fatalf("unexpected signature length %v", len(signature))
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !ecdsa.Verify(publicKey, hash[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+		t.Error("invalid signature")
+	}
+
+	var header map[string]string
+	if err := json.Unmarshal(decodeTestBase64(t, parts[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header["alg"] != "ES256" || header["typ"] != "JWT" {
+		t.Errorf("unexpected header %v", header)
+	}
+
+	var claims struct {
+		Aud string `json:"aud"`
+		Exp int64  `json:"exp"`
+		Sub string `json:"sub"`
+	}
+	if err := json.Unmarshal(decodeTestBase64(t, parts[1]), &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Aud != expectedAudience || claims.Sub != "mailto:admin@example.com" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	// RFC 8292 doesn't allow more than 24 hours
+	if expiresIn := time.Until(time.Unix(claims.Exp, 0)); expiresIn <= 0 || expiresIn > 24*time.Hour {
+		t.Errorf("unexpected expiration %v", expiresIn)
+	}
+}
+
+func TestVapidAuthorization(t *testing.T) {
+	s := newTestWebPushService(t)
+
+	endpoint, err := url.Parse("https://fcm.googleapis.com/fcm/send/abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorization, err := s.vapidAuthorization(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(authorization, ", k="+s.VapidPublicKey()) {
+		t.Errorf("the key should be the one the browser subscribed with")
+	}
+	verifyVapid(t, authorization, "https://fcm.googleapis.com")
+}
+
+func TestSendWebPush(t *testing.T) {
+	s := newTestWebPushService(t)
+
+	var status = http.StatusCreated
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	// the test server is on the loopback which is refused by the client of the service
+	s.client = server.Client()
+
+	subscription := &dto.WebPushSubscription{Endpoint: server.URL + "/push/abc"}
+	subscription.Keys.P256dh = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	subscription.Keys.Auth = "BTBZMqHH6r4Tts7J_aSIgg"
+
+	ctx := context.Background()
+	if err := s.send(ctx, subscription, []byte("message"), "call-1", 30*time.Second, WebPushUrgencyHigh); err != nil {
+		t.Fatal(err)
+	}
+	for header, expected := range map[string]string{
+		"Content-Encoding": "aes128gcm",
+		"Content-Type":     "application/octet-stream",
+		"Ttl":              "30",
+		"Urgency":          WebPushUrgencyHigh,
+		"Topic":            "call-1",
+	} {
+		if actual := received.Header.Get(header); actual != expected {
+			t.Errorf("expected %v header %v, got %v", header, expected, actual)
+		}
+	}
+	verifyVapid(t, received.Header.Get("Authorization"), server.URL)
+	if len(receivedBody) != 16+4+1+65+len("message")+1+16 {
+		t.Errorf("unexpected body length %v", len(receivedBody))
+	}
+
+	status = http.StatusGone
+	if err := s.send(ctx, subscription, []byte("message"), "", time.Minute, WebPushUrgencyNormal); !errors.Is(err, errWebPushSubscriptionGone) {
+		t.Errorf("expected %v, got %v", errWebPushSubscriptionGone, err)
+	}
+	if received.Header.Get("Topic") != "" {
+		t.Error("the empty topic shouldn't be sent")
+	}
+
+	status = http.StatusTooManyRequests
+	if err := s.send(ctx, subscription, []byte("message"), "", time.Minute, WebPushUrgencyNormal); err == nil || errors.Is(err, errWebPushSubscriptionGone) {
+		t.Errorf("expected the error, got %v", err)
+	}
+}
+
+func TestValidateWebPushEndpoint(t *testing.T) {
+	viper.Set("webPush.allowedHosts", []string{"fcm.googleapis.com", "notify.windows.com"})
+
+	cases := []struct {
+		endpoint string
+		allowed  bool
+	}{
+		{"https://fcm.googleapis.com/fcm/send/abc", true},
+		{"https://FCM.googleapis.com/fcm/send/abc", true},
+		{"https://fcm.googleapis.com:443/fcm/send/abc", true},
+		{"https://wns2-par02p.notify.windows.com/w/?token=abc", true},
+		{"http://fcm.googleapis.com/fcm/send/abc", false},
+		{"https://fcm.googleapis.com:8080/fcm/send/abc", false},
+		{"https://user@fcm.googleapis.com/fcm/send/abc", false},
+		{"https://fcm.googleapis.com.evil.example/abc", false},
+		{"https://evilnotify.windows.com/abc", false},
+		{"https://internal-service/abc", false},
+		{"https://127.0.0.1/abc", false},
+		{"https://[::1]/abc", false},
+		{"https://169.254.169.254/latest/meta-data", false},
+		{"not a url", false},
+		{"", false},
+	}
+	for _, c := range cases {
+		err := ValidateWebPushEndpoint(c.endpoint)
+		if c.allowed && err != nil {
+			t.Errorf("%v should be allowed, got %v", c.endpoint, err)
+		}
+		if !c.allowed && !errors.Is(err, ErrWebPushEndpointNotAllowed) {
+			t.Errorf("%v shouldn't be allowed, got %v", c.endpoint, err)
+		}
+	}
+}
+
+func TestSendWebPushRefusesInternalAddress(t *testing.T) {
+	s := newTestWebPushService(t)
+
+	var received bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	// the allowed host can be resolved to the internal address
+	subscription := &dto.WebPushSubscription{Endpoint: server.URL + "/push/abc"}
+	subscription.Keys.P256dh = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	subscription.Keys.Auth = "BTBZMqHH6r4Tts7J_aSIgg"
+	err := s.send(context.Background(), subscription, []byte("message"), "", time.Minute, WebPushUrgencyNormal)
+	if !errors.Is(err, ErrWebPushEndpointNotAllowed) {
+		t.Errorf("expected %v, got %v", ErrWebPushEndpointNotAllowed, err)
+	}
+	if received {
+		t.Error("the internal address shouldn't be connected to")
+	}
+
+	for _, address := range []string{"10.0.0.1:443", "192.168.1.1:443", "100.64.0.1:443", "[fd00::1]:443", "[::ffff:127.0.0.1]:443", "0.0.0.0:443"} {
+		if err := denyInternalAddress("tcp", address, nil); !errors.Is(err, ErrWebPushEndpointNotAllowed) {
+			t.Errorf("%v should be refused, got %v", address, err)
+		}
+	}
+	if err := denyInternalAddress("tcp", "142.250.74.42:443", nil); err != nil {
+		t.Errorf("the public address should be allowed, got %v", err)
+	}
+}
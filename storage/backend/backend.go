@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/tags"
+)
+
+const TypeMinio = "minio"
+const TypeLocal = "local"
+
+// https://min.io/docs/minio/linux/reference/minio-mc/mc-event-add.html#mc-event-supported-events
+// the local backend emits the same events as minio does, so the listener doesn't depend on the backend
+
+const ObjectCreated = "s3:ObjectCreated"
+const ObjectRemoved = "s3:ObjectRemoved"
+
+const ObjectCreatedCompleteMultipartUpload = ObjectCreated + ":CompleteMultipartUpload"
+
+const ObjectRemovedDelete = ObjectRemoved + ":Delete"
+
+const ObjectCreatedPutTagging = ObjectCreated + ":PutTagging"
+const ObjectCreatedPut = ObjectCreated + ":Put"
+const ObjectCreatedCopy = ObjectCreated + ":Copy"
+
+// the user metadata has this prefix in the listings and in the events, but not in the stat
+const XAmzMetaPrefix = "X-Amz-Meta-"
+
+// the content of the object, the reading from minio is lazy, so the errors can appear only during reading
+type Object interface {
+	io.ReadSeekCloser
+	Stat() (minio.ObjectInfo, error)
+}
+
+type CompletedPart struct {
+	PartNumber int64
+	ETag       string
+}
+
+// the change of the object in the bucket subscribed via SubscribeEvents
+type Event struct {
+	EventName    string
+	Bucket       string
+	Key          string
+	Size         int64
+	ETag         string
+	ContentType  string
+	UserMetadata map[string]string // with XAmzMetaPrefix
+}
+
+// delivers the events of the local backend to the same place where minio sends its notifications
+type EventPublisher interface {
+	PublishStorageEvent(ctx context.Context, event Event) error
+}
+
+// the storage of the objects
+// the option structs of minio are used as the plain data in order not to duplicate them, the local backend ignores the fields it doesn't support
+type Backend interface {
+	BucketExists(ctx context.Context, bucketName string) (bool, error)
+	MakeBucket(ctx context.Context, bucketName string, opts minio.MakeBucketOptions) error
+	// the events of the bucket are delivered to the listener, see listener.CreateMinioEventsChannel
+	SubscribeEvents(ctx context.Context, bucketName string) error
+
+	FileExists(ctx context.Context, bucketName, key string) (bool, *minio.ObjectInfo, error)
+	StatObject(ctx context.Context, bucketName, key string, opts minio.StatObjectOptions) (minio.ObjectInfo, error)
+	ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo
+
+	GetObject(ctx context.Context, bucketName, key string, opts minio.GetObjectOptions) (Object, error)
+	PutObject(ctx context.Context, bucketName, key string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error)
+	FPutObject(ctx context.Context, bucketName, key, filePath string, opts minio.PutObjectOptions) (minio.UploadInfo, error)
+	CopyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (minio.UploadInfo, error)
+	RemoveObject(ctx context.Context, bucketName, key string, opts minio.RemoveObjectOptions) error
+
+	GetObjectTagging(ctx context.Context, bucketName, key string, opts minio.GetObjectTaggingOptions) (*tags.Tags, error)
+	PutObjectTagging(ctx context.Context, bucketName, key string, otags *tags.Tags, opts minio.PutObjectTaggingOptions) error
+
+	// for the processes of this service, e.g. ffmpeg
+	PresignedGetObject(ctx context.Context, bucketName, key string, expiry time.Duration, reqParams url.Values) (*url.URL, error)
+	// for the browser, the url is relative
+	PresignedExternalGetObject(ctx context.Context, bucketName, key string, expiry time.Duration, reqParams url.Values) (string, error)
+
+	// the metadata is without XAmzMetaPrefix
+	CreateMultipartUpload(ctx context.Context, bucketName, key string, metadata map[string]string, expires time.Time) (string, error)
+	// for the browser, the url is relative, the response contains the ETag header
+	PresignedExternalUploadPart(ctx context.Context, bucketName, key, uploadId string, partNumber int, expiry time.Duration) (string, error)
+	// returns ETag of the part
+	UploadPart(ctx context.Context, bucketName, key, uploadId string, partNumber int64, data []byte) (string, error)
+	CompleteMultipartUpload(ctx context.Context, bucketName, key, uploadId string, parts []CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, bucketName, key, uploadId string) error
+}
@@ -0,0 +1,900 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/tags"
+	"nkonev.name/storage/logger"
+)
+
+// served by handlers.LocalBackendHandler, they are under the public prefix because the signature is the authorization
+const LocalUrlObject = "/api/storage/public/local/object"
+const LocalUrlPart = "/api/storage/public/local/part"
+
+const localParamBucket = "bucket"
+const localParamKey = "key"
+const localParamUploadId = "uploadId"
+const localParamPartNumber = "partNumber"
+const localParamExpires = "expires"
+const localParamSignature = "signature"
+
+const LocalParamResponseContentType = "response-content-type"
+const LocalParamResponseContentDisposition = "response-content-disposition"
+
+// the service directories are hidden in order not to clash with the buckets
+const localMetaDir = ".meta"
+const localMultipartDir = ".multipart"
+const localTmpDir = ".tmp"
+
+const localDefaultContentType = "application/octet-stream"
+
+var ErrLocalInvalidName = errors.New("invalid bucket or object name")
+var ErrLocalInvalidSignature = errors.New("invalid signature")
+var ErrLocalExpiredSignature = errors.New("expired signature")
+
+// keeps the objects as the plain files <root>/<bucket>/<key>, the content type, the user metadata and the tags are kept aside in <root>/.meta/<bucket>/<key>.json
+// it's intended for the single instance installations
+type LocalBackend struct {
+	root       string
+	signingKey []byte
+	publisher  EventPublisher
+	lgr        *logger.Logger
+
+	// the read-modify-write of the metadata
+	metaLock sync.Mutex
+
+	subscribedLock sync.RWMutex
+	subscribed     map[string]bool
+}
+
+type localMetadata struct {
+	ContentType  string            `json:"contentType"`
+	ETag         string            `json:"etag"`
+	UserMetadata map[string]string `json:"userMetadata"` // without XAmzMetaPrefix
+	Tags         map[string]string `json:"tags"`
+}
+
+type localMultipartUpload struct {
+	Bucket       string            `json:"bucket"`
+	Key          string            `json:"key"`
+	UserMetadata map[string]string `json:"userMetadata"`
+	Expires      time.Time         `json:"expires"`
+}
+
+type localObject struct {
+	*os.File
+	info minio.ObjectInfo
+}
+
+func (o *localObject) Stat() (minio.ObjectInfo, error) {
+	return o.info, nil
+}
+
+func NewLocalBackend(lgr *logger.Logger, root, signingKey string, publisher EventPublisher) (*LocalBackend, error) {
+	if len(signingKey) == 0 {
+		return nil, errors.New("signing key of the local backend is empty")
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range []string{absRoot, filepath.Join(absRoot, localMetaDir), filepath.Join(absRoot, localMultipartDir), filepath.Join(absRoot, localTmpDir)} {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, err
+		}
+	}
+	return &LocalBackend{
+		root:       absRoot,
+		signingKey: []byte(signingKey),
+		publisher:  publisher,
+		lgr:        lgr,
+		subscribed: map[string]bool{},
+	}, nil
+}
+
+func noSuchKey(bucketName, key string) error {
+	return minio.ErrorResponse{
+		Code:       "NoSuchKey",
+		Message:    "The specified key does not exist.",
+		BucketName: bucketName,
+		Key:        key,
+		StatusCode: http.StatusNotFound,
+	}
+}
+
+func noSuchUpload(bucketName, key string) error {
+	return minio.ErrorResponse{
+		Code:       "NoSuchUpload",
+		Message:    "The specified multipart upload does not exist.",
+		BucketName: bucketName,
+		Key:        key,
+		StatusCode: http.StatusNotFound,
+	}
+}
+
+func isValidBucketName(bucketName string) bool {
+	return len(bucketName) > 0 && !strings.HasPrefix(bucketName, ".") && !strings.ContainsAny(bucketName, "/\\")
+}
+
+// the key should not escape the bucket
+func isValidKey(key string) bool {
+	if len(key) == 0 || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *LocalBackend) bucketPath(bucketName string) (string, error) {
+	if !isValidBucketName(bucketName) {
+		return "", ErrLocalInvalidName
+	}
+	return filepath.Join(b.root, bucketName), nil
+}
+
+func (b *LocalBackend) objectPath(bucketName, key string) (string, error) {
+	if !isValidBucketName(bucketName) || !isValidKey(key) {
+		return "", ErrLocalInvalidName
+	}
+	return filepath.Join(b.root, bucketName, filepath.FromSlash(key)), nil
+}
+
+func (b *LocalBackend) metadataPath(bucketName, key string) string {
+	return filepath.Join(b.root, localMetaDir, bucketName, filepath.FromSlash(key)+".json")
+}
+
+func (b *LocalBackend) uploadPath(uploadId string) (string, error) {
+	if _, err := uuid.Parse(uploadId); err != nil {
+		return "", ErrLocalInvalidName
+	}
+	return filepath.Join(b.root, localMultipartDir, uploadId), nil
+}
+
+func normalizeUserMetadata(userMetadata map[string]string) map[string]string {
+	res := map[string]string{}
+	for k, v := range userMetadata {
+		canonical := http.CanonicalHeaderKey(k)
+		res[strings.TrimPrefix(canonical, XAmzMetaPrefix)] = v
+	}
+	return res
+}
+
+func prefixUserMetadata(userMetadata map[string]string) map[string]string {
+	res := map[string]string{}
+	for k, v := range userMetadata {
+		res[XAmzMetaPrefix+k] = v
+	}
+	return res
+}
+
+// the file is written to the temporary directory and then renamed, so the readers never see the partially written file
+// returns the path of the temporary file, its size and md5
+func (b *LocalBackend) writeTemp(reader io.Reader) (string, int64, string, error) {
+	tmp, err := os.CreateTemp(filepath.Join(b.root, localTmpDir), "object-*")
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(tmp, hash), reader)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", 0, "", err
+	}
+	return tmp.Name(), written, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func moveInto(tmpPath, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, target)
+}
+
+func (b *LocalBackend) writeFileAtomically(target string, reader io.Reader) (int64, string, error) {
+	tmpPath, written, etag, err := b.writeTemp(reader)
+	if err != nil {
+		return 0, "", err
+	}
+	if err := moveInto(tmpPath, target); err != nil {
+		os.Remove(tmpPath)
+		return 0, "", err
+	}
+	return written, etag, nil
+}
+
+func (b *LocalBackend) readMetadata(bucketName, key string) (*localMetadata, error) {
+	data, err := os.ReadFile(b.metadataPath(bucketName, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &localMetadata{ContentType: localDefaultContentType, UserMetadata: map[string]string{}, Tags: map[string]string{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	md := new(localMetadata)
+	err = json.Unmarshal(data, md)
+	if err != nil {
+		return nil, err
+	}
+	if md.UserMetadata == nil {
+		md.UserMetadata = map[string]string{}
+	}
+	if md.Tags == nil {
+		md.Tags = map[string]string{}
+	}
+	return md, nil
+}
+
+func (b *LocalBackend) writeMetadata(bucketName, key string, md *localMetadata) error {
+	data, err := json.Marshal(md)
+	if err != nil {
+		return err
+	}
+	_, _, err = b.writeFileAtomically(b.metadataPath(bucketName, key), bytes.NewReader(data))
+	return err
+}
+
+// removes the empty directories up to the stop one
+func removeEmptyParents(dir, stop string) {
+	for dir != stop && strings.HasPrefix(dir, stop) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func (b *LocalBackend) stat(bucketName, key string, withPrefixedMetadata bool) (minio.ObjectInfo, error) {
+	objectPath, err := b.objectPath(bucketName, key)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+	fileInfo, err := os.Stat(objectPath)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && fileInfo.IsDir()) {
+		return minio.ObjectInfo{}, noSuchKey(bucketName, key)
+	} else if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+	md, err := b.readMetadata(bucketName, key)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	// the same as minio does
+	userMetadata := md.UserMetadata
+	if withPrefixedMetadata {
+		userMetadata = prefixUserMetadata(md.UserMetadata)
+		userMetadata["content-type"] = md.ContentType
+	}
+	return minio.ObjectInfo{
+		Key:          key,
+		Size:         fileInfo.Size(),
+		LastModified: fileInfo.ModTime().UTC(),
+		ETag:         md.ETag,
+		ContentType:  md.ContentType,
+		UserMetadata: userMetadata,
+		UserTags:     md.Tags,
+	}, nil
+}
+
+func (b *LocalBackend) SubscribeEvents(ctx context.Context, bucketName string) error {
+	b.subscribedLock.Lock()
+	defer b.subscribedLock.Unlock()
+	b.subscribed[bucketName] = true
+	return nil
+}
+
+// the object is already changed, so the error of publishing is just logged, the scheduled actualization fixes the consequences
+func (b *LocalBackend) emit(ctx context.Context, eventName, bucketName, key string, info *minio.ObjectInfo) {
+	b.subscribedLock.RLock()
+	subscribed := b.subscribed[bucketName]
+	b.subscribedLock.RUnlock()
+	if !subscribed || b.publisher == nil {
+		return
+	}
+
+	event := Event{
+		EventName:    eventName,
+		Bucket:       bucketName,
+		Key:          key,
+		UserMetadata: map[string]string{},
+	}
+	if info != nil {
+		event.Size = info.Size
+		event.ETag = info.ETag
+		event.ContentType = info.ContentType
+		event.UserMetadata = prefixUserMetadata(info.UserMetadata)
+	}
+	if err := b.publisher.PublishStorageEvent(ctx, event); err != nil {
+		b.lgr.WithTracing(ctx).Errorf("Error during publishing event %v for %v/%v: %v", eventName, bucketName, key, err)
+	}
+}
+
+func (b *LocalBackend) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	bucketPath, err := b.bucketPath(bucketName)
+	if err != nil {
+		return false, err
+	}
+	fileInfo, err := os.Stat(bucketPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return fileInfo.IsDir(), nil
+}
+
+func (b *LocalBackend) MakeBucket(ctx context.Context, bucketName string, opts minio.MakeBucketOptions) error {
+	bucketPath, err := b.bucketPath(bucketName)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(bucketPath, 0o750)
+}
+
+func (b *LocalBackend) FileExists(ctx context.Context, bucketName, key string) (bool, *minio.ObjectInfo, error) {
+	objectInfo, err := b.stat(bucketName, key, false)
+	if err != nil {
+		if errTyped, ok := err.(minio.ErrorResponse); ok && errTyped.Code == "NoSuchKey" {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	return true, &objectInfo, nil
+}
+
+func (b *LocalBackend) StatObject(ctx context.Context, bucketName, key string, opts minio.StatObjectOptions) (minio.ObjectInfo, error) {
+	return b.stat(bucketName, key, false)
+}
+
+// the non-recursive listing returns the directories as the objects with the trailing slash, the same as minio does
+func (b *LocalBackend) ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	ch := make(chan minio.ObjectInfo, 1)
+	go func() {
+		defer close(ch)
+		send := func(info minio.ObjectInfo) bool {
+			select {
+			case ch <- info:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		bucketPath, err := b.bucketPath(bucketName)
+		if err != nil {
+			send(minio.ObjectInfo{Err: err})
+			return
+		}
+		// the listing starts from the deepest directory of the prefix
+		dirKey := opts.Prefix[:strings.LastIndex(opts.Prefix, "/")+1]
+		if len(dirKey) > 0 && !isValidKey(strings.TrimSuffix(dirKey, "/")) {
+			send(minio.ObjectInfo{Err: ErrLocalInvalidName})
+			return
+		}
+		startDir := filepath.Join(bucketPath, filepath.FromSlash(dirKey))
+
+		if !opts.Recursive {
+			entries, err := os.ReadDir(startDir)
+			if errors.Is(err, fs.ErrNotExist) {
+				return
+			} else if err != nil {
+				send(minio.ObjectInfo{Err: err})
+				return
+			}
+			for _, entry := range entries {
+				key := dirKey + entry.Name()
+				if !strings.HasPrefix(key, opts.Prefix) {
+					continue
+				}
+				var info minio.ObjectInfo
+				if entry.IsDir() {
+					info = minio.ObjectInfo{Key: key + "/"}
+				} else if info, err = b.stat(bucketName, key, opts.WithMetadata); err != nil {
+					continue
+				}
+				if !send(info) {
+					return
+				}
+			}
+			return
+		}
+
+		err = filepath.WalkDir(startDir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(bucketPath, p)
+			if err != nil {
+				return err
+			}
+			key := filepath.ToSlash(rel)
+			if !strings.HasPrefix(key, opts.Prefix) {
+				return nil
+			}
+			info, err := b.stat(bucketName, key, opts.WithMetadata)
+			if err != nil {
+				// removed concurrently
+				return nil
+			}
+			if !send(info) {
+				return filepath.SkipAll
+			}
+			return nil
+		})
+		if err != nil {
+			send(minio.ObjectInfo{Err: err})
+		}
+	}()
+	return ch
+}
+
+func (b *LocalBackend) GetObject(ctx context.Context, bucketName, key string, opts minio.GetObjectOptions) (Object, error) {
+	info, err := b.stat(bucketName, key, false)
+	if err != nil {
+		return nil, err
+	}
+	objectPath, err := b.objectPath(bucketName, key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(objectPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, noSuchKey(bucketName, key)
+	} else if err != nil {
+		return nil, err
+	}
+	return &localObject{File: file, info: info}, nil
+}
+
+func (b *LocalBackend) put(ctx context.Context, eventName, bucketName, key string, reader io.Reader, objectSize int64, md *localMetadata) (minio.UploadInfo, error) {
+	objectPath, err := b.objectPath(bucketName, key)
+	if err != nil {
+		return minio.UploadInfo{}, err
+	}
+	if objectSize >= 0 {
+		reader = io.LimitReader(reader, objectSize)
+	}
+
+	tmpPath, written, etag, err := b.writeTemp(reader)
+	if err != nil {
+		return minio.UploadInfo{}, err
+	}
+
+	// the object and its metadata are replaced together
+	b.metaLock.Lock()
+	err = moveInto(tmpPath, objectPath)
+	if err != nil {
+		os.Remove(tmpPath)
+	} else {
+		md.ETag = etag
+		err = b.writeMetadata(bucketName, key, md)
+	}
+	b.metaLock.Unlock()
+	if err != nil {
+		return minio.UploadInfo{}, err
+	}
+	if objectSize >= 0 && written != objectSize {
+		b.lgr.WithTracing(ctx).Warnf("Expected %v bytes for %v/%v, but got %v", objectSize, bucketName, key, written)
+	}
+
+	info, err := b.stat(bucketName, key, false)
+	if err != nil {
+		return minio.UploadInfo{}, err
+	}
+	b.emit(ctx, eventName, bucketName, key, &info)
+	return minio.UploadInfo{Bucket: bucketName, Key: key, ETag: etag, Size: written, LastModified: info.LastModified}, nil
+}
+
+func contentTypeOrDefault(contentType, key string) string {
+	if len(contentType) > 0 {
+		return contentType
+	}
+	if byExtension := mime.TypeByExtension(path.Ext(key)); len(byExtension) > 0 {
+		return byExtension
+	}
+	return localDefaultContentType
+}
+
+func (b *LocalBackend) PutObject(ctx context.Context, bucketName, key string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+	return b.put(ctx, ObjectCreatedPut, bucketName, key, reader, objectSize, &localMetadata{
+		ContentType:  contentTypeOrDefault(opts.ContentType, key),
+		UserMetadata: normalizeUserMetadata(opts.UserMetadata),
+		Tags:         opts.UserTags,
+	})
+}
+
+func (b *LocalBackend) FPutObject(ctx context.Context, bucketName, key, filePath string, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return minio.UploadInfo{}, err
+	}
+	defer file.Close()
+	return b.put(ctx, ObjectCreatedPut, bucketName, key, file, -1, &localMetadata{
+		ContentType:  contentTypeOrDefault(opts.ContentType, filePath),
+		UserMetadata: normalizeUserMetadata(opts.UserMetadata),
+		Tags:         opts.UserTags,
+	})
+}
+
+// the tags are copied together with the content unless they are replaced, the same as in S3
+func (b *LocalBackend) CopyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (minio.UploadInfo, error) {
+	object, err := b.GetObject(ctx, src.Bucket, src.Object, minio.GetObjectOptions{})
+	if err != nil {
+		return minio.UploadInfo{}, err
+	}
+	defer object.Close()
+	srcMd, err := b.readMetadata(src.Bucket, src.Object)
+	if err != nil {
+		return minio.UploadInfo{}, err
+	}
+
+	md := &localMetadata{
+		ContentType:  srcMd.ContentType,
+		UserMetadata: srcMd.UserMetadata,
+		Tags:         srcMd.Tags,
+	}
+	if dst.ReplaceMetadata {
+		md.UserMetadata = normalizeUserMetadata(dst.UserMetadata)
+	}
+	if dst.ReplaceTags {
+		md.Tags = dst.UserTags
+	}
+	return b.put(ctx, ObjectCreatedCopy, dst.Bucket, dst.Object, object, -1, md)
+}
+
+// the absence of the object isn't an error, the same as in S3.
+// ForceDelete removes all the objects with the key as the prefix, the same as minio does
+func (b *LocalBackend) RemoveObject(ctx context.Context, bucketName, key string, opts minio.RemoveObjectOptions) error {
+	if opts.ForceDelete {
+		return b.removePrefix(ctx, bucketName, key)
+	}
+	objectPath, err := b.objectPath(bucketName, key)
+	if err != nil {
+		return err
+	}
+	b.metaLock.Lock()
+	err = os.Remove(objectPath)
+	if err == nil {
+		metadataPath := b.metadataPath(bucketName, key)
+		os.Remove(metadataPath)
+		removeEmptyParents(filepath.Dir(metadataPath), filepath.Join(b.root, localMetaDir, bucketName))
+		removeEmptyParents(filepath.Dir(objectPath), filepath.Join(b.root, bucketName))
+	}
+	b.metaLock.Unlock()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	b.emit(ctx, ObjectRemovedDelete, bucketName, key, nil)
+	return nil
+}
+
+func (b *LocalBackend) removePrefix(ctx context.Context, bucketName, prefix string) error {
+	// the listing is finished before the removing, so the walk doesn't see the removed directories
+	keys := []string{}
+	for info := range b.ListObjects(ctx, bucketName, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return info.Err
+		}
+		keys = append(keys, info.Key)
+	}
+	for _, key := range keys {
+		err := b.RemoveObject(ctx, bucketName, key, minio.RemoveObjectOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *LocalBackend) GetObjectTagging(ctx context.Context, bucketName, key string, opts minio.GetObjectTaggingOptions) (*tags.Tags, error) {
+	info, err := b.stat(bucketName, key, false)
+	if err != nil {
+		return nil, err
+	}
+	return tags.MapToObjectTags(info.UserTags)
+}
+
+func (b *LocalBackend) PutObjectTagging(ctx context.Context, bucketName, key string, otags *tags.Tags, opts minio.PutObjectTaggingOptions) error {
+	b.metaLock.Lock()
+	_, err := b.stat(bucketName, key, false)
+	if err == nil {
+		var md *localMetadata
+		md, err = b.readMetadata(bucketName, key)
+		if err == nil {
+			md.Tags = otags.ToMap()
+			err = b.writeMetadata(bucketName, key, md)
+		}
+	}
+	b.metaLock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	info, err := b.stat(bucketName, key, false)
+	if err != nil {
+		return err
+	}
+	b.emit(ctx, ObjectCreatedPutTagging, bucketName, key, &info)
+	return nil
+}
+
+// ffmpeg reads the file directly, the opaque form is used because ffmpeg doesn't unescape the path
+func (b *LocalBackend) PresignedGetObject(ctx context.Context, bucketName, key string, expiry time.Duration, reqParams url.Values) (*url.URL, error) {
+	objectPath, err := b.objectPath(bucketName, key)
+	if err != nil {
+		return nil, err
+	}
+	return &url.URL{Scheme: "file", Opaque: objectPath}, nil
+}
+
+func (b *LocalBackend) sign(method, urlPath string, query url.Values) string {
+	mac := hmac.New(sha256.New, b.signingKey)
+	mac.Write([]byte(method + "\n" + urlPath + "\n" + query.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (b *LocalBackend) signedUrl(method, urlPath string, query url.Values, expiry time.Duration) string {
+	query.Set(localParamExpires, strconv.FormatInt(time.Now().Add(expiry).Unix(), 10))
+	query.Set(localParamSignature, b.sign(method, urlPath, query))
+	return urlPath + "?" + query.Encode()
+}
+
+// the query is signed as a whole, so none of the parameters can be changed
+func (b *LocalBackend) VerifySignedUrl(method, urlPath string, query url.Values) error {
+	signed := url.Values{}
+	for k, v := range query {
+		if k != localParamSignature {
+			signed[k] = v
+		}
+	}
+	expected := b.sign(method, urlPath, signed)
+	if !hmac.Equal([]byte(expected), []byte(query.Get(localParamSignature))) {
+		return ErrLocalInvalidSignature
+	}
+	expires, err := strconv.ParseInt(query.Get(localParamExpires), 10, 64)
+	if err != nil {
+		return ErrLocalInvalidSignature
+	}
+	if time.Now().Unix() > expires {
+		return ErrLocalExpiredSignature
+	}
+	return nil
+}
+
+func (b *LocalBackend) PresignedExternalGetObject(ctx context.Context, bucketName, key string, expiry time.Duration, reqParams url.Values) (string, error) {
+	if _, err := b.objectPath(bucketName, key); err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	for k, v := range reqParams {
+		query[k] = v
+	}
+	query.Set(localParamBucket, bucketName)
+	query.Set(localParamKey, key)
+	return b.signedUrl(http.MethodGet, LocalUrlObject, query, expiry), nil
+}
+
+// returns bucket and key of the verified url
+func LocalObjectFromUrl(query url.Values) (string, string) {
+	return query.Get(localParamBucket), query.Get(localParamKey)
+}
+
+// returns bucket, key, upload id and part number of the verified url
+func LocalPartFromUrl(query url.Values) (string, string, string, int64, error) {
+	partNumber, err := strconv.ParseInt(query.Get(localParamPartNumber), 10, 64)
+	if err != nil {
+		return "", "", "", 0, err
+	}
+	return query.Get(localParamBucket), query.Get(localParamKey), query.Get(localParamUploadId), partNumber, nil
+}
+
+// the default disposition makes the browser to use the original filename instead of the last segment of the url
+func LocalContentDisposition(query url.Values, key string) string {
+	if disposition := query.Get(LocalParamResponseContentDisposition); len(disposition) > 0 {
+		return disposition
+	}
+	return mime.FormatMediaType("inline", map[string]string{"filename": path.Base(key)})
+}
+
+func (b *LocalBackend) removeExpiredUploads() {
+	multipartPath := filepath.Join(b.root, localMultipartDir)
+	entries, err := os.ReadDir(multipartPath)
+	if err != nil {
+		b.lgr.Errorf("Error during listing multipart uploads: %v", err)
+		return
+	}
+	for _, entry := range entries {
+		upload, err := b.readUpload(entry.Name())
+		if err == nil && time.Now().After(upload.Expires) {
+			b.lgr.Infof("Removing expired multipart upload %v of %v/%v", entry.Name(), upload.Bucket, upload.Key)
+			os.RemoveAll(filepath.Join(multipartPath, entry.Name()))
+		}
+	}
+}
+
+func (b *LocalBackend) readUpload(uploadId string) (*localMultipartUpload, error) {
+	uploadPath, err := b.uploadPath(uploadId)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(uploadPath, "upload.json"))
+	if err != nil {
+		return nil, err
+	}
+	upload := new(localMultipartUpload)
+	err = json.Unmarshal(data, upload)
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// the upload should belong to the object
+func (b *LocalBackend) getUpload(bucketName, key, uploadId string) (string, *localMultipartUpload, error) {
+	uploadPath, err := b.uploadPath(uploadId)
+	if err != nil {
+		return "", nil, err
+	}
+	upload, err := b.readUpload(uploadId)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil, noSuchUpload(bucketName, key)
+	} else if err != nil {
+		return "", nil, err
+	}
+	if upload.Bucket != bucketName || upload.Key != key || time.Now().After(upload.Expires) {
+		return "", nil, noSuchUpload(bucketName, key)
+	}
+	return uploadPath, upload, nil
+}
+
+func (b *LocalBackend) CreateMultipartUpload(ctx context.Context, bucketName, key string, metadata map[string]string, expires time.Time) (string, error) {
+	if _, err := b.objectPath(bucketName, key); err != nil {
+		return "", err
+	}
+	b.removeExpiredUploads()
+
+	uploadId := uuid.New().String()
+	data, err := json.Marshal(localMultipartUpload{
+		Bucket:       bucketName,
+		Key:          key,
+		UserMetadata: normalizeUserMetadata(metadata),
+		Expires:      expires,
+	})
+	if err != nil {
+		return "", err
+	}
+	uploadPath, err := b.uploadPath(uploadId)
+	if err != nil {
+		return "", err
+	}
+	_, _, err = b.writeFileAtomically(filepath.Join(uploadPath, "upload.json"), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	return uploadId, nil
+}
+
+func (b *LocalBackend) PresignedExternalUploadPart(ctx context.Context, bucketName, key, uploadId string, partNumber int, expiry time.Duration) (string, error) {
+	query := url.Values{}
+	query.Set(localParamBucket, bucketName)
+	query.Set(localParamKey, key)
+	query.Set(localParamUploadId, uploadId)
+	query.Set(localParamPartNumber, strconv.Itoa(partNumber))
+	return b.signedUrl(http.MethodPut, LocalUrlPart, query, expiry), nil
+}
+
+func partFileName(partNumber int64) string {
+	return fmt.Sprintf("%05d.part", partNumber)
+}
+
+// the parts are uploaded in parallel, so every part has its own files
+func (b *LocalBackend) WritePart(ctx context.Context, bucketName, key, uploadId string, partNumber int64, reader io.Reader) (string, error) {
+	if partNumber < 1 || partNumber > 10000 {
+		return "", ErrLocalInvalidName
+	}
+	uploadPath, _, err := b.getUpload(bucketName, key, uploadId)
+	if err != nil {
+		return "", err
+	}
+	_, etag, err := b.writeFileAtomically(filepath.Join(uploadPath, partFileName(partNumber)), reader)
+	if err != nil {
+		return "", err
+	}
+	_, _, err = b.writeFileAtomically(filepath.Join(uploadPath, partFileName(partNumber)+".etag"), strings.NewReader(etag))
+	if err != nil {
+		return "", err
+	}
+	return "\"" + etag + "\"", nil
+}
+
+func (b *LocalBackend) UploadPart(ctx context.Context, bucketName, key, uploadId string, partNumber int64, data []byte) (string, error) {
+	return b.WritePart(ctx, bucketName, key, uploadId, partNumber, bytes.NewReader(data))
+}
+
+func (b *LocalBackend) CompleteMultipartUpload(ctx context.Context, bucketName, key, uploadId string, parts []CompletedPart) error {
+	uploadPath, upload, err := b.getUpload(bucketName, key, uploadId)
+	if err != nil {
+		return err
+	}
+	if len(parts) == 0 {
+		return errors.New("there are no parts")
+	}
+
+	files := []io.Reader{}
+	for i, part := range parts {
+		if i > 0 && part.PartNumber <= parts[i-1].PartNumber {
+			return errors.New("the parts should be in the ascending order")
+		}
+		etag, err := os.ReadFile(filepath.Join(uploadPath, partFileName(part.PartNumber)+".etag"))
+		if err != nil {
+			return fmt.Errorf("part %v is absent: %w", part.PartNumber, err)
+		}
+		if string(etag) != strings.Trim(part.ETag, "\"") {
+			return fmt.Errorf("etag of part %v mismatches", part.PartNumber)
+		}
+		file, err := os.Open(filepath.Join(uploadPath, partFileName(part.PartNumber)))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		files = append(files, file)
+	}
+
+	_, err = b.put(ctx, ObjectCreatedCompleteMultipartUpload, bucketName, key, io.MultiReader(files...), -1, &localMetadata{
+		ContentType:  contentTypeOrDefault("", key),
+		UserMetadata: upload.UserMetadata,
+		Tags:         map[string]string{},
+	})
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(uploadPath)
+}
+
+func (b *LocalBackend) AbortMultipartUpload(ctx context.Context, bucketName, key, uploadId string) error {
+	uploadPath, _, err := b.getUpload(bucketName, key, uploadId)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(uploadPath)
+}
+
+var _ Backend = (*LocalBackend)(nil)
@@ -0,0 +1,350 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"nkonev.name/storage/logger"
+)
+
+const testBucket = "files"
+const testKey = "chat/1/0b3a2c1e-55a4-4bd2-9d0f-111111111111/video.mp4"
+
+func newTestLocalBackend(t *testing.T) *LocalBackend {
+	b, err := NewLocalBackend(logger.NewLogger(), t.TempDir(), "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.MakeBucket(context.Background(), testBucket, minio.MakeBucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestIsValidKey(t *testing.T) {
+	cases := []struct {
+		key   string
+		valid bool
+	}{
+		{"file.txt", true},
+		{"chat/1/uuid/file.txt", true},
+		{"chat/1/..file.txt", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"../../etc/passwd", false},
+		{"chat/../../etc/passwd", false},
+		{"chat/1/..", false},
+		{"chat/./file.txt", false},
+		{"chat//file.txt", false},
+		{"chat/1/", false},
+		{"chat\\..\\..\\file.txt", false},
+	}
+	for _, c := range cases {
+		if valid := isValidKey(c.key); valid != c.valid {
+			t.Errorf("isValidKey(%q) = %v, expected %v", c.key, valid, c.valid)
+		}
+	}
+}
+
+func TestObjectPathStaysInBucket(t *testing.T) {
+	b := newTestLocalBackend(t)
+
+	p, err := b.objectPath(testBucket, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(p, filepath.Join(b.root, testBucket)+string(filepath.Separator)) {
+		t.Errorf("%v is outside of the bucket", p)
+	}
+
+	for _, c := range []struct{ bucket, key string }{
+		{testBucket, "../" + localMetaDir + "/files/x.json"},
+		{testBucket, "a/../../other/file"},
+		{"..", "etc/passwd"},
+		{localMultipartDir, "upload.json"},
+		{"files/../other", "file"},
+		{"", "file"},
+	} {
+		if _, err := b.objectPath(c.bucket, c.key); !errors.Is(err, ErrLocalInvalidName) {
+			t.Errorf("objectPath(%q, %q) expected to be rejected, got %v", c.bucket, c.key, err)
+		}
+	}
+
+	if _, err := b.uploadPath("../../files/chat"); !errors.Is(err, ErrLocalInvalidName) {
+		t.Errorf("not uuid upload id expected to be rejected, got %v", err)
+	}
+
+	_, err = b.PutObject(context.Background(), testBucket, "../escaped.txt", strings.NewReader("data"), 4, minio.PutObjectOptions{})
+	if !errors.Is(err, ErrLocalInvalidName) {
+		t.Errorf("expected to be rejected, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(b.root, "escaped.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the file is written outside of the bucket")
+	}
+}
+
+func splitSignedUrl(t *testing.T, signed string) (string, url.Values) {
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Path, u.Query()
+}
+
+func TestVerifySignedUrl(t *testing.T) {
+	b := newTestLocalBackend(t)
+	ctx := context.Background()
+
+	signed, err := b.PresignedExternalGetObject(ctx, testBucket, testKey, time.Minute, url.Values{LocalParamResponseContentType: {"video/mp4"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	urlPath, query := splitSignedUrl(t, signed)
+	if urlPath != LocalUrlObject {
+		t.Errorf("unexpected path %v", urlPath)
+	}
+	if err := b.VerifySignedUrl(http.MethodGet, urlPath, query); err != nil {
+		t.Errorf("expected to be valid, got %v", err)
+	}
+	if bucket, key := LocalObjectFromUrl(query); bucket != testBucket || key != testKey {
+		t.Errorf("unexpected object %v/%v", bucket, key)
+	}
+
+	tamper := func(name, value string) url.Values {
+		tampered := url.Values{}
+		for k, v := range query {
+			tampered[k] = v
+		}
+		tampered.Set(name, value)
+		return tampered
+	}
+	cases := []struct {
+		name     string
+		method   string
+		urlPath  string
+		query    url.Values
+		expected error
+	}{
+		{"other key", http.MethodGet, urlPath, tamper(localParamKey, "chat/2/other/secret.txt"), ErrLocalInvalidSignature},
+		{"other bucket", http.MethodGet, urlPath, tamper(localParamBucket, "files-preview"), ErrLocalInvalidSignature},
+		{"prolonged", http.MethodGet, urlPath, tamper(localParamExpires, strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)), ErrLocalInvalidSignature},
+		{"other content type", http.MethodGet, urlPath, tamper(LocalParamResponseContentType, "text/html"), ErrLocalInvalidSignature},
+		{"added parameter", http.MethodGet, urlPath, tamper(LocalParamResponseContentDisposition, "attachment"), ErrLocalInvalidSignature},
+		{"other signature", http.MethodGet, urlPath, tamper(localParamSignature, strings.Repeat("0", 64)), ErrLocalInvalidSignature},
+		{"without signature", http.MethodGet, urlPath, tamper(localParamSignature, ""), ErrLocalInvalidSignature},
+		{"other method", http.MethodPut, urlPath, query, ErrLocalInvalidSignature},
+		{"other path", http.MethodGet, LocalUrlPart, query, ErrLocalInvalidSignature},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := b.VerifySignedUrl(c.method, c.urlPath, c.query); !errors.Is(err, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, err)
+			}
+		})
+	}
+
+	other, err := NewLocalBackend(logger.NewLogger(), t.TempDir(), "other secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.VerifySignedUrl(http.MethodGet, urlPath, query); !errors.Is(err, ErrLocalInvalidSignature) {
+		t.Errorf("signed by the other key expected to be rejected, got %v", err)
+	}
+}
+
+func TestVerifyExpiredSignedUrl(t *testing.T) {
+	b := newTestLocalBackend(t)
+
+	signed, err := b.PresignedExternalGetObject(context.Background(), testBucket, testKey, -time.Minute, url.Values{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	urlPath, query := splitSignedUrl(t, signed)
+	if err := b.VerifySignedUrl(http.MethodGet, urlPath, query); !errors.Is(err, ErrLocalExpiredSignature) {
+		t.Errorf("expected %v, got %v", ErrLocalExpiredSignature, err)
+	}
+}
+
+func TestNewLocalBackendRequiresSigningKey(t *testing.T) {
+	if _, err := NewLocalBackend(logger.NewLogger(), t.TempDir(), "", nil); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestMultipartUpload(t *testing.T) {
+	b := newTestLocalBackend(t)
+	ctx := context.Background()
+
+	uploadId, err := b.CreateMultipartUpload(ctx, testBucket, testKey, map[string]string{"X-Amz-Meta-Ownerid": "1"}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the parts are uploaded through the signed urls
+	signed, err := b.PresignedExternalUploadPart(ctx, testBucket, testKey, uploadId, 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	urlPath, query := splitSignedUrl(t, signed)
+	if err := b.VerifySignedUrl(http.MethodPut, urlPath, query); err != nil {
+		t.Fatal(err)
+	}
+	bucket, key, partUploadId, partNumber, err := LocalPartFromUrl(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bucket != testBucket || key != testKey || partUploadId != uploadId || partNumber != 2 {
+		t.Errorf("unexpected part %v %v %v %v", bucket, key, partUploadId, partNumber)
+	}
+
+	// in the reversed order, as the browser can do
+	etag2, err := b.WritePart(ctx, bucket, key, partUploadId, partNumber, strings.NewReader("world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag1, err := b.UploadPart(ctx, testBucket, testKey, uploadId, 1, []byte("hello "))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.CompleteMultipartUpload(ctx, testBucket, testKey, uploadId, []CompletedPart{{2, etag2}, {1, etag1}}); err == nil {
+		t.Error("the descending parts expected to be rejected")
+	}
+	if err := b.CompleteMultipartUpload(ctx, testBucket, testKey, uploadId, []CompletedPart{{1, etag2}, {2, etag2}}); err == nil {
+		t.Error("the mismatched etag expected to be rejected")
+	}
+	if err := b.CompleteMultipartUpload(ctx, testBucket, testKey, uploadId, []CompletedPart{{1, etag1}, {3, etag2}}); err == nil {
+		t.Error("the absent part expected to be rejected")
+	}
+
+	if err := b.CompleteMultipartUpload(ctx, testBucket, testKey, uploadId, []CompletedPart{{1, etag1}, {2, etag2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	object, err := b.GetObject(ctx, testBucket, testKey, minio.GetObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer object.Close()
+	content, err := io.ReadAll(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello world" {
+		t.Errorf("unexpected content %q", content)
+	}
+	info, err := object.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ContentType != "video/mp4" || info.UserMetadata["Ownerid"] != "1" {
+		t.Errorf("unexpected info %+v", info)
+	}
+
+	// the upload is removed after the completion
+	_, err = b.WritePart(ctx, testBucket, testKey, uploadId, 3, strings.NewReader("again"))
+	if !isNoSuchUpload(err) {
+		t.Errorf("expected NoSuchUpload, got %v", err)
+	}
+}
+
+func isNoSuchUpload(err error) bool {
+	var errResponse minio.ErrorResponse
+	return errors.As(err, &errResponse) && errResponse.Code == "NoSuchUpload"
+}
+
+func TestMultipartUploadBelongsToObject(t *testing.T) {
+	b := newTestLocalBackend(t)
+	ctx := context.Background()
+
+	uploadId, err := b.CreateMultipartUpload(ctx, testBucket, testKey, map[string]string{}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the signed part url of the own upload can't be used for the other object
+	if _, err := b.WritePart(ctx, testBucket, "chat/2/other/file.txt", uploadId, 1, strings.NewReader("x")); !isNoSuchUpload(err) {
+		t.Errorf("expected NoSuchUpload, got %v", err)
+	}
+	if _, err := b.WritePart(ctx, testBucket, testKey, "../../files/chat", 1, strings.NewReader("x")); !errors.Is(err, ErrLocalInvalidName) {
+		t.Errorf("expected %v, got %v", ErrLocalInvalidName, err)
+	}
+	for _, partNumber := range []int64{0, 10001} {
+		if _, err := b.WritePart(ctx, testBucket, testKey, uploadId, partNumber, strings.NewReader("x")); !errors.Is(err, ErrLocalInvalidName) {
+			t.Errorf("part %v expected to be rejected, got %v", partNumber, err)
+		}
+	}
+
+	if err := b.AbortMultipartUpload(ctx, testBucket, testKey, uploadId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.WritePart(ctx, testBucket, testKey, uploadId, 1, bytes.NewReader([]byte("x"))); !isNoSuchUpload(err) {
+		t.Errorf("expected NoSuchUpload after abort, got %v", err)
+	}
+}
+
+func TestExpiredMultipartUpload(t *testing.T) {
+	b := newTestLocalBackend(t)
+	ctx := context.Background()
+
+	expired, err := b.CreateMultipartUpload(ctx, testBucket, testKey, map[string]string{}, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.UploadPart(ctx, testBucket, testKey, expired, 1, []byte("x")); !isNoSuchUpload(err) {
+		t.Errorf("expected NoSuchUpload, got %v", err)
+	}
+
+	// the expired uploads are removed on the creation of the next one
+	if _, err := b.CreateMultipartUpload(ctx, testBucket, testKey, map[string]string{}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(b.root, localMultipartDir, expired)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the expired upload isn't removed")
+	}
+}
+
+func TestRemoveObjectForceDelete(t *testing.T) {
+	b := newTestLocalBackend(t)
+	ctx := context.Background()
+
+	keys := []string{"chat/1/item/a.txt", "chat/1/item/nested/b.txt", "chat/1/item2/c.txt", "chat/2/item/d.txt"}
+	for _, key := range keys {
+		if _, err := b.PutObject(ctx, testBucket, key, strings.NewReader("data"), 4, minio.PutObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := b.RemoveObject(ctx, testBucket, "chat/1/item/", minio.RemoveObjectOptions{ForceDelete: true}); err != nil {
+		t.Fatal(err)
+	}
+	// the absent prefix isn't an error
+	if err := b.RemoveObject(ctx, testBucket, "chat/3/", minio.RemoveObjectOptions{ForceDelete: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	remaining := []string{}
+	for info := range b.ListObjects(ctx, testBucket, minio.ListObjectsOptions{Prefix: "chat/", Recursive: true}) {
+		if info.Err != nil {
+			t.Fatal(info.Err)
+		}
+		remaining = append(remaining, info.Key)
+	}
+	if strings.Join(remaining, ",") != "chat/1/item2/c.txt,chat/2/item/d.txt" {
+		t.Errorf("only the objects with the prefix should be removed, got %v", remaining)
+	}
+	if _, err := os.Stat(filepath.Join(b.root, testBucket, "chat", "1", "item")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the empty directories should be removed")
+	}
+}
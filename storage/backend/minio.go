@@ -0,0 +1,190 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsS3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/notification"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/logger"
+)
+
+// the minio client is used for the most of the operations, aws client is used for the multipart upload because minio-go doesn't expose it
+type MinioBackend struct {
+	*minio.Client
+	awsS3 *awsS3.S3
+	lgr   *logger.Logger
+}
+
+func NewMinioBackend(lgr *logger.Logger, client *minio.Client, awsS3Client *awsS3.S3) *MinioBackend {
+	return &MinioBackend{
+		Client: client,
+		awsS3:  awsS3Client,
+		lgr:    lgr,
+	}
+}
+
+// the internal url of minio is replaced with the prefix which is proxied to minio
+func changeMinioUrl(url *url.URL) (string, error) {
+	externalS3UrlPrefix := viper.GetString("minio.externalS3UrlPrefix")
+	parsed, err := url.Parse(externalS3UrlPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	url.Path = parsed.Path + url.Path
+	url.Host = ""
+	url.Scheme = ""
+
+	stringV := url.String()
+
+	return stringV, nil
+}
+
+// see AMQP minio's endpoint in docker-compose
+func (c *MinioBackend) SubscribeEvents(ctx context.Context, bucketName string) error {
+	bucketNotification, err := c.Client.GetBucketNotification(ctx, bucketName)
+	if err != nil {
+		return err
+	}
+
+	arn := notification.Arn{
+		Partition: "minio",
+		Service:   "sqs",
+		Region:    "",
+		AccountID: "primary",
+		Resource:  "amqp",
+	}
+	subscriptionName := arn.String()
+	queueConfigs := bucketNotification.QueueConfigs
+	for _, qc := range queueConfigs {
+		if qc.Queue == subscriptionName {
+			return nil
+		}
+	}
+
+	c.lgr.Infof("Will create subscription for bucket %v to arn %v", bucketName, arn)
+	return c.Client.SetBucketNotification(ctx, bucketName, notification.Configuration{
+		QueueConfigs: []notification.QueueConfig{
+			notification.QueueConfig{
+				Queue: subscriptionName,
+				Config: notification.Config{
+					Events: []notification.EventType{
+						ObjectCreated + ":*",
+						ObjectRemoved + ":*",
+					},
+				},
+			},
+		},
+	})
+}
+
+func (c *MinioBackend) FileExists(ctx context.Context, bucket, key string) (bool, *minio.ObjectInfo, error) {
+	objectInfo, err := c.Client.StatObject(ctx, bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if errTyped, ok := err.(minio.ErrorResponse); ok {
+			if errTyped.Code == "NoSuchKey" {
+				return false, nil, nil
+			}
+		}
+		return false, nil, err
+	}
+	return true, &objectInfo, err
+}
+
+func (c *MinioBackend) GetObject(ctx context.Context, bucketName, key string, opts minio.GetObjectOptions) (Object, error) {
+	object, err := c.Client.GetObject(ctx, bucketName, key, opts)
+	if err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (c *MinioBackend) PresignedExternalGetObject(ctx context.Context, bucketName, key string, expiry time.Duration, reqParams url.Values) (string, error) {
+	u, err := c.Client.PresignedGetObject(ctx, bucketName, key, expiry, reqParams)
+	if err != nil {
+		return "", err
+	}
+	return changeMinioUrl(u)
+}
+
+func (c *MinioBackend) CreateMultipartUpload(ctx context.Context, bucketName, key string, metadata map[string]string, expires time.Time) (string, error) {
+	converted := map[string]*string{}
+	for k, v := range metadata {
+		converted[k] = aws.String(v)
+	}
+	upload, err := c.awsS3.CreateMultipartUploadWithContext(ctx, &awsS3.CreateMultipartUploadInput{
+		Expires:  &expires,
+		Bucket:   &bucketName,
+		Key:      &key,
+		Metadata: converted,
+	})
+	if err != nil {
+		return "", err
+	}
+	return *upload.UploadId, nil
+}
+
+func (c *MinioBackend) PresignedExternalUploadPart(ctx context.Context, bucketName, key, uploadId string, partNumber int, expiry time.Duration) (string, error) {
+	var urlVals = url.Values{}
+	urlVals.Set("partNumber", strconv.Itoa(partNumber))
+	urlVals.Set("uploadId", uploadId)
+
+	u, err := c.Client.Presign(ctx, "PUT", bucketName, key, expiry, urlVals)
+	if err != nil {
+		return "", err
+	}
+	return changeMinioUrl(u)
+}
+
+func (c *MinioBackend) UploadPart(ctx context.Context, bucketName, key, uploadId string, partNumber int64, data []byte) (string, error) {
+	out, err := c.awsS3.UploadPartWithContext(ctx, &awsS3.UploadPartInput{
+		Body:          bytes.NewReader(data),
+		Bucket:        &bucketName,
+		Key:           &key,
+		PartNumber:    &partNumber,
+		UploadId:      &uploadId,
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(out.ETag), nil
+}
+
+func (c *MinioBackend) CompleteMultipartUpload(ctx context.Context, bucketName, key, uploadId string, parts []CompletedPart) error {
+	arr := []*awsS3.CompletedPart{}
+	for _, part := range parts {
+		arr = append(arr, &awsS3.CompletedPart{
+			ETag:       aws.String(part.ETag),
+			PartNumber: aws.Int64(part.PartNumber),
+		})
+	}
+	_, err := c.awsS3.CompleteMultipartUploadWithContext(ctx, &awsS3.CompleteMultipartUploadInput{
+		Key:      &key,
+		Bucket:   &bucketName,
+		UploadId: &uploadId,
+		MultipartUpload: &awsS3.CompletedMultipartUpload{
+			Parts: arr,
+		},
+	})
+	return err
+}
+
+func (c *MinioBackend) AbortMultipartUpload(ctx context.Context, bucketName, key, uploadId string) error {
+	_, err := c.awsS3.AbortMultipartUploadWithContext(ctx, &awsS3.AbortMultipartUploadInput{
+		Bucket:   &bucketName,
+		Key:      &key,
+		UploadId: &uploadId,
+	})
+	return err
+}
+
+// the rest of the methods are the ones of minio.Client
+var _ Backend = (*MinioBackend)(nil)
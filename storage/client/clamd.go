@@ -0,0 +1,149 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"nkonev.name/storage/logger"
+)
+
+const clamdStreamPrefix = "stream: "
+const clamdFoundSuffix = " FOUND"
+const clamdErrorSuffix = " ERROR"
+const clamdOk = "OK"
+
+const defaultClamdChunkSize = 64 * 1024
+
+type ScanResult struct {
+	Infected  bool
+	Signature string
+}
+
+// speaks the clamd protocol over TCP, see "man clamd", INSTREAM command
+type ClamdClient struct {
+	address   string
+	timeout   time.Duration
+	chunkSize int
+	lgr       *logger.Logger
+}
+
+func NewClamdClient(lgr *logger.Logger) *ClamdClient {
+	chunkSize := viper.GetInt("antivirus.chunkSize")
+	if chunkSize <= 0 {
+		chunkSize = defaultClamdChunkSize
+	}
+	return &ClamdClient{
+		address:   viper.GetString("antivirus.address"),
+		timeout:   viper.GetDuration("antivirus.timeout"),
+		chunkSize: chunkSize,
+		lgr:       lgr,
+	}
+}
+
+func (c *ClamdClient) dial(ctx context.Context) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, err
+	}
+	if c.timeout > 0 {
+		err = conn.SetDeadline(time.Now().Add(c.timeout))
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// streams the content to clamd chunk by chunk, each chunk is prefixed with its length, the zero length chunk terminates the stream
+func (c *ClamdClient) Scan(ctx context.Context, reader io.Reader) (*ScanResult, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	// interrupts the streaming when the context is done
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	if _, err = conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, c.chunkSize)
+	sizeBuf := make([]byte, 4)
+	for {
+		n, readErr := reader.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(sizeBuf, uint32(n))
+			if _, err = conn.Write(sizeBuf); err != nil {
+				// clamd closes the connection when the stream exceeds its StreamMaxLength
+				return nil, c.tryReadError(conn, err)
+			}
+			if _, err = conn.Write(buf[:n]); err != nil {
+				return nil, c.tryReadError(conn, err)
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	binary.BigEndian.PutUint32(sizeBuf, 0)
+	if _, err = conn.Write(sizeBuf); err != nil {
+		return nil, c.tryReadError(conn, err)
+	}
+
+	resp, err := readClamdResponse(conn)
+	if err != nil {
+		return nil, err
+	}
+	return parseClamdScanResponse(resp)
+}
+
+func (c *ClamdClient) tryReadError(conn net.Conn, writeErr error) error {
+	resp, err := readClamdResponse(conn)
+	if err != nil || len(resp) == 0 {
+		return writeErr
+	}
+	return fmt.Errorf("clamd error: %v", resp)
+}
+
+// the responses of z-prefixed commands are terminated by \0
+func readClamdResponse(conn net.Conn) (string, error) {
+	resp, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && !(errors.Is(err, io.EOF) && len(resp) > 0) {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimSuffix(resp, "\x00")), nil
+}
+
+func parseClamdScanResponse(resp string) (*ScanResult, error) {
+	result := strings.TrimPrefix(resp, clamdStreamPrefix)
+	switch {
+	case result == clamdOk:
+		return &ScanResult{}, nil
+	case strings.HasSuffix(result, clamdFoundSuffix):
+		return &ScanResult{
+			Infected:  true,
+			Signature: strings.TrimSuffix(result, clamdFoundSuffix),
+		}, nil
+	case strings.HasSuffix(result, clamdErrorSuffix):
+		return nil, fmt.Errorf("clamd error: %v", strings.TrimSuffix(result, clamdErrorSuffix))
+	default:
+		return nil, fmt.Errorf("unexpected clamd response: %v", resp)
+	}
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// answers like clamd does, collects the streamed chunks
+type fakeClamd struct {
+	listener net.Listener
+	chunks   chan []int
+}
+
+func startFakeClamd(t *testing.T) *fakeClamd {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeClamd{listener: listener, chunks: make(chan []int, 1)}
+	go f.serve()
+	t.Cleanup(func() {
+		listener.Close()
+	})
+	return f
+}
+
+func (f *fakeClamd) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeClamd) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	command, err := reader.ReadString('\x00')
+	if err != nil {
+		return
+	}
+	if command != "zINSTREAM\x00" {
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+		return
+	}
+
+	var content bytes.Buffer
+	var sizes []int
+	sizeBuf := make([]byte, 4)
+	for {
+		if _, err = io.ReadFull(reader, sizeBuf); err != nil {
+			return
+		}
+		size := binary.BigEndian.Uint32(sizeBuf)
+		if size == 0 {
+			break
+		}
+		sizes = append(sizes, int(size))
+		if _, err = io.CopyN(&content, reader, int64(size)); err != nil {
+			return
+		}
+	}
+	f.chunks <- sizes
+
+	if strings.Contains(content.String(), "EICAR-STANDARD-ANTIVIRUS-TEST-FILE") {
+		conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+	} else if content.Len() == 0 {
+		conn.Write([]byte("stream: Empty file ERROR\x00"))
+	} else {
+		conn.Write([]byte("stream: OK\x00"))
+	}
+}
+
+func newTestClamdClient(address string, chunkSize int) *ClamdClient {
+	return &ClamdClient{
+		address:   address,
+		timeout:   5 * time.Second,
+		chunkSize: chunkSize,
+	}
+}
+
+func TestScanClean(t *testing.T) {
+	f := startFakeClamd(t)
+	c := newTestClamdClient(f.listener.Addr().String(), 4)
+
+	result, err := c.Scan(context.Background(), strings.NewReader("hello, world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Infected || result.Signature != "" {
+		t.Errorf("expected clean, got %+v", result)
+	}
+	if sizes := <-f.chunks; !slices.Equal(sizes, []int{4, 4, 4}) {
+		t.Errorf("unexpected chunks %v", sizes)
+	}
+}
+
+func TestScanInfected(t *testing.T) {
+	f := startFakeClamd(t)
+	c := newTestClamdClient(f.listener.Addr().String(), 16)
+
+	result, err := c.Scan(context.Background(), strings.NewReader(eicar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Infected || result.Signature != "Eicar-Test-Signature" {
+		t.Errorf("expected infected, got %+v", result)
+	}
+	<-f.chunks
+}
+
+func TestScanError(t *testing.T) {
+	f := startFakeClamd(t)
+	c := newTestClamdClient(f.listener.Addr().String(), 16)
+
+	_, err := c.Scan(context.Background(), strings.NewReader(""))
+	if err == nil || !strings.Contains(err.Error(), "Empty file") {
+		t.Errorf("expected clamd error, got %v", err)
+	}
+	<-f.chunks
+}
+
+func TestScanUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	c := newTestClamdClient(address, 16)
+	_, err = c.Scan(context.Background(), strings.NewReader(eicar))
+	if err == nil {
+		t.Error("expected error")
+	}
+}
@@ -31,6 +31,7 @@ type RestClient struct {
 	checkChatExistsPath    string
 	chatParticipantIdsPath string
 	chatCountPath          string
+	isChatAdminPath        string
 	tracer                 trace.Tracer
 	lgr                    *logger.Logger
 }
@@ -61,6 +62,7 @@ func NewChatAccessClient(lgr *logger.Logger) *RestClient {
 		checkChatExistsPath:    viper.GetString("chat.url.checkChatExistsPath"),
 		chatParticipantIdsPath: viper.GetString("chat.url.chatParticipants"),
 		chatCountPath:          viper.GetString("chat.url.chatCount"),
+		isChatAdminPath:        viper.GetString("chat.url.isChatAdmin"),
 		tracer:                 trcr,
 		lgr:                    lgr,
 	}
@@ -122,6 +124,36 @@ func (h *RestClient) CheckAccessExtended(c context.Context, userId *int64, chatI
 	}
 }
 
+func (h *RestClient) IsAdmin(c context.Context, userId int64, chatId int64) (bool, error) {
+	url0 := fmt.Sprintf("%v%v?userId=%v&chatId=%v", h.baseUrl, h.isChatAdminPath, userId, chatId)
+
+	req, err := http.NewRequest("GET", url0, nil)
+	if err != nil {
+		h.lgr.WithTracing(c).Errorw("Error during create GET", err)
+		return false, err
+	}
+
+	ctx, span := h.tracer.Start(c, "chat.IsAdmin")
+	defer span.End()
+	req = req.WithContext(ctx)
+
+	response, err := h.client.Do(req)
+	if err != nil {
+		h.lgr.WithTracing(c).Errorw("Transport error during checking is admin", err)
+		return false, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusOK {
+		return true, nil
+	} else if response.StatusCode == http.StatusUnauthorized {
+		return false, nil
+	} else {
+		err := errors.New("Unexpected status on isAdmin")
+		h.lgr.WithTracing(c).Errorw("Unexpected status on isAdmin", err, "httpCode", response.StatusCode)
+		return false, err
+	}
+}
+
 func (h *RestClient) RemoveFileItem(c context.Context, chatId int64, fileItemUuid string, userId int64) {
 	fullUrl := fmt.Sprintf("%v%v?chatId=%v&fileItemUuid=%v&userId=%v", h.baseUrl, h.removeFileItemPath, chatId, fileItemUuid, userId)
 
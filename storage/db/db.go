@@ -170,6 +170,7 @@ func ConfigureDb(lgr *logger.Logger, lc fx.Lifecycle) (*DB, error) {
 func (db *DB) RecreateDb() {
 	_, err := db.Exec(fmt.Sprintf(`
 	drop table if exists metadata_cache;
+	drop table if exists quota;
 	drop table if exists %s;
 	drop table if exists %s;
 	
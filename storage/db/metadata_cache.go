@@ -25,10 +25,21 @@ const metadataColumns = `
 	
 	published,
 	
+	scan_status,
+	
+	version_count,
+	
 	file_size,
 	
 	create_date_time,
-	edit_date_time
+	edit_date_time,
+	
+	width,
+	height,
+	duration,
+	video_codec,
+	audio_codec,
+	page_count
 `
 
 // "" <=> dto.NoFileItemUuid
@@ -68,12 +79,28 @@ func Set(ctx context.Context, co CommonOperations, metadataCache dto.MetadataCac
 		    $6,
 		  	$7,
 		    $8,
-		    $9
+		    $9,
+		    $10,
+		    $11,
+		    $12,
+		    $13,
+		    $14,
+		    $15,
+		    $16,
+		    $17
 		) on conflict (chat_id, file_item_uuid, filename) 
 		do update set 
 			published = $6,
-		    file_size = $7,
-			edit_date_time = $9
+			scan_status = $7,
+			version_count = $8,
+		    file_size = $9,
+			edit_date_time = $11,
+			width = $12,
+			height = $13,
+			duration = $14,
+			video_codec = $15,
+			audio_codec = $16,
+			page_count = $17
 	`, metadataColumns),
 		metadataCache.ChatId,
 		metadataCache.FileItemUuid,
@@ -82,9 +109,17 @@ func Set(ctx context.Context, co CommonOperations, metadataCache dto.MetadataCac
 		metadataCache.OwnerId,
 		metadataCache.CorrelationId,
 		metadataCache.Published,
+		metadataCache.ScanStatus,
+		metadataCache.VersionCount,
 		metadataCache.FileSize,
 		metadataCache.CreateDateTime,
 		metadataCache.EditDateTime,
+		metadataCache.Media.Width,
+		metadataCache.Media.Height,
+		metadataCache.Media.Duration,
+		metadataCache.Media.VideoCodec,
+		metadataCache.Media.AudioCodec,
+		metadataCache.Media.PageCount,
 	)
 
 	if err != nil {
@@ -102,9 +137,17 @@ func provideScanToMetadataCache(ucs *dto.MetadataCache) []any {
 		&ucs.OwnerId,
 		&ucs.CorrelationId,
 		&ucs.Published,
+		&ucs.ScanStatus,
+		&ucs.VersionCount,
 		&ucs.FileSize,
 		&ucs.CreateDateTime,
 		&ucs.EditDateTime,
+		&ucs.Media.Width,
+		&ucs.Media.Height,
+		&ucs.Media.Duration,
+		&ucs.Media.VideoCodec,
+		&ucs.Media.AudioCodec,
+		&ucs.Media.PageCount,
 	}
 }
 
@@ -433,6 +476,40 @@ func GetList(ctx context.Context, co CommonOperations, chatId int64, fileItemUui
 	return list, nil
 }
 
+// the files which haven't got the verdict of the antivirus, the page is after the given key
+func GetUnverified(ctx context.Context, co CommonOperations, after dto.MetadataCacheId, limit int) ([]dto.MetadataCache, error) {
+	list := make([]dto.MetadataCache, 0)
+	rows, err := co.QueryContext(ctx, `select `+metadataColumns+`
+		from metadata_cache
+		where scan_status in ($1, $2) and (chat_id, file_item_uuid, filename) > ($3, $4, $5)
+		order by chat_id, file_item_uuid, filename
+		limit $6`,
+		dto.ScanStatusFailed, dto.ScanStatusNotScanned, after.ChatId, after.FileItemUuid, after.Filename, limit)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		ucs := dto.MetadataCache{}
+		if err = rows.Scan(provideScanToMetadataCache(&ucs)[:]...); err != nil {
+			return nil, eris.Wrap(err, "error during scanning")
+		} else {
+			list = append(list, ucs)
+		}
+	}
+	return list, nil
+}
+
+func SetScanStatus(ctx context.Context, co CommonOperations, metadataCacheId dto.MetadataCacheId, scanStatus string) error {
+	_, err := co.ExecContext(ctx, `update metadata_cache set scan_status = $4
+								where (chat_id, file_item_uuid, filename) = ($1, $2, $3)`,
+		metadataCacheId.ChatId, metadataCacheId.FileItemUuid, metadataCacheId.Filename, scanStatus)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
 func GetCount(ctx context.Context, co CommonOperations, chatId int64, fileItemUuid string, filterObj Filter) (int64, error) {
 	var count int64
 
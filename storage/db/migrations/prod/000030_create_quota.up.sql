@@ -0,0 +1,15 @@
+-- not unlogged because it is set by admin and can't be restored from S3 unlike metadata_cache
+create table quota(
+    -- user or chat
+    subject_type varchar(8) not null,
+    -- -1 means the default for all the users or chats
+    subject_id bigint not null,
+    -- in bytes, null means unlimited
+    max_size bigint,
+
+    primary key (subject_type, subject_id)
+);
+
+SELECT create_reference_table('quota');
+
+create index idx_owner_user_id on metadata_cache(owner_user_id);
@@ -0,0 +1,2 @@
+-- the files uploaded before the antivirus was enabled are considered as not scanned
+alter table metadata_cache add column scan_status varchar(16) not null default 'not_scanned';
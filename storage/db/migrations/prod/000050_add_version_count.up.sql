@@ -0,0 +1,2 @@
+-- the previous versions are stored in the separate bucket, it's the count of them
+alter table metadata_cache add column version_count int not null default 0;
@@ -0,0 +1,40 @@
+-- not unlogged because the links are created by users and can't be restored from S3 unlike metadata_cache
+create table share_link(
+    chat_id bigint not null,
+    id varchar(36) not null,
+
+    -- the shared file, see ./services.files.go::GetKey()
+    file_item_uuid varchar(36) not null,
+    filename varchar(255) not null,
+
+    owner_user_id bigint not null,
+    -- bcrypt, null means without password
+    password_hash varchar(60),
+    -- null means forever
+    expires_at timestamp,
+    -- null means unlimited
+    max_downloads bigint,
+    download_count bigint not null default 0,
+    revoked boolean not null default false,
+
+    create_date_time timestamp not null default utc_now(),
+
+    primary key (chat_id, id)
+);
+
+SELECT create_distributed_table('share_link', 'chat_id', colocate_with => 'metadata_cache');
+
+create index idx_share_link_file on share_link(chat_id, file_item_uuid, filename);
+
+-- the statistics, a row per download
+create table share_link_download(
+    chat_id bigint not null,
+    link_id varchar(36) not null,
+    create_date_time timestamp not null default utc_now(),
+
+    foreign key (chat_id, link_id) references share_link(chat_id, id) on delete cascade
+);
+
+SELECT create_distributed_table('share_link_download', 'chat_id', colocate_with => 'share_link');
+
+create index idx_share_link_download on share_link_download(chat_id, link_id, create_date_time);
@@ -0,0 +1,7 @@
+-- the properties of the media extracted after the upload, null means not applicable or not extracted
+alter table metadata_cache add column width int;
+alter table metadata_cache add column height int;
+alter table metadata_cache add column duration double precision; -- in seconds
+alter table metadata_cache add column video_codec varchar(32);
+alter table metadata_cache add column audio_codec varchar(32);
+alter table metadata_cache add column page_count int;
@@ -0,0 +1,2 @@
+-- the files which are scanned by the actualization task
+create index idx_unverified on metadata_cache(chat_id, file_item_uuid, filename) where scan_status in ('failed', 'not_scanned');
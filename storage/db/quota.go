@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/rotisserie/eris"
+	"nkonev.name/storage/dto"
+)
+
+func SetQuota(ctx context.Context, co CommonOperations, quota dto.Quota) error {
+	_, err := co.ExecContext(ctx, `insert into quota(subject_type, subject_id, max_size) values ($1, $2, $3)
+		on conflict (subject_type, subject_id) do update set max_size = excluded.max_size`,
+		quota.SubjectType, quota.SubjectId, quota.MaxSize)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+func RemoveQuota(ctx context.Context, co CommonOperations, subjectType string, subjectId int64) error {
+	_, err := co.ExecContext(ctx, `delete from quota where (subject_type, subject_id) = ($1, $2)`, subjectType, subjectId)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// returns nil if there is no the quota set by admin
+func GetQuota(ctx context.Context, co CommonOperations, subjectType string, subjectId int64) (*dto.Quota, error) {
+	row := co.QueryRowContext(ctx, `select subject_type, subject_id, max_size from quota where (subject_type, subject_id) = ($1, $2)`, subjectType, subjectId)
+	if row.Err() != nil {
+		return nil, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	q := dto.Quota{}
+	err := row.Scan(&q.SubjectType, &q.SubjectId, &q.MaxSize)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// there were no rows, but otherwise no error occurred
+			return nil, nil
+		}
+		return nil, eris.Wrap(err, "error during scanning from db")
+	}
+	return &q, nil
+}
+
+// returns the quotas of the given subjects, the subjects without the quota are absent
+func GetQuotas(ctx context.Context, co CommonOperations, subjectType string, subjectIds []int64) (map[int64]dto.Quota, error) {
+	res := map[int64]dto.Quota{}
+	rows, err := co.QueryContext(ctx, `select subject_type, subject_id, max_size from quota where subject_type = $1 and subject_id = any($2)`, subjectType, subjectIds)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		q := dto.Quota{}
+		if err = rows.Scan(&q.SubjectType, &q.SubjectId, &q.MaxSize); err != nil {
+			return nil, eris.Wrap(err, "error during scanning")
+		}
+		res[q.SubjectId] = q
+	}
+	return res, nil
+}
+
+func GetAllQuotas(ctx context.Context, co CommonOperations) ([]dto.Quota, error) {
+	list := make([]dto.Quota, 0)
+	rows, err := co.QueryContext(ctx, `select subject_type, subject_id, max_size from quota order by subject_type, subject_id`)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		q := dto.Quota{}
+		if err = rows.Scan(&q.SubjectType, &q.SubjectId, &q.MaxSize); err != nil {
+			return nil, eris.Wrap(err, "error during scanning")
+		}
+		list = append(list, q)
+	}
+	return list, nil
+}
+
+func GetConsumptionByOwner(ctx context.Context, co CommonOperations, ownerId int64) (int64, error) {
+	row := co.QueryRowContext(ctx, `select coalesce(sum(file_size), 0) from metadata_cache where owner_user_id = $1`, ownerId)
+	if row.Err() != nil {
+		return 0, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var consumption int64
+	if err := row.Scan(&consumption); err != nil {
+		return 0, eris.Wrap(err, "error during scanning from db")
+	}
+	return consumption, nil
+}
+
+func GetConsumptionByChat(ctx context.Context, co CommonOperations, chatId int64) (int64, error) {
+	row := co.QueryRowContext(ctx, `select coalesce(sum(file_size), 0) from metadata_cache where chat_id = $1`, chatId)
+	if row.Err() != nil {
+		return 0, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var consumption int64
+	if err := row.Scan(&consumption); err != nil {
+		return 0, eris.Wrap(err, "error during scanning from db")
+	}
+	return consumption, nil
+}
+
+func GetTopConsumersByOwner(ctx context.Context, co CommonOperations, limit, offset int) ([]dto.Consumer, error) {
+	rows, err := co.QueryContext(ctx, `select owner_user_id, sum(file_size) as used, count(*) from metadata_cache
+		group by owner_user_id
+		order by used desc, owner_user_id
+		limit $1 offset $2`, limit, offset)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	return scanConsumers(rows)
+}
+
+func GetTopConsumersByChat(ctx context.Context, co CommonOperations, limit, offset int) ([]dto.Consumer, error) {
+	rows, err := co.QueryContext(ctx, `select chat_id, sum(file_size) as used, count(*) from metadata_cache
+		group by chat_id
+		order by used desc, chat_id
+		limit $1 offset $2`, limit, offset)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	return scanConsumers(rows)
+}
+
+func scanConsumers(rows *sql.Rows) ([]dto.Consumer, error) {
+	defer rows.Close()
+	list := make([]dto.Consumer, 0)
+	for rows.Next() {
+		c := dto.Consumer{}
+		if err := rows.Scan(&c.Id, &c.Used, &c.FilesCount); err != nil {
+			return nil, eris.Wrap(err, "error during scanning")
+		}
+		list = append(list, c)
+	}
+	return list, nil
+}
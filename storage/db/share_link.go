@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/rotisserie/eris"
+	"nkonev.name/storage/dto"
+)
+
+const shareLinkColumns = `l.chat_id, l.id, l.file_item_uuid, l.filename, l.owner_user_id, l.password_hash, l.expires_at, l.max_downloads, l.download_count, l.revoked, l.create_date_time,
+	(select max(d.create_date_time) from share_link_download d where d.chat_id = l.chat_id and d.link_id = l.id)`
+
+func scanShareLink(scanner interface{ Scan(dest ...any) error }) (*dto.ShareLink, error) {
+	l := dto.ShareLink{}
+	err := scanner.Scan(&l.ChatId, &l.Id, &l.FileItemUuid, &l.Filename, &l.OwnerId, &l.PasswordHash, &l.ExpiresAt, &l.MaxDownloads, &l.DownloadCount, &l.Revoked, &l.CreateDateTime, &l.LastDownloadDateTime)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func CreateShareLink(ctx context.Context, co CommonOperations, link dto.ShareLink) error {
+	_, err := co.ExecContext(ctx, `insert into share_link(chat_id, id, file_item_uuid, filename, owner_user_id, password_hash, expires_at, max_downloads)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		link.ChatId, link.Id, link.FileItemUuid, link.Filename, link.OwnerId, link.PasswordHash, link.ExpiresAt, link.MaxDownloads)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// returns nil if there is no such link
+func GetShareLink(ctx context.Context, co CommonOperations, chatId int64, id string) (*dto.ShareLink, error) {
+	row := co.QueryRowContext(ctx, `select `+shareLinkColumns+` from share_link l where (l.chat_id, l.id) = ($1, $2)`, chatId, id)
+	if row.Err() != nil {
+		return nil, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	l, err := scanShareLink(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// there were no rows, but otherwise no error occurred
+			return nil, nil
+		}
+		return nil, eris.Wrap(err, "error during scanning from db")
+	}
+	return l, nil
+}
+
+func GetShareLinksOfFile(ctx context.Context, co CommonOperations, metadataCacheId dto.MetadataCacheId) ([]dto.ShareLink, error) {
+	list := make([]dto.ShareLink, 0)
+	rows, err := co.QueryContext(ctx, `select `+shareLinkColumns+` from share_link l
+		where (l.chat_id, l.file_item_uuid, l.filename) = ($1, $2, $3)
+		order by l.create_date_time desc`,
+		metadataCacheId.ChatId, metadataCacheId.FileItemUuid, metadataCacheId.Filename)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		l, err := scanShareLink(rows)
+		if err != nil {
+			return nil, eris.Wrap(err, "error during scanning")
+		}
+		list = append(list, *l)
+	}
+	return list, nil
+}
+
+func CountShareLinksOfFile(ctx context.Context, co CommonOperations, metadataCacheId dto.MetadataCacheId) (int64, error) {
+	var count int64
+	row := co.QueryRowContext(ctx, `select count(*) from share_link where (chat_id, file_item_uuid, filename) = ($1, $2, $3)`,
+		metadataCacheId.ChatId, metadataCacheId.FileItemUuid, metadataCacheId.Filename)
+	if row.Err() != nil {
+		return 0, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	err := row.Scan(&count)
+	if err != nil {
+		return 0, eris.Wrap(err, "error during scanning from db")
+	}
+	return count, nil
+}
+
+func RevokeShareLink(ctx context.Context, co CommonOperations, chatId int64, id string) error {
+	_, err := co.ExecContext(ctx, `update share_link set revoked = true where (chat_id, id) = ($1, $2)`, chatId, id)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// the links are useless without the file, the downloads are removed by cascade
+func RemoveShareLinksOfFile(ctx context.Context, co CommonOperations, metadataCacheId dto.MetadataCacheId) error {
+	_, err := co.ExecContext(ctx, `delete from share_link where (chat_id, file_item_uuid, filename) = ($1, $2, $3)`,
+		metadataCacheId.ChatId, metadataCacheId.FileItemUuid, metadataCacheId.Filename)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// the conditions are repeated in the update in order not to exceed max downloads by the concurrent downloads
+// returns false if the link became unavailable
+func RecordShareLinkDownload(ctx context.Context, co CommonOperations, chatId int64, id string) (bool, error) {
+	res, err := co.ExecContext(ctx, `update share_link set download_count = download_count + 1
+		where (chat_id, id) = ($1, $2)
+		and not revoked
+		and (expires_at is null or expires_at > utc_now())
+		and (max_downloads is null or download_count < max_downloads)`,
+		chatId, id)
+	if err != nil {
+		return false, eris.Wrap(err, "error during interacting with db")
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, eris.Wrap(err, "error during interacting with db")
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	_, err = co.ExecContext(ctx, `insert into share_link_download(chat_id, link_id) values ($1, $2)`, chatId, id)
+	if err != nil {
+		return false, eris.Wrap(err, "error during interacting with db")
+	}
+	return true, nil
+}
+
+// the days without downloads are absent
+func GetShareLinkDailyDownloads(ctx context.Context, co CommonOperations, chatId int64, id string, since time.Time) ([]dto.ShareLinkDailyDownloadsDto, error) {
+	list := make([]dto.ShareLinkDailyDownloadsDto, 0)
+	rows, err := co.QueryContext(ctx, `select to_char(date_trunc('day', create_date_time), 'YYYY-MM-DD') as day, count(*)
+		from share_link_download
+		where (chat_id, link_id) = ($1, $2) and create_date_time >= $3
+		group by day
+		order by day`,
+		chatId, id, since)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		d := dto.ShareLinkDailyDownloadsDto{}
+		if err = rows.Scan(&d.Date, &d.Count); err != nil {
+			return nil, eris.Wrap(err, "error during scanning")
+		}
+		list = append(list, d)
+	}
+	return list, nil
+}
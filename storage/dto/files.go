@@ -5,25 +5,50 @@ import (
 )
 
 type FileInfoDto struct {
-	Id             string    `json:"id"`
-	Filename       string    `json:"filename"`
-	Url            string    `json:"url"`
-	PublishedUrl   *string   `json:"publishedUrl"`
-	PreviewUrl     *string   `json:"previewUrl"`
+	Id             string         `json:"id"`
+	Filename       string         `json:"filename"`
+	Url            string         `json:"url"`
+	PublishedUrl   *string        `json:"publishedUrl"`
+	PreviewUrl     *string        `json:"previewUrl"`
+	Size           int64          `json:"size"`
+	CanDelete      bool           `json:"canDelete"`
+	CanEdit        bool           `json:"canEdit"`
+	CanShare       bool           `json:"canShare"`
+	LastModified   time.Time      `json:"lastModified"`
+	CreateDateTime time.Time      `json:"createDateTime"`
+	OwnerId        int64          `json:"ownerId"`
+	Owner          *User          `json:"owner"`
+	CanPlayAsVideo bool           `json:"canPlayAsVideo"`
+	CanShowAsImage bool           `json:"canShowAsImage"`
+	CanPlayAsAudio bool           `json:"canPlayAsAudio"`
+	FileItemUuid   string         `json:"fileItemUuid"`
+	Previewable    bool           `json:"previewable"`
+	Type           *string        `json:"aType"`
+	ScanStatus     string         `json:"scanStatus"`
+	VersionCount   int            `json:"versionCount"`
+	HlsUrl         *string        `json:"hlsUrl"`
+	Media          *MediaMetadata `json:"media"`
+}
+
+const HlsStatusNone = "none"
+const HlsStatusConverting = "converting"
+const HlsStatusReady = "ready"
+
+// the state of the adaptive streaming ladder of the video
+type HlsStatusDto struct {
+	Status   string  `json:"status"`
+	Progress int     `json:"progress"` // in percents
+	Url      *string `json:"url"`      // of the master playlist
+}
+
+// the previous content of the replaced file
+type FileVersionDto struct {
+	VersionId      string    `json:"versionId"`
 	Size           int64     `json:"size"`
-	CanDelete      bool      `json:"canDelete"`
-	CanEdit        bool      `json:"canEdit"`
-	CanShare       bool      `json:"canShare"`
-	LastModified   time.Time `json:"lastModified"`
 	CreateDateTime time.Time `json:"createDateTime"`
 	OwnerId        int64     `json:"ownerId"`
 	Owner          *User     `json:"owner"`
-	CanPlayAsVideo bool      `json:"canPlayAsVideo"`
-	CanShowAsImage bool      `json:"canShowAsImage"`
-	CanPlayAsAudio bool      `json:"canPlayAsAudio"`
-	FileItemUuid   string    `json:"fileItemUuid"`
-	Previewable    bool      `json:"previewable"`
-	Type           *string   `json:"aType"`
+	Url            string    `json:"url"`
 }
 
 type WrappedFileInfoDto struct {
@@ -8,6 +8,12 @@ import (
 const NoFileItemUuid = ""
 const NoChatId = -1
 
+// the verdict of the antivirus
+const ScanStatusNotScanned = "not_scanned" // the antivirus is disabled or the file was uploaded before it was enabled
+const ScanStatusClean = "clean"
+const ScanStatusInfected = "infected"
+const ScanStatusFailed = "failed" // the antivirus was unable to scan the file
+
 type MetadataCache struct {
 	ChatId       int64
 	FileItemUuid string
@@ -18,10 +24,30 @@ type MetadataCache struct {
 
 	Published bool
 
+	ScanStatus string
+
+	VersionCount int
+
 	FileSize int64
 
 	CreateDateTime time.Time
 	EditDateTime   time.Time
+
+	Media MediaMetadata
+}
+
+// the properties of the uploaded media, nil means the property isn't applicable to the file or it wasn't extracted
+type MediaMetadata struct {
+	Width      *int32   `json:"width"`
+	Height     *int32   `json:"height"`
+	Duration   *float64 `json:"duration"` // in seconds
+	VideoCodec *string  `json:"videoCodec"`
+	AudioCodec *string  `json:"audioCodec"`
+	PageCount  *int32   `json:"pageCount"`
+}
+
+func (m *MediaMetadata) IsEmpty() bool {
+	return m.Width == nil && m.Height == nil && m.Duration == nil && m.VideoCodec == nil && m.AudioCodec == nil && m.PageCount == nil
 }
 
 type MetadataCacheId struct {
@@ -0,0 +1,44 @@
+package dto
+
+const QuotaSubjectUser = "user"
+const QuotaSubjectChat = "chat"
+
+// subject id of the default quota for all the users or chats
+const QuotaSubjectDefault = -1
+
+type Quota struct {
+	SubjectType string
+	SubjectId   int64
+	MaxSize     *int64 // nil means unlimited
+}
+
+type QuotaDto struct {
+	SubjectType string `json:"subjectType"`
+	SubjectId   *int64 `json:"subjectId"` // nil means the default for all the users or chats
+	MaxSize     *int64 `json:"maxSize"`   // nil means unlimited
+}
+
+type QuotaUsageDto struct {
+	Used      int64  `json:"used"`
+	Max       *int64 `json:"max"`       // nil means unlimited
+	Available *int64 `json:"available"` // nil means unlimited
+}
+
+type Consumer struct {
+	Id         int64
+	Used       int64
+	FilesCount int64
+}
+
+type ConsumerDto struct {
+	Id         int64   `json:"id"`
+	Login      *string `json:"login,omitempty"` // only for users
+	Used       int64   `json:"used"`
+	FilesCount int64   `json:"filesCount"`
+	Max        *int64  `json:"max"`
+}
+
+type UsageBreakdownDto struct {
+	Users []ConsumerDto `json:"users"`
+	Chats []ConsumerDto `json:"chats"`
+}
@@ -0,0 +1,73 @@
+package dto
+
+import "time"
+
+// the reasons of the share link being unavailable
+const ShareLinkStatusActive = "active"
+const ShareLinkStatusRevoked = "revoked"
+const ShareLinkStatusExpired = "expired"
+const ShareLinkStatusExhausted = "exhausted" // the max downloads reached
+
+type ShareLink struct {
+	ChatId       int64
+	Id           string
+	FileItemUuid string
+	Filename     string
+
+	OwnerId      int64
+	PasswordHash *string    // nil means without password
+	ExpiresAt    *time.Time // nil means forever
+	MaxDownloads *int64     // nil means unlimited
+
+	DownloadCount int64
+	Revoked       bool
+
+	CreateDateTime       time.Time
+	LastDownloadDateTime *time.Time
+}
+
+func (l *ShareLink) GetStatus(now time.Time) string {
+	if l.Revoked {
+		return ShareLinkStatusRevoked
+	}
+	if l.ExpiresAt != nil && !now.Before(*l.ExpiresAt) {
+		return ShareLinkStatusExpired
+	}
+	if l.MaxDownloads != nil && l.DownloadCount >= *l.MaxDownloads {
+		return ShareLinkStatusExhausted
+	}
+	return ShareLinkStatusActive
+}
+
+type CreateShareLinkDto struct {
+	FileId       string     `json:"fileId"`
+	Password     *string    `json:"password"`     // nil or empty means without password
+	ExpiresAt    *time.Time `json:"expiresAt"`    // nil means forever
+	MaxDownloads *int64     `json:"maxDownloads"` // nil means unlimited
+}
+
+type ShareLinkDto struct {
+	Id                   string     `json:"id"`
+	FileId               string     `json:"fileId"`
+	Url                  string     `json:"url"`
+	OwnerId              int64      `json:"ownerId"`
+	HasPassword          bool       `json:"hasPassword"`
+	ExpiresAt            *time.Time `json:"expiresAt"`
+	MaxDownloads         *int64     `json:"maxDownloads"`
+	DownloadCount        int64      `json:"downloadCount"`
+	Status               string     `json:"status"`
+	CreateDateTime       time.Time  `json:"createDateTime"`
+	LastDownloadDateTime *time.Time `json:"lastDownloadDateTime"`
+}
+
+type ShareLinkDailyDownloadsDto struct {
+	Date  string `json:"date"` // 2006-01-02 in UTC
+	Count int64  `json:"count"`
+}
+
+type ShareLinkStatsDto struct {
+	Id                   string                       `json:"id"`
+	DownloadCount        int64                        `json:"downloadCount"`
+	LastDownloadDateTime *time.Time                   `json:"lastDownloadDateTime"`
+	Daily                []ShareLinkDailyDownloadsDto `json:"daily"`
+}
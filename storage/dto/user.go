@@ -16,3 +16,5 @@ type AdditionalData struct {
 	Confirmed bool     `json:"confirmed"`
 	Roles     []string `json:"roles"`
 }
+
+const ROLE_ADMIN = "ROLE_ADMIN"
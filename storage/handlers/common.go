@@ -15,9 +15,10 @@ import (
 	"github.com/prometheus/common/expfmt"
 	"github.com/spf13/viper"
 	"nkonev.name/storage/auth"
+	"nkonev.name/storage/backend"
 	"nkonev.name/storage/client"
+	"nkonev.name/storage/dto"
 	"nkonev.name/storage/logger"
-	"nkonev.name/storage/s3"
 	"nkonev.name/storage/utils"
 )
 
@@ -188,30 +189,85 @@ func calcBucketsConsumption(ctx context.Context, lgr *logger.Logger, restClient
 	return resV, nil
 }
 
-func checkUserLimit(ctx context.Context, lgr *logger.Logger, minioClient *s3.InternalMinioClient, bucketName string, userPrincipalDto *auth.AuthResult, desiredSize int64, restClient *client.RestClient) (bool, int64, int64, error) {
+const limitedByStorage = "storage"
+const limitedByUser = "user"
+const limitedByChat = "chat"
+
+type limitsCheckResult struct {
+	ok bool
+	// used and available of the most restrictive limit
+	used      int64
+	available int64
+	limitedBy string
+
+	// user is nil when the quota of the user is not applied
+	user *dto.QuotaUsageDto
+	chat *dto.QuotaUsageDto
+}
+
+// is implemented by services.QuotaService
+type quotaUsageService interface {
+	GetUserUsage(ctx context.Context, userId int64) (*dto.QuotaUsageDto, error)
+	GetChatUsage(ctx context.Context, chatId int64) (*dto.QuotaUsageDto, error)
+}
+
+// limits.enabled and CAN_UNLIMITED_UPLOAD are about the whole storage.
+// the quotas are checked anyway, CAN_UNLIMITED_UPLOAD lifts the quota of the user, but not the one of the chat
+func checkUserLimit(ctx context.Context, lgr *logger.Logger, minioClient backend.Backend, bucketName string, userPrincipalDto *auth.AuthResult, chatId int64, desiredSize int64, restClient *client.RestClient, quotaService quotaUsageService) (*limitsCheckResult, error) {
 	limitsEnabled := viper.GetBool("limits.enabled")
-	// TODO take into account userId
 	consumption, err := calcBucketsConsumption(ctx, lgr, restClient)
 	if err != nil {
 		lgr.WithTracing(ctx).Errorf("Error during getting consumption %v", err)
-		return false, 0, 0, err
+		return nil, err
 	}
 
-	isUnlimited := (userPrincipalDto != nil && userPrincipalDto.HasPermission("CAN_UNLIMITED_UPLOAD")) || !limitsEnabled
+	canUnlimitedUpload := userPrincipalDto != nil && userPrincipalDto.HasPermission("CAN_UNLIMITED_UPLOAD")
+	isUnlimited := canUnlimitedUpload || !limitsEnabled
 
 	maxAllowed, err := getMaxAllowedConsumption(ctx, lgr, restClient, isUnlimited)
 	if err != nil {
 		lgr.WithTracing(ctx).Errorf("Error during calculating max allowed %v", err)
-		return false, 0, 0, err
+		return nil, err
 	}
 	available := maxAllowed - consumption
 	lgr.WithTracing(ctx).Debugf("Max allowed %v, isUnlimited %v, consumption %v, available %v", maxAllowed, isUnlimited, consumption, available)
 
-	if desiredSize > available {
-		lgr.WithTracing(ctx).Infof("Upload too large %v+%v>%v bytes", consumption, desiredSize, maxAllowed)
-		return false, consumption, available, nil
+	res := &limitsCheckResult{
+		used:      consumption,
+		available: available,
+		limitedBy: limitedByStorage,
+	}
+
+	// one heavy uploader or one chat shouldn't exhaust the storage for everybody
+	if !canUnlimitedUpload && userPrincipalDto != nil {
+		res.user, err = quotaService.GetUserUsage(ctx, userPrincipalDto.UserId)
+		if err != nil {
+			lgr.WithTracing(ctx).Errorf("Error during getting user quota usage %v", err)
+			return nil, err
+		}
+		res.restrictBy(res.user, limitedByUser)
+	}
+
+	res.chat, err = quotaService.GetChatUsage(ctx, chatId)
+	if err != nil {
+		lgr.WithTracing(ctx).Errorf("Error during getting chat quota usage %v", err)
+		return nil, err
+	}
+	res.restrictBy(res.chat, limitedByChat)
+
+	res.ok = desiredSize <= res.available
+	if !res.ok {
+		lgr.WithTracing(ctx).Infof("Upload too large %v+%v>%v bytes, limited by %v", res.used, desiredSize, res.used+res.available, res.limitedBy)
+	}
+	return res, nil
+}
+
+func (res *limitsCheckResult) restrictBy(usage *dto.QuotaUsageDto, limitedBy string) {
+	if usage.Available != nil && *usage.Available < res.available {
+		res.used = usage.Used
+		res.available = *usage.Available
+		res.limitedBy = limitedBy
 	}
-	return true, consumption, available, nil
 }
 
 func cacheableResponse(c echo.Context, ttl time.Duration) {
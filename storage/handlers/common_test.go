@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"nkonev.name/storage/auth"
+	"nkonev.name/storage/client"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+)
+
+const testBucketUsage = 1000
+const testClusterCapacity = 1000000
+
+// answers the metrics requests like minio does
+func startFakeMinioMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/minio/v2/metrics/bucket":
+			fmt.Fprintf(w, "# TYPE minio_bucket_usage_total_bytes gauge\nminio_bucket_usage_total_bytes{bucket=\"files\"} %v\n", testBucketUsage)
+		case "/minio/v2/metrics/cluster":
+			fmt.Fprintf(w, "# TYPE minio_cluster_capacity_usable_total_bytes gauge\nminio_cluster_capacity_usable_total_bytes %v\n", testClusterCapacity)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	viper.Set("minio.secured", false)
+	viper.Set("minio.internalEndpoint", strings.TrimPrefix(server.URL, "http://"))
+}
+
+type fakeQuotaUsageService struct {
+	user map[int64]*dto.QuotaUsageDto
+	chat map[int64]*dto.QuotaUsageDto
+}
+
+func unlimitedUsage() *dto.QuotaUsageDto {
+	return &dto.QuotaUsageDto{}
+}
+
+func limitedUsage(used, max int64) *dto.QuotaUsageDto {
+	available := max - used
+	return &dto.QuotaUsageDto{Used: used, Max: &max, Available: &available}
+}
+
+func (f *fakeQuotaUsageService) GetUserUsage(ctx context.Context, userId int64) (*dto.QuotaUsageDto, error) {
+	if u, ok := f.user[userId]; ok {
+		return u, nil
+	}
+	return unlimitedUsage(), nil
+}
+
+func (f *fakeQuotaUsageService) GetChatUsage(ctx context.Context, chatId int64) (*dto.QuotaUsageDto, error) {
+	if u, ok := f.chat[chatId]; ok {
+		return u, nil
+	}
+	return unlimitedUsage(), nil
+}
+
+func TestCheckUserLimit(t *testing.T) {
+	startFakeMinioMetrics(t)
+	viper.Set("limits.default.all.users.limit", 5000)
+
+	const heavyUser = 1
+	const regularUser = 2
+	const fullChat = 10
+	const emptyChat = 11
+	quotas := &fakeQuotaUsageService{
+		user: map[int64]*dto.QuotaUsageDto{
+			heavyUser:   limitedUsage(900, 1000),
+			regularUser: limitedUsage(0, 1000),
+		},
+		chat: map[int64]*dto.QuotaUsageDto{
+			fullChat:  limitedUsage(1950, 2000),
+			emptyChat: limitedUsage(0, 2000),
+		},
+	}
+
+	cases := []struct {
+		name          string
+		limitsEnabled bool
+		user          *auth.AuthResult
+		chatId        int64
+		size          int64
+		ok            bool
+		limitedBy     string
+	}{
+		{"user under quota", true, &auth.AuthResult{UserId: regularUser}, emptyChat, 500, true, limitedByUser},
+		{"user over quota", true, &auth.AuthResult{UserId: heavyUser}, emptyChat, 500, false, limitedByUser},
+		{"user over quota with disabled limits", false, &auth.AuthResult{UserId: heavyUser}, emptyChat, 500, false, limitedByUser},
+		{"chat over quota", true, &auth.AuthResult{UserId: regularUser}, fullChat, 100, false, limitedByChat},
+		{"chat over quota with disabled limits", false, &auth.AuthResult{UserId: regularUser}, fullChat, 100, false, limitedByChat},
+		{"unlimited user over own quota", true, &auth.AuthResult{UserId: heavyUser, Permissions: []string{"CAN_UNLIMITED_UPLOAD"}}, emptyChat, 500, true, limitedByChat},
+		{"unlimited user in limited chat", true, &auth.AuthResult{UserId: heavyUser, Permissions: []string{"CAN_UNLIMITED_UPLOAD"}}, fullChat, 100, false, limitedByChat},
+		{"storage limit", true, &auth.AuthResult{UserId: 3}, 12, 4500, false, limitedByStorage},
+		{"storage limit with disabled limits", false, &auth.AuthResult{UserId: 3}, 12, 4500, true, limitedByStorage},
+		{"anonymous in limited chat", true, nil, fullChat, 100, false, limitedByChat},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			viper.Set("limits.enabled", c.limitsEnabled)
+			lgr := logger.NewLogger()
+
+			res, err := checkUserLimit(context.Background(), lgr, nil, "files", c.user, c.chatId, c.size, client.NewChatAccessClient(lgr), quotas)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.ok != c.ok {
+				t.Errorf("Expected ok=%v, got %v", c.ok, res.ok)
+			}
+			if res.limitedBy != c.limitedBy {
+				t.Errorf("Expected limited by %v, got %v", c.limitedBy, res.limitedBy)
+			}
+		})
+	}
+}
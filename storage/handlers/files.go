@@ -10,19 +10,18 @@ import (
 	"strings"
 	"time"
 
-	awsS3 "github.com/aws/aws-sdk-go/service/s3"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/tags"
 	"github.com/spf13/viper"
 	"nkonev.name/storage/auth"
+	"nkonev.name/storage/backend"
 	"nkonev.name/storage/client"
 	"nkonev.name/storage/db"
 	"nkonev.name/storage/dto"
 	"nkonev.name/storage/logger"
 	"nkonev.name/storage/producer"
-	"nkonev.name/storage/s3"
 	"nkonev.name/storage/services"
 	"nkonev.name/storage/utils"
 )
@@ -30,12 +29,16 @@ import (
 const headerCorrelationId = "X-CorrelationId"
 
 type FilesHandler struct {
-	minio            *s3.InternalMinioClient
-	awsS3            *awsS3.S3
+	minio            backend.Backend
 	restClient       *client.RestClient
 	minioConfig      *utils.MinioConfig
 	filesService     *services.FilesService
 	redisInfoService *services.RedisInfoService
+	quotaService     *services.QuotaService
+	versionService   *services.VersionService
+	hlsService       *services.HlsService
+	tusService       *services.TusService
+	shareLinkService *services.ShareLinkService
 	dba              *db.DB
 	lgr              *logger.Logger
 	publisher        *producer.RabbitFileUploadedPublisher
@@ -50,23 +53,31 @@ const ConvertingImage = "/images/covers/ffmpeg_converting.jpg"
 
 func NewFilesHandler(
 	lgr *logger.Logger,
-	minio *s3.InternalMinioClient,
-	awsS3 *awsS3.S3,
+	minio backend.Backend,
 	restClient *client.RestClient,
 	minioConfig *utils.MinioConfig,
 	filesService *services.FilesService,
 	redisInfoService *services.RedisInfoService,
+	quotaService *services.QuotaService,
+	versionService *services.VersionService,
+	hlsService *services.HlsService,
+	tusService *services.TusService,
+	shareLinkService *services.ShareLinkService,
 	dba *db.DB,
 	publisher *producer.RabbitFileUploadedPublisher,
 ) *FilesHandler {
 	return &FilesHandler{
 		lgr:              lgr,
 		minio:            minio,
-		awsS3:            awsS3,
 		restClient:       restClient,
 		minioConfig:      minioConfig,
 		filesService:     filesService,
 		redisInfoService: redisInfoService,
+		quotaService:     quotaService,
+		versionService:   versionService,
+		hlsService:       hlsService,
+		tusService:       tusService,
+		shareLinkService: shareLinkService,
 		dba:              dba,
 		publisher:        publisher,
 	}
@@ -163,12 +174,12 @@ func (h *FilesHandler) InitMultipartUpload(c echo.Context) error {
 	}
 
 	// check enough size taking on account free disk space probe (see LimitsHandler)
-	ok, consumption, available, err := checkUserLimit(c.Request().Context(), h.lgr, h.minio, bucketName, userPrincipalDto, reqDto.FileSize, h.restClient)
+	limits, err := checkUserLimit(c.Request().Context(), h.lgr, h.minio, bucketName, userPrincipalDto, chatId, reqDto.FileSize, h.restClient, h.quotaService)
 	if err != nil {
 		return err
 	}
-	if !ok {
-		return c.JSON(http.StatusOK, &utils.H{"status": "oversized", "used": consumption, "available": available})
+	if !limits.ok {
+		return c.JSON(http.StatusOK, &utils.H{"status": "oversized", "used": limits.used, "available": limits.available, "limitedBy": limits.limitedBy})
 	}
 
 	correlationId := c.Request().Header.Get(headerCorrelationId)
@@ -187,14 +198,7 @@ func (h *FilesHandler) InitMultipartUpload(c echo.Context) error {
 
 	expire := viper.GetDuration("minio.multipart.expire")
 	expTime := time.Now().UTC().Add(expire)
-	converted := convertMetadata(&metadata)
-	mpu := awsS3.CreateMultipartUploadInput{
-		Expires:  &expTime,
-		Bucket:   &bucketName,
-		Key:      &aKey,
-		Metadata: converted,
-	}
-	upload, err := h.awsS3.CreateMultipartUpload(&mpu)
+	uploadId, err := h.minio.CreateMultipartUpload(c.Request().Context(), bucketName, aKey, metadata, expTime)
 	if err != nil {
 		return err
 	}
@@ -209,21 +213,12 @@ func (h *FilesHandler) InitMultipartUpload(c echo.Context) error {
 
 	presignedUrls := []PresignedUrl{}
 	for i := 1; i <= chunksNum; i++ {
-		var urlVals = url.Values{}
-		urlVals.Set("partNumber", utils.IntToString(i))
-		urlVals.Set("uploadId", *upload.UploadId)
-
-		u, err := h.minio.Presign(c.Request().Context(), "PUT", bucketName, aKey, uploadDuration, urlVals)
+		stringUrl, err := h.minio.PresignedExternalUploadPart(c.Request().Context(), bucketName, aKey, uploadId, i, uploadDuration)
 		if err != nil {
 			h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting downlad url %v", err)
 			return err
 		}
 
-		stringUrl, err := services.ChangeMinioUrl(u)
-		if err != nil {
-			return err
-		}
-
 		presignedUrls = append(presignedUrls, PresignedUrl{stringUrl, i})
 	}
 
@@ -237,7 +232,7 @@ func (h *FilesHandler) InitMultipartUpload(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, &utils.H{
 		"status":        "ready",
-		"uploadId":      upload.UploadId,
+		"uploadId":      uploadId,
 		"presignedUrls": presignedUrls,
 		"chunkSize":     chunkSize,
 		"fileItemUuid":  chatFileItemUuid,
@@ -249,16 +244,6 @@ func (h *FilesHandler) InitMultipartUpload(c echo.Context) error {
 	})
 }
 
-func convertMetadata(urlValues *map[string]string) map[string]*string {
-	res := map[string]*string{}
-	for k, v := range *urlValues {
-		kk := k
-		vv := v
-		res[kk] = &vv
-	}
-	return res
-}
-
 func (h *FilesHandler) FinishMultipartUpload(c echo.Context) error {
 	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
 	if !ok {
@@ -282,25 +267,16 @@ func (h *FilesHandler) FinishMultipartUpload(c echo.Context) error {
 		return err
 	}
 
-	arr := []*awsS3.CompletedPart{}
+	arr := []backend.CompletedPart{}
 
 	for _, part := range reqDto.Parts {
-		part := part
-		arr = append(arr, &awsS3.CompletedPart{
-			ETag:       &part.Etag,
-			PartNumber: &part.PartNumber,
+		arr = append(arr, backend.CompletedPart{
+			ETag:       part.Etag,
+			PartNumber: part.PartNumber,
 		})
 	}
 
-	input := awsS3.CompleteMultipartUploadInput{
-		Key:      &reqDto.Key,
-		Bucket:   &bucketName,
-		UploadId: &reqDto.UploadId,
-		MultipartUpload: &awsS3.CompletedMultipartUpload{
-			Parts: arr,
-		},
-	}
-	_, err = h.awsS3.CompleteMultipartUpload(&input)
+	err = h.minio.CompleteMultipartUpload(c.Request().Context(), bucketName, reqDto.Key, reqDto.UploadId, arr)
 	if err != nil {
 		return err
 	}
@@ -353,12 +329,24 @@ func (h *FilesHandler) ReplaceHandler(c echo.Context) error {
 	// end check
 
 	fileSize := int64(len(bindTo.Text))
-	userLimitOk, _, _, err := checkUserLimit(c.Request().Context(), h.lgr, h.minio, bucketName, userPrincipalDto, fileSize, h.restClient)
+
+	// only the growth of the file is taken into account
+	desiredSize := fileSize
+	existing, err := db.Get(c.Request().Context(), h.dba, dto.MetadataCacheId{ChatId: chatId, FileItemUuid: fileItemUuid, Filename: bindTo.Filename}, nil)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting the existing file, userId = %v, chatId = %v: %v", userPrincipalDto.UserId, chatId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if existing != nil {
+		desiredSize = max(fileSize-existing.FileSize, 0)
+	}
+
+	limits, err := checkUserLimit(c.Request().Context(), h.lgr, h.minio, bucketName, userPrincipalDto, chatId, desiredSize, h.restClient, h.quotaService)
 	if err != nil {
 		return err
 	}
-	if !userLimitOk {
-		return c.JSON(http.StatusRequestEntityTooLarge, &utils.H{"status": "fail"})
+	if !limits.ok {
+		return c.JSON(http.StatusRequestEntityTooLarge, &utils.H{"status": "fail", "limitedBy": limits.limitedBy})
 	}
 
 	contentType := bindTo.ContentType
@@ -369,6 +357,13 @@ func (h *FilesHandler) ReplaceHandler(c echo.Context) error {
 
 	aKey := services.GetKey(bindTo.Filename, fileItemUuid, chatId)
 
+	// keep the previous content
+	err = h.versionService.Archive(c.Request().Context(), aKey)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during archiving the previous version of %v: %v", aKey, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
 	var userMetadata = services.SerializeMetadataSimple(userPrincipalDto.UserId, nil, nil, nil, utils.GetUnixMilliUtc())
 
 	if _, err := h.minio.PutObject(c.Request().Context(), bucketName, aKey, src, fileSize, minio.PutObjectOptions{ContentType: contentType, UserMetadata: userMetadata}); err != nil {
@@ -796,14 +791,17 @@ func (h *FilesHandler) DeleteHandler(c echo.Context) error {
 			return c.NoContent(http.StatusInternalServerError)
 		}
 
-		if utils.IsVideo(fileId) {
-			previewToCheck := utils.SetVideoPreviewExtension(fileId)
-			err = h.minio.RemoveObject(c.Request().Context(), h.minioConfig.FilesPreview, previewToCheck, minio.RemoveObjectOptions{})
-			if err != nil {
-				h.lgr.WithTracing(c.Request().Context()).Errorf("Error during removing object %v", err)
-			}
-		} else if utils.IsImage(fileId) {
-			previewToCheck := utils.SetImagePreviewExtension(fileId)
+		err = h.versionService.RemoveVersions(c.Request().Context(), services.GetVersionsPrefix(fileId))
+		if err != nil {
+			h.lgr.WithTracing(c.Request().Context()).Errorf("Error during removing versions %v", err)
+		}
+
+		err = h.hlsService.Remove(c.Request().Context(), services.GetHlsPrefix(fileId))
+		if err != nil {
+			h.lgr.WithTracing(c.Request().Context()).Errorf("Error during removing hls %v", err)
+		}
+
+		if previewToCheck := h.filesService.GetPreviewKey(fileId); previewToCheck != "" {
 			err = h.minio.RemoveObject(c.Request().Context(), h.minioConfig.FilesPreview, previewToCheck, minio.RemoveObjectOptions{})
 			if err != nil {
 				h.lgr.WithTracing(c.Request().Context()).Errorf("Error during removing object %v", err)
@@ -854,6 +852,16 @@ func (h *FilesHandler) DeleteHandler(c echo.Context) error {
 			h.lgr.WithTracing(c.Request().Context()).Errorf("Error during removing object %v", err)
 			return c.NoContent(http.StatusInternalServerError)
 		}
+
+		err = h.versionService.RemoveVersions(c.Request().Context(), prefix)
+		if err != nil {
+			h.lgr.WithTracing(c.Request().Context()).Errorf("Error during removing versions %v", err)
+		}
+
+		err = h.hlsService.Remove(c.Request().Context(), prefix)
+		if err != nil {
+			h.lgr.WithTracing(c.Request().Context()).Errorf("Error during removing hls %v", err)
+		}
 	} else {
 		h.lgr.WithTracing(c.Request().Context()).Errorf("Unknown invariant")
 		return c.NoContent(http.StatusInternalServerError)
@@ -947,7 +955,11 @@ func (h *FilesHandler) SetPublic(c echo.Context) error {
 	}
 	// end check
 
-	tagsMap := services.SerializeTags(bindTo.Public)
+	if bindTo.Public && mce.ScanStatus == dto.ScanStatusInfected {
+		return c.JSON(http.StatusForbidden, &utils.H{"status": dto.ScanStatusInfected})
+	}
+
+	tagsMap := services.SerializeTags(bindTo.Public, mce.ScanStatus)
 	objectTags, err := tags.MapToObjectTags(tagsMap)
 	if err != nil {
 		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during mapping tags %v", err)
@@ -1132,32 +1144,34 @@ func (h *FilesHandler) LimitsHandler(c echo.Context) error {
 	if err != nil {
 		return err
 	}
-	ok, consumption, available, err := checkUserLimit(c.Request().Context(), h.lgr, h.minio, bucketName, userPrincipalDto, desiredSize, h.restClient)
+	limits, err := checkUserLimit(c.Request().Context(), h.lgr, h.minio, bucketName, userPrincipalDto, chatId, desiredSize, h.restClient, h.quotaService)
 	if err != nil {
 		return err
 	}
 
-	if !ok {
-		return c.JSON(http.StatusOK, &utils.H{"status": "oversized", "used": consumption, "available": available})
-	} else {
-		return c.JSON(http.StatusOK, &utils.H{"status": "ok", "used": consumption, "available": available})
+	status := "ok"
+	if !limits.ok {
+		status = "oversized"
 	}
+	return c.JSON(http.StatusOK, &utils.H{"status": status, "used": limits.used, "available": limits.available, "limitedBy": limits.limitedBy, "user": limits.user, "chat": limits.chat})
 }
 
 type S3Response struct {
-	AccessKey string            `json:"accessKey"`
-	Secret    string            `json:"secret"`
-	Region    string            `json:"region"`
-	Endpoint  string            `json:"endpoint"`
-	Bucket    string            `json:"bucket"`
-	Metadata  map[string]string `json:"metadata"`
-	Filepath  string            `json:"filepath"`
+	AccessKey    string            `json:"accessKey"`
+	Secret       string            `json:"secret"`
+	Region       string            `json:"region"`
+	Endpoint     string            `json:"endpoint"`
+	Bucket       string            `json:"bucket"`
+	Metadata     map[string]string `json:"metadata"`
+	Filepath     string            `json:"filepath"`
+	FileItemUuid string            `json:"fileItemUuid"`
 }
 
 type S3Request struct {
-	FileName string `json:"fileName"`
-	ChatId   int64  `json:"chatId"`
-	OwnerId  int64  `json:"ownerId"`
+	FileName     string  `json:"fileName"`
+	ChatId       int64   `json:"chatId"`
+	OwnerId      int64   `json:"ownerId"`
+	FileItemUuid *string `json:"fileItemUuid"` // optional, used to put several recordings (e.g. per-participant tracks) into the one file item
 }
 
 func (h *FilesHandler) S3Handler(c echo.Context) error {
@@ -1174,18 +1188,24 @@ func (h *FilesHandler) S3Handler(c echo.Context) error {
 	isConferenceRecording := true
 	metadata := services.SerializeMetadataSimple(bindTo.OwnerId, nil, &isConferenceRecording, nil, utils.GetUnixMilliUtc())
 
-	chatFileItemUuid := utils.GetFileItemId()
+	var chatFileItemUuid string
+	if bindTo.FileItemUuid != nil && len(*bindTo.FileItemUuid) > 0 {
+		chatFileItemUuid = *bindTo.FileItemUuid
+	} else {
+		chatFileItemUuid = utils.GetFileItemId()
+	}
 
 	aKey := services.GetKey(bindTo.FileName, chatFileItemUuid, bindTo.ChatId)
 
 	response := S3Response{
-		AccessKey: accessKeyID,
-		Secret:    secretAccessKey,
-		Region:    viper.GetString("minio.location"),
-		Endpoint:  endpoint,
-		Bucket:    h.minioConfig.Files,
-		Metadata:  metadata,
-		Filepath:  aKey,
+		AccessKey:    accessKeyID,
+		Secret:       secretAccessKey,
+		Region:       viper.GetString("minio.location"),
+		Endpoint:     endpoint,
+		Bucket:       h.minioConfig.Files,
+		Metadata:     metadata,
+		Filepath:     aKey,
+		FileItemUuid: chatFileItemUuid,
 	}
 
 	return c.JSON(http.StatusOK, response)
@@ -1398,6 +1418,16 @@ func (h *FilesHandler) PreviewDownloadHandler(c echo.Context) error {
 	}
 	// end check
 
+	quarantined, scanStatus, err := h.isPreviewQuarantined(c.Request().Context(), objectInfo)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking the scan status %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if quarantined {
+		h.lgr.WithTracing(c.Request().Context()).Infof("Refusing to serve the preview of %v file %v", scanStatus, fileId)
+		return c.JSON(http.StatusForbidden, &utils.H{"status": scanStatus})
+	}
+
 	object, e := h.minio.GetObject(c.Request().Context(), bucketName, fileId, minio.GetObjectOptions{})
 	if e != nil {
 		return c.JSON(http.StatusInternalServerError, &utils.H{"status": "fail"})
@@ -1450,6 +1480,16 @@ func (h *FilesHandler) PublicPreviewDownloadHandler(c echo.Context) error {
 	}
 	// end check
 
+	quarantined, scanStatus, err := h.isPreviewQuarantined(c.Request().Context(), objectInfo)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking the scan status %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if quarantined {
+		h.lgr.WithTracing(c.Request().Context()).Infof("Refusing to serve the preview of %v file %v", scanStatus, fileId)
+		return c.JSON(http.StatusForbidden, &utils.H{"status": scanStatus})
+	}
+
 	object, e := h.minio.GetObject(c.Request().Context(), bucketName, fileId, minio.GetObjectOptions{})
 	if e != nil {
 		return c.JSON(http.StatusInternalServerError, &utils.H{"status": "fail"})
@@ -1514,6 +1554,11 @@ func (h *FilesHandler) DownloadHandler(c echo.Context) error {
 	}
 	// end check
 
+	if services.IsQuarantined(mce.ScanStatus) {
+		h.lgr.WithTracing(c.Request().Context()).Infof("Refusing to serve the %v file %v", mce.ScanStatus, fileId)
+		return c.JSON(http.StatusForbidden, &utils.H{"status": mce.ScanStatus})
+	}
+
 	// send redirect to presigned
 	downloadUrl, ttl, err := h.filesService.GetTemporaryDownloadUrl(c.Request().Context(), fileId)
 	if err != nil {
@@ -1526,6 +1571,33 @@ func (h *FilesHandler) DownloadHandler(c echo.Context) error {
 	return nil
 }
 
+// the infected files are kept, but they aren't served, see services.IsQuarantined. returns the scan status as well
+func (h *FilesHandler) isQuarantined(ctx context.Context, fileId string) (bool, string, error) {
+	mcid, err := utils.BuildMetadataCacheId(fileId)
+	if err != nil {
+		return false, "", err
+	}
+	mce, err := db.Get(ctx, h.dba, *mcid, nil)
+	if err != nil {
+		return false, "", err
+	}
+	// the storage event hasn't been processed yet
+	scanStatus := dto.ScanStatusNotScanned
+	if mce != nil {
+		scanStatus = mce.ScanStatus
+	}
+	return services.IsQuarantined(scanStatus), scanStatus, nil
+}
+
+func (h *FilesHandler) isPreviewQuarantined(ctx context.Context, previewObjectInfo *minio.ObjectInfo) (bool, string, error) {
+	originalKey, err := services.GetOriginalKeyFromMetadata(previewObjectInfo.UserMetadata, false)
+	if err != nil {
+		// there is no original key in the previews which were generated by old versions
+		return false, "", nil
+	}
+	return h.isQuarantined(ctx, originalKey)
+}
+
 func (h *FilesHandler) previewCacheableResponse(c echo.Context) {
 	cacheableResponse(c, viper.GetDuration("response.cache.preview"))
 }
@@ -1604,7 +1676,16 @@ func (h *FilesHandler) PublicDownloadHandler(c echo.Context) error {
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
-	if !isPublic {
+	// the share link is checked instead of the published flag, the download is counted below
+	var shareLink *dto.ShareLink
+	countedDownload := isCountedDownload(c.Request())
+	if linkId := c.QueryParam(utils.ShareLinkParam); len(linkId) > 0 {
+		var valid bool
+		shareLink, valid, err = h.checkShareLink(c, fileId, linkId, countedDownload)
+		if !valid {
+			return err
+		}
+	} else if !isPublic {
 		h.lgr.WithTracing(c.Request().Context()).Infof("File %v is not public, checking is chat blog", fileId)
 
 		chatId, err := utils.ParseChatId(objectInfo.Key)
@@ -1634,6 +1715,30 @@ func (h *FilesHandler) PublicDownloadHandler(c echo.Context) error {
 	}
 	// end check
 
+	quarantined, scanStatus, err := h.isQuarantined(c.Request().Context(), fileId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking the scan status %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if quarantined {
+		h.lgr.WithTracing(c.Request().Context()).Infof("Refusing to serve the %v file %v", scanStatus, fileId)
+		return c.JSON(http.StatusForbidden, &utils.H{"status": scanStatus})
+	}
+
+	if shareLink != nil {
+		if countedDownload {
+			err = h.shareLinkService.RecordDownload(c.Request().Context(), shareLink)
+			if handled, respErr := shareLinkErrorResponse(c, err); handled {
+				return respErr
+			} else if err != nil {
+				h.lgr.WithTracing(c.Request().Context()).Errorf("Error during recording download of share link %v: %v", shareLink.Id, err)
+				return c.NoContent(http.StatusInternalServerError)
+			}
+		}
+		// the presigned url could be used again bypassing the link, so the content goes through here
+		return h.streamShareLinkDownload(c, bucketName, objectInfo)
+	}
+
 	// send redirect to presigned
 	downloadUrl, ttl, err := h.filesService.GetTemporaryDownloadUrl(c.Request().Context(), objectInfo.Key)
 	if err != nil {
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"nkonev.name/storage/auth"
+	"nkonev.name/storage/services"
+	"nkonev.name/storage/utils"
+)
+
+// the user should be a participant of the chat of the video
+func (h *FilesHandler) checkVideoAccess(c echo.Context, fileId string) (int, error) {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return 0, errors.New("Error during getting auth context")
+	}
+
+	chatId, err := utils.ParseChatId(fileId)
+	if err != nil || !utils.IsVideo(fileId) {
+		return http.StatusBadRequest, nil
+	}
+
+	belongs, err := h.restClient.CheckAccess(c.Request().Context(), &userPrincipalDto.UserId, chatId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking user auth to chat %v", err)
+		return http.StatusInternalServerError, nil
+	}
+	if !belongs {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("User %v is not belongs to chat %v", userPrincipalDto.UserId, chatId)
+		return http.StatusUnauthorized, nil
+	}
+
+	quarantined, _, err := h.isQuarantined(c.Request().Context(), fileId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking the scan status %v", err)
+		return http.StatusInternalServerError, nil
+	}
+	if quarantined {
+		return http.StatusForbidden, nil
+	}
+	return http.StatusOK, nil
+}
+
+func (h *FilesHandler) HlsStatus(c echo.Context) error {
+	fileId := c.QueryParam(utils.FileParam)
+	code, err := h.checkVideoAccess(c, fileId)
+	if err != nil {
+		return err
+	}
+	if code != http.StatusOK {
+		return c.NoContent(code)
+	}
+
+	status, err := h.hlsService.GetStatus(c.Request().Context(), fileId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting hls status of %v: %v", fileId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+func (h *FilesHandler) HlsMasterPlaylist(c echo.Context) error {
+	fileId := c.QueryParam(utils.FileParam)
+	code, err := h.checkVideoAccess(c, fileId)
+	if err != nil {
+		return err
+	}
+	if code != http.StatusOK {
+		return c.NoContent(code)
+	}
+
+	exists, err := h.hlsService.Exists(c.Request().Context(), fileId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking hls of %v: %v", fileId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if !exists {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	playlist, err := h.hlsService.GetMasterPlaylist(c.Request().Context(), fileId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting master playlist of %v: %v", fileId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return playlistResponse(c, playlist)
+}
+
+func (h *FilesHandler) HlsRenditionPlaylist(c echo.Context) error {
+	fileId := c.QueryParam(utils.FileParam)
+	rendition := c.QueryParam(utils.RenditionParam)
+	code, err := h.checkVideoAccess(c, fileId)
+	if err != nil {
+		return err
+	}
+	if code != http.StatusOK {
+		return c.NoContent(code)
+	}
+
+	playlist, err := h.hlsService.GetRenditionPlaylist(c.Request().Context(), fileId, rendition)
+	if errors.Is(err, services.ErrHlsRenditionNotFound) {
+		return c.NoContent(http.StatusNotFound)
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting rendition %v playlist of %v: %v", rendition, fileId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return playlistResponse(c, playlist)
+}
+
+// the playlists contain the short-lived presigned urls, so they shouldn't be cached
+func playlistResponse(c echo.Context, playlist string) error {
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.Blob(http.StatusOK, "application/vnd.apple.mpegurl", []byte(playlist))
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/logger"
+)
+
+// serves the signed urls of the local backend, it replaces the s3 endpoint which is proxied to minio
+type LocalBackendHandler struct {
+	local *backend.LocalBackend
+	lgr   *logger.Logger
+}
+
+func NewLocalBackendHandler(lgr *logger.Logger, minio backend.Backend) *LocalBackendHandler {
+	local, _ := minio.(*backend.LocalBackend)
+	return &LocalBackendHandler{
+		local: local,
+		lgr:   lgr,
+	}
+}
+
+func (h *LocalBackendHandler) Enabled() bool {
+	return h.local != nil
+}
+
+func (h *LocalBackendHandler) verify(c echo.Context, method string) error {
+	err := h.local.VerifySignedUrl(method, c.Request().URL.Path, c.QueryParams())
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Infof("Rejected signed url %v: %v", c.Request().URL.Path, err)
+		return c.NoContent(http.StatusForbidden)
+	}
+	return nil
+}
+
+func (h *LocalBackendHandler) Download(c echo.Context) error {
+	// HEAD is signed as GET, the same as in s3
+	if err := h.verify(c, http.MethodGet); err != nil || c.Response().Committed {
+		return err
+	}
+
+	bucketName, key := backend.LocalObjectFromUrl(c.QueryParams())
+	object, err := h.local.GetObject(c.Request().Context(), bucketName, key, minio.GetObjectOptions{})
+	if err != nil {
+		var errResponse minio.ErrorResponse
+		if errors.As(err, &errResponse) && errResponse.Code == "NoSuchKey" {
+			return c.NoContent(http.StatusNotFound)
+		}
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting object %v: %v", key, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	defer object.Close()
+
+	info, err := object.Stat()
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during stat object %v: %v", key, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	contentType := info.ContentType
+	if overridden := c.QueryParam(backend.LocalParamResponseContentType); len(overridden) > 0 {
+		contentType = overridden
+	}
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	c.Response().Header().Set(echo.HeaderContentDisposition, backend.LocalContentDisposition(c.QueryParams(), key))
+	c.Response().Header().Set("ETag", "\""+info.ETag+"\"")
+
+	// supports the ranges which are used by the video player
+	http.ServeContent(c.Response(), c.Request(), "", info.LastModified.In(time.UTC), object)
+	return nil
+}
+
+// the response contains ETag which is sent by the browser on the finish of the multipart upload
+func (h *LocalBackendHandler) UploadPart(c echo.Context) error {
+	if err := h.verify(c, http.MethodPut); err != nil || c.Response().Committed {
+		return err
+	}
+
+	bucketName, key, uploadId, partNumber, err := backend.LocalPartFromUrl(c.QueryParams())
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	// the part is not bigger than the chunk which is given on the init of the multipart upload
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, viper.GetInt64("minio.multipart.chunkSize"))
+	etag, err := h.local.WritePart(c.Request().Context(), bucketName, key, uploadId, partNumber, body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return c.NoContent(http.StatusRequestEntityTooLarge)
+		}
+		var errResponse minio.ErrorResponse
+		if errors.As(err, &errResponse) && errResponse.Code == "NoSuchUpload" {
+			return c.NoContent(http.StatusNotFound)
+		}
+		if errors.Is(err, backend.ErrLocalInvalidName) {
+			return c.NoContent(http.StatusBadRequest)
+		}
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during writing part %v of %v: %v", partNumber, key, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	c.Response().Header().Set("ETag", etag)
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/logger"
+)
+
+const testPartKey = "chat/1/0b3a2c1e-55a4-4bd2-9d0f-111111111111/video.mp4"
+
+func newTestLocalBackendHandler(t *testing.T) (*LocalBackendHandler, *backend.LocalBackend) {
+	lgr := logger.NewLogger()
+	local, err := backend.NewLocalBackend(lgr, t.TempDir(), "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := local.MakeBucket(context.Background(), "files", minio.MakeBucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	return NewLocalBackendHandler(lgr, local), local
+}
+
+func uploadPart(h *LocalBackendHandler, signedUrl, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, signedUrl, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	if err := h.UploadPart(c); err != nil {
+		c.Error(err)
+	}
+	return rec
+}
+
+func TestUploadPartLimitsBody(t *testing.T) {
+	viper.Set("minio.multipart.chunkSize", 8)
+	h, local := newTestLocalBackendHandler(t)
+	ctx := context.Background()
+
+	uploadId, err := local.CreateMultipartUpload(ctx, "files", testPartKey, map[string]string{}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedUrl, err := local.PresignedExternalUploadPart(ctx, "files", testPartKey, uploadId, 1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// one byte more than the chunk
+	if rec := uploadPart(h, signedUrl, "the chunk"); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected %v, got %v", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+
+	rec := uploadPart(h, signedUrl, "a chunk!")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %v, got %v", http.StatusOK, rec.Code)
+	}
+	etag := rec.Header().Get("ETag")
+	if err := local.CompleteMultipartUpload(ctx, "files", testPartKey, uploadId, []backend.CompletedPart{{PartNumber: 1, ETag: etag}}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := local.StatObject(ctx, "files", testPartKey, minio.StatObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != 8 {
+		t.Errorf("unexpected size %v", info.Size)
+	}
+}
+
+func TestUploadPartRequiresSignature(t *testing.T) {
+	viper.Set("minio.multipart.chunkSize", 8)
+	h, local := newTestLocalBackendHandler(t)
+	ctx := context.Background()
+
+	uploadId, err := local.CreateMultipartUpload(ctx, "files", testPartKey, map[string]string{}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedUrl, err := local.PresignedExternalUploadPart(ctx, "files", testPartKey, uploadId, 1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := uploadPart(h, strings.Replace(signedUrl, "partNumber=1", "partNumber=2", 1), "chunk"); rec.Code != http.StatusForbidden {
+		t.Errorf("expected %v, got %v", http.StatusForbidden, rec.Code)
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"nkonev.name/storage/auth"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/services"
+	"nkonev.name/storage/utils"
+)
+
+type QuotaHandler struct {
+	quotaService *services.QuotaService
+	lgr          *logger.Logger
+}
+
+func NewQuotaHandler(
+	lgr *logger.Logger,
+	quotaService *services.QuotaService,
+) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: quotaService,
+		lgr:          lgr,
+	}
+}
+
+func (h *QuotaHandler) checkIsAdmin(c echo.Context) (bool, error) {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return false, errors.New("Error during getting auth context")
+	}
+	return userPrincipalDto.HasRole(dto.ROLE_ADMIN), nil
+}
+
+func (h *QuotaHandler) GetQuotas(c echo.Context) error {
+	if isAdmin, err := h.checkIsAdmin(c); err != nil {
+		return err
+	} else if !isAdmin {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	quotas, err := h.quotaService.GetQuotas(c.Request().Context())
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting quotas: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, quotas)
+}
+
+// sets either the quota of the particular user or chat, or the default one in case subjectId is absent
+func (h *QuotaHandler) SetQuota(c echo.Context) error {
+	if isAdmin, err := h.checkIsAdmin(c); err != nil {
+		return err
+	} else if !isAdmin {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	var bindTo = new(dto.QuotaDto)
+	if err := c.Bind(bindTo); err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Warnf("Error during binding to dto %v", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	err := h.quotaService.SetQuota(c.Request().Context(), bindTo)
+	if errors.Is(err, services.ErrInvalidQuota) {
+		return c.NoContent(http.StatusBadRequest)
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during setting quota: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *QuotaHandler) RemoveQuota(c echo.Context) error {
+	if isAdmin, err := h.checkIsAdmin(c); err != nil {
+		return err
+	} else if !isAdmin {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	var subjectId *int64
+	if subjectIdString := c.QueryParam("subjectId"); subjectIdString != "" {
+		parsed, err := utils.ParseInt64(subjectIdString)
+		if err != nil {
+			return c.NoContent(http.StatusBadRequest)
+		}
+		subjectId = &parsed
+	}
+
+	err := h.quotaService.RemoveQuota(c.Request().Context(), c.QueryParam("subjectType"), subjectId)
+	if errors.Is(err, services.ErrInvalidQuota) {
+		return c.NoContent(http.StatusBadRequest)
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during removing quota: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// top consumers by user and by chat
+func (h *QuotaHandler) GetUsageBreakdown(c echo.Context) error {
+	if isAdmin, err := h.checkIsAdmin(c); err != nil {
+		return err
+	} else if !isAdmin {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	page := utils.FixPageString(c.QueryParam("page"))
+	size := utils.FixSizeString(c.QueryParam("size"))
+	offset := utils.GetOffset(page, size)
+
+	breakdown, err := h.quotaService.GetUsageBreakdown(c.Request().Context(), size, offset)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting usage breakdown: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, breakdown)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/minio/minio-go/v7"
+	"nkonev.name/storage/auth"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/services"
+	"nkonev.name/storage/utils"
+)
+
+// the header is preferred over the query parameter in order not to get the password into the access logs
+const headerShareLinkPassword = "X-Share-Link-Password"
+const shareLinkPasswordParam = "password"
+
+func getShareLinkPassword(c echo.Context) string {
+	if password := c.Request().Header.Get(headerShareLinkPassword); len(password) > 0 {
+		return password
+	}
+	return c.QueryParam(shareLinkPasswordParam)
+}
+
+// maps the errors of the share link service onto the response, returns false if the error isn't related to the share links
+func shareLinkErrorResponse(c echo.Context, err error) (bool, error) {
+	var unavailableError *services.ShareLinkUnavailableError
+	switch {
+	case errors.Is(err, services.ErrInvalidShareLink):
+		return true, c.NoContent(http.StatusBadRequest)
+	case errors.Is(err, services.ErrShareLinkNotFound):
+		return true, c.NoContent(http.StatusNotFound)
+	case errors.Is(err, services.ErrShareLinkForbidden):
+		return true, c.NoContent(http.StatusUnauthorized)
+	case errors.Is(err, services.ErrShareLinkInfected):
+		return true, c.JSON(http.StatusForbidden, &utils.H{"status": dto.ScanStatusInfected})
+	case errors.Is(err, services.ErrTooManyShareLinks):
+		return true, c.JSON(http.StatusConflict, &utils.H{"status": "too_many_links"})
+	case errors.Is(err, services.ErrShareLinkPasswordRequired):
+		return true, c.JSON(http.StatusUnauthorized, &utils.H{"status": "password_required"})
+	case errors.Is(err, services.ErrShareLinkWrongPassword):
+		return true, c.JSON(http.StatusUnauthorized, &utils.H{"status": "wrong_password"})
+	case errors.Is(err, services.ErrShareLinkTooManyAttempts):
+		return true, c.JSON(http.StatusTooManyRequests, &utils.H{"status": "too_many_attempts"})
+	case errors.As(err, &unavailableError):
+		return true, c.JSON(http.StatusGone, &utils.H{"status": unavailableError.Status})
+	default:
+		return false, nil
+	}
+}
+
+func (h *FilesHandler) CreateShareLink(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	var bindTo = new(dto.CreateShareLinkDto)
+	if err := c.Bind(bindTo); err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Warnf("Error during binding to dto %v", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	link, err := h.shareLinkService.Create(c.Request().Context(), userPrincipalDto.UserId, bindTo)
+	if handled, respErr := shareLinkErrorResponse(c, err); handled {
+		return respErr
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during creating share link for %v: %v", bindTo.FileId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, link)
+}
+
+func (h *FilesHandler) ListShareLinks(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	fileId := c.QueryParam(utils.FileParam)
+	links, err := h.shareLinkService.List(c.Request().Context(), userPrincipalDto.UserId, fileId)
+	if handled, respErr := shareLinkErrorResponse(c, err); handled {
+		return respErr
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during listing share links of %v: %v", fileId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, links)
+}
+
+func (h *FilesHandler) RevokeShareLink(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return err
+	}
+
+	err = h.shareLinkService.Revoke(c.Request().Context(), userPrincipalDto.UserId, chatId, c.Param("id"))
+	if handled, respErr := shareLinkErrorResponse(c, err); handled {
+		return respErr
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during revoking share link %v: %v", c.Param("id"), err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *FilesHandler) ShareLinkStats(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return err
+	}
+
+	stats, err := h.shareLinkService.GetStats(c.Request().Context(), userPrincipalDto.UserId, chatId, c.Param("id"))
+	if handled, respErr := shareLinkErrorResponse(c, err); handled {
+		return respErr
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting stats of share link %v: %v", c.Param("id"), err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+// the link replaces the check of published flag, so it works for the non-published files as well
+func (h *FilesHandler) checkShareLink(c echo.Context, fileId, linkId string, counted bool) (*dto.ShareLink, bool, error) {
+	link, err := h.shareLinkService.Check(c.Request().Context(), fileId, linkId, getShareLinkPassword(c), counted)
+	if handled, respErr := shareLinkErrorResponse(c, err); handled {
+		h.lgr.WithTracing(c.Request().Context()).Infof("Share link %v of file %v can't be used: %v", linkId, fileId, err)
+		return nil, false, respErr
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking share link %v: %v", linkId, err)
+		return nil, false, c.NoContent(http.StatusInternalServerError)
+	}
+	return link, true, nil
+}
+
+// the download is counted once, the range requests which resume it or fetch the parts aren't
+func isCountedDownload(request *http.Request) bool {
+	rangeHeader := strings.TrimSpace(request.Header.Get("Range"))
+	if len(rangeHeader) == 0 {
+		return true
+	}
+	ranges, found := strings.CutPrefix(rangeHeader, "bytes=")
+	if !found {
+		// the server ignores the unknown unit and sends the whole content
+		return true
+	}
+	return strings.HasPrefix(strings.TrimSpace(ranges), "0-")
+}
+
+func (h *FilesHandler) streamShareLinkDownload(c echo.Context, bucketName string, objectInfo *minio.ObjectInfo) error {
+	object, err := h.minio.GetObject(c.Request().Context(), bucketName, objectInfo.Key, minio.GetObjectOptions{})
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting object %v: %v", objectInfo.Key, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	defer object.Close()
+
+	// every download through the link should be counted
+	c.Response().Header().Set("Cache-Control", "no-store")
+	if len(objectInfo.ContentType) > 0 {
+		c.Response().Header().Set(echo.HeaderContentType, objectInfo.ContentType)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": services.ReadFilename(objectInfo.Key)}))
+
+	// supports the ranges in order to resume the download, the same as the presigned url
+	http.ServeContent(c.Response(), c.Request(), "", objectInfo.LastModified.In(time.UTC), object)
+	return nil
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/minio/minio-go/v7"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/logger"
+)
+
+const testSharedKey = "chat/1/0b3a2c1e-55a4-4bd2-9d0f-111111111111/report.txt"
+
+func downloadShared(t *testing.T, h *FilesHandler, objectInfo *minio.ObjectInfo, rangeHeader string) *http.Response {
+	req := httptest.NewRequest(http.MethodGet, "/public/download", nil)
+	if len(rangeHeader) > 0 {
+		req.Header.Set("Range", rangeHeader)
+	}
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	if err := h.streamShareLinkDownload(c, "files", objectInfo); err != nil {
+		c.Error(err)
+	}
+	return rec.Result()
+}
+
+func TestShareLinkDownloadIsStreamed(t *testing.T) {
+	ctx := context.Background()
+	lgr := logger.NewLogger()
+	local, err := backend.NewLocalBackend(lgr, t.TempDir(), "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := local.MakeBucket(ctx, "files", minio.MakeBucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	const content = "the shared content"
+	_, err = local.PutObject(ctx, "files", testSharedKey, strings.NewReader(content), int64(len(content)), minio.PutObjectOptions{ContentType: "text/plain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	objectInfo, err := local.StatObject(ctx, "files", testSharedKey, minio.StatObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &FilesHandler{minio: local, lgr: lgr}
+
+	resp := downloadShared(t, h, &objectInfo, "")
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != content {
+		t.Fatalf("the content should be sent, got %v %q", resp.StatusCode, body)
+	}
+	// there is no url which could be used without the link
+	if location := resp.Header.Get("Location"); len(location) > 0 {
+		t.Errorf("there should be no redirect, got %v", location)
+	}
+	if cacheControl := resp.Header.Get("Cache-Control"); cacheControl != "no-store" {
+		t.Errorf("the download shouldn't be cached, got %v", cacheControl)
+	}
+	if contentType := resp.Header.Get(echo.HeaderContentType); contentType != "text/plain" {
+		t.Errorf("unexpected content type %v", contentType)
+	}
+	if disposition := resp.Header.Get(echo.HeaderContentDisposition); disposition != "attachment; filename=report.txt" {
+		t.Errorf("unexpected disposition %v", disposition)
+	}
+
+	resp = downloadShared(t, h, &objectInfo, "bytes=4-9")
+	body, _ = io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusPartialContent || string(body) != "shared" {
+		t.Errorf("the range should be sent, got %v %q", resp.StatusCode, body)
+	}
+}
+
+func TestShareLinkCountedDownload(t *testing.T) {
+	cases := []struct {
+		rangeHeader string
+		counted     bool
+	}{
+		{"", true},
+		{"bytes=0-", true},
+		{"bytes=0-99", true},
+		{"bytes= 0-99, 200-299", true},
+		{"bytes=100-", false},
+		{"bytes=-100", false},
+		{"bytes=100-199, 0-99", false},
+		{"items=5-", true},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/public/download", nil)
+		if len(c.rangeHeader) > 0 {
+			req.Header.Set("Range", c.rangeHeader)
+		}
+		if counted := isCountedDownload(req); counted != c.counted {
+			t.Errorf("the download with range %q should be counted %v, got %v", c.rangeHeader, c.counted, counted)
+		}
+	}
+}
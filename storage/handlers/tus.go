@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"nkonev.name/storage/auth"
+	"nkonev.name/storage/services"
+	"nkonev.name/storage/utils"
+)
+
+// https://tus.io/protocols/resumable-upload
+const tusVersion = "1.0.0"
+const tusExtensions = "creation,termination,expiration"
+const tusContentType = "application/offset+octet-stream"
+
+const headerTusResumable = "Tus-Resumable"
+const headerUploadOffset = "Upload-Offset"
+const headerUploadLength = "Upload-Length"
+const headerUploadMetadata = "Upload-Metadata"
+const headerUploadExpires = "Upload-Expires"
+
+// not the part of the protocol, they are needed to send the message with the file
+const headerFileItemUuid = "File-Item-Uuid"
+const headerFileKey = "File-Key"
+
+// "filename ZmlsZS50eHQ=,fileItemUuid MDFI..." - the values are base64 encoded, the value can be absent
+func parseTusMetadata(header string) (map[string]string, error) {
+	res := map[string]string{}
+	if len(strings.TrimSpace(header)) == 0 {
+		return res, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if len(key) == 0 {
+			return nil, errors.New("empty key in metadata")
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+		res[key] = string(value)
+	}
+	return res, nil
+}
+
+func getTusUploadUrl(chatId int64, id string) string {
+	return fmt.Sprintf("/api/storage/%v/tus/%v", chatId, id)
+}
+
+func setTusUploadHeaders(c echo.Context, upload *services.TusUpload) {
+	c.Response().Header().Set(headerUploadOffset, utils.Int64ToString(upload.Offset))
+	c.Response().Header().Set(headerUploadExpires, upload.ExpiresAt.Format(http.TimeFormat))
+	c.Response().Header().Set(headerFileItemUuid, upload.FileItemUuid)
+	c.Response().Header().Set(headerFileKey, upload.Key)
+}
+
+// every request except OPTIONS should declare the version of the protocol
+func checkTusResumable(c echo.Context) bool {
+	c.Response().Header().Set(headerTusResumable, tusVersion)
+	if c.Request().Header.Get(headerTusResumable) != tusVersion {
+		c.Response().Header().Set("Tus-Version", tusVersion)
+		return false
+	}
+	return true
+}
+
+func (h *FilesHandler) TusOptions(c echo.Context) error {
+	c.Response().Header().Set(headerTusResumable, tusVersion)
+	c.Response().Header().Set("Tus-Version", tusVersion)
+	c.Response().Header().Set("Tus-Extension", tusExtensions)
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *FilesHandler) TusCreate(c echo.Context) error {
+	if !checkTusResumable(c) {
+		return c.NoContent(http.StatusPreconditionFailed)
+	}
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return err
+	}
+	if ok, err := h.restClient.CheckAccess(c.Request().Context(), &userPrincipalDto.UserId, chatId); err != nil {
+		return c.NoContent(http.StatusInternalServerError)
+	} else if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	// Upload-Defer-Length isn't supported, the size is needed to check the limits
+	fileSize, err := utils.ParseInt64(c.Request().Header.Get(headerUploadLength))
+	if err != nil || fileSize < 0 {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	metadata, err := parseTusMetadata(c.Request().Header.Get(headerUploadMetadata))
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Warnf("Error during parsing upload metadata %v", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if len(metadata["filename"]) == 0 {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	bucketName := h.minioConfig.Files
+
+	// generated for the first file
+	chatFileItemUuid := utils.GetFileItemId()
+	if fileItemUuid := metadata["fileItemUuid"]; fileItemUuid != "" {
+		// and reused for the subsequent
+		chatFileItemUuid = fileItemUuid
+	}
+
+	// check this fileItem belongs to user
+	belongs, err := h.checkFileItemBelongsToUser(chatFileItemUuid, c, chatId, bucketName, userPrincipalDto)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking belongs, userId = %v, chatId = %v: %v", userPrincipalDto.UserId, chatId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if !belongs {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+	// end check
+
+	filteredFilename := utils.CleanFilename(c.Request().Context(), h.lgr, metadata["filename"], utils.GetBoolean(metadata["shouldAddDateToTheFilename"]))
+
+	aKey := services.GetKey(filteredFilename, chatFileItemUuid, chatId)
+
+	// check that this file does not exist
+	exists, _, err := h.minio.FileExists(c.Request().Context(), bucketName, aKey)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf(err.Error())
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if exists {
+		h.lgr.WithTracing(c.Request().Context()).Infof("Conflict for: %v", aKey)
+		return c.JSON(http.StatusConflict, &utils.H{"status": "error", "message": fmt.Sprintf("Already exists: %v", aKey)})
+	}
+
+	limits, err := checkUserLimit(c.Request().Context(), h.lgr, h.minio, bucketName, userPrincipalDto, chatId, fileSize, h.restClient, h.quotaService)
+	if err != nil {
+		return err
+	}
+	if !limits.ok {
+		return c.JSON(http.StatusRequestEntityTooLarge, &utils.H{"status": "oversized", "used": limits.used, "available": limits.available, "limitedBy": limits.limitedBy})
+	}
+
+	correlationId := c.Request().Header.Get(headerCorrelationId)
+	var correlationIdP *string
+	if correlationId != "" {
+		_, err = uuid.Parse(correlationId)
+		if err != nil {
+			return c.NoContent(http.StatusBadRequest)
+		}
+		correlationIdP = &correlationId
+	}
+
+	var isMessageRecording *bool
+	if isMessageRecordingStr, ok := metadata["isMessageRecording"]; ok {
+		isMessageRecordingValue := utils.GetBoolean(isMessageRecordingStr)
+		isMessageRecording = &isMessageRecordingValue
+	}
+
+	objectMetadata := services.SerializeMetadataSimple(userPrincipalDto.UserId, correlationIdP, nil, isMessageRecording, utils.GetUnixMilliUtc())
+	upload, err := h.tusService.Create(c.Request().Context(), chatId, userPrincipalDto.UserId, aKey, chatFileItemUuid, fileSize, objectMetadata)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during creating upload, userId = %v, chatId = %v: %v", userPrincipalDto.UserId, chatId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	setTusUploadHeaders(c, upload)
+	c.Response().Header().Set(echo.HeaderLocation, getTusUploadUrl(chatId, upload.Id))
+	return c.NoContent(http.StatusCreated)
+}
+
+// only the owner can continue the upload
+func (h *FilesHandler) getTusUpload(c echo.Context) (*services.TusUpload, int, error) {
+	if !checkTusResumable(c) {
+		return nil, http.StatusPreconditionFailed, nil
+	}
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return nil, 0, errors.New("Error during getting auth context")
+	}
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return nil, http.StatusBadRequest, nil
+	}
+
+	upload, err := h.tusService.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting upload %v", err)
+		return nil, http.StatusInternalServerError, nil
+	}
+	// the absent upload is either completed, terminated or expired
+	if upload == nil || upload.ChatId != chatId || upload.OwnerId != userPrincipalDto.UserId {
+		return nil, http.StatusNotFound, nil
+	}
+
+	if ok, err := h.restClient.CheckAccess(c.Request().Context(), &userPrincipalDto.UserId, chatId); err != nil {
+		return nil, http.StatusInternalServerError, nil
+	} else if !ok {
+		return nil, http.StatusUnauthorized, nil
+	}
+	return upload, http.StatusOK, nil
+}
+
+func (h *FilesHandler) TusHead(c echo.Context) error {
+	upload, code, err := h.getTusUpload(c)
+	if err != nil {
+		return err
+	}
+	if code != http.StatusOK {
+		return c.NoContent(code)
+	}
+
+	setTusUploadHeaders(c, upload)
+	c.Response().Header().Set(headerUploadLength, utils.Int64ToString(upload.Length))
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *FilesHandler) TusPatch(c echo.Context) error {
+	upload, code, err := h.getTusUpload(c)
+	if err != nil {
+		return err
+	}
+	if code != http.StatusOK {
+		return c.NoContent(code)
+	}
+
+	if c.Request().Header.Get(echo.HeaderContentType) != tusContentType {
+		return c.NoContent(http.StatusUnsupportedMediaType)
+	}
+	offset, err := utils.ParseInt64(c.Request().Header.Get(headerUploadOffset))
+	if err != nil || offset < 0 {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if contentLength := c.Request().ContentLength; contentLength > 0 && offset+contentLength > upload.Length {
+		return c.NoContent(http.StatusRequestEntityTooLarge)
+	}
+
+	upload, err = h.tusService.Write(c.Request().Context(), upload.Id, offset, c.Request().Body)
+	if errors.Is(err, services.ErrTusOffsetMismatch) {
+		return c.NoContent(http.StatusConflict)
+	} else if errors.Is(err, services.ErrTusLocked) || errors.Is(err, services.ErrTusLockLost) {
+		return c.NoContent(http.StatusLocked)
+	} else if errors.Is(err, services.ErrTusUploadNotFound) {
+		return c.NoContent(http.StatusNotFound)
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during writing to upload %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	setTusUploadHeaders(c, upload)
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *FilesHandler) TusTerminate(c echo.Context) error {
+	upload, code, err := h.getTusUpload(c)
+	if err != nil {
+		return err
+	}
+	if code != http.StatusOK {
+		return c.NoContent(code)
+	}
+
+	err = h.tusService.Terminate(c.Request().Context(), upload)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during terminating upload %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"nkonev.name/storage/auth"
+	"nkonev.name/storage/db"
+	"nkonev.name/storage/services"
+	"nkonev.name/storage/utils"
+)
+
+type RestoreVersionDto struct {
+	Id        string `json:"id"` // file id
+	VersionId string `json:"versionId"`
+}
+
+// the file should belong to the chat from the url
+func (h *FilesHandler) checkFileOfChat(c echo.Context, fileId string) (int64, int, error) {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return 0, 0, errors.New("Error during getting auth context")
+	}
+
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return 0, 0, err
+	}
+	fileChatId, err := utils.ParseChatId(fileId)
+	if err != nil || fileChatId != chatId {
+		return 0, http.StatusBadRequest, nil
+	}
+
+	if ok, err := h.restClient.CheckAccess(c.Request().Context(), &userPrincipalDto.UserId, chatId); err != nil {
+		return 0, http.StatusInternalServerError, nil
+	} else if !ok {
+		return 0, http.StatusUnauthorized, nil
+	}
+	return chatId, http.StatusOK, nil
+}
+
+func (h *FilesHandler) ListVersions(c echo.Context) error {
+	fileId := c.QueryParam(utils.FileParam)
+	_, code, err := h.checkFileOfChat(c, fileId)
+	if err != nil {
+		return err
+	}
+	if code != http.StatusOK {
+		return c.NoContent(code)
+	}
+
+	versions, err := h.versionService.GetVersions(c.Request().Context(), fileId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting versions of %v: %v", fileId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, versions)
+}
+
+func (h *FilesHandler) RestoreVersion(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	var bindTo = new(RestoreVersionDto)
+	if err := c.Bind(bindTo); err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Warnf("Error during binding to dto %v", err)
+		return err
+	}
+
+	chatId, code, err := h.checkFileOfChat(c, bindTo.Id)
+	if err != nil {
+		return err
+	}
+	if code != http.StatusOK {
+		return c.NoContent(code)
+	}
+
+	mcid, err := utils.BuildMetadataCacheId(bindTo.Id)
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	// check this fileItem belongs to user
+	belongs, err := h.checkFileItemBelongsToUser(mcid.FileItemUuid, c, chatId, h.minioConfig.Files, userPrincipalDto)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking belongs, userId = %v, chatId = %v: %v", userPrincipalDto.UserId, chatId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if !belongs {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+	// end check
+
+	versionInfo, err := h.versionService.StatVersion(c.Request().Context(), bindTo.Id, bindTo.VersionId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting the version %v of %v: %v", bindTo.VersionId, bindTo.Id, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if versionInfo == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	// only the growth of the file is taken into account
+	desiredSize := versionInfo.Size
+	existing, err := db.Get(c.Request().Context(), h.dba, *mcid, nil)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting the existing file, userId = %v, chatId = %v: %v", userPrincipalDto.UserId, chatId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if existing != nil {
+		desiredSize = max(versionInfo.Size-existing.FileSize, 0)
+	}
+
+	limits, err := checkUserLimit(c.Request().Context(), h.lgr, h.minio, h.minioConfig.Files, userPrincipalDto, chatId, desiredSize, h.restClient, h.quotaService)
+	if err != nil {
+		return err
+	}
+	if !limits.ok {
+		return c.JSON(http.StatusRequestEntityTooLarge, &utils.H{"status": "fail", "limitedBy": limits.limitedBy})
+	}
+
+	err = h.versionService.Restore(c.Request().Context(), bindTo.Id, bindTo.VersionId, userPrincipalDto.UserId)
+	if errors.Is(err, services.ErrVersionNotFound) {
+		return c.NoContent(http.StatusNotFound)
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during restoring the version %v of %v: %v", bindTo.VersionId, bindTo.Id, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *FilesHandler) DownloadVersion(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	fileId := c.QueryParam(utils.FileParam)
+	versionId := c.QueryParam(utils.VersionIdParam)
+
+	chatId, err := utils.ParseChatId(fileId)
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	belongs, err := h.restClient.CheckAccess(c.Request().Context(), &userPrincipalDto.UserId, chatId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking user auth to chat %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if !belongs {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("User %v is not belongs to chat %v", userPrincipalDto.UserId, chatId)
+		return c.NoContent(http.StatusUnauthorized)
+	}
+	// end check
+
+	versionInfo, err := h.versionService.StatVersion(c.Request().Context(), fileId, versionId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting the version %v of %v: %v", versionId, fileId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if versionInfo == nil {
+		return c.Redirect(http.StatusTemporaryRedirect, NotFoundImage)
+	}
+
+	quarantined, scanStatus, err := h.versionService.IsVersionQuarantined(c.Request().Context(), fileId, versionId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking the scan status %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if quarantined {
+		h.lgr.WithTracing(c.Request().Context()).Infof("Refusing to serve the %v version %v of %v", scanStatus, versionId, fileId)
+		return c.JSON(http.StatusForbidden, &utils.H{"status": scanStatus})
+	}
+
+	// send redirect to presigned
+	downloadUrl, ttl, err := h.versionService.GetTemporaryDownloadUrl(c.Request().Context(), fileId, versionId)
+	if err != nil {
+		return err
+	}
+
+	cacheableResponse(c, ttl)
+	c.Response().Header().Set("Location", downloadUrl)
+	c.Response().WriteHeader(http.StatusTemporaryRedirect)
+	return nil
+}
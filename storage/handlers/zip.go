@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/auth"
+	"nkonev.name/storage/services"
+	"nkonev.name/storage/utils"
+)
+
+func (h *FilesHandler) ZipHandler(c echo.Context) error {
+	return h.zipHandler(c, false)
+}
+
+func (h *FilesHandler) PublicZipHandler(c echo.Context) error {
+	return h.zipHandler(c, true)
+}
+
+// streams the archive of all the files of the file item or of the explicitly listed files of the chat
+func (h *FilesHandler) zipHandler(c echo.Context, public bool) error {
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return err
+	}
+
+	fileItemUuid := c.QueryParam("fileItemUuid")
+	fileIds := c.QueryParams()[utils.FileParam]
+	if (len(fileItemUuid) == 0) == (len(fileIds) == 0) {
+		return c.JSON(http.StatusBadRequest, &utils.H{"status": "fail", "message": "either fileItemUuid or file should be present"})
+	}
+
+	// check user belongs to chat
+	if !public {
+		var userPrincipalDto, _ = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+		if userPrincipalDto == nil {
+			return c.NoContent(http.StatusUnauthorized)
+		}
+		belongs, err := h.restClient.CheckAccess(c.Request().Context(), &userPrincipalDto.UserId, chatId)
+		if err != nil {
+			h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking user auth to chat %v", err)
+			return c.NoContent(http.StatusInternalServerError)
+		}
+		if !belongs {
+			h.lgr.WithTracing(c.Request().Context()).Errorf("User %v is not belongs to chat %v", userPrincipalDto.UserId, chatId)
+			return c.NoContent(http.StatusUnauthorized)
+		}
+	}
+
+	objects, code, err := h.getZipObjects(c.Request().Context(), chatId, fileItemUuid, fileIds)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting objects for zip, chatId = %v: %v", chatId, err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if code != http.StatusOK {
+		return c.NoContent(code)
+	}
+
+	maxFiles := viper.GetInt("zip.maxFiles")
+	if len(objects) > maxFiles {
+		return c.JSON(http.StatusBadRequest, &utils.H{"status": "fail", "message": fmt.Sprintf("too many files, the max is %v", maxFiles)})
+	}
+
+	if public {
+		allowed, err := h.checkPublicZipAccess(c, chatId, objects)
+		if err != nil {
+			h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking public access to chat %v: %v", chatId, err)
+			return c.NoContent(http.StatusInternalServerError)
+		}
+		if !allowed {
+			return c.NoContent(http.StatusUnauthorized)
+		}
+	}
+	// end check
+
+	servedObjects, err := h.skipQuarantined(c.Request().Context(), objects, h.isQuarantined)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during checking the scan status %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if len(servedObjects) == 0 {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	archiveName := fmt.Sprintf("chat-%v-files.zip", chatId)
+	if len(fileItemUuid) > 0 {
+		archiveName = fileItemUuid + ".zip"
+	}
+	c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=\""+archiveName+"\"")
+	c.Response().WriteHeader(http.StatusOK)
+
+	// the status is already sent, so the client sees the broken archive in case of error
+	err = h.filesService.WriteZip(c.Request().Context(), c.Response(), servedObjects)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during streaming zip, chatId = %v: %v", chatId, err)
+	}
+	return nil
+}
+
+// the rest of the files are served, isQuarantined is h.isQuarantined
+func (h *FilesHandler) skipQuarantined(ctx context.Context, objects []minio.ObjectInfo, isQuarantined func(ctx context.Context, fileId string) (bool, string, error)) ([]minio.ObjectInfo, error) {
+	servedObjects := []minio.ObjectInfo{}
+	for _, objInfo := range objects {
+		quarantined, scanStatus, err := isQuarantined(ctx, objInfo.Key)
+		if err != nil {
+			return nil, err
+		}
+		if quarantined {
+			h.lgr.WithTracing(ctx).Infof("Skipping the %v file %v in zip", scanStatus, objInfo.Key)
+			continue
+		}
+		servedObjects = append(servedObjects, objInfo)
+	}
+	return servedObjects, nil
+}
+
+func (h *FilesHandler) getZipObjects(ctx context.Context, chatId int64, fileItemUuid string, fileIds []string) ([]minio.ObjectInfo, int, error) {
+	objects := []minio.ObjectInfo{}
+	if len(fileItemUuid) > 0 {
+		var fileObjects <-chan minio.ObjectInfo = h.minio.ListObjects(ctx, h.minioConfig.Files, minio.ListObjectsOptions{
+			Prefix:    fmt.Sprintf("chat/%v/%v/", chatId, fileItemUuid),
+			Recursive: true,
+		})
+		for objInfo := range fileObjects {
+			if objInfo.Err != nil {
+				return nil, 0, objInfo.Err
+			}
+			objects = append(objects, objInfo)
+		}
+		if len(objects) == 0 {
+			return nil, http.StatusNotFound, nil
+		}
+		return objects, http.StatusOK, nil
+	}
+
+	for _, fileId := range fileIds {
+		// the files should be from the chat from the url
+		fileChatId, err := utils.ParseChatId(fileId)
+		if err != nil || fileChatId != chatId {
+			return nil, http.StatusBadRequest, nil
+		}
+		exists, objInfo, err := h.minio.FileExists(ctx, h.minioConfig.Files, fileId)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !exists {
+			return nil, http.StatusNotFound, nil
+		}
+		objects = append(objects, *objInfo)
+	}
+	return objects, http.StatusOK, nil
+}
+
+// the same rules as in PublicDownloadHandler - every file should be either published or belong to the blog
+func (h *FilesHandler) checkPublicZipAccess(c echo.Context, chatId int64, objects []minio.ObjectInfo) (bool, error) {
+	overrideChatId := getOverrideChatIdPublic(c)
+	overrideMessageId := getOverrideMessageIdPublic(c)
+
+	checkedFileItems := map[string]bool{}
+	for _, objInfo := range objects {
+		tagging, err := h.minio.GetObjectTagging(c.Request().Context(), h.minioConfig.Files, objInfo.Key, minio.GetObjectTaggingOptions{})
+		if err != nil {
+			return false, err
+		}
+		isPublic, err := services.DeserializeTags(tagging)
+		if err != nil {
+			return false, err
+		}
+		if isPublic {
+			continue
+		}
+
+		fileItemUuid, err := utils.ParseFileItemUuid(objInfo.Key)
+		if err != nil {
+			return false, err
+		}
+		belongs, checked := checkedFileItems[fileItemUuid]
+		if !checked {
+			belongs, err = h.restClient.CheckAccessExtended(c.Request().Context(), nil, chatId, overrideChatId, overrideMessageId, fileItemUuid)
+			if err != nil {
+				return false, err
+			}
+			checkedFileItems[fileItemUuid] = belongs
+		}
+		if !belongs {
+			h.lgr.WithTracing(c.Request().Context()).Errorf("File %v is not public", objInfo.Key)
+			return false, nil
+		}
+	}
+	return true, nil
+}
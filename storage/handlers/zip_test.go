@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/auth"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/client"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/services"
+	"nkonev.name/storage/utils"
+)
+
+const testZipFileItem = "0b3a2c1e-55a4-4bd2-9d0f-222222222222"
+
+// the user 5 is the participant of the chat 1 only
+func startFakeChatAccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("chatId") == "1" && r.URL.Query().Get("userId") == "5" {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(server.Close)
+	viper.Set("chat.url.base", server.URL)
+	viper.Set("chat.url.access", "/internal/access")
+}
+
+func newTestZipHandler(t *testing.T, files ...string) *FilesHandler {
+	startFakeChatAccess(t)
+	viper.Set("zip.maxFiles", 2)
+
+	ctx := context.Background()
+	lgr := logger.NewLogger()
+	local, err := backend.NewLocalBackend(lgr, t.TempDir(), "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minioConfig := &utils.MinioConfig{Files: "files"}
+	if err := local.MakeBucket(ctx, minioConfig.Files, minio.MakeBucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range files {
+		_, err = local.PutObject(ctx, minioConfig.Files, key, strings.NewReader(key), int64(len(key)), minio.PutObjectOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	restClient := client.NewChatAccessClient(lgr)
+	return &FilesHandler{
+		minio:        local,
+		restClient:   restClient,
+		minioConfig:  minioConfig,
+		filesService: services.NewFilesService(lgr, local, restClient, nil, minioConfig, nil),
+		lgr:          lgr,
+	}
+}
+
+func requestZip(t *testing.T, h *FilesHandler, chatId string, query url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/storage/"+chatId+"/zip?"+query.Encode(), nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("chatId")
+	c.SetParamValues(chatId)
+	c.Set(utils.USER_PRINCIPAL_DTO, &auth.AuthResult{UserId: 5})
+	if err := h.ZipHandler(c); err != nil {
+		c.Error(err)
+	}
+	return rec
+}
+
+func TestZipOfForeignChat(t *testing.T) {
+	foreignKey := fmt.Sprintf("chat/2/%v/secret.txt", testZipFileItem)
+	h := newTestZipHandler(t, foreignKey)
+
+	// the user isn't the participant of the chat 2
+	rec := requestZip(t, h, "2", url.Values{"fileItemUuid": {testZipFileItem}})
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected %v, got %v", http.StatusUnauthorized, rec.Code)
+	}
+
+	// the file of the chat 2 can't be requested through the chat 1
+	rec = requestZip(t, h, "1", url.Values{utils.FileParam: {foreignKey}})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected %v, got %v", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestZipMaxFiles(t *testing.T) {
+	files := []string{}
+	for i := range 3 {
+		files = append(files, fmt.Sprintf("chat/1/%v/file%v.txt", testZipFileItem, i))
+	}
+	h := newTestZipHandler(t, files...)
+
+	rec := requestZip(t, h, "1", url.Values{"fileItemUuid": {testZipFileItem}})
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "the max is 2") {
+		t.Errorf("the file item over the limit should be refused, got %v %v", rec.Code, rec.Body.String())
+	}
+
+	rec = requestZip(t, h, "1", url.Values{utils.FileParam: files})
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "the max is 2") {
+		t.Errorf("the files over the limit should be refused, got %v %v", rec.Code, rec.Body.String())
+	}
+}
+
+func TestZipWithoutFiles(t *testing.T) {
+	h := newTestZipHandler(t)
+
+	rec := requestZip(t, h, "1", url.Values{"fileItemUuid": {testZipFileItem}})
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected %v, got %v", http.StatusNotFound, rec.Code)
+	}
+	rec = requestZip(t, h, "1", url.Values{})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("either file item or files are required, got %v", rec.Code)
+	}
+}
+
+func TestZipSkipsQuarantined(t *testing.T) {
+	h := &FilesHandler{lgr: logger.NewLogger()}
+	statuses := map[string]string{
+		"chat/1/item/clean.txt":    dto.ScanStatusClean,
+		"chat/1/item/infected.txt": dto.ScanStatusInfected,
+		"chat/1/item/failed.txt":   dto.ScanStatusFailed,
+		"chat/1/item/other.txt":    dto.ScanStatusClean,
+	}
+	isQuarantined := func(ctx context.Context, fileId string) (bool, string, error) {
+		return services.IsQuarantined(statuses[fileId]), statuses[fileId], nil
+	}
+	viper.Set("antivirus.enabled", true)
+	viper.Set("antivirus.failOpen", false)
+
+	objects := []minio.ObjectInfo{{Key: "chat/1/item/clean.txt"}, {Key: "chat/1/item/infected.txt"}, {Key: "chat/1/item/failed.txt"}, {Key: "chat/1/item/other.txt"}}
+	served, err := h.skipQuarantined(context.Background(), objects, isQuarantined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{}
+	for _, objInfo := range served {
+		keys = append(keys, objInfo.Key)
+	}
+	if expected := []string{"chat/1/item/clean.txt", "chat/1/item/other.txt"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+
+	broken := func(ctx context.Context, fileId string) (bool, string, error) {
+		return false, "", errors.New("db is down")
+	}
+	if _, err := h.skipQuarantined(context.Background(), objects, broken); err == nil {
+		t.Errorf("the error of the check should be returned")
+	}
+}
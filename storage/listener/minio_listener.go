@@ -8,11 +8,11 @@ import (
 	"github.com/streadway/amqp"
 	"github.com/tidwall/gjson"
 	"go.opentelemetry.io/otel"
+	"nkonev.name/storage/backend"
 	"nkonev.name/storage/client"
 	"nkonev.name/storage/db"
 	"nkonev.name/storage/dto"
 	"nkonev.name/storage/logger"
-	"nkonev.name/storage/s3"
 	"nkonev.name/storage/services"
 	"nkonev.name/storage/utils"
 )
@@ -25,8 +25,12 @@ func CreateMinioEventsListener(
 	eventService *services.EventService,
 	client *client.RestClient,
 	minioConfig *utils.MinioConfig,
-	minioClient *s3.InternalMinioClient,
+	minioClient backend.Backend,
 	convertingService *services.ConvertingService,
+	antivirusService *services.AntivirusService,
+	versionService *services.VersionService,
+	hlsService *services.HlsService,
+	mediaService *services.MediaService,
 	dba *db.DB,
 ) MinioEventsListener {
 	tr := otel.Tracer("amqp/listener")
@@ -52,6 +56,12 @@ func CreateMinioEventsListener(
 		}
 		timestamp := userMetadata.Get(services.TimestampKey(true)).Int() // unix milli in UTC
 
+		// the scrubbing rewrites the image during the processing of its creation or update, so the event of the rewriting is redundant
+		if eventName == utils.ObjectCreatedPut && userMetadata.Get(services.ScrubbedKey(true)).Bool() {
+			lgr.WithTracing(ctx).Debugf("Skipping the event of the scrubbing of %v", key)
+			return nil
+		}
+
 		normalizedKey := utils.StripBucketName(key, minioConfig.Files)
 		workingChatId, err := utils.ParseChatId(normalizedKey)
 		if err != nil {
@@ -83,7 +93,32 @@ func CreateMinioEventsListener(
 		if isEventForEventService(eventType) {
 			eventServiceResponse = eventService.HandleEvent(ctx, normalizedKey, workingChatId, eventType)
 		}
-		if isEventForPreviewService(eventType, previewAlreadyExists, normalizedKey) {
+
+		scanStatus := dto.ScanStatusNotScanned
+		var justScanned bool
+		if eventType == utils.FILE_CREATED || eventType == utils.FILE_UPDATED {
+			scanStatus, justScanned, err = scanAndQuarantine(ctx, lgr, antivirusService, normalizedKey, eventServiceResponse, msg.Redelivered)
+			if err != nil {
+				return err
+			}
+		}
+		infected := scanStatus == dto.ScanStatusInfected
+		if infected {
+			eventForConvertingService = false
+		}
+
+		// the file is parsed only after the clean verdict, the scrubbing goes before the preview, so neither the preview nor the participants get the private metadata
+		if isEventForScrubbing(eventType, eventName, scanStatus) && mediaService.IsScrubbingEnabled() {
+			scrubbed, err := mediaService.Scrub(ctx, normalizedKey)
+			if err != nil {
+				lgr.WithTracing(ctx).Errorf("Error during scrubbing %v, the original is kept: %v", normalizedKey, err)
+			} else if scrubbed {
+				// the size has changed
+				eventServiceResponse = eventService.HandleEvent(ctx, normalizedKey, workingChatId, eventType)
+			}
+		}
+
+		if isEventForPreviewService(eventType, previewAlreadyExists, normalizedKey, previewService) && !infected {
 			previewServiceResponse = previewService.HandleMinioEvent(ctx, minioEvent, eventForConvertingService)
 		}
 
@@ -93,11 +128,23 @@ func CreateMinioEventsListener(
 		case utils.FILE_CREATED:
 			fallthrough
 		case utils.FILE_UPDATED:
-			mce, err = createdDbEntity(normalizedKey, workingChatId, ownerId, correlationId, timestamp, eventServiceResponse)
+			mce, err = createdDbEntity(normalizedKey, workingChatId, ownerId, correlationId, timestamp, scanStatus, eventServiceResponse)
 			if err != nil {
 				lgr.WithTracing(ctx).Errorf("Error during creating db entity: %v", err)
 				return err
 			}
+			mce.VersionCount, err = versionService.CountVersions(ctx, normalizedKey)
+			if err != nil {
+				lgr.WithTracing(ctx).Errorf("Error during counting versions: %v", err)
+				return err
+			}
+			if !infected {
+				mce.Media, err = getMedia(ctx, mediaService, dba, eventName, normalizedKey)
+				if err != nil {
+					lgr.WithTracing(ctx).Errorf("Error during getting media metadata: %v", err)
+					return err
+				}
+			}
 			err = db.Set(ctx, dba, *mce)
 			if err != nil {
 				lgr.WithTracing(ctx).Errorf("Error during saving to database: %v", err)
@@ -114,6 +161,11 @@ func CreateMinioEventsListener(
 				lgr.WithTracing(ctx).Errorf("Error during removing from database: %v", err)
 				return err
 			}
+			err = db.RemoveShareLinksOfFile(ctx, dba, *mck)
+			if err != nil {
+				lgr.WithTracing(ctx).Errorf("Error during removing share links from database: %v", err)
+				return err
+			}
 		default:
 			return fmt.Errorf("Unknown case %v", eventType)
 		}
@@ -122,7 +174,7 @@ func CreateMinioEventsListener(
 			if isEventForEventService(eventType) {
 				eventService.SendToParticipants(ctx, normalizedKey, workingChatId, eventType, participantIds, eventServiceResponse, mce)
 			}
-			if isEventForPreviewService(eventType, previewAlreadyExists, normalizedKey) {
+			if isEventForPreviewService(eventType, previewAlreadyExists, normalizedKey, previewService) && !infected {
 				previewService.SendToParticipants(ctx, minioEvent, participantIds, previewServiceResponse)
 			}
 			return nil
@@ -130,10 +182,17 @@ func CreateMinioEventsListener(
 		if err != nil {
 			lgr.WithTracing(ctx).Errorf("Error during getting participant ids: %v", err)
 		}
+		if infected && justScanned {
+			eventService.SendInfectedToOwner(ctx, normalizedKey, workingChatId, eventServiceResponse, mce)
+		}
 		// because converting is longer than creating the preview, we do this long job in the end, after sending preview_created event
 		if eventForConvertingService {
 			convertingService.HandleEvent(ctx, minioEvent)
 		}
+		// the recording gets the hls from its converted copy
+		if isEventForHlsService(eventType, minioEvent, eventForConvertingService, infected, hlsService) {
+			hlsService.HandleEvent(ctx, minioEvent)
+		}
 
 		return nil
 	}
@@ -147,8 +206,8 @@ func isEventForEventService(eventType utils.EventType) bool {
 	}
 }
 
-func isEventForPreviewService(eventType utils.EventType, previewExists bool, normalizedKey string) bool {
-	return eventType == utils.FILE_CREATED && !previewExists && utils.IsPreviewable(normalizedKey)
+func isEventForPreviewService(eventType utils.EventType, previewExists bool, normalizedKey string, previewService *services.PreviewService) bool {
+	return eventType == utils.FILE_CREATED && !previewExists && previewService.HasPreviewer(normalizedKey)
 }
 
 func isEventForConvertingService(eventType utils.EventType, minioEvent *dto.MinioEvent, previewExists, isMessageRecording bool) bool {
@@ -158,7 +217,15 @@ func isEventForConvertingService(eventType utils.EventType, minioEvent *dto.Mini
 		!previewExists // prevents the indefinite converting
 }
 
-func isPreviewAlreadyExists(ctx context.Context, lgr *logger.Logger, minioConfig *utils.MinioConfig, minioClient *s3.InternalMinioClient, normalizedKey string) (bool, error) {
+func isEventForHlsService(eventType utils.EventType, minioEvent *dto.MinioEvent, eventForConvertingService, infected bool, hlsService *services.HlsService) bool {
+	return eventType == utils.FILE_CREATED &&
+		hlsService.IsEnabled() &&
+		utils.IsVideo(minioEvent.Key) &&
+		!eventForConvertingService &&
+		!infected
+}
+
+func isPreviewAlreadyExists(ctx context.Context, lgr *logger.Logger, minioConfig *utils.MinioConfig, minioClient backend.Backend, normalizedKey string) (bool, error) {
 	previewKey := utils.SetVideoPreviewExtension(normalizedKey)
 	exists, _, err := minioClient.FileExists(ctx, minioConfig.FilesPreview, previewKey)
 	if err != nil {
@@ -167,7 +234,79 @@ func isPreviewAlreadyExists(ctx context.Context, lgr *logger.Logger, minioConfig
 	return exists, err
 }
 
-func createdDbEntity(normalizedKey string, chatId, ownerId int64, correlationId *string, timestamp int64, eventServiceResponse *services.HandleEventResponse) (*dto.MetadataCache, error) {
+// the created file or the rewritten content, e.g. the edited text or the restored version, the tagging doesn't change the content.
+// the clean one or the one which isn't scanned because the antivirus is disabled
+func isEventForScrubbing(eventType utils.EventType, eventName string, scanStatus string) bool {
+	contentChanged := eventType == utils.FILE_CREATED || (eventType == utils.FILE_UPDATED && eventName == utils.ObjectCreatedPut)
+	return contentChanged && (scanStatus == dto.ScanStatusClean || scanStatus == dto.ScanStatusNotScanned)
+}
+
+// the download of the file is refused till it gets the clean verdict, see services.IsQuarantined
+func scanAndQuarantine(ctx context.Context, lgr *logger.Logger, antivirusService *services.AntivirusService, normalizedKey string, eventServiceResponse *services.HandleEventResponse, redelivered bool) (string, bool, error) {
+	scanStatus, justScanned, err := scan(ctx, lgr, antivirusService, normalizedKey, eventServiceResponse, redelivered)
+	if err != nil {
+		return "", false, err
+	}
+	if scanStatus == dto.ScanStatusInfected {
+		antivirusService.Quarantine(ctx, normalizedKey)
+	}
+	return scanStatus, justScanned, nil
+}
+
+// the file which was scanned already has the verdict in its tags, e.g. the event is caused by the tagging,
+// otherwise the file is new or replaced, so it is scanned before it becomes downloadable.
+// the failed scanning is retried once by the redelivery, then the file gets dto.ScanStatusFailed and is scanned again by the actualization task
+func scan(ctx context.Context, lgr *logger.Logger, antivirusService *services.AntivirusService, normalizedKey string, eventServiceResponse *services.HandleEventResponse, redelivered bool) (string, bool, error) {
+	if scanStatus, ok := eventServiceResponse.GetScanStatus(); ok {
+		return scanStatus, false, nil
+	}
+	if !antivirusService.IsEnabled() {
+		return dto.ScanStatusNotScanned, false, nil
+	}
+
+	publishedP, err := eventServiceResponse.GetTags()
+	if err != nil {
+		return "", false, err
+	}
+	var published bool
+	if publishedP != nil {
+		published = *publishedP
+	}
+
+	scanStatus, err := antivirusService.Scan(ctx, normalizedKey)
+	if err != nil {
+		if !redelivered {
+			return "", false, &retryableError{err: err}
+		}
+		lgr.WithTracing(ctx).Errorf("Error during scanning the redelivered %v, it will be scanned by the actualization task: %v", normalizedKey, err)
+		return dto.ScanStatusFailed, false, nil
+	}
+	err = antivirusService.StoreVerdict(ctx, normalizedKey, published, scanStatus)
+	if err != nil {
+		lgr.WithTracing(ctx).Errorf("Error during storing the verdict for %v: %v", normalizedKey, err)
+	}
+	return scanStatus, true, nil
+}
+
+// the tagging doesn't change the content, so the properties extracted before are kept
+func getMedia(ctx context.Context, mediaService *services.MediaService, dba *db.DB, eventName, normalizedKey string) (dto.MediaMetadata, error) {
+	if eventName == utils.ObjectCreatedPutTagging {
+		mcid, err := utils.BuildMetadataCacheId(normalizedKey)
+		if err != nil {
+			return dto.MediaMetadata{}, err
+		}
+		existing, err := db.Get(ctx, dba, *mcid, nil)
+		if err != nil {
+			return dto.MediaMetadata{}, err
+		}
+		if existing != nil {
+			return existing.Media, nil
+		}
+	}
+	return mediaService.Extract(ctx, normalizedKey), nil
+}
+
+func createdDbEntity(normalizedKey string, chatId, ownerId int64, correlationId *string, timestamp int64, scanStatus string, eventServiceResponse *services.HandleEventResponse) (*dto.MetadataCache, error) {
 	fileItemUuid, err := utils.ParseFileItemUuid(normalizedKey)
 	if err != nil {
 		return nil, err
@@ -188,6 +327,9 @@ func createdDbEntity(normalizedKey string, chatId, ownerId int64, correlationId
 	if publishedP != nil {
 		published = *publishedP
 	}
+	if scanStatus == dto.ScanStatusInfected {
+		published = false
+	}
 
 	objInfo := eventServiceResponse.GetObjectInfo()
 	if objInfo == nil {
@@ -201,6 +343,7 @@ func createdDbEntity(normalizedKey string, chatId, ownerId int64, correlationId
 		OwnerId:        ownerId,
 		CorrelationId:  correlationId,
 		Published:      published,
+		ScanStatus:     scanStatus,
 		FileSize:       objInfo.Size,
 		CreateDateTime: eventTime,
 		EditDateTime:   eventTime,
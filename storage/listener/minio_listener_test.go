@@ -0,0 +1,376 @@
+package listener
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/client"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/services"
+	"nkonev.name/storage/utils"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+const testFileKey = "chat/1/0b3a2c1e-55a4-4bd2-9d0f-111111111111/photo.png"
+
+// answers like clamd does, hangs without the answer when hang is set
+type fakeClamd struct {
+	listener net.Listener
+	scans    atomic.Int64
+	hang     atomic.Bool
+}
+
+func startFakeClamd(t *testing.T, hang bool) *fakeClamd {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeClamd{listener: listener}
+	f.hang.Store(hang)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.handle(conn)
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+	})
+	return f
+}
+
+func (f *fakeClamd) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	if _, err := reader.ReadString('\x00'); err != nil {
+		return
+	}
+	var content bytes.Buffer
+	sizeBuf := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(reader, sizeBuf); err != nil {
+			return
+		}
+		size := binary.BigEndian.Uint32(sizeBuf)
+		if size == 0 {
+			break
+		}
+		if _, err := io.CopyN(&content, reader, int64(size)); err != nil {
+			return
+		}
+	}
+	f.scans.Add(1)
+	if f.hang.Load() {
+		io.Copy(io.Discard, conn)
+		return
+	}
+	if strings.Contains(content.String(), "EICAR-STANDARD-ANTIVIRUS-TEST-FILE") {
+		conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+	} else {
+		conn.Write([]byte("stream: OK\x00"))
+	}
+}
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []backend.Event
+}
+
+func (p *recordingPublisher) PublishStorageEvent(ctx context.Context, event backend.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) eventNames() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := []string{}
+	for _, e := range p.events {
+		names = append(names, e.EventName)
+	}
+	return names
+}
+
+type pipeline struct {
+	backend     *backend.LocalBackend
+	publisher   *recordingPublisher
+	clamd       *fakeClamd
+	minioConfig *utils.MinioConfig
+	antivirus   *services.AntivirusService
+	events      *services.EventService
+	previewKey  string
+}
+
+func newPipeline(t *testing.T, hang bool) *pipeline {
+	clamd := startFakeClamd(t, hang)
+	viper.Set("antivirus.enabled", true)
+	viper.Set("antivirus.failOpen", false)
+	viper.Set("antivirus.address", clamd.listener.Addr().String())
+	viper.Set("antivirus.timeout", 5*time.Second)
+	viper.Set("antivirus.scanTimeout", 5*time.Second)
+	viper.Set("types.image", []string{".png"})
+
+	lgr := logger.NewLogger()
+	publisher := &recordingPublisher{}
+	localBackend, err := backend.NewLocalBackend(lgr, t.TempDir(), "secret", publisher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minioConfig := &utils.MinioConfig{Files: "files", FilesPreview: "files-preview"}
+	ctx := context.Background()
+	for _, bucket := range []string{minioConfig.Files, minioConfig.FilesPreview} {
+		if err := localBackend.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := localBackend.SubscribeEvents(ctx, minioConfig.Files); err != nil {
+		t.Fatal(err)
+	}
+
+	previewerRegistry := services.NewPreviewerRegistry(lgr, localBackend, minioConfig)
+	return &pipeline{
+		backend:     localBackend,
+		publisher:   publisher,
+		clamd:       clamd,
+		minioConfig: minioConfig,
+		antivirus:   services.NewAntivirusService(lgr, client.NewClamdClient(lgr), localBackend, minioConfig, previewerRegistry),
+		events:      services.NewEventService(lgr, client.NewChatAccessClient(lgr), localBackend, minioConfig, nil, nil),
+		previewKey:  previewerRegistry.GetPreviewKey(testFileKey),
+	}
+}
+
+// uploads the file and its preview which could be generated before
+func (p *pipeline) upload(t *testing.T, content string) {
+	ctx := context.Background()
+	_, err := p.backend.PutObject(ctx, p.minioConfig.Files, testFileKey, strings.NewReader(content), int64(len(content)), minio.PutObjectOptions{
+		UserMetadata: services.SerializeMetadataSimple(5, nil, nil, nil, time.Now().UnixMilli()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.backend.PutObject(ctx, p.minioConfig.FilesPreview, p.previewKey, strings.NewReader("preview"), 7, minio.PutObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// what the listener does for the created or updated file
+func (p *pipeline) deliver(t *testing.T, eventType utils.EventType, redelivered bool) (string, bool, error) {
+	ctx := context.Background()
+	response := p.events.HandleEvent(ctx, testFileKey, 1, eventType)
+	if response == nil {
+		t.Fatal("nil response of the event service")
+	}
+	return scanAndQuarantine(ctx, logger.NewLogger(), p.antivirus, testFileKey, response, redelivered)
+}
+
+func (p *pipeline) process(t *testing.T, eventType utils.EventType) (string, bool) {
+	scanStatus, justScanned, err := p.deliver(t, eventType, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scanStatus, justScanned
+}
+
+func (p *pipeline) previewExists(t *testing.T) bool {
+	exists, _, err := p.backend.FileExists(context.Background(), p.minioConfig.FilesPreview, p.previewKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return exists
+}
+
+func (p *pipeline) storedVerdict(t *testing.T) (string, bool) {
+	tagging, err := p.backend.GetObjectTagging(context.Background(), p.minioConfig.Files, testFileKey, minio.GetObjectTaggingOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return services.DeserializeScanStatusTag(tagging)
+}
+
+func TestInfectedUploadIsQuarantined(t *testing.T) {
+	p := newPipeline(t, false)
+	p.upload(t, eicar)
+
+	scanStatus, justScanned := p.process(t, utils.FILE_CREATED)
+	if scanStatus != dto.ScanStatusInfected || !justScanned {
+		t.Fatalf("Expected just scanned infected, got %v %v", scanStatus, justScanned)
+	}
+	if verdict, ok := p.storedVerdict(t); !ok || verdict != dto.ScanStatusInfected {
+		t.Errorf("Expected the stored verdict, got %v %v", verdict, ok)
+	}
+	if p.previewExists(t) {
+		t.Error("Expected the preview to be removed")
+	}
+	if !services.IsQuarantined(scanStatus) {
+		t.Error("Expected the download to be refused")
+	}
+
+	// the storing of the verdict causes the tagging event, it mustn't cause the scanning again
+	names := p.publisher.eventNames()
+	if len(names) == 0 || names[len(names)-1] != utils.ObjectCreatedPutTagging {
+		t.Fatalf("Expected the tagging event, got %v", names)
+	}
+	scanStatus, justScanned = p.process(t, utils.FILE_UPDATED)
+	if scanStatus != dto.ScanStatusInfected || justScanned {
+		t.Errorf("Expected the stored infected verdict, got %v %v", scanStatus, justScanned)
+	}
+	if scans := p.clamd.scans.Load(); scans != 1 {
+		t.Errorf("Expected one scan, got %v", scans)
+	}
+	// the infected file is never served
+	viper.Set("antivirus.failOpen", true)
+	if !services.IsQuarantined(scanStatus) {
+		t.Error("Expected the download to be refused with failOpen")
+	}
+}
+
+func TestCleanUploadIsServed(t *testing.T) {
+	p := newPipeline(t, false)
+	p.upload(t, "hello, world")
+
+	scanStatus, _ := p.process(t, utils.FILE_CREATED)
+	if scanStatus != dto.ScanStatusClean {
+		t.Fatalf("Expected clean, got %v", scanStatus)
+	}
+	if verdict, ok := p.storedVerdict(t); !ok || verdict != dto.ScanStatusClean {
+		t.Errorf("Expected the stored verdict, got %v %v", verdict, ok)
+	}
+	if !p.previewExists(t) {
+		t.Error("Expected the preview to be kept")
+	}
+	if services.IsQuarantined(scanStatus) {
+		t.Error("Expected the download to be allowed")
+	}
+}
+
+func TestScanTimeoutFailsClosed(t *testing.T) {
+	p := newPipeline(t, true)
+	viper.Set("antivirus.scanTimeout", 200*time.Millisecond)
+	p.upload(t, "hello, world")
+
+	start := time.Now()
+	_, _, err := p.deliver(t, utils.FILE_CREATED, false)
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("The scan isn't bounded, took %v", elapsed)
+	}
+	// the delivery is requeued
+	var retryable *retryableError
+	if !errors.As(err, &retryable) {
+		t.Fatalf("Expected the retryable error, got %v", err)
+	}
+
+	scanStatus, justScanned, err := p.deliver(t, utils.FILE_CREATED, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scanStatus != dto.ScanStatusFailed || justScanned {
+		t.Fatalf("Expected failed, got %v %v", scanStatus, justScanned)
+	}
+	// the failure isn't the verdict, the file is scanned again by the actualization task
+	if verdict, ok := p.storedVerdict(t); ok {
+		t.Errorf("Expected no stored verdict, got %v", verdict)
+	}
+	if !services.IsQuarantined(scanStatus) {
+		t.Error("Expected the download to be refused")
+	}
+	viper.Set("antivirus.failOpen", true)
+	if services.IsQuarantined(scanStatus) {
+		t.Error("Expected the download to be allowed with failOpen")
+	}
+}
+
+func TestRescanAfterOutage(t *testing.T) {
+	p := newPipeline(t, true)
+	viper.Set("antivirus.scanTimeout", 200*time.Millisecond)
+	p.upload(t, eicar)
+
+	if _, err := p.antivirus.Rescan(context.Background(), testFileKey, true); err == nil {
+		t.Fatal("Expected the error during the outage")
+	}
+	if !p.previewExists(t) {
+		t.Error("Expected the preview to be kept without the verdict")
+	}
+
+	p.clamd.hang.Store(false)
+	scanStatus, err := p.antivirus.Rescan(context.Background(), testFileKey, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scanStatus != dto.ScanStatusInfected {
+		t.Fatalf("Expected infected, got %v", scanStatus)
+	}
+	if verdict, ok := p.storedVerdict(t); !ok || verdict != dto.ScanStatusInfected {
+		t.Errorf("Expected the stored verdict, got %v %v", verdict, ok)
+	}
+	if p.previewExists(t) {
+		t.Error("Expected the preview to be removed")
+	}
+}
+
+func TestIsQuarantined(t *testing.T) {
+	cases := []struct {
+		enabled     bool
+		failOpen    bool
+		scanStatus  string
+		quarantined bool
+	}{
+		{true, false, dto.ScanStatusClean, false},
+		{true, false, dto.ScanStatusInfected, true},
+		{true, false, dto.ScanStatusFailed, true},
+		{true, false, dto.ScanStatusNotScanned, true},
+		{true, true, dto.ScanStatusFailed, false},
+		{true, true, dto.ScanStatusNotScanned, false},
+		{false, false, dto.ScanStatusNotScanned, false},
+		{false, false, dto.ScanStatusInfected, true},
+	}
+	for _, c := range cases {
+		viper.Set("antivirus.enabled", c.enabled)
+		viper.Set("antivirus.failOpen", c.failOpen)
+		if q := services.IsQuarantined(c.scanStatus); q != c.quarantined {
+			t.Errorf("enabled=%v failOpen=%v %v: expected %v, got %v", c.enabled, c.failOpen, c.scanStatus, c.quarantined, q)
+		}
+	}
+}
+
+func TestIsEventForScrubbing(t *testing.T) {
+	cases := []struct {
+		eventType  utils.EventType
+		eventName  string
+		scanStatus string
+		scrubbed   bool
+	}{
+		{utils.FILE_CREATED, utils.ObjectCreatedCompleteMultipartUpload, dto.ScanStatusClean, true},
+		{utils.FILE_CREATED, utils.ObjectCreatedCompleteMultipartUpload, dto.ScanStatusNotScanned, true},
+		{utils.FILE_CREATED, utils.ObjectCreatedCompleteMultipartUpload, dto.ScanStatusInfected, false},
+		{utils.FILE_UPDATED, utils.ObjectCreatedPut, dto.ScanStatusClean, true},
+		{utils.FILE_UPDATED, utils.ObjectCreatedPut, dto.ScanStatusFailed, false},
+		{utils.FILE_UPDATED, utils.ObjectCreatedPutTagging, dto.ScanStatusClean, false},
+		{utils.FILE_DELETED, utils.ObjectRemovedDelete, dto.ScanStatusNotScanned, false},
+	}
+	for _, c := range cases {
+		if scrubbed := isEventForScrubbing(c.eventType, c.eventName, c.scanStatus); scrubbed != c.scrubbed {
+			t.Errorf("%v %v %v: expected %v, got %v", c.eventType, c.eventName, c.scanStatus, c.scrubbed, scrubbed)
+		}
+	}
+}
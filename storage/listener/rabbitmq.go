@@ -2,18 +2,31 @@ package listener
 
 import (
 	"context"
+	"errors"
+
 	"github.com/beliyav/go-amqp-reconnect/rabbitmq"
 	"github.com/streadway/amqp"
 	"go.uber.org/fx"
 	"nkonev.name/storage/logger"
+	"nkonev.name/storage/producer"
 	myRabbit "nkonev.name/storage/rabbitmq"
 )
 
-// see AMQP minio's endpoint in docker-compose
-const MinioEventsExchange = "minio-events"
-
 type FanoutNotificationsChannel struct{ *rabbitmq.Channel }
 
+// the delivery is returned to the queue in order to be processed again, e.g. when the antivirus is temporarily unavailable
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
 func create(lgr *logger.Logger, name string, consumeCh *rabbitmq.Channel) *amqp.Queue {
 	var err error
 	var q amqp.Queue
@@ -69,12 +82,12 @@ func CreateMinioEventsChannel(lgr *logger.Logger, connection *rabbitmq.Connectio
 				},
 			})
 
-			err := channel.ExchangeDeclare(MinioEventsExchange, "direct", true, false, false, false, nil)
+			err := channel.ExchangeDeclare(producer.MinioEventsExchange, "direct", true, false, false, false, nil)
 			if err != nil {
 				return err
 			}
 
-			aQueue := createAndBind(lgr, queueName, "", MinioEventsExchange, channel)
+			aQueue := createAndBind(lgr, queueName, "", producer.MinioEventsExchange, channel)
 			listen(lgr, channel, aQueue, onMessage, lc)
 			return nil
 		},
@@ -108,6 +121,15 @@ func listen(
 				}()
 
 				err := onMessage(&msg)
+				var retryable *retryableError
+				if errors.As(err, &retryable) {
+					lgr.Warnf("In processing queue %v error, the delivery is requeued: %v", queue.Name, err)
+					err = msg.Nack(false, true)
+					if err != nil {
+						lgr.Errorf("In nacking delivery for queue %v error: %v", queue.Name, err)
+					}
+					return
+				}
 				if err != nil {
 					lgr.Errorf("In processing queue %v error: %v", queue.Name, err)
 				}
@@ -11,7 +11,6 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
-	"github.com/minio/minio-go/v7/pkg/notification"
 	"github.com/nkonev/dcron"
 	"github.com/spf13/viper"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
@@ -28,6 +27,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"nkonev.name/storage/app"
+	"nkonev.name/storage/backend"
 	"nkonev.name/storage/client"
 	"nkonev.name/storage/config"
 	"nkonev.name/storage/db"
@@ -36,7 +36,6 @@ import (
 	"nkonev.name/storage/logger"
 	"nkonev.name/storage/producer"
 	"nkonev.name/storage/rabbitmq"
-	"nkonev.name/storage/s3"
 	"nkonev.name/storage/services"
 	"nkonev.name/storage/tasks"
 	"nkonev.name/storage/utils"
@@ -57,8 +56,7 @@ func main() {
 		}),
 		fx.Provide(
 			configureTracer,
-			configureInternalMinio,
-			configureAwsS3,
+			configureBackend,
 			configureMinioEntities,
 			configureEcho,
 			db.ConfigureDb,
@@ -72,19 +70,31 @@ func main() {
 			tasks.NewActualizeMetadataCacheService,
 			tasks.ActualizeMetadataCacheScheduler,
 			client.NewChatAccessClient,
+			client.NewClamdClient,
 			handlers.ConfigureStaticMiddleware,
 			handlers.ConfigureAuthMiddleware,
 			handlers.NewUserAvatarHandler,
 			handlers.NewChatAvatarHandler,
 			handlers.NewFilesHandler,
+			handlers.NewQuotaHandler,
+			handlers.NewLocalBackendHandler,
 			listener.CreateMinioEventsListener,
 			producer.NewRabbitFileUploadedPublisher,
+			producer.NewRabbitStorageEventsPublisher,
 			rabbitmq.CreateRabbitMqConnection,
 			services.NewFilesService,
 			services.NewPreviewService,
+			services.NewPreviewerRegistry,
 			services.NewEventService,
 			services.NewConvertingService,
 			services.NewRedisInfoService,
+			services.NewQuotaService,
+			services.NewShareLinkService,
+			services.NewAntivirusService,
+			services.NewVersionService,
+			services.NewHlsService,
+			services.NewTusService,
+			services.NewMediaService,
 		),
 		fx.Invoke(
 			runMigrations,
@@ -138,6 +148,8 @@ func configureEcho(
 	uah *handlers.UserAvatarHandler,
 	cha *handlers.ChatAvatarHandler,
 	fh *handlers.FilesHandler,
+	qh *handlers.QuotaHandler,
+	lbh *handlers.LocalBackendHandler,
 	tp *sdktrace.TracerProvider,
 ) *echo.Echo {
 
@@ -208,6 +220,12 @@ func configureEcho(
 	e.POST("/internal/s3", fh.S3Handler)
 	e.PUT("/api/storage/:chatId/upload/init", fh.InitMultipartUpload)
 	e.PUT("/api/storage/:chatId/upload/finish", fh.FinishMultipartUpload)
+	e.OPTIONS("/api/storage/:chatId/tus", fh.TusOptions)
+	e.POST("/api/storage/:chatId/tus", fh.TusCreate)
+	e.OPTIONS("/api/storage/:chatId/tus/:id", fh.TusOptions)
+	e.HEAD("/api/storage/:chatId/tus/:id", fh.TusHead)
+	e.PATCH("/api/storage/:chatId/tus/:id", fh.TusPatch)
+	e.DELETE("/api/storage/:chatId/tus/:id", fh.TusTerminate)
 	e.PUT("/api/storage/:chatId/replace/file", fh.ReplaceHandler)
 	e.GET("/api/storage/:chatId", fh.ListHandler)
 	e.GET("/api/storage/public/:chatId", fh.ListHandlerPublic)
@@ -228,6 +246,27 @@ func configureEcho(
 	e.GET(utils.UrlStoragePublicPreviewFile, fh.PublicPreviewDownloadHandler)
 	e.GET(utils.UrlStoragePublicGetFile, fh.PublicDownloadHandler)
 	e.GET(utils.UrlStorageGetFile, fh.DownloadHandler)
+	e.GET("/api/storage/:chatId/zip", fh.ZipHandler)
+	e.GET("/api/storage/public/:chatId/zip", fh.PublicZipHandler)
+	e.GET("/api/storage/:chatId/file/versions", fh.ListVersions)
+	e.PUT("/api/storage/:chatId/file/versions/restore", fh.RestoreVersion)
+	e.GET(utils.UrlStorageGetFileVersion, fh.DownloadVersion)
+	e.GET(utils.UrlStorageHlsStatus, fh.HlsStatus)
+	e.GET(utils.UrlStorageHlsMaster, fh.HlsMasterPlaylist)
+	e.GET(utils.UrlStorageHlsPlaylist, fh.HlsRenditionPlaylist)
+	e.POST("/api/storage/share-link", fh.CreateShareLink)
+	e.GET("/api/storage/share-link", fh.ListShareLinks)
+	e.DELETE("/api/storage/:chatId/share-link/:id", fh.RevokeShareLink)
+	e.GET("/api/storage/:chatId/share-link/:id/stats", fh.ShareLinkStats)
+	e.GET("/api/storage/quota", qh.GetQuotas)
+	e.PUT("/api/storage/quota", qh.SetQuota)
+	e.DELETE("/api/storage/quota", qh.RemoveQuota)
+	e.GET("/api/storage/quota/usage", qh.GetUsageBreakdown)
+	if lbh.Enabled() {
+		e.GET(backend.LocalUrlObject, lbh.Download)
+		e.HEAD(backend.LocalUrlObject, lbh.Download)
+		e.PUT(backend.LocalUrlPart, lbh.UploadPart)
+	}
 
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
@@ -240,7 +279,24 @@ func configureEcho(
 	return e
 }
 
-func configureInternalMinio() (*s3.InternalMinioClient, error) {
+func configureBackend(lgr *logger.Logger, publisher *producer.RabbitStorageEventsPublisher) (backend.Backend, error) {
+	backendType := viper.GetString("backend.type")
+	switch backendType {
+	case backend.TypeMinio:
+		minioClient, err := configureInternalMinio()
+		if err != nil {
+			return nil, err
+		}
+		return backend.NewMinioBackend(lgr, minioClient, configureAwsS3()), nil
+	case backend.TypeLocal:
+		lgr.Infof("Using local filesystem backend in %v", viper.GetString("backend.local.root"))
+		return backend.NewLocalBackend(lgr, viper.GetString("backend.local.root"), viper.GetString("backend.local.signingKey"), publisher)
+	default:
+		return nil, fmt.Errorf("unknown storage backend type: %v", backendType)
+	}
+}
+
+func configureInternalMinio() (*minio.Client, error) {
 	endpoint := viper.GetString("minio.internalEndpoint")
 	accessKeyID := viper.GetString("minio.accessKeyId")
 	secretAccessKey := viper.GetString("minio.secretAccessKey")
@@ -257,7 +313,7 @@ func configureInternalMinio() (*s3.InternalMinioClient, error) {
 		return nil, err
 	}
 
-	return &s3.InternalMinioClient{minioClient}, nil
+	return minioClient, nil
 }
 
 // https://github.com/aws/aws-sdk-go
@@ -345,8 +401,8 @@ func runEcho(lgr *logger.Logger, e *echo.Echo) {
 	lgr.Info("Server started. Waiting for interrupt signal 2 (Ctrl+C)")
 }
 
-func configureMinioEntities(lgr *logger.Logger, client *s3.InternalMinioClient) (*utils.MinioConfig, error) {
-	var ua, ca, f, p string
+func configureMinioEntities(lgr *logger.Logger, client backend.Backend) (*utils.MinioConfig, error) {
+	var ua, ca, f, p, v, hls, tus string
 	var err error
 	if ua, err = utils.EnsureAndGetUserAvatarBucket(lgr, client); err != nil {
 		return nil, err
@@ -360,53 +416,26 @@ func configureMinioEntities(lgr *logger.Logger, client *s3.InternalMinioClient)
 	if p, err = utils.EnsureAndGetFilesPreviewBucket(lgr, client); err != nil {
 		return nil, err
 	}
-	bucketNotification, err := client.GetBucketNotification(context.Background(), f)
-	if err != nil {
+	if v, err = utils.EnsureAndGetFilesVersionsBucket(lgr, client); err != nil {
 		return nil, err
 	}
-
-	arn := notification.Arn{
-		Partition: "minio",
-		Service:   "sqs",
-		Region:    "",
-		AccountID: "primary",
-		Resource:  "amqp",
+	if hls, err = utils.EnsureAndGetFilesHlsBucket(lgr, client); err != nil {
+		return nil, err
 	}
-	subscriptionName := arn.String()
-	shouldCreateSubscription := true
-	queueConfigs := bucketNotification.QueueConfigs
-	if queueConfigs != nil {
-		for _, qc := range queueConfigs {
-			if qc.Queue == subscriptionName {
-				shouldCreateSubscription = false
-				break
-			}
-		}
+	if tus, err = utils.EnsureAndGetFilesTusBucket(lgr, client); err != nil {
+		return nil, err
 	}
-	if shouldCreateSubscription {
-		lgr.Infof("Will create subscription for bucket %v to arn %v", f, arn)
-		err := client.SetBucketNotification(context.Background(), f, notification.Configuration{
-			QueueConfigs: []notification.QueueConfig{
-				notification.QueueConfig{
-					Queue: subscriptionName,
-					Config: notification.Config{
-						Events: []notification.EventType{
-							utils.ObjectCreated + ":*",
-							utils.ObjectRemoved + ":*",
-						},
-					},
-				},
-			},
-		})
-		if err != nil {
-			return nil, err
-		}
+	if err = client.SubscribeEvents(context.Background(), f); err != nil {
+		return nil, err
 	}
 	return &utils.MinioConfig{
-		UserAvatar:   ua,
-		ChatAvatar:   ca,
-		Files:        f,
-		FilesPreview: p,
+		UserAvatar:    ua,
+		ChatAvatar:    ca,
+		Files:         f,
+		FilesPreview:  p,
+		FilesVersions: v,
+		FilesHls:      hls,
+		FilesTus:      tus,
 	}, nil
 }
 
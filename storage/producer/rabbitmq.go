@@ -67,6 +67,8 @@ func (rp *RabbitFileUploadedPublisher) PublishFileEvent(ctx context.Context, use
 		outputEventType = "file_removed"
 	case utils.FILE_UPDATED:
 		outputEventType = "file_updated"
+	case utils.FILE_INFECTED:
+		outputEventType = "file_infected"
 	default:
 		rp.lgr.WithTracing(ctx).Errorf("Error during determining rabbitmq output event type")
 		return errors.New("Unknown type")
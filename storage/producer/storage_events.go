@@ -0,0 +1,108 @@
+package producer
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/beliyav/go-amqp-reconnect/rabbitmq"
+	"github.com/streadway/amqp"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/logger"
+	myRabbitmq "nkonev.name/storage/rabbitmq"
+)
+
+// see AMQP minio's endpoint in docker-compose
+const MinioEventsExchange = "minio-events"
+
+// the subset of the minio notification which is read by the listener
+type minioEventObject struct {
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	ETag         string            `json:"eTag"`
+	ContentType  string            `json:"contentType"`
+	UserMetadata map[string]string `json:"userMetadata,omitempty"`
+}
+
+type minioEventBucket struct {
+	Name string `json:"name"`
+}
+
+type minioEventS3 struct {
+	Bucket minioEventBucket `json:"bucket"`
+	Object minioEventObject `json:"object"`
+}
+
+type minioEventRecord struct {
+	EventName string       `json:"eventName"`
+	EventTime string       `json:"eventTime"`
+	S3        minioEventS3 `json:"s3"`
+}
+
+type minioEvent struct {
+	EventName string             `json:"EventName"`
+	Key       string             `json:"Key"`
+	Records   []minioEventRecord `json:"Records"`
+}
+
+// publishes the events of the local backend in the same format as minio does, so the listener handles them as usual
+type RabbitStorageEventsPublisher struct {
+	channel *rabbitmq.Channel
+	lgr     *logger.Logger
+}
+
+func NewRabbitStorageEventsPublisher(connection *rabbitmq.Connection, lgr *logger.Logger) *RabbitStorageEventsPublisher {
+	return &RabbitStorageEventsPublisher{
+		channel: myRabbitmq.CreateRabbitMqChannelWithCallback(lgr, connection, func(channel *rabbitmq.Channel) error {
+			return channel.ExchangeDeclare(MinioEventsExchange, "direct", true, false, false, false, nil)
+		}),
+		lgr: lgr,
+	}
+}
+
+func (rp *RabbitStorageEventsPublisher) PublishStorageEvent(ctx context.Context, event backend.Event) error {
+	key := event.Bucket + "/" + event.Key
+	aEvent := minioEvent{
+		EventName: event.EventName,
+		Key:       key,
+		Records: []minioEventRecord{
+			{
+				EventName: event.EventName,
+				EventTime: time.Now().UTC().Format(time.RFC3339Nano),
+				S3: minioEventS3{
+					Bucket: minioEventBucket{Name: event.Bucket},
+					Object: minioEventObject{
+						Key:          event.Key,
+						Size:         event.Size,
+						ETag:         event.ETag,
+						ContentType:  event.ContentType,
+						UserMetadata: event.UserMetadata,
+					},
+				},
+			},
+		},
+	}
+
+	bytea, err := json.Marshal(aEvent)
+	if err != nil {
+		rp.lgr.WithTracing(ctx).Error(err, "Failed during marshal storage event")
+		return err
+	}
+
+	msg := amqp.Publishing{
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now().UTC(),
+		ContentType:  "application/json",
+		Body:         bytea,
+		Headers:      myRabbitmq.InjectAMQPHeaders(ctx),
+	}
+
+	if err := rp.channel.Publish(MinioEventsExchange, "", false, false, msg); err != nil {
+		rp.lgr.WithTracing(ctx).Error(err, "Error during publishing")
+		return err
+	}
+
+	return nil
+}
+
+var _ backend.EventPublisher = (*RabbitStorageEventsPublisher)(nil)
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/tags"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/client"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/utils"
+)
+
+type AntivirusService struct {
+	clamdClient       *client.ClamdClient
+	minio             backend.Backend
+	minioConfig       *utils.MinioConfig
+	previewerRegistry *PreviewerRegistry
+	lgr               *logger.Logger
+}
+
+func NewAntivirusService(lgr *logger.Logger, clamdClient *client.ClamdClient, minio backend.Backend, minioConfig *utils.MinioConfig, previewerRegistry *PreviewerRegistry) *AntivirusService {
+	return &AntivirusService{
+		clamdClient:       clamdClient,
+		minio:             minio,
+		minioConfig:       minioConfig,
+		previewerRegistry: previewerRegistry,
+		lgr:               lgr,
+	}
+}
+
+func (s *AntivirusService) IsEnabled() bool {
+	return viper.GetBool("antivirus.enabled")
+}
+
+// only the clean files are served when the antivirus is enabled.
+// antivirus.failOpen allows the ones which haven't got the verdict, e.g. because of the clamd outage, the infected files are never served
+func IsQuarantined(scanStatus string) bool {
+	switch scanStatus {
+	case dto.ScanStatusClean:
+		return false
+	case dto.ScanStatusInfected:
+		return true
+	}
+	return viper.GetBool("antivirus.enabled") && !viper.GetBool("antivirus.failOpen")
+}
+
+// returns the verdict, the error means there is no verdict, e.g. because of the clamd outage.
+// it blocks the processing of the storage events, so it's bounded by antivirus.scanTimeout
+func (s *AntivirusService) Scan(ctx context.Context, normalizedKey string) (string, error) {
+	if scanTimeout := viper.GetDuration("antivirus.scanTimeout"); scanTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, scanTimeout)
+		defer cancel()
+	}
+
+	object, err := s.minio.GetObject(ctx, s.minioConfig.Files, normalizedKey, minio.GetObjectOptions{})
+	if err != nil {
+		return "", fmt.Errorf("unable to get object %v for scanning: %w", normalizedKey, err)
+	}
+	defer object.Close()
+
+	result, err := s.clamdClient.Scan(ctx, object)
+	if err != nil {
+		return "", fmt.Errorf("unable to scan %v: %w", normalizedKey, err)
+	}
+	if result.Infected {
+		s.lgr.WithTracing(ctx).Warnf("File %v is infected with %v", normalizedKey, result.Signature)
+		return dto.ScanStatusInfected, nil
+	}
+	return dto.ScanStatusClean, nil
+}
+
+// the infected file isn't published anymore, the next tagging event won't trigger the scanning again.
+// dto.ScanStatusFailed isn't a verdict, so it isn't stored and the file is scanned again by the actualization task
+func (s *AntivirusService) StoreVerdict(ctx context.Context, normalizedKey string, published bool, scanStatus string) error {
+	if scanStatus != dto.ScanStatusClean && scanStatus != dto.ScanStatusInfected {
+		return nil
+	}
+	if scanStatus == dto.ScanStatusInfected {
+		published = false
+	}
+	objectTags, err := tags.MapToObjectTags(SerializeTags(published, scanStatus))
+	if err != nil {
+		return err
+	}
+	return s.minio.PutObjectTagging(ctx, s.minioConfig.Files, normalizedKey, objectTags, minio.PutObjectTaggingOptions{})
+}
+
+// scans the file which hasn't got the verdict on the storage event, e.g. because of the clamd outage or because it was uploaded before the antivirus was enabled
+func (s *AntivirusService) Rescan(ctx context.Context, normalizedKey string, published bool) (string, error) {
+	scanStatus, err := s.Scan(ctx, normalizedKey)
+	if err != nil {
+		return "", err
+	}
+	err = s.StoreVerdict(ctx, normalizedKey, published, scanStatus)
+	if err != nil {
+		return "", err
+	}
+	if scanStatus == dto.ScanStatusInfected {
+		s.Quarantine(ctx, normalizedKey)
+	}
+	return scanStatus, nil
+}
+
+// removes the preview because it can be generated before the file was replaced with the infected one
+func (s *AntivirusService) Quarantine(ctx context.Context, normalizedKey string) {
+	previewKey := s.previewerRegistry.GetPreviewKey(normalizedKey)
+	if previewKey == "" {
+		return
+	}
+	err := s.minio.RemoveObject(ctx, s.minioConfig.FilesPreview, previewKey, minio.RemoveObjectOptions{})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error during removing preview %v of infected file: %v", previewKey, err)
+	}
+}
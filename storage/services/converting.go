@@ -8,9 +8,9 @@ import (
 	"github.com/spf13/viper"
 	"io/ioutil"
 	"net/url"
+	"nkonev.name/storage/backend"
 	"nkonev.name/storage/dto"
 	"nkonev.name/storage/logger"
-	"nkonev.name/storage/s3"
 	"nkonev.name/storage/utils"
 	"os"
 	"os/exec"
@@ -18,14 +18,14 @@ import (
 )
 
 type ConvertingService struct {
-	minio            *s3.InternalMinioClient
+	minio            backend.Backend
 	minioConfig      *utils.MinioConfig
 	tempDirPrefix    string
 	redisInfoService *RedisInfoService
 	lgr              *logger.Logger
 }
 
-func NewConvertingService(lgr *logger.Logger, minio *s3.InternalMinioClient, minioConfig *utils.MinioConfig, redisInfoService *RedisInfoService) *ConvertingService {
+func NewConvertingService(lgr *logger.Logger, minio backend.Backend, minioConfig *utils.MinioConfig, redisInfoService *RedisInfoService) *ConvertingService {
 	tempDirPrefix := viper.GetString("converting.tempDir")
 	lgr.Infof("Ensuring temp root dir for the converting videos using ffmpeg: %v", tempDirPrefix)
 	os.MkdirAll(tempDirPrefix, os.ModePerm)
@@ -6,15 +6,15 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/tags"
+	"nkonev.name/storage/backend"
 	"nkonev.name/storage/client"
 	"nkonev.name/storage/dto"
 	"nkonev.name/storage/logger"
 	"nkonev.name/storage/producer"
-	"nkonev.name/storage/s3"
 	"nkonev.name/storage/utils"
 )
 
-func NewEventService(lgr *logger.Logger, client *client.RestClient, minio *s3.InternalMinioClient, minioConfig *utils.MinioConfig, filesService *FilesService, publisher *producer.RabbitFileUploadedPublisher) *EventService {
+func NewEventService(lgr *logger.Logger, client *client.RestClient, minio backend.Backend, minioConfig *utils.MinioConfig, filesService *FilesService, publisher *producer.RabbitFileUploadedPublisher) *EventService {
 	return &EventService{
 		client:       client,
 		minio:        minio,
@@ -27,7 +27,7 @@ func NewEventService(lgr *logger.Logger, client *client.RestClient, minio *s3.In
 
 type EventService struct {
 	client       *client.RestClient
-	minio        *s3.InternalMinioClient
+	minio        backend.Backend
 	minioConfig  *utils.MinioConfig
 	filesService *FilesService
 	publisher    *producer.RabbitFileUploadedPublisher
@@ -94,6 +94,14 @@ func (r *HandleEventResponse) GetTags() (*bool, error) {
 	return &published, nil
 }
 
+// returns false in case the file hasn't been scanned yet
+func (r *HandleEventResponse) GetScanStatus() (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	return DeserializeScanStatusTag(r.tagging)
+}
+
 func (r *HandleEventResponse) GetObjectInfo() *minio.ObjectInfo {
 	if r == nil {
 		return nil
@@ -148,3 +156,21 @@ func (s *EventService) SendToParticipants(ctx context.Context, normalizedKey str
 		}
 	}
 }
+
+// lets the uploader know why their file isn't available
+func (s *EventService) SendInfectedToOwner(ctx context.Context, normalizedKey string, chatId int64, response *HandleEventResponse, mce *dto.MetadataCache) {
+	ownerId := mce.OwnerId
+	fileInfo, err := s.filesService.GetFileInfo(ctx, false, utils.ChatIdNonExistent, utils.MessageIdNonExistent, &ownerId, mce)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error get file info: %v", err)
+		return
+	}
+	fileInfo.Owner = response.users[response.fileOwnerId]
+
+	err = s.publisher.PublishFileEvent(ctx, ownerId, chatId, &dto.WrappedFileInfoDto{
+		FileInfoDto: fileInfo,
+	}, utils.FILE_INFECTED, mce.CorrelationId)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error sending infected event for %v: %v", normalizedKey, err)
+	}
+}
@@ -11,35 +11,38 @@ import (
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/tags"
 	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
 	"nkonev.name/storage/client"
 	"nkonev.name/storage/db"
 	"nkonev.name/storage/dto"
 	"nkonev.name/storage/logger"
-	"nkonev.name/storage/s3"
 	"nkonev.name/storage/utils"
 )
 
 type FilesService struct {
-	minio       *s3.InternalMinioClient
-	restClient  *client.RestClient
-	minioConfig *utils.MinioConfig
-	dba         *db.DB
-	lgr         *logger.Logger
+	minio             backend.Backend
+	restClient        *client.RestClient
+	minioConfig       *utils.MinioConfig
+	dba               *db.DB
+	previewerRegistry *PreviewerRegistry
+	lgr               *logger.Logger
 }
 
 func NewFilesService(
 	lgr *logger.Logger,
-	minio *s3.InternalMinioClient,
+	minio backend.Backend,
 	chatClient *client.RestClient,
 	dba *db.DB,
 	minioConfig *utils.MinioConfig,
+	previewerRegistry *PreviewerRegistry,
 ) *FilesService {
 	return &FilesService{
-		minio:       minio,
-		restClient:  chatClient,
-		minioConfig: minioConfig,
-		dba:         dba,
-		lgr:         lgr,
+		minio:             minio,
+		restClient:        chatClient,
+		minioConfig:       minioConfig,
+		dba:               dba,
+		previewerRegistry: previewerRegistry,
+		lgr:               lgr,
 	}
 }
 
@@ -150,12 +153,7 @@ func (h *FilesService) GetListFilesItemUuids(
 func (h *FilesService) GetTemporaryDownloadUrl(ctx context.Context, aKey string) (string, time.Duration, error) {
 	ttl := viper.GetDuration("minio.presignDownloadTtl")
 
-	u, err := h.minio.PresignedGetObject(ctx, h.minioConfig.Files, aKey, ttl, url.Values{})
-	if err != nil {
-		return "", time.Second, err
-	}
-
-	downloadUrl, err := ChangeMinioUrl(u)
+	downloadUrl, err := h.minio.PresignedExternalGetObject(ctx, h.minioConfig.Files, aKey, ttl, url.Values{})
 	if err != nil {
 		return "", time.Second, err
 	}
@@ -177,22 +175,6 @@ func (h *FilesService) GetConstantDownloadUrl(aKey string) (string, error) {
 	return downloadUrlStr, nil
 }
 
-func ChangeMinioUrl(url *url.URL) (string, error) {
-	externalS3UrlPrefix := viper.GetString("minio.externalS3UrlPrefix")
-	parsed, err := url.Parse(externalS3UrlPrefix)
-	if err != nil {
-		return "", err
-	}
-
-	url.Path = parsed.Path + url.Path
-	url.Host = ""
-	url.Scheme = ""
-
-	stringV := url.String()
-
-	return stringV, nil
-}
-
 func (h *FilesService) GetPublishedUrl(public bool, fileName string) (*string, error) {
 	if !public {
 		return nil, nil
@@ -257,6 +239,7 @@ func (h *FilesService) GetFileInfo(c context.Context, public bool, overrideChatI
 
 	var downloadUrl string
 	var previewUrl *string
+	var hlsUrl *string
 
 	var canDelete, canEdit, canShare bool
 
@@ -276,6 +259,13 @@ func (h *FilesService) GetFileInfo(c context.Context, public bool, overrideChatI
 
 		downloadUrl = downloadUrltmp
 		previewUrl = previewUrltmp
+
+		// the playlists are served only to the participants
+		hlsUrl, err = h.getHlsUrl(c, aKey)
+		if err != nil {
+			h.lgr.WithTracing(c).Errorf("Error during getting hls url %v", err)
+			return nil, err
+		}
 	} else {
 		// public microservice flow - user clicks on FileListModal
 		// it's safe becasue we already checked the access before
@@ -316,6 +306,26 @@ func (h *FilesService) GetFileInfo(c context.Context, public bool, overrideChatI
 		Previewable:    utils.IsPreviewable(aKey),
 		Type:           aType,
 		CreateDateTime: mce.CreateDateTime,
+		ScanStatus:     mce.ScanStatus,
+		VersionCount:   mce.VersionCount,
+		HlsUrl:         hlsUrl,
+	}
+	if !mce.Media.IsEmpty() {
+		media := mce.Media
+		info.Media = &media
+	}
+	if mce.ScanStatus == dto.ScanStatusInfected {
+		// it's quarantined, so nothing except the name and the verdict is available
+		info.PublishedUrl = nil
+		info.PreviewUrl = nil
+		info.HlsUrl = nil
+		info.Previewable = false
+		info.CanPlayAsVideo = false
+		info.CanShowAsImage = false
+		info.CanPlayAsAudio = false
+		info.CanEdit = false
+		info.CanShare = false
+		info.Media = nil
 	}
 	return info, nil
 }
@@ -350,6 +360,7 @@ func makeUrlPublic(src string, additionalSegment string, overrideChatId, overrid
 const Media_image = "image"
 const Media_video = "video"
 const Media_audio = "audio"
+const Media_document = "document"
 
 func (h *FilesService) GetPreviewUrlSmart(c context.Context, aKey string) *string {
 	return h.getPreviewUrlSmart(c, aKey, utils.UrlBasePreview, nil, nil)
@@ -363,10 +374,37 @@ func (h *FilesService) getPreviewUrlSmart(c context.Context, aKey string, urlBas
 	} else if utils.IsImage(aKey) {
 		recognizedType = Media_image
 		return h.getPreviewUrl(c, aKey, recognizedType, urlBase, overrideChatId, overrideMessageId)
+	} else if h.previewerRegistry.HasPreviewer(aKey) {
+		recognizedType = Media_document
+		return h.getPreviewUrl(c, aKey, recognizedType, urlBase, overrideChatId, overrideMessageId)
 	}
 	return nil
 }
 
+// returns nil in case there is no hls for this video yet
+func (h *FilesService) getHlsUrl(c context.Context, aKey string) (*string, error) {
+	if !utils.IsVideo(aKey) {
+		return nil, nil
+	}
+	exists, _, err := h.minio.FileExists(c, h.minioConfig.FilesHls, GetHlsMasterKey(aKey))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	hlsUrl, err := GetHlsUrl(aKey)
+	if err != nil {
+		return nil, err
+	}
+	return &hlsUrl, nil
+}
+
+// returns an empty string in case the file has no preview
+func (h *FilesService) GetPreviewKey(normalizedKey string) string {
+	return h.previewerRegistry.GetPreviewKey(normalizedKey)
+}
+
 func GetType(itemUrl string) *string {
 	var recognizedType string = ""
 	if utils.IsVideo(itemUrl) {
@@ -414,6 +452,8 @@ func (h *FilesService) getPreviewUrl(c context.Context, aKey string, requestedMe
 		previewMinioKey = utils.SetVideoPreviewExtension(aKey)
 	} else if requestedMediaType == Media_image {
 		previewMinioKey = utils.SetImagePreviewExtension(aKey)
+	} else if requestedMediaType == Media_document {
+		previewMinioKey = utils.SetDocumentPreviewExtension(aKey)
 	}
 	if previewMinioKey != "" {
 		query := respUrl.Query()
@@ -444,6 +484,7 @@ func (h *FilesService) getPreviewUrl(c context.Context, aKey string, requestedMe
 }
 
 const publishedKey = "published"
+const scanStatusKey = "scanstatus"
 
 const ownerIdKey = "ownerid"
 const correlationIdKey = "correlationid"
@@ -455,6 +496,9 @@ const originalKey = "originalkey"
 
 const timestampKey = "timestamp"
 
+// the image was rewritten without the private metadata
+const scrubbedKey = "scrubbed"
+
 func SerializeMetadataSimple(userId int64, correlationId *string, isConferenceRecording *bool, isUserMessageRecording *bool, timestamp int64) map[string]string {
 	var userMetadata = map[string]string{}
 	userMetadata[ownerIdKey] = utils.Int64ToString(userId)
@@ -569,9 +613,21 @@ func TimestampKey(hasAmzPrefix bool) string {
 	return prefix + strings.Title(timestampKey)
 }
 
-func SerializeTags(published bool) map[string]string {
+func ScrubbedKey(hasAmzPrefix bool) string {
+	var prefix = ""
+	if hasAmzPrefix {
+		prefix = xAmzMetaPrefix
+	}
+	return prefix + strings.Title(scrubbedKey)
+}
+
+// the verdict is stored in the tags too in order to survive the rebuilding of metadata cache
+func SerializeTags(published bool, scanStatus string) map[string]string {
 	var userTags = map[string]string{}
 	userTags[publishedKey] = fmt.Sprintf("%v", published)
+	if scanStatus == dto.ScanStatusClean || scanStatus == dto.ScanStatusInfected {
+		userTags[scanStatusKey] = scanStatus
+	}
 	return userTags
 }
 
@@ -588,6 +644,19 @@ func DeserializeTags(tagging *tags.Tags) (bool, error) {
 	return utils.ParseBoolean(publishedString)
 }
 
+// returns false in case the file hasn't got the verdict yet, the "failed" status stored by the previous versions isn't the verdict
+func DeserializeScanStatusTag(tagging *tags.Tags) (string, bool) {
+	if tagging == nil {
+		return "", false
+	}
+
+	scanStatus := tagging.ToMap()[scanStatusKey]
+	if scanStatus != dto.ScanStatusClean && scanStatus != dto.ScanStatusInfected {
+		return "", false
+	}
+	return scanStatus, true
+}
+
 func GetUsersRemotelyOrEmpty(lgr *logger.Logger, userIdSet map[int64]bool, restClient *client.RestClient, c context.Context) map[int64]*dto.User {
 	if remoteUsers, err := getUsersRemotely(lgr, userIdSet, restClient, c); err != nil {
 		lgr.WithTracing(c).Warn("Error during getting users from aaa")
@@ -0,0 +1,475 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/utils"
+)
+
+const hlsMasterPlaylist = "master.m3u8"
+const hlsRenditionPlaylist = "index.m3u8"
+
+const hlsPlaylistContentType = "application/vnd.apple.mpegurl"
+const hlsSegmentContentType = "video/mp2t"
+
+var ErrHlsRenditionNotFound = errors.New("rendition not found")
+
+type HlsRendition struct {
+	Name         string `mapstructure:"name"`
+	Height       int    `mapstructure:"height"`
+	VideoBitrate string `mapstructure:"videoBitrate"`
+	AudioBitrate string `mapstructure:"audioBitrate"`
+}
+
+// converts the videos to the adaptive streaming ladder,
+// the master playlist, the rendition playlists and the segments are stored in the separate bucket under the "<key of video>/" prefix,
+// the playlists are served with the presigned segment urls, so the segments are downloaded directly from minio
+type HlsService struct {
+	minio            backend.Backend
+	minioConfig      *utils.MinioConfig
+	tempDirPrefix    string
+	redisInfoService *RedisInfoService
+	lgr              *logger.Logger
+}
+
+func NewHlsService(lgr *logger.Logger, minio backend.Backend, minioConfig *utils.MinioConfig, redisInfoService *RedisInfoService) *HlsService {
+	return &HlsService{
+		minio:            minio,
+		minioConfig:      minioConfig,
+		tempDirPrefix:    viper.GetString("converting.tempDir"),
+		redisInfoService: redisInfoService,
+		lgr:              lgr,
+	}
+}
+
+func (s *HlsService) IsEnabled() bool {
+	return viper.GetBool("converting.hls.enabled")
+}
+
+func GetHlsPrefix(normalizedKey string) string {
+	return normalizedKey + "/"
+}
+
+func GetHlsMasterKey(normalizedKey string) string {
+	return GetHlsPrefix(normalizedKey) + hlsMasterPlaylist
+}
+
+func getHlsRenditionKey(normalizedKey, rendition, fileName string) string {
+	return GetHlsPrefix(normalizedKey) + rendition + "/" + fileName
+}
+
+func GetHlsUrl(normalizedKey string) (string, error) {
+	return getHlsUrl(utils.UrlStorageHlsMaster, normalizedKey, "")
+}
+
+func getHlsUrl(urlBase, normalizedKey, rendition string) (string, error) {
+	hlsUrl, err := url.Parse(urlBase)
+	if err != nil {
+		return "", err
+	}
+
+	query := hlsUrl.Query()
+	query.Add(utils.FileParam, normalizedKey)
+	if rendition != "" {
+		query.Add(utils.RenditionParam, rendition)
+	}
+	hlsUrl.RawQuery = query.Encode()
+	return hlsUrl.String(), nil
+}
+
+func getHlsRenditions() ([]HlsRendition, error) {
+	var renditions []HlsRendition
+	err := viper.UnmarshalKey("converting.hls.renditions", &renditions)
+	return renditions, err
+}
+
+// the master playlist is uploaded the last, so its presence means the ladder is complete
+func (s *HlsService) Exists(ctx context.Context, normalizedKey string) (bool, error) {
+	exists, _, err := s.minio.FileExists(ctx, s.minioConfig.FilesHls, GetHlsMasterKey(normalizedKey))
+	return exists, err
+}
+
+func (s *HlsService) GetStatus(ctx context.Context, normalizedKey string) (*dto.HlsStatusDto, error) {
+	progress, converting, err := s.redisInfoService.GetHlsConverting(ctx, normalizedKey)
+	if err != nil {
+		return nil, err
+	}
+	if converting {
+		return &dto.HlsStatusDto{Status: dto.HlsStatusConverting, Progress: progress}, nil
+	}
+
+	exists, err := s.Exists(ctx, normalizedKey)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &dto.HlsStatusDto{Status: dto.HlsStatusNone}, nil
+	}
+	hlsUrl, err := GetHlsUrl(normalizedKey)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.HlsStatusDto{Status: dto.HlsStatusReady, Progress: 100, Url: &hlsUrl}, nil
+}
+
+func (s *HlsService) HandleEvent(ctx context.Context, event *dto.MinioEvent) {
+	normalizedKey := utils.StripBucketName(event.Key, s.minioConfig.Files)
+	s.Convert(ctx, normalizedKey)
+}
+
+func (s *HlsService) Convert(ctx context.Context, normalizedKey string) {
+	if _, converting, err := s.redisInfoService.GetHlsConverting(ctx, normalizedKey); err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get hls converting for key %v from redis: %v", normalizedKey, err)
+		return
+	} else if converting {
+		s.lgr.WithTracing(ctx).Infof("Hls for %v is already being converted", normalizedKey)
+		return
+	}
+
+	s.redisInfoService.SetHlsConverting(ctx, normalizedKey, 0)
+	defer s.redisInfoService.RemoveHlsConverting(ctx, normalizedKey)
+
+	renditions, err := getHlsRenditions()
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error during reading hls renditions: %v", err)
+		return
+	}
+	if len(renditions) == 0 {
+		s.lgr.WithTracing(ctx).Errorf("There are no configured hls renditions")
+		return
+	}
+
+	d := viper.GetDuration("converting.presignedDuration")
+	presignedUrl, err := s.minio.PresignedGetObject(ctx, s.minioConfig.Files, normalizedKey, d, url.Values{})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error during getting presigned url for %v", normalizedKey)
+		return
+	}
+	stringPresingedUrl := presignedUrl.String()
+
+	probe, err := s.probe(ctx, normalizedKey, stringPresingedUrl)
+	if err != nil {
+		return
+	}
+
+	dir, err := os.MkdirTemp(s.tempDirPrefix, utils.RemoveExtension(utils.GetFilename(normalizedKey))+"__hls__")
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("error during create temp dir for the hls converting using ffmpeg: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	renditions = fitRenditions(renditions, probe.height)
+	s.lgr.WithTracing(ctx).Infof("Converting %v to hls with %v renditions", normalizedKey, len(renditions))
+
+	ffCmd := exec.Command(viper.GetString("converting.ffmpegPath"), buildHlsArgs(stringPresingedUrl, dir, renditions, probe.hasAudio, viper.GetInt("converting.hls.segmentDuration"))...)
+	// https://medium.com/@ganeshmaharaj/clean-exit-of-golangs-exec-command-897832ac3fa5
+	ffCmd.SysProcAttr = &syscall.SysProcAttr{
+		Pdeathsig: syscall.SIGTERM,
+	}
+	var stderr bytes.Buffer
+	ffCmd.Stderr = &stderr
+	progressReader, err := ffCmd.StdoutPipe()
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error during getting stdout for key %v: %v", normalizedKey, err)
+		return
+	}
+	err = ffCmd.Start()
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error during starting hls converting for key %v: %v", normalizedKey, err)
+		return
+	}
+	s.trackProgress(ctx, normalizedKey, progressReader, probe.durationMicros)
+	err = ffCmd.Wait()
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error during hls converting for key %v: %v: stderr: %v", normalizedKey, fmt.Sprint(err), stderr.String())
+		return
+	}
+
+	err = s.upload(ctx, normalizedKey, dir)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error during storing hls of %v to minio: %v", normalizedKey, err)
+		return
+	}
+	s.lgr.WithTracing(ctx).Infof("Converted %v to hls", normalizedKey)
+}
+
+type hlsProbe struct {
+	durationMicros int64
+	height         int
+	hasAudio       bool
+}
+
+func (s *HlsService) probe(ctx context.Context, normalizedKey, input string) (*hlsProbe, error) {
+	probeCmd := exec.Command(viper.GetString("converting.hls.ffprobePath"),
+		"-v", "error",
+		"-show_entries", "format=duration:stream=codec_type,height",
+		"-of", "json",
+		input,
+	)
+	probeCmd.SysProcAttr = &syscall.SysProcAttr{
+		Pdeathsig: syscall.SIGTERM,
+	}
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	probeCmd.Stdout = &out
+	probeCmd.Stderr = &stderr
+	err := probeCmd.Run()
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error during probing for key %v: %v: stderr: %v", normalizedKey, fmt.Sprint(err), stderr.String())
+		return nil, err
+	}
+
+	res, err := parseHlsProbe(out.Bytes())
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error during parsing probe for key %v: %v", normalizedKey, err)
+		return nil, err
+	}
+	return res, nil
+}
+
+func parseHlsProbe(data []byte) (*hlsProbe, error) {
+	var parsed struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	err := json.Unmarshal(data, &parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &hlsProbe{}
+	// webm recordings can have no duration
+	if duration, err := strconv.ParseFloat(parsed.Format.Duration, 64); err == nil {
+		res.durationMicros = int64(duration * 1_000_000)
+	}
+	for _, stream := range parsed.Streams {
+		switch stream.CodecType {
+		case "video":
+			res.height = max(res.height, stream.Height)
+		case "audio":
+			res.hasAudio = true
+		}
+	}
+	if res.height == 0 {
+		return nil, errors.New("there is no video stream")
+	}
+	return res, nil
+}
+
+// there is no sense to upscale, the lowest rendition is kept anyway
+func fitRenditions(renditions []HlsRendition, sourceHeight int) []HlsRendition {
+	res := []HlsRendition{}
+	lowest := renditions[0]
+	for _, rendition := range renditions {
+		if rendition.Height <= sourceHeight {
+			res = append(res, rendition)
+		}
+		if rendition.Height < lowest.Height {
+			lowest = rendition
+		}
+	}
+	if len(res) == 0 {
+		res = append(res, lowest)
+	}
+	return res
+}
+
+func buildHlsArgs(input, dir string, renditions []HlsRendition, hasAudio bool, segmentDuration int) []string {
+	var filter strings.Builder
+	filter.WriteString(fmt.Sprintf("[0:v]split=%v", len(renditions)))
+	for i := range renditions {
+		filter.WriteString(fmt.Sprintf("[v%v]", i))
+	}
+	for i, rendition := range renditions {
+		filter.WriteString(fmt.Sprintf(";[v%v]scale=-2:%v[v%vout]", i, rendition.Height, i))
+	}
+
+	args := []string{"-i", input, "-filter_complex", filter.String()}
+	var streamMap []string
+	for i, rendition := range renditions {
+		args = append(args,
+			"-map", fmt.Sprintf("[v%vout]", i),
+			fmt.Sprintf("-c:v:%v", i), "libx264",
+			fmt.Sprintf("-b:v:%v", i), rendition.VideoBitrate,
+		)
+		stream := fmt.Sprintf("v:%v", i)
+		if hasAudio {
+			args = append(args,
+				"-map", "0:a:0",
+				fmt.Sprintf("-c:a:%v", i), "aac",
+				fmt.Sprintf("-b:a:%v", i), rendition.AudioBitrate,
+			)
+			stream += fmt.Sprintf(",a:%v", i)
+		}
+		streamMap = append(streamMap, stream+",name:"+rendition.Name)
+	}
+	args = append(args,
+		"-preset", "veryfast",
+		// the segments of all the renditions should start at the same keyframes in order to switch between them
+		"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%v)", segmentDuration),
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(segmentDuration),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(dir, "%v", "segment_%05d.ts"),
+		"-master_pl_name", hlsMasterPlaylist,
+		"-var_stream_map", strings.Join(streamMap, " "),
+		"-progress", "pipe:1",
+		"-nostats",
+		filepath.Join(dir, "%v", hlsRenditionPlaylist),
+	)
+	return args
+}
+
+// ffmpeg writes the "key=value" lines to stdout, out_time_us is the position in the output
+func (s *HlsService) trackProgress(ctx context.Context, normalizedKey string, reader io.Reader, durationMicros int64) {
+	scanner := bufio.NewScanner(reader)
+	lastProgress := 0
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found || durationMicros <= 0 {
+			continue
+		}
+		// out_time_ms is in microseconds too, the old versions of ffmpeg have only it
+		if key != "out_time_us" && key != "out_time_ms" {
+			continue
+		}
+		outTime, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		progress := int(min(outTime*100/durationMicros, 99))
+		if progress > lastProgress {
+			lastProgress = progress
+			s.redisInfoService.SetHlsConverting(ctx, normalizedKey, progress)
+		}
+	}
+}
+
+func (s *HlsService) upload(ctx context.Context, normalizedKey, dir string) error {
+	userMetadata := SerializeOriginalKeyToMetadata(normalizedKey)
+	put := func(path string) error {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		contentType := hlsSegmentContentType
+		if strings.HasSuffix(path, ".m3u8") {
+			contentType = hlsPlaylistContentType
+		}
+		_, err = s.minio.FPutObject(ctx, s.minioConfig.FilesHls, GetHlsPrefix(normalizedKey)+filepath.ToSlash(rel), path, minio.PutObjectOptions{ContentType: contentType, UserMetadata: userMetadata})
+		return err
+	}
+
+	masterPath := filepath.Join(dir, hlsMasterPlaylist)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path == masterPath {
+			return nil
+		}
+		return put(path)
+	})
+	if err != nil {
+		return err
+	}
+	return put(masterPath)
+}
+
+func (s *HlsService) readPlaylist(ctx context.Context, key string) ([]string, error) {
+	object, err := s.minio.GetObject(ctx, s.minioConfig.FilesHls, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(object)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+func isPlaylistUri(line string) bool {
+	return len(line) > 0 && !strings.HasPrefix(line, "#")
+}
+
+// the rendition playlists are referenced through the storage in order to check the access
+func (s *HlsService) GetMasterPlaylist(ctx context.Context, normalizedKey string) (string, error) {
+	lines, err := s.readPlaylist(ctx, GetHlsMasterKey(normalizedKey))
+	if err != nil {
+		return "", err
+	}
+	for i, line := range lines {
+		if !isPlaylistUri(line) {
+			continue
+		}
+		rendition, _, _ := strings.Cut(line, "/")
+		lines[i], err = getHlsUrl(utils.UrlStorageHlsPlaylist, normalizedKey, rendition)
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// the segments are referenced by the short-lived presigned urls
+func (s *HlsService) GetRenditionPlaylist(ctx context.Context, normalizedKey, rendition string) (string, error) {
+	if rendition == "" || strings.ContainsAny(rendition, "/\\") || rendition == ".." {
+		return "", ErrHlsRenditionNotFound
+	}
+	playlistKey := getHlsRenditionKey(normalizedKey, rendition, hlsRenditionPlaylist)
+	exists, _, err := s.minio.FileExists(ctx, s.minioConfig.FilesHls, playlistKey)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", ErrHlsRenditionNotFound
+	}
+
+	lines, err := s.readPlaylist(ctx, playlistKey)
+	if err != nil {
+		return "", err
+	}
+	ttl := viper.GetDuration("converting.hls.presignedSegmentTtl")
+	for i, line := range lines {
+		if !isPlaylistUri(line) {
+			continue
+		}
+		lines[i], err = s.minio.PresignedExternalGetObject(ctx, s.minioConfig.FilesHls, getHlsRenditionKey(normalizedKey, rendition, line), ttl, url.Values{})
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// removes the hls of the video or of all the videos with the given prefix
+func (s *HlsService) Remove(ctx context.Context, prefix string) error {
+	return s.minio.RemoveObject(ctx, s.minioConfig.FilesHls, prefix, minio.RemoveObjectOptions{ForceDelete: true})
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/minio/minio-go/v7"
+	redisV9 "github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/utils"
+)
+
+const testHlsKey = "chat/1/file-item/video.mp4"
+
+var testHlsConfig = &utils.MinioConfig{Files: "files", FilesHls: "files-hls"}
+
+var testLadder = []HlsRendition{
+	{Name: "720p", Height: 720, VideoBitrate: "2800k", AudioBitrate: "128k"},
+	{Name: "360p", Height: 360, VideoBitrate: "800k", AudioBitrate: "96k"},
+	{Name: "1080p", Height: 1080, VideoBitrate: "5000k", AudioBitrate: "192k"},
+}
+
+func newTestHlsService(t *testing.T) (*HlsService, *backend.LocalBackend, *miniredis.Miniredis) {
+	ctx := context.Background()
+	lgr := logger.NewLogger()
+	local, err := backend.NewLocalBackend(lgr, t.TempDir(), "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := local.MakeBucket(ctx, testHlsConfig.FilesHls, minio.MakeBucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	mr := miniredis.RunT(t)
+	redisClient := redisV9.NewClient(&redisV9.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		redisClient.Close()
+	})
+	return NewHlsService(lgr, local, testHlsConfig, NewRedisInfoService(redisClient)), local, mr
+}
+
+func putHlsObject(t *testing.T, local *backend.LocalBackend, key, content string) {
+	_, err := local.PutObject(context.Background(), testHlsConfig.FilesHls, key, strings.NewReader(content), int64(len(content)), minio.PutObjectOptions{ContentType: hlsPlaylistContentType})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func renditionNames(renditions []HlsRendition) []string {
+	names := []string{}
+	for _, rendition := range renditions {
+		names = append(names, rendition.Name)
+	}
+	return names
+}
+
+func TestFitRenditions(t *testing.T) {
+	cases := []struct {
+		name         string
+		sourceHeight int
+		expected     []string
+	}{
+		{"the higher ones are skipped", 720, []string{"720p", "360p"}},
+		{"the whole ladder", 2160, []string{"720p", "360p", "1080p"}},
+		{"the lowest one is kept for the small source", 240, []string{"360p"}},
+		{"between the renditions", 500, []string{"360p"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if names := renditionNames(fitRenditions(testLadder, c.sourceHeight)); !reflect.DeepEqual(names, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, names)
+			}
+		})
+	}
+}
+
+func TestParseHlsProbe(t *testing.T) {
+	probe, err := parseHlsProbe([]byte(`{"streams": [{"codec_type": "video", "height": 480}, {"codec_type": "audio"}, {"codec_type": "video", "height": 720}], "format": {"duration": "12.5"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *probe != (hlsProbe{durationMicros: 12_500_000, height: 720, hasAudio: true}) {
+		t.Errorf("unexpected probe %+v", *probe)
+	}
+
+	// webm recordings can have no duration
+	probe, err = parseHlsProbe([]byte(`{"streams": [{"codec_type": "video", "height": 360}], "format": {}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *probe != (hlsProbe{height: 360}) {
+		t.Errorf("unexpected probe %+v", *probe)
+	}
+
+	if _, err = parseHlsProbe([]byte(`{"streams": [{"codec_type": "audio"}], "format": {"duration": "1"}}`)); err == nil {
+		t.Errorf("the audio without video should be refused")
+	}
+}
+
+func TestBuildHlsArgs(t *testing.T) {
+	renditions := fitRenditions(testLadder, 720)
+
+	args := strings.Join(buildHlsArgs("input", "dir", renditions, true, 4), " ")
+	for _, expected := range []string{
+		"-filter_complex [0:v]split=2[v0][v1];[v0]scale=-2:720[v0out];[v1]scale=-2:360[v1out]",
+		"-b:v:0 2800k", "-b:a:1 96k",
+		"-var_stream_map v:0,a:0,name:720p v:1,a:1,name:360p",
+		"-hls_time 4",
+	} {
+		if !strings.Contains(args, expected) {
+			t.Errorf("expected %q in %v", expected, args)
+		}
+	}
+
+	args = strings.Join(buildHlsArgs("input", "dir", renditions, false, 4), " ")
+	if strings.Contains(args, "0:a:0") {
+		t.Errorf("there should be no audio mapping, got %v", args)
+	}
+	if !strings.Contains(args, "-var_stream_map v:0,name:720p v:1,name:360p") {
+		t.Errorf("unexpected stream map in %v", args)
+	}
+}
+
+func TestGetMasterPlaylist(t *testing.T) {
+	s, local, _ := newTestHlsService(t)
+	putHlsObject(t, local, GetHlsMasterKey(testHlsKey), "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=2928000,RESOLUTION=1280x720\n720p/index.m3u8\n#EXT-X-STREAM-INF:BANDWIDTH=896000,RESOLUTION=640x360\n360p/index.m3u8\n")
+
+	playlist, err := s.GetMasterPlaylist(context.Background(), testHlsKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(playlist, "\n"), "\n")
+	if len(lines) != 5 || lines[0] != "#EXTM3U" || !strings.HasPrefix(lines[1], "#EXT-X-STREAM-INF") {
+		t.Fatalf("the tags should be kept, got %v", playlist)
+	}
+	for i, rendition := range map[int]string{2: "720p", 4: "360p"} {
+		// the rendition playlists are requested through the storage, so the access is checked
+		rewritten, err := url.Parse(lines[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rewritten.Path != utils.UrlStorageHlsPlaylist || rewritten.Query().Get(utils.FileParam) != testHlsKey || rewritten.Query().Get(utils.RenditionParam) != rendition {
+			t.Errorf("unexpected url of %v: %v", rendition, lines[i])
+		}
+	}
+}
+
+func TestGetRenditionPlaylist(t *testing.T) {
+	viper.Set("converting.hls.presignedSegmentTtl", time.Minute)
+	s, local, _ := newTestHlsService(t)
+	putHlsObject(t, local, getHlsRenditionKey(testHlsKey, "360p", hlsRenditionPlaylist), "#EXTM3U\n#EXT-X-TARGETDURATION:4\n#EXTINF:4.000000,\nsegment_00000.ts\n#EXTINF:2.000000,\nsegment_00001.ts\n#EXT-X-ENDLIST\n")
+
+	playlist, err := s.GetRenditionPlaylist(context.Background(), testHlsKey, "360p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(playlist, "\n"), "\n")
+	if len(lines) != 7 || lines[6] != "#EXT-X-ENDLIST" {
+		t.Fatalf("the tags should be kept, got %v", playlist)
+	}
+	for i, segment := range map[int]string{3: "segment_00000.ts", 5: "segment_00001.ts"} {
+		presigned, err := url.Parse(lines[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := local.VerifySignedUrl("GET", presigned.Path, presigned.Query()); err != nil {
+			t.Errorf("the url of %v should be presigned, got %v: %v", segment, lines[i], err)
+		}
+		bucket, key := backend.LocalObjectFromUrl(presigned.Query())
+		if bucket != testHlsConfig.FilesHls || key != getHlsRenditionKey(testHlsKey, "360p", segment) {
+			t.Errorf("unexpected object of %v: %v/%v", segment, bucket, key)
+		}
+	}
+}
+
+func TestGetRenditionPlaylistNotFound(t *testing.T) {
+	s, local, _ := newTestHlsService(t)
+	putHlsObject(t, local, GetHlsMasterKey(testHlsKey), "#EXTM3U\n")
+
+	for _, rendition := range []string{"", "1080p", "..", "../other", "360p/../.."} {
+		if _, err := s.GetRenditionPlaylist(context.Background(), testHlsKey, rendition); !errors.Is(err, ErrHlsRenditionNotFound) {
+			t.Errorf("rendition %q: expected not found, got %v", rendition, err)
+		}
+	}
+}
+
+func TestHlsProgress(t *testing.T) {
+	ctx := context.Background()
+	s, local, mr := newTestHlsService(t)
+	progressKey := convertingHlsPrefix + testHlsKey
+
+	// the lines of the other keys and the going back are ignored
+	s.trackProgress(ctx, testHlsKey, strings.NewReader("frame=10\nout_time_us=2500000\nout_time_ms=1000000\nprogress=continue\n"), 10_000_000)
+	if value, err := mr.Get(progressKey); err != nil || value != "25" {
+		t.Errorf("expected the progress 25 in %v, got %v %v", progressKey, value, err)
+	}
+
+	// 100 is only for the uploaded ladder
+	s.trackProgress(ctx, testHlsKey, strings.NewReader("out_time_us=9000000\nout_time_us=10000000\nprogress=end\n"), 10_000_000)
+	if value, err := mr.Get(progressKey); err != nil || value != "99" {
+		t.Errorf("expected the progress 99 in %v, got %v %v", progressKey, value, err)
+	}
+
+	status, err := s.GetStatus(ctx, testHlsKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != dto.HlsStatusConverting || status.Progress != 99 {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	s.redisInfoService.RemoveHlsConverting(ctx, testHlsKey)
+	status, err = s.GetStatus(ctx, testHlsKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != dto.HlsStatusNone {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	putHlsObject(t, local, GetHlsMasterKey(testHlsKey), "#EXTM3U\n")
+	status, err = s.GetStatus(ctx, testHlsKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != dto.HlsStatusReady || status.Progress != 100 || status.Url == nil {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestHlsProgressWithoutDuration(t *testing.T) {
+	s, _, mr := newTestHlsService(t)
+
+	s.trackProgress(context.Background(), testHlsKey, strings.NewReader("out_time_us=2500000\n"), 0)
+	if mr.Exists(convertingHlsPrefix + testHlsKey) {
+		t.Errorf("the progress can't be calculated without the duration")
+	}
+}
+
+func TestHlsUpload(t *testing.T) {
+	s, local, _ := newTestHlsService(t)
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		hlsMasterPlaylist: "#EXTM3U\n",
+		filepath.Join("360p", hlsRenditionPlaylist): "#EXTM3U\n",
+		filepath.Join("360p", "segment_00000.ts"):   "segment",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.upload(context.Background(), testHlsKey, dir); err != nil {
+		t.Fatal(err)
+	}
+	for key, contentType := range map[string]string{
+		GetHlsMasterKey(testHlsKey):                                  hlsPlaylistContentType,
+		getHlsRenditionKey(testHlsKey, "360p", hlsRenditionPlaylist): hlsPlaylistContentType,
+		getHlsRenditionKey(testHlsKey, "360p", "segment_00000.ts"):   hlsSegmentContentType,
+	} {
+		objectInfo, err := local.StatObject(context.Background(), testHlsConfig.FilesHls, key, minio.StatObjectOptions{})
+		if err != nil {
+			t.Errorf("%v should be uploaded: %v", key, err)
+			continue
+		}
+		if objectInfo.ContentType != contentType {
+			t.Errorf("unexpected content type of %v: %v", key, objectInfo.ContentType)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/utils"
+)
+
+// the exif is in the beginning of jpeg, so there is no need to read the whole image in order to get the orientation
+const mediaImageHeaderSize = 256 * 1024
+
+// extracts the properties of the uploaded media and removes the private metadata from the images
+type MediaService struct {
+	minio       backend.Backend
+	minioConfig *utils.MinioConfig
+	lgr         *logger.Logger
+}
+
+func NewMediaService(lgr *logger.Logger, minio backend.Backend, minioConfig *utils.MinioConfig) *MediaService {
+	return &MediaService{
+		minio:       minio,
+		minioConfig: minioConfig,
+		lgr:         lgr,
+	}
+}
+
+func (s *MediaService) IsScrubbingEnabled() bool {
+	return viper.GetBool("media.scrubbing.enabled")
+}
+
+// rewrites the image without exif and the other private metadata, the tags and the user metadata are preserved.
+// returns true in case the image was rewritten, so the rewriting causes the new event
+func (s *MediaService) Scrub(ctx context.Context, normalizedKey string) (bool, error) {
+	scrubber := getScrubber(normalizedKey)
+	if scrubber == nil {
+		return false, nil
+	}
+
+	objectInfo, err := s.minio.StatObject(ctx, s.minioConfig.Files, normalizedKey, minio.StatObjectOptions{})
+	if err != nil {
+		return false, err
+	}
+	if objectInfo.Size > viper.GetInt64("media.scrubbing.maxSize") {
+		s.lgr.WithTracing(ctx).Infof("Image %v is too big to be scrubbed: %v bytes", normalizedKey, objectInfo.Size)
+		return false, nil
+	}
+	if _, ok := objectInfo.UserMetadata[ScrubbedKey(false)]; ok {
+		return false, nil
+	}
+
+	object, err := s.minio.GetObject(ctx, s.minioConfig.Files, normalizedKey, minio.GetObjectOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return false, err
+	}
+
+	scrubbed, changed, err := scrubber(data)
+	if err != nil {
+		return false, err
+	}
+	if !changed {
+		return false, nil
+	}
+
+	tagging, err := s.minio.GetObjectTagging(ctx, s.minioConfig.Files, normalizedKey, minio.GetObjectTaggingOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	userMetadata := map[string]string{}
+	for k, v := range objectInfo.UserMetadata {
+		userMetadata[k] = v
+	}
+	userMetadata[ScrubbedKey(false)] = utils.BooleanToString(true)
+	var userTags map[string]string
+	if tagging != nil {
+		userTags = tagging.ToMap()
+	}
+
+	_, err = s.minio.PutObject(ctx, s.minioConfig.Files, normalizedKey, bytes.NewReader(scrubbed), int64(len(scrubbed)), minio.PutObjectOptions{
+		ContentType:  objectInfo.ContentType,
+		UserMetadata: userMetadata,
+		UserTags:     userTags,
+	})
+	if err != nil {
+		return false, err
+	}
+	s.lgr.WithTracing(ctx).Infof("Removed %v bytes of metadata from image %v", len(data)-len(scrubbed), normalizedKey)
+	return true, nil
+}
+
+// the errors are logged, the properties which weren't extracted are nil
+func (s *MediaService) Extract(ctx context.Context, normalizedKey string) dto.MediaMetadata {
+	var res dto.MediaMetadata
+	var err error
+	if utils.IsImage(normalizedKey) {
+		res, err = s.extractImage(ctx, normalizedKey)
+	} else if utils.IsVideo(normalizedKey) || utils.IsAudio(normalizedKey) {
+		res, err = s.extractAudioVideo(ctx, normalizedKey)
+	} else if utils.IsOfType(Document_pdf, normalizedKey) {
+		res, err = s.extractPdf(ctx, normalizedKey)
+	}
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error during extracting media metadata of %v: %v", normalizedKey, err)
+	}
+	return res
+}
+
+func (s *MediaService) extractImage(ctx context.Context, normalizedKey string) (dto.MediaMetadata, error) {
+	object, err := s.minio.GetObject(ctx, s.minioConfig.Files, normalizedKey, minio.GetObjectOptions{})
+	if err != nil {
+		return dto.MediaMetadata{}, err
+	}
+	defer object.Close()
+
+	header, err := io.ReadAll(io.LimitReader(object, mediaImageHeaderSize))
+	if err != nil {
+		return dto.MediaMetadata{}, err
+	}
+	config, _, err := image.DecodeConfig(io.MultiReader(bytes.NewReader(header), object))
+	if err != nil {
+		return dto.MediaMetadata{}, err
+	}
+	width, height := int32(config.Width), int32(config.Height)
+	// the viewer rotates the image according to the orientation
+	if orientation := readJpegOrientation(header); orientation >= 5 {
+		width, height = height, width
+	}
+	return dto.MediaMetadata{Width: &width, Height: &height}, nil
+}
+
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+		Width     int32  `json:"width"`
+		Height    int32  `json:"height"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+func (s *MediaService) extractAudioVideo(ctx context.Context, normalizedKey string) (dto.MediaMetadata, error) {
+	presignedUrl, err := s.minio.PresignedGetObject(ctx, s.minioConfig.Files, normalizedKey, viper.GetDuration("preview.presignedDuration"), url.Values{})
+	if err != nil {
+		return dto.MediaMetadata{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, viper.GetDuration("media.timeout"))
+	defer cancel()
+
+	probeCmd := exec.CommandContext(ctx, viper.GetString("media.ffprobePath"),
+		"-v", "error", "-print_format", "json", "-show_format", "-show_streams", presignedUrl.String())
+	out, err := runMediaCommand(probeCmd)
+	if err != nil {
+		return dto.MediaMetadata{}, err
+	}
+
+	var probed ffprobeOutput
+	err = json.Unmarshal(out.Bytes(), &probed)
+	if err != nil {
+		return dto.MediaMetadata{}, err
+	}
+
+	var res dto.MediaMetadata
+	for _, stream := range probed.Streams {
+		codecName := stream.CodecName
+		switch stream.CodecType {
+		// the cover of the audio is the video stream too, so the first one is taken
+		case "video":
+			if res.VideoCodec == nil {
+				res.VideoCodec = &codecName
+				if stream.Width > 0 && stream.Height > 0 {
+					width, height := stream.Width, stream.Height
+					res.Width, res.Height = &width, &height
+				}
+			}
+		case "audio":
+			if res.AudioCodec == nil {
+				res.AudioCodec = &codecName
+			}
+		}
+	}
+	// the recordings of the browser can have no duration
+	if duration, err := strconv.ParseFloat(probed.Format.Duration, 64); err == nil {
+		res.Duration = &duration
+	}
+	return res, nil
+}
+
+// counts the pages with pdfinfo from poppler-utils, it doesn't read from stdin
+func (s *MediaService) extractPdf(ctx context.Context, normalizedKey string) (dto.MediaMetadata, error) {
+	object, err := s.minio.GetObject(ctx, s.minioConfig.Files, normalizedKey, minio.GetObjectOptions{})
+	if err != nil {
+		return dto.MediaMetadata{}, err
+	}
+	defer object.Close()
+
+	file, err := os.CreateTemp(viper.GetString("preview.tempDir"), "media-*.pdf")
+	if err != nil {
+		return dto.MediaMetadata{}, err
+	}
+	defer os.Remove(file.Name())
+	_, err = io.Copy(file, object)
+	file.Close()
+	if err != nil {
+		return dto.MediaMetadata{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, viper.GetDuration("media.timeout"))
+	defer cancel()
+
+	infoCmd := exec.CommandContext(ctx, viper.GetString("media.pdfinfoPath"), file.Name())
+	out, err := runMediaCommand(infoCmd)
+	if err != nil {
+		return dto.MediaMetadata{}, err
+	}
+
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		name, value, found := strings.Cut(scanner.Text(), ":")
+		if found && name == "Pages" {
+			pages, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+			if err != nil {
+				return dto.MediaMetadata{}, err
+			}
+			pageCount := int32(pages)
+			return dto.MediaMetadata{PageCount: &pageCount}, nil
+		}
+	}
+	return dto.MediaMetadata{}, nil
+}
+
+func runMediaCommand(cmd *exec.Cmd) (*bytes.Buffer, error) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Pdeathsig: syscall.SIGTERM,
+	}
+
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("%w: stderr: %v", err, stderr.String())
+	}
+	return &out, nil
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/siyouyun-open/imaging"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/utils"
+)
+
+const Document_pdf = "pdf"
+const Document_office = "office"
+
+// renders the jpeg thumbnail of the file
+type Previewer interface {
+	Preview(ctx context.Context, normalizedKey string) (*bytes.Buffer, error)
+	// the key of the thumbnail in the preview bucket
+	PreviewKey(normalizedKey string) string
+}
+
+// the previewers are keyed by type, the type of file is recognized by the extensions from "types.<type>" config
+type PreviewerRegistry struct {
+	previewers map[string]Previewer
+	types      []string // keeps the order of the registration
+}
+
+func NewPreviewerRegistry(lgr *logger.Logger, minio backend.Backend, minioConfig *utils.MinioConfig) *PreviewerRegistry {
+	registry := &PreviewerRegistry{
+		previewers: map[string]Previewer{},
+	}
+	pdf := &pdfPreviewer{minio: minio, minioConfig: minioConfig, lgr: lgr}
+	registry.Register(Media_image, &imagePreviewer{minio: minio, minioConfig: minioConfig, lgr: lgr})
+	registry.Register(Media_video, &videoPreviewer{minio: minio, minioConfig: minioConfig, lgr: lgr})
+	registry.Register(Document_pdf, pdf)
+	// LibreOffice is big, so it's present only in the image built for it, see Dockerfile
+	if viper.GetBool("preview.office.enabled") {
+		registry.Register(Document_office, &officePreviewer{pdfPreviewer: pdf})
+	}
+	return registry
+}
+
+// replaces the previewer in case the type is already registered
+func (r *PreviewerRegistry) Register(aType string, previewer Previewer) {
+	if _, ok := r.previewers[aType]; !ok {
+		r.types = append(r.types, aType)
+	}
+	r.previewers[aType] = previewer
+}
+
+// returns nil in case there is no previewer for this file
+func (r *PreviewerRegistry) Get(normalizedKey string) Previewer {
+	for _, aType := range r.types {
+		if utils.IsOfType(aType, normalizedKey) {
+			return r.previewers[aType]
+		}
+	}
+	return nil
+}
+
+func (r *PreviewerRegistry) HasPreviewer(normalizedKey string) bool {
+	return r.Get(normalizedKey) != nil
+}
+
+// returns an empty string in case there is no previewer for this file
+func (r *PreviewerRegistry) GetPreviewKey(normalizedKey string) string {
+	previewer := r.Get(normalizedKey)
+	if previewer == nil {
+		return ""
+	}
+	return previewer.PreviewKey(normalizedKey)
+}
+
+type imagePreviewer struct {
+	minio       backend.Backend
+	minioConfig *utils.MinioConfig
+	lgr         *logger.Logger
+}
+
+func (p *imagePreviewer) Preview(ctx context.Context, normalizedKey string) (*bytes.Buffer, error) {
+	object, err := p.minio.GetObject(ctx, p.minioConfig.Files, normalizedKey, minio.GetObjectOptions{})
+	if err != nil {
+		p.lgr.WithTracing(ctx).Errorf("Error during getting image file %v for %v", err, normalizedKey)
+		return nil, err
+	}
+	defer object.Close()
+
+	return resizeImageToJpg(ctx, p.lgr, object)
+}
+
+func (p *imagePreviewer) PreviewKey(normalizedKey string) string {
+	return utils.SetImagePreviewExtension(normalizedKey)
+}
+
+type videoPreviewer struct {
+	minio       backend.Backend
+	minioConfig *utils.MinioConfig
+	lgr         *logger.Logger
+}
+
+func (p *videoPreviewer) Preview(ctx context.Context, normalizedKey string) (*bytes.Buffer, error) {
+	d := viper.GetDuration("preview.presignedDuration")
+	presignedUrl, err := p.minio.PresignedGetObject(ctx, p.minioConfig.Files, normalizedKey, d, url.Values{})
+	if err != nil {
+		p.lgr.WithTracing(ctx).Errorf("Error during getting presigned url for %v", normalizedKey)
+		return nil, err
+	}
+	stringPresingedUrl := presignedUrl.String()
+
+	ffCmd := exec.Command(viper.GetString("preview.ffmpegPath"),
+		"-i", stringPresingedUrl, "-vf", "thumbnail", "-frames:v", "1",
+		"-c:v", "png", "-f", "rawvideo", "-an", "-")
+	out, err := runPreviewCommand(ctx, p.lgr, ffCmd, normalizedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return resizeImageToJpg(ctx, p.lgr, bytes.NewReader(out.Bytes()))
+}
+
+func (p *videoPreviewer) PreviewKey(normalizedKey string) string {
+	return utils.SetVideoPreviewExtension(normalizedKey)
+}
+
+// renders the first page with pdftoppm from poppler-utils
+type pdfPreviewer struct {
+	minio       backend.Backend
+	minioConfig *utils.MinioConfig
+	lgr         *logger.Logger
+}
+
+func (p *pdfPreviewer) Preview(ctx context.Context, normalizedKey string) (*bytes.Buffer, error) {
+	object, err := p.minio.GetObject(ctx, p.minioConfig.Files, normalizedKey, minio.GetObjectOptions{})
+	if err != nil {
+		p.lgr.WithTracing(ctx).Errorf("Error during getting pdf file %v for %v", err, normalizedKey)
+		return nil, err
+	}
+	defer object.Close()
+
+	return p.render(ctx, normalizedKey, object)
+}
+
+func (p *pdfPreviewer) render(ctx context.Context, normalizedKey string, pdf io.Reader) (*bytes.Buffer, error) {
+	ctx, cancel := context.WithTimeout(ctx, getDocumentPreviewTimeout())
+	defer cancel()
+
+	// "-" as the input means stdin, the absent output prefix means stdout
+	pdfCmd := exec.CommandContext(ctx, viper.GetString("preview.pdftoppmPath"),
+		"-png", "-f", "1", "-l", "1", "-singlefile", "-scale-to", "720", "-")
+	pdfCmd.Stdin = pdf
+	out, err := runPreviewCommand(ctx, p.lgr, pdfCmd, normalizedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return resizeImageToJpg(ctx, p.lgr, bytes.NewReader(out.Bytes()))
+}
+
+func (p *pdfPreviewer) PreviewKey(normalizedKey string) string {
+	return utils.SetDocumentPreviewExtension(normalizedKey)
+}
+
+// converts the document to pdf with headless LibreOffice, then renders its first page
+type officePreviewer struct {
+	pdfPreviewer *pdfPreviewer
+}
+
+func (p *officePreviewer) Preview(ctx context.Context, normalizedKey string) (*bytes.Buffer, error) {
+	lgr := p.pdfPreviewer.lgr
+	object, err := p.pdfPreviewer.minio.GetObject(ctx, p.pdfPreviewer.minioConfig.Files, normalizedKey, minio.GetObjectOptions{})
+	if err != nil {
+		lgr.WithTracing(ctx).Errorf("Error during getting office file %v for %v", err, normalizedKey)
+		return nil, err
+	}
+	defer object.Close()
+
+	// LibreOffice reads and writes only the files
+	workDir, err := os.MkdirTemp(viper.GetString("preview.tempDir"), "preview-")
+	if err != nil {
+		lgr.WithTracing(ctx).Errorf("Error during creating temporary directory for %v: %v", normalizedKey, err)
+		return nil, err
+	}
+	defer os.RemoveAll(workDir)
+
+	sourceFile := filepath.Join(workDir, "source"+utils.GetDotExtensionStr(normalizedKey))
+	err = writeToFile(sourceFile, object)
+	if err != nil {
+		lgr.WithTracing(ctx).Errorf("Error during saving %v to temporary file: %v", normalizedKey, err)
+		return nil, err
+	}
+
+	convertCtx, cancel := context.WithTimeout(ctx, getDocumentPreviewTimeout())
+	defer cancel()
+
+	// the own profile allows to run several converters simultaneously
+	sofficeCmd := exec.CommandContext(convertCtx, viper.GetString("preview.libreOfficePath"),
+		"-env:UserInstallation=file://"+filepath.Join(workDir, "profile"),
+		"--headless", "--convert-to", "pdf", "--outdir", workDir, sourceFile)
+	_, err = runPreviewCommand(convertCtx, lgr, sofficeCmd, normalizedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf, err := os.Open(filepath.Join(workDir, "source.pdf"))
+	if err != nil {
+		lgr.WithTracing(ctx).Errorf("Error during opening converted pdf for %v: %v", normalizedKey, err)
+		return nil, err
+	}
+	defer pdf.Close()
+
+	return p.pdfPreviewer.render(ctx, normalizedKey, pdf)
+}
+
+func (p *officePreviewer) PreviewKey(normalizedKey string) string {
+	return utils.SetDocumentPreviewExtension(normalizedKey)
+}
+
+func getDocumentPreviewTimeout() time.Duration {
+	return viper.GetDuration("preview.documentTimeout")
+}
+
+func writeToFile(fileName string, reader io.Reader) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(file, reader)
+	return err
+}
+
+func runPreviewCommand(ctx context.Context, lgr *logger.Logger, cmd *exec.Cmd, normalizedKey string) (*bytes.Buffer, error) {
+	// https://medium.com/@ganeshmaharaj/clean-exit-of-golangs-exec-command-897832ac3fa5
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Pdeathsig: syscall.SIGTERM,
+	}
+
+	// getting real error msg : https://stackoverflow.com/questions/18159704/how-to-debug-exit-status-1-error-when-running-exec-command-in-golang
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		lgr.WithTracing(ctx).Errorf("Error during creating thumbnail for key %v: %v: stderr: %v", normalizedKey, fmt.Sprint(err), stderr.String())
+		return nil, err
+	}
+	return &out, nil
+}
+
+func resizeImageToJpg(ctx context.Context, lgr *logger.Logger, reader io.Reader) (*bytes.Buffer, error) {
+	srcImage, _, err := image.Decode(reader)
+	if err != nil {
+		lgr.WithTracing(ctx).Errorf("Error during decoding image: %v", err)
+		return nil, err
+	}
+	dstImage := imaging.Resize(srcImage, 0, 360, imaging.Lanczos)
+	byteBuffer := new(bytes.Buffer)
+	err = jpeg.Encode(byteBuffer, dstImage, nil)
+	if err != nil {
+		lgr.WithTracing(ctx).Errorf("Error during encoding image: %v", err)
+		return nil, err
+	}
+	return byteBuffer, nil
+}
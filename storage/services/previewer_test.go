@@ -0,0 +1,227 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/utils"
+)
+
+const testFilesBucket = "files"
+
+func setPreviewTypes() {
+	viper.Set("types.image", []string{".png", ".jpg"})
+	viper.Set("types.video", []string{".mp4"})
+	viper.Set("types.pdf", []string{".pdf"})
+	viper.Set("types.office", []string{".docx", ".odt"})
+}
+
+func TestPreviewerRegistry(t *testing.T) {
+	setPreviewTypes()
+	cases := []struct {
+		name          string
+		officeEnabled bool
+		key           string
+		previewer     Previewer
+		previewKey    string
+	}{
+		{"image", false, "chat/1/a.png", &imagePreviewer{}, "chat/1/a.jpg"},
+		{"pdf", false, "chat/1/a.pdf", &pdfPreviewer{}, "chat/1/a.jpg"},
+		{"office is disabled", false, "chat/1/a.docx", nil, ""},
+		{"office is enabled", true, "chat/1/a.docx", &officePreviewer{}, "chat/1/a.jpg"},
+		{"unknown", true, "chat/1/a.bin", nil, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			viper.Set("preview.office.enabled", c.officeEnabled)
+			registry := NewPreviewerRegistry(logger.NewLogger(), nil, &utils.MinioConfig{Files: testFilesBucket})
+
+			previewer := registry.Get(c.key)
+			if c.previewer == nil {
+				if previewer != nil || registry.HasPreviewer(c.key) {
+					t.Errorf("there should be no previewer for %v, got %T", c.key, previewer)
+				}
+			} else if fmt.Sprintf("%T", previewer) != fmt.Sprintf("%T", c.previewer) {
+				t.Errorf("expected %T for %v, got %T", c.previewer, c.key, previewer)
+			}
+			if actual := registry.GetPreviewKey(c.key); actual != c.previewKey {
+				t.Errorf("expected the preview key %q, got %q", c.previewKey, actual)
+			}
+		})
+	}
+}
+
+// the fake converters are the shell scripts which record what they have got
+type fakeConverters struct {
+	dir     string
+	tempDir string
+}
+
+func (f *fakeConverters) path(name string) string {
+	return filepath.Join(f.dir, name)
+}
+
+func (f *fakeConverters) read(t *testing.T, name string) string {
+	data, err := os.ReadFile(f.path(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func writeScript(t *testing.T, path string, body string) {
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// soffice converts by copying the source as the pdf next to it, pdftoppm prints the png page
+func setupFakeConverters(t *testing.T, sofficeBody string) *fakeConverters {
+	f := &fakeConverters{dir: t.TempDir(), tempDir: t.TempDir()}
+
+	page := image.NewRGBA(image.Rect(0, 0, 500, 720))
+	for x := 0; x < 500; x++ {
+		page.Set(x, 100, color.Black)
+	}
+	var pageBuf bytes.Buffer
+	if err := png.Encode(&pageBuf, page); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(f.path("page.png"), pageBuf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	writeScript(t, f.path("soffice"), `printf '%s\n' "$@" > `+f.path("soffice.args")+"\n"+sofficeBody)
+	writeScript(t, f.path("pdftoppm"), `printf '%s\n' "$@" > `+f.path("pdftoppm.args")+"\n"+
+		`cat > `+f.path("pdftoppm.stdin")+"\n"+
+		`cat `+f.path("page.png"))
+
+	viper.Set("preview.libreOfficePath", f.path("soffice"))
+	viper.Set("preview.pdftoppmPath", f.path("pdftoppm"))
+	viper.Set("preview.tempDir", f.tempDir)
+	viper.Set("preview.documentTimeout", 5*time.Second)
+	return f
+}
+
+const convertBySourceCopy = `eval src=\${$#}
+cp "$src" "$(dirname "$src")/source.pdf"`
+
+func newTestOfficePreviewer(t *testing.T, key string, content []byte) *officePreviewer {
+	setPreviewTypes()
+	viper.Set("preview.office.enabled", true)
+	lgr := logger.NewLogger()
+	b, err := backend.NewLocalBackend(lgr, t.TempDir(), "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := b.MakeBucket(ctx, testFilesBucket, minio.MakeBucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.PutObject(ctx, testFilesBucket, key, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	previewer, ok := NewPreviewerRegistry(lgr, b, &utils.MinioConfig{Files: testFilesBucket}).Get(key).(*officePreviewer)
+	if !ok {
+		t.Fatalf("there is no office previewer for %v", key)
+	}
+	return previewer
+}
+
+func assertTempDirIsEmpty(t *testing.T, f *fakeConverters) {
+	entries, err := os.ReadDir(f.tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("the temporary files should be removed, got %v", entries)
+	}
+}
+
+func TestOfficePreview(t *testing.T) {
+	f := setupFakeConverters(t, convertBySourceCopy)
+	content := []byte("the content of the document")
+	previewer := newTestOfficePreviewer(t, "chat/1/report.docx", content)
+
+	preview, err := previewer.Preview(context.Background(), "chat/1/report.docx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thumbnail, err := jpeg.Decode(preview)
+	if err != nil {
+		t.Fatalf("the preview should be jpeg: %v", err)
+	}
+	if height := thumbnail.Bounds().Dy(); height != 360 {
+		t.Errorf("the preview should be resized to 360, got %v", height)
+	}
+
+	sofficeArgs := strings.Split(strings.TrimSpace(f.read(t, "soffice.args")), "\n")
+	if len(sofficeArgs) != 7 || sofficeArgs[1] != "--headless" || sofficeArgs[3] != "pdf" {
+		t.Fatalf("unexpected soffice arguments %v", sofficeArgs)
+	}
+	workDir := sofficeArgs[5]
+	if filepath.Dir(workDir) != f.tempDir {
+		t.Errorf("the conversion should happen in %v, got %v", f.tempDir, workDir)
+	}
+	// the own profile of LibreOffice allows the simultaneous conversions
+	if sofficeArgs[0] != "-env:UserInstallation=file://"+filepath.Join(workDir, "profile") {
+		t.Errorf("unexpected profile %v", sofficeArgs[0])
+	}
+	if sofficeArgs[6] != filepath.Join(workDir, "source.docx") {
+		t.Errorf("the source should keep the extension, got %v", sofficeArgs[6])
+	}
+
+	// the converted pdf is rendered
+	if stdin := f.read(t, "pdftoppm.stdin"); stdin != string(content) {
+		t.Errorf("pdftoppm should get the converted pdf, got %q", stdin)
+	}
+	if args := f.read(t, "pdftoppm.args"); !strings.Contains(args, "-singlefile") {
+		t.Errorf("only the first page should be rendered, got %v", args)
+	}
+	assertTempDirIsEmpty(t, f)
+}
+
+func TestOfficePreviewConverterFails(t *testing.T) {
+	f := setupFakeConverters(t, "echo 'broken document' >&2; exit 1")
+	previewer := newTestOfficePreviewer(t, "chat/1/report.odt", []byte("broken"))
+
+	_, err := previewer.Preview(context.Background(), "chat/1/report.odt")
+	if err == nil {
+		t.Fatal("the error of the converter should be returned")
+	}
+	if _, err := os.Stat(f.path("pdftoppm.stdin")); !os.IsNotExist(err) {
+		t.Error("pdftoppm shouldn't be called")
+	}
+	assertTempDirIsEmpty(t, f)
+}
+
+func TestOfficePreviewTimeout(t *testing.T) {
+	f := setupFakeConverters(t, "exec sleep 30")
+	viper.Set("preview.documentTimeout", 200*time.Millisecond)
+	previewer := newTestOfficePreviewer(t, "chat/1/report.docx", []byte("slow"))
+
+	started := time.Now()
+	_, err := previewer.Preview(context.Background(), "chat/1/report.docx")
+	if err == nil {
+		t.Fatal("the hanging converter should be stopped with the error")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("the converter should be stopped by the timeout, took %v", elapsed)
+	}
+	assertTempDirIsEmpty(t, f)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/viper"
+	"nkonev.name/storage/client"
+	"nkonev.name/storage/db"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+)
+
+var ErrInvalidQuota = errors.New("invalid quota")
+
+type QuotaService struct {
+	restClient *client.RestClient
+	dba        *db.DB
+	lgr        *logger.Logger
+}
+
+func NewQuotaService(
+	lgr *logger.Logger,
+	chatClient *client.RestClient,
+	dba *db.DB,
+) *QuotaService {
+	return &QuotaService{
+		restClient: chatClient,
+		dba:        dba,
+		lgr:        lgr,
+	}
+}
+
+func isValidSubjectType(subjectType string) bool {
+	return subjectType == dto.QuotaSubjectUser || subjectType == dto.QuotaSubjectChat
+}
+
+// the default from the config is used until admin sets it, non-positive value means unlimited
+func getConfiguredDefaultQuota(subjectType string) *int64 {
+	v := viper.GetInt64("limits.default." + subjectType + ".limit")
+	if v <= 0 {
+		return nil
+	}
+	return &v
+}
+
+// the own quota of subject, then the default quota set by admin, then the default quota from the config
+func (s *QuotaService) getMaxSize(ctx context.Context, co db.CommonOperations, subjectType string, subjectId int64) (*int64, error) {
+	for _, id := range []int64{subjectId, dto.QuotaSubjectDefault} {
+		quota, err := db.GetQuota(ctx, co, subjectType, id)
+		if err != nil {
+			return nil, err
+		}
+		if quota != nil {
+			return quota.MaxSize, nil
+		}
+	}
+	return getConfiguredDefaultQuota(subjectType), nil
+}
+
+func (s *QuotaService) getUsage(ctx context.Context, subjectType string, subjectId int64) (*dto.QuotaUsageDto, error) {
+	return db.TransactWithResult(ctx, s.dba, func(tx *db.Tx) (*dto.QuotaUsageDto, error) {
+		maxSize, err := s.getMaxSize(ctx, tx, subjectType, subjectId)
+		if err != nil {
+			return nil, err
+		}
+
+		var used int64
+		if subjectType == dto.QuotaSubjectUser {
+			used, err = db.GetConsumptionByOwner(ctx, tx, subjectId)
+		} else {
+			used, err = db.GetConsumptionByChat(ctx, tx, subjectId)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		res := &dto.QuotaUsageDto{
+			Used: used,
+			Max:  maxSize,
+		}
+		if maxSize != nil {
+			available := *maxSize - used
+			res.Available = &available
+		}
+		return res, nil
+	})
+}
+
+func (s *QuotaService) GetUserUsage(ctx context.Context, userId int64) (*dto.QuotaUsageDto, error) {
+	return s.getUsage(ctx, dto.QuotaSubjectUser, userId)
+}
+
+func (s *QuotaService) GetChatUsage(ctx context.Context, chatId int64) (*dto.QuotaUsageDto, error) {
+	return s.getUsage(ctx, dto.QuotaSubjectChat, chatId)
+}
+
+func convertToQuotaDto(quota dto.Quota) dto.QuotaDto {
+	res := dto.QuotaDto{
+		SubjectType: quota.SubjectType,
+		MaxSize:     quota.MaxSize,
+	}
+	if quota.SubjectId != dto.QuotaSubjectDefault {
+		subjectId := quota.SubjectId
+		res.SubjectId = &subjectId
+	}
+	return res
+}
+
+func (s *QuotaService) GetQuotas(ctx context.Context) ([]dto.QuotaDto, error) {
+	quotas, err := db.GetAllQuotas(ctx, s.dba)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.QuotaDto, 0, len(quotas))
+	for _, quota := range quotas {
+		res = append(res, convertToQuotaDto(quota))
+	}
+	return res, nil
+}
+
+func (s *QuotaService) SetQuota(ctx context.Context, quotaDto *dto.QuotaDto) error {
+	if !isValidSubjectType(quotaDto.SubjectType) {
+		return ErrInvalidQuota
+	}
+	if quotaDto.MaxSize != nil && *quotaDto.MaxSize < 0 {
+		return ErrInvalidQuota
+	}
+	subjectId := int64(dto.QuotaSubjectDefault)
+	if quotaDto.SubjectId != nil {
+		subjectId = *quotaDto.SubjectId
+	}
+
+	return db.SetQuota(ctx, s.dba, dto.Quota{
+		SubjectType: quotaDto.SubjectType,
+		SubjectId:   subjectId,
+		MaxSize:     quotaDto.MaxSize,
+	})
+}
+
+// after removing the subject falls back to the default quota
+func (s *QuotaService) RemoveQuota(ctx context.Context, subjectType string, subjectId *int64) error {
+	if !isValidSubjectType(subjectType) {
+		return ErrInvalidQuota
+	}
+	id := int64(dto.QuotaSubjectDefault)
+	if subjectId != nil {
+		id = *subjectId
+	}
+	return db.RemoveQuota(ctx, s.dba, subjectType, id)
+}
+
+func (s *QuotaService) convertToConsumerDtos(ctx context.Context, co db.CommonOperations, subjectType string, consumers []dto.Consumer) ([]dto.ConsumerDto, error) {
+	ids := make([]int64, 0, len(consumers))
+	for _, consumer := range consumers {
+		ids = append(ids, consumer.Id)
+	}
+	quotas, err := db.GetQuotas(ctx, co, subjectType, ids)
+	if err != nil {
+		return nil, err
+	}
+	defaultMaxSize, err := s.getMaxSize(ctx, co, subjectType, dto.QuotaSubjectDefault)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.ConsumerDto, 0, len(consumers))
+	for _, consumer := range consumers {
+		consumerDto := dto.ConsumerDto{
+			Id:         consumer.Id,
+			Used:       consumer.Used,
+			FilesCount: consumer.FilesCount,
+			Max:        defaultMaxSize,
+		}
+		if quota, ok := quotas[consumer.Id]; ok {
+			consumerDto.Max = quota.MaxSize
+		}
+		res = append(res, consumerDto)
+	}
+	return res, nil
+}
+
+// top consumers by user and by chat
+func (s *QuotaService) GetUsageBreakdown(ctx context.Context, size, offset int) (*dto.UsageBreakdownDto, error) {
+	res, err := db.TransactWithResult(ctx, s.dba, func(tx *db.Tx) (*dto.UsageBreakdownDto, error) {
+		byOwner, err := db.GetTopConsumersByOwner(ctx, tx, size, offset)
+		if err != nil {
+			return nil, err
+		}
+		byChat, err := db.GetTopConsumersByChat(ctx, tx, size, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		users, err := s.convertToConsumerDtos(ctx, tx, dto.QuotaSubjectUser, byOwner)
+		if err != nil {
+			return nil, err
+		}
+		chats, err := s.convertToConsumerDtos(ctx, tx, dto.QuotaSubjectChat, byChat)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.UsageBreakdownDto{
+			Users: users,
+			Chats: chats,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Users) > 0 {
+		userIds := make([]int64, 0, len(res.Users))
+		for _, u := range res.Users {
+			userIds = append(userIds, u.Id)
+		}
+		users, err := s.restClient.GetUsers(ctx, userIds)
+		if err != nil {
+			s.lgr.WithTracing(ctx).Warnf("Unable to get users for the usage breakdown: %v", err)
+		} else {
+			for i := range res.Users {
+				for _, user := range users {
+					if user != nil && user.Id == res.Users[i].Id {
+						login := user.Login
+						res.Users[i].Login = &login
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
@@ -18,6 +18,7 @@ func NewRedisInfoService(redisClient *redisV9.Client) *RedisInfoService {
 
 const convertingOriginalPrefix = "converting:original:"
 const convertingConvertedPrefix = "converting:converted:"
+const convertingHlsPrefix = "converting:hls:"
 
 func (s *RedisInfoService) GetOriginalConverting(ctx context.Context, minioKeyOfOriginal string) (bool, error) {
 	value, err := s.redisClient.Get(ctx, convertingOriginalPrefix+minioKeyOfOriginal).Bool()
@@ -62,3 +63,26 @@ func (s *RedisInfoService) SetConvertedConverting(ctx context.Context, minioKeyO
 func (s *RedisInfoService) RemoveConvertedConverting(ctx context.Context, minioKeyOfConverted string) {
 	s.redisClient.Del(ctx, convertingConvertedPrefix+minioKeyOfConverted)
 }
+
+// the progress of the hls converting in percents, false in case the video isn't being converted
+func (s *RedisInfoService) GetHlsConverting(ctx context.Context, minioKeyOfOriginal string) (int, bool, error) {
+	value, err := s.redisClient.Get(ctx, convertingHlsPrefix+minioKeyOfOriginal).Int()
+	if err != nil {
+		if err == redisV9.Nil {
+			return 0, false, nil
+		} else {
+			return 0, false, err
+		}
+	} else {
+		return value, true, nil
+	}
+}
+
+func (s *RedisInfoService) SetHlsConverting(ctx context.Context, minioKeyOfOriginal string, progress int) {
+	maxConvertingDuration := viper.GetDuration("converting.maxDuration")
+	s.redisClient.Set(ctx, convertingHlsPrefix+minioKeyOfOriginal, progress, maxConvertingDuration)
+}
+
+func (s *RedisInfoService) RemoveHlsConverting(ctx context.Context, minioKeyOfOriginal string) {
+	s.redisClient.Del(ctx, convertingHlsPrefix+minioKeyOfOriginal)
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+
+	"nkonev.name/storage/utils"
+)
+
+// the metadata of the images is removed without re-encoding, so the quality isn't lost
+
+var errMalformedImage = errors.New("malformed image")
+
+const jpegMarkerSOI = 0xD8
+const jpegMarkerEOI = 0xD9
+const jpegMarkerSOS = 0xDA
+const jpegMarkerAPP1 = 0xE1  // exif and xmp, they contain gps, the camera, the date
+const jpegMarkerAPP13 = 0xED // photoshop's iptc, it can contain the location
+
+const exifOrientationTag = 0x0112
+const exifOrientationNormal = 1
+
+var exifHeader = []byte("Exif\x00\x00")
+
+type jpegSegment struct {
+	marker  byte
+	start   int // of 0xFF
+	end     int
+	payload []byte
+}
+
+// calls onSegment for each segment before the image data, returns the offset of the image data
+func walkJpegSegments(data []byte, onSegment func(segment jpegSegment) bool) (int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != jpegMarkerSOI {
+		return 0, errMalformedImage
+	}
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return 0, errMalformedImage
+		}
+		start := i
+		// the markers can be padded with 0xFF
+		for i < len(data) && data[i] == 0xFF {
+			i++
+		}
+		if i+3 > len(data) {
+			return 0, errMalformedImage
+		}
+		marker := data[i]
+		if marker == jpegMarkerSOS || marker == jpegMarkerEOI {
+			return start, nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i+1 : i+3]))
+		end := i + 1 + length
+		if length < 2 || end > len(data) {
+			return 0, errMalformedImage
+		}
+		if !onSegment(jpegSegment{marker: marker, start: start, end: end, payload: data[i+3 : end]}) {
+			return end, nil
+		}
+		i = end
+	}
+	return 0, errMalformedImage
+}
+
+// returns exifOrientationNormal in case the orientation is absent
+func readExifOrientation(exif []byte) int {
+	if !bytes.HasPrefix(exif, exifHeader) {
+		return exifOrientationNormal
+	}
+	tiff := exif[len(exifHeader):]
+	if len(tiff) < 8 {
+		return exifOrientationNormal
+	}
+	var order binary.ByteOrder
+	switch string(tiff[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return exifOrientationNormal
+	}
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return exifOrientationNormal
+	}
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for e := 0; e < entries; e++ {
+		entry := ifd + 2 + e*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:entry+2]) == exifOrientationTag {
+			orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return exifOrientationNormal
+			}
+			return orientation
+		}
+	}
+	return exifOrientationNormal
+}
+
+// the reader of the image can be limited, the orientation is in the beginning of the file
+func readJpegOrientation(data []byte) int {
+	orientation := exifOrientationNormal
+	walkJpegSegments(data, func(segment jpegSegment) bool {
+		if segment.marker == jpegMarkerAPP1 && bytes.HasPrefix(segment.payload, exifHeader) {
+			orientation = readExifOrientation(segment.payload)
+			return false
+		}
+		return true
+	})
+	return orientation
+}
+
+// the exif which contains only the orientation, otherwise the photos from the phones are shown rotated
+func minimalExifSegment(orientation int) []byte {
+	tiff := make([]byte, 26)
+	copy(tiff, "MM\x00\x2A")
+	binary.BigEndian.PutUint32(tiff[4:], 8)                    // offset of IFD0
+	binary.BigEndian.PutUint16(tiff[8:], 1)                    // the number of entries
+	binary.BigEndian.PutUint16(tiff[10:], exifOrientationTag)  // tag
+	binary.BigEndian.PutUint16(tiff[12:], 3)                   // SHORT
+	binary.BigEndian.PutUint32(tiff[14:], 1)                   // count
+	binary.BigEndian.PutUint16(tiff[18:], uint16(orientation)) // value
+	binary.BigEndian.PutUint32(tiff[22:], 0)                   // there is no next IFD
+	payload := append(append([]byte{}, exifHeader...), tiff...)
+	segment := []byte{0xFF, jpegMarkerAPP1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// removes exif, xmp and iptc, keeps the orientation, returns false in case there was nothing to remove
+func scrubJpeg(data []byte) ([]byte, bool, error) {
+	var kept [][2]int
+	removed := false
+	orientation := exifOrientationNormal
+	exifPosition := -1
+	imageData, err := walkJpegSegments(data, func(segment jpegSegment) bool {
+		switch segment.marker {
+		case jpegMarkerAPP1, jpegMarkerAPP13:
+			removed = true
+			if segment.marker == jpegMarkerAPP1 && bytes.HasPrefix(segment.payload, exifHeader) {
+				orientation = readExifOrientation(segment.payload)
+				exifPosition = len(kept)
+			}
+		default:
+			kept = append(kept, [2]int{segment.start, segment.end})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if !removed {
+		return data, false, nil
+	}
+
+	var res bytes.Buffer
+	res.Grow(len(data))
+	res.Write(data[0:2])
+	for i, k := range kept {
+		if i == exifPosition && orientation != exifOrientationNormal {
+			res.Write(minimalExifSegment(orientation))
+		}
+		res.Write(data[k[0]:k[1]])
+	}
+	if exifPosition == len(kept) && orientation != exifOrientationNormal {
+		res.Write(minimalExifSegment(orientation))
+	}
+	res.Write(data[imageData:])
+	return res.Bytes(), true, nil
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// the text chunks can contain xmp
+var pngPrivateChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+}
+
+// removes exif and the textual metadata, returns false in case there was nothing to remove
+func scrubPng(data []byte) ([]byte, bool, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, false, errMalformedImage
+	}
+	var res bytes.Buffer
+	res.Grow(len(data))
+	res.Write(pngSignature)
+	removed := false
+	i := len(pngSignature)
+	for i < len(data) {
+		if i+12 > len(data) {
+			return nil, false, errMalformedImage
+		}
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		chunkType := string(data[i+4 : i+8])
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, false, errMalformedImage
+		}
+		// the kept chunks are copied as is, so the corrupted one would be kept corrupted
+		if crc32.ChecksumIEEE(data[i+4:end-4]) != binary.BigEndian.Uint32(data[end-4:end]) {
+			return nil, false, errMalformedImage
+		}
+		if pngPrivateChunks[chunkType] {
+			removed = true
+		} else {
+			res.Write(data[i:end])
+		}
+		i = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	if !removed {
+		return data, false, nil
+	}
+	return res.Bytes(), true, nil
+}
+
+// returns nil in case the image of this type isn't scrubbed
+func getScrubber(normalizedKey string) func(data []byte) ([]byte, bool, error) {
+	switch utils.GetDotExtensionStr(normalizedKey) {
+	case ".jpg", ".jpeg":
+		return scrubJpeg
+	case ".png":
+		return scrubPng
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+)
+
+var jpegSOI = []byte{0xFF, jpegMarkerSOI}
+var jpegImageData = []byte{0xFF, jpegMarkerSOS, 0x00, 0x02, 0x11, 0x22, 0xFF, jpegMarkerEOI}
+
+const jpegMarkerAPP0 = 0xE0
+const jpegMarkerDQT = 0xDB
+
+func jpegSegmentBytes(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// the little endian exif with the orientation and one more tag
+func exifPayload(orientation int) []byte {
+	tiff := make([]byte, 38)
+	copy(tiff, "II\x2A\x00")
+	binary.LittleEndian.PutUint32(tiff[4:], 8)
+	binary.LittleEndian.PutUint16(tiff[8:], 2)
+	// the gps ifd pointer
+	binary.LittleEndian.PutUint16(tiff[10:], 0x8825)
+	binary.LittleEndian.PutUint16(tiff[12:], 4)
+	binary.LittleEndian.PutUint32(tiff[14:], 1)
+	binary.LittleEndian.PutUint32(tiff[18:], 100)
+	binary.LittleEndian.PutUint16(tiff[22:], exifOrientationTag)
+	binary.LittleEndian.PutUint16(tiff[24:], 3)
+	binary.LittleEndian.PutUint32(tiff[26:], 1)
+	binary.LittleEndian.PutUint16(tiff[30:], uint16(orientation))
+	return append(append([]byte{}, exifHeader...), tiff...)
+}
+
+func concat(parts ...[]byte) []byte {
+	var res []byte
+	for _, p := range parts {
+		res = append(res, p...)
+	}
+	return res
+}
+
+func TestScrubJpeg(t *testing.T) {
+	app0 := jpegSegmentBytes(jpegMarkerAPP0, []byte("JFIF\x00\x01\x01"))
+	dqt := jpegSegmentBytes(jpegMarkerDQT, []byte{0x00, 0x01, 0x02})
+	xmp := jpegSegmentBytes(jpegMarkerAPP1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>"))
+	iptc := jpegSegmentBytes(jpegMarkerAPP13, []byte("Photoshop 3.0\x00location"))
+
+	cases := []struct {
+		name        string
+		data        []byte
+		err         bool
+		changed     bool
+		expected    []byte
+		orientation int
+	}{
+		{
+			name: "not a jpeg",
+			data: []byte("GIF89a"),
+			err:  true,
+		},
+		{
+			name: "truncated segment",
+			data: concat(jpegSOI, jpegSegmentBytes(jpegMarkerAPP1, exifPayload(6))[:20]),
+			err:  true,
+		},
+		{
+			name: "segment length beyond the end",
+			data: concat(jpegSOI, []byte{0xFF, jpegMarkerAPP0, 0x10, 0x00, 0x01, 0x02}, jpegImageData),
+			err:  true,
+		},
+		{
+			name: "segment length less than its own size",
+			data: concat(jpegSOI, []byte{0xFF, jpegMarkerAPP0, 0x00, 0x01}, jpegImageData),
+			err:  true,
+		},
+		{
+			name: "garbage instead of marker",
+			data: concat(jpegSOI, []byte{0x00, 0x01, 0x02, 0x03}, jpegImageData),
+			err:  true,
+		},
+		{
+			name: "no image data",
+			data: concat(jpegSOI, app0),
+			err:  true,
+		},
+		{
+			name:        "nothing to remove",
+			data:        concat(jpegSOI, app0, dqt, jpegImageData),
+			changed:     false,
+			expected:    concat(jpegSOI, app0, dqt, jpegImageData),
+			orientation: exifOrientationNormal,
+		},
+		{
+			name:        "exif is replaced with the orientation",
+			data:        concat(jpegSOI, app0, jpegSegmentBytes(jpegMarkerAPP1, exifPayload(6)), dqt, jpegImageData),
+			changed:     true,
+			expected:    concat(jpegSOI, app0, minimalExifSegment(6), dqt, jpegImageData),
+			orientation: 6,
+		},
+		{
+			name:        "exif with the normal orientation is removed",
+			data:        concat(jpegSOI, jpegSegmentBytes(jpegMarkerAPP1, exifPayload(exifOrientationNormal)), app0, jpegImageData),
+			changed:     true,
+			expected:    concat(jpegSOI, app0, jpegImageData),
+			orientation: exifOrientationNormal,
+		},
+		{
+			name: "exif without image data",
+			data: concat(jpegSOI, app0, jpegSegmentBytes(jpegMarkerAPP1, exifPayload(8))),
+			err:  true,
+		},
+		{
+			name:        "exif before image data",
+			data:        concat(jpegSOI, app0, jpegSegmentBytes(jpegMarkerAPP1, exifPayload(8)), jpegImageData),
+			changed:     true,
+			expected:    concat(jpegSOI, app0, minimalExifSegment(8), jpegImageData),
+			orientation: 8,
+		},
+		{
+			name:        "xmp and iptc are removed",
+			data:        concat(jpegSOI, app0, xmp, iptc, dqt, jpegImageData),
+			changed:     true,
+			expected:    concat(jpegSOI, app0, dqt, jpegImageData),
+			orientation: exifOrientationNormal,
+		},
+		{
+			name:        "padded marker",
+			data:        concat(jpegSOI, []byte{0xFF}, iptc, app0, jpegImageData),
+			changed:     true,
+			expected:    concat(jpegSOI, app0, jpegImageData),
+			orientation: exifOrientationNormal,
+		},
+		{
+			name:        "invalid orientation",
+			data:        concat(jpegSOI, jpegSegmentBytes(jpegMarkerAPP1, exifPayload(42)), jpegImageData),
+			changed:     true,
+			expected:    concat(jpegSOI, jpegImageData),
+			orientation: exifOrientationNormal,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res, changed, err := scrubJpeg(c.data)
+			if c.err {
+				if !errors.Is(err, errMalformedImage) {
+					t.Fatalf("Expected malformed image, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != c.changed {
+				t.Errorf("Expected changed=%v, got %v", c.changed, changed)
+			}
+			if !bytes.Equal(res, c.expected) {
+				t.Errorf("Expected\n%x\ngot\n%x", c.expected, res)
+			}
+			if o := readJpegOrientation(res); o != c.orientation {
+				t.Errorf("Expected orientation %v, got %v", c.orientation, o)
+			}
+		})
+	}
+}
+
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], chunkType)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// checks that every chunk has the correct crc
+func validPng(data []byte) bool {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return false
+	}
+	i := len(pngSignature)
+	for i < len(data) {
+		if i+12 > len(data) {
+			return false
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if end > len(data) || crc32.ChecksumIEEE(data[i+4:end-4]) != binary.BigEndian.Uint32(data[end-4:]) {
+			return false
+		}
+		i = end
+	}
+	return true
+}
+
+func TestScrubPng(t *testing.T) {
+	ihdr := pngChunk("IHDR", []byte{0, 0, 0, 1, 0, 0, 0, 1, 8, 6, 0, 0, 0})
+	idat := pngChunk("IDAT", []byte{0x78, 0x9C, 0x62, 0x00, 0x01})
+	iend := pngChunk("IEND", nil)
+	text := pngChunk("tEXt", []byte("Comment\x00secret"))
+	itxt := pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00<x:xmpmeta/>"))
+	exif := pngChunk("eXIf", exifPayload(6)[len(exifHeader):])
+
+	badCrc := pngChunk("IDAT", []byte{0x78, 0x9C, 0x62, 0x00, 0x01})
+	badCrc[len(badCrc)-1] ^= 0xFF
+	badCrcPrivate := append([]byte{}, text...)
+	badCrcPrivate[len(badCrcPrivate)-1] ^= 0xFF
+
+	cases := []struct {
+		name     string
+		data     []byte
+		err      bool
+		changed  bool
+		expected []byte
+	}{
+		{
+			name: "not a png",
+			data: []byte("GIF89a"),
+			err:  true,
+		},
+		{
+			name: "truncated chunk",
+			data: concat(pngSignature, ihdr, idat[:10]),
+			err:  true,
+		},
+		{
+			name: "chunk length beyond the end",
+			data: concat(pngSignature, ihdr, []byte{0x7F, 0, 0, 0}, []byte("IDAT"), []byte{0, 0, 0, 0}),
+			err:  true,
+		},
+		{
+			name: "corrupted kept chunk",
+			data: concat(pngSignature, ihdr, text, badCrc, iend),
+			err:  true,
+		},
+		{
+			name: "corrupted removed chunk",
+			data: concat(pngSignature, ihdr, badCrcPrivate, idat, iend),
+			err:  true,
+		},
+		{
+			name:     "nothing to remove",
+			data:     concat(pngSignature, ihdr, idat, iend),
+			changed:  false,
+			expected: concat(pngSignature, ihdr, idat, iend),
+		},
+		{
+			name:     "exif and text are removed",
+			data:     concat(pngSignature, ihdr, exif, text, idat, itxt, iend),
+			changed:  true,
+			expected: concat(pngSignature, ihdr, idat, iend),
+		},
+		{
+			name:     "the data after the end is dropped",
+			data:     concat(pngSignature, ihdr, text, idat, iend, []byte("trailer")),
+			changed:  true,
+			expected: concat(pngSignature, ihdr, idat, iend),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res, changed, err := scrubPng(c.data)
+			if c.err {
+				if !errors.Is(err, errMalformedImage) {
+					t.Fatalf("Expected malformed image, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != c.changed {
+				t.Errorf("Expected changed=%v, got %v", c.changed, changed)
+			}
+			if !bytes.Equal(res, c.expected) {
+				t.Errorf("Expected\n%x\ngot\n%x", c.expected, res)
+			}
+			if !validPng(res) {
+				t.Error("The result has the invalid chunks")
+			}
+		})
+	}
+}
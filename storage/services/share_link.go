@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	redisV9 "github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
+	"nkonev.name/storage/client"
+	"nkonev.name/storage/db"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/utils"
+)
+
+// bcrypt ignores the bytes after
+const maxShareLinkPasswordLength = 72
+
+var ErrInvalidShareLink = errors.New("invalid share link")
+var ErrShareLinkNotFound = errors.New("share link not found")
+var ErrShareLinkForbidden = errors.New("not allowed to manage share links of the file")
+var ErrShareLinkInfected = errors.New("infected file can't be shared")
+var ErrTooManyShareLinks = errors.New("too many share links of the file")
+var ErrShareLinkPasswordRequired = errors.New("share link password required")
+var ErrShareLinkWrongPassword = errors.New("wrong share link password")
+var ErrShareLinkTooManyAttempts = errors.New("too many wrong passwords of share link")
+
+const shareLinkAttemptsPrefix = "shareLink:attempts:"
+
+// the window starts on the first wrong password, so the link is unblocked after it regardless of the further attempts
+var registerShareLinkAttemptScript = redisV9.NewScript(`
+local attempts = redis.call("incr", KEYS[1])
+if attempts == 1 then
+	redis.call("pexpire", KEYS[1], ARGV[1])
+end
+return attempts
+`)
+
+// the link exists, but it's revoked, expired or exhausted
+type ShareLinkUnavailableError struct {
+	Status string
+}
+
+func (e *ShareLinkUnavailableError) Error() string {
+	return fmt.Sprintf("share link is %v", e.Status)
+}
+
+// the links are created by the owner of the file, the owner and the chat admins can see the statistics and revoke them
+type ShareLinkService struct {
+	restClient  *client.RestClient
+	dba         *db.DB
+	redisClient *redisV9.Client
+	lgr         *logger.Logger
+}
+
+func NewShareLinkService(
+	lgr *logger.Logger,
+	chatClient *client.RestClient,
+	dba *db.DB,
+	redisClient *redisV9.Client,
+) *ShareLinkService {
+	return &ShareLinkService{
+		restClient:  chatClient,
+		dba:         dba,
+		redisClient: redisClient,
+		lgr:         lgr,
+	}
+}
+
+func GetShareLinkUrl(fileId, id string) (string, error) {
+	downloadUrl, err := url.Parse(utils.UrlStorageGetFilePublicExternal)
+	if err != nil {
+		return "", err
+	}
+
+	query := downloadUrl.Query()
+	query.Add(utils.FileParam, fileId)
+	query.Add(utils.ShareLinkParam, id)
+	downloadUrl.RawQuery = query.Encode()
+	return downloadUrl.String(), nil
+}
+
+func getShareLinkFileId(link *dto.ShareLink) string {
+	return GetKey(link.Filename, link.FileItemUuid, link.ChatId)
+}
+
+func convertShareLink(link *dto.ShareLink, now time.Time) (*dto.ShareLinkDto, error) {
+	fileId := getShareLinkFileId(link)
+	linkUrl, err := GetShareLinkUrl(fileId, link.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ShareLinkDto{
+		Id:                   link.Id,
+		FileId:               fileId,
+		Url:                  linkUrl,
+		OwnerId:              link.OwnerId,
+		HasPassword:          link.PasswordHash != nil,
+		ExpiresAt:            link.ExpiresAt,
+		MaxDownloads:         link.MaxDownloads,
+		DownloadCount:        link.DownloadCount,
+		Status:               link.GetStatus(now),
+		CreateDateTime:       link.CreateDateTime,
+		LastDownloadDateTime: link.LastDownloadDateTime,
+	}, nil
+}
+
+func (s *ShareLinkService) canManage(ctx context.Context, userId, ownerId, chatId int64) (bool, error) {
+	if userId == ownerId {
+		return true, nil
+	}
+	return s.restClient.IsAdmin(ctx, userId, chatId)
+}
+
+func (s *ShareLinkService) Create(ctx context.Context, userId int64, req *dto.CreateShareLinkDto) (*dto.ShareLinkDto, error) {
+	now := time.Now().UTC()
+	mcid, err := utils.BuildMetadataCacheId(req.FileId)
+	if err != nil {
+		return nil, ErrInvalidShareLink
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, ErrInvalidShareLink
+	}
+	if req.MaxDownloads != nil && *req.MaxDownloads <= 0 {
+		return nil, ErrInvalidShareLink
+	}
+	var passwordHash *string
+	if req.Password != nil && len(*req.Password) > 0 {
+		if len(*req.Password) > maxShareLinkPasswordLength {
+			return nil, ErrInvalidShareLink
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		hashString := string(hash)
+		passwordHash = &hashString
+	}
+
+	return db.TransactWithResult(ctx, s.dba, func(tx *db.Tx) (*dto.ShareLinkDto, error) {
+		mce, err := db.Get(ctx, tx, *mcid, nil)
+		if err != nil {
+			return nil, err
+		}
+		if mce == nil {
+			return nil, ErrShareLinkNotFound
+		}
+		// the same as for publishing
+		if mce.OwnerId != userId {
+			return nil, ErrShareLinkForbidden
+		}
+		if mce.ScanStatus == dto.ScanStatusInfected {
+			return nil, ErrShareLinkInfected
+		}
+
+		count, err := db.CountShareLinksOfFile(ctx, tx, *mcid)
+		if err != nil {
+			return nil, err
+		}
+		if count >= viper.GetInt64("shareLink.maxPerFile") {
+			return nil, ErrTooManyShareLinks
+		}
+
+		var expiresAt *time.Time
+		if req.ExpiresAt != nil {
+			utc := req.ExpiresAt.UTC()
+			expiresAt = &utc
+		}
+		link := dto.ShareLink{
+			ChatId:         mcid.ChatId,
+			Id:             uuid.New().String(),
+			FileItemUuid:   mcid.FileItemUuid,
+			Filename:       mcid.Filename,
+			OwnerId:        userId,
+			PasswordHash:   passwordHash,
+			ExpiresAt:      expiresAt,
+			MaxDownloads:   req.MaxDownloads,
+			CreateDateTime: now,
+		}
+		err = db.CreateShareLink(ctx, tx, link)
+		if err != nil {
+			return nil, err
+		}
+		return convertShareLink(&link, now)
+	})
+}
+
+// including the revoked, expired and exhausted ones
+func (s *ShareLinkService) List(ctx context.Context, userId int64, fileId string) ([]dto.ShareLinkDto, error) {
+	mcid, err := utils.BuildMetadataCacheId(fileId)
+	if err != nil {
+		return nil, ErrInvalidShareLink
+	}
+	mce, err := db.Get(ctx, s.dba, *mcid, nil)
+	if err != nil {
+		return nil, err
+	}
+	if mce == nil {
+		return nil, ErrShareLinkNotFound
+	}
+	allowed, err := s.canManage(ctx, userId, mce.OwnerId, mcid.ChatId)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrShareLinkForbidden
+	}
+
+	links, err := db.GetShareLinksOfFile(ctx, s.dba, *mcid)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	res := make([]dto.ShareLinkDto, 0, len(links))
+	for _, link := range links {
+		converted, err := convertShareLink(&link, now)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *converted)
+	}
+	return res, nil
+}
+
+func (s *ShareLinkService) getManageable(ctx context.Context, userId, chatId int64, id string) (*dto.ShareLink, error) {
+	link, err := db.GetShareLink(ctx, s.dba, chatId, id)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, ErrShareLinkNotFound
+	}
+	allowed, err := s.canManage(ctx, userId, link.OwnerId, chatId)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrShareLinkForbidden
+	}
+	return link, nil
+}
+
+// the revoked link is kept for the statistics
+func (s *ShareLinkService) Revoke(ctx context.Context, userId, chatId int64, id string) error {
+	_, err := s.getManageable(ctx, userId, chatId, id)
+	if err != nil {
+		return err
+	}
+	return db.RevokeShareLink(ctx, s.dba, chatId, id)
+}
+
+func (s *ShareLinkService) GetStats(ctx context.Context, userId, chatId int64, id string) (*dto.ShareLinkStatsDto, error) {
+	link, err := s.getManageable(ctx, userId, chatId, id)
+	if err != nil {
+		return nil, err
+	}
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -viper.GetInt("shareLink.statsDays"))
+	daily, err := db.GetShareLinkDailyDownloads(ctx, s.dba, chatId, id, since)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ShareLinkStatsDto{
+		Id:                   link.Id,
+		DownloadCount:        link.DownloadCount,
+		LastDownloadDateTime: link.LastDownloadDateTime,
+		Daily:                daily,
+	}, nil
+}
+
+// checks the link of the anonymous user is usable for the file, the download isn't counted here
+// counted is false for the continuation of the download, e.g. the range request, it's allowed for the exhausted link
+func (s *ShareLinkService) Check(ctx context.Context, fileId, id, password string, counted bool) (*dto.ShareLink, error) {
+	mcid, err := utils.BuildMetadataCacheId(fileId)
+	if err != nil {
+		return nil, ErrInvalidShareLink
+	}
+	link, err := db.GetShareLink(ctx, s.dba, mcid.ChatId, id)
+	if err != nil {
+		return nil, err
+	}
+	// the link is bound to the particular file
+	if link == nil || link.FileItemUuid != mcid.FileItemUuid || link.Filename != mcid.Filename {
+		return nil, ErrShareLinkNotFound
+	}
+	err = checkShareLinkStatus(link, time.Now().UTC(), counted)
+	if err != nil {
+		return nil, err
+	}
+	err = s.checkPassword(ctx, link, password)
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func checkShareLinkStatus(link *dto.ShareLink, now time.Time, counted bool) error {
+	status := link.GetStatus(now)
+	if status == dto.ShareLinkStatusActive || (status == dto.ShareLinkStatusExhausted && !counted) {
+		return nil
+	}
+	return &ShareLinkUnavailableError{Status: status}
+}
+
+// the wrong passwords are counted per link rather than per client, so the guessing from the many addresses is limited too
+func (s *ShareLinkService) checkPassword(ctx context.Context, link *dto.ShareLink, password string) error {
+	if link.PasswordHash == nil {
+		return nil
+	}
+	if len(password) == 0 {
+		return ErrShareLinkPasswordRequired
+	}
+	attemptsKey := shareLinkAttemptsPrefix + link.Id
+	attempts, err := s.redisClient.Get(ctx, attemptsKey).Int()
+	if err != nil && !errors.Is(err, redisV9.Nil) {
+		return err
+	}
+	if attempts >= viper.GetInt("shareLink.password.maxAttempts") {
+		return ErrShareLinkTooManyAttempts
+	}
+	if bcrypt.CompareHashAndPassword([]byte(*link.PasswordHash), []byte(password)) != nil {
+		err = registerShareLinkAttemptScript.Run(ctx, s.redisClient, []string{attemptsKey}, viper.GetDuration("shareLink.password.attemptsWindow").Milliseconds()).Err()
+		if err != nil {
+			return err
+		}
+		return ErrShareLinkWrongPassword
+	}
+	return nil
+}
+
+func (s *ShareLinkService) RecordDownload(ctx context.Context, link *dto.ShareLink) error {
+	return db.Transact(ctx, s.dba, func(tx *db.Tx) error {
+		recorded, err := db.RecordShareLinkDownload(ctx, tx, link.ChatId, link.Id)
+		if err != nil {
+			return err
+		}
+		if recorded {
+			return nil
+		}
+		// the link has been changed by the concurrent request or it has just expired
+		actual, err := db.GetShareLink(ctx, tx, link.ChatId, link.Id)
+		if err != nil {
+			return err
+		}
+		if actual == nil {
+			return ErrShareLinkNotFound
+		}
+		status := actual.GetStatus(time.Now().UTC())
+		if status == dto.ShareLinkStatusActive {
+			// the clocks of the app and the db differ a bit
+			status = dto.ShareLinkStatusExpired
+		}
+		return &ShareLinkUnavailableError{Status: status}
+	})
+}
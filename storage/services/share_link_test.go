@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	redisV9 "github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+)
+
+func newTestShareLinkService(t *testing.T) (*ShareLinkService, *miniredis.Miniredis) {
+	viper.Set("shareLink.password.maxAttempts", 3)
+	viper.Set("shareLink.password.attemptsWindow", time.Minute)
+
+	mr := miniredis.RunT(t)
+	redisClient := redisV9.NewClient(&redisV9.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		redisClient.Close()
+	})
+	return NewShareLinkService(logger.NewLogger(), nil, nil, redisClient), mr
+}
+
+func newTestProtectedLink(t *testing.T, id, password string) *dto.ShareLink {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashString := string(hash)
+	return &dto.ShareLink{Id: id, PasswordHash: &hashString}
+}
+
+func TestShareLinkPassword(t *testing.T) {
+	ctx := context.Background()
+	s, mr := newTestShareLinkService(t)
+	link := newTestProtectedLink(t, "link", "secret")
+
+	if err := s.checkPassword(ctx, link, ""); !errors.Is(err, ErrShareLinkPasswordRequired) {
+		t.Errorf("expected the password is required, got %v", err)
+	}
+	if err := s.checkPassword(ctx, link, "secret"); err != nil {
+		t.Errorf("the right password should pass, got %v", err)
+	}
+	if err := s.checkPassword(ctx, &dto.ShareLink{Id: "open"}, ""); err != nil {
+		t.Errorf("the link without the password should pass, got %v", err)
+	}
+	if len(mr.Keys()) != 0 {
+		t.Errorf("only the wrong passwords should be counted, got %v", mr.Keys())
+	}
+}
+
+func TestShareLinkPasswordAttemptsAreLimited(t *testing.T) {
+	ctx := context.Background()
+	s, mr := newTestShareLinkService(t)
+	link := newTestProtectedLink(t, "link", "secret")
+	other := newTestProtectedLink(t, "other", "secret")
+
+	for i := 0; i < 3; i++ {
+		if err := s.checkPassword(ctx, link, "guess"); !errors.Is(err, ErrShareLinkWrongPassword) {
+			t.Fatalf("attempt %v: expected the wrong password, got %v", i, err)
+		}
+	}
+	// even the right password isn't checked till the window is over
+	if err := s.checkPassword(ctx, link, "secret"); !errors.Is(err, ErrShareLinkTooManyAttempts) {
+		t.Errorf("expected too many attempts, got %v", err)
+	}
+	if err := s.checkPassword(ctx, other, "secret"); err != nil {
+		t.Errorf("another link shouldn't be blocked, got %v", err)
+	}
+
+	// the window starts on the first wrong password and isn't prolonged by the later ones
+	if ttl := mr.TTL(shareLinkAttemptsPrefix + link.Id); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("the attempts should expire in the window, got %v", ttl)
+	}
+	mr.FastForward(time.Minute)
+	if err := s.checkPassword(ctx, link, "secret"); err != nil {
+		t.Errorf("the link should be unblocked after the window, got %v", err)
+	}
+}
+
+func TestShareLinkStatusForNotCountedDownload(t *testing.T) {
+	now := time.Now().UTC()
+	maxDownloads := int64(1)
+	past := now.Add(-time.Minute)
+
+	exhausted := &dto.ShareLink{Id: "exhausted", MaxDownloads: &maxDownloads, DownloadCount: 1}
+	if err := checkShareLinkStatus(exhausted, now, true); err == nil {
+		t.Errorf("the new download of the exhausted link should be refused")
+	}
+	if err := checkShareLinkStatus(exhausted, now, false); err != nil {
+		t.Errorf("the exhausted link should be resumed, got %v", err)
+	}
+
+	expired := &dto.ShareLink{Id: "expired", ExpiresAt: &past}
+	var unavailableError *ShareLinkUnavailableError
+	if err := checkShareLinkStatus(expired, now, false); !errors.As(err, &unavailableError) || unavailableError.Status != dto.ShareLinkStatusExpired {
+		t.Errorf("the expired link shouldn't be resumed, got %v", err)
+	}
+	revoked := &dto.ShareLink{Id: "revoked", Revoked: true}
+	if err := checkShareLinkStatus(revoked, now, false); !errors.As(err, &unavailableError) || unavailableError.Status != dto.ShareLinkStatusRevoked {
+		t.Errorf("the revoked link shouldn't be resumed, got %v", err)
+	}
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	redisV9 "github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/utils"
+)
+
+const tusUploadPrefix = "tus:upload:"
+const tusLockPrefix = "tus:lock:"
+
+// s3 requires all the parts except the last to be at least 5 MiB
+const minTusPartSize = 5 * 1024 * 1024
+
+var ErrTusUploadNotFound = errors.New("upload not found")
+var ErrTusOffsetMismatch = errors.New("offset mismatch")
+var ErrTusLocked = errors.New("upload is locked by another request")
+var ErrTusLockLost = errors.New("lock of the upload is lost")
+var errTusTailLost = errors.New("unfinished part of the upload is lost")
+
+// the lock is removed and prolonged only by its owner
+var releaseTusLockScript = redisV9.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+var extendTusLockScript = redisV9.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+type TusPart struct {
+	PartNumber int64  `json:"partNumber"`
+	ETag       string `json:"etag"`
+}
+
+// the state of the resumable upload. the bytes which aren't enough for the next s3 part are kept as the tail object,
+// so the offset is moved by every received byte, as tus requires for the clients which send the upload by the small chunks
+type TusUpload struct {
+	Id           string    `json:"id"`
+	ChatId       int64     `json:"chatId"`
+	OwnerId      int64     `json:"ownerId"`
+	Key          string    `json:"key"`
+	FileItemUuid string    `json:"fileItemUuid"`
+	UploadId     string    `json:"uploadId"` // of the s3 multipart upload
+	Length       int64     `json:"length"`
+	Offset       int64     `json:"offset"`
+	PartSize     int64     `json:"partSize"`
+	Parts        []TusPart `json:"parts"`
+	TailSize     int64     `json:"tailSize"` // the end of the offset
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+func (u *TusUpload) IsComplete() bool {
+	return u.Offset == u.Length
+}
+
+// maps the tus offsets onto the s3 multipart parts, the state is kept in redis, so the upload survives the page reload and the restart
+type TusService struct {
+	redisClient *redisV9.Client
+	minio       backend.Backend
+	minioConfig *utils.MinioConfig
+	lgr         *logger.Logger
+}
+
+func NewTusService(lgr *logger.Logger, redisClient *redisV9.Client, minio backend.Backend, minioConfig *utils.MinioConfig) *TusService {
+	return &TusService{
+		redisClient: redisClient,
+		minio:       minio,
+		minioConfig: minioConfig,
+		lgr:         lgr,
+	}
+}
+
+func getTusPartSize() int64 {
+	return max(viper.GetInt64("minio.multipart.chunkSize"), minTusPartSize)
+}
+
+func getTusLockTtl() time.Duration {
+	return viper.GetDuration("minio.multipart.tusLockTtl")
+}
+
+func (s *TusService) Create(ctx context.Context, chatId, ownerId int64, key, fileItemUuid string, length int64, metadata map[string]string) (*TusUpload, error) {
+	bucketName := s.minioConfig.Files
+	expTime := time.Now().UTC().Add(viper.GetDuration("minio.multipart.expire"))
+	uploadId, err := s.minio.CreateMultipartUpload(ctx, bucketName, key, metadata, expTime)
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &TusUpload{
+		Id:           uuid.New().String(),
+		ChatId:       chatId,
+		OwnerId:      ownerId,
+		Key:          key,
+		FileItemUuid: fileItemUuid,
+		UploadId:     uploadId,
+		Length:       length,
+		PartSize:     getTusPartSize(),
+		Parts:        []TusPart{},
+		ExpiresAt:    expTime,
+	}
+	err = s.save(ctx, upload)
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// returns nil in case there is no such upload
+func (s *TusService) Get(ctx context.Context, id string) (*TusUpload, error) {
+	data, err := s.redisClient.Get(ctx, tusUploadPrefix+id).Bytes()
+	if err == redisV9.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	upload := new(TusUpload)
+	err = json.Unmarshal(data, upload)
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+func (s *TusService) save(ctx context.Context, upload *TusUpload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	return s.redisClient.Set(ctx, tusUploadPrefix+upload.Id, data, time.Until(upload.ExpiresAt)).Err()
+}
+
+func (s *TusService) remove(ctx context.Context, id string) error {
+	err := s.minio.RemoveObject(ctx, s.minioConfig.FilesTus, GetTusTailPrefix(id), minio.RemoveObjectOptions{ForceDelete: true})
+	if err != nil {
+		return err
+	}
+	return s.redisClient.Del(ctx, tusUploadPrefix+id).Err()
+}
+
+func GetTusTailPrefix(id string) string {
+	return id + "/"
+}
+
+// the offset is in the key, so the saved state refers to its tail even if the new tail was written, but the state wasn't saved
+func getTusTailKey(id string, offset int64) string {
+	return fmt.Sprintf("%v%v", GetTusTailPrefix(id), offset)
+}
+
+func (s *TusService) getTail(ctx context.Context, upload *TusUpload) ([]byte, error) {
+	if upload.TailSize == 0 {
+		return nil, nil
+	}
+	tail, err := s.readTail(ctx, upload)
+	var errResponse minio.ErrorResponse
+	if errors.As(err, &errResponse) && errResponse.Code == "NoSuchKey" {
+		return nil, errTusTailLost
+	} else if err != nil {
+		return nil, err
+	}
+	if int64(len(tail)) != upload.TailSize {
+		return nil, errTusTailLost
+	}
+	return tail, nil
+}
+
+// minio returns the absence on the reading, the local backend does on the opening
+func (s *TusService) readTail(ctx context.Context, upload *TusUpload) ([]byte, error) {
+	object, err := s.minio.GetObject(ctx, s.minioConfig.FilesTus, getTusTailKey(upload.Id, upload.Offset), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	return io.ReadAll(object)
+}
+
+func (s *TusService) putTail(ctx context.Context, upload *TusUpload, offset int64, tail []byte) error {
+	_, err := s.minio.PutObject(ctx, s.minioConfig.FilesTus, getTusTailKey(upload.Id, offset), bytes.NewReader(tail), int64(len(tail)), minio.PutObjectOptions{ContentType: "application/octet-stream"})
+	return err
+}
+
+// the previous tail isn't needed after the state refers to the new one
+func (s *TusService) removeTail(ctx context.Context, id string, offset int64) {
+	err := s.minio.RemoveObject(ctx, s.minioConfig.FilesTus, getTusTailKey(id, offset), minio.RemoveObjectOptions{})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Warnf("Unable to remove the tail %v of upload %v: %v", offset, id, err)
+	}
+}
+
+// appends the body starting from the offset, the received bytes are kept even if the body is interrupted
+func (s *TusService) Write(ctx context.Context, id string, offset int64, body io.Reader) (*TusUpload, error) {
+	// the received bytes should be saved even when the client has gone
+	ctx = context.WithoutCancel(ctx)
+
+	lock, err := s.lock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release(ctx)
+
+	upload, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload == nil {
+		return nil, ErrTusUploadNotFound
+	}
+	if offset != upload.Offset {
+		return upload, ErrTusOffsetMismatch
+	}
+
+	tail, err := s.getTail(ctx, upload)
+	if errors.Is(err, errTusTailLost) {
+		// the client gets the end of the uploaded parts on HEAD and sends the rest again
+		s.lgr.WithTracing(ctx).Warnf("The tail of upload %v is lost, moving the offset back to %v", upload.Id, upload.Offset-upload.TailSize)
+		err = s.moveOffset(ctx, upload, upload.Offset-upload.TailSize, 0)
+		if err != nil {
+			return nil, err
+		}
+		return upload, ErrTusOffsetMismatch
+	} else if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(tail)
+	received := upload.Offset
+	var readErr error
+	for received < upload.Length {
+		n, err := io.CopyN(buf, body, min(upload.PartSize-int64(buf.Len()), upload.Length-received))
+		received += n
+		if int64(buf.Len()) == upload.PartSize && received < upload.Length {
+			// another request has taken over the upload, so its state can't be changed
+			if lock.isLost() {
+				return nil, ErrTusLockLost
+			}
+			err := s.uploadPart(ctx, upload, buf.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			buf.Reset()
+			err = s.moveOffset(ctx, upload, received, 0)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+	}
+
+	if readErr != nil && !errors.Is(readErr, io.EOF) {
+		s.lgr.WithTracing(ctx).Infof("The body of upload %v was interrupted at %v: %v", upload.Id, received, readErr)
+	}
+
+	if received == upload.Length {
+		if lock.isLost() {
+			return nil, ErrTusLockLost
+		}
+		if buf.Len() > 0 || len(upload.Parts) == 0 {
+			err = s.uploadPart(ctx, upload, buf.Bytes())
+			if err != nil {
+				return nil, err
+			}
+		}
+		upload.Offset = received
+		err = s.complete(ctx, upload)
+		if err != nil {
+			return nil, err
+		}
+		return upload, nil
+	}
+
+	if received > upload.Offset {
+		if lock.isLost() {
+			return nil, ErrTusLockLost
+		}
+		err = s.putTail(ctx, upload, received, buf.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		err = s.moveOffset(ctx, upload, received, int64(buf.Len()))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return upload, nil
+}
+
+func (s *TusService) moveOffset(ctx context.Context, upload *TusUpload, offset, tailSize int64) error {
+	previousOffset, previousTailSize := upload.Offset, upload.TailSize
+	upload.Offset = offset
+	upload.TailSize = tailSize
+	err := s.save(ctx, upload)
+	if err != nil {
+		return err
+	}
+	if previousTailSize > 0 {
+		s.removeTail(ctx, upload.Id, previousOffset)
+	}
+	return nil
+}
+
+func (s *TusService) uploadPart(ctx context.Context, upload *TusUpload, data []byte) error {
+	bucketName := s.minioConfig.Files
+	partNumber := int64(len(upload.Parts) + 1)
+	etag, err := s.minio.UploadPart(ctx, bucketName, upload.Key, upload.UploadId, partNumber, data)
+	if err != nil {
+		return err
+	}
+	upload.Parts = append(upload.Parts, TusPart{PartNumber: partNumber, ETag: etag})
+	return nil
+}
+
+// the completion causes the usual minio events, so the file is processed as any other uploaded file
+func (s *TusService) complete(ctx context.Context, upload *TusUpload) error {
+	bucketName := s.minioConfig.Files
+	arr := []backend.CompletedPart{}
+	for _, part := range upload.Parts {
+		arr = append(arr, backend.CompletedPart{
+			ETag:       part.ETag,
+			PartNumber: part.PartNumber,
+		})
+	}
+	err := s.minio.CompleteMultipartUpload(ctx, bucketName, upload.Key, upload.UploadId, arr)
+	if err != nil {
+		return err
+	}
+	return s.remove(ctx, upload.Id)
+}
+
+func (s *TusService) Terminate(ctx context.Context, upload *TusUpload) error {
+	bucketName := s.minioConfig.Files
+	err := s.minio.AbortMultipartUpload(ctx, bucketName, upload.Key, upload.UploadId)
+	if err != nil {
+		return err
+	}
+	return s.remove(ctx, upload.Id)
+}
+
+// the lock is owned by the request which has taken it, so the request which has lost it after the long pause can't remove the lock of another one.
+// it is prolonged while the body is being received
+type tusLock struct {
+	redisClient *redisV9.Client
+	key         string
+	token       string
+	lost        atomic.Bool
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+func (s *TusService) lock(ctx context.Context, id string) (*tusLock, error) {
+	lock := &tusLock{
+		redisClient: s.redisClient,
+		key:         tusLockPrefix + id,
+		token:       uuid.New().String(),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	locked, err := s.redisClient.SetNX(ctx, lock.key, lock.token, getTusLockTtl()).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, ErrTusLocked
+	}
+	go lock.prolong(ctx, s.lgr)
+	return lock, nil
+}
+
+func (l *tusLock) prolong(ctx context.Context, lgr *logger.Logger) {
+	defer close(l.done)
+	ttl := getTusLockTtl()
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			res, err := extendTusLockScript.Run(ctx, l.redisClient, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+			if err != nil {
+				// the next attempt can succeed before the expiration
+				lgr.WithTracing(ctx).Warnf("Unable to prolong the lock %v: %v", l.key, err)
+				continue
+			}
+			if res == 0 {
+				l.lost.Store(true)
+				return
+			}
+		}
+	}
+}
+
+func (l *tusLock) isLost() bool {
+	return l.lost.Load()
+}
+
+func (l *tusLock) release(ctx context.Context) {
+	close(l.stop)
+	<-l.done
+	releaseTusLockScript.Run(ctx, l.redisClient, []string{l.key}, l.token)
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/minio/minio-go/v7"
+	redisV9 "github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/utils"
+)
+
+// keeps the parts of the only multipart upload in memory
+type fakeMultipartBackend struct {
+	backend.Backend
+	mu        sync.Mutex
+	parts     map[int64][]byte
+	completed []byte
+}
+
+func (b *fakeMultipartBackend) CreateMultipartUpload(ctx context.Context, bucketName, key string, metadata map[string]string, expires time.Time) (string, error) {
+	return "upload-id", nil
+}
+
+func (b *fakeMultipartBackend) UploadPart(ctx context.Context, bucketName, key, uploadId string, partNumber int64, data []byte) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.parts[partNumber] = bytes.Clone(data)
+	return fmt.Sprintf("etag-%v", partNumber), nil
+}
+
+func (b *fakeMultipartBackend) CompleteMultipartUpload(ctx context.Context, bucketName, key, uploadId string, parts []backend.CompletedPart) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, part := range parts {
+		if part.ETag != fmt.Sprintf("etag-%v", part.PartNumber) {
+			return errors.New("wrong etag")
+		}
+		b.completed = append(b.completed, b.parts[part.PartNumber]...)
+	}
+	return nil
+}
+
+func (b *fakeMultipartBackend) partSizes() []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	numbers := []int64{}
+	for number := range b.parts {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	res := []int{}
+	for _, number := range numbers {
+		res = append(res, len(b.parts[number]))
+	}
+	return res
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+func newTestTusService(t *testing.T, lockTtl time.Duration) (*TusService, *fakeMultipartBackend, *miniredis.Miniredis) {
+	viper.Set("minio.multipart.chunkSize", 0)
+	viper.Set("minio.multipart.expire", time.Hour)
+	viper.Set("minio.multipart.tusLockTtl", lockTtl)
+
+	mr := miniredis.RunT(t)
+	redisClient := redisV9.NewClient(&redisV9.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		redisClient.Close()
+	})
+	lgr := logger.NewLogger()
+	// the tails are the usual objects
+	local, err := backend.NewLocalBackend(lgr, t.TempDir(), "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := local.MakeBucket(context.Background(), testTusConfig.FilesTus, minio.MakeBucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeMultipartBackend{Backend: local, parts: map[int64][]byte{}}
+	return NewTusService(lgr, redisClient, fake, testTusConfig), fake, mr
+}
+
+var testTusConfig = &utils.MinioConfig{Files: "files", FilesTus: "files-tus"}
+
+func tusTails(t *testing.T, b backend.Backend, id string) []string {
+	keys := []string{}
+	for objInfo := range b.ListObjects(context.Background(), testTusConfig.FilesTus, minio.ListObjectsOptions{Prefix: GetTusTailPrefix(id), Recursive: true}) {
+		if objInfo.Err != nil {
+			t.Fatal(objInfo.Err)
+		}
+		keys = append(keys, objInfo.Key)
+	}
+	return keys
+}
+
+func testData(length int) []byte {
+	data := make([]byte, length)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition isn't met")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTusWriteResumesFromTheReceivedBytes(t *testing.T) {
+	ctx := context.Background()
+	s, fake, mr := newTestTusService(t, time.Minute)
+	partSize := int(getTusPartSize())
+	data := testData(2*partSize + 100)
+
+	upload, err := s.Create(ctx, 1, 2, "chat/1/file.bin", "uuid", int64(len(data)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the body is interrupted in the middle of the second part
+	interruptedAt := partSize + 100
+	upload, err = s.Write(ctx, upload.Id, 0, io.MultiReader(bytes.NewReader(data[:interruptedAt]), failingReader{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.Offset != int64(interruptedAt) {
+		t.Errorf("the offset should be at the last received byte, got %v", upload.Offset)
+	}
+	if len(mr.Keys()) != 1 || mr.Exists(tusLockPrefix+upload.Id) {
+		t.Errorf("only the upload state should be kept in redis, got %v", mr.Keys())
+	}
+	if tails := tusTails(t, fake, upload.Id); fmt.Sprint(tails) != fmt.Sprint([]string{getTusTailKey(upload.Id, int64(interruptedAt))}) {
+		t.Errorf("the unfinished part should be kept as the tail, got %v", tails)
+	}
+
+	stored, err := s.Get(ctx, upload.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Offset != int64(interruptedAt) || stored.TailSize != 100 || len(stored.Parts) != 1 {
+		t.Errorf("unexpected saved state %+v", stored)
+	}
+
+	_, err = s.Write(ctx, upload.Id, int64(partSize), bytes.NewReader(data[partSize:]))
+	if !errors.Is(err, ErrTusOffsetMismatch) {
+		t.Errorf("expected the offset mismatch, got %v", err)
+	}
+
+	upload, err = s.Write(ctx, upload.Id, int64(interruptedAt), bytes.NewReader(data[interruptedAt:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !upload.IsComplete() {
+		t.Errorf("the upload should be complete, got the offset %v", upload.Offset)
+	}
+	if !bytes.Equal(fake.completed, data) {
+		t.Error("the completed object differs from the sent data")
+	}
+	if sizes := fake.partSizes(); fmt.Sprint(sizes) != fmt.Sprint([]int{partSize, partSize, 100}) {
+		t.Errorf("unexpected part sizes %v", sizes)
+	}
+	if len(mr.Keys()) != 0 {
+		t.Errorf("the completed upload should be removed from redis, got %v", mr.Keys())
+	}
+	if tails := tusTails(t, fake, upload.Id); len(tails) != 0 {
+		t.Errorf("the tails should be removed, got %v", tails)
+	}
+}
+
+// e.g. tus-js-client with the chunkSize less than the part
+func TestTusWriteBySmallChunks(t *testing.T) {
+	ctx := context.Background()
+	s, fake, _ := newTestTusService(t, time.Minute)
+	partSize := int(getTusPartSize())
+	chunkSize := 1024 * 1024
+	data := testData(partSize + 3*chunkSize/2)
+
+	upload, err := s.Create(ctx, 1, 2, "chat/1/file.bin", "uuid", int64(len(data)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for offset := 0; offset < len(data); offset += chunkSize {
+		end := min(offset+chunkSize, len(data))
+		upload, err = s.Write(ctx, upload.Id, int64(offset), bytes.NewReader(data[offset:end]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if upload.Offset != int64(end) {
+			t.Fatalf("the offset should be moved by the chunk to %v, got %v", end, upload.Offset)
+		}
+		if !upload.IsComplete() && len(tusTails(t, fake, upload.Id)) > 1 {
+			t.Errorf("only the last tail should be kept, got %v", tusTails(t, fake, upload.Id))
+		}
+	}
+	if !upload.IsComplete() || !bytes.Equal(fake.completed, data) {
+		t.Error("the completed object differs from the sent data")
+	}
+	if sizes := fake.partSizes(); fmt.Sprint(sizes) != fmt.Sprint([]int{partSize, len(data) - partSize}) {
+		t.Errorf("unexpected part sizes %v", sizes)
+	}
+}
+
+func TestTusWriteWithLostTail(t *testing.T) {
+	ctx := context.Background()
+	s, fake, _ := newTestTusService(t, time.Minute)
+	partSize := int(getTusPartSize())
+	data := testData(partSize + 200)
+
+	upload, err := s.Create(ctx, 1, 2, "chat/1/file.bin", "uuid", int64(len(data)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upload, err = s.Write(ctx, upload.Id, 0, bytes.NewReader(data[:partSize+100]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fake.RemoveObject(ctx, testTusConfig.FilesTus, getTusTailKey(upload.Id, upload.Offset), minio.RemoveObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the client should send the bytes after the uploaded parts again
+	upload, err = s.Write(ctx, upload.Id, int64(partSize+100), bytes.NewReader(data[partSize+100:]))
+	if !errors.Is(err, ErrTusOffsetMismatch) {
+		t.Fatalf("expected the offset mismatch, got %v", err)
+	}
+	if upload.Offset != int64(partSize) || upload.TailSize != 0 {
+		t.Errorf("the offset should be moved back to the end of the parts, got %+v", upload)
+	}
+	upload, err = s.Write(ctx, upload.Id, int64(partSize), bytes.NewReader(data[partSize:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !upload.IsComplete() || !bytes.Equal(fake.completed, data) {
+		t.Error("the completed object differs from the sent data")
+	}
+}
+
+func TestTusWriteEmptyUpload(t *testing.T) {
+	ctx := context.Background()
+	s, fake, _ := newTestTusService(t, time.Minute)
+
+	upload, err := s.Create(ctx, 1, 2, "chat/1/empty.txt", "uuid", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upload, err = s.Write(ctx, upload.Id, 0, bytes.NewReader(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !upload.IsComplete() || len(fake.completed) != 0 || fmt.Sprint(fake.partSizes()) != "[0]" {
+		t.Errorf("the empty upload should be completed with the only empty part, got %+v", upload)
+	}
+}
+
+func TestTusWriteIsLocked(t *testing.T) {
+	ctx := context.Background()
+	s, _, mr := newTestTusService(t, time.Minute)
+
+	upload, err := s.Create(ctx, 1, 2, "chat/1/file.bin", "uuid", 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr.Set(tusLockPrefix+upload.Id, "another")
+
+	_, err = s.Write(ctx, upload.Id, 0, bytes.NewReader(testData(10)))
+	if !errors.Is(err, ErrTusLocked) {
+		t.Errorf("expected the lock error, got %v", err)
+	}
+	if value, _ := mr.Get(tusLockPrefix + upload.Id); value != "another" {
+		t.Errorf("the lock of another request should be kept, got %v", value)
+	}
+}
+
+func TestTusLockIsReleasedOnlyByOwner(t *testing.T) {
+	ctx := context.Background()
+	s, _, mr := newTestTusService(t, time.Minute)
+
+	lock, err := s.lock(ctx, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.lock(ctx, "id")
+	if !errors.Is(err, ErrTusLocked) {
+		t.Errorf("expected the lock error, got %v", err)
+	}
+
+	// the lock has expired and is taken by another request
+	mr.Set(tusLockPrefix+"id", "another")
+	lock.release(ctx)
+	if value, _ := mr.Get(tusLockPrefix + "id"); value != "another" {
+		t.Errorf("the lock of another request should be kept, got %v", value)
+	}
+
+	mr.Del(tusLockPrefix + "id")
+	lock, err = s.lock(ctx, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.release(ctx)
+	if mr.Exists(tusLockPrefix + "id") {
+		t.Error("the own lock should be removed")
+	}
+}
+
+func TestTusLockIsProlongedWhileWriting(t *testing.T) {
+	ctx := context.Background()
+	lockTtl := 300 * time.Millisecond
+	s, _, mr := newTestTusService(t, lockTtl)
+
+	upload, err := s.Create(ctx, 1, 2, "chat/1/file.bin", "uuid", 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Write(ctx, upload.Id, 0, pr)
+		done <- err
+	}()
+
+	lockKey := tusLockPrefix + upload.Id
+	waitFor(t, func() bool { return mr.Exists(lockKey) })
+	mr.FastForward(lockTtl - 50*time.Millisecond)
+	waitFor(t, func() bool { return mr.TTL(lockKey) > lockTtl-50*time.Millisecond })
+
+	pw.Write(testData(10))
+	pw.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists(lockKey) {
+		t.Error("the lock should be released")
+	}
+}
+
+func TestTusWriteStopsWhenLockIsLost(t *testing.T) {
+	ctx := context.Background()
+	lockTtl := 150 * time.Millisecond
+	s, fake, mr := newTestTusService(t, lockTtl)
+	partSize := int(getTusPartSize())
+
+	upload, err := s.Create(ctx, 1, 2, "chat/1/file.bin", "uuid", int64(2*partSize), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Write(ctx, upload.Id, 0, pr)
+		done <- err
+	}()
+
+	lockKey := tusLockPrefix + upload.Id
+	waitFor(t, func() bool { return mr.Exists(lockKey) })
+	// the lock has expired during the pause and is taken by another request
+	mr.Set(lockKey, "another")
+	time.Sleep(lockTtl)
+
+	go pw.Write(testData(partSize))
+	if err := <-done; !errors.Is(err, ErrTusLockLost) {
+		t.Errorf("expected the lost lock, got %v", err)
+	}
+	pw.Close()
+
+	if len(fake.partSizes()) != 0 {
+		t.Error("the part shouldn't be uploaded without the lock")
+	}
+	stored, err := s.Get(ctx, upload.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Offset != 0 {
+		t.Errorf("the state shouldn't be changed without the lock, got the offset %v", stored.Offset)
+	}
+	if value, _ := mr.Get(lockKey); value != "another" {
+		t.Errorf("the lock of another request should be kept, got %v", value)
+	}
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/client"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/utils"
+)
+
+var ErrVersionNotFound = errors.New("version not found")
+
+// the previous versions of a file are stored in the separate bucket under the "<key of file>/<version id>" keys,
+// the version id is the unix milli time of the replacing
+type VersionService struct {
+	minio       backend.Backend
+	minioConfig *utils.MinioConfig
+	restClient  *client.RestClient
+	lgr         *logger.Logger
+}
+
+func NewVersionService(lgr *logger.Logger, minio backend.Backend, minioConfig *utils.MinioConfig, restClient *client.RestClient) *VersionService {
+	return &VersionService{
+		minio:       minio,
+		minioConfig: minioConfig,
+		restClient:  restClient,
+		lgr:         lgr,
+	}
+}
+
+func getMaxVersions() int {
+	return viper.GetInt("versions.maxCount")
+}
+
+func GetVersionsPrefix(normalizedKey string) string {
+	return normalizedKey + "/"
+}
+
+func GetVersionKey(normalizedKey, versionId string) string {
+	return GetVersionsPrefix(normalizedKey) + versionId
+}
+
+func getVersionId(versionKey string) string {
+	return versionKey[strings.LastIndex(versionKey, "/")+1:]
+}
+
+func (s *VersionService) listVersionObjects(ctx context.Context, normalizedKey string, withMetadata bool) ([]minio.ObjectInfo, error) {
+	var objects <-chan minio.ObjectInfo = s.minio.ListObjects(ctx, s.minioConfig.FilesVersions, minio.ListObjectsOptions{
+		Prefix:       GetVersionsPrefix(normalizedKey),
+		Recursive:    true,
+		WithMetadata: withMetadata,
+	})
+	res := []minio.ObjectInfo{}
+	for objInfo := range objects {
+		if objInfo.Err != nil {
+			return nil, objInfo.Err
+		}
+		res = append(res, objInfo)
+	}
+	// newest first
+	slices.SortFunc(res, func(a, b minio.ObjectInfo) int {
+		return strings.Compare(getVersionId(b.Key), getVersionId(a.Key))
+	})
+	return res, nil
+}
+
+func (s *VersionService) CountVersions(ctx context.Context, normalizedKey string) (int, error) {
+	objects, err := s.listVersionObjects(ctx, normalizedKey, false)
+	if err != nil {
+		return 0, err
+	}
+	return len(objects), nil
+}
+
+// copies the current content of the file to the versions bucket before the file is overwritten
+func (s *VersionService) Archive(ctx context.Context, normalizedKey string) error {
+	maxVersions := getMaxVersions()
+	if maxVersions <= 0 {
+		return nil
+	}
+
+	err := s.copyToVersions(ctx, normalizedKey)
+	if err != nil {
+		return err
+	}
+	return s.removeExcessVersions(ctx, normalizedKey, maxVersions)
+}
+
+func (s *VersionService) copyToVersions(ctx context.Context, normalizedKey string) error {
+	exists, objectInfo, err := s.minio.FileExists(ctx, s.minioConfig.Files, normalizedKey)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	// the author and the time of the version, the original key is used to remove the versions of the removed files
+	userMetadata := SerializeOriginalKeyToMetadata(normalizedKey)
+	if ownerId, correlationId, timestamp, err := DeserializeMetadata(objectInfo.UserMetadata, false); err == nil {
+		var correlationIdPtr *string
+		if len(correlationId) > 0 {
+			correlationIdPtr = &correlationId
+		}
+		for k, v := range SerializeMetadataSimple(ownerId, correlationIdPtr, nil, nil, timestamp) {
+			userMetadata[k] = v
+		}
+	}
+
+	versionKey := GetVersionKey(normalizedKey, utils.Int64ToString(utils.GetUnixMilliUtc()))
+	_, err = s.minio.CopyObject(ctx, minio.CopyDestOptions{
+		Bucket:          s.minioConfig.FilesVersions,
+		Object:          versionKey,
+		UserMetadata:    userMetadata,
+		ReplaceMetadata: true,
+	}, minio.CopySrcOptions{
+		Bucket: s.minioConfig.Files,
+		Object: normalizedKey,
+	})
+	return err
+}
+
+func (s *VersionService) removeExcessVersions(ctx context.Context, normalizedKey string, maxVersions int) error {
+	objects, err := s.listVersionObjects(ctx, normalizedKey, false)
+	if err != nil {
+		return err
+	}
+	if len(objects) <= maxVersions {
+		return nil
+	}
+	for _, objInfo := range objects[maxVersions:] {
+		s.lgr.WithTracing(ctx).Infof("Removing the excess version %v", objInfo.Key)
+		err = s.minio.RemoveObject(ctx, s.minioConfig.FilesVersions, objInfo.Key, minio.RemoveObjectOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *VersionService) GetVersions(ctx context.Context, normalizedKey string) ([]dto.FileVersionDto, error) {
+	objects, err := s.listVersionObjects(ctx, normalizedKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var ownerIdSet = map[int64]bool{}
+	res := make([]dto.FileVersionDto, 0, len(objects))
+	for _, objInfo := range objects {
+		versionId := getVersionId(objInfo.Key)
+		downloadUrl, err := GetVersionDownloadUrl(normalizedKey, versionId)
+		if err != nil {
+			return nil, err
+		}
+
+		version := dto.FileVersionDto{
+			VersionId:      versionId,
+			Size:           objInfo.Size,
+			CreateDateTime: objInfo.LastModified,
+			Url:            downloadUrl,
+		}
+		// metadata can be absent because of poor backup
+		if ownerId, _, timestamp, err := DeserializeMetadata(objInfo.UserMetadata, true); err == nil {
+			version.OwnerId = ownerId
+			version.CreateDateTime = utils.GetEventTimeFromTimestamp(timestamp)
+			ownerIdSet[ownerId] = true
+		}
+		res = append(res, version)
+	}
+
+	users := GetUsersRemotelyOrEmpty(s.lgr, ownerIdSet, s.restClient, ctx)
+	for i := range res {
+		res[i].Owner = users[res[i].OwnerId]
+	}
+	return res, nil
+}
+
+func GetVersionDownloadUrl(normalizedKey, versionId string) (string, error) {
+	downloadUrl, err := url.Parse(utils.UrlStorageGetFileVersion)
+	if err != nil {
+		return "", err
+	}
+
+	query := downloadUrl.Query()
+	query.Add(utils.FileParam, normalizedKey)
+	query.Add(utils.VersionIdParam, versionId)
+	downloadUrl.RawQuery = query.Encode()
+	return downloadUrl.String(), nil
+}
+
+// returns nil in case there is no such version
+func (s *VersionService) StatVersion(ctx context.Context, normalizedKey, versionId string) (*minio.ObjectInfo, error) {
+	if _, err := utils.ParseInt64(versionId); err != nil {
+		return nil, nil
+	}
+	exists, objectInfo, err := s.minio.FileExists(ctx, s.minioConfig.FilesVersions, GetVersionKey(normalizedKey, versionId))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	return objectInfo, nil
+}
+
+// returns the scan status as well, see IsQuarantined
+func (s *VersionService) IsVersionQuarantined(ctx context.Context, normalizedKey, versionId string) (bool, string, error) {
+	// the tags are copied together with the content
+	tagging, err := s.minio.GetObjectTagging(ctx, s.minioConfig.FilesVersions, GetVersionKey(normalizedKey, versionId), minio.GetObjectTaggingOptions{})
+	if err != nil {
+		return false, "", err
+	}
+	scanStatus, ok := DeserializeScanStatusTag(tagging)
+	if !ok {
+		scanStatus = dto.ScanStatusNotScanned
+	}
+	return IsQuarantined(scanStatus), scanStatus, nil
+}
+
+func (s *VersionService) GetTemporaryDownloadUrl(ctx context.Context, normalizedKey, versionId string) (string, time.Duration, error) {
+	ttl := viper.GetDuration("minio.presignDownloadTtl")
+
+	reqParams := url.Values{}
+	reqParams.Set("response-content-disposition", "attachment; filename=\""+ReadFilename(normalizedKey)+"\"")
+	downloadUrl, err := s.minio.PresignedExternalGetObject(ctx, s.minioConfig.FilesVersions, GetVersionKey(normalizedKey, versionId), ttl, reqParams)
+	if err != nil {
+		return "", time.Second, err
+	}
+
+	return downloadUrl, ttl, nil
+}
+
+// the current content becomes a version too, so the restoring isn't destructive
+func (s *VersionService) Restore(ctx context.Context, normalizedKey, versionId string, userId int64) error {
+	versionInfo, err := s.StatVersion(ctx, normalizedKey, versionId)
+	if err != nil {
+		return err
+	}
+	if versionInfo == nil {
+		return ErrVersionNotFound
+	}
+
+	object, err := s.minio.GetObject(ctx, s.minioConfig.FilesVersions, GetVersionKey(normalizedKey, versionId), minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	// the excess versions are removed only after the restored one is read, it can be the oldest one
+	maxVersions := getMaxVersions()
+	if maxVersions > 0 {
+		err = s.copyToVersions(ctx, normalizedKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	var userMetadata = SerializeMetadataSimple(userId, nil, nil, nil, utils.GetUnixMilliUtc())
+	_, err = s.minio.PutObject(ctx, s.minioConfig.Files, normalizedKey, object, versionInfo.Size, minio.PutObjectOptions{ContentType: versionInfo.ContentType, UserMetadata: userMetadata})
+	if err != nil {
+		return err
+	}
+
+	if maxVersions > 0 {
+		return s.removeExcessVersions(ctx, normalizedKey, maxVersions)
+	}
+	return nil
+}
+
+// removes the versions of the file or of all the files with the given prefix
+func (s *VersionService) RemoveVersions(ctx context.Context, prefix string) error {
+	return s.minio.RemoveObject(ctx, s.minioConfig.FilesVersions, prefix, minio.RemoveObjectOptions{ForceDelete: true})
+}
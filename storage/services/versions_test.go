@@ -0,0 +1,385 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/client"
+	"nkonev.name/storage/dto"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/utils"
+)
+
+const testVersionedKey = "chat/1/file-item/doc.txt"
+
+var testVersionsConfig = &utils.MinioConfig{Files: "files", FilesVersions: "files-versions"}
+
+// aaa returns the users with the login "user<id>"
+func startFakeAaa(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		users := []*dto.User{}
+		for _, id := range strings.Split(r.URL.Query().Get("userId"), ",") {
+			userId, err := utils.ParseInt64(id)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			users = append(users, &dto.User{Id: userId, Login: "user" + id})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(users)
+	}))
+	t.Cleanup(server.Close)
+	viper.Set("aaa.url.base", server.URL)
+	viper.Set("aaa.url.getUsers", "/internal/user/list")
+}
+
+// opens the object on the first read, the same as minio does
+type lazyBackend struct {
+	backend.Backend
+}
+
+type lazyObject struct {
+	backend.Object
+	open func() (backend.Object, error)
+}
+
+func (b *lazyBackend) GetObject(ctx context.Context, bucketName, key string, opts minio.GetObjectOptions) (backend.Object, error) {
+	return &lazyObject{open: func() (backend.Object, error) {
+		return b.Backend.GetObject(ctx, bucketName, key, opts)
+	}}, nil
+}
+
+func (o *lazyObject) Read(p []byte) (int, error) {
+	if o.Object == nil {
+		object, err := o.open()
+		if err != nil {
+			return 0, err
+		}
+		o.Object = object
+	}
+	return o.Object.Read(p)
+}
+
+func (o *lazyObject) Close() error {
+	if o.Object == nil {
+		return nil
+	}
+	return o.Object.Close()
+}
+
+func newTestVersionService(t *testing.T, maxVersions int) (*VersionService, backend.Backend) {
+	viper.Set("versions.maxCount", maxVersions)
+	startFakeAaa(t)
+
+	lgr := logger.NewLogger()
+	b, err := backend.NewLocalBackend(lgr, t.TempDir(), "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bucket := range []string{testVersionsConfig.Files, testVersionsConfig.FilesVersions} {
+		if err := b.MakeBucket(context.Background(), bucket, minio.MakeBucketOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lazy := &lazyBackend{Backend: b}
+	return NewVersionService(lgr, lazy, testVersionsConfig, client.NewChatAccessClient(lgr)), lazy
+}
+
+func putTestFile(t *testing.T, b backend.Backend, key string, content string, ownerId int64) {
+	_, err := b.PutObject(context.Background(), testVersionsConfig.Files, key, strings.NewReader(content), int64(len(content)), minio.PutObjectOptions{
+		ContentType:  "text/plain",
+		UserMetadata: SerializeMetadataSimple(ownerId, nil, nil, nil, utils.GetUnixMilliUtc()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// replaces the file the same way the replace handler does
+func replaceTestFile(t *testing.T, s *VersionService, b backend.Backend, key string, content string, ownerId int64) {
+	// the version id is the milli time
+	time.Sleep(2 * time.Millisecond)
+	if err := s.Archive(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+	putTestFile(t, b, key, content, ownerId)
+}
+
+func readTestObject(t *testing.T, b backend.Backend, bucket, key string) string {
+	object, err := b.GetObject(context.Background(), bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func readTestVersion(t *testing.T, b backend.Backend, versionId string) string {
+	return readTestObject(t, b, testVersionsConfig.FilesVersions, GetVersionKey(testVersionedKey, versionId))
+}
+
+func TestVersionsOfReplacedFile(t *testing.T) {
+	ctx := context.Background()
+	s, b := newTestVersionService(t, 10)
+
+	putTestFile(t, b, testVersionedKey, "first", 1)
+	replaceTestFile(t, s, b, testVersionedKey, "second!", 2)
+	replaceTestFile(t, s, b, testVersionedKey, "third", 3)
+
+	if actual := readTestObject(t, b, testVersionsConfig.Files, testVersionedKey); actual != "third" {
+		t.Errorf("the file should have the last content, got %q", actual)
+	}
+	count, err := s.CountVersions(ctx, testVersionedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 versions, got %v", count)
+	}
+
+	versions, err := s.GetVersions(ctx, testVersionedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %v", len(versions))
+	}
+	// the newest first, with the author of the replaced content
+	expected := []struct {
+		content string
+		ownerId int64
+	}{{"second!", 2}, {"first", 1}}
+	for i, e := range expected {
+		version := versions[i]
+		if actual := readTestVersion(t, b, version.VersionId); actual != e.content {
+			t.Errorf("version %v should have %q, got %q", i, e.content, actual)
+		}
+		if version.Size != int64(len(e.content)) {
+			t.Errorf("version %v should have the size %v, got %v", i, len(e.content), version.Size)
+		}
+		if version.OwnerId != e.ownerId || version.Owner == nil || version.Owner.Login != "user"+utils.Int64ToString(e.ownerId) {
+			t.Errorf("version %v should be authored by %v, got %+v", i, e.ownerId, version.Owner)
+		}
+		if version.CreateDateTime.IsZero() {
+			t.Errorf("version %v should have the time", i)
+		}
+		if !strings.Contains(version.Url, utils.VersionIdParam+"="+version.VersionId) {
+			t.Errorf("the url of version %v should point to it, got %v", i, version.Url)
+		}
+	}
+}
+
+func TestVersionsAreCapped(t *testing.T) {
+	ctx := context.Background()
+	s, b := newTestVersionService(t, 2)
+
+	putTestFile(t, b, testVersionedKey, "v1", 1)
+	for _, content := range []string{"v2", "v3", "v4"} {
+		replaceTestFile(t, s, b, testVersionedKey, content, 1)
+	}
+
+	versions, err := s.GetVersions(ctx, testVersionedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := []string{}
+	for _, version := range versions {
+		contents = append(contents, readTestVersion(t, b, version.VersionId))
+	}
+	if strings.Join(contents, ",") != "v3,v2" {
+		t.Errorf("only the newest versions should be kept, got %v", contents)
+	}
+}
+
+func TestVersionsAreDisabled(t *testing.T) {
+	s, b := newTestVersionService(t, 0)
+
+	putTestFile(t, b, testVersionedKey, "v1", 1)
+	replaceTestFile(t, s, b, testVersionedKey, "v2", 1)
+
+	count, err := s.CountVersions(context.Background(), testVersionedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("there should be no versions, got %v", count)
+	}
+}
+
+func TestArchiveAbsentFile(t *testing.T) {
+	s, _ := newTestVersionService(t, 10)
+
+	if err := s.Archive(context.Background(), testVersionedKey); err != nil {
+		t.Fatal(err)
+	}
+	count, err := s.CountVersions(context.Background(), testVersionedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("there should be no versions, got %v", count)
+	}
+}
+
+func TestRestoreVersion(t *testing.T) {
+	ctx := context.Background()
+	s, b := newTestVersionService(t, 10)
+
+	putTestFile(t, b, testVersionedKey, "first", 1)
+	replaceTestFile(t, s, b, testVersionedKey, "second", 2)
+	versions, err := s.GetVersions(ctx, testVersionedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	err = s.Restore(ctx, testVersionedKey, versions[0].VersionId, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := readTestObject(t, b, testVersionsConfig.Files, testVersionedKey); actual != "first" {
+		t.Errorf("the file should have the restored content, got %q", actual)
+	}
+	info, err := b.StatObject(ctx, testVersionsConfig.Files, testVersionedKey, minio.StatObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ownerId, _, _, err := DeserializeMetadata(info.UserMetadata, false); err != nil || ownerId != 3 {
+		t.Errorf("the restoring user should become the owner, got %v %v", ownerId, err)
+	}
+	if info.ContentType != "text/plain" {
+		t.Errorf("the content type should be kept, got %v", info.ContentType)
+	}
+
+	// the replaced content isn't lost
+	versions, err = s.GetVersions(ctx, testVersionedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || readTestVersion(t, b, versions[0].VersionId) != "second" {
+		t.Errorf("the content before the restoring should become the version, got %+v", versions)
+	}
+}
+
+func TestRestoreOldestVersionAtCap(t *testing.T) {
+	ctx := context.Background()
+	s, b := newTestVersionService(t, 2)
+
+	putTestFile(t, b, testVersionedKey, "v1", 1)
+	replaceTestFile(t, s, b, testVersionedKey, "v2", 1)
+	replaceTestFile(t, s, b, testVersionedKey, "v3", 1)
+	versions, err := s.GetVersions(ctx, testVersionedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	err = s.Restore(ctx, testVersionedKey, versions[len(versions)-1].VersionId, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := readTestObject(t, b, testVersionsConfig.Files, testVersionedKey); actual != "v1" {
+		t.Errorf("the file should have the restored content, got %q", actual)
+	}
+	versions, err = s.GetVersions(ctx, testVersionedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := []string{}
+	for _, version := range versions {
+		contents = append(contents, readTestVersion(t, b, version.VersionId))
+	}
+	if strings.Join(contents, ",") != "v3,v2" {
+		t.Errorf("the cap should be kept after the restoring, got %v", contents)
+	}
+}
+
+func TestRestoreTheOnlyVersion(t *testing.T) {
+	ctx := context.Background()
+	s, b := newTestVersionService(t, 1)
+
+	putTestFile(t, b, testVersionedKey, "v1", 1)
+	replaceTestFile(t, s, b, testVersionedKey, "v2", 1)
+	versions, err := s.GetVersions(ctx, testVersionedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	err = s.Restore(ctx, testVersionedKey, versions[0].VersionId, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := readTestObject(t, b, testVersionsConfig.Files, testVersionedKey); actual != "v1" {
+		t.Errorf("the file should have the restored content, got %q", actual)
+	}
+	versions, err = s.GetVersions(ctx, testVersionedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || readTestVersion(t, b, versions[0].VersionId) != "v2" {
+		t.Errorf("the replaced content should become the only version, got %+v", versions)
+	}
+}
+
+func TestRestoreAbsentVersion(t *testing.T) {
+	s, b := newTestVersionService(t, 10)
+	putTestFile(t, b, testVersionedKey, "first", 1)
+
+	for _, versionId := range []string{"12345", "../../other", ""} {
+		err := s.Restore(context.Background(), testVersionedKey, versionId, 1)
+		if !errors.Is(err, ErrVersionNotFound) {
+			t.Errorf("expected not found for %q, got %v", versionId, err)
+		}
+	}
+	if actual := readTestObject(t, b, testVersionsConfig.Files, testVersionedKey); actual != "first" {
+		t.Errorf("the file shouldn't be changed, got %q", actual)
+	}
+}
+
+func TestRemoveVersions(t *testing.T) {
+	ctx := context.Background()
+	s, b := newTestVersionService(t, 10)
+	const otherKey = "chat/1/other-file-item/doc.txt"
+
+	for _, key := range []string{testVersionedKey, otherKey} {
+		putTestFile(t, b, key, "first", 1)
+		replaceTestFile(t, s, b, key, "second", 1)
+	}
+
+	if err := s.RemoveVersions(ctx, GetVersionsPrefix(testVersionedKey)); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := s.CountVersions(ctx, testVersionedKey); count != 0 {
+		t.Errorf("the versions of the removed file should be removed, got %v", count)
+	}
+	if count, _ := s.CountVersions(ctx, otherKey); count != 1 {
+		t.Errorf("the versions of the other file should be kept, got %v", count)
+	}
+
+	// the removal of the file item
+	if err := s.RemoveVersions(ctx, "chat/1/other-file-item/"); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := s.CountVersions(ctx, otherKey); count != 0 {
+		t.Errorf("the versions of the file item should be removed, got %v", count)
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"nkonev.name/storage/utils"
+)
+
+// the media are already compressed, so they are just stored
+func getZipMethod(normalizedKey string) uint16 {
+	if utils.IsImage(normalizedKey) || utils.IsVideo(normalizedKey) || utils.IsAudio(normalizedKey) {
+		return zip.Store
+	}
+	return zip.Deflate
+}
+
+// the files from the different file items can have the same names
+func getUniqueZipName(filename string, usedNames map[string]bool) string {
+	name := filename
+	for i := 1; usedNames[name]; i++ {
+		dotIdx := strings.LastIndex(filename, ".")
+		if dotIdx > 0 {
+			name = fmt.Sprintf("%v (%v)%v", filename[:dotIdx], i, filename[dotIdx:])
+		} else {
+			name = fmt.Sprintf("%v (%v)", filename, i)
+		}
+	}
+	usedNames[name] = true
+	return name
+}
+
+// streams the archive directly from minio to the writer, nothing is buffered on the disk
+func (h *FilesService) WriteZip(ctx context.Context, writer io.Writer, objects []minio.ObjectInfo) error {
+	zipWriter := zip.NewWriter(writer)
+	usedNames := map[string]bool{}
+	for _, objInfo := range objects {
+		err := h.writeZipEntry(ctx, zipWriter, objInfo, getUniqueZipName(ReadFilename(objInfo.Key), usedNames))
+		if err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
+
+func (h *FilesService) writeZipEntry(ctx context.Context, zipWriter *zip.Writer, objInfo minio.ObjectInfo, name string) error {
+	object, err := h.minio.GetObject(ctx, h.minioConfig.Files, objInfo.Key, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	entryWriter, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   getZipMethod(objInfo.Key),
+		Modified: objInfo.LastModified,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entryWriter, object)
+	return err
+}
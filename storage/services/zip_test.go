@@ -0,0 +1,114 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
+	"nkonev.name/storage/logger"
+	"nkonev.name/storage/utils"
+)
+
+func TestGetUniqueZipName(t *testing.T) {
+	usedNames := map[string]bool{}
+	cases := []struct {
+		filename string
+		expected string
+	}{
+		{"report.txt", "report.txt"},
+		{"report.txt", "report (1).txt"},
+		{"report.txt", "report (2).txt"},
+		{"report (1).txt", "report (1) (1).txt"},
+		{"archive.tar.gz", "archive.tar.gz"},
+		{"archive.tar.gz", "archive.tar (1).gz"},
+		{"README", "README"},
+		{"README", "README (1)"},
+		{".env", ".env"},
+		{".env", ".env (1)"},
+	}
+	for _, c := range cases {
+		if name := getUniqueZipName(c.filename, usedNames); name != c.expected {
+			t.Errorf("%v: expected %v, got %v", c.filename, c.expected, name)
+		}
+	}
+}
+
+func TestWriteZip(t *testing.T) {
+	viper.Set("types.image", []string{".png"})
+	ctx := context.Background()
+	lgr := logger.NewLogger()
+	local, err := backend.NewLocalBackend(lgr, t.TempDir(), "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minioConfig := &utils.MinioConfig{Files: "files"}
+	if err := local.MakeBucket(ctx, minioConfig.Files, minio.MakeBucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// the files of the different file items have the same names
+	contents := map[string]string{
+		"chat/1/first-item/report.txt":  "the first report",
+		"chat/1/second-item/report.txt": "the second report",
+		"chat/1/second-item/image.png":  "png",
+	}
+	objects := []minio.ObjectInfo{}
+	for _, key := range []string{"chat/1/first-item/report.txt", "chat/1/second-item/report.txt", "chat/1/second-item/image.png"} {
+		_, err = local.PutObject(ctx, minioConfig.Files, key, strings.NewReader(contents[key]), int64(len(contents[key])), minio.PutObjectOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		objInfo, err := local.StatObject(ctx, minioConfig.Files, key, minio.StatObjectOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		objects = append(objects, objInfo)
+	}
+	s := NewFilesService(lgr, local, nil, nil, minioConfig, nil)
+
+	var archive bytes.Buffer
+	if err := s.WriteZip(ctx, &archive, objects); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		name    string
+		content string
+		method  uint16
+	}{
+		{"report.txt", "the first report", zip.Deflate},
+		{"report (1).txt", "the second report", zip.Deflate},
+		// the media are already compressed
+		{"image.png", "png", zip.Store},
+	}
+	if len(reader.File) != len(expected) {
+		t.Fatalf("expected %v entries, got %v", len(expected), len(reader.File))
+	}
+	for i, e := range expected {
+		entry := reader.File[i]
+		if entry.Name != e.name || entry.Method != e.method {
+			t.Errorf("unexpected entry %v with method %v, expected %v with method %v", entry.Name, entry.Method, e.name, e.method)
+		}
+		entryReader, err := entry.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(entryReader)
+		entryReader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != e.content {
+			t.Errorf("unexpected content of %v: %q", entry.Name, content)
+		}
+	}
+}
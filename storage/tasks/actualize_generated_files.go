@@ -11,8 +11,8 @@ import (
 	"github.com/spf13/viper"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
+	"nkonev.name/storage/backend"
 	"nkonev.name/storage/logger"
-	"nkonev.name/storage/s3"
 	"nkonev.name/storage/services"
 	"nkonev.name/storage/utils"
 )
@@ -41,7 +41,7 @@ func ActualizeGeneratedFilesScheduler(
 }
 
 type ActualizeGeneratedFilesService struct {
-	minioClient        *s3.InternalMinioClient
+	minioClient        backend.Backend
 	minioBucketsConfig *utils.MinioConfig
 	previewService     *services.PreviewService
 	tracer             trace.Tracer
@@ -107,8 +107,9 @@ func (srv *ActualizeGeneratedFilesService) processFiles(c context.Context, filen
 				srv.lgr.WithTracing(c).Infof("Create missed converted for %v", fileOjInfo.Key)
 				srv.convertingService.Convert(c, fileOjInfo.Key)
 			}
-		} else if utils.IsImage(fileOjInfo.Key) {
-			previewToCheck := utils.SetImagePreviewExtension(fileOjInfo.Key)
+		} else if srv.previewService.HasPreviewer(fileOjInfo.Key) {
+			// images and documents
+			previewToCheck := srv.previewService.GetPreviewKey(fileOjInfo.Key)
 			exists, _, err := srv.minioClient.FileExists(c, srv.minioBucketsConfig.FilesPreview, previewToCheck)
 			if err != nil {
 				srv.lgr.WithTracing(c).Errorf("Unable to check existence for %v: %v", previewToCheck, err)
@@ -161,10 +162,89 @@ func (srv *ActualizeGeneratedFilesService) processFiles(c context.Context, filen
 	}
 	srv.lgr.WithTracing(c).Infof("Checking for excess previews finished")
 
+	// remove versions of removed files
+	srv.lgr.WithTracing(c).Infof("Checking for excess versions")
+	var versionObjects <-chan minio.ObjectInfo = srv.minioClient.ListObjects(c, srv.minioBucketsConfig.FilesVersions, minio.ListObjectsOptions{
+		Prefix:       filenameChatPrefix,
+		Recursive:    true,
+		WithMetadata: true,
+	})
+	for versionOjInfo := range versionObjects {
+		srv.lgr.WithTracing(c).Debugf("Start processing minio key '%v'", versionOjInfo.Key)
+		originalKey, err := services.GetOriginalKeyFromMetadata(versionOjInfo.UserMetadata, true)
+		if err != nil {
+			srv.lgr.WithTracing(c).Errorf("There is no original key for version %v, skipping", versionOjInfo.Key)
+			continue
+		}
+		exists, _, err := srv.minioClient.FileExists(c, srv.minioBucketsConfig.Files, originalKey)
+		if err != nil {
+			srv.lgr.WithTracing(c).Errorf("Unable to get exists for %v: %v", originalKey, err)
+			continue
+		}
+		if !exists {
+			srv.lgr.WithTracing(c).Infof("Will remove version %v of removed %v", versionOjInfo.Key, originalKey)
+			err := srv.minioClient.RemoveObject(c, srv.minioBucketsConfig.FilesVersions, versionOjInfo.Key, minio.RemoveObjectOptions{})
+			if err != nil {
+				srv.lgr.WithTracing(c).Errorf("Error during removing version key %v", err)
+				continue
+			}
+		}
+	}
+	srv.lgr.WithTracing(c).Infof("Checking for excess versions finished")
+
+	// remove hls of removed videos
+	srv.lgr.WithTracing(c).Infof("Checking for excess hls")
+	var hlsObjects <-chan minio.ObjectInfo = srv.minioClient.ListObjects(c, srv.minioBucketsConfig.FilesHls, minio.ListObjectsOptions{
+		Prefix:       filenameChatPrefix,
+		Recursive:    true,
+		WithMetadata: true,
+	})
+	for hlsOjInfo := range hlsObjects {
+		srv.lgr.WithTracing(c).Debugf("Start processing minio key '%v'", hlsOjInfo.Key)
+		originalKey, err := services.GetOriginalKeyFromMetadata(hlsOjInfo.UserMetadata, true)
+		if err != nil {
+			srv.lgr.WithTracing(c).Errorf("There is no original key for hls %v, skipping", hlsOjInfo.Key)
+			continue
+		}
+		exists, _, err := srv.minioClient.FileExists(c, srv.minioBucketsConfig.Files, originalKey)
+		if err != nil {
+			srv.lgr.WithTracing(c).Errorf("Unable to get exists for %v: %v", originalKey, err)
+			continue
+		}
+		if !exists {
+			srv.lgr.WithTracing(c).Infof("Will remove hls %v of removed %v", hlsOjInfo.Key, originalKey)
+			err := srv.minioClient.RemoveObject(c, srv.minioBucketsConfig.FilesHls, hlsOjInfo.Key, minio.RemoveObjectOptions{})
+			if err != nil {
+				srv.lgr.WithTracing(c).Errorf("Error during removing hls key %v", err)
+				continue
+			}
+		}
+	}
+	srv.lgr.WithTracing(c).Infof("Checking for excess hls finished")
+
+	// remove the unfinished parts of the abandoned resumable uploads, the upload can't live longer than the multipart one
+	srv.lgr.WithTracing(c).Infof("Checking for expired tus tails")
+	expiredBefore := time.Now().Add(-viper.GetDuration("minio.multipart.expire"))
+	var tusObjects <-chan minio.ObjectInfo = srv.minioClient.ListObjects(c, srv.minioBucketsConfig.FilesTus, minio.ListObjectsOptions{
+		Recursive: true,
+	})
+	for tusOjInfo := range tusObjects {
+		if tusOjInfo.LastModified.After(expiredBefore) {
+			continue
+		}
+		srv.lgr.WithTracing(c).Infof("Will remove expired tus tail %v", tusOjInfo.Key)
+		err := srv.minioClient.RemoveObject(c, srv.minioBucketsConfig.FilesTus, tusOjInfo.Key, minio.RemoveObjectOptions{})
+		if err != nil {
+			srv.lgr.WithTracing(c).Errorf("Error during removing tus tail %v", err)
+			continue
+		}
+	}
+	srv.lgr.WithTracing(c).Infof("Checking for expired tus tails finished")
+
 	srv.lgr.WithTracing(c).Infof("End of generated files job")
 }
 
-func NewActualizeGeneratedFilesService(lgr *logger.Logger, minioClient *s3.InternalMinioClient, minioBucketsConfig *utils.MinioConfig, previewService *services.PreviewService, redisInfoService *services.RedisInfoService, convertingService *services.ConvertingService) *ActualizeGeneratedFilesService {
+func NewActualizeGeneratedFilesService(lgr *logger.Logger, minioClient backend.Backend, minioBucketsConfig *utils.MinioConfig, previewService *services.PreviewService, redisInfoService *services.RedisInfoService, convertingService *services.ConvertingService) *ActualizeGeneratedFilesService {
 	trcr := otel.Tracer("scheduler/actualize-generated-files")
 	return &ActualizeGeneratedFilesService{
 		lgr:                lgr,
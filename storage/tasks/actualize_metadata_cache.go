@@ -10,10 +10,10 @@ import (
 	"github.com/spf13/viper"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
+	"nkonev.name/storage/backend"
 	"nkonev.name/storage/db"
 	"nkonev.name/storage/dto"
 	"nkonev.name/storage/logger"
-	"nkonev.name/storage/s3"
 	"nkonev.name/storage/services"
 	"nkonev.name/storage/utils"
 )
@@ -42,8 +42,11 @@ func ActualizeMetadataCacheScheduler(
 }
 
 type ActualizeMetadataCacheService struct {
-	minioClient        *s3.InternalMinioClient
+	minioClient        backend.Backend
 	minioBucketsConfig *utils.MinioConfig
+	versionService     *services.VersionService
+	mediaService       *services.MediaService
+	antivirusService   *services.AntivirusService
 	dba                *db.DB
 	tracer             trace.Tracer
 	lgr                *logger.Logger
@@ -114,7 +117,18 @@ func (srv *ActualizeMetadataCacheService) processFiles(c context.Context, filena
 			eventTime := utils.GetEventTimeFromTimestamp(timestamp)
 
 			// use try* function for the case when there is no metadata (files were copied on the disk, so metadata wasn't preserved)
-			published := srv.tryGetTags(c, fileOjInfo)
+			published, scanStatus := srv.tryGetTags(c, fileOjInfo)
+
+			versionCount, err := srv.versionService.CountVersions(c, fileOjInfo.Key)
+			if err != nil {
+				srv.lgr.WithTracing(c).Errorf("Unable to count versions for %v: %v", fileOjInfo.Key, err)
+				continue
+			}
+
+			var media dto.MediaMetadata
+			if scanStatus != dto.ScanStatusInfected {
+				media = srv.mediaService.Extract(c, fileOjInfo.Key)
+			}
 
 			err = db.Set(c, srv.dba, dto.MetadataCache{
 				ChatId:         chatId,
@@ -123,9 +137,12 @@ func (srv *ActualizeMetadataCacheService) processFiles(c context.Context, filena
 				OwnerId:        ownerId,
 				CorrelationId:  correlationIdPtr,
 				Published:      published,
+				ScanStatus:     scanStatus,
+				VersionCount:   versionCount,
 				FileSize:       fileOjInfo.Size,
 				CreateDateTime: eventTime,
 				EditDateTime:   eventTime,
+				Media:          media,
 			})
 			if err != nil {
 				srv.lgr.WithTracing(c).Errorf("Unable to create metadata cache for %v: %v", mcid.String(), err)
@@ -171,6 +188,11 @@ func (srv *ActualizeMetadataCacheService) processFiles(c context.Context, filena
 					srv.lgr.WithTracing(c).Errorf("Error during removing metadata cache for %v: %v", mcid.String(), err)
 					continue
 				}
+				err = db.RemoveShareLinksOfFile(c, srv.dba, mcid)
+				if err != nil {
+					srv.lgr.WithTracing(c).Errorf("Error during removing share links for %v: %v", mcid.String(), err)
+					continue
+				}
 				wasDeletions = true
 			}
 		}
@@ -184,23 +206,72 @@ func (srv *ActualizeMetadataCacheService) processFiles(c context.Context, filena
 	}
 	srv.lgr.WithTracing(c).Infof("Checking for excess metadata cache items finished")
 
+	if srv.antivirusService.IsEnabled() {
+		srv.rescanUnverified(c)
+	}
+
 	srv.lgr.WithTracing(c).Infof("End of actualize metadata cache job")
 }
 
-func (srv *ActualizeMetadataCacheService) tryGetTags(ctx context.Context, fileOjInfo minio.ObjectInfo) bool {
+// the files which haven't got the verdict are refused to be downloaded, and there is no storage event which would scan them again
+func (srv *ActualizeMetadataCacheService) rescanUnverified(c context.Context) {
+	srv.lgr.WithTracing(c).Infof("Scanning the files without the verdict")
+	after := dto.MetadataCacheId{ChatId: dto.NoChatId}
+	for {
+		metadatas, err := db.GetUnverified(c, srv.dba, after, utils.DefaultSize)
+		if err != nil {
+			srv.lgr.WithTracing(c).Errorf("Error during getting the files without the verdict: %v", err)
+			return
+		}
+
+		for _, metadata := range metadatas {
+			aKey := utils.BuildNormalizedKey(&metadata)
+			mcid := dto.MetadataCacheId{
+				ChatId:       metadata.ChatId,
+				FileItemUuid: metadata.FileItemUuid,
+				Filename:     metadata.Filename,
+			}
+			after = mcid
+
+			scanStatus, err := srv.antivirusService.Rescan(c, aKey, metadata.Published)
+			if err != nil {
+				srv.lgr.WithTracing(c).Errorf("Unable to scan %v: %v", aKey, err)
+				continue
+			}
+			// the tagging event updates the rest, the status is set right away in order not to wait for it
+			err = db.SetScanStatus(c, srv.dba, mcid, scanStatus)
+			if err != nil {
+				srv.lgr.WithTracing(c).Errorf("Unable to set the scan status for %v: %v", mcid.String(), err)
+				continue
+			}
+		}
+
+		if len(metadatas) < utils.DefaultSize {
+			break
+		}
+	}
+	srv.lgr.WithTracing(c).Infof("Scanning the files without the verdict finished")
+}
+
+func (srv *ActualizeMetadataCacheService) tryGetTags(ctx context.Context, fileOjInfo minio.ObjectInfo) (bool, string) {
 	tags, err := srv.minioClient.GetObjectTagging(ctx, srv.minioBucketsConfig.Files, fileOjInfo.Key, minio.GetObjectTaggingOptions{})
 	if err != nil {
 		srv.lgr.WithTracing(ctx).Debugf("Unable to get tags for %v: %v", fileOjInfo.Key, err)
-		return false
+		return false, dto.ScanStatusNotScanned
+	}
+
+	scanStatus, ok := services.DeserializeScanStatusTag(tags)
+	if !ok {
+		scanStatus = dto.ScanStatusNotScanned
 	}
 
 	published, err := services.DeserializeTags(tags)
 	if err != nil {
 		srv.lgr.WithTracing(ctx).Errorf("Unable to deserialize tags for %v: %v", fileOjInfo.Key, err)
-		return false
+		return false, scanStatus
 	}
 
-	return published
+	return published, scanStatus
 }
 
 func (srv *ActualizeMetadataCacheService) tryGetMetadata(ctx context.Context, fileOjInfo minio.ObjectInfo) (int64, string, int64, error) {
@@ -222,12 +293,15 @@ func (srv *ActualizeMetadataCacheService) tryGetMetadata(ctx context.Context, fi
 	return ownerId, correlationId, timestamp, nil
 }
 
-func NewActualizeMetadataCacheService(lgr *logger.Logger, minioClient *s3.InternalMinioClient, minioBucketsConfig *utils.MinioConfig, dba *db.DB) *ActualizeMetadataCacheService {
+func NewActualizeMetadataCacheService(lgr *logger.Logger, minioClient backend.Backend, minioBucketsConfig *utils.MinioConfig, versionService *services.VersionService, mediaService *services.MediaService, antivirusService *services.AntivirusService, dba *db.DB) *ActualizeMetadataCacheService {
 	trcr := otel.Tracer("scheduler/actualize-metadata-cache")
 	return &ActualizeMetadataCacheService{
 		lgr:                lgr,
 		minioClient:        minioClient,
 		minioBucketsConfig: minioBucketsConfig,
+		versionService:     versionService,
+		mediaService:       mediaService,
+		antivirusService:   antivirusService,
 		dba:                dba,
 		tracer:             trcr,
 	}
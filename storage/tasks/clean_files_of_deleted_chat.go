@@ -11,9 +11,9 @@ import (
 	"github.com/spf13/viper"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
+	"nkonev.name/storage/backend"
 	"nkonev.name/storage/client"
 	"nkonev.name/storage/logger"
-	"nkonev.name/storage/s3"
 	"nkonev.name/storage/utils"
 )
 
@@ -41,7 +41,7 @@ func CleanFilesOfDeletedChatScheduler(
 }
 
 type CleanFilesOfDeletedChatService struct {
-	minioClient        *s3.InternalMinioClient
+	minioClient        backend.Backend
 	minioBucketsConfig *utils.MinioConfig
 	chatClient         *client.RestClient
 	tracer             trace.Tracer
@@ -138,7 +138,7 @@ func (srv *CleanFilesOfDeletedChatService) processBatch(c context.Context, chatI
 	}
 }
 
-func NewCleanFilesOfDeletedChatService(lgr *logger.Logger, minioClient *s3.InternalMinioClient, minioBucketsConfig *utils.MinioConfig, chatClient *client.RestClient) *CleanFilesOfDeletedChatService {
+func NewCleanFilesOfDeletedChatService(lgr *logger.Logger, minioClient backend.Backend, minioBucketsConfig *utils.MinioConfig, chatClient *client.RestClient) *CleanFilesOfDeletedChatService {
 	trcr := otel.Tracer("scheduler/clean-files-of-deleted-chat")
 	return &CleanFilesOfDeletedChatService{
 		lgr:                lgr,
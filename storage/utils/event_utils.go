@@ -9,6 +9,7 @@ const (
 	FILE_CREATED
 	FILE_DELETED
 	FILE_UPDATED
+	FILE_INFECTED // only for the uploader
 )
 
 func GetEventType(eventName string, isRecording bool) (EventType, error) {
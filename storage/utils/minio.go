@@ -8,12 +8,12 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/spf13/viper"
+	"nkonev.name/storage/backend"
 	"nkonev.name/storage/dto"
 	"nkonev.name/storage/logger"
-	"nkonev.name/storage/s3"
 )
 
-func ensureBucket(lgr *logger.Logger, minioClient *s3.InternalMinioClient, bucketName, location string) error {
+func ensureBucket(lgr *logger.Logger, minioClient backend.Backend, bucketName, location string) error {
 	// Check to see if we already own this bucket (which happens if you run this twice)
 	exists, err := minioClient.BucketExists(context.Background(), bucketName)
 	if err == nil && exists {
@@ -35,49 +35,70 @@ func ensureBucket(lgr *logger.Logger, minioClient *s3.InternalMinioClient, bucke
 	}
 }
 
-func EnsureAndGetUserAvatarBucket(lgr *logger.Logger, minioClient *s3.InternalMinioClient) (string, error) {
+func EnsureAndGetUserAvatarBucket(lgr *logger.Logger, minioClient backend.Backend) (string, error) {
 	bucketName := viper.GetString("minio.bucket.userAvatar")
 	bucketLocation := viper.GetString("minio.location")
 	err := ensureBucket(lgr, minioClient, bucketName, bucketLocation)
 	return bucketName, err
 }
 
-func EnsureAndGetChatAvatarBucket(lgr *logger.Logger, minioClient *s3.InternalMinioClient) (string, error) {
+func EnsureAndGetChatAvatarBucket(lgr *logger.Logger, minioClient backend.Backend) (string, error) {
 	bucketName := viper.GetString("minio.bucket.chatAvatar")
 	bucketLocation := viper.GetString("minio.location")
 	err := ensureBucket(lgr, minioClient, bucketName, bucketLocation)
 	return bucketName, err
 }
 
-func EnsureAndGetFilesBucket(lgr *logger.Logger, minioClient *s3.InternalMinioClient) (string, error) {
+func EnsureAndGetFilesBucket(lgr *logger.Logger, minioClient backend.Backend) (string, error) {
 	bucketName := viper.GetString("minio.bucket.files")
 	bucketLocation := viper.GetString("minio.location")
 	err := ensureBucket(lgr, minioClient, bucketName, bucketLocation)
 	return bucketName, err
 }
 
-func EnsureAndGetFilesPreviewBucket(lgr *logger.Logger, minioClient *s3.InternalMinioClient) (string, error) {
+func EnsureAndGetFilesPreviewBucket(lgr *logger.Logger, minioClient backend.Backend) (string, error) {
 	bucketName := viper.GetString("minio.bucket.filesPreview")
 	bucketLocation := viper.GetString("minio.location")
 	err := ensureBucket(lgr, minioClient, bucketName, bucketLocation)
 	return bucketName, err
 }
 
+func EnsureAndGetFilesVersionsBucket(lgr *logger.Logger, minioClient backend.Backend) (string, error) {
+	bucketName := viper.GetString("minio.bucket.filesVersions")
+	bucketLocation := viper.GetString("minio.location")
+	err := ensureBucket(lgr, minioClient, bucketName, bucketLocation)
+	return bucketName, err
+}
+
+func EnsureAndGetFilesHlsBucket(lgr *logger.Logger, minioClient backend.Backend) (string, error) {
+	bucketName := viper.GetString("minio.bucket.filesHls")
+	bucketLocation := viper.GetString("minio.location")
+	err := ensureBucket(lgr, minioClient, bucketName, bucketLocation)
+	return bucketName, err
+}
+
+func EnsureAndGetFilesTusBucket(lgr *logger.Logger, minioClient backend.Backend) (string, error) {
+	bucketName := viper.GetString("minio.bucket.filesTus")
+	bucketLocation := viper.GetString("minio.location")
+	err := ensureBucket(lgr, minioClient, bucketName, bucketLocation)
+	return bucketName, err
+}
+
 type MinioConfig struct {
-	UserAvatar, ChatAvatar, Files, FilesPreview string
+	UserAvatar, ChatAvatar, Files, FilesPreview, FilesVersions, FilesHls, FilesTus string
 }
 
-// https://min.io/docs/minio/linux/reference/minio-mc/mc-event-add.html#mc-event-supported-events
+// see backend.ObjectCreated
 
-const ObjectCreated = "s3:ObjectCreated"
-const ObjectRemoved = "s3:ObjectRemoved"
+const ObjectCreated = backend.ObjectCreated
+const ObjectRemoved = backend.ObjectRemoved
 
-const ObjectCreatedCompleteMultipartUpload = ObjectCreated + ":CompleteMultipartUpload"
+const ObjectCreatedCompleteMultipartUpload = backend.ObjectCreatedCompleteMultipartUpload
 
-const ObjectRemovedDelete = ObjectRemoved + ":Delete"
+const ObjectRemovedDelete = backend.ObjectRemovedDelete
 
-const ObjectCreatedPutTagging = ObjectCreated + ":PutTagging"
-const ObjectCreatedPut = ObjectCreated + ":Put"
+const ObjectCreatedPutTagging = backend.ObjectCreatedPutTagging
+const ObjectCreatedPut = backend.ObjectCreatedPut
 
 func SetVideoPreviewExtension(key string) string {
 	return SetExtension(key, "jpg")
@@ -87,7 +108,14 @@ func SetImagePreviewExtension(key string) string {
 	return SetExtension(key, "jpg")
 }
 
+func SetDocumentPreviewExtension(key string) string {
+	return SetExtension(key, "jpg")
+}
+
 const FileParam = "file"
+const VersionIdParam = "versionId"
+const RenditionParam = "rendition"
+const ShareLinkParam = "link"
 const TimeParam = "time"
 const OverrideMessageId = "overrideMessageId"
 const OverrideChatId = "overrideChatId"
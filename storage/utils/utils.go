@@ -265,6 +265,12 @@ func IsVideo(minioKey string) bool {
 	return StringContains(videoTypes2, GetDotExtensionStr(minioKey))
 }
 
+func IsOfType(aType string, minioKey string) bool {
+	types := viper.GetStringSlice("types." + aType)
+	types2 := toLower(types)
+	return StringContains(types2, GetDotExtensionStr(minioKey))
+}
+
 func IsPreviewable(minioKey string) bool {
 	return IsImage(minioKey) || IsVideo(minioKey)
 }
@@ -297,6 +303,10 @@ func GetType(aDto interface{}) string {
 const UrlStoragePublicGetFile = "/api/storage/public/download"
 const UrlStoragePublicPreviewFile = "/api/storage/public/download/embed/preview"
 const UrlStorageGetFile = "/api/storage/download"
+const UrlStorageGetFileVersion = "/api/storage/download/version"
+const UrlStorageHlsMaster = "/api/storage/hls/master.m3u8"
+const UrlStorageHlsPlaylist = "/api/storage/hls/playlist.m3u8"
+const UrlStorageHlsStatus = "/api/storage/hls/status"
 const UrlStorageGetFilePublicExternal = "/api/storage/public/download"
 const UrlBasePreview = "/api/storage/embed/preview"
 const UrlStorageEmbedPreview = "/embed/preview"
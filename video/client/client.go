@@ -314,12 +314,14 @@ func (h *RestClient) GetChatNameForInvite(c context.Context, chatId int64, behal
 }
 
 type S3Request struct {
-	FileName string `json:"fileName"`
-	ChatId   int64  `json:"chatId"`
-	OwnerId  int64  `json:"ownerId"`
+	FileName     string  `json:"fileName"`
+	ChatId       int64   `json:"chatId"`
+	OwnerId      int64   `json:"ownerId"`
+	FileItemUuid *string `json:"fileItemUuid"`
 }
 
-func (h *RestClient) GetS3(c context.Context, filename string, chatId int64, userId int64) (*dto.S3Response, error) {
+// fileItemUuid is optional, if it is set then storage puts the file into the existing file item
+func (h *RestClient) GetS3(c context.Context, filename string, chatId int64, userId int64, fileItemUuid *string) (*dto.S3Response, error) {
 	contentType := "application/json;charset=UTF-8"
 	fullUrl := h.storageBaseUrl + h.storageS3Path
 
@@ -336,9 +338,10 @@ func (h *RestClient) GetS3(c context.Context, filename string, chatId int64, use
 	}
 
 	req := S3Request{
-		FileName: filename,
-		ChatId:   chatId,
-		OwnerId:  userId,
+		FileName:     filename,
+		ChatId:       chatId,
+		OwnerId:      userId,
+		FileItemUuid: fileItemUuid,
 	}
 
 	bytesData, err := json.Marshal(req)
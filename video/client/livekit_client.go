@@ -13,6 +13,14 @@ type LivekitRoomClient interface {
 	MutePublishedTrack(ctx context.Context, req *livekit.MuteRoomTrackRequest) (*livekit.MuteRoomTrackResponse, error)
 	ListRooms(ctx context.Context, req *livekit.ListRoomsRequest) (*livekit.ListRoomsResponse, error)
 	RemoveParticipant(ctx context.Context, req *livekit.RoomParticipantIdentity) (*livekit.RemoveParticipantResponse, error)
+	UpdateParticipant(ctx context.Context, req *livekit.UpdateParticipantRequest) (*livekit.ParticipantInfo, error)
+}
+
+type LivekitEgressClient interface {
+	StartRoomCompositeEgress(ctx context.Context, req *livekit.RoomCompositeEgressRequest) (*livekit.EgressInfo, error)
+	StartParticipantEgress(ctx context.Context, req *livekit.ParticipantEgressRequest) (*livekit.EgressInfo, error)
+	ListEgress(ctx context.Context, req *livekit.ListEgressRequest) (*livekit.ListEgressResponse, error)
+	StopEgress(ctx context.Context, req *livekit.StopEgressRequest) (*livekit.EgressInfo, error)
 }
 
 func NewLivekitClient(conf *config.ExtendedConfig) LivekitRoomClient {
@@ -20,6 +28,6 @@ func NewLivekitClient(conf *config.ExtendedConfig) LivekitRoomClient {
 	return client
 }
 
-func NewEgressClient(conf *config.ExtendedConfig) *lksdk.EgressClient {
+func NewEgressClient(conf *config.ExtendedConfig) LivekitEgressClient {
 	return lksdk.NewEgressClient(conf.LivekitConfig.Url, conf.LivekitConfig.Api.Key, conf.LivekitConfig.Api.Secret)
 }
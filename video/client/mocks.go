@@ -11,6 +11,305 @@ import (
 	mock "github.com/stretchr/testify/mock"
 )
 
+// NewMockLivekitEgressClient creates a new instance of MockLivekitEgressClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockLivekitEgressClient(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockLivekitEgressClient {
+	mock := &MockLivekitEgressClient{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockLivekitEgressClient is an autogenerated mock type for the LivekitEgressClient type
+type MockLivekitEgressClient struct {
+	mock.Mock
+}
+
+type MockLivekitEgressClient_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockLivekitEgressClient) EXPECT() *MockLivekitEgressClient_Expecter {
+	return &MockLivekitEgressClient_Expecter{mock: &_m.Mock}
+}
+
+// ListEgress provides a mock function for the type MockLivekitEgressClient
+func (_mock *MockLivekitEgressClient) ListEgress(ctx context.Context, req *livekit.ListEgressRequest) (*livekit.ListEgressResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListEgress")
+	}
+
+	var r0 *livekit.ListEgressResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *livekit.ListEgressRequest) (*livekit.ListEgressResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *livekit.ListEgressRequest) *livekit.ListEgressResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*livekit.ListEgressResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *livekit.ListEgressRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLivekitEgressClient_ListEgress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListEgress'
+type MockLivekitEgressClient_ListEgress_Call struct {
+	*mock.Call
+}
+
+// ListEgress is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *livekit.ListEgressRequest
+func (_e *MockLivekitEgressClient_Expecter) ListEgress(ctx interface{}, req interface{}) *MockLivekitEgressClient_ListEgress_Call {
+	return &MockLivekitEgressClient_ListEgress_Call{Call: _e.mock.On("ListEgress", ctx, req)}
+}
+
+func (_c *MockLivekitEgressClient_ListEgress_Call) Run(run func(ctx context.Context, req *livekit.ListEgressRequest)) *MockLivekitEgressClient_ListEgress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *livekit.ListEgressRequest
+		if args[1] != nil {
+			arg1 = args[1].(*livekit.ListEgressRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLivekitEgressClient_ListEgress_Call) Return(listEgressResponse *livekit.ListEgressResponse, err error) *MockLivekitEgressClient_ListEgress_Call {
+	_c.Call.Return(listEgressResponse, err)
+	return _c
+}
+
+func (_c *MockLivekitEgressClient_ListEgress_Call) RunAndReturn(run func(ctx context.Context, req *livekit.ListEgressRequest) (*livekit.ListEgressResponse, error)) *MockLivekitEgressClient_ListEgress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartParticipantEgress provides a mock function for the type MockLivekitEgressClient
+func (_mock *MockLivekitEgressClient) StartParticipantEgress(ctx context.Context, req *livekit.ParticipantEgressRequest) (*livekit.EgressInfo, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartParticipantEgress")
+	}
+
+	var r0 *livekit.EgressInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *livekit.ParticipantEgressRequest) (*livekit.EgressInfo, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *livekit.ParticipantEgressRequest) *livekit.EgressInfo); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*livekit.EgressInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *livekit.ParticipantEgressRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLivekitEgressClient_StartParticipantEgress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartParticipantEgress'
+type MockLivekitEgressClient_StartParticipantEgress_Call struct {
+	*mock.Call
+}
+
+// StartParticipantEgress is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *livekit.ParticipantEgressRequest
+func (_e *MockLivekitEgressClient_Expecter) StartParticipantEgress(ctx interface{}, req interface{}) *MockLivekitEgressClient_StartParticipantEgress_Call {
+	return &MockLivekitEgressClient_StartParticipantEgress_Call{Call: _e.mock.On("StartParticipantEgress", ctx, req)}
+}
+
+func (_c *MockLivekitEgressClient_StartParticipantEgress_Call) Run(run func(ctx context.Context, req *livekit.ParticipantEgressRequest)) *MockLivekitEgressClient_StartParticipantEgress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *livekit.ParticipantEgressRequest
+		if args[1] != nil {
+			arg1 = args[1].(*livekit.ParticipantEgressRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLivekitEgressClient_StartParticipantEgress_Call) Return(egressInfo *livekit.EgressInfo, err error) *MockLivekitEgressClient_StartParticipantEgress_Call {
+	_c.Call.Return(egressInfo, err)
+	return _c
+}
+
+func (_c *MockLivekitEgressClient_StartParticipantEgress_Call) RunAndReturn(run func(ctx context.Context, req *livekit.ParticipantEgressRequest) (*livekit.EgressInfo, error)) *MockLivekitEgressClient_StartParticipantEgress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartRoomCompositeEgress provides a mock function for the type MockLivekitEgressClient
+func (_mock *MockLivekitEgressClient) StartRoomCompositeEgress(ctx context.Context, req *livekit.RoomCompositeEgressRequest) (*livekit.EgressInfo, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartRoomCompositeEgress")
+	}
+
+	var r0 *livekit.EgressInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *livekit.RoomCompositeEgressRequest) (*livekit.EgressInfo, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *livekit.RoomCompositeEgressRequest) *livekit.EgressInfo); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*livekit.EgressInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *livekit.RoomCompositeEgressRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLivekitEgressClient_StartRoomCompositeEgress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartRoomCompositeEgress'
+type MockLivekitEgressClient_StartRoomCompositeEgress_Call struct {
+	*mock.Call
+}
+
+// StartRoomCompositeEgress is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *livekit.RoomCompositeEgressRequest
+func (_e *MockLivekitEgressClient_Expecter) StartRoomCompositeEgress(ctx interface{}, req interface{}) *MockLivekitEgressClient_StartRoomCompositeEgress_Call {
+	return &MockLivekitEgressClient_StartRoomCompositeEgress_Call{Call: _e.mock.On("StartRoomCompositeEgress", ctx, req)}
+}
+
+func (_c *MockLivekitEgressClient_StartRoomCompositeEgress_Call) Run(run func(ctx context.Context, req *livekit.RoomCompositeEgressRequest)) *MockLivekitEgressClient_StartRoomCompositeEgress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *livekit.RoomCompositeEgressRequest
+		if args[1] != nil {
+			arg1 = args[1].(*livekit.RoomCompositeEgressRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLivekitEgressClient_StartRoomCompositeEgress_Call) Return(egressInfo *livekit.EgressInfo, err error) *MockLivekitEgressClient_StartRoomCompositeEgress_Call {
+	_c.Call.Return(egressInfo, err)
+	return _c
+}
+
+func (_c *MockLivekitEgressClient_StartRoomCompositeEgress_Call) RunAndReturn(run func(ctx context.Context, req *livekit.RoomCompositeEgressRequest) (*livekit.EgressInfo, error)) *MockLivekitEgressClient_StartRoomCompositeEgress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StopEgress provides a mock function for the type MockLivekitEgressClient
+func (_mock *MockLivekitEgressClient) StopEgress(ctx context.Context, req *livekit.StopEgressRequest) (*livekit.EgressInfo, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StopEgress")
+	}
+
+	var r0 *livekit.EgressInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *livekit.StopEgressRequest) (*livekit.EgressInfo, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *livekit.StopEgressRequest) *livekit.EgressInfo); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*livekit.EgressInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *livekit.StopEgressRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLivekitEgressClient_StopEgress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StopEgress'
+type MockLivekitEgressClient_StopEgress_Call struct {
+	*mock.Call
+}
+
+// StopEgress is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *livekit.StopEgressRequest
+func (_e *MockLivekitEgressClient_Expecter) StopEgress(ctx interface{}, req interface{}) *MockLivekitEgressClient_StopEgress_Call {
+	return &MockLivekitEgressClient_StopEgress_Call{Call: _e.mock.On("StopEgress", ctx, req)}
+}
+
+func (_c *MockLivekitEgressClient_StopEgress_Call) Run(run func(ctx context.Context, req *livekit.StopEgressRequest)) *MockLivekitEgressClient_StopEgress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *livekit.StopEgressRequest
+		if args[1] != nil {
+			arg1 = args[1].(*livekit.StopEgressRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLivekitEgressClient_StopEgress_Call) Return(egressInfo *livekit.EgressInfo, err error) *MockLivekitEgressClient_StopEgress_Call {
+	_c.Call.Return(egressInfo, err)
+	return _c
+}
+
+func (_c *MockLivekitEgressClient_StopEgress_Call) RunAndReturn(run func(ctx context.Context, req *livekit.StopEgressRequest) (*livekit.EgressInfo, error)) *MockLivekitEgressClient_StopEgress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockLivekitRoomClient creates a new instance of MockLivekitRoomClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockLivekitRoomClient(t interface {
@@ -309,3 +608,71 @@ func (_c *MockLivekitRoomClient_RemoveParticipant_Call) RunAndReturn(run func(ct
 	_c.Call.Return(run)
 	return _c
 }
+
+// UpdateParticipant provides a mock function for the type MockLivekitRoomClient
+func (_mock *MockLivekitRoomClient) UpdateParticipant(ctx context.Context, req *livekit.UpdateParticipantRequest) (*livekit.ParticipantInfo, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateParticipant")
+	}
+
+	var r0 *livekit.ParticipantInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *livekit.UpdateParticipantRequest) (*livekit.ParticipantInfo, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *livekit.UpdateParticipantRequest) *livekit.ParticipantInfo); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*livekit.ParticipantInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *livekit.UpdateParticipantRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLivekitRoomClient_UpdateParticipant_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateParticipant'
+type MockLivekitRoomClient_UpdateParticipant_Call struct {
+	*mock.Call
+}
+
+// UpdateParticipant is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *livekit.UpdateParticipantRequest
+func (_e *MockLivekitRoomClient_Expecter) UpdateParticipant(ctx interface{}, req interface{}) *MockLivekitRoomClient_UpdateParticipant_Call {
+	return &MockLivekitRoomClient_UpdateParticipant_Call{Call: _e.mock.On("UpdateParticipant", ctx, req)}
+}
+
+func (_c *MockLivekitRoomClient_UpdateParticipant_Call) Run(run func(ctx context.Context, req *livekit.UpdateParticipantRequest)) *MockLivekitRoomClient_UpdateParticipant_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *livekit.UpdateParticipantRequest
+		if args[1] != nil {
+			arg1 = args[1].(*livekit.UpdateParticipantRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLivekitRoomClient_UpdateParticipant_Call) Return(participantInfo *livekit.ParticipantInfo, err error) *MockLivekitRoomClient_UpdateParticipant_Call {
+	_c.Call.Return(participantInfo, err)
+	return _c
+}
+
+func (_c *MockLivekitRoomClient_UpdateParticipant_Call) RunAndReturn(run func(ctx context.Context, req *livekit.UpdateParticipantRequest) (*livekit.ParticipantInfo, error)) *MockLivekitRoomClient_UpdateParticipant_Call {
+	_c.Call.Return(run)
+	return _c
+}
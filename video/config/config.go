@@ -153,6 +153,7 @@ type ExtendedConfig struct {
 	RabbitMqConfig      RabbitMqConfig   `mapstructure:"rabbitmq"`
 	RestrictRecording   bool             `mapstructure:"restrictRecording"`
 	RecordPreset        string           `mapstructure:"recordPreset"`
+	RecordMode          string           `mapstructure:"recordMode"`
 	VideoTokenValidTime time.Duration    `mapstructure:"videoTokenValidTime"`
 	RedisConfig         RedisConfig      `mapstructure:"redis"`
 	OtlpConfig          OtlpConfig       `mapstructure:"otlp"`
@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/rotisserie/eris"
+	"nkonev.name/video/dto"
+	"time"
+)
+
+func (tx *Tx) CreateBreakoutRoom(ctx context.Context, chatId int64, name string, endsAt *time.Time) (int64, error) {
+	row := tx.QueryRowContext(ctx, `insert into breakout_room(chat_id, name, ends_at) values ($1, $2, $3) returning id`, chatId, name, endsAt)
+	if row.Err() != nil {
+		return 0, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		return 0, eris.Wrap(err, "error during scanning from db")
+	}
+	return id, nil
+}
+
+func provideScanToBreakoutRoom(br *dto.BreakoutRoom) []any {
+	return []any{
+		&br.Id,
+		&br.ChatId,
+		&br.Name,
+		&br.EndsAt,
+		&br.CreateDateTime,
+	}
+}
+
+func (tx *Tx) GetBreakoutRooms(ctx context.Context, chatId int64) ([]dto.BreakoutRoom, error) {
+	rows, err := tx.QueryContext(ctx, `select 
+			id,
+			chat_id,
+			name,
+			ends_at,
+			create_date_time
+		from breakout_room 
+		where chat_id = $1
+		order by id
+	`, chatId)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+	list := make([]dto.BreakoutRoom, 0)
+	for rows.Next() {
+		br := dto.BreakoutRoom{}
+		if err := rows.Scan(provideScanToBreakoutRoom(&br)[:]...); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		}
+		list = append(list, br)
+	}
+	return list, nil
+}
+
+// returns nil if there is no such room in the chat
+func (tx *Tx) GetBreakoutRoom(ctx context.Context, chatId, breakoutRoomId int64) (*dto.BreakoutRoom, error) {
+	row := tx.QueryRowContext(ctx, `select 
+			id,
+			chat_id,
+			name,
+			ends_at,
+			create_date_time
+		from breakout_room 
+		where (chat_id, id) = ($1, $2)
+	`, chatId, breakoutRoomId)
+	if row.Err() != nil {
+		return nil, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	br := dto.BreakoutRoom{}
+	err := row.Scan(provideScanToBreakoutRoom(&br)[:]...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// there were no rows, but otherwise no error occurred
+			return nil, nil
+		}
+		return nil, eris.Wrap(err, "error during scanning from db")
+	}
+	return &br, nil
+}
+
+func (tx *Tx) GetExpiredBreakoutRooms(ctx context.Context, limit int64) ([]dto.BreakoutRoom, error) {
+	rows, err := tx.QueryContext(ctx, `select 
+			id,
+			chat_id,
+			name,
+			ends_at,
+			create_date_time
+		from breakout_room 
+		where ends_at is not null and ends_at < utc_now()
+		order by ends_at
+		limit $1
+	`, limit)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+	list := make([]dto.BreakoutRoom, 0)
+	for rows.Next() {
+		br := dto.BreakoutRoom{}
+		if err := rows.Scan(provideScanToBreakoutRoom(&br)[:]...); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		}
+		list = append(list, br)
+	}
+	return list, nil
+}
+
+// participants are removed by cascade
+func (tx *Tx) RemoveBreakoutRoom(ctx context.Context, chatId, breakoutRoomId int64) error {
+	_, err := tx.ExecContext(ctx, `delete from breakout_room where (chat_id, id) = ($1, $2)`, chatId, breakoutRoomId)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// the user can be only in one breakout room of the chat, so it moves him from the previous one
+func (tx *Tx) AssignToBreakoutRoom(ctx context.Context, chatId, breakoutRoomId, userId int64) error {
+	_, err := tx.ExecContext(ctx, `
+		insert into breakout_room_participant(chat_id, user_id, breakout_room_id) values ($1, $2, $3) 
+		on conflict (chat_id, user_id) do update set breakout_room_id = $3
+	`, chatId, userId, breakoutRoomId)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+func (tx *Tx) UnassignFromBreakoutRoom(ctx context.Context, chatId, userId int64) error {
+	_, err := tx.ExecContext(ctx, `delete from breakout_room_participant where (chat_id, user_id) = ($1, $2)`, chatId, userId)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// returns breakoutRoomId -> userIds
+func (tx *Tx) GetBreakoutRoomParticipants(ctx context.Context, chatId int64) (map[int64][]int64, error) {
+	rows, err := tx.QueryContext(ctx, `select breakout_room_id, user_id from breakout_room_participant where chat_id = $1 order by user_id`, chatId)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+	ret := map[int64][]int64{}
+	for rows.Next() {
+		var breakoutRoomId, userId int64
+		if err := rows.Scan(&breakoutRoomId, &userId); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		}
+		ret[breakoutRoomId] = append(ret[breakoutRoomId], userId)
+	}
+	return ret, nil
+}
+
+// returns nil if the user isn't assigned to any breakout room of the chat
+func (tx *Tx) GetUserBreakoutRoomId(ctx context.Context, chatId, userId int64) (*int64, error) {
+	row := tx.QueryRowContext(ctx, `select breakout_room_id from breakout_room_participant where (chat_id, user_id) = ($1, $2)`, chatId, userId)
+	if row.Err() != nil {
+		return nil, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var breakoutRoomId int64
+	err := row.Scan(&breakoutRoomId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// there were no rows, but otherwise no error occurred
+			return nil, nil
+		}
+		return nil, eris.Wrap(err, "error during scanning from db")
+	}
+	return &breakoutRoomId, nil
+}
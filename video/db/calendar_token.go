@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+)
+
+// returns nil if the user hasn't got the calendar feed yet
+func (tx *Tx) GetCalendarToken(ctx context.Context, userId int64) (*uuid.UUID, error) {
+	row := tx.QueryRowContext(ctx, `select token from calendar_token where user_id = $1`, userId)
+	if row.Err() != nil {
+		return nil, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var token uuid.UUID
+	err := row.Scan(&token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// there were no rows, but otherwise no error occurred
+			return nil, nil
+		}
+		return nil, eris.Wrap(err, "error during scanning from db")
+	}
+	return &token, nil
+}
+
+// replaces the previous token, so the old feed url stops working
+func (tx *Tx) SetCalendarToken(ctx context.Context, userId int64, token uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `insert into calendar_token(user_id, token) values ($1, $2)
+		on conflict(user_id) do update set token = excluded.token, create_date_time = utc_now()`,
+		userId, token)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+func (tx *Tx) DeleteCalendarToken(ctx context.Context, userId int64) error {
+	_, err := tx.ExecContext(ctx, `delete from calendar_token where user_id = $1`, userId)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// returns nil if there is no such token, e.g. it was revoked
+func (tx *Tx) GetUserIdByCalendarToken(ctx context.Context, token uuid.UUID) (*int64, error) {
+	row := tx.QueryRowContext(ctx, `select user_id from calendar_token where token = $1`, token)
+	if row.Err() != nil {
+		return nil, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var userId int64
+	err := row.Scan(&userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// there were no rows, but otherwise no error occurred
+			return nil, nil
+		}
+		return nil, eris.Wrap(err, "error during scanning from db")
+	}
+	return &userId, nil
+}
@@ -170,6 +170,13 @@ func ConfigureDb(lgr *logger.Logger, lc fx.Lifecycle) (*DB, error) {
 func (db *DB) RecreateDb() {
 	_, err := db.Exec(fmt.Sprintf(`
 	drop table if exists user_call_state;
+	drop table if exists raised_hand;
+	drop table if exists moderated_room;
+	drop table if exists breakout_room_participant;
+	drop table if exists breakout_room;
+	drop table if exists scheduled_meeting_invitee;
+	drop table if exists scheduled_meeting;
+	drop table if exists user_availability;
 	drop table if exists %s;
 	drop table if exists %s;
 	
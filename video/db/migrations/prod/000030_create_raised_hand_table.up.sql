@@ -0,0 +1,16 @@
+create unlogged table raised_hand(
+    chat_id bigint not null,
+    user_id bigint not null,
+
+    has_floor boolean not null default false,
+
+    raised_at timestamp not null default utc_now(),
+
+    primary key (chat_id, user_id)
+);
+
+create unlogged table moderated_room(
+    chat_id bigint not null primary key,
+
+    create_date_time timestamp not null default utc_now()
+);
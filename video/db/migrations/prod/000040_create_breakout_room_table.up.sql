@@ -0,0 +1,20 @@
+create unlogged table breakout_room(
+    id bigserial primary key,
+    chat_id bigint not null,
+    name varchar(256) not null,
+
+    -- the room is closed automatically by scheduler after this moment
+    ends_at timestamp,
+
+    create_date_time timestamp not null default utc_now()
+);
+
+create index breakout_room_chat_id_idx on breakout_room(chat_id);
+
+create unlogged table breakout_room_participant(
+    chat_id bigint not null,
+    user_id bigint not null,
+    breakout_room_id bigint not null references breakout_room(id) on delete cascade,
+
+    primary key (chat_id, user_id)
+);
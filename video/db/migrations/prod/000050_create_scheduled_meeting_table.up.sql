@@ -0,0 +1,32 @@
+-- not unlogged because meetings are planned by users in advance and should survive the restart
+create table scheduled_meeting(
+    id bigserial primary key,
+    chat_id bigint not null,
+
+    owner_id bigint not null,
+    owner_login varchar(256) not null,
+    owner_avatar text,
+
+    title varchar(256) not null,
+    start_time timestamp not null,
+    duration_seconds bigint not null,
+    -- RFC 5545 recurrence rule without DTSTART, for example FREQ=WEEKLY;BYDAY=MO,WE
+    rrule text,
+
+    -- the start of the last occurrence which was processed by scheduler
+    last_reminded_at timestamp,
+    last_started_at timestamp,
+
+    create_date_time timestamp not null default utc_now()
+);
+
+create index scheduled_meeting_chat_id_idx on scheduled_meeting(chat_id);
+
+create table scheduled_meeting_invitee(
+    meeting_id bigint not null references scheduled_meeting(id) on delete cascade,
+    user_id bigint not null,
+
+    primary key (meeting_id, user_id)
+);
+
+create index scheduled_meeting_invitee_user_id_idx on scheduled_meeting_invitee(user_id);
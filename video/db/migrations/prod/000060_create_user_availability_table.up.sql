@@ -0,0 +1,12 @@
+-- not unlogged because it is the user's settings
+create table user_availability(
+    user_id bigint primary key,
+    do_not_disturb boolean not null default false,
+
+    -- minutes since the midnight in the user's time zone, null means there is no working hours schedule
+    working_hours_start smallint,
+    working_hours_end smallint,
+    -- bit mask where the bit 0 is Sunday and the bit 6 is Saturday
+    working_days smallint not null default 62,
+    time_zone varchar(64) not null default 'UTC'
+);
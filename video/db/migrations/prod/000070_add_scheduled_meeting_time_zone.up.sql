@@ -0,0 +1,10 @@
+-- the recurrence is expanded in the meeting's time zone, so the occurrences keep their local time across the daylight saving time changes
+alter table scheduled_meeting add column time_zone varchar(64) not null default 'UTC';
+
+-- the secret part of the calendar feed url, calendar applications can't pass the session
+-- the user revokes the feed by regenerating the token
+create table calendar_token(
+    user_id bigint primary key,
+    token uuid not null unique,
+    create_date_time timestamp not null default utc_now()
+);
@@ -0,0 +1,8 @@
+-- the start of the occurrence which the scheduler hasn't started yet, null when the meeting has passed or the series has ended
+-- the scheduler scans only the meetings whose next occurrence is due instead of all the recurring ones
+alter table scheduled_meeting add column next_occurrence timestamp;
+
+-- the scheduler moves it to the actual occurrence on the first scan
+update scheduled_meeting set next_occurrence = start_time;
+
+create index scheduled_meeting_next_occurrence_idx on scheduled_meeting(next_occurrence) where next_occurrence is not null;
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/rotisserie/eris"
+	"nkonev.name/video/dto"
+)
+
+// keeps the original raising time in case the hand is already raised
+func (tx *Tx) RaiseHand(ctx context.Context, chatId, userId int64) error {
+	_, err := tx.ExecContext(ctx, `
+		insert into raised_hand(chat_id, user_id) values ($1, $2) 
+		on conflict (chat_id, user_id) do nothing
+	`, chatId, userId)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// returns true if the hand was raised
+func (tx *Tx) LowerHand(ctx context.Context, chatId, userId int64) (bool, error) {
+	res, err := tx.ExecContext(ctx, `delete from raised_hand where (chat_id, user_id) = ($1, $2)`, chatId, userId)
+	if err != nil {
+		return false, eris.Wrap(err, "error during interacting with db")
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, eris.Wrap(err, "error during interacting with db")
+	}
+	return affected > 0, nil
+}
+
+// the user can be given the floor even without raising the hand
+func (tx *Tx) GrantFloor(ctx context.Context, chatId, userId int64) error {
+	_, err := tx.ExecContext(ctx, `
+		insert into raised_hand(chat_id, user_id, has_floor) values ($1, $2, true) 
+		on conflict (chat_id, user_id) do update set has_floor = true
+	`, chatId, userId)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+func (tx *Tx) HasFloor(ctx context.Context, chatId, userId int64) (bool, error) {
+	row := tx.QueryRowContext(ctx, `select has_floor from raised_hand where (chat_id, user_id) = ($1, $2)`, chatId, userId)
+	if row.Err() != nil {
+		return false, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var hasFloor bool
+	err := row.Scan(&hasFloor)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// there were no rows, but otherwise no error occurred
+			return false, nil
+		}
+		return false, eris.Wrap(err, "error during scanning from db")
+	}
+	return hasFloor, nil
+}
+
+func (tx *Tx) GetRaisedHands(ctx context.Context, chatId int64) ([]dto.VideoRaisedHandDto, error) {
+	rows, err := tx.QueryContext(ctx, `select 
+			user_id,
+			has_floor,
+			raised_at
+		from raised_hand 
+		where chat_id = $1
+		order by raised_at, user_id
+	`, chatId)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+	list := make([]dto.VideoRaisedHandDto, 0)
+	for rows.Next() {
+		hand := dto.VideoRaisedHandDto{}
+		if err := rows.Scan(&hand.UserId, &hand.HasFloor, &hand.RaisedAt); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		}
+		list = append(list, hand)
+	}
+	return list, nil
+}
+
+func (tx *Tx) RemoveRaisedHands(ctx context.Context, chatId int64) error {
+	_, err := tx.ExecContext(ctx, `delete from raised_hand where chat_id = $1`, chatId)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+func (tx *Tx) IsModerated(ctx context.Context, chatId int64) (bool, error) {
+	row := tx.QueryRowContext(ctx, `select exists(select * from moderated_room where chat_id = $1)`, chatId)
+	if row.Err() != nil {
+		return false, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var moderated bool
+	if err := row.Scan(&moderated); err != nil {
+		return false, eris.Wrap(err, "error during scanning from db")
+	}
+	return moderated, nil
+}
+
+func (tx *Tx) SetModerated(ctx context.Context, chatId int64, moderated bool) error {
+	var err error
+	if moderated {
+		_, err = tx.ExecContext(ctx, `insert into moderated_room(chat_id) values ($1) on conflict (chat_id) do nothing`, chatId)
+	} else {
+		_, err = tx.ExecContext(ctx, `delete from moderated_room where chat_id = $1`, chatId)
+	}
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/rotisserie/eris"
+	"nkonev.name/video/dto"
+	"time"
+)
+
+const scheduledMeetingColumns = `
+			id,
+			chat_id,
+			owner_id,
+			owner_login,
+			owner_avatar,
+			title,
+			start_time,
+			duration_seconds,
+			rrule,
+			time_zone,
+			last_reminded_at,
+			last_started_at,
+			next_occurrence,
+			create_date_time
+`
+
+func provideScanToScheduledMeeting(m *dto.ScheduledMeeting) []any {
+	return []any{
+		&m.Id,
+		&m.ChatId,
+		&m.OwnerId,
+		&m.OwnerLogin,
+		&m.OwnerAvatar,
+		&m.Title,
+		&m.StartTime,
+		&m.DurationSeconds,
+		&m.Rrule,
+		&m.TimeZone,
+		&m.LastRemindedAt,
+		&m.LastStartedAt,
+		&m.NextOccurrence,
+		&m.CreateDateTime,
+	}
+}
+
+func scanScheduledMeetings(rows *sql.Rows) ([]dto.ScheduledMeeting, error) {
+	defer rows.Close()
+	list := make([]dto.ScheduledMeeting, 0)
+	for rows.Next() {
+		m := dto.ScheduledMeeting{}
+		if err := rows.Scan(provideScanToScheduledMeeting(&m)[:]...); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		}
+		list = append(list, m)
+	}
+	return list, nil
+}
+
+func (tx *Tx) CreateScheduledMeeting(ctx context.Context, m *dto.ScheduledMeeting) (int64, error) {
+	row := tx.QueryRowContext(ctx, `insert into scheduled_meeting(chat_id, owner_id, owner_login, owner_avatar, title, start_time, duration_seconds, rrule, time_zone, next_occurrence) 
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) 
+		returning id`,
+		m.ChatId, m.OwnerId, m.OwnerLogin, m.OwnerAvatar, m.Title, m.StartTime, m.DurationSeconds, m.Rrule, m.TimeZone, m.NextOccurrence)
+	if row.Err() != nil {
+		return 0, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		return 0, eris.Wrap(err, "error during scanning from db")
+	}
+	return id, nil
+}
+
+// resets the processed occurrences because the schedule could be changed
+func (tx *Tx) UpdateScheduledMeeting(ctx context.Context, m *dto.ScheduledMeeting) error {
+	_, err := tx.ExecContext(ctx, `update scheduled_meeting 
+		set title = $3, start_time = $4, duration_seconds = $5, rrule = $6, time_zone = $7, next_occurrence = $8, last_reminded_at = null, last_started_at = null 
+		where (chat_id, id) = ($1, $2)`,
+		m.ChatId, m.Id, m.Title, m.StartTime, m.DurationSeconds, m.Rrule, m.TimeZone, m.NextOccurrence)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// invitees are removed by cascade
+func (tx *Tx) DeleteScheduledMeeting(ctx context.Context, chatId, meetingId int64) error {
+	_, err := tx.ExecContext(ctx, `delete from scheduled_meeting where (chat_id, id) = ($1, $2)`, chatId, meetingId)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// returns nil if there is no such meeting in the chat
+func (tx *Tx) GetScheduledMeeting(ctx context.Context, chatId, meetingId int64) (*dto.ScheduledMeeting, error) {
+	row := tx.QueryRowContext(ctx, `select `+scheduledMeetingColumns+` from scheduled_meeting where (chat_id, id) = ($1, $2)`, chatId, meetingId)
+	if row.Err() != nil {
+		return nil, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	m := dto.ScheduledMeeting{}
+	err := row.Scan(provideScanToScheduledMeeting(&m)[:]...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// there were no rows, but otherwise no error occurred
+			return nil, nil
+		}
+		return nil, eris.Wrap(err, "error during scanning from db")
+	}
+	return &m, nil
+}
+
+func (tx *Tx) GetScheduledMeetings(ctx context.Context, chatId int64) ([]dto.ScheduledMeeting, error) {
+	rows, err := tx.QueryContext(ctx, `select `+scheduledMeetingColumns+` from scheduled_meeting where chat_id = $1 order by start_time, id`, chatId)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	return scanScheduledMeetings(rows)
+}
+
+// meetings where the user is either the owner or the invitee
+func (tx *Tx) GetScheduledMeetingsOfUser(ctx context.Context, userId int64) ([]dto.ScheduledMeeting, error) {
+	rows, err := tx.QueryContext(ctx, `select `+scheduledMeetingColumns+` from scheduled_meeting 
+		where owner_id = $1 or id in (select meeting_id from scheduled_meeting_invitee where user_id = $1) 
+		order by start_time, id`, userId)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	return scanScheduledMeetings(rows)
+}
+
+// candidates for reminding or starting: the meetings whose next occurrence is due, they are paged by id because the processing moves the next occurrence
+func (tx *Tx) GetScheduledMeetingsToProcess(ctx context.Context, dueBefore time.Time, afterId, limit int64) ([]dto.ScheduledMeeting, error) {
+	rows, err := tx.QueryContext(ctx, `select `+scheduledMeetingColumns+` from scheduled_meeting 
+		where next_occurrence <= $1 and id > $2 
+		order by id 
+		limit $3`, dueBefore, afterId, limit)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	return scanScheduledMeetings(rows)
+}
+
+func (tx *Tx) SetScheduledMeetingReminded(ctx context.Context, meetingId int64, occurrence time.Time) error {
+	_, err := tx.ExecContext(ctx, `update scheduled_meeting set last_reminded_at = $2 where id = $1`, meetingId, occurrence)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// nextOccurrence is the one after the started, nil when the series has ended
+func (tx *Tx) SetScheduledMeetingStarted(ctx context.Context, meetingId int64, occurrence time.Time, nextOccurrence *time.Time) error {
+	_, err := tx.ExecContext(ctx, `update scheduled_meeting set last_started_at = $2, next_occurrence = $3 where id = $1`, meetingId, occurrence, nextOccurrence)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+func (tx *Tx) SetScheduledMeetingNextOccurrence(ctx context.Context, meetingId int64, nextOccurrence *time.Time) error {
+	_, err := tx.ExecContext(ctx, `update scheduled_meeting set next_occurrence = $2 where id = $1`, meetingId, nextOccurrence)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+func (tx *Tx) SetScheduledMeetingInvitees(ctx context.Context, meetingId int64, userIds []int64) error {
+	_, err := tx.ExecContext(ctx, `delete from scheduled_meeting_invitee where meeting_id = $1`, meetingId)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	for _, userId := range userIds {
+		_, err = tx.ExecContext(ctx, `insert into scheduled_meeting_invitee(meeting_id, user_id) values ($1, $2) on conflict do nothing`, meetingId, userId)
+		if err != nil {
+			return eris.Wrap(err, "error during interacting with db")
+		}
+	}
+	return nil
+}
+
+// returns meetingId -> userIds
+func (tx *Tx) GetScheduledMeetingInvitees(ctx context.Context, meetingIds []int64) (map[int64][]int64, error) {
+	ret := map[int64][]int64{}
+	if len(meetingIds) == 0 {
+		return ret, nil
+	}
+	rows, err := tx.QueryContext(ctx, `select meeting_id, user_id from scheduled_meeting_invitee where meeting_id = any($1) order by user_id`, meetingIds)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var meetingId, userId int64
+		if err := rows.Scan(&meetingId, &userId); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		}
+		ret[meetingId] = append(ret[meetingId], userId)
+	}
+	return ret, nil
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/rotisserie/eris"
+	"nkonev.name/video/dto"
+)
+
+const DefaultWorkingDays = 62 // Monday - Friday
+
+const DefaultTimeZone = "UTC"
+
+func provideScanToUserAvailability(a *dto.UserAvailability) []any {
+	return []any{
+		&a.UserId,
+		&a.DoNotDisturb,
+		&a.WorkingHoursStart,
+		&a.WorkingHoursEnd,
+		&a.WorkingDays,
+		&a.TimeZone,
+	}
+}
+
+func (tx *Tx) SetUserAvailability(ctx context.Context, a dto.UserAvailability) error {
+	_, err := tx.ExecContext(ctx, `insert into user_availability(user_id, do_not_disturb, working_hours_start, working_hours_end, working_days, time_zone) 
+		values ($1, $2, $3, $4, $5, $6) 
+		on conflict(user_id) do update set 
+			do_not_disturb = excluded.do_not_disturb, 
+			working_hours_start = excluded.working_hours_start, 
+			working_hours_end = excluded.working_hours_end, 
+			working_days = excluded.working_days, 
+			time_zone = excluded.time_zone`,
+		a.UserId, a.DoNotDisturb, a.WorkingHoursStart, a.WorkingHoursEnd, a.WorkingDays, a.TimeZone)
+	if err != nil {
+		return eris.Wrap(err, "error during interacting with db")
+	}
+	return nil
+}
+
+// returns nil if the user hasn't set up their availability
+func (tx *Tx) GetUserAvailability(ctx context.Context, userId int64) (*dto.UserAvailability, error) {
+	row := tx.QueryRowContext(ctx, `select user_id, do_not_disturb, working_hours_start, working_hours_end, working_days, time_zone from user_availability where user_id = $1`, userId)
+	if row.Err() != nil {
+		return nil, eris.Wrap(row.Err(), "error during interacting with db")
+	}
+	a := dto.UserAvailability{}
+	err := row.Scan(provideScanToUserAvailability(&a)[:]...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// there were no rows, but otherwise no error occurred
+			return nil, nil
+		}
+		return nil, eris.Wrap(err, "error during scanning from db")
+	}
+	return &a, nil
+}
+
+// users who haven't set up their availability are absent in the result
+func (tx *Tx) GetUserAvailabilities(ctx context.Context, userIds []int64) ([]dto.UserAvailability, error) {
+	list := make([]dto.UserAvailability, 0)
+	if len(userIds) == 0 {
+		return list, nil
+	}
+	rows, err := tx.QueryContext(ctx, `select user_id, do_not_disturb, working_hours_start, working_hours_end, working_days, time_zone from user_availability where user_id = any($1) order by user_id`, userIds)
+	if err != nil {
+		return nil, eris.Wrap(err, "error during interacting with db")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a := dto.UserAvailability{}
+		if err := rows.Scan(provideScanToUserAvailability(&a)[:]...); err != nil {
+			return nil, eris.Wrap(err, "error during interacting with db")
+		}
+		list = append(list, a)
+	}
+	return list, nil
+}
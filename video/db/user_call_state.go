@@ -21,6 +21,8 @@ const CallStatusCancelling = "cancelling"     // will be removed after some time
 
 const CallStatusRemoving = "removing" // will be removed after some time automatically by scheduler
 
+const CallStatusBusy = "busy" // the callee is in do-not-disturb mode or in another call, will be removed after some time automatically by scheduler
+
 const CallStatusNotFound = ""
 
 const UserCallMarkedForRemoveAtNotSet = 0
@@ -33,13 +35,14 @@ const NoTetATet = false
 
 // aka Should be changed Automatically After Timeout
 func IsTemporary(userCallStatus string) bool {
-	return userCallStatus == CallStatusCancelling || userCallStatus == CallStatusRemoving
+	return userCallStatus == CallStatusCancelling || userCallStatus == CallStatusRemoving || userCallStatus == CallStatusBusy
 }
 
 func getTemporaryStates() []string {
 	return []string{
 		CallStatusCancelling,
 		CallStatusRemoving,
+		CallStatusBusy,
 	}
 }
 
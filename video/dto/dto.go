@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type VideoInviteDto struct {
 	ChatId       int64   `json:"chatId"`
 	UserIds      []int64 `json:"userIds"`
@@ -8,8 +10,9 @@ type VideoInviteDto struct {
 }
 
 type VideoCallUserCountChangedDto struct {
-	UsersCount int64 `json:"usersCount"`
-	ChatId     int64 `json:"chatId"`
+	UsersCount    int64             `json:"usersCount"`
+	ChatId        int64             `json:"chatId"`
+	BreakoutRooms []BreakoutRoomDto `json:"breakoutRooms,omitempty"`
 }
 
 type VideoCallUsersCallStatusChangedDto struct {
@@ -66,16 +69,81 @@ type VideoDialChanges struct {
 }
 
 type S3Response struct {
-	AccessKey string            `json:"accessKey"`
-	Secret    string            `json:"secret"`
-	Region    string            `json:"region"`
-	Endpoint  string            `json:"endpoint"`
-	Bucket    string            `json:"bucket"`
-	Metadata  map[string]string `json:"metadata"`
-	Filepath  string            `json:"filepath"`
+	AccessKey    string            `json:"accessKey"`
+	Secret       string            `json:"secret"`
+	Region       string            `json:"region"`
+	Endpoint     string            `json:"endpoint"`
+	Bucket       string            `json:"bucket"`
+	Metadata     map[string]string `json:"metadata"`
+	Filepath     string            `json:"filepath"`
+	FileItemUuid string            `json:"fileItemUuid"`
 }
 
 type BasicChatDto struct {
 	TetATet        bool    `json:"tetATet"`
 	ParticipantIds []int64 `json:"participantIds"`
 }
+
+type VideoRaisedHandDto struct {
+	UserId   int64     `json:"userId"`
+	HasFloor bool      `json:"hasFloor"`
+	RaisedAt time.Time `json:"raisedAt"`
+}
+
+// the queue of raised hands, ordered by the raising time
+type VideoRaisedHandsChangedDto struct {
+	ChatId    int64                `json:"chatId"`
+	Moderated bool                 `json:"moderated"`
+	Hands     []VideoRaisedHandDto `json:"hands"`
+}
+
+type BreakoutRoomDto struct {
+	Id         int64      `json:"id"`
+	Name       string     `json:"name"`
+	EndsAt     *time.Time `json:"endsAt"`
+	UserIds    []int64    `json:"userIds"`    // assigned users
+	UsersCount int64      `json:"usersCount"` // users who are in the room at the moment
+}
+
+type VideoBreakoutRoomsChangedDto struct {
+	ChatId        int64             `json:"chatId"`
+	BreakoutRooms []BreakoutRoomDto `json:"breakoutRooms"`
+}
+
+// the user has to reconnect to the another room of the chat's call
+type VideoRoomChangedDto struct {
+	ChatId           int64   `json:"chatId"`
+	BreakoutRoomId   *int64  `json:"breakoutRoomId"` // nil means the main room
+	BreakoutRoomName *string `json:"breakoutRoomName"`
+}
+
+type ScheduledMeetingDto struct {
+	Id              int64      `json:"id"`
+	ChatId          int64      `json:"chatId"`
+	OwnerId         int64      `json:"ownerId"`
+	Title           string     `json:"title"`
+	StartTime       time.Time  `json:"startTime"`
+	DurationSeconds int64      `json:"durationSeconds"`
+	Rrule           *string    `json:"rrule"`    // RFC 5545 recurrence rule, for example FREQ=WEEKLY;BYDAY=MO,WE
+	TimeZone        string     `json:"timeZone"` // IANA name, the recurrence is expanded in it
+	InviteeIds      []int64    `json:"inviteeIds"`
+	NextOccurrence  *time.Time `json:"nextOccurrence"` // nil when the meeting has already passed
+}
+
+type WorkingHoursDto struct {
+	Start string         `json:"start"` // 09:00
+	End   string         `json:"end"`   // 18:00, can be less than start for the night shifts
+	Days  []time.Weekday `json:"days"`  // 0 is Sunday
+}
+
+type UserAvailabilityDto struct {
+	DoNotDisturb bool             `json:"doNotDisturb"`
+	WorkingHours *WorkingHoursDto `json:"workingHours"`
+	TimeZone     string           `json:"timeZone"` // IANA name, for example Europe/Berlin
+	Available    bool             `json:"available"`
+}
+
+type UserAvailabilityStatusDto struct {
+	UserId int64  `json:"userId"`
+	Status string `json:"status"`
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type GlobalUserEvent struct {
 	EventType                 string                        `json:"eventType"`
 	UserId                    int64                         `json:"userId"`
@@ -10,18 +12,40 @@ type GlobalUserEvent struct {
 	VideoCallScreenShareChangedDto *VideoCallScreenShareChangedDto `json:"videoCallScreenShareChangedDto"`
 }
 
+type ChatEvent struct {
+	EventType          string                        `json:"eventType"`
+	ChatId             int64                         `json:"chatId"`
+	UserId             int64                         `json:"userId"`
+	RaisedHandsEvent   *VideoRaisedHandsChangedDto   `json:"raisedHandsEvent"`
+	BreakoutRoomsEvent *VideoBreakoutRoomsChangedDto `json:"breakoutRoomsEvent"`
+	RoomChangedEvent   *VideoRoomChangedDto          `json:"roomChangedEvent"`
+}
+
 type MissedCallNotification struct {
 	Description string `json:"description"`
 }
 
+type CallInvitationNotification struct {
+	Description string `json:"description"`
+}
+
+type MeetingReminderNotification struct {
+	MeetingId   int64     `json:"meetingId"`
+	Description string    `json:"description"`
+	StartTime   time.Time `json:"startTime"`
+}
+
 type NotificationEvent struct {
-	EventType              string                  `json:"eventType"`
-	ChatId                 int64                   `json:"chatId"`
-	UserId                 int64                   `json:"userId"`
-	ByUserId               int64                   `json:"byUserId"`
-	ByLogin                string                  `json:"byLogin"`
-	ByAvatar               *string                 `json:"byAvatar"`
-	MissedCallNotification *MissedCallNotification `json:"missedCallNotification"`
+	EventType                   string                       `json:"eventType"`
+	ChatId                      int64                        `json:"chatId"`
+	UserId                      int64                        `json:"userId"`
+	ByUserId                    int64                        `json:"byUserId"`
+	ByLogin                     string                       `json:"byLogin"`
+	ByAvatar                    *string                      `json:"byAvatar"`
+	ChatTitle                   string                       `json:"chatTitle"`
+	MissedCallNotification      *MissedCallNotification      `json:"missedCallNotification"`
+	MeetingReminderNotification *MeetingReminderNotification `json:"meetingReminderNotification"`
+	CallInvitationNotification  *CallInvitationNotification  `json:"callInvitationNotification"`
 }
 
 type GeneralEvent struct {
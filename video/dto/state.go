@@ -18,7 +18,7 @@ type UserCallState struct {
 
 	TokenTaken bool
 
-	// Owner* fields are set only when Status == CallStatusBeingInvited, CallStatusCancelling, CallStatusRemoving
+	// Owner* fields are set only when Status == CallStatusBeingInvited, CallStatusCancelling, CallStatusRemoving, CallStatusBusy
 	OwnerTokenId *uuid.UUID
 	OwnerUserId *int64
 
@@ -36,3 +36,46 @@ type UserCallState struct {
 
 	CreateDateTime time.Time
 }
+
+type BreakoutRoom struct {
+	Id     int64
+	ChatId int64
+	Name   string
+
+	EndsAt *time.Time
+
+	CreateDateTime time.Time
+}
+
+type ScheduledMeeting struct {
+	Id     int64
+	ChatId int64
+
+	OwnerId     int64
+	OwnerLogin  string
+	OwnerAvatar *string
+
+	Title           string
+	StartTime       time.Time
+	DurationSeconds int64
+	Rrule           *string
+	TimeZone        string
+
+	LastRemindedAt *time.Time
+	LastStartedAt  *time.Time
+	NextOccurrence *time.Time // nil when the meeting has passed
+
+	CreateDateTime time.Time
+}
+
+type UserAvailability struct {
+	UserId       int64
+	DoNotDisturb bool
+
+	// minutes since the midnight in TimeZone
+	WorkingHoursStart *int
+	WorkingHoursEnd   *int
+	// bit mask of time.Weekday
+	WorkingDays int
+	TimeZone    string
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"nkonev.name/video/auth"
+	"nkonev.name/video/dto"
+	"nkonev.name/video/logger"
+	"nkonev.name/video/services"
+	"nkonev.name/video/utils"
+	"strings"
+)
+
+type AvailabilityHandler struct {
+	availabilityService *services.AvailabilityService
+	lgr                 *logger.Logger
+}
+
+func NewAvailabilityHandler(availabilityService *services.AvailabilityService, lgr *logger.Logger) *AvailabilityHandler {
+	return &AvailabilityHandler{availabilityService: availabilityService, lgr: lgr}
+}
+
+func (h *AvailabilityHandler) GetAvailability(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	availability, err := h.availabilityService.GetAvailability(c.Request().Context(), userPrincipalDto.UserId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting availability: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, availability)
+}
+
+func (h *AvailabilityHandler) SetAvailability(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	reqDto := new(dto.UserAvailabilityDto)
+	err := c.Bind(reqDto)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Infof("Unable to bind availability: %v", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	availability, err := h.availabilityService.SetAvailability(c.Request().Context(), userPrincipalDto.UserId, reqDto)
+	if errors.Is(err, services.ErrInvalidAvailability) {
+		return c.NoContent(http.StatusBadRequest)
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during setting availability: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, availability)
+}
+
+// the caller can check whether the users are able to answer before calling them
+func (h *AvailabilityHandler) GetStatuses(c echo.Context) error {
+	userIds := make([]int64, 0)
+	for _, us := range strings.Split(c.QueryParam("userId"), ",") {
+		if us == "" {
+			continue
+		}
+		userId, err := utils.ParseInt64(us)
+		if err != nil {
+			return c.NoContent(http.StatusBadRequest)
+		}
+		if !utils.Contains(userIds, userId) {
+			userIds = append(userIds, userId)
+		}
+	}
+	if len(userIds) > utils.DefaultSize {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	statuses, err := h.availabilityService.GetStatuses(c.Request().Context(), userIds)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting availability statuses: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, statuses)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"nkonev.name/video/auth"
+	"nkonev.name/video/client"
+	"nkonev.name/video/logger"
+	"nkonev.name/video/services"
+	"nkonev.name/video/utils"
+	"time"
+)
+
+type BreakoutRoomHandler struct {
+	chatClient          *client.RestClient
+	breakoutRoomService *services.BreakoutRoomService
+	lgr                 *logger.Logger
+}
+
+type CreateBreakoutRoomsRequest struct {
+	Names           []string `json:"names"`
+	DurationSeconds *int64   `json:"durationSeconds"`
+}
+
+type BreakoutRoomAssignment struct {
+	UserId int64 `json:"userId"`
+	// nil means the main room
+	BreakoutRoomId *int64 `json:"breakoutRoomId"`
+}
+
+type AssignBreakoutRoomsRequest struct {
+	Assignments []BreakoutRoomAssignment `json:"assignments"`
+}
+
+func NewBreakoutRoomHandler(chatClient *client.RestClient, breakoutRoomService *services.BreakoutRoomService, lgr *logger.Logger) *BreakoutRoomHandler {
+	return &BreakoutRoomHandler{chatClient: chatClient, breakoutRoomService: breakoutRoomService, lgr: lgr}
+}
+
+func (h *BreakoutRoomHandler) GetBreakoutRooms(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return err
+	}
+	if ok, err := h.chatClient.CheckAccess(c.Request().Context(), userPrincipalDto.UserId, chatId); err != nil {
+		return c.NoContent(http.StatusInternalServerError)
+	} else if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	breakoutRooms, err := h.breakoutRoomService.GetBreakoutRooms(c.Request().Context(), chatId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting breakout rooms: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, breakoutRooms)
+}
+
+func (h *BreakoutRoomHandler) CreateBreakoutRooms(c echo.Context) error {
+	chatId, ok, err := h.checkIsAdmin(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	reqDto := new(CreateBreakoutRoomsRequest)
+	err = c.Bind(reqDto)
+	if err != nil {
+		return err
+	}
+	if len(reqDto.Names) == 0 {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var duration *time.Duration
+	if reqDto.DurationSeconds != nil {
+		if *reqDto.DurationSeconds <= 0 {
+			return c.NoContent(http.StatusBadRequest)
+		}
+		d := time.Duration(*reqDto.DurationSeconds) * time.Second
+		duration = &d
+	}
+
+	err = h.breakoutRoomService.CreateBreakoutRooms(c.Request().Context(), chatId, reqDto.Names, duration)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during creating breakout rooms: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// assigns the users either from the body or randomly in case random=true
+func (h *BreakoutRoomHandler) Assign(c echo.Context) error {
+	chatId, ok, err := h.checkIsAdmin(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	var userPrincipalDto = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+
+	random, _ := utils.ParseBoolean(c.QueryParam("random"))
+	if random {
+		// the host stays in the main room
+		err = h.breakoutRoomService.AssignRandomly(c.Request().Context(), chatId, []int64{userPrincipalDto.UserId})
+	} else {
+		reqDto := new(AssignBreakoutRoomsRequest)
+		err = c.Bind(reqDto)
+		if err != nil {
+			return err
+		}
+		assignments := map[int64]*int64{}
+		for _, assignment := range reqDto.Assignments {
+			assignments[assignment.UserId] = assignment.BreakoutRoomId
+		}
+		err = h.breakoutRoomService.Assign(c.Request().Context(), chatId, assignments)
+	}
+	if errors.Is(err, services.ErrBreakoutRoomNotFound) {
+		return c.NoContent(http.StatusNotFound)
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during assigning to breakout rooms: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *BreakoutRoomHandler) Close(c echo.Context) error {
+	chatId, ok, err := h.checkIsAdmin(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	breakoutRoomId, err := utils.ParseInt64(c.Param("breakoutRoomId"))
+	if err != nil {
+		return err
+	}
+
+	err = h.breakoutRoomService.Close(c.Request().Context(), chatId, breakoutRoomId)
+	if errors.Is(err, services.ErrBreakoutRoomNotFound) {
+		return c.NoContent(http.StatusNotFound)
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during closing breakout room: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *BreakoutRoomHandler) CloseAll(c echo.Context) error {
+	chatId, ok, err := h.checkIsAdmin(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	err = h.breakoutRoomService.CloseAll(c.Request().Context(), chatId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during closing breakout rooms: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *BreakoutRoomHandler) checkIsAdmin(c echo.Context) (int64, bool, error) {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return 0, false, errors.New("Error during getting auth context")
+	}
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return 0, false, err
+	}
+	isAdmin, err := h.chatClient.IsAdmin(c.Request().Context(), userPrincipalDto.UserId, chatId)
+	if err != nil {
+		return 0, false, err
+	}
+	return chatId, isAdmin, nil
+}
@@ -17,30 +17,31 @@ import (
 	"nkonev.name/video/services"
 	"nkonev.name/video/utils"
 	"strings"
+	"time"
 )
 
 type InviteHandler struct {
 	database                 *db.DB
 	chatClient               *client.RestClient
 	dialStatusPublisher      *producer.RabbitDialStatusPublisher
-	notificationPublisher    *producer.RabbitNotificationsPublisher
 	invitePublisher          *producer.RabbitInvitePublisher
 	userService              *services.UserService
 	stateChangedEventService *services.StateChangedEventService
+	breakoutRoomService      *services.BreakoutRoomService
+	availabilityService      *services.AvailabilityService
 	config                   *config.ExtendedConfig
 	lgr                      *logger.Logger
 }
 
-const EventMissedCall = "missed_call"
-
 func NewInviteHandler(
 	database *db.DB,
 	chatClient *client.RestClient,
 	dialStatusPublisher *producer.RabbitDialStatusPublisher,
-	notificationPublisher *producer.RabbitNotificationsPublisher,
 	invitePublisher *producer.RabbitInvitePublisher,
 	userService *services.UserService,
 	stateChangedEventService *services.StateChangedEventService,
+	breakoutRoomService *services.BreakoutRoomService,
+	availabilityService *services.AvailabilityService,
 	config *config.ExtendedConfig,
 	lgr *logger.Logger,
 ) *InviteHandler {
@@ -48,10 +49,11 @@ func NewInviteHandler(
 		database:                 database,
 		chatClient:               chatClient,
 		dialStatusPublisher:      dialStatusPublisher,
-		notificationPublisher:    notificationPublisher,
 		invitePublisher:          invitePublisher,
 		userService:              userService,
 		stateChangedEventService: stateChangedEventService,
+		breakoutRoomService:      breakoutRoomService,
+		availabilityService:      availabilityService,
 		config:                   config,
 		lgr:                      lgr,
 	}
@@ -166,17 +168,31 @@ func (vh *InviteHandler) addAsCallee(c context.Context, tx *db.Tx, calleeUserId
 	}
 
 	var ucss []dto.UserCallState = make([]dto.UserCallState, 0)
+	var inAnotherCall = false
 	for _, gotStatus := range gotStatusesAllChats {
-		if !db.CanOverrideCallStatus(gotStatus.Status) {
+		if gotStatus.Status == db.CallStatusInCall && gotStatus.ChatId != chatId {
+			inAnotherCall = true
+		} else if !db.CanOverrideCallStatus(gotStatus.Status) {
 			vh.lgr.WithTracing(c).Infof("Unable to invite somebody with non-overridable status")
 			return http.StatusConflict
 		} else {
 			ucss = append(ucss, gotStatus)
 		}
 	}
+
+	unavailable, err := vh.availabilityService.IsUnavailable(c, tx, calleeUserId)
+	if err != nil {
+		vh.lgr.WithTracing(c).Errorf("Error %v", err)
+		return http.StatusInternalServerError
+	}
+
 	// we remove callee's previous inviting - only after CanOverrideCallStatus() check
 	vh.hardRemove(c, tx, ucss)
 
+	if inAnotherCall || unavailable {
+		return vh.addAsBusyCallee(c, tx, calleeUserId, chatId, userPrincipalDto, ownerTokenId, tetATet, unavailable)
+	}
+
 	var newCalleeStatus = dto.UserCallState{
 		TokenId:      uuid.New(),
 		UserId:       calleeUserId,
@@ -198,6 +214,39 @@ func (vh *InviteHandler) addAsCallee(c context.Context, tx *db.Tx, calleeUserId
 
 	// for better user experience
 	vh.sendEvents(c, chatId, calleeUserId, db.CallStatusBeingInvited, userPrincipalDto.UserId, userPrincipalDto.Avatar, tetATet)
+	vh.availabilityService.SendCallInvitationNotifications(c, chatId, userPrincipalDto.UserId, userPrincipalDto.UserLogin, &userPrincipalDto.Avatar, []int64{calleeUserId})
+
+	return http.StatusOK
+}
+
+// the callee isn't rung, the caller sees them as busy until the scheduler removes this temporary status
+func (vh *InviteHandler) addAsBusyCallee(c context.Context, tx *db.Tx, calleeUserId int64, chatId int64, userPrincipalDto *auth.AuthResult, ownerTokenId uuid.UUID, tetATet bool, doNotDisturb bool) int {
+	now := time.Now().UTC()
+	var newCalleeStatus = dto.UserCallState{
+		TokenId:           uuid.New(),
+		UserId:            calleeUserId,
+		ChatId:            chatId,
+		TokenTaken:        false,
+		OwnerTokenId:      &ownerTokenId,
+		OwnerUserId:       &userPrincipalDto.UserId,
+		Status:            db.CallStatusBusy,
+		ChatTetATet:       tetATet,
+		OwnerAvatar:       &userPrincipalDto.Avatar,
+		MarkedForRemoveAt: &now,
+	}
+
+	err := tx.Set(c, newCalleeStatus)
+	if err != nil {
+		vh.lgr.WithTracing(c).Errorf("Error %v", err)
+		return http.StatusInternalServerError
+	}
+
+	vh.sendEvents(c, chatId, calleeUserId, db.CallStatusBusy, userPrincipalDto.UserId, userPrincipalDto.Avatar, tetATet)
+
+	// the user in do-not-disturb mode gets to know about the call afterwards
+	if doNotDisturb {
+		vh.availabilityService.SendMissedCallNotifications(c, chatId, userPrincipalDto.UserId, userPrincipalDto.UserLogin, &userPrincipalDto.Avatar, []int64{calleeUserId})
+	}
 
 	return http.StatusOK
 }
@@ -251,7 +300,12 @@ func (vh *InviteHandler) ProcessEnterToDial(c echo.Context) error {
 	// https://github.com/nkonev/videochat/blob/8fd81bccbe5f552de1ca123e2ba855dfe814cf66/development.md#generate-livekit-token
 	aKey := vh.config.LivekitConfig.Api.Key
 	aSecret := vh.config.LivekitConfig.Api.Secret
-	aRoomId := utils.GetRoomNameFromId(chatId)
+	// the user assigned to the breakout room gets the token for it instead of the main room
+	aRoomId, err := vh.breakoutRoomService.GetRoomNameForUser(c.Request().Context(), chatId, userPrincipalDto.UserId)
+	if err != nil {
+		vh.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting room name, userId=%v, chatId=%v, error=%v", userPrincipalDto.UserId, chatId, err)
+		return err
+	}
 
 	var tokenId uuid.UUID
 	var token string
@@ -367,8 +421,9 @@ func (vh *InviteHandler) ProcessEnterToDial(c echo.Context) error {
 	}
 
 	return c.JSON(http.StatusOK, TokenResponse{
-		TokenId: tokenId,
-		Token:   token,
+		TokenId:  tokenId,
+		Token:    token,
+		RoomName: aRoomId,
 	})
 }
 
@@ -399,8 +454,9 @@ func (vh *InviteHandler) getOppositeUserOfTetATetIfPossible(c context.Context, b
 }
 
 type TokenResponse struct {
-	TokenId uuid.UUID `json:"tokenId"`
-	Token   string    `json:"token"`
+	TokenId  uuid.UUID `json:"tokenId"`
+	Token    string    `json:"token"`
+	RoomName string    `json:"roomName"`
 }
 
 func (vh *InviteHandler) getJoinToken(apiKey, apiSecret, room string, authResult *auth.AuthResult, tokenId uuid.UUID) (string, error) {
@@ -619,51 +675,14 @@ func (vh *InviteHandler) ProcessExit(c echo.Context) error {
 
 func (vh *InviteHandler) sendMissedCallNotification(ctx context.Context, chatId int64, userPrincipalDto *auth.AuthResult, statuses []dto.UserCallState) {
 
-	missedUserMap := map[int64][]dto.UserCallState{}
-	for _, status := range statuses {
-		missedUserMap[status.UserId] = append(missedUserMap[status.UserId], status)
-	}
 	missedUsersList := make([]int64, 0)
-	for k, v := range missedUserMap {
-		if len(v) > 0 {
-			missedUsersList = append(missedUsersList, k)
+	for _, status := range statuses {
+		if status.Status == db.CallStatusBeingInvited && !utils.Contains(missedUsersList, status.UserId) {
+			missedUsersList = append(missedUsersList, status.UserId)
 		}
 	}
 
-	if len(missedUsersList) > 0 {
-		if chatNames, err := vh.chatClient.GetChatNameForInvite(ctx, chatId, userPrincipalDto.UserId, missedUsersList); err != nil {
-			vh.lgr.WithTracing(ctx).Errorf("Error %v", err)
-		} else {
-			for _, chatName := range chatNames {
-				states := missedUserMap[chatName.UserId]
-				sentToUser := false
-				for _, state := range states {
-					if state.Status == db.CallStatusBeingInvited && !sentToUser {
-						// here send missed call notification
-						var missedCall = dto.NotificationEvent{
-							EventType:              EventMissedCall,
-							ChatId:                 chatId,
-							UserId:                 chatName.UserId,
-							MissedCallNotification: &dto.MissedCallNotification{chatName.Name},
-							ByUserId:               userPrincipalDto.UserId,
-							ByLogin:                userPrincipalDto.UserLogin,
-						}
-						if len(userPrincipalDto.Avatar) > 0 {
-							missedCall.ByAvatar = &userPrincipalDto.Avatar
-						}
-
-						err = vh.notificationPublisher.Publish(ctx, missedCall)
-						if err != nil {
-							vh.lgr.WithTracing(ctx).Errorf("Error %v", err)
-						}
-
-						sentToUser = true
-					}
-				}
-
-			}
-		}
-	}
+	vh.availabilityService.SendMissedCallNotifications(ctx, chatId, userPrincipalDto.UserId, userPrincipalDto.UserLogin, &userPrincipalDto.Avatar, missedUsersList)
 }
 
 // send current dial statuses to WebSocket
@@ -20,10 +20,11 @@ type LivekitWebhookHandler struct {
 	egressService          *services.EgressService
 	restClient             *client.RestClient
 	rabbitUserIdsPublisher *producer.RabbitUserIdsPublisher
+	raiseHandService       *services.RaiseHandService
 	lgr                    *logger.Logger
 }
 
-func NewLivekitWebhookHandler(config *config.ExtendedConfig, notificationService *services.NotificationService, userService *services.UserService, egressService *services.EgressService, restClient *client.RestClient, rabbitUserIdsPublisher *producer.RabbitUserIdsPublisher, lgr *logger.Logger) *LivekitWebhookHandler {
+func NewLivekitWebhookHandler(config *config.ExtendedConfig, notificationService *services.NotificationService, userService *services.UserService, egressService *services.EgressService, restClient *client.RestClient, rabbitUserIdsPublisher *producer.RabbitUserIdsPublisher, raiseHandService *services.RaiseHandService, lgr *logger.Logger) *LivekitWebhookHandler {
 	return &LivekitWebhookHandler{
 		config:                 config,
 		notificationService:    notificationService,
@@ -31,6 +32,7 @@ func NewLivekitWebhookHandler(config *config.ExtendedConfig, notificationService
 		egressService:          egressService,
 		restClient:             restClient,
 		rabbitUserIdsPublisher: rabbitUserIdsPublisher,
+		raiseHandService:       raiseHandService,
 		lgr:                    lgr,
 	}
 }
@@ -61,7 +63,12 @@ func (h *LivekitWebhookHandler) GetLivekitWebhookHandler() echo.HandlerFunc {
 				return nil
 			}
 
-			usersCount := int64(event.Room.NumParticipants)
+			// the users of the breakout rooms are counted as well
+			usersCount, _, err := h.userService.CountUsersInChat(c.Request().Context(), chatId)
+			if err != nil {
+				h.lgr.WithTracing(c.Request().Context()).Errorf("got error during counting users in chatId=%v, %v", chatId, err)
+				usersCount = int64(event.Room.NumParticipants)
+			}
 
 			err = h.restClient.GetChatParticipantIds(c.Request().Context(), chatId, func(participantIds []int64) error {
 				internalErr := h.notificationService.NotifyVideoUserCountChanged(c.Request().Context(), participantIds, chatId, usersCount)
@@ -91,6 +98,16 @@ func (h *LivekitWebhookHandler) GetLivekitWebhookHandler() echo.HandlerFunc {
 					if err != nil {
 						h.lgr.WithTracing(c.Request().Context()).Errorf("Error during notifying about user is in video, userId=%v, chatId=%v, error=%v", metadata.UserId, chatId, err)
 					}
+
+					// the moderation of the chat is applied to its breakout rooms as well
+					h.raiseHandService.ApplyModeration(c.Request().Context(), chatId, event.Room.Name, event.Participant)
+				}
+			} else {
+				metadata, err := utils.ParseParticipantMetadataOrNull(event.Participant)
+				if err != nil {
+					h.lgr.WithTracing(c.Request().Context()).Errorf("got error during parsing metadata from participant=%v chatId=%v, %v", event.Participant, chatId, err)
+				} else if metadata != nil {
+					h.raiseHandService.OnParticipantLeft(c.Request().Context(), chatId, event.Room.Name, metadata.UserId)
 				}
 			}
 		} else if event.Event == "egress_started" {
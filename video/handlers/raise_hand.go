@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"net/http"
+	"nkonev.name/video/auth"
+	"nkonev.name/video/client"
+	"nkonev.name/video/logger"
+	"nkonev.name/video/services"
+	"nkonev.name/video/utils"
+)
+
+type RaiseHandHandler struct {
+	chatClient       *client.RestClient
+	raiseHandService *services.RaiseHandService
+	lgr              *logger.Logger
+}
+
+func NewRaiseHandHandler(chatClient *client.RestClient, raiseHandService *services.RaiseHandService, lgr *logger.Logger) *RaiseHandHandler {
+	return &RaiseHandHandler{chatClient: chatClient, raiseHandService: raiseHandService, lgr: lgr}
+}
+
+func (h *RaiseHandHandler) GetRaisedHands(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return err
+	}
+	if ok, err := h.chatClient.CheckAccess(c.Request().Context(), userPrincipalDto.UserId, chatId); err != nil {
+		return c.NoContent(http.StatusInternalServerError)
+	} else if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	raisedHands, err := h.raiseHandService.GetRaisedHands(c.Request().Context(), chatId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting raised hands: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, raisedHands)
+}
+
+func (h *RaiseHandHandler) RaiseHand(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return err
+	}
+	if ok, err := h.chatClient.CheckAccess(c.Request().Context(), userPrincipalDto.UserId, chatId); err != nil {
+		return c.NoContent(http.StatusInternalServerError)
+	} else if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	err = h.raiseHandService.RaiseHand(c.Request().Context(), chatId, userPrincipalDto.UserId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during raising hand: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// user lowers his own hand, moderator can lower anybody's hand by specifying userId
+func (h *RaiseHandHandler) LowerHand(c echo.Context) error {
+	chatId, userId, ok, err := h.getTargetUser(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	err = h.raiseHandService.LowerHand(c.Request().Context(), chatId, userId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during lowering hand: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *RaiseHandHandler) GrantFloor(c echo.Context) error {
+	chatId, ok, err := h.checkIsModerator(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	userId, err := utils.ParseInt64(c.QueryParam("userId"))
+	if err != nil {
+		return err
+	}
+
+	err = h.raiseHandService.GrantFloor(c.Request().Context(), chatId, userId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during granting the floor: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// user returns his own floor, moderator can take the floor back from anybody by specifying userId
+func (h *RaiseHandHandler) ReturnFloor(c echo.Context) error {
+	chatId, userId, ok, err := h.getTargetUser(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	err = h.raiseHandService.ReturnFloor(c.Request().Context(), chatId, userId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during returning the floor: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *RaiseHandHandler) SetModerated(c echo.Context) error {
+	chatId, ok, err := h.checkIsModerator(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	moderated, err := utils.ParseBoolean(c.QueryParam("enabled"))
+	if err != nil {
+		return err
+	}
+
+	err = h.raiseHandService.SetModerated(c.Request().Context(), chatId, moderated)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during setting moderated mode: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// moderator is a chat admin, as for Kick and Mute
+func (h *RaiseHandHandler) checkIsModerator(c echo.Context) (int64, bool, error) {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return 0, false, errors.New("Error during getting auth context")
+	}
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return 0, false, err
+	}
+	isAdmin, err := h.chatClient.IsAdmin(c.Request().Context(), userPrincipalDto.UserId, chatId)
+	if err != nil {
+		return 0, false, err
+	}
+	return chatId, isAdmin, nil
+}
+
+// returns the current user when userId isn't specified, otherwise requires the moderator
+func (h *RaiseHandHandler) getTargetUser(c echo.Context) (int64, int64, bool, error) {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return 0, 0, false, errors.New("Error during getting auth context")
+	}
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	userIdString := c.QueryParam("userId")
+	if len(userIdString) == 0 {
+		hasAccess, err := h.chatClient.CheckAccess(c.Request().Context(), userPrincipalDto.UserId, chatId)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		return chatId, userPrincipalDto.UserId, hasAccess, nil
+	}
+
+	userId, err := utils.ParseInt64(userIdString)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if userId == userPrincipalDto.UserId {
+		hasAccess, err := h.chatClient.CheckAccess(c.Request().Context(), userPrincipalDto.UserId, chatId)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		return chatId, userId, hasAccess, nil
+	}
+
+	isAdmin, err := h.chatClient.IsAdmin(c.Request().Context(), userPrincipalDto.UserId, chatId)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return chatId, userId, isAdmin, nil
+}
@@ -9,25 +9,30 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/livekit/protocol/livekit"
-	lksdk "github.com/livekit/server-sdk-go/v2"
 	"nkonev.name/video/auth"
 	"nkonev.name/video/client"
 	"nkonev.name/video/config"
+	"nkonev.name/video/dto"
 	"nkonev.name/video/logger"
 	"nkonev.name/video/services"
 	"nkonev.name/video/utils"
 )
 
+const RecordModeComposite = "composite"
+const RecordModeAudio = "audio"
+const RecordModeTracks = "tracks"
+
 type RecordHandler struct {
-	egressClient  *lksdk.EgressClient
-	restClient    *client.RestClient
-	egressService *services.EgressService
-	conf          *config.ExtendedConfig
-	recordPreset  livekit.EncodingOptionsPreset
-	lgr           *logger.Logger
+	egressClient      client.LivekitEgressClient
+	restClient        *client.RestClient
+	egressService     *services.EgressService
+	livekitRoomClient client.LivekitRoomClient
+	conf              *config.ExtendedConfig
+	recordPreset      livekit.EncodingOptionsPreset
+	lgr               *logger.Logger
 }
 
-func NewRecordHandler(egressClient *lksdk.EgressClient, restClient *client.RestClient, egressService *services.EgressService, conf *config.ExtendedConfig, lgr *logger.Logger) (*RecordHandler, error) {
+func NewRecordHandler(egressClient client.LivekitEgressClient, restClient *client.RestClient, egressService *services.EgressService, livekitRoomClient client.LivekitRoomClient, conf *config.ExtendedConfig, lgr *logger.Logger) (*RecordHandler, error) {
 	var recordPreset livekit.EncodingOptionsPreset
 	switch conf.RecordPreset {
 	case "H264_720P_30":
@@ -51,7 +56,30 @@ func NewRecordHandler(egressClient *lksdk.EgressClient, restClient *client.RestC
 		return nil, errors.New("Unexpected value of recordPreset")
 	}
 
-	return &RecordHandler{egressClient: egressClient, restClient: restClient, egressService: egressService, conf: conf, recordPreset: recordPreset, lgr: lgr}, nil
+	if !isValidRecordMode(getRecordModeOrDefault("", conf)) {
+		return nil, errors.New("Unexpected value of recordMode")
+	}
+
+	return &RecordHandler{egressClient: egressClient, restClient: restClient, egressService: egressService, livekitRoomClient: livekitRoomClient, conf: conf, recordPreset: recordPreset, lgr: lgr}, nil
+}
+
+func isValidRecordMode(mode string) bool {
+	switch mode {
+	case RecordModeComposite, RecordModeAudio, RecordModeTracks:
+		return true
+	default:
+		return false
+	}
+}
+
+func getRecordModeOrDefault(mode string, conf *config.ExtendedConfig) string {
+	if len(mode) > 0 {
+		return mode
+	}
+	if len(conf.RecordMode) > 0 {
+		return conf.RecordMode
+	}
+	return RecordModeComposite
 }
 
 func (rh *RecordHandler) canRecord(ctx context.Context, chatId int64, userPrincipalDto *auth.AuthResult) (bool, error) {
@@ -85,13 +113,47 @@ func (rh *RecordHandler) StartRecording(c echo.Context) error {
 		return c.NoContent(http.StatusUnauthorized)
 	}
 
+	mode := getRecordModeOrDefault(c.QueryParam("mode"), rh.conf)
+	if !isValidRecordMode(mode) {
+		rh.lgr.WithTracing(c.Request().Context()).Warnf("Wrong record mode %v", mode)
+		return c.NoContent(http.StatusBadRequest)
+	}
+
 	roomName := utils.GetRoomNameFromId(chatId)
-	fileName := fmt.Sprintf("recording_%v.mp4", time.Now().UTC().Format("20060102150405"))
-	s3, err := rh.restClient.GetS3(c.Request().Context(), fileName, chatId, userPrincipalDto.UserId)
+	timestamp := time.Now().UTC().Format("20060102150405")
+
+	var egressIds []string
+	switch mode {
+	case RecordModeTracks:
+		egressIds, err = rh.startParticipantEgresses(c.Request().Context(), roomName, timestamp, chatId, userPrincipalDto.UserId)
+	default:
+		var egressId string
+		egressId, err = rh.startRoomCompositeEgress(c.Request().Context(), roomName, timestamp, chatId, userPrincipalDto.UserId, mode == RecordModeAudio)
+		if err == nil {
+			egressIds = []string{egressId}
+		}
+	}
 	if err != nil {
-		rh.lgr.WithTracing(c.Request().Context()).Errorf("Error during gettting s3 %v", err)
+		rh.lgr.WithTracing(c.Request().Context()).Errorf("Error during starting recording %v", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	if len(egressIds) == 0 {
+		rh.lgr.WithTracing(c.Request().Context()).Infof("There are no participants to record in chat %v", chatId)
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	rh.lgr.WithTracing(c.Request().Context()).Infof("Starting recording in mode %v: %v", mode, egressIds)
+	return c.JSON(http.StatusAccepted, utils.H{"egressId": egressIds[0], "egressIds": egressIds, "mode": mode})
+}
+
+func (rh *RecordHandler) getS3Output(ctx context.Context, fileName string, chatId int64, userId int64, fileItemUuid *string) (*livekit.EncodedFileOutput_S3, *dto.S3Response, error) {
+	s3, err := rh.restClient.GetS3(ctx, fileName, chatId, userId, fileItemUuid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error during gettting s3 %v", err)
+	}
+	if s3 == nil {
+		return nil, nil, errors.New("Got empty s3 response")
+	}
 
 	s3u := livekit.EncodedFileOutput_S3{
 		S3: &livekit.S3Upload{
@@ -104,33 +166,114 @@ func (rh *RecordHandler) StartRecording(c echo.Context) error {
 			Metadata:       s3.Metadata,
 		},
 	}
-	preset := rh.recordPreset
+	return &s3u, s3, nil
+}
+
+func (rh *RecordHandler) startRoomCompositeEgress(ctx context.Context, roomName, timestamp string, chatId, userId int64, audioOnly bool) (string, error) {
+	fileType := livekit.EncodedFileType_MP4
+	fileName := fmt.Sprintf("recording_%v.mp4", timestamp)
+	if audioOnly {
+		fileType = livekit.EncodedFileType_OGG
+		fileName = fmt.Sprintf("recording_%v.ogg", timestamp)
+	}
+
+	s3u, s3, err := rh.getS3Output(ctx, fileName, chatId, userId, nil)
+	if err != nil {
+		return "", err
+	}
+
 	streamRequest := &livekit.RoomCompositeEgressRequest{
 		RoomName: roomName,
 		Layout:   "speaker-dark",
 		FileOutputs: []*livekit.EncodedFileOutput{
 			&livekit.EncodedFileOutput{
-				FileType:        livekit.EncodedFileType_MP4,
+				FileType:        fileType,
 				Filepath:        s3.Filepath,
-				Output:          &s3u,
+				Output:          s3u,
 				DisableManifest: true,
 			},
 		},
-		AudioOnly: false,
+		AudioOnly: audioOnly,
 		VideoOnly: false,
-		Options: &livekit.RoomCompositeEgressRequest_Preset{
-			Preset: preset,
-		},
+	}
+	if !audioOnly {
+		streamRequest.Options = &livekit.RoomCompositeEgressRequest_Preset{
+			Preset: rh.recordPreset,
+		}
 	}
 
-	info, err := rh.egressClient.StartRoomCompositeEgress(c.Request().Context(), streamRequest)
+	info, err := rh.egressClient.StartRoomCompositeEgress(ctx, streamRequest)
 	if err != nil {
-		rh.lgr.WithTracing(c.Request().Context()).Errorf("Error during starting recording %v", err)
-		return err
+		return "", err
+	}
+	return info.EgressId, nil
+}
+
+// starts the egress per each human participant, all the files are placed into the one file item.
+// in case of the error the already started egresses are stopped, so there are no orphaned recordings
+func (rh *RecordHandler) startParticipantEgresses(ctx context.Context, roomName, timestamp string, chatId, userId int64) ([]string, error) {
+	lpr, err := rh.livekitRoomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: roomName})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get participants %v", err)
+	}
+
+	var fileItemUuid *string
+	egressIds := []string{}
+	for _, participant := range lpr.Participants {
+		if utils.IsNotHumanUser(participant.Identity) {
+			continue
+		}
+		participantUserId, err := utils.GetUserIdFromIdentity(participant.Identity)
+		if err != nil {
+			rh.lgr.WithTracing(ctx).Errorf("Unable to get userId from identity %v: %v", participant.Identity, err)
+			continue
+		}
+
+		fileName := fmt.Sprintf("recording_%v_user%v.mp4", timestamp, participantUserId)
+		s3u, s3, err := rh.getS3Output(ctx, fileName, chatId, userId, fileItemUuid)
+		if err != nil {
+			rh.stopEgresses(ctx, egressIds)
+			return nil, err
+		}
+		fileItemUuid = &s3.FileItemUuid
+
+		participantRequest := &livekit.ParticipantEgressRequest{
+			RoomName: roomName,
+			Identity: participant.Identity,
+			FileOutputs: []*livekit.EncodedFileOutput{
+				&livekit.EncodedFileOutput{
+					FileType:        livekit.EncodedFileType_MP4,
+					Filepath:        s3.Filepath,
+					Output:          s3u,
+					DisableManifest: true,
+				},
+			},
+			Options: &livekit.ParticipantEgressRequest_Preset{
+				Preset: rh.recordPreset,
+			},
+		}
+
+		info, err := rh.egressClient.StartParticipantEgress(ctx, participantRequest)
+		if err != nil {
+			rh.stopEgresses(ctx, egressIds)
+			return nil, err
+		}
+		egressIds = append(egressIds, info.EgressId)
+	}
+	return egressIds, nil
+}
+
+func (rh *RecordHandler) stopEgresses(ctx context.Context, egressIds []string) {
+	// the request can be cancelled already
+	ctx = context.WithoutCancel(ctx)
+	for _, egressId := range egressIds {
+		_, err := rh.egressClient.StopEgress(ctx, &livekit.StopEgressRequest{EgressId: egressId})
+		if err != nil {
+			rh.lgr.WithTracing(ctx).Errorf("Error during stopping egress %v: %v", egressId, err)
+		} else {
+			rh.lgr.WithTracing(ctx).Infof("Stopped egress %v because the recording has failed to start", egressId)
+		}
 	}
-	egressId := info.EgressId
-	rh.lgr.WithTracing(c.Request().Context()).Infof("Starting recording %v", egressId)
-	return c.JSON(http.StatusAccepted, utils.H{"egressId": egressId})
 }
 
 func (rh *RecordHandler) StopRecording(c echo.Context) error {
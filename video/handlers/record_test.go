@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"nkonev.name/video/client"
+	"nkonev.name/video/config"
+	"nkonev.name/video/dto"
+	"nkonev.name/video/logger"
+)
+
+const testFileItemUuid = "0b3a2c1e-55a4-4bd2-9d0f-111111111111"
+
+// answers like storage does, fails starting from the failOn-th request when failOn is positive
+func startFakeStorage(t *testing.T, failOn int64) *config.ExtendedConfig {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n := requests.Add(1); failOn > 0 && n >= failOn {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(dto.S3Response{
+			Bucket:       "files",
+			Filepath:     "chat/1/" + testFileItemUuid + "/recording.mp4",
+			FileItemUuid: testFileItemUuid,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return &config.ExtendedConfig{
+		StorageConfig: config.StorageConfig{
+			StorageUrlConfig: config.StorageUrlConfig{Base: server.URL, S3: "/internal/s3"},
+		},
+		RecordPreset: "H264_720P_30",
+		RecordMode:   RecordModeTracks,
+	}
+}
+
+func newTestRecordHandler(t *testing.T, conf *config.ExtendedConfig, participants ...string) (*RecordHandler, *client.MockLivekitEgressClient) {
+	lgr := logger.NewLogger()
+	egressClient := client.NewMockLivekitEgressClient(t)
+	roomClient := client.NewMockLivekitRoomClient(t)
+
+	lps := []*livekit.ParticipantInfo{}
+	for _, identity := range participants {
+		lps = append(lps, &livekit.ParticipantInfo{Identity: identity})
+	}
+	roomClient.On("ListParticipants", mock.Anything, mock.Anything).Return(&livekit.ListParticipantsResponse{Participants: lps}, nil)
+
+	rh, err := NewRecordHandler(egressClient, client.NewRestClient(conf, lgr), nil, roomClient, conf, lgr)
+	require.NoError(t, err)
+	return rh, egressClient
+}
+
+func startParticipantEgressFor(identity string) interface{} {
+	return mock.MatchedBy(func(req *livekit.ParticipantEgressRequest) bool {
+		return req.Identity == identity
+	})
+}
+
+func stopEgressFor(egressId string) interface{} {
+	return mock.MatchedBy(func(req *livekit.StopEgressRequest) bool {
+		return req.EgressId == egressId
+	})
+}
+
+func TestStartParticipantEgresses(t *testing.T) {
+	conf := startFakeStorage(t, 0)
+	rh, egressClient := newTestRecordHandler(t, conf, "1_a", "EG_recorder", "2_b")
+
+	egressClient.On("StartParticipantEgress", mock.Anything, startParticipantEgressFor("1_a")).Return(&livekit.EgressInfo{EgressId: "eg1"}, nil).Once()
+	egressClient.On("StartParticipantEgress", mock.Anything, startParticipantEgressFor("2_b")).Return(&livekit.EgressInfo{EgressId: "eg2"}, nil).Once()
+
+	egressIds, err := rh.startParticipantEgresses(context.Background(), "chat1", "2026", 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"eg1", "eg2"}, egressIds)
+	egressClient.AssertNotCalled(t, "StopEgress", mock.Anything, mock.Anything)
+}
+
+func TestStartParticipantEgressesFailureStopsStarted(t *testing.T) {
+	conf := startFakeStorage(t, 0)
+	rh, egressClient := newTestRecordHandler(t, conf, "1_a", "2_b", "3_c")
+
+	egressClient.On("StartParticipantEgress", mock.Anything, startParticipantEgressFor("1_a")).Return(&livekit.EgressInfo{EgressId: "eg1"}, nil).Once()
+	egressClient.On("StartParticipantEgress", mock.Anything, startParticipantEgressFor("2_b")).Return(&livekit.EgressInfo{EgressId: "eg2"}, nil).Once()
+	egressClient.On("StartParticipantEgress", mock.Anything, startParticipantEgressFor("3_c")).Return(nil, errors.New("egress limit reached")).Once()
+	egressClient.On("StopEgress", mock.Anything, stopEgressFor("eg1")).Return(&livekit.EgressInfo{EgressId: "eg1"}, nil).Once()
+	// the stopping continues despite the error
+	egressClient.On("StopEgress", mock.Anything, stopEgressFor("eg2")).Return(nil, errors.New("not found")).Once()
+
+	egressIds, err := rh.startParticipantEgresses(context.Background(), "chat1", "2026", 1, 1)
+	assert.Error(t, err)
+	assert.Empty(t, egressIds)
+}
+
+func TestStartParticipantEgressesStorageFailureStopsStarted(t *testing.T) {
+	conf := startFakeStorage(t, 2)
+	rh, egressClient := newTestRecordHandler(t, conf, "1_a", "2_b")
+
+	egressClient.On("StartParticipantEgress", mock.Anything, startParticipantEgressFor("1_a")).Return(&livekit.EgressInfo{EgressId: "eg1"}, nil).Once()
+	egressClient.On("StopEgress", mock.Anything, stopEgressFor("eg1")).Return(&livekit.EgressInfo{EgressId: "eg1"}, nil).Once()
+
+	egressIds, err := rh.startParticipantEgresses(context.Background(), "chat1", "2026", 1, 1)
+	assert.Error(t, err)
+	assert.Empty(t, egressIds)
+}
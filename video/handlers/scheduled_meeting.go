@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"nkonev.name/video/auth"
+	"nkonev.name/video/client"
+	"nkonev.name/video/dto"
+	"nkonev.name/video/logger"
+	"nkonev.name/video/services"
+	"nkonev.name/video/utils"
+	"strings"
+	"time"
+)
+
+type ScheduledMeetingHandler struct {
+	chatClient              *client.RestClient
+	scheduledMeetingService *services.ScheduledMeetingService
+	lgr                     *logger.Logger
+}
+
+type ScheduledMeetingRequest struct {
+	Title           string    `json:"title"`
+	StartTime       time.Time `json:"startTime"`
+	DurationSeconds int64     `json:"durationSeconds"`
+	Rrule           *string   `json:"rrule"`
+	TimeZone        string    `json:"timeZone"` // IANA name, UTC by default
+	InviteeIds      []int64   `json:"inviteeIds"`
+}
+
+type CalendarFeedDto struct {
+	Path string `json:"path"`
+}
+
+const maxScheduledMeetingTitleLength = 256
+
+func NewScheduledMeetingHandler(chatClient *client.RestClient, scheduledMeetingService *services.ScheduledMeetingService, lgr *logger.Logger) *ScheduledMeetingHandler {
+	return &ScheduledMeetingHandler{chatClient: chatClient, scheduledMeetingService: scheduledMeetingService, lgr: lgr}
+}
+
+func (h *ScheduledMeetingHandler) GetScheduledMeetings(c echo.Context) error {
+	chatId, _, ok, err := h.checkAccess(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	meetings, err := h.scheduledMeetingService.GetScheduledMeetings(c.Request().Context(), chatId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting scheduled meetings: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, meetings)
+}
+
+func (h *ScheduledMeetingHandler) GetScheduledMeeting(c echo.Context) error {
+	chatId, _, ok, err := h.checkAccess(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	meetingId, err := utils.ParseInt64(c.Param("meetingId"))
+	if err != nil {
+		return err
+	}
+
+	meeting, err := h.scheduledMeetingService.GetScheduledMeeting(c.Request().Context(), chatId, meetingId)
+	if errors.Is(err, services.ErrScheduledMeetingNotFound) {
+		return c.NoContent(http.StatusNotFound)
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting scheduled meeting: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, meeting)
+}
+
+func (h *ScheduledMeetingHandler) CreateScheduledMeeting(c echo.Context) error {
+	chatId, userPrincipalDto, ok, err := h.checkAccess(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	reqDto, code := h.bindAndValidate(c, chatId)
+	if code != http.StatusOK {
+		return c.NoContent(code)
+	}
+
+	meeting := &dto.ScheduledMeeting{
+		ChatId:          chatId,
+		OwnerId:         userPrincipalDto.UserId,
+		OwnerLogin:      userPrincipalDto.UserLogin,
+		Title:           reqDto.Title,
+		StartTime:       reqDto.StartTime,
+		DurationSeconds: reqDto.DurationSeconds,
+		Rrule:           reqDto.Rrule,
+		TimeZone:        reqDto.TimeZone,
+	}
+	if len(userPrincipalDto.Avatar) > 0 {
+		meeting.OwnerAvatar = &userPrincipalDto.Avatar
+	}
+
+	created, err := h.scheduledMeetingService.CreateScheduledMeeting(c.Request().Context(), meeting, reqDto.InviteeIds)
+	if errors.Is(err, services.ErrInvalidRrule) || errors.Is(err, services.ErrInvalidTimeZone) {
+		return c.NoContent(http.StatusBadRequest)
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during creating scheduled meeting: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+func (h *ScheduledMeetingHandler) UpdateScheduledMeeting(c echo.Context) error {
+	chatId, meetingId, ok, err := h.checkCanEdit(c)
+	if errors.Is(err, services.ErrScheduledMeetingNotFound) {
+		return c.NoContent(http.StatusNotFound)
+	} else if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	reqDto, code := h.bindAndValidate(c, chatId)
+	if code != http.StatusOK {
+		return c.NoContent(code)
+	}
+
+	meeting := &dto.ScheduledMeeting{
+		Id:              meetingId,
+		ChatId:          chatId,
+		Title:           reqDto.Title,
+		StartTime:       reqDto.StartTime,
+		DurationSeconds: reqDto.DurationSeconds,
+		Rrule:           reqDto.Rrule,
+		TimeZone:        reqDto.TimeZone,
+	}
+
+	updated, err := h.scheduledMeetingService.UpdateScheduledMeeting(c.Request().Context(), meeting, reqDto.InviteeIds)
+	if errors.Is(err, services.ErrInvalidRrule) || errors.Is(err, services.ErrInvalidTimeZone) {
+		return c.NoContent(http.StatusBadRequest)
+	} else if errors.Is(err, services.ErrScheduledMeetingNotFound) {
+		return c.NoContent(http.StatusNotFound)
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during updating scheduled meeting: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+func (h *ScheduledMeetingHandler) DeleteScheduledMeeting(c echo.Context) error {
+	chatId, meetingId, ok, err := h.checkCanEdit(c)
+	if errors.Is(err, services.ErrScheduledMeetingNotFound) {
+		return c.NoContent(http.StatusNotFound)
+	} else if err != nil {
+		return err
+	}
+	if !ok {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	err = h.scheduledMeetingService.DeleteScheduledMeeting(c.Request().Context(), chatId, meetingId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during deleting scheduled meeting: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// iCalendar feed of the current user's meetings, suitable for subscribing from a calendar application
+func (h *ScheduledMeetingHandler) GetCalendar(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	calendar, err := h.scheduledMeetingService.GetCalendar(c.Request().Context(), userPrincipalDto.UserId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting calendar: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="meetings.ics"`)
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", []byte(calendar))
+}
+
+// the same feed for the calendar applications which can't pass the session, the token in the path identifies the user
+func (h *ScheduledMeetingHandler) GetCalendarFeed(c echo.Context) error {
+	token, err := uuid.Parse(c.Param("token"))
+	if err != nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	calendar, err := h.scheduledMeetingService.GetCalendarByToken(c.Request().Context(), token)
+	if errors.Is(err, services.ErrCalendarTokenNotFound) {
+		return c.NoContent(http.StatusNotFound)
+	} else if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting calendar feed: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", []byte(calendar))
+}
+
+func getCalendarFeedPath(token uuid.UUID) string {
+	return fmt.Sprintf("/api/video/public/calendar/%v/meetings.ics", token)
+}
+
+// returns the feed url to subscribe from a calendar application
+func (h *ScheduledMeetingHandler) GetCalendarFeedUrl(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	token, err := h.scheduledMeetingService.GetOrCreateCalendarToken(c.Request().Context(), userPrincipalDto.UserId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting calendar token: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, CalendarFeedDto{Path: getCalendarFeedPath(token)})
+}
+
+// the previous feed url stops working, e.g. when it has leaked
+func (h *ScheduledMeetingHandler) RegenerateCalendarFeedUrl(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	token, err := h.scheduledMeetingService.RegenerateCalendarToken(c.Request().Context(), userPrincipalDto.UserId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during regenerating calendar token: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, CalendarFeedDto{Path: getCalendarFeedPath(token)})
+}
+
+func (h *ScheduledMeetingHandler) RevokeCalendarFeedUrl(c echo.Context) error {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return errors.New("Error during getting auth context")
+	}
+
+	err := h.scheduledMeetingService.RevokeCalendarToken(c.Request().Context(), userPrincipalDto.UserId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during revoking calendar token: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// returns http.StatusOK in case the request is valid
+func (h *ScheduledMeetingHandler) bindAndValidate(c echo.Context, chatId int64) (*ScheduledMeetingRequest, int) {
+	reqDto := new(ScheduledMeetingRequest)
+	err := c.Bind(reqDto)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Infof("Unable to bind scheduled meeting: %v", err)
+		return nil, http.StatusBadRequest
+	}
+
+	reqDto.Title = strings.TrimSpace(reqDto.Title)
+	if len(reqDto.Title) == 0 || len([]rune(reqDto.Title)) > maxScheduledMeetingTitleLength {
+		return nil, http.StatusBadRequest
+	}
+	if reqDto.StartTime.IsZero() || reqDto.DurationSeconds <= 0 {
+		return nil, http.StatusBadRequest
+	}
+
+	// the invitees should be the participants of the chat
+	for _, inviteeId := range reqDto.InviteeIds {
+		if ok, err := h.chatClient.CheckAccess(c.Request().Context(), inviteeId, chatId); err != nil {
+			return nil, http.StatusInternalServerError
+		} else if !ok {
+			return nil, http.StatusBadRequest
+		}
+	}
+	return reqDto, http.StatusOK
+}
+
+func (h *ScheduledMeetingHandler) checkAccess(c echo.Context) (int64, *auth.AuthResult, bool, error) {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return 0, nil, false, errors.New("Error during getting auth context")
+	}
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return 0, nil, false, err
+	}
+	hasAccess, err := h.chatClient.CheckAccess(c.Request().Context(), userPrincipalDto.UserId, chatId)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	return chatId, userPrincipalDto, hasAccess, nil
+}
+
+// the meeting can be changed by its owner or by the chat admin
+func (h *ScheduledMeetingHandler) checkCanEdit(c echo.Context) (int64, int64, bool, error) {
+	var userPrincipalDto, ok = c.Get(utils.USER_PRINCIPAL_DTO).(*auth.AuthResult)
+	if !ok {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Error during getting auth context")
+		return 0, 0, false, errors.New("Error during getting auth context")
+	}
+	chatId, err := utils.ParseInt64(c.Param("chatId"))
+	if err != nil {
+		return 0, 0, false, err
+	}
+	meetingId, err := utils.ParseInt64(c.Param("meetingId"))
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	ownerId, err := h.scheduledMeetingService.GetOwnerId(c.Request().Context(), chatId, meetingId)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if ownerId == userPrincipalDto.UserId {
+		hasAccess, err := h.chatClient.CheckAccess(c.Request().Context(), userPrincipalDto.UserId, chatId)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		return chatId, meetingId, hasAccess, nil
+	}
+
+	isAdmin, err := h.chatClient.IsAdmin(c.Request().Context(), userPrincipalDto.UserId, chatId)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return chatId, meetingId, isAdmin, nil
+}
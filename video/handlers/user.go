@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"github.com/labstack/echo/v4"
 	"github.com/livekit/protocol/livekit"
 	"github.com/pkg/errors"
@@ -14,14 +15,15 @@ import (
 )
 
 type UserHandler struct {
-	chatClient        *client.RestClient
-	userService       *services.UserService
-	livekitRoomClient client.LivekitRoomClient
-	lgr               *logger.Logger
+	chatClient          *client.RestClient
+	userService         *services.UserService
+	breakoutRoomService *services.BreakoutRoomService
+	livekitRoomClient   client.LivekitRoomClient
+	lgr                 *logger.Logger
 }
 
-func NewUserHandler(chatClient *client.RestClient, userService *services.UserService, livekitRoomClient client.LivekitRoomClient, lgr *logger.Logger) *UserHandler {
-	return &UserHandler{chatClient: chatClient, userService: userService, livekitRoomClient: livekitRoomClient, lgr: lgr}
+func NewUserHandler(chatClient *client.RestClient, userService *services.UserService, breakoutRoomService *services.BreakoutRoomService, livekitRoomClient client.LivekitRoomClient, lgr *logger.Logger) *UserHandler {
+	return &UserHandler{chatClient: chatClient, userService: userService, breakoutRoomService: breakoutRoomService, livekitRoomClient: livekitRoomClient, lgr: lgr}
 }
 
 func (h *UserHandler) GetVideoUsers(c echo.Context) error {
@@ -40,14 +42,19 @@ func (h *UserHandler) GetVideoUsers(c echo.Context) error {
 		return c.NoContent(http.StatusUnauthorized)
 	}
 
-	var roomName = utils.GetRoomNameFromId(chatId)
-	usersCount, _, err := h.userService.CountUsers(c.Request().Context(), roomName)
+	usersCount, _, err := h.userService.CountUsersInChat(c.Request().Context(), chatId)
 	if err != nil {
 		h.lgr.WithTracing(c.Request().Context()).Errorf("got error during getting participants from http users request, %v", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
-	return c.JSON(http.StatusOK, dto.VideoCallUserCountChangedDto{UsersCount: usersCount, ChatId: chatId})
+	breakoutRooms, err := h.breakoutRoomService.GetBreakoutRooms(c.Request().Context(), chatId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("got error during getting breakout rooms from http users request, %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, dto.VideoCallUserCountChangedDto{UsersCount: usersCount, ChatId: chatId, BreakoutRooms: breakoutRooms})
 }
 
 func (h *UserHandler) Kick(c echo.Context) error {
@@ -97,19 +104,31 @@ func (h *UserHandler) Mute(c echo.Context) error {
 		return err
 	}
 
-	roomName := utils.GetRoomNameFromId(chatId)
+	roomNames, err := h.userService.GetRoomNames(c.Request().Context(), chatId)
+	if err != nil {
+		h.lgr.WithTracing(c.Request().Context()).Errorf("Unable to get room names %v", err)
+		return err
+	}
 
+	for _, roomName := range roomNames {
+		h.muteInRoom(c.Request().Context(), roomName, chatId, userId)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *UserHandler) muteInRoom(ctx context.Context, roomName string, chatId, userId int64) {
 	lpr := &livekit.ListParticipantsRequest{Room: roomName}
-	participants, err := h.livekitRoomClient.ListParticipants(c.Request().Context(), lpr)
+	participants, err := h.livekitRoomClient.ListParticipants(ctx, lpr)
 	if err != nil {
-		h.lgr.WithTracing(c.Request().Context()).Errorf("Unable to get participants %v", err)
-		return err
+		// the breakout room can be not created in livekit yet
+		h.lgr.WithTracing(ctx).Debugf("Unable to get participants of room %v: %v", roomName, err)
+		return
 	}
 
 	for _, participant := range participants.Participants {
 		metadata, err := utils.ParseParticipantMetadataOrNull(participant)
 		if err != nil {
-			h.lgr.WithTracing(c.Request().Context()).Errorf("got error during parsing metadata from participant=%v chatId=%v, %v", participant, chatId, err)
+			h.lgr.WithTracing(ctx).Errorf("got error during parsing metadata from participant=%v chatId=%v, %v", participant, chatId, err)
 			continue
 		}
 		if metadata == nil {
@@ -117,7 +136,7 @@ func (h *UserHandler) Mute(c echo.Context) error {
 		}
 
 		if metadata.UserId == userId {
-			h.lgr.WithTracing(c.Request().Context()).Infof("Muting userId=%v with identity %v from chatId=%v", userId, participant.Identity, chatId)
+			h.lgr.WithTracing(ctx).Infof("Muting userId=%v with identity %v from chatId=%v", userId, participant.Identity, chatId)
 
 			for _, track := range participant.GetTracks() {
 				if track.Type == livekit.TrackType_AUDIO && !track.Muted {
@@ -127,14 +146,13 @@ func (h *UserHandler) Mute(c echo.Context) error {
 						Muted:    true,
 						TrackSid: track.Sid,
 					}
-					_, err := h.livekitRoomClient.MutePublishedTrack(c.Request().Context(), muteReq)
+					_, err := h.livekitRoomClient.MutePublishedTrack(ctx, muteReq)
 					if err != nil {
-						h.lgr.WithTracing(c.Request().Context()).Errorf("got error during muting userId=%v, %v", userId, err)
+						h.lgr.WithTracing(ctx).Errorf("got error during muting userId=%v, %v", userId, err)
 						continue
 					}
 				}
 			}
 		}
 	}
-	return c.NoContent(http.StatusOK)
 }
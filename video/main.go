@@ -64,6 +64,10 @@ func main() {
 			handlers.NewLivekitWebhookHandler,
 			handlers.NewInviteHandler,
 			handlers.NewRecordHandler,
+			handlers.NewRaiseHandHandler,
+			handlers.NewBreakoutRoomHandler,
+			handlers.NewScheduledMeetingHandler,
+			handlers.NewAvailabilityHandler,
 			rabbitmq.CreateRabbitMqConnection,
 			producer.NewRabbitUserCountPublisher,
 			producer.NewRabbitInvitePublisher,
@@ -72,10 +76,16 @@ func main() {
 			producer.NewRabbitRecordingPublisher,
 			producer.NewRabbitNotificationsPublisher,
 			producer.NewRabbitScreenSharePublisher,
+			producer.NewRabbitRaisedHandsPublisher,
+			producer.NewRabbitBreakoutRoomsPublisher,
 			services.NewNotificationService,
 			services.NewUserService,
 			services.NewStateChangedEventService,
 			services.NewEgressService,
+			services.NewRaiseHandService,
+			services.NewBreakoutRoomService,
+			services.NewScheduledMeetingService,
+			services.NewAvailabilityService,
 			tasks.RedisV9,
 			tasks.Scheduler,
 			tasks.NewVideoCallUsersCountNotifierService,
@@ -88,6 +98,10 @@ func main() {
 			tasks.RecordingNotifierScheduler,
 			tasks.NewSynchronizeWithLivekitService,
 			tasks.SynchronizeWithLivekitSheduler,
+			tasks.NewBreakoutRoomsCloserService,
+			tasks.BreakoutRoomsCloserScheduler,
+			tasks.NewScheduledMeetingsService,
+			tasks.ScheduledMeetingsScheduler,
 			listener.CreateAaaUserSessionsKilledListener,
 			type_registry.NewTypeRegistryInstance,
 			configureMigrations,
@@ -153,6 +167,10 @@ func configureApiEcho(
 	lhf *handlers.LivekitWebhookHandler,
 	ih *handlers.InviteHandler,
 	rh *handlers.RecordHandler,
+	rhh *handlers.RaiseHandHandler,
+	brh *handlers.BreakoutRoomHandler,
+	smh *handlers.ScheduledMeetingHandler,
+	avh *handlers.AvailabilityHandler,
 	tp *sdktrace.TracerProvider,
 ) *ApiEcho {
 
@@ -222,6 +240,31 @@ func configureApiEcho(
 	e.PUT("/api/video/:chatId/kick", uh.Kick)
 	e.PUT("/api/video/:chatId/mute", uh.Mute)
 
+	e.GET("/api/video/:chatId/hand", rhh.GetRaisedHands)
+	e.PUT("/api/video/:chatId/hand/raise", rhh.RaiseHand)
+	e.PUT("/api/video/:chatId/hand/lower", rhh.LowerHand)          // by user itself or by moderator with userId
+	e.PUT("/api/video/:chatId/hand/grant-floor", rhh.GrantFloor)   // by moderator
+	e.PUT("/api/video/:chatId/hand/return-floor", rhh.ReturnFloor) // by user itself or by moderator with userId
+	e.PUT("/api/video/:chatId/moderated", rhh.SetModerated)        // by moderator, turns on or off the moderated speaking
+	e.GET("/api/video/:chatId/breakout", brh.GetBreakoutRooms)
+	e.POST("/api/video/:chatId/breakout", brh.CreateBreakoutRooms)
+	e.PUT("/api/video/:chatId/breakout/assign", brh.Assign) // either by the body or randomly with random=true
+	e.DELETE("/api/video/:chatId/breakout/:breakoutRoomId", brh.Close)
+	e.DELETE("/api/video/:chatId/breakout", brh.CloseAll)
+	e.GET("/api/video/:chatId/meeting", smh.GetScheduledMeetings)
+	e.POST("/api/video/:chatId/meeting", smh.CreateScheduledMeeting)
+	e.GET("/api/video/:chatId/meeting/:meetingId", smh.GetScheduledMeeting)
+	e.PUT("/api/video/:chatId/meeting/:meetingId", smh.UpdateScheduledMeeting)    // by owner or chat admin
+	e.DELETE("/api/video/:chatId/meeting/:meetingId", smh.DeleteScheduledMeeting) // by owner or chat admin
+	e.GET("/api/video/calendar/meetings.ics", smh.GetCalendar)
+	e.GET("/api/video/calendar/feed", smh.GetCalendarFeedUrl)
+	e.PUT("/api/video/calendar/feed", smh.RegenerateCalendarFeedUrl)
+	e.DELETE("/api/video/calendar/feed", smh.RevokeCalendarFeedUrl)
+	e.GET("/api/video/public/calendar/:token/meetings.ics", smh.GetCalendarFeed) // for calendar applications, without the session
+	e.GET("/api/video/availability", avh.GetAvailability)
+	e.PUT("/api/video/availability", avh.SetAvailability) // do-not-disturb and working hours
+	e.GET("/api/video/availability/status", avh.GetStatuses)
+
 	e.PUT("/api/video/:id/dial/invite", ih.ProcessCreatingOrDeletingInvite) // used by owner to add or remove from dial list
 	e.PUT("/api/video/:id/dial/enter", ih.ProcessEnterToDial)               // user enters to call somehow, either by clicking green tube or opening .../video link
 	e.PUT("/api/video/:id/dial/cancel", ih.ProcessCancelInvitation)         // cancelling by invitee
@@ -312,6 +355,8 @@ func runScheduler(
 	videoRecordingTask *tasks.RecordingNotifierTask,
 	usersInVideoStatusNotifierTask *tasks.UsersInVideoStatusNotifierTask,
 	synchronizeWithLivekitTask *tasks.SynchronizeWithLivekitTask,
+	breakoutRoomsCloserTask *tasks.BreakoutRoomsCloserTask,
+	scheduledMeetingsTask *tasks.ScheduledMeetingsTask,
 	lc fx.Lifecycle,
 ) error {
 	scheduler.Start()
@@ -367,6 +412,26 @@ func runScheduler(
 		lgr.Infof("Task " + synchronizeWithLivekitTask.Key() + " is disabled")
 	}
 
+	if viper.GetBool("schedulers." + breakoutRoomsCloserTask.Key() + ".enabled") {
+		lgr.Infof("Adding task " + breakoutRoomsCloserTask.Key() + " to scheduler")
+		err := scheduler.AddJobs(breakoutRoomsCloserTask)
+		if err != nil {
+			return err
+		}
+	} else {
+		lgr.Infof("Task " + breakoutRoomsCloserTask.Key() + " is disabled")
+	}
+
+	if viper.GetBool("schedulers." + scheduledMeetingsTask.Key() + ".enabled") {
+		lgr.Infof("Adding task " + scheduledMeetingsTask.Key() + " to scheduler")
+		err := scheduler.AddJobs(scheduledMeetingsTask)
+		if err != nil {
+			return err
+		}
+	} else {
+		lgr.Infof("Task " + scheduledMeetingsTask.Key() + " is disabled")
+	}
+
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
 			lgr.Infof("Stopping scheduler")
@@ -260,6 +260,10 @@ func runTest(t *testing.T, testFunc interface{}) *fxtest.App {
 			handlers.NewLivekitWebhookHandler,
 			handlers.NewInviteHandler,
 			handlers.NewRecordHandler,
+			handlers.NewRaiseHandHandler,
+			handlers.NewBreakoutRoomHandler,
+			handlers.NewScheduledMeetingHandler,
+			handlers.NewAvailabilityHandler,
 			rabbitmq.CreateRabbitMqConnection,
 			producer.NewRabbitUserCountPublisher,
 			producer.NewRabbitInvitePublisher,
@@ -268,10 +272,16 @@ func runTest(t *testing.T, testFunc interface{}) *fxtest.App {
 			producer.NewRabbitRecordingPublisher,
 			producer.NewRabbitNotificationsPublisher,
 			producer.NewRabbitScreenSharePublisher,
+			producer.NewRabbitRaisedHandsPublisher,
+			producer.NewRabbitBreakoutRoomsPublisher,
 			services.NewNotificationService,
 			services.NewUserService,
 			services.NewStateChangedEventService,
 			services.NewEgressService,
+			services.NewRaiseHandService,
+			services.NewBreakoutRoomService,
+			services.NewScheduledMeetingService,
+			services.NewAvailabilityService,
 			tasks.RedisV9,
 			tasks.Scheduler,
 			tasks.NewSynchronizeWithLivekitService,
@@ -444,3 +454,288 @@ func TestItsImpossibleToMakeACallToUserWhoAlreadyInCall(t *testing.T) {
 		assert.Equal(t, http.StatusConflict, c)
 	})
 }
+
+func TestUserInAnotherCallIsShownAsBusy(t *testing.T) {
+	aaaEmu := startAaaEmu()
+	defer aaaEmu.Close()
+
+	chatEmu := startChatEmu()
+	defer chatEmu.Close()
+
+	runTest(t, func(
+		e *ApiEcho,
+		database *db.DB,
+	) {
+		var calleeUserId int64 = 42
+		var anotherChatId int64 = 2
+		var chatId int64 = 1
+
+		assert.NoError(t, db.Transact(context.Background(), database, func(tx *db.Tx) error {
+			return tx.Set(context.Background(), dto.UserCallState{
+				TokenId:    uuid.New(),
+				UserId:     calleeUserId,
+				ChatId:     anotherChatId,
+				TokenTaken: true,
+				Status:     db.CallStatusInCall,
+			})
+		}))
+
+		var userId int64 = 4
+
+		c, b, _ := request("GET", "/api/video/availability/status?userId="+utils.Int64ToString(calleeUserId), userId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+		assert.Equal(t, services.AvailabilityStatusBusy, getJsonPathResult(t, b, "$[0].status"))
+
+		c, _, _ = request("PUT", "/api/video/"+utils.Int64ToString(chatId)+"/dial/invite?userId="+utils.Int64ToString(calleeUserId)+"&call=true", userId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+
+		states, err := db.TransactWithResult(context.Background(), database, func(tx *db.Tx) ([]dto.UserCallState, error) {
+			return tx.GetByCalleeUserIdFromAllChats(context.Background(), calleeUserId)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(states))
+		for _, st := range states {
+			if st.ChatId == chatId {
+				assert.Equal(t, db.CallStatusBusy, st.Status)
+				assert.NotNil(t, st.MarkedForRemoveAt)
+			} else {
+				assert.Equal(t, db.CallStatusInCall, st.Status)
+			}
+		}
+	})
+}
+
+func TestUserInDoNotDisturbModeIsNotRung(t *testing.T) {
+	chatEmu := startChatEmu()
+	defer chatEmu.Close()
+
+	runTest(t, func(
+		e *ApiEcho,
+		database *db.DB,
+	) {
+		var calleeUserId int64 = 42
+		var chatId int64 = 1
+		var userId int64 = 4
+
+		c, _, _ := request("PUT", "/api/video/availability", calleeUserId, strings.NewReader(`{"timeZone": "Mars/Olympus_Mons"}`), e)
+		assert.Equal(t, http.StatusBadRequest, c)
+
+		c, b, _ := request("PUT", "/api/video/availability", calleeUserId, strings.NewReader(`{"doNotDisturb": true, "timeZone": "Europe/Berlin", "workingHours": {"start": "09:00", "end": "18:00", "days": [1, 2, 3, 4, 5]}}`), e)
+		assert.Equal(t, http.StatusOK, c)
+		assert.Equal(t, false, getJsonPathResult(t, b, "$.available"))
+		assert.Equal(t, "18:00", getJsonPathResult(t, b, "$.workingHours.end"))
+
+		c, b, _ = request("GET", "/api/video/availability/status?userId="+utils.Int64ToString(calleeUserId)+","+utils.Int64ToString(userId), userId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+		assert.Equal(t, services.AvailabilityStatusDoNotDisturb, getJsonPathResult(t, b, "$[0].status"))
+		assert.Equal(t, services.AvailabilityStatusAvailable, getJsonPathResult(t, b, "$[1].status"))
+
+		c, _, _ = request("PUT", "/api/video/"+utils.Int64ToString(chatId)+"/dial/invite?userId="+utils.Int64ToString(calleeUserId)+"&call=true", userId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+
+		states, err := db.TransactWithResult(context.Background(), database, func(tx *db.Tx) ([]dto.UserCallState, error) {
+			return tx.GetByCalleeUserIdFromAllChats(context.Background(), calleeUserId)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(states))
+		assert.Equal(t, db.CallStatusBusy, states[0].Status)
+		assert.Equal(t, userId, *states[0].OwnerUserId)
+	})
+}
+
+func TestWorkingHours(t *testing.T) {
+	start := 9 * 60
+	end := 18 * 60
+	availability := &dto.UserAvailability{
+		WorkingHoursStart: &start,
+		WorkingHoursEnd:   &end,
+		WorkingDays:       db.DefaultWorkingDays,
+		TimeZone:          "Europe/Berlin",
+	}
+	// Monday, 10:00 in Berlin
+	assert.True(t, services.IsAvailable(availability, time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)))
+	// Monday, 19:00 in Berlin
+	assert.False(t, services.IsAvailable(availability, time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC)))
+	// Sunday, 10:00 in Berlin
+	assert.False(t, services.IsAvailable(availability, time.Date(2024, 1, 14, 9, 0, 0, 0, time.UTC)))
+
+	// the night shift from Friday to Saturday
+	nightStart := 22 * 60
+	nightEnd := 6 * 60
+	availability.WorkingHoursStart = &nightStart
+	availability.WorkingHoursEnd = &nightEnd
+	// Saturday, 02:00 in Berlin
+	assert.True(t, services.IsAvailable(availability, time.Date(2024, 1, 20, 1, 0, 0, 0, time.UTC)))
+	// Sunday, 02:00 in Berlin
+	assert.False(t, services.IsAvailable(availability, time.Date(2024, 1, 21, 1, 0, 0, 0, time.UTC)))
+}
+
+func TestRaisedHandsAreOrderedByRaisingTime(t *testing.T) {
+	chatEmu := startChatEmu()
+	defer chatEmu.Close()
+
+	runTest(t, func(
+		e *ApiEcho,
+		database *db.DB,
+	) {
+		var chatId int64 = 2
+		var firstUserId int64 = 5
+		var secondUserId int64 = 6
+
+		c, _, _ := request("PUT", "/api/video/"+utils.Int64ToString(chatId)+"/hand/raise", secondUserId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+		c, _, _ = request("PUT", "/api/video/"+utils.Int64ToString(chatId)+"/hand/raise", firstUserId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+		// the second raising doesn't move the user to the end of the queue
+		c, _, _ = request("PUT", "/api/video/"+utils.Int64ToString(chatId)+"/hand/raise", secondUserId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+
+		c, b, _ := request("GET", "/api/video/"+utils.Int64ToString(chatId)+"/hand", firstUserId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+		assert.Equal(t, float64(secondUserId), getJsonPathResult(t, b, "$.hands[0].userId"))
+		assert.Equal(t, float64(firstUserId), getJsonPathResult(t, b, "$.hands[1].userId"))
+		assert.Equal(t, false, getJsonPathRaw(t, b, "$.moderated"))
+
+		c, _, _ = request("PUT", "/api/video/"+utils.Int64ToString(chatId)+"/hand/lower", secondUserId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+
+		hands, err := db.TransactWithResult(context.Background(), database, func(tx *db.Tx) ([]dto.VideoRaisedHandDto, error) {
+			return tx.GetRaisedHands(context.Background(), chatId)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(hands))
+		assert.Equal(t, firstUserId, hands[0].UserId)
+		assert.False(t, hands[0].HasFloor)
+	})
+}
+
+func TestUserIsAssignedToBreakoutRoom(t *testing.T) {
+	chatEmu := startChatEmu()
+	defer chatEmu.Close()
+
+	runTest(t, func(
+		e *ApiEcho,
+		database *db.DB,
+		livekitRoomClient client.LivekitRoomClient,
+		breakoutRoomService *services.BreakoutRoomService,
+	) {
+		mockLivekitRoomClient := livekitRoomClient.(*client.MockLivekitRoomClient)
+		mockLivekitRoomClient.On("ListParticipants", mock.Anything, mock.Anything).Return(&livekit.ListParticipantsResponse{
+			Participants: []*livekit.ParticipantInfo{},
+		}, nil)
+
+		var chatId int64 = 3
+		var hostUserId int64 = 1
+		var userId int64 = 2
+
+		c, _, _ := request("POST", "/api/video/"+utils.Int64ToString(chatId)+"/breakout", hostUserId, strings.NewReader(`{"names": ["first", "second"], "durationSeconds": 600}`), e)
+		assert.Equal(t, http.StatusOK, c)
+
+		breakoutRooms, err := db.TransactWithResult(context.Background(), database, func(tx *db.Tx) ([]dto.BreakoutRoom, error) {
+			return tx.GetBreakoutRooms(context.Background(), chatId)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(breakoutRooms))
+		assert.Equal(t, "second", breakoutRooms[1].Name)
+		assert.NotNil(t, breakoutRooms[1].EndsAt)
+
+		secondRoomId := breakoutRooms[1].Id
+		c, _, _ = request("PUT", "/api/video/"+utils.Int64ToString(chatId)+"/breakout/assign", hostUserId, strings.NewReader(`{"assignments": [{"userId": `+utils.Int64ToString(userId)+`, "breakoutRoomId": `+utils.Int64ToString(secondRoomId)+`}]}`), e)
+		assert.Equal(t, http.StatusOK, c)
+
+		roomName, err := breakoutRoomService.GetRoomNameForUser(context.Background(), chatId, userId)
+		assert.NoError(t, err)
+		assert.Equal(t, utils.GetBreakoutRoomName(chatId, secondRoomId), roomName)
+
+		c, b, _ := request("GET", "/api/video/"+utils.Int64ToString(chatId)+"/breakout", userId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+		assert.Equal(t, float64(userId), getJsonPathResult(t, b, "$[1].userIds[0]"))
+
+		c, _, _ = request("DELETE", "/api/video/"+utils.Int64ToString(chatId)+"/breakout", hostUserId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+
+		roomName, err = breakoutRoomService.GetRoomNameForUser(context.Background(), chatId, userId)
+		assert.NoError(t, err)
+		assert.Equal(t, utils.GetRoomNameFromId(chatId), roomName)
+	})
+}
+
+func TestScheduledMeetingRingsInviteesAtStart(t *testing.T) {
+	chatEmu := startChatEmu()
+	defer chatEmu.Close()
+
+	runTest(t, func(
+		e *ApiEcho,
+		database *db.DB,
+		scheduledMeetingService *services.ScheduledMeetingService,
+	) {
+		var chatId int64 = 4
+		var ownerId int64 = 1
+		var inviteeId int64 = 3
+
+		startTime := time.Now().UTC().Add(-time.Second).Format(time.RFC3339)
+		c, b, _ := request("POST", "/api/video/"+utils.Int64ToString(chatId)+"/meeting", ownerId, strings.NewReader(`{"title": "Daily, sync", "startTime": "`+startTime+`", "durationSeconds": 900, "rrule": "RRULE:FREQ=DAILY", "inviteeIds": [`+utils.Int64ToString(inviteeId)+`]}`), e)
+		assert.Equal(t, http.StatusCreated, c)
+		meetingId := int64(getJsonPathResult(t, b, "$.id").(float64))
+		assert.Equal(t, "FREQ=DAILY", getJsonPathResult(t, b, "$.rrule"))
+		assert.Equal(t, float64(inviteeId), getJsonPathResult(t, b, "$.inviteeIds[0]"))
+
+		c, _, _ = request("POST", "/api/video/"+utils.Int64ToString(chatId)+"/meeting", ownerId, strings.NewReader(`{"title": "Wrong", "startTime": "`+startTime+`", "durationSeconds": 900, "rrule": "FREQ=SOMETIMES"}`), e)
+		assert.Equal(t, http.StatusBadRequest, c)
+
+		c, b, _ = request("GET", "/api/video/calendar/meetings.ics", inviteeId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+		assert.Contains(t, b, "SUMMARY:Daily\\, sync\r\n")
+		assert.Contains(t, b, "RRULE:FREQ=DAILY\r\n")
+
+		// calendar applications subscribe by the url with the token, without the session
+		c, b, _ = request("GET", "/api/video/calendar/feed", inviteeId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+		feedPath := getJsonPathResult(t, b, "$.path").(string)
+		c, b, _ = requestWithHeader("GET", feedPath, http.Header{}, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+		assert.Contains(t, b, "SUMMARY:Daily\\, sync\r\n")
+
+		c, b, _ = request("GET", "/api/video/calendar/feed", inviteeId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+		assert.Equal(t, feedPath, getJsonPathResult(t, b, "$.path"))
+
+		c, b, _ = request("PUT", "/api/video/calendar/feed", inviteeId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+		regeneratedFeedPath := getJsonPathResult(t, b, "$.path").(string)
+		assert.NotEqual(t, feedPath, regeneratedFeedPath)
+		c, _, _ = requestWithHeader("GET", feedPath, http.Header{}, nil, e)
+		assert.Equal(t, http.StatusNotFound, c)
+
+		c, _, _ = request("DELETE", "/api/video/calendar/feed", inviteeId, nil, e)
+		assert.Equal(t, http.StatusOK, c)
+		c, _, _ = requestWithHeader("GET", regeneratedFeedPath, http.Header{}, nil, e)
+		assert.Equal(t, http.StatusNotFound, c)
+
+		scheduledMeetingService.ProcessDueMeetings(context.Background(), 10*time.Minute, 2*time.Minute)
+
+		states, err := db.TransactWithResult(context.Background(), database, func(tx *db.Tx) ([]dto.UserCallState, error) {
+			return tx.GetByCalleeUserIdFromAllChats(context.Background(), inviteeId)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(states))
+		assert.Equal(t, db.CallStatusBeingInvited, states[0].Status)
+		assert.Equal(t, chatId, states[0].ChatId)
+		assert.Equal(t, ownerId, *states[0].OwnerUserId)
+
+		// the meeting isn't scanned till its next occurrence
+		meeting, err := db.TransactWithResult(context.Background(), database, func(tx *db.Tx) (*dto.ScheduledMeeting, error) {
+			return tx.GetScheduledMeeting(context.Background(), chatId, meetingId)
+		})
+		assert.NoError(t, err)
+		assert.True(t, meeting.NextOccurrence.After(time.Now().UTC().Add(23*time.Hour)))
+
+		// the same occurrence isn't rung twice
+		scheduledMeetingService.ProcessDueMeetings(context.Background(), 10*time.Minute, 2*time.Minute)
+		states, err = db.TransactWithResult(context.Background(), database, func(tx *db.Tx) ([]dto.UserCallState, error) {
+			return tx.GetByCalleeUserIdFromAllChats(context.Background(), inviteeId)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(states))
+	})
+}
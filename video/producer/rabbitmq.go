@@ -338,3 +338,127 @@ func NewRabbitScreenSharePublisher(lgr *logger.Logger, connection *rabbitmq.Conn
 		lgr:     lgr,
 	}
 }
+
+func (rp *RabbitRaisedHandsPublisher) Publish(ctx context.Context, participantIds []int64, chatNotifyDto *dto.VideoRaisedHandsChangedDto) error {
+	headers := myRabbitmq.InjectAMQPHeaders(ctx)
+
+	for _, participantId := range participantIds {
+		event := dto.ChatEvent{
+			EventType:        "video_raised_hands_changed",
+			ChatId:           chatNotifyDto.ChatId,
+			UserId:           participantId,
+			RaisedHandsEvent: chatNotifyDto,
+		}
+
+		bytea, err := json.Marshal(event)
+		if err != nil {
+			rp.lgr.WithTracing(ctx).Error(err, "Failed during marshal chatNotifyDto")
+			continue
+		}
+
+		msg := amqp.Publishing{
+			DeliveryMode: amqp.Transient,
+			Timestamp:    time.Now().UTC(),
+			ContentType:  "application/json",
+			Body:         bytea,
+			Type:         utils.GetType(event),
+			Headers:      headers,
+		}
+
+		if err := rp.channel.Publish(AsyncEventsFanoutExchange, "", false, false, msg); err != nil {
+			rp.lgr.WithTracing(ctx).Error(err, "Error during publishing")
+			continue
+		}
+	}
+	return nil
+}
+
+type RabbitRaisedHandsPublisher struct {
+	channel *rabbitmq.Channel
+	lgr     *logger.Logger
+}
+
+func NewRabbitRaisedHandsPublisher(lgr *logger.Logger, connection *rabbitmq.Connection) *RabbitRaisedHandsPublisher {
+	return &RabbitRaisedHandsPublisher{
+		channel: myRabbitmq.CreateRabbitMqChannel(lgr, connection),
+		lgr:     lgr,
+	}
+}
+
+func (rp *RabbitBreakoutRoomsPublisher) Publish(ctx context.Context, participantIds []int64, chatNotifyDto *dto.VideoBreakoutRoomsChangedDto) error {
+	headers := myRabbitmq.InjectAMQPHeaders(ctx)
+
+	for _, participantId := range participantIds {
+		event := dto.ChatEvent{
+			EventType:          "video_breakout_rooms_changed",
+			ChatId:             chatNotifyDto.ChatId,
+			UserId:             participantId,
+			BreakoutRoomsEvent: chatNotifyDto,
+		}
+
+		bytea, err := json.Marshal(event)
+		if err != nil {
+			rp.lgr.WithTracing(ctx).Error(err, "Failed during marshal chatNotifyDto")
+			continue
+		}
+
+		msg := amqp.Publishing{
+			DeliveryMode: amqp.Transient,
+			Timestamp:    time.Now().UTC(),
+			ContentType:  "application/json",
+			Body:         bytea,
+			Type:         utils.GetType(event),
+			Headers:      headers,
+		}
+
+		if err := rp.channel.Publish(AsyncEventsFanoutExchange, "", false, false, msg); err != nil {
+			rp.lgr.WithTracing(ctx).Error(err, "Error during publishing")
+			continue
+		}
+	}
+	return nil
+}
+
+func (rp *RabbitBreakoutRoomsPublisher) PublishRoomChanged(ctx context.Context, userId int64, roomChanged *dto.VideoRoomChangedDto) error {
+	headers := myRabbitmq.InjectAMQPHeaders(ctx)
+
+	event := dto.ChatEvent{
+		EventType:        "video_room_changed",
+		ChatId:           roomChanged.ChatId,
+		UserId:           userId,
+		RoomChangedEvent: roomChanged,
+	}
+
+	bytea, err := json.Marshal(event)
+	if err != nil {
+		rp.lgr.WithTracing(ctx).Error(err, "Failed during marshal roomChanged")
+		return err
+	}
+
+	msg := amqp.Publishing{
+		DeliveryMode: amqp.Transient,
+		Timestamp:    time.Now().UTC(),
+		ContentType:  "application/json",
+		Body:         bytea,
+		Type:         utils.GetType(event),
+		Headers:      headers,
+	}
+
+	if err := rp.channel.Publish(AsyncEventsFanoutExchange, "", false, false, msg); err != nil {
+		rp.lgr.WithTracing(ctx).Error(err, "Error during publishing")
+		return err
+	}
+	return nil
+}
+
+type RabbitBreakoutRoomsPublisher struct {
+	channel *rabbitmq.Channel
+	lgr     *logger.Logger
+}
+
+func NewRabbitBreakoutRoomsPublisher(lgr *logger.Logger, connection *rabbitmq.Connection) *RabbitBreakoutRoomsPublisher {
+	return &RabbitBreakoutRoomsPublisher{
+		channel: myRabbitmq.CreateRabbitMqChannel(lgr, connection),
+		lgr:     lgr,
+	}
+}
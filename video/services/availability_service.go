@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata" // the images can miss the system time zone database
+
+	"nkonev.name/video/client"
+	"nkonev.name/video/db"
+	"nkonev.name/video/dto"
+	"nkonev.name/video/logger"
+	"nkonev.name/video/producer"
+	"nkonev.name/video/utils"
+)
+
+const EventMissedCall = "missed_call"
+
+const AvailabilityStatusAvailable = "available"
+const AvailabilityStatusDoNotDisturb = "doNotDisturb"
+const AvailabilityStatusBusy = "busy" // in a call
+
+const minutesInDay = 24 * 60
+
+var ErrInvalidAvailability = errors.New("invalid availability")
+
+type AvailabilityService struct {
+	database              *db.DB
+	chatClient            *client.RestClient
+	notificationPublisher *producer.RabbitNotificationsPublisher
+	lgr                   *logger.Logger
+}
+
+func NewAvailabilityService(database *db.DB, chatClient *client.RestClient, notificationPublisher *producer.RabbitNotificationsPublisher, lgr *logger.Logger) *AvailabilityService {
+	return &AvailabilityService{
+		database:              database,
+		chatClient:            chatClient,
+		notificationPublisher: notificationPublisher,
+		lgr:                   lgr,
+	}
+}
+
+// a user is not available when they turned do-not-disturb on or when it's outside of their working hours
+func IsAvailable(a *dto.UserAvailability, now time.Time) bool {
+	if a == nil {
+		return true
+	}
+	if a.DoNotDisturb {
+		return false
+	}
+	if a.WorkingHoursStart == nil || a.WorkingHoursEnd == nil {
+		return true
+	}
+
+	loc, err := time.LoadLocation(a.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	start, end := *a.WorkingHoursStart, *a.WorkingHoursEnd
+
+	weekday := local.Weekday()
+	if start > end && minute < end {
+		// the night shift which has begun yesterday
+		weekday = (weekday + 6) % 7
+	}
+	if a.WorkingDays&(1<<weekday) == 0 {
+		return false
+	}
+
+	if start <= end {
+		return minute >= start && minute < end
+	} else {
+		return minute >= start || minute < end
+	}
+}
+
+func formatMinutes(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func parseMinutes(str string) (int, error) {
+	t, err := time.Parse("15:04", str)
+	if err != nil {
+		return 0, errors.Join(ErrInvalidAvailability, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func convertToUserAvailabilityDto(a *dto.UserAvailability, now time.Time) *dto.UserAvailabilityDto {
+	if a == nil {
+		return &dto.UserAvailabilityDto{
+			TimeZone:  db.DefaultTimeZone,
+			Available: true,
+		}
+	}
+	ret := &dto.UserAvailabilityDto{
+		DoNotDisturb: a.DoNotDisturb,
+		TimeZone:     a.TimeZone,
+		Available:    IsAvailable(a, now),
+	}
+	if a.WorkingHoursStart != nil && a.WorkingHoursEnd != nil {
+		days := []time.Weekday{}
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if a.WorkingDays&(1<<d) != 0 {
+				days = append(days, d)
+			}
+		}
+		ret.WorkingHours = &dto.WorkingHoursDto{
+			Start: formatMinutes(*a.WorkingHoursStart),
+			End:   formatMinutes(*a.WorkingHoursEnd),
+			Days:  days,
+		}
+	}
+	return ret
+}
+
+func (s *AvailabilityService) GetAvailability(ctx context.Context, userId int64) (*dto.UserAvailabilityDto, error) {
+	a, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (*dto.UserAvailability, error) {
+		return tx.GetUserAvailability(ctx, userId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return convertToUserAvailabilityDto(a, time.Now().UTC()), nil
+}
+
+func (s *AvailabilityService) SetAvailability(ctx context.Context, userId int64, req *dto.UserAvailabilityDto) (*dto.UserAvailabilityDto, error) {
+	a := dto.UserAvailability{
+		UserId:       userId,
+		DoNotDisturb: req.DoNotDisturb,
+		WorkingDays:  db.DefaultWorkingDays,
+		TimeZone:     req.TimeZone,
+	}
+	if len(a.TimeZone) == 0 {
+		a.TimeZone = db.DefaultTimeZone
+	}
+	if _, err := time.LoadLocation(a.TimeZone); err != nil {
+		return nil, errors.Join(ErrInvalidAvailability, err)
+	}
+
+	if req.WorkingHours != nil {
+		start, err := parseMinutes(req.WorkingHours.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseMinutes(req.WorkingHours.End)
+		if err != nil {
+			return nil, err
+		}
+		if start == end {
+			return nil, ErrInvalidAvailability
+		}
+		a.WorkingHoursStart = &start
+		a.WorkingHoursEnd = &end
+		if req.WorkingHours.Days != nil {
+			a.WorkingDays = 0
+			for _, d := range req.WorkingHours.Days {
+				if d < time.Sunday || d > time.Saturday {
+					return nil, ErrInvalidAvailability
+				}
+				a.WorkingDays |= 1 << d
+			}
+		}
+	}
+
+	err := db.Transact(ctx, s.database, func(tx *db.Tx) error {
+		return tx.SetUserAvailability(ctx, a)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return convertToUserAvailabilityDto(&a, time.Now().UTC()), nil
+}
+
+// returns the ids of users who shouldn't be rung now
+func (s *AvailabilityService) GetUnavailableUserIds(ctx context.Context, tx *db.Tx, userIds []int64) ([]int64, error) {
+	availabilities, err := tx.GetUserAvailabilities(ctx, userIds)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	ret := []int64{}
+	for _, a := range availabilities {
+		if !IsAvailable(&a, now) {
+			ret = append(ret, a.UserId)
+		}
+	}
+	return ret, nil
+}
+
+func (s *AvailabilityService) IsUnavailable(ctx context.Context, tx *db.Tx, userId int64) (bool, error) {
+	unavailableUserIds, err := s.GetUnavailableUserIds(ctx, tx, []int64{userId})
+	if err != nil {
+		return false, err
+	}
+	return utils.Contains(unavailableUserIds, userId), nil
+}
+
+// what the caller would see before the calling
+func (s *AvailabilityService) GetStatuses(ctx context.Context, userIds []int64) ([]dto.UserAvailabilityStatusDto, error) {
+	return db.TransactWithResult(ctx, s.database, func(tx *db.Tx) ([]dto.UserAvailabilityStatusDto, error) {
+		unavailableUserIds, err := s.GetUnavailableUserIds(ctx, tx, userIds)
+		if err != nil {
+			return nil, err
+		}
+		states, err := tx.GetUserStatesFiltered(ctx, userIds)
+		if err != nil {
+			return nil, err
+		}
+		inCallUserIds := []int64{}
+		for _, st := range states {
+			if st.Status == db.CallStatusInCall {
+				inCallUserIds = append(inCallUserIds, st.UserId)
+			}
+		}
+
+		ret := make([]dto.UserAvailabilityStatusDto, 0, len(userIds))
+		for _, userId := range userIds {
+			status := AvailabilityStatusAvailable
+			if utils.Contains(unavailableUserIds, userId) {
+				status = AvailabilityStatusDoNotDisturb
+			} else if utils.Contains(inCallUserIds, userId) {
+				status = AvailabilityStatusBusy
+			}
+			ret = append(ret, dto.UserAvailabilityStatusDto{UserId: userId, Status: status})
+		}
+		return ret, nil
+	})
+}
+
+func (s *AvailabilityService) SendMissedCallNotifications(ctx context.Context, chatId int64, byUserId int64, byLogin string, byAvatar *string, userIds []int64) {
+	if len(userIds) == 0 {
+		return
+	}
+
+	chatNames, err := s.chatClient.GetChatNameForInvite(ctx, chatId, byUserId, userIds)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error %v", err)
+		return
+	}
+	for _, chatName := range chatNames {
+		var missedCall = dto.NotificationEvent{
+			EventType:              EventMissedCall,
+			ChatId:                 chatId,
+			UserId:                 chatName.UserId,
+			MissedCallNotification: &dto.MissedCallNotification{Description: chatName.Name},
+			ByUserId:               byUserId,
+			ByLogin:                byLogin,
+		}
+		if byAvatar != nil && len(*byAvatar) > 0 {
+			missedCall.ByAvatar = byAvatar
+		}
+
+		err = s.notificationPublisher.Publish(ctx, missedCall)
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Error %v", err)
+		}
+	}
+}
+
+func (s *AvailabilityService) SendCallInvitationNotifications(ctx context.Context, chatId int64, byUserId int64, byLogin string, byAvatar *string, userIds []int64) {
+	if len(userIds) == 0 {
+		return
+	}
+
+	chatNames, err := s.chatClient.GetChatNameForInvite(ctx, chatId, byUserId, userIds)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Error %v", err)
+		return
+	}
+	sendCallInvitationNotifications(ctx, s.notificationPublisher, s.lgr, chatId, byUserId, byLogin, byAvatar, chatNames)
+}
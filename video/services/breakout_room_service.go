@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"nkonev.name/video/client"
+	"nkonev.name/video/db"
+	"nkonev.name/video/dto"
+	"nkonev.name/video/logger"
+	"nkonev.name/video/utils"
+)
+
+var ErrBreakoutRoomNotFound = errors.New("Breakout room is not found")
+
+type BreakoutRoomService struct {
+	database            *db.DB
+	livekitRoomClient   client.LivekitRoomClient
+	restClient          *client.RestClient
+	notificationService *NotificationService
+	lgr                 *logger.Logger
+}
+
+func NewBreakoutRoomService(database *db.DB, livekitRoomClient client.LivekitRoomClient, restClient *client.RestClient, notificationService *NotificationService, lgr *logger.Logger) *BreakoutRoomService {
+	return &BreakoutRoomService{
+		database:            database,
+		livekitRoomClient:   livekitRoomClient,
+		restClient:          restClient,
+		notificationService: notificationService,
+		lgr:                 lgr,
+	}
+}
+
+type breakoutRoomsWithParticipants struct {
+	rooms        []dto.BreakoutRoom
+	participants map[int64][]int64
+}
+
+func (s *BreakoutRoomService) GetBreakoutRooms(ctx context.Context, chatId int64) ([]dto.BreakoutRoomDto, error) {
+	data, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (*breakoutRoomsWithParticipants, error) {
+		rooms, err := tx.GetBreakoutRooms(ctx, chatId)
+		if err != nil {
+			return nil, err
+		}
+		participants, err := tx.GetBreakoutRoomParticipants(ctx, chatId)
+		if err != nil {
+			return nil, err
+		}
+		return &breakoutRoomsWithParticipants{rooms: rooms, participants: participants}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]dto.BreakoutRoomDto, 0, len(data.rooms))
+	for _, br := range data.rooms {
+		var usersCount int64
+		// the room can be not created in livekit yet
+		participants, err := s.livekitRoomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: utils.GetBreakoutRoomName(chatId, br.Id)})
+		if err == nil {
+			usersCount = int64(len(participants.Participants))
+		}
+		userIds := data.participants[br.Id]
+		if userIds == nil {
+			userIds = []int64{}
+		}
+		ret = append(ret, dto.BreakoutRoomDto{
+			Id:         br.Id,
+			Name:       br.Name,
+			EndsAt:     br.EndsAt,
+			UserIds:    userIds,
+			UsersCount: usersCount,
+		})
+	}
+	return ret, nil
+}
+
+// duration is optional, rooms without it are closed only manually
+func (s *BreakoutRoomService) CreateBreakoutRooms(ctx context.Context, chatId int64, names []string, duration *time.Duration) error {
+	var endsAt *time.Time
+	if duration != nil {
+		t := time.Now().UTC().Add(*duration)
+		endsAt = &t
+	}
+	err := db.Transact(ctx, s.database, func(tx *db.Tx) error {
+		for _, name := range names {
+			_, err := tx.CreateBreakoutRoom(ctx, chatId, name, endsAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.notifyBreakoutRoomsChanged(ctx, chatId)
+	return nil
+}
+
+// breakoutRoomId == nil means moving the user back to the main room
+func (s *BreakoutRoomService) Assign(ctx context.Context, chatId int64, assignments map[int64]*int64) error {
+	movedUsers, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (map[int64]*dto.BreakoutRoom, error) {
+		movedUsers := map[int64]*dto.BreakoutRoom{}
+		for userId, breakoutRoomId := range assignments {
+			previousBreakoutRoomId, err := tx.GetUserBreakoutRoomId(ctx, chatId, userId)
+			if err != nil {
+				return nil, err
+			}
+
+			if breakoutRoomId == nil {
+				err = tx.UnassignFromBreakoutRoom(ctx, chatId, userId)
+				if err != nil {
+					return nil, err
+				}
+				if previousBreakoutRoomId != nil {
+					movedUsers[userId] = nil
+				}
+				continue
+			}
+
+			br, err := tx.GetBreakoutRoom(ctx, chatId, *breakoutRoomId)
+			if err != nil {
+				return nil, err
+			}
+			if br == nil {
+				return nil, ErrBreakoutRoomNotFound
+			}
+			err = tx.AssignToBreakoutRoom(ctx, chatId, br.Id, userId)
+			if err != nil {
+				return nil, err
+			}
+			if previousBreakoutRoomId == nil || *previousBreakoutRoomId != br.Id {
+				movedUsers[userId] = br
+			}
+		}
+		return movedUsers, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notifyBreakoutRoomsChanged(ctx, chatId)
+	// the client reconnects to the new room after the event
+	for userId, br := range movedUsers {
+		s.notifyRoomChanged(ctx, chatId, userId, br)
+	}
+	return nil
+}
+
+// distributes the users being in the main room evenly among all the breakout rooms
+func (s *BreakoutRoomService) AssignRandomly(ctx context.Context, chatId int64, excludeUserIds []int64) error {
+	breakoutRooms, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) ([]dto.BreakoutRoom, error) {
+		return tx.GetBreakoutRooms(ctx, chatId)
+	})
+	if err != nil {
+		return err
+	}
+	if len(breakoutRooms) == 0 {
+		return ErrBreakoutRoomNotFound
+	}
+
+	participants, err := s.livekitRoomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: utils.GetRoomNameFromId(chatId)})
+	if err != nil {
+		return err
+	}
+
+	userIdSet := map[int64]bool{}
+	userIds := []int64{}
+	for _, participant := range participants.Participants {
+		metadata, err := utils.ParseParticipantMetadataOrNull(participant)
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("got error during parsing metadata from participant=%v chatId=%v, %v", participant, chatId, err)
+			continue
+		}
+		if metadata == nil || userIdSet[metadata.UserId] || utils.Contains(excludeUserIds, metadata.UserId) {
+			continue
+		}
+		userIdSet[metadata.UserId] = true
+		userIds = append(userIds, metadata.UserId)
+	}
+
+	rand.Shuffle(len(userIds), func(i, j int) {
+		userIds[i], userIds[j] = userIds[j], userIds[i]
+	})
+
+	assignments := map[int64]*int64{}
+	for i, userId := range userIds {
+		breakoutRoomId := breakoutRooms[i%len(breakoutRooms)].Id
+		assignments[userId] = &breakoutRoomId
+	}
+
+	return s.Assign(ctx, chatId, assignments)
+}
+
+// removes the breakout room and pulls its users back to the main room
+func (s *BreakoutRoomService) Close(ctx context.Context, chatId, breakoutRoomId int64) error {
+	userIds, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) ([]int64, error) {
+		br, err := tx.GetBreakoutRoom(ctx, chatId, breakoutRoomId)
+		if err != nil {
+			return nil, err
+		}
+		if br == nil {
+			return nil, ErrBreakoutRoomNotFound
+		}
+		participants, err := tx.GetBreakoutRoomParticipants(ctx, chatId)
+		if err != nil {
+			return nil, err
+		}
+		err = tx.RemoveBreakoutRoom(ctx, chatId, breakoutRoomId)
+		if err != nil {
+			return nil, err
+		}
+		return participants[breakoutRoomId], nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notifyBreakoutRoomsChanged(ctx, chatId)
+	// the client reconnects to the main room after the event
+	for _, userId := range userIds {
+		s.notifyRoomChanged(ctx, chatId, userId, nil)
+	}
+	return nil
+}
+
+func (s *BreakoutRoomService) CloseAll(ctx context.Context, chatId int64) error {
+	breakoutRooms, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) ([]dto.BreakoutRoom, error) {
+		return tx.GetBreakoutRooms(ctx, chatId)
+	})
+	if err != nil {
+		return err
+	}
+	for _, br := range breakoutRooms {
+		err = s.Close(ctx, chatId, br.Id)
+		if err != nil && !errors.Is(err, ErrBreakoutRoomNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// closes time-boxed rooms, invoked by scheduler
+func (s *BreakoutRoomService) CloseExpired(ctx context.Context) {
+	for {
+		expired, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) ([]dto.BreakoutRoom, error) {
+			return tx.GetExpiredBreakoutRooms(ctx, utils.DefaultSize)
+		})
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to get expired breakout rooms: %v", err)
+			return
+		}
+		for _, br := range expired {
+			s.lgr.WithTracing(ctx).Infof("Closing expired breakout room %v of chatId %v", br.Id, br.ChatId)
+			err = s.Close(ctx, br.ChatId, br.Id)
+			if err != nil && !errors.Is(err, ErrBreakoutRoomNotFound) {
+				s.lgr.WithTracing(ctx).Errorf("Unable to close breakout room %v of chatId %v: %v", br.Id, br.ChatId, err)
+				return
+			}
+		}
+		if len(expired) < utils.DefaultSize {
+			return
+		}
+	}
+}
+
+// returns the breakout room name if the user is assigned to one, otherwise the main room name
+func (s *BreakoutRoomService) GetRoomNameForUser(ctx context.Context, chatId, userId int64) (string, error) {
+	breakoutRoomId, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (*int64, error) {
+		return tx.GetUserBreakoutRoomId(ctx, chatId, userId)
+	})
+	if err != nil {
+		return "", err
+	}
+	if breakoutRoomId != nil {
+		return utils.GetBreakoutRoomName(chatId, *breakoutRoomId), nil
+	}
+	return utils.GetRoomNameFromId(chatId), nil
+}
+
+func (s *BreakoutRoomService) notifyBreakoutRoomsChanged(ctx context.Context, chatId int64) {
+	breakoutRooms, err := s.GetBreakoutRooms(ctx, chatId)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get breakout rooms for chatId %v: %v", chatId, err)
+		return
+	}
+
+	event := &dto.VideoBreakoutRoomsChangedDto{
+		ChatId:        chatId,
+		BreakoutRooms: breakoutRooms,
+	}
+	err = s.restClient.GetChatParticipantIds(ctx, chatId, func(participantIds []int64) error {
+		return s.notificationService.NotifyBreakoutRoomsChanged(ctx, participantIds, event)
+	})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to notify about breakout rooms for chatId %v: %v", chatId, err)
+	}
+}
+
+// br == nil means the main room
+func (s *BreakoutRoomService) notifyRoomChanged(ctx context.Context, chatId, userId int64, br *dto.BreakoutRoom) {
+	event := &dto.VideoRoomChangedDto{
+		ChatId: chatId,
+	}
+	if br != nil {
+		event.BreakoutRoomId = &br.Id
+		event.BreakoutRoomName = &br.Name
+	}
+	err := s.notificationService.NotifyRoomChanged(ctx, userId, event)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to notify userId %v about room changed in chatId %v: %v", userId, chatId, err)
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"nkonev.name/video/dto"
+	"nkonev.name/video/logger"
+	"nkonev.name/video/producer"
+)
+
+const EventCallInvitation = "call_invitation"
+
+// the invitation is delivered by the notification service via web push, so the callee is able to get it with the closed tab.
+// it isn't stored as the notification, the missed call is stored instead
+func sendCallInvitationNotifications(ctx context.Context, notificationPublisher *producer.RabbitNotificationsPublisher, lgr *logger.Logger, chatId int64, byUserId int64, byLogin string, byAvatar *string, inviteNames []*dto.ChatName) {
+	for _, inviteName := range inviteNames {
+		if inviteName.UserId == byUserId {
+			continue
+		}
+		var invitation = dto.NotificationEvent{
+			EventType:                  EventCallInvitation,
+			ChatId:                     chatId,
+			UserId:                     inviteName.UserId,
+			CallInvitationNotification: &dto.CallInvitationNotification{Description: inviteName.Name},
+			ByUserId:                   byUserId,
+			ByLogin:                    byLogin,
+		}
+		if byAvatar != nil && len(*byAvatar) > 0 {
+			invitation.ByAvatar = byAvatar
+		}
+
+		err := notificationPublisher.Publish(ctx, invitation)
+		if err != nil {
+			lgr.WithTracing(ctx).Errorf("Error %v", err)
+		}
+	}
+}
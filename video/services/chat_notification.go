@@ -12,6 +12,8 @@ type NotificationService struct {
 	rabbitMqRecordPublisher      *producer.RabbitRecordingPublisher
 	rabbitMqScreenSharePublisher *producer.RabbitScreenSharePublisher
 	rabbitUserIdsPublisher       *producer.RabbitUserIdsPublisher
+	rabbitRaisedHandsPublisher   *producer.RabbitRaisedHandsPublisher
+	rabbitBreakoutRoomsPublisher *producer.RabbitBreakoutRoomsPublisher
 	lgr                          *logger.Logger
 }
 
@@ -20,6 +22,8 @@ func NewNotificationService(
 	rabbitMqRecordPublisher *producer.RabbitRecordingPublisher,
 	rabbitMqScreenSharePublisher *producer.RabbitScreenSharePublisher,
 	rabbitUserIdsPublisher *producer.RabbitUserIdsPublisher,
+	rabbitRaisedHandsPublisher *producer.RabbitRaisedHandsPublisher,
+	rabbitBreakoutRoomsPublisher *producer.RabbitBreakoutRoomsPublisher,
 	lgr *logger.Logger,
 ) *NotificationService {
 	return &NotificationService{
@@ -27,6 +31,8 @@ func NewNotificationService(
 		rabbitMqScreenSharePublisher: rabbitMqScreenSharePublisher,
 		rabbitMqRecordPublisher:      rabbitMqRecordPublisher,
 		rabbitUserIdsPublisher:       rabbitUserIdsPublisher,
+		rabbitRaisedHandsPublisher:   rabbitRaisedHandsPublisher,
+		rabbitBreakoutRoomsPublisher: rabbitBreakoutRoomsPublisher,
 		lgr:                          lgr,
 	}
 }
@@ -61,3 +67,24 @@ func (h *NotificationService) NotifyRecordingChanged(ctx context.Context, chatId
 
 	return h.rabbitMqRecordPublisher.Publish(ctx, recordInProgressByOwner, chatId)
 }
+
+// sends the whole queue of raised hands to chatEvents
+func (h *NotificationService) NotifyRaisedHandsChanged(ctx context.Context, participantIds []int64, raisedHands *dto.VideoRaisedHandsChangedDto) error {
+	h.lgr.WithTracing(ctx).Debugf("Notifying about raised hands chat_id=%v", raisedHands.ChatId)
+
+	return h.rabbitRaisedHandsPublisher.Publish(ctx, participantIds, raisedHands)
+}
+
+// sends the breakout rooms with their assigned users to chatEvents
+func (h *NotificationService) NotifyBreakoutRoomsChanged(ctx context.Context, participantIds []int64, breakoutRooms *dto.VideoBreakoutRoomsChangedDto) error {
+	h.lgr.WithTracing(ctx).Debugf("Notifying about breakout rooms chat_id=%v", breakoutRooms.ChatId)
+
+	return h.rabbitBreakoutRoomsPublisher.Publish(ctx, participantIds, breakoutRooms)
+}
+
+// tells the user to reconnect to the room he has been moved to
+func (h *NotificationService) NotifyRoomChanged(ctx context.Context, userId int64, roomChanged *dto.VideoRoomChangedDto) error {
+	h.lgr.WithTracing(ctx).Debugf("Notifying about room changed chat_id=%v user_id=%v", roomChanged.ChatId, userId)
+
+	return h.rabbitBreakoutRoomsPublisher.PublishRoomChanged(ctx, userId, roomChanged)
+}
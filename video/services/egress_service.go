@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/livekit/protocol/livekit"
-	lksdk "github.com/livekit/server-sdk-go/v2"
+	"nkonev.name/video/client"
 	"nkonev.name/video/logger"
 	"nkonev.name/video/utils"
 )
@@ -13,11 +13,11 @@ import (
 const ownerIdMetadataKey = "ownerid"
 
 type EgressService struct {
-	egressClient *lksdk.EgressClient
+	egressClient client.LivekitEgressClient
 	lgr          *logger.Logger
 }
 
-func NewEgressService(egressClient *lksdk.EgressClient, lgr *logger.Logger) *EgressService {
+func NewEgressService(egressClient client.LivekitEgressClient, lgr *logger.Logger) *EgressService {
 	return &EgressService{egressClient: egressClient, lgr: lgr}
 }
 
@@ -51,24 +51,16 @@ func (rh *EgressService) GetActiveEgresses(ctx context.Context, chatId int64) (m
 func (rh *EgressService) GetOwnerId(ctx context.Context, egress *livekit.EgressInfo) (int64, error) {
 	var ownerId int64
 	wasSet := false
-	inf := egress.Request
-	ic, ok := inf.(*livekit.EgressInfo_RoomComposite)
-	if ok {
-		fileOutputs := ic.RoomComposite.FileOutputs
-		if len(fileOutputs) > 0 {
-			fileOutput := fileOutputs[0]
-			aS3 := fileOutput.GetS3()
-			if aS3 != nil {
-				ownerIdString, ok := aS3.Metadata[ownerIdMetadataKey]
-				if ok {
-					anOwnerId, err := utils.ParseInt64(ownerIdString)
-					if err != nil {
-						rh.lgr.WithTracing(ctx).Errorf("Unable to parse owner id: %v", err)
-					} else {
-						ownerId = anOwnerId
-						wasSet = true
-					}
-				}
+	metadata := getS3Metadata(egress)
+	if metadata != nil {
+		ownerIdString, ok := metadata[ownerIdMetadataKey]
+		if ok {
+			anOwnerId, err := utils.ParseInt64(ownerIdString)
+			if err != nil {
+				rh.lgr.WithTracing(ctx).Errorf("Unable to parse owner id: %v", err)
+			} else {
+				ownerId = anOwnerId
+				wasSet = true
 			}
 		}
 	}
@@ -77,3 +69,29 @@ func (rh *EgressService) GetOwnerId(ctx context.Context, egress *livekit.EgressI
 	}
 	return ownerId, nil
 }
+
+// room composite (including audio-only one), participant and track egresses carry the s3 upload in the different places
+func getS3Metadata(egress *livekit.EgressInfo) map[string]string {
+	var fileOutputs []*livekit.EncodedFileOutput
+	switch req := egress.Request.(type) {
+	case *livekit.EgressInfo_RoomComposite:
+		fileOutputs = req.RoomComposite.FileOutputs
+	case *livekit.EgressInfo_Participant:
+		fileOutputs = req.Participant.FileOutputs
+	case *livekit.EgressInfo_TrackComposite:
+		fileOutputs = req.TrackComposite.FileOutputs
+	case *livekit.EgressInfo_Track:
+		aS3 := req.Track.GetFile().GetS3()
+		if aS3 != nil {
+			return aS3.Metadata
+		}
+		return nil
+	}
+	if len(fileOutputs) > 0 {
+		aS3 := fileOutputs[0].GetS3()
+		if aS3 != nil {
+			return aS3.Metadata
+		}
+	}
+	return nil
+}
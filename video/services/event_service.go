@@ -59,15 +59,21 @@ func (h *StateChangedEventService) NotifyAllChatsAboutVideoCallUsersCount(ctx co
 		h.lgr.WithTracing(ctx).Error(err, "error during reading rooms %v", err)
 		return
 	}
+	var processedChatIds = map[int64]bool{}
 	for _, room := range rooms.Rooms {
 		chatId, err := utils.GetRoomIdFromName(room.Name)
 		if err != nil {
 			h.lgr.WithTracing(ctx).Errorf("got error during getting chat id from roomName %v %v", room.Name, err)
 			continue
 		}
+		// the main room and the breakout rooms of the chat are counted together
+		if processedChatIds[chatId] {
+			continue
+		}
+		processedChatIds[chatId] = true
 
 		// Here room.NumParticipants are zeroed, so we need to invoke service
-		usersCount, hasScreenShares, err := h.userService.CountUsers(ctx, room.Name)
+		usersCount, hasScreenShares, err := h.userService.CountUsersInChat(ctx, chatId)
 		if err != nil {
 			h.lgr.WithTracing(ctx).Errorf("got error during counting users in scheduler, %v", err)
 			continue
@@ -158,6 +164,10 @@ func (h *StateChangedEventService) NotifyAllChatsAboutVideoCallRecording(ctx con
 		return
 	}
 	for _, room := range rooms.Rooms {
+		// egresses are started only in the main room
+		if utils.IsBreakoutRoomName(room.Name) {
+			continue
+		}
 		chatId, err := utils.GetRoomIdFromName(room.Name)
 		if err != nil {
 			h.lgr.WithTracing(ctx).Errorf("got error during getting chat id from roomName %v %v", room.Name, err)
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/proto"
+	"nkonev.name/video/client"
+	"nkonev.name/video/db"
+	"nkonev.name/video/dto"
+	"nkonev.name/video/logger"
+	"nkonev.name/video/utils"
+)
+
+// sources allowed to publish for the participant without the floor in the moderated mode
+var sourcesWithoutMicrophone = []livekit.TrackSource{
+	livekit.TrackSource_CAMERA,
+	livekit.TrackSource_SCREEN_SHARE,
+	livekit.TrackSource_SCREEN_SHARE_AUDIO,
+}
+
+type RaiseHandService struct {
+	database            *db.DB
+	livekitRoomClient   client.LivekitRoomClient
+	restClient          *client.RestClient
+	notificationService *NotificationService
+	breakoutRoomService *BreakoutRoomService
+	lgr                 *logger.Logger
+}
+
+func NewRaiseHandService(database *db.DB, livekitRoomClient client.LivekitRoomClient, restClient *client.RestClient, notificationService *NotificationService, breakoutRoomService *BreakoutRoomService, lgr *logger.Logger) *RaiseHandService {
+	return &RaiseHandService{
+		database:            database,
+		livekitRoomClient:   livekitRoomClient,
+		restClient:          restClient,
+		notificationService: notificationService,
+		breakoutRoomService: breakoutRoomService,
+		lgr:                 lgr,
+	}
+}
+
+func (s *RaiseHandService) GetRaisedHands(ctx context.Context, chatId int64) (*dto.VideoRaisedHandsChangedDto, error) {
+	return db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (*dto.VideoRaisedHandsChangedDto, error) {
+		moderated, err := tx.IsModerated(ctx, chatId)
+		if err != nil {
+			return nil, err
+		}
+		hands, err := tx.GetRaisedHands(ctx, chatId)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.VideoRaisedHandsChangedDto{
+			ChatId:    chatId,
+			Moderated: moderated,
+			Hands:     hands,
+		}, nil
+	})
+}
+
+func (s *RaiseHandService) RaiseHand(ctx context.Context, chatId, userId int64) error {
+	err := db.Transact(ctx, s.database, func(tx *db.Tx) error {
+		return tx.RaiseHand(ctx, chatId, userId)
+	})
+	if err != nil {
+		return err
+	}
+	s.notifyRaisedHandsChanged(ctx, chatId)
+	return nil
+}
+
+func (s *RaiseHandService) LowerHand(ctx context.Context, chatId, userId int64) error {
+	hadFloor, err := s.removeFromQueue(ctx, chatId, userId)
+	if err != nil {
+		return err
+	}
+	if hadFloor {
+		s.updateMicrophonePermission(ctx, chatId, userId, false)
+	}
+	s.notifyRaisedHandsChanged(ctx, chatId)
+	return nil
+}
+
+func (s *RaiseHandService) GrantFloor(ctx context.Context, chatId, userId int64) error {
+	err := db.Transact(ctx, s.database, func(tx *db.Tx) error {
+		return tx.GrantFloor(ctx, chatId, userId)
+	})
+	if err != nil {
+		return err
+	}
+	s.updateMicrophonePermission(ctx, chatId, userId, true)
+	s.notifyRaisedHandsChanged(ctx, chatId)
+	return nil
+}
+
+// the floor is returned to the moderator, so the user leaves the queue
+func (s *RaiseHandService) ReturnFloor(ctx context.Context, chatId, userId int64) error {
+	_, err := s.removeFromQueue(ctx, chatId, userId)
+	if err != nil {
+		return err
+	}
+	s.updateMicrophonePermission(ctx, chatId, userId, false)
+	s.notifyRaisedHandsChanged(ctx, chatId)
+	return nil
+}
+
+func (s *RaiseHandService) SetModerated(ctx context.Context, chatId int64, moderated bool) error {
+	err := db.Transact(ctx, s.database, func(tx *db.Tx) error {
+		return tx.SetModerated(ctx, chatId, moderated)
+	})
+	if err != nil {
+		return err
+	}
+
+	// the users of the breakout rooms are the participants of the call as well
+	roomNames := []string{utils.GetRoomNameFromId(chatId)}
+	breakoutRooms, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) ([]dto.BreakoutRoom, error) {
+		return tx.GetBreakoutRooms(ctx, chatId)
+	})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get breakout rooms for chatId %v: %v", chatId, err)
+	}
+	for _, br := range breakoutRooms {
+		roomNames = append(roomNames, utils.GetBreakoutRoomName(chatId, br.Id))
+	}
+
+	for _, roomName := range roomNames {
+		// the breakout room can be not created in livekit yet
+		participants, err := s.livekitRoomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: roomName})
+		if err != nil {
+			s.lgr.WithTracing(ctx).Infof("Unable to get participants of room %v: %v", roomName, err)
+			continue
+		}
+		for _, participant := range participants.Participants {
+			s.ApplyModeration(ctx, chatId, roomName, participant)
+		}
+	}
+
+	s.notifyRaisedHandsChanged(ctx, chatId)
+	return nil
+}
+
+// restricts the microphone of the participant in case the room is moderated and the participant neither is a moderator nor has the floor
+func (s *RaiseHandService) ApplyModeration(ctx context.Context, chatId int64, roomName string, participant *livekit.ParticipantInfo) {
+	if utils.IsNotHumanUser(participant.Identity) {
+		return
+	}
+	userId, err := utils.GetUserIdFromIdentity(participant.Identity)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get userId from identity %v: %v", participant.Identity, err)
+		return
+	}
+
+	type moderationState struct {
+		moderated bool
+		hasFloor  bool
+	}
+	state, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (*moderationState, error) {
+		moderated, err := tx.IsModerated(ctx, chatId)
+		if err != nil {
+			return nil, err
+		}
+		hasFloor, err := tx.HasFloor(ctx, chatId, userId)
+		if err != nil {
+			return nil, err
+		}
+		return &moderationState{moderated: moderated, hasFloor: hasFloor}, nil
+	})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get moderation state for chatId %v: %v", chatId, err)
+		return
+	}
+
+	allowMicrophone := !state.moderated || state.hasFloor
+	if !allowMicrophone {
+		isAdmin, err := s.restClient.IsAdmin(ctx, userId, chatId)
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Error during checking is chat admin for userId %v, chatId %v: %v", userId, chatId, err)
+			return
+		}
+		allowMicrophone = isAdmin
+	}
+
+	s.setMicrophonePermission(ctx, roomName, participant, allowMicrophone)
+}
+
+// removes the user from the queue when he has left the call entirely
+func (s *RaiseHandService) OnParticipantLeft(ctx context.Context, chatId int64, roomName string, userId int64) {
+	actualRoomName, err := s.breakoutRoomService.GetRoomNameForUser(ctx, chatId, userId)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get room of userId %v in chatId %v: %v", userId, chatId, err)
+		return
+	}
+	if actualRoomName != roomName {
+		// the user is being moved to or from the breakout room
+		return
+	}
+
+	participants, err := s.livekitRoomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: actualRoomName})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get participants %v", err)
+		return
+	}
+	for _, participant := range participants.Participants {
+		md, err := utils.ParseParticipantMetadataOrNull(participant)
+		if err != nil || md == nil {
+			continue
+		}
+		if md.UserId == userId {
+			// the user is still in the call from another tab or device
+			return
+		}
+	}
+
+	removed, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (bool, error) {
+		return tx.LowerHand(ctx, chatId, userId)
+	})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to lower the hand of userId %v in chatId %v: %v", userId, chatId, err)
+		return
+	}
+	if removed {
+		s.notifyRaisedHandsChanged(ctx, chatId)
+	}
+}
+
+// returns true if the user had the floor
+func (s *RaiseHandService) removeFromQueue(ctx context.Context, chatId, userId int64) (bool, error) {
+	return db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (bool, error) {
+		hasFloor, err := tx.HasFloor(ctx, chatId, userId)
+		if err != nil {
+			return false, err
+		}
+		_, err = tx.LowerHand(ctx, chatId, userId)
+		if err != nil {
+			return false, err
+		}
+		return hasFloor, nil
+	})
+}
+
+func (s *RaiseHandService) updateMicrophonePermission(ctx context.Context, chatId, userId int64, hasFloor bool) {
+	moderated, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (bool, error) {
+		return tx.IsModerated(ctx, chatId)
+	})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get moderation state for chatId %v: %v", chatId, err)
+		return
+	}
+	if !moderated {
+		return
+	}
+
+	// the user can be in the breakout room
+	roomName, err := s.breakoutRoomService.GetRoomNameForUser(ctx, chatId, userId)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get room of userId %v in chatId %v: %v", userId, chatId, err)
+		return
+	}
+	participants, err := s.livekitRoomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: roomName})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get participants %v", err)
+		return
+	}
+	for _, participant := range participants.Participants {
+		md, err := utils.ParseParticipantMetadataOrNull(participant)
+		if err != nil || md == nil || md.UserId != userId {
+			continue
+		}
+		if hasFloor {
+			s.setMicrophonePermission(ctx, roomName, participant, true)
+		} else {
+			// moderators keep their microphone
+			s.ApplyModeration(ctx, chatId, roomName, participant)
+		}
+	}
+}
+
+func (s *RaiseHandService) setMicrophonePermission(ctx context.Context, roomName string, participant *livekit.ParticipantInfo, allowMicrophone bool) {
+	if allowMicrophone && (participant.Permission == nil || len(participant.Permission.CanPublishSources) == 0) {
+		// nothing to do - all the sources are already allowed
+		return
+	}
+
+	var permission *livekit.ParticipantPermission
+	if participant.Permission != nil {
+		permission = proto.Clone(participant.Permission).(*livekit.ParticipantPermission)
+	} else {
+		permission = &livekit.ParticipantPermission{
+			CanSubscribe:   true,
+			CanPublish:     true,
+			CanPublishData: true,
+		}
+	}
+	if allowMicrophone {
+		// empty means all the sources are allowed
+		permission.CanPublishSources = nil
+	} else {
+		permission.CanPublishSources = sourcesWithoutMicrophone
+	}
+
+	_, err := s.livekitRoomClient.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
+		Room:       roomName,
+		Identity:   participant.Identity,
+		Permission: permission,
+	})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to update permissions of %v in room %v: %v", participant.Identity, roomName, err)
+	}
+}
+
+func (s *RaiseHandService) notifyRaisedHandsChanged(ctx context.Context, chatId int64) {
+	raisedHands, err := s.GetRaisedHands(ctx, chatId)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get raised hands for chatId %v: %v", chatId, err)
+		return
+	}
+
+	err = s.restClient.GetChatParticipantIds(ctx, chatId, func(participantIds []int64) error {
+		return s.notificationService.NotifyRaisedHandsChanged(ctx, participantIds, raisedHands)
+	})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to notify about raised hands for chatId %v: %v", chatId, err)
+	}
+}
@@ -0,0 +1,696 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/teambition/rrule-go"
+	"nkonev.name/video/client"
+	"nkonev.name/video/db"
+	"nkonev.name/video/dto"
+	"nkonev.name/video/logger"
+	"nkonev.name/video/producer"
+	"nkonev.name/video/utils"
+)
+
+const EventMeetingReminder = "meeting_reminder"
+
+var ErrScheduledMeetingNotFound = errors.New("scheduled meeting not found")
+var ErrInvalidRrule = errors.New("invalid recurrence rule")
+var ErrInvalidTimeZone = errors.New("invalid time zone")
+var ErrCalendarTokenNotFound = errors.New("calendar token not found")
+
+type ScheduledMeetingService struct {
+	database                 *db.DB
+	chatClient               *client.RestClient
+	notificationPublisher    *producer.RabbitNotificationsPublisher
+	stateChangedEventService *StateChangedEventService
+	lgr                      *logger.Logger
+}
+
+func NewScheduledMeetingService(database *db.DB, chatClient *client.RestClient, notificationPublisher *producer.RabbitNotificationsPublisher, stateChangedEventService *StateChangedEventService, lgr *logger.Logger) *ScheduledMeetingService {
+	return &ScheduledMeetingService{
+		database:                 database,
+		chatClient:               chatClient,
+		notificationPublisher:    notificationPublisher,
+		stateChangedEventService: stateChangedEventService,
+		lgr:                      lgr,
+	}
+}
+
+// accepts the rule both with and without "RRULE:" prefix
+func normalizeRrule(rule *string) *string {
+	if rule == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(*rule), "RRULE:"))
+	if len(trimmed) == 0 {
+		return nil
+	}
+	return &trimmed
+}
+
+// the occurrences are expanded in loc, so 10:00 stays 10:00 after the daylight saving time change
+func parseRrule(rule string, start time.Time, loc *time.Location) (*rrule.RRule, error) {
+	opt, err := rrule.StrToROption(rule)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidRrule, err)
+	}
+	opt.Dtstart = start.In(loc)
+	r, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidRrule, err)
+	}
+	return r, nil
+}
+
+func loadMeetingLocation(timeZone string) (*time.Location, error) {
+	if len(timeZone) == 0 {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidTimeZone, err)
+	}
+	return loc, nil
+}
+
+// returns the start of the first occurrence in UTC which is at or after the given time, nil if there is no such
+func getNextOccurrence(m *dto.ScheduledMeeting, after time.Time) (*time.Time, error) {
+	if m.Rrule == nil {
+		if m.StartTime.Before(after) {
+			return nil, nil
+		}
+		return &m.StartTime, nil
+	}
+	loc, err := loadMeetingLocation(m.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	r, err := parseRrule(*m.Rrule, m.StartTime, loc)
+	if err != nil {
+		return nil, err
+	}
+	next := r.After(after, true)
+	if next.IsZero() {
+		return nil, nil
+	}
+	next = next.UTC()
+	return &next, nil
+}
+
+// the occurrence which follows the started one, nil for the one-time meeting or at the end of the series
+func getOccurrenceAfter(m *dto.ScheduledMeeting, occurrence time.Time) (*time.Time, error) {
+	return getNextOccurrence(m, occurrence.Add(time.Nanosecond))
+}
+
+// normalizes and checks the schedule before saving
+func prepareSchedule(meeting *dto.ScheduledMeeting) error {
+	meeting.StartTime = meeting.StartTime.UTC()
+	meeting.Rrule = normalizeRrule(meeting.Rrule)
+	if len(meeting.TimeZone) == 0 {
+		meeting.TimeZone = db.DefaultTimeZone
+	}
+	loc, err := loadMeetingLocation(meeting.TimeZone)
+	if err != nil {
+		return err
+	}
+	if meeting.Rrule != nil {
+		if _, err := parseRrule(*meeting.Rrule, meeting.StartTime, loc); err != nil {
+			return err
+		}
+	}
+	// the passed occurrences are skipped by the scheduler
+	meeting.NextOccurrence, err = getNextOccurrence(meeting, meeting.StartTime)
+	return err
+}
+
+func (s *ScheduledMeetingService) convertToDto(m *dto.ScheduledMeeting, inviteeIds []int64) dto.ScheduledMeetingDto {
+	if inviteeIds == nil {
+		inviteeIds = []int64{}
+	}
+	nextOccurrence, err := getNextOccurrence(m, time.Now().UTC())
+	if err != nil {
+		s.lgr.Errorf("Unable to get the next occurrence of meeting %v: %v", m.Id, err)
+	}
+	return dto.ScheduledMeetingDto{
+		Id:              m.Id,
+		ChatId:          m.ChatId,
+		OwnerId:         m.OwnerId,
+		Title:           m.Title,
+		StartTime:       m.StartTime,
+		DurationSeconds: m.DurationSeconds,
+		Rrule:           m.Rrule,
+		TimeZone:        m.TimeZone,
+		InviteeIds:      inviteeIds,
+		NextOccurrence:  nextOccurrence,
+	}
+}
+
+func (s *ScheduledMeetingService) convertToDtos(ctx context.Context, tx *db.Tx, meetings []dto.ScheduledMeeting) ([]dto.ScheduledMeetingDto, error) {
+	meetingIds := make([]int64, 0, len(meetings))
+	for _, m := range meetings {
+		meetingIds = append(meetingIds, m.Id)
+	}
+	invitees, err := tx.GetScheduledMeetingInvitees(ctx, meetingIds)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]dto.ScheduledMeetingDto, 0, len(meetings))
+	for _, m := range meetings {
+		ret = append(ret, s.convertToDto(&m, invitees[m.Id]))
+	}
+	return ret, nil
+}
+
+func (s *ScheduledMeetingService) GetScheduledMeetings(ctx context.Context, chatId int64) ([]dto.ScheduledMeetingDto, error) {
+	return db.TransactWithResult(ctx, s.database, func(tx *db.Tx) ([]dto.ScheduledMeetingDto, error) {
+		meetings, err := tx.GetScheduledMeetings(ctx, chatId)
+		if err != nil {
+			return nil, err
+		}
+		return s.convertToDtos(ctx, tx, meetings)
+	})
+}
+
+func (s *ScheduledMeetingService) GetScheduledMeeting(ctx context.Context, chatId, meetingId int64) (*dto.ScheduledMeetingDto, error) {
+	return db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (*dto.ScheduledMeetingDto, error) {
+		m, err := tx.GetScheduledMeeting(ctx, chatId, meetingId)
+		if err != nil {
+			return nil, err
+		}
+		if m == nil {
+			return nil, ErrScheduledMeetingNotFound
+		}
+		dtos, err := s.convertToDtos(ctx, tx, []dto.ScheduledMeeting{*m})
+		if err != nil {
+			return nil, err
+		}
+		return &dtos[0], nil
+	})
+}
+
+// the owner, login and avatar are taken only from the creating, the rest fields are taken from meeting
+func (s *ScheduledMeetingService) CreateScheduledMeeting(ctx context.Context, meeting *dto.ScheduledMeeting, inviteeIds []int64) (*dto.ScheduledMeetingDto, error) {
+	if err := prepareSchedule(meeting); err != nil {
+		return nil, err
+	}
+
+	return db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (*dto.ScheduledMeetingDto, error) {
+		id, err := tx.CreateScheduledMeeting(ctx, meeting)
+		if err != nil {
+			return nil, err
+		}
+		err = tx.SetScheduledMeetingInvitees(ctx, id, inviteeIds)
+		if err != nil {
+			return nil, err
+		}
+		m, err := tx.GetScheduledMeeting(ctx, meeting.ChatId, id)
+		if err != nil {
+			return nil, err
+		}
+		dtos, err := s.convertToDtos(ctx, tx, []dto.ScheduledMeeting{*m})
+		if err != nil {
+			return nil, err
+		}
+		return &dtos[0], nil
+	})
+}
+
+func (s *ScheduledMeetingService) UpdateScheduledMeeting(ctx context.Context, meeting *dto.ScheduledMeeting, inviteeIds []int64) (*dto.ScheduledMeetingDto, error) {
+	if err := prepareSchedule(meeting); err != nil {
+		return nil, err
+	}
+
+	return db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (*dto.ScheduledMeetingDto, error) {
+		existing, err := tx.GetScheduledMeeting(ctx, meeting.ChatId, meeting.Id)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, ErrScheduledMeetingNotFound
+		}
+		err = tx.UpdateScheduledMeeting(ctx, meeting)
+		if err != nil {
+			return nil, err
+		}
+		err = tx.SetScheduledMeetingInvitees(ctx, meeting.Id, inviteeIds)
+		if err != nil {
+			return nil, err
+		}
+		m, err := tx.GetScheduledMeeting(ctx, meeting.ChatId, meeting.Id)
+		if err != nil {
+			return nil, err
+		}
+		dtos, err := s.convertToDtos(ctx, tx, []dto.ScheduledMeeting{*m})
+		if err != nil {
+			return nil, err
+		}
+		return &dtos[0], nil
+	})
+}
+
+// returns the owner of the meeting in order to check the permissions
+func (s *ScheduledMeetingService) GetOwnerId(ctx context.Context, chatId, meetingId int64) (int64, error) {
+	return db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (int64, error) {
+		m, err := tx.GetScheduledMeeting(ctx, chatId, meetingId)
+		if err != nil {
+			return 0, err
+		}
+		if m == nil {
+			return 0, ErrScheduledMeetingNotFound
+		}
+		return m.OwnerId, nil
+	})
+}
+
+func (s *ScheduledMeetingService) DeleteScheduledMeeting(ctx context.Context, chatId, meetingId int64) error {
+	return db.Transact(ctx, s.database, func(tx *db.Tx) error {
+		return tx.DeleteScheduledMeeting(ctx, chatId, meetingId)
+	})
+}
+
+// invoked by scheduler
+// reminds remindBefore before the start of an occurrence and rings the invitees at the start
+// the occurrence which has begun earlier than startGracePeriod ago is skipped
+func (s *ScheduledMeetingService) ProcessDueMeetings(ctx context.Context, remindBefore, startGracePeriod time.Duration) {
+	now := time.Now().UTC()
+	afterId := int64(0)
+	hasMoreElements := true
+	for hasMoreElements {
+		meetings, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) ([]dto.ScheduledMeeting, error) {
+			return tx.GetScheduledMeetingsToProcess(ctx, now.Add(remindBefore), afterId, utils.DefaultSize)
+		})
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to get scheduled meetings: %v", err)
+			return
+		}
+
+		for _, m := range meetings {
+			s.processMeeting(ctx, &m, now, remindBefore, startGracePeriod)
+			afterId = m.Id
+		}
+
+		hasMoreElements = len(meetings) == utils.DefaultSize
+	}
+}
+
+func (s *ScheduledMeetingService) processMeeting(ctx context.Context, m *dto.ScheduledMeeting, now time.Time, remindBefore, startGracePeriod time.Duration) {
+	occurrence, err := getNextOccurrence(m, now.Add(-startGracePeriod))
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get the next occurrence of meeting %v: %v", m.Id, err)
+		return
+	}
+	if occurrence == nil {
+		// the meeting has passed or its series has ended, so the scheduler doesn't scan it anymore
+		s.setNextOccurrence(ctx, m, nil)
+		return
+	}
+	if m.NextOccurrence == nil || !m.NextOccurrence.Equal(*occurrence) {
+		// the occurrences which have begun earlier than startGracePeriod ago are skipped
+		s.setNextOccurrence(ctx, m, occurrence)
+	}
+
+	invitees, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (map[int64][]int64, error) {
+		return tx.GetScheduledMeetingInvitees(ctx, []int64{m.Id})
+	})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get invitees of meeting %v: %v", m.Id, err)
+		return
+	}
+	inviteeIds := invitees[m.Id]
+
+	alreadyReminded := m.LastRemindedAt != nil && !m.LastRemindedAt.Before(*occurrence)
+	if now.Before(*occurrence) && !now.Before(occurrence.Add(-remindBefore)) && !alreadyReminded {
+		s.lgr.WithTracing(ctx).Infof("Reminding about meeting %v of chatId %v starting at %v", m.Id, m.ChatId, *occurrence)
+		// the owner is reminded as well
+		s.remind(ctx, m, *occurrence, append([]int64{m.OwnerId}, inviteeIds...))
+		err = db.Transact(ctx, s.database, func(tx *db.Tx) error {
+			return tx.SetScheduledMeetingReminded(ctx, m.Id, *occurrence)
+		})
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to mark meeting %v as reminded: %v", m.Id, err)
+		}
+	}
+
+	alreadyStarted := m.LastStartedAt != nil && !m.LastStartedAt.Before(*occurrence)
+	if !now.Before(*occurrence) && !alreadyStarted {
+		s.lgr.WithTracing(ctx).Infof("Starting meeting %v of chatId %v scheduled at %v", m.Id, m.ChatId, *occurrence)
+		s.ring(ctx, m, inviteeIds)
+		nextOccurrence, err := getOccurrenceAfter(m, *occurrence)
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to get the occurrence of meeting %v after %v: %v", m.Id, *occurrence, err)
+			return
+		}
+		err = db.Transact(ctx, s.database, func(tx *db.Tx) error {
+			return tx.SetScheduledMeetingStarted(ctx, m.Id, *occurrence, nextOccurrence)
+		})
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to mark meeting %v as started: %v", m.Id, err)
+		}
+	}
+}
+
+func (s *ScheduledMeetingService) setNextOccurrence(ctx context.Context, m *dto.ScheduledMeeting, nextOccurrence *time.Time) {
+	err := db.Transact(ctx, s.database, func(tx *db.Tx) error {
+		return tx.SetScheduledMeetingNextOccurrence(ctx, m.Id, nextOccurrence)
+	})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to set the next occurrence of meeting %v: %v", m.Id, err)
+	}
+}
+
+func (s *ScheduledMeetingService) remind(ctx context.Context, m *dto.ScheduledMeeting, occurrence time.Time, userIds []int64) {
+	uniqueUserIds := []int64{}
+	for _, userId := range userIds {
+		if !utils.Contains(uniqueUserIds, userId) {
+			uniqueUserIds = append(uniqueUserIds, userId)
+		}
+	}
+
+	chatNames, err := s.chatClient.GetChatNameForInvite(ctx, m.ChatId, m.OwnerId, uniqueUserIds)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get chat names for meeting %v: %v", m.Id, err)
+		return
+	}
+	for _, chatName := range chatNames {
+		err = s.notificationPublisher.Publish(ctx, dto.NotificationEvent{
+			EventType: EventMeetingReminder,
+			ChatId:    m.ChatId,
+			UserId:    chatName.UserId,
+			ByUserId:  m.OwnerId,
+			ByLogin:   m.OwnerLogin,
+			ByAvatar:  m.OwnerAvatar,
+			ChatTitle: chatName.Name,
+			MeetingReminderNotification: &dto.MeetingReminderNotification{
+				MeetingId:   m.Id,
+				Description: m.Title,
+				StartTime:   occurrence,
+			},
+		})
+		if err != nil {
+			s.lgr.WithTracing(ctx).Errorf("Unable to send the reminder about meeting %v to userId %v: %v", m.Id, chatName.UserId, err)
+		}
+	}
+}
+
+// puts the invitees to the dial list on behalf of the owner, the further ringing is made by ChatDialerService
+// the invitees who are already in a call or are being called are skipped
+func (s *ScheduledMeetingService) ring(ctx context.Context, m *dto.ScheduledMeeting, inviteeIds []int64) {
+	basicChatInfo, err := s.chatClient.GetBasicChatInfo(ctx, m.ChatId, m.OwnerId)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get basic chat info for meeting %v: %v", m.Id, err)
+		return
+	}
+
+	ownerTokenId := uuid.New()
+	invitedUserIds, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) ([]int64, error) {
+		invitedUserIds := []int64{}
+		for _, inviteeId := range inviteeIds {
+			if inviteeId == m.OwnerId {
+				continue
+			}
+
+			states, err := tx.GetByCalleeUserIdFromAllChats(ctx, inviteeId)
+			if err != nil {
+				return nil, err
+			}
+			canOverride := true
+			statesToRemove := []dto.UserCallStateId{}
+			for _, st := range states {
+				if !db.CanOverrideCallStatus(st.Status) {
+					canOverride = false
+					break
+				}
+				statesToRemove = append(statesToRemove, dto.UserCallStateId{TokenId: st.TokenId, UserId: st.UserId})
+			}
+			if !canOverride {
+				s.lgr.WithTracing(ctx).Infof("Skipping ringing userId %v for meeting %v because of non-overridable status", inviteeId, m.Id)
+				continue
+			}
+			err = tx.RemoveByUserCallStates(ctx, statesToRemove)
+			if err != nil {
+				return nil, err
+			}
+
+			err = tx.Set(ctx, dto.UserCallState{
+				TokenId:      uuid.New(),
+				UserId:       inviteeId,
+				ChatId:       m.ChatId,
+				TokenTaken:   false,
+				OwnerTokenId: &ownerTokenId,
+				OwnerUserId:  &m.OwnerId,
+				Status:       db.CallStatusBeingInvited,
+				ChatTetATet:  basicChatInfo.TetATet,
+				OwnerAvatar:  m.OwnerAvatar,
+			})
+			if err != nil {
+				return nil, err
+			}
+			invitedUserIds = append(invitedUserIds, inviteeId)
+		}
+		return invitedUserIds, nil
+	})
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to ring invitees of meeting %v: %v", m.Id, err)
+		return
+	}
+	if len(invitedUserIds) == 0 {
+		return
+	}
+
+	inviteNames, err := s.chatClient.GetChatNameForInvite(ctx, m.ChatId, m.OwnerId, invitedUserIds)
+	if err != nil {
+		s.lgr.WithTracing(ctx).Errorf("Unable to get chat invite names for meeting %v: %v", m.Id, err)
+		return
+	}
+	statuses := map[int64]string{}
+	for _, userId := range invitedUserIds {
+		statuses[userId] = db.CallStatusBeingInvited
+	}
+	// for better user experience, otherwise the invitees would wait for ChatDialerService
+	s.stateChangedEventService.SendDialEvents(ctx, m.ChatId, statuses, m.OwnerId, utils.NullToEmpty(m.OwnerAvatar), basicChatInfo.TetATet, inviteNames)
+	sendCallInvitationNotifications(ctx, s.notificationPublisher, s.lgr, m.ChatId, m.OwnerId, m.OwnerLogin, m.OwnerAvatar, inviteNames)
+}
+
+// RFC 5545 calendar with the meetings where the user is either the owner or the invitee
+func (s *ScheduledMeetingService) GetCalendar(ctx context.Context, userId int64) (string, error) {
+	meetings, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) ([]dto.ScheduledMeeting, error) {
+		return tx.GetScheduledMeetingsOfUser(ctx, userId)
+	})
+	if err != nil {
+		return "", err
+	}
+	return buildCalendar(meetings, time.Now().UTC()), nil
+}
+
+// the feed for calendar applications, they can't pass the session so the token is the part of the url
+func (s *ScheduledMeetingService) GetCalendarByToken(ctx context.Context, token uuid.UUID) (string, error) {
+	userId, err := db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (*int64, error) {
+		return tx.GetUserIdByCalendarToken(ctx, token)
+	})
+	if err != nil {
+		return "", err
+	}
+	if userId == nil {
+		return "", ErrCalendarTokenNotFound
+	}
+	return s.GetCalendar(ctx, *userId)
+}
+
+// creates the token on the first request
+func (s *ScheduledMeetingService) GetOrCreateCalendarToken(ctx context.Context, userId int64) (uuid.UUID, error) {
+	return db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (uuid.UUID, error) {
+		token, err := tx.GetCalendarToken(ctx, userId)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if token != nil {
+			return *token, nil
+		}
+		newToken := uuid.New()
+		return newToken, tx.SetCalendarToken(ctx, userId, newToken)
+	})
+}
+
+// revokes the previous feed url
+func (s *ScheduledMeetingService) RegenerateCalendarToken(ctx context.Context, userId int64) (uuid.UUID, error) {
+	return db.TransactWithResult(ctx, s.database, func(tx *db.Tx) (uuid.UUID, error) {
+		newToken := uuid.New()
+		return newToken, tx.SetCalendarToken(ctx, userId, newToken)
+	})
+}
+
+func (s *ScheduledMeetingService) RevokeCalendarToken(ctx context.Context, userId int64) error {
+	return db.Transact(ctx, s.database, func(tx *db.Tx) error {
+		return tx.DeleteCalendarToken(ctx, userId)
+	})
+}
+
+// the meetings in the non-UTC time zones are written in their local time with TZID, so the calendar applications expand the recurrence the same way
+func buildCalendar(meetings []dto.ScheduledMeeting, now time.Time) string {
+	// the time zone -> the earliest start of its meetings
+	timeZoneStarts := map[string]time.Time{}
+	timeZones := []string{}
+	for _, m := range meetings {
+		if m.TimeZone == db.DefaultTimeZone || len(m.TimeZone) == 0 {
+			continue
+		}
+		if _, err := time.LoadLocation(m.TimeZone); err != nil {
+			continue
+		}
+		if existing, ok := timeZoneStarts[m.TimeZone]; !ok {
+			timeZoneStarts[m.TimeZone] = m.StartTime
+			timeZones = append(timeZones, m.TimeZone)
+		} else if m.StartTime.Before(existing) {
+			timeZoneStarts[m.TimeZone] = m.StartTime
+		}
+	}
+
+	var sb strings.Builder
+	writeIcsLine(&sb, "BEGIN:VCALENDAR")
+	writeIcsLine(&sb, "VERSION:2.0")
+	writeIcsLine(&sb, "PRODID:-//nkonev.name//videochat//EN")
+	writeIcsLine(&sb, "CALSCALE:GREGORIAN")
+	writeIcsLine(&sb, "METHOD:PUBLISH")
+	for _, timeZone := range timeZones {
+		loc, _ := time.LoadLocation(timeZone)
+		start := timeZoneStarts[timeZone]
+		lastYear := max(start.Year(), now.Year())
+		writeVtimezone(&sb, loc, time.Date(start.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(lastYear+vtimezoneYearsAhead, time.January, 1, 0, 0, 0, 0, time.UTC))
+	}
+	for _, m := range meetings {
+		end := m.StartTime.Add(time.Duration(m.DurationSeconds) * time.Second)
+		writeIcsLine(&sb, "BEGIN:VEVENT")
+		writeIcsLine(&sb, fmt.Sprintf("UID:meeting-%v@videochat", m.Id))
+		writeIcsLine(&sb, "DTSTAMP:"+formatIcsTime(m.CreateDateTime))
+		if _, ok := timeZoneStarts[m.TimeZone]; ok {
+			loc, _ := time.LoadLocation(m.TimeZone)
+			writeIcsLine(&sb, "DTSTART;TZID="+m.TimeZone+":"+formatIcsLocalTime(m.StartTime.In(loc)))
+			writeIcsLine(&sb, "DTEND;TZID="+m.TimeZone+":"+formatIcsLocalTime(end.In(loc)))
+		} else {
+			writeIcsLine(&sb, "DTSTART:"+formatIcsTime(m.StartTime))
+			writeIcsLine(&sb, "DTEND:"+formatIcsTime(end))
+		}
+		writeIcsLine(&sb, "SUMMARY:"+escapeIcsText(m.Title))
+		if m.Rrule != nil {
+			writeIcsLine(&sb, "RRULE:"+*m.Rrule)
+		}
+		writeIcsLine(&sb, "END:VEVENT")
+	}
+	writeIcsLine(&sb, "END:VCALENDAR")
+	return sb.String()
+}
+
+// how many years after the current one the VTIMEZONE describes
+const vtimezoneYearsAhead = 2
+
+// writes the observances of the time zone which are in effect in [from, to)
+// they are taken from the tz database as the list of transitions, because go doesn't expose the rules
+func writeVtimezone(sb *strings.Builder, loc *time.Location, from, to time.Time) {
+	writeIcsLine(sb, "BEGIN:VTIMEZONE")
+	writeIcsLine(sb, "TZID:"+loc.String())
+	// the observance which is in effect at the beginning
+	_, offset := from.In(loc).Zone()
+	writeObservance(sb, from.In(loc), offset)
+	for _, transition := range getZoneTransitions(loc, from, to) {
+		writeObservance(sb, transition.In(loc), offset)
+		_, offset = transition.In(loc).Zone()
+	}
+	writeIcsLine(sb, "END:VTIMEZONE")
+}
+
+// t is the moment of the transition in the new offset, offsetFrom is the previous one
+func writeObservance(sb *strings.Builder, t time.Time, offsetFrom int) {
+	name, offsetTo := t.Zone()
+	kind := "STANDARD"
+	if t.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	writeIcsLine(sb, "BEGIN:"+kind)
+	// the local time is in the previous offset as RFC 5545 requires
+	writeIcsLine(sb, "DTSTART:"+formatIcsLocalTime(t.In(time.FixedZone("", offsetFrom))))
+	writeIcsLine(sb, "TZOFFSETFROM:"+formatIcsOffset(offsetFrom))
+	writeIcsLine(sb, "TZOFFSETTO:"+formatIcsOffset(offsetTo))
+	writeIcsLine(sb, "TZNAME:"+escapeIcsText(name))
+	writeIcsLine(sb, "END:"+kind)
+}
+
+// returns the moments when the utc offset changes
+func getZoneTransitions(loc *time.Location, from, to time.Time) []time.Time {
+	ret := []time.Time{}
+	const step = 24 * time.Hour
+	for t := from; t.Before(to); t = t.Add(step) {
+		next := t.Add(step)
+		_, offset := t.In(loc).Zone()
+		_, nextOffset := next.In(loc).Zone()
+		if offset == nextOffset {
+			continue
+		}
+		// the first second with the new offset
+		low, high := t, next
+		for high.Sub(low) > time.Second {
+			middle := low.Add(high.Sub(low) / 2).Truncate(time.Second)
+			if _, middleOffset := middle.In(loc).Zone(); middleOffset == offset {
+				low = middle
+			} else {
+				high = middle
+			}
+		}
+		ret = append(ret, high)
+	}
+	return ret
+}
+
+func formatIcsLocalTime(t time.Time) string {
+	return t.Format("20060102T150405")
+}
+
+func formatIcsOffset(offsetSeconds int) string {
+	sign := "+"
+	if offsetSeconds < 0 {
+		sign = "-"
+		offsetSeconds = -offsetSeconds
+	}
+	return fmt.Sprintf("%v%02d%02d", sign, offsetSeconds/3600, offsetSeconds%3600/60)
+}
+
+func formatIcsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func escapeIcsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// folds the line longer than 75 octets as RFC 5545 requires, not splitting utf-8 sequences
+func writeIcsLine(sb *strings.Builder, line string) {
+	const maxOctets = 75
+	first := true
+	for len(line) > 0 {
+		limit := maxOctets
+		if !first {
+			// the leading space is counted
+			limit = maxOctets - 1
+			sb.WriteString(" ")
+		}
+		if len(line) <= limit {
+			sb.WriteString(line)
+			break
+		}
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		sb.WriteString(line[:cut])
+		sb.WriteString("\r\n")
+		line = line[cut:]
+		first = false
+	}
+	sb.WriteString("\r\n")
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nkonev.name/video/dto"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// 10:00 in Berlin on Monday, 2 March 2026, which is before the daylight saving time change on 29 March
+var berlinMonday = time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+
+func TestGetNextOccurrenceKeepsLocalTimeAcrossDst(t *testing.T) {
+	cases := []struct {
+		name     string
+		timeZone string
+		after    time.Time
+		expected time.Time
+	}{
+		{"before the change", "Europe/Berlin", time.Date(2026, time.March, 20, 0, 0, 0, 0, time.UTC), time.Date(2026, time.March, 23, 9, 0, 0, 0, time.UTC)},
+		{"after the change to summer time", "Europe/Berlin", time.Date(2026, time.March, 28, 0, 0, 0, 0, time.UTC), time.Date(2026, time.March, 30, 8, 0, 0, 0, time.UTC)},
+		{"after the change back to winter time", "Europe/Berlin", time.Date(2026, time.October, 26, 0, 0, 0, 0, time.UTC), time.Date(2026, time.October, 26, 9, 0, 0, 0, time.UTC)},
+		{"utc doesn't shift", "UTC", time.Date(2026, time.March, 28, 0, 0, 0, 0, time.UTC), time.Date(2026, time.March, 30, 9, 0, 0, 0, time.UTC)},
+		{"empty is utc", "", time.Date(2026, time.March, 28, 0, 0, 0, 0, time.UTC), time.Date(2026, time.March, 30, 9, 0, 0, 0, time.UTC)},
+		{"southern hemisphere", "America/Sao_Paulo", time.Date(2026, time.March, 28, 0, 0, 0, 0, time.UTC), time.Date(2026, time.March, 30, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := &dto.ScheduledMeeting{StartTime: berlinMonday, Rrule: ptr("FREQ=WEEKLY;BYDAY=MO"), TimeZone: c.timeZone}
+			next, err := getNextOccurrence(m, c.after)
+			require.NoError(t, err)
+			require.NotNil(t, next)
+			assert.Equal(t, c.expected, *next)
+			assert.Equal(t, time.UTC, next.Location())
+		})
+	}
+}
+
+func TestPrepareSchedule(t *testing.T) {
+	m := &dto.ScheduledMeeting{StartTime: berlinMonday, Rrule: ptr(" RRULE:FREQ=DAILY ")}
+	require.NoError(t, prepareSchedule(m))
+	assert.Equal(t, "UTC", m.TimeZone)
+	assert.Equal(t, "FREQ=DAILY", *m.Rrule)
+
+	require.NotNil(t, m.NextOccurrence)
+	assert.Equal(t, berlinMonday, *m.NextOccurrence)
+
+	assert.ErrorIs(t, prepareSchedule(&dto.ScheduledMeeting{StartTime: berlinMonday, TimeZone: "Mars/Olympus_Mons"}), ErrInvalidTimeZone)
+	assert.ErrorIs(t, prepareSchedule(&dto.ScheduledMeeting{StartTime: berlinMonday, Rrule: ptr("FREQ=SOMETIMES"), TimeZone: "Europe/Berlin"}), ErrInvalidRrule)
+}
+
+func TestGetOccurrenceAfter(t *testing.T) {
+	daily := &dto.ScheduledMeeting{StartTime: berlinMonday, Rrule: ptr("FREQ=DAILY;COUNT=2"), TimeZone: "Europe/Berlin"}
+	next, err := getOccurrenceAfter(daily, berlinMonday)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, berlinMonday.AddDate(0, 0, 1), *next)
+
+	// the series has ended
+	next, err = getOccurrenceAfter(daily, *next)
+	require.NoError(t, err)
+	assert.Nil(t, next)
+
+	once := &dto.ScheduledMeeting{StartTime: berlinMonday, TimeZone: "UTC"}
+	next, err = getOccurrenceAfter(once, berlinMonday)
+	require.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestGetZoneTransitions(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	transitions := getZoneTransitions(berlin, time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, []time.Time{
+		time.Date(2026, time.March, 29, 1, 0, 0, 0, time.UTC),
+		time.Date(2026, time.October, 25, 1, 0, 0, 0, time.UTC),
+	}, transitions)
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	assert.Empty(t, getZoneTransitions(tokyo, time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestBuildCalendar(t *testing.T) {
+	now := time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2026, time.January, 15, 12, 0, 0, 0, time.UTC)
+	calendar := buildCalendar([]dto.ScheduledMeeting{
+		{Id: 1, Title: "Weekly", StartTime: berlinMonday, DurationSeconds: 1800, Rrule: ptr("FREQ=WEEKLY;BYDAY=MO"), TimeZone: "Europe/Berlin", CreateDateTime: created},
+		{Id: 2, Title: "Once", StartTime: berlinMonday, DurationSeconds: 3600, TimeZone: "UTC", CreateDateTime: created},
+		{Id: 3, Title: "Also weekly", StartTime: berlinMonday.Add(time.Hour), DurationSeconds: 1800, Rrule: ptr("FREQ=WEEKLY"), TimeZone: "Europe/Berlin", CreateDateTime: created},
+	}, now)
+
+	// the recurring meeting is in the local time, so calendar applications expand it the same way
+	assert.Contains(t, calendar, "DTSTART;TZID=Europe/Berlin:20260302T100000\r\nDTEND;TZID=Europe/Berlin:20260302T103000\r\n")
+	assert.Contains(t, calendar, "DTSTART;TZID=Europe/Berlin:20260302T110000\r\n")
+	assert.Contains(t, calendar, "RRULE:FREQ=WEEKLY;BYDAY=MO\r\n")
+	assert.Contains(t, calendar, "DTSTART:20260302T090000Z\r\nDTEND:20260302T100000Z\r\n")
+
+	// the one time zone is described once
+	assert.Equal(t, 1, strings.Count(calendar, "BEGIN:VTIMEZONE\r\n"))
+	assert.Equal(t, 1, strings.Count(calendar, "TZID:Europe/Berlin\r\n"))
+	assert.Contains(t, calendar, "BEGIN:STANDARD\r\nDTSTART:20260101T010000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0100\r\nTZNAME:CET\r\nEND:STANDARD\r\n")
+	assert.Contains(t, calendar, "BEGIN:DAYLIGHT\r\nDTSTART:20260329T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nTZNAME:CEST\r\nEND:DAYLIGHT\r\n")
+	assert.Contains(t, calendar, "BEGIN:STANDARD\r\nDTSTART:20261025T030000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nTZNAME:CET\r\nEND:STANDARD\r\n")
+	// till the end of the next years
+	assert.Contains(t, calendar, "DTSTART:20271031T030000\r\n")
+	assert.NotContains(t, calendar, "DTSTART:20281029T030000\r\n")
+	assert.Less(t, strings.Index(calendar, "END:VTIMEZONE"), strings.Index(calendar, "BEGIN:VEVENT"))
+}
+
+func TestBuildCalendarWithoutTimeZones(t *testing.T) {
+	calendar := buildCalendar([]dto.ScheduledMeeting{
+		{Id: 1, Title: "Once", StartTime: berlinMonday, DurationSeconds: 3600, TimeZone: "UTC"},
+	}, berlinMonday)
+
+	assert.NotContains(t, calendar, "VTIMEZONE")
+	assert.True(t, strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(calendar, "END:VCALENDAR\r\n"))
+}
+
+func TestWriteIcsLineFolds(t *testing.T) {
+	var sb strings.Builder
+	writeIcsLine(&sb, "SUMMARY:"+strings.Repeat("ж", 60))
+
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\r\n"), "\r\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.True(t, strings.HasPrefix(lines[1], " "))
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("ж", 60), lines[0]+strings.TrimPrefix(lines[1], " "))
+}
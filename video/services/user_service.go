@@ -36,56 +36,89 @@ func NewUserService(livekitRoomClient client.LivekitRoomClient, database *db.DB,
 	}
 }
 
-func (h *UserService) CountUsers(ctx context.Context, roomName string) (int64, bool, error) {
-	var req *livekit.ListParticipantsRequest = &livekit.ListParticipantsRequest{Room: roomName}
-	participants, err := h.livekitRoomClient.ListParticipants(ctx, req)
+// returns the main room and the breakout rooms of the chat
+func (h *UserService) GetRoomNames(ctx context.Context, chatId int64) ([]string, error) {
+	breakoutRooms, err := db.TransactWithResult(ctx, h.database, func(tx *db.Tx) ([]dto.BreakoutRoom, error) {
+		return tx.GetBreakoutRooms(ctx, chatId)
+	})
 	if err != nil {
-		return 0, false, err
+		return nil, err
+	}
+	ret := []string{utils.GetRoomNameFromId(chatId)}
+	for _, br := range breakoutRooms {
+		ret = append(ret, utils.GetBreakoutRoomName(chatId, br.Id))
 	}
+	return ret, nil
+}
 
-	var hasScreenShares = false
-	for _, p := range participants.Participants {
-		for _, t := range p.Tracks {
-			if t.Source == livekit.TrackSource_SCREEN_SHARE {
-				hasScreenShares = true
-				break
+// lists participants of the main room and of the breakout rooms
+// breakout room can be not created in livekit yet, so its errors are ignored
+func (h *UserService) listChatParticipants(ctx context.Context, chatId int64) (map[string][]*livekit.ParticipantInfo, error) {
+	roomNames, err := h.GetRoomNames(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string][]*livekit.ParticipantInfo{}
+	for _, roomName := range roomNames {
+		participants, err := h.livekitRoomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: roomName})
+		if err != nil {
+			if utils.IsBreakoutRoomName(roomName) {
+				h.lgr.WithTracing(ctx).Debugf("Unable to get participants of breakout room %v: %v", roomName, err)
+				continue
 			}
+			return nil, err
 		}
-		if hasScreenShares {
-			break
-		}
+		ret[roomName] = participants.Participants
 	}
+	return ret, nil
+}
 
-	var usersCount = int64(len(participants.Participants))
+// counts users of the main room together with the breakout rooms
+func (h *UserService) CountUsersInChat(ctx context.Context, chatId int64) (int64, bool, error) {
+	participantsByRoom, err := h.listChatParticipants(ctx, chatId)
+	if err != nil {
+		return 0, false, err
+	}
+	var usersCount int64
+	var hasScreenShares = false
+	for _, participants := range participantsByRoom {
+		usersCount += int64(len(participants))
+		for _, p := range participants {
+			for _, t := range p.Tracks {
+				if t.Source == livekit.TrackSource_SCREEN_SHARE {
+					hasScreenShares = true
+				}
+			}
+		}
+	}
 	return usersCount, hasScreenShares, nil
 }
 
 func (vh *UserService) GetVideoParticipants(ctx context.Context, chatId int64) ([]dto.UserCallStateId, error) {
-	roomName := utils.GetRoomNameFromId(chatId)
-
 	var ret = []dto.UserCallStateId{}
 	var set = make(map[dto.UserCallStateId]bool)
 
-	lpr := &livekit.ListParticipantsRequest{Room: roomName}
-	participants, err := vh.livekitRoomClient.ListParticipants(ctx, lpr)
+	participantsByRoom, err := vh.listChatParticipants(ctx, chatId)
 	if err != nil {
 		vh.lgr.WithTracing(ctx).Errorf("Unable to get participants %v", err)
 		return ret, err
 	}
 
-	for _, participant := range participants.Participants {
-		metadata, err := utils.ParseParticipantMetadataOrNull(participant)
-		if err != nil {
-			vh.lgr.WithTracing(ctx).Errorf("got error during parsing metadata from participant=%v chatId=%v, %v", participant, chatId, err)
-			continue
-		}
-		if metadata == nil {
-			continue
+	for _, participants := range participantsByRoom {
+		for _, participant := range participants {
+			metadata, err := utils.ParseParticipantMetadataOrNull(participant)
+			if err != nil {
+				vh.lgr.WithTracing(ctx).Errorf("got error during parsing metadata from participant=%v chatId=%v, %v", participant, chatId, err)
+				continue
+			}
+			if metadata == nil {
+				continue
+			}
+			set[dto.UserCallStateId{
+				UserId:  metadata.UserId,
+				TokenId: metadata.TokenId,
+			}] = true
 		}
-		set[dto.UserCallStateId{
-			UserId:  metadata.UserId,
-			TokenId: metadata.TokenId,
-		}] = true
 	}
 
 	for key, value := range set {
@@ -98,16 +131,19 @@ func (vh *UserService) GetVideoParticipants(ctx context.Context, chatId int64) (
 }
 
 func (vh *UserService) KickUserHavingChatId(ctx context.Context, chatId, userId int64) {
-	roomName := utils.GetRoomNameFromId(chatId)
-
-	lpr := &livekit.ListParticipantsRequest{Room: roomName}
-	participants, err := vh.livekitRoomClient.ListParticipants(ctx, lpr)
+	participantsByRoom, err := vh.listChatParticipants(ctx, chatId)
 	if err != nil {
 		vh.lgr.WithTracing(ctx).Errorf("Unable to get participants %v", err)
 		return
 	}
 
-	for _, participant := range participants.Participants {
+	for roomName, participants := range participantsByRoom {
+		vh.kickUserFromRoom(ctx, roomName, chatId, userId, participants)
+	}
+}
+
+func (vh *UserService) kickUserFromRoom(ctx context.Context, roomName string, chatId, userId int64, participants []*livekit.ParticipantInfo) {
+	for _, participant := range participants {
 		metadata, err := utils.ParseParticipantMetadataOrNull(participant)
 		if err != nil {
 			vh.lgr.WithTracing(ctx).Errorf("got error during parsing metadata from participant=%v chatId=%v, %v", participant, chatId, err)
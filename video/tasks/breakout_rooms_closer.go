@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/nkonev/dcron"
+	redisLock "github.com/nkonev/dcron/plugin/lock/redis"
+	otelTrace "github.com/nkonev/dcron/plugin/trace/otel"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"nkonev.name/video/logger"
+	"nkonev.name/video/services"
+)
+
+type BreakoutRoomsCloserService struct {
+	breakoutRoomService *services.BreakoutRoomService
+	tracer              trace.Tracer
+	lgr                 *logger.Logger
+}
+
+func NewBreakoutRoomsCloserService(breakoutRoomService *services.BreakoutRoomService, lgr *logger.Logger) *BreakoutRoomsCloserService {
+	trcr := otel.Tracer("scheduler/breakout-rooms-closer")
+	return &BreakoutRoomsCloserService{
+		breakoutRoomService: breakoutRoomService,
+		tracer:              trcr,
+		lgr:                 lgr,
+	}
+}
+
+func (srv *BreakoutRoomsCloserService) doJob(ctx context.Context) {
+	srv.lgr.WithTracing(ctx).Debugf("Invoked periodic BreakoutRoomsCloserService")
+	srv.breakoutRoomService.CloseExpired(ctx)
+
+	srv.lgr.WithTracing(ctx).Debugf("End of BreakoutRoomsCloserService")
+}
+
+type BreakoutRoomsCloserTask struct {
+	dcron.Job
+}
+
+func BreakoutRoomsCloserScheduler(
+	service *BreakoutRoomsCloserService,
+	lgr *logger.Logger,
+) *BreakoutRoomsCloserTask {
+	const key = "breakoutRoomsCloserTask"
+	var str = viper.GetString("schedulers." + key + ".cron")
+	lgr.Infof("Created BreakoutRoomsCloserScheduler with cron %v", str)
+
+	job := dcron.NewJob(key, str, func(ctx context.Context) error {
+		service.doJob(ctx)
+		return nil
+	},
+		otelTrace.WithTracing(service.tracer, "scheduler.BreakoutRoomsCloser"),
+		redisLock.WithLockTTL(viper.GetDuration("schedulers."+key+".expiration")),
+	)
+
+	return &BreakoutRoomsCloserTask{job}
+}
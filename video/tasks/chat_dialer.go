@@ -29,6 +29,7 @@ type ChatDialerService struct {
 	chatClient               *client.RestClient
 	tracer                   trace.Tracer
 	stateChangedEventService *services.StateChangedEventService
+	availabilityService      *services.AvailabilityService
 }
 
 func NewChatDialerService(
@@ -38,6 +39,7 @@ func NewChatDialerService(
 	dialStatusPublisher *producer.RabbitDialStatusPublisher,
 	chatClient *client.RestClient,
 	stateChangedEventService *services.StateChangedEventService,
+	availabilityService *services.AvailabilityService,
 	lgr *logger.Logger,
 ) *ChatDialerService {
 	trcr := otel.Tracer("scheduler/chat-dialer")
@@ -49,6 +51,7 @@ func NewChatDialerService(
 		chatClient:               chatClient,
 		tracer:                   trcr,
 		stateChangedEventService: stateChangedEventService,
+		availabilityService:      availabilityService,
 		lgr:                      lgr,
 	}
 }
@@ -126,6 +129,8 @@ func (srv *ChatDialerService) processBatch(ctx context.Context, tx *db.Tx, chatI
 		realOwnerId = st.UserId
 	}
 
+	srv.stopRingingUnavailable(ctx, tx, chatId, ownerId, batchUserStates)
+
 	m := map[int64]string{}
 	for _, state := range batchUserStates {
 		// cleanNotNeededAnymoreDialData - should be before status == services.CallStatusNotFound exit
@@ -136,6 +141,63 @@ func (srv *ChatDialerService) processBatch(ctx context.Context, tx *db.Tx, chatI
 	srv.stateChangedEventService.SendDialEvents(ctx, chatId, m, realOwnerId, utils.NullToEmpty(st.OwnerAvatar), st.ChatTetATet, inviteNames)
 }
 
+// the callees who have turned do-not-disturb on or whose working hours have ended during the ringing
+// are switched to busy status and get missed call notification instead
+func (srv *ChatDialerService) stopRingingUnavailable(ctx context.Context, tx *db.Tx, chatId, ownerId int64, batchUserStates []dto.UserCallState) {
+	if ownerId == db.NoUser {
+		return
+	}
+
+	beingInvitedUserIds := []int64{}
+	for _, state := range batchUserStates {
+		if state.Status == db.CallStatusBeingInvited {
+			beingInvitedUserIds = append(beingInvitedUserIds, state.UserId)
+		}
+	}
+	if len(beingInvitedUserIds) == 0 {
+		return
+	}
+
+	unavailableUserIds, err := srv.availabilityService.GetUnavailableUserIds(ctx, tx, beingInvitedUserIds)
+	if err != nil {
+		srv.lgr.WithTracing(ctx).Errorf("Unable to get unavailable users of chat %v: %v", chatId, err)
+		return
+	}
+	if len(unavailableUserIds) == 0 {
+		return
+	}
+
+	missedUserIds := []int64{}
+	for i := range batchUserStates {
+		state := &batchUserStates[i]
+		if state.Status != db.CallStatusBeingInvited || !utils.Contains(unavailableUserIds, state.UserId) {
+			continue
+		}
+		srv.lgr.WithTracing(ctx).Infof("Stopping ringing of unavailable user tokenId %v, userId %v, chat %v", state.TokenId, state.UserId, chatId)
+		err = tx.SetRemoving(ctx, dto.UserCallStateId{
+			TokenId: state.TokenId,
+			UserId:  state.UserId,
+		}, db.CallStatusBusy)
+		if err != nil {
+			srv.lgr.WithTracing(ctx).Errorf("Unable to set busy status, user tokenId %v, userId %v, chat %v, error %v", state.TokenId, state.UserId, chatId, err)
+			continue
+		}
+		now := time.Now().UTC()
+		state.Status = db.CallStatusBusy
+		state.MarkedForRemoveAt = &now
+		if !utils.Contains(missedUserIds, state.UserId) {
+			missedUserIds = append(missedUserIds, state.UserId)
+		}
+	}
+
+	owners, err := srv.chatClient.GetUsers(ctx, []int64{ownerId})
+	if err != nil || len(owners) == 0 {
+		srv.lgr.WithTracing(ctx).Errorf("Unable to get the owner %v in order to send missed call notifications: %v", ownerId, err)
+		return
+	}
+	srv.availabilityService.SendMissedCallNotifications(ctx, chatId, ownerId, owners[0].Login, owners[0].Avatar, missedUserIds)
+}
+
 func (srv *ChatDialerService) cleanNotNeededAnymoreDialData(
 	ctx context.Context,
 	tx *db.Tx,
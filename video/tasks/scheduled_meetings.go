@@ -0,0 +1,63 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/nkonev/dcron"
+	redisLock "github.com/nkonev/dcron/plugin/lock/redis"
+	otelTrace "github.com/nkonev/dcron/plugin/trace/otel"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"nkonev.name/video/logger"
+	"nkonev.name/video/services"
+)
+
+type ScheduledMeetingsService struct {
+	scheduledMeetingService *services.ScheduledMeetingService
+	tracer                  trace.Tracer
+	lgr                     *logger.Logger
+}
+
+func NewScheduledMeetingsService(scheduledMeetingService *services.ScheduledMeetingService, lgr *logger.Logger) *ScheduledMeetingsService {
+	trcr := otel.Tracer("scheduler/scheduled-meetings")
+	return &ScheduledMeetingsService{
+		scheduledMeetingService: scheduledMeetingService,
+		tracer:                  trcr,
+		lgr:                     lgr,
+	}
+}
+
+func (srv *ScheduledMeetingsService) doJob(ctx context.Context) {
+	srv.lgr.WithTracing(ctx).Debugf("Invoked periodic ScheduledMeetingsService")
+	srv.scheduledMeetingService.ProcessDueMeetings(
+		ctx,
+		viper.GetDuration("schedulers.scheduledMeetingsTask.remindBefore"),
+		viper.GetDuration("schedulers.scheduledMeetingsTask.startGracePeriod"),
+	)
+
+	srv.lgr.WithTracing(ctx).Debugf("End of ScheduledMeetingsService")
+}
+
+type ScheduledMeetingsTask struct {
+	dcron.Job
+}
+
+func ScheduledMeetingsScheduler(
+	service *ScheduledMeetingsService,
+	lgr *logger.Logger,
+) *ScheduledMeetingsTask {
+	const key = "scheduledMeetingsTask"
+	var str = viper.GetString("schedulers." + key + ".cron")
+	lgr.Infof("Created ScheduledMeetingsScheduler with cron %v", str)
+
+	job := dcron.NewJob(key, str, func(ctx context.Context) error {
+		service.doJob(ctx)
+		return nil
+	},
+		otelTrace.WithTracing(service.tracer, "scheduler.ScheduledMeetings"),
+		redisLock.WithLockTTL(viper.GetDuration("schedulers."+key+".expiration")),
+	)
+
+	return &ScheduledMeetingsTask{job}
+}
@@ -172,12 +172,18 @@ func (srv *SynchronizeWithLivekitService) createParticipants(ctx context.Context
 		return
 	}
 
+	// the participants of the breakout rooms are got together with the main room, so each chat is processed once
+	processedChatIds := map[int64]bool{}
 	for _, room := range rooms.Rooms {
 		chatId, err := utils.GetRoomIdFromName(room.Name)
 		if err != nil {
 			srv.lgr.WithTracing(ctx).Errorf("got error during getting chat id from roomName %v %v", room.Name, err)
 			continue
 		}
+		if processedChatIds[chatId] {
+			continue
+		}
+		processedChatIds[chatId] = true
 
 		videoParticipants, err := srv.userService.GetVideoParticipants(ctx, chatId)
 		if err != nil {
@@ -177,6 +177,11 @@ func GetRoomNameFromId(chatId int64) string {
 	return fmt.Sprintf("chat%v", chatId)
 }
 
+// breakout room is a sub-room of the chat's call, e.g. chat42_breakout3
+func GetBreakoutRoomName(chatId, breakoutRoomId int64) string {
+	return fmt.Sprintf("chat%v_breakout%v", chatId, breakoutRoomId)
+}
+
 func MakeIdentityFromUserId(userId int64) string {
 	return fmt.Sprintf("%v_%v", userId, uuid.New().String())
 }
@@ -228,15 +233,30 @@ func ParseParticipantMetadataOrNull(participant *livekit.ParticipantInfo) (*dto.
 	return ParseMetadata(participant.Metadata)
 }
 
+// returns chat id both for the main room and for the breakout room
 func GetRoomIdFromName(chatName string) (int64, error) {
-	var chatId int64
-	if _, err := fmt.Sscanf(chatName, "chat%d", &chatId); err != nil {
-		return 0, err
+	chatId, _, err := ParseRoomName(chatName)
+	return chatId, err
+}
+
+// returns chat id and breakout room id, the latter is nil for the main room
+func ParseRoomName(roomName string) (int64, *int64, error) {
+	var chatId, breakoutRoomId int64
+	if n, _ := fmt.Sscanf(roomName, "chat%d_breakout%d", &chatId, &breakoutRoomId); n == 2 {
+		return chatId, &breakoutRoomId, nil
+	}
+	if _, err := fmt.Sscanf(roomName, "chat%d", &chatId); err != nil {
+		return 0, nil, err
 	} else {
-		return chatId, nil
+		return chatId, nil, nil
 	}
 }
 
+func IsBreakoutRoomName(roomName string) bool {
+	_, breakoutRoomId, err := ParseRoomName(roomName)
+	return err == nil && breakoutRoomId != nil
+}
+
 func GetType(aDto interface{}) string {
 	strName := fmt.Sprintf("%T", aDto)
 	return strName